@@ -0,0 +1,111 @@
+package admin
+
+import (
+	"net/http"
+	"strconv"
+
+	"hasir-api/pkg/authentication"
+	"hasir-api/pkg/httpjson"
+)
+
+type handler struct {
+	service       Service
+	authenticator *authentication.AuthInterceptor
+}
+
+func NewHandler(service Service, authenticator *authentication.AuthInterceptor) *handler {
+	return &handler{
+		service:       service,
+		authenticator: authenticator,
+	}
+}
+
+func (h *handler) RegisterRoutes() (string, http.Handler) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /admin/queue-stats", h.requireAdmin(h.GetQueueStats))
+	mux.HandleFunc("GET /admin/organizations", h.requireAdmin(h.GetOrganizations))
+	mux.HandleFunc("GET /admin/invites/{inviteId}/email-job", h.requireAdmin(h.GetEmailJobByInviteId))
+	mux.HandleFunc("GET /admin/orphaned-repositories", h.requireAdmin(h.GetOrphanedRepositories))
+	mux.HandleFunc("DELETE /admin/orphaned-repositories", h.requireAdmin(h.PruneOrphanedRepository))
+
+	return "/admin/", mux
+}
+
+// requireAdmin authenticates the bearer token like the RPC services do and
+// lets only administrators through, with the caller's identity in the request
+// context.
+func (h *handler) requireAdmin(next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		ctx, err := h.authenticator.Authenticate(r.Context(), r.Header.Get("Authorization"))
+		if err != nil {
+			w.Header().Set("WWW-Authenticate", `Bearer realm="Admin"`)
+			http.Error(w, "Authentication required", http.StatusUnauthorized)
+			return
+		}
+
+		if err := h.service.RequireAdmin(ctx); err != nil {
+			httpjson.WriteError(w, "failed to check administrator", err)
+			return
+		}
+
+		next(w, r.WithContext(ctx))
+	}
+}
+
+func (h *handler) GetQueueStats(w http.ResponseWriter, r *http.Request) {
+	stats, err := h.service.GetQueueStats(r.Context())
+	if err != nil {
+		httpjson.WriteError(w, "failed to get queue stats", err)
+		return
+	}
+
+	httpjson.Write(w, "queue stats", stats)
+}
+
+// GetOrganizations takes the includeDeleted, page and pageSize query
+// parameters; missing or malformed values fall back to the defaults.
+func (h *handler) GetOrganizations(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	includeDeleted, _ := strconv.ParseBool(query.Get("includeDeleted"))
+	page, _ := strconv.Atoi(query.Get("page"))
+	pageSize, _ := strconv.Atoi(query.Get("pageSize"))
+
+	organizations, err := h.service.GetOrganizationsAdmin(r.Context(), includeDeleted, page, pageSize)
+	if err != nil {
+		httpjson.WriteError(w, "failed to get organizations", err)
+		return
+	}
+
+	httpjson.Write(w, "organizations", organizations)
+}
+
+func (h *handler) GetEmailJobByInviteId(w http.ResponseWriter, r *http.Request) {
+	job, err := h.service.GetEmailJobByInviteId(r.Context(), r.PathValue("inviteId"))
+	if err != nil {
+		httpjson.WriteError(w, "failed to get email job", err)
+		return
+	}
+
+	httpjson.Write(w, "email job", job)
+}
+
+func (h *handler) GetOrphanedRepositories(w http.ResponseWriter, r *http.Request) {
+	paths, err := h.service.FindOrphanedRepositories(r.Context())
+	if err != nil {
+		httpjson.WriteError(w, "failed to find orphaned repositories", err)
+		return
+	}
+
+	httpjson.Write(w, "orphaned repositories", map[string][]string{"paths": paths})
+}
+
+// PruneOrphanedRepository takes the directory to remove in the path query
+// parameter, as reported by GetOrphanedRepositories.
+func (h *handler) PruneOrphanedRepository(w http.ResponseWriter, r *http.Request) {
+	if err := h.service.PruneOrphan(r.Context(), r.URL.Query().Get("path")); err != nil {
+		httpjson.WriteError(w, "failed to prune orphaned repository", err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
@@ -0,0 +1,180 @@
+package admin
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"connectrpc.com/connect"
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+
+	"hasir-api/pkg/authentication"
+)
+
+const testJwtSecret = "test-secret"
+
+func createTestToken(t *testing.T, userId string) string {
+	t.Helper()
+
+	claims := &authentication.JwtClaims{
+		RegisteredClaims: jwt.RegisteredClaims{
+			Subject:   userId,
+			ExpiresAt: jwt.NewNumericDate(time.Now().Add(time.Hour)),
+		},
+	}
+
+	signed, err := jwt.NewWithClaims(jwt.SigningMethodHS256, claims).SignedString([]byte(testJwtSecret))
+	require.NoError(t, err)
+
+	return "Bearer " + signed
+}
+
+func newTestHandler(t *testing.T) (*http.ServeMux, *MockRepository) {
+	t.Helper()
+
+	ctrl := gomock.NewController(t)
+	mockRepository := NewMockRepository(ctrl)
+
+	mux := http.NewServeMux()
+	path, h := NewHandler(
+		NewService(mockRepository, nil, []string{"admin-1"}),
+		authentication.NewAuthInterceptor([]byte(testJwtSecret)),
+	).RegisterRoutes()
+	mux.Handle(path, h)
+
+	return mux, mockRepository
+}
+
+func TestHandler_GetQueueStats(t *testing.T) {
+	t.Run("returns stats for admins", func(t *testing.T) {
+		mux, mockRepository := newTestHandler(t)
+
+		mockRepository.EXPECT().
+			GetEmailJobStats(gomock.Any()).
+			Return(&QueueStatsDTO{Pending: 1, Failed: 2}, nil)
+		mockRepository.EXPECT().
+			GetSdkGenerationJobStats(gomock.Any()).
+			Return(&QueueStatsDTO{Processing: 3}, nil)
+
+		req := httptest.NewRequest(http.MethodGet, "/admin/queue-stats", nil)
+		req.Header.Set("Authorization", createTestToken(t, "admin-1"))
+		w := httptest.NewRecorder()
+
+		mux.ServeHTTP(w, req)
+
+		require.Equal(t, http.StatusOK, w.Code)
+		assert.Equal(t, "application/json", w.Header().Get("Content-Type"))
+
+		var resp QueueStatsResponse
+		require.NoError(t, json.NewDecoder(w.Body).Decode(&resp))
+		assert.Equal(t, 1, resp.EmailJobs.Pending)
+		assert.Equal(t, 2, resp.EmailJobs.Failed)
+		assert.Equal(t, 3, resp.SdkGenerationJobs.Processing)
+	})
+
+	t.Run("returns 403 for non-admins", func(t *testing.T) {
+		mux, _ := newTestHandler(t)
+
+		req := httptest.NewRequest(http.MethodGet, "/admin/queue-stats", nil)
+		req.Header.Set("Authorization", createTestToken(t, "user-1"))
+		w := httptest.NewRecorder()
+
+		mux.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusForbidden, w.Code)
+	})
+
+	t.Run("returns 401 without a valid token", func(t *testing.T) {
+		mux, _ := newTestHandler(t)
+
+		req := httptest.NewRequest(http.MethodGet, "/admin/queue-stats", nil)
+		req.Header.Set("Authorization", "Bearer invalid")
+		w := httptest.NewRecorder()
+
+		mux.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusUnauthorized, w.Code)
+	})
+}
+
+func TestHandler_GetOrganizations(t *testing.T) {
+	t.Run("passes the query parameters through", func(t *testing.T) {
+		mux, mockRepository := newTestHandler(t)
+
+		deletedAt := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+		mockRepository.EXPECT().
+			GetOrganizations(gomock.Any(), true, 2, 5).
+			Return([]OrganizationDTO{{Id: "org-1", Slug: "gone", DeletedAt: &deletedAt}}, 6, nil)
+
+		req := httptest.NewRequest(http.MethodGet, "/admin/organizations?includeDeleted=true&page=2&pageSize=5", nil)
+		req.Header.Set("Authorization", createTestToken(t, "admin-1"))
+		w := httptest.NewRecorder()
+
+		mux.ServeHTTP(w, req)
+
+		require.Equal(t, http.StatusOK, w.Code)
+
+		var resp OrganizationsResponse
+		require.NoError(t, json.NewDecoder(w.Body).Decode(&resp))
+		require.Len(t, resp.Organizations, 1)
+		require.NotNil(t, resp.Organizations[0].DeletedAt)
+		assert.Equal(t, 6, resp.TotalCount)
+	})
+
+	t.Run("returns 403 for non-admins", func(t *testing.T) {
+		mux, _ := newTestHandler(t)
+
+		req := httptest.NewRequest(http.MethodGet, "/admin/organizations?includeDeleted=true", nil)
+		req.Header.Set("Authorization", createTestToken(t, "user-1"))
+		w := httptest.NewRecorder()
+
+		mux.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusForbidden, w.Code)
+	})
+}
+
+func TestHandler_GetEmailJobByInviteId(t *testing.T) {
+	t.Run("returns the latest job", func(t *testing.T) {
+		mux, mockRepository := newTestHandler(t)
+
+		mockRepository.EXPECT().
+			GetEmailJobByInviteId(gomock.Any(), "invite-1").
+			Return(&EmailJobDTO{Id: "job-1", InviteId: "invite-1", Status: "completed", Attempts: 1}, nil)
+
+		req := httptest.NewRequest(http.MethodGet, "/admin/invites/invite-1/email-job", nil)
+		req.Header.Set("Authorization", createTestToken(t, "admin-1"))
+		w := httptest.NewRecorder()
+
+		mux.ServeHTTP(w, req)
+
+		require.Equal(t, http.StatusOK, w.Code)
+
+		var resp EmailJob
+		require.NoError(t, json.NewDecoder(w.Body).Decode(&resp))
+		assert.Equal(t, "job-1", resp.Id)
+		assert.Equal(t, "completed", resp.Status)
+	})
+
+	t.Run("returns 404 when the invite has no job", func(t *testing.T) {
+		mux, mockRepository := newTestHandler(t)
+
+		mockRepository.EXPECT().
+			GetEmailJobByInviteId(gomock.Any(), "invite-2").
+			Return(nil, connect.NewError(connect.CodeNotFound, errors.New("email job not found")))
+
+		req := httptest.NewRequest(http.MethodGet, "/admin/invites/invite-2/email-job", nil)
+		req.Header.Set("Authorization", createTestToken(t, "admin-1"))
+		w := httptest.NewRecorder()
+
+		mux.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusNotFound, w.Code)
+	})
+}
@@ -0,0 +1,91 @@
+package admin
+
+import "time"
+
+type QueueStatsDTO struct {
+	Pending         int        `db:"pending"`
+	Processing      int        `db:"processing"`
+	Completed       int        `db:"completed"`
+	Failed          int        `db:"failed"`
+	Cancelled       int        `db:"cancelled"`
+	OldestPendingAt *time.Time `db:"oldest_pending_at"`
+}
+
+type QueueStats struct {
+	Pending                 int     `json:"pending"`
+	Processing              int     `json:"processing"`
+	Completed               int     `json:"completed"`
+	Failed                  int     `json:"failed"`
+	Cancelled               int     `json:"cancelled"`
+	OldestPendingAgeSeconds float64 `json:"oldestPendingAgeSeconds"`
+}
+
+type QueueStatsResponse struct {
+	EmailJobs         QueueStats `json:"emailJobs"`
+	SdkGenerationJobs QueueStats `json:"sdkGenerationJobs"`
+}
+
+type OrganizationDTO struct {
+	Id          string     `db:"id"`
+	Slug        string     `db:"slug"`
+	DisplayName string     `db:"display_name"`
+	Visibility  string     `db:"visibility"`
+	CreatedBy   string     `db:"created_by"`
+	CreatedAt   time.Time  `db:"created_at"`
+	DeletedAt   *time.Time `db:"deleted_at"`
+}
+
+type Organization struct {
+	Id          string     `json:"id"`
+	Slug        string     `json:"slug"`
+	DisplayName string     `json:"displayName"`
+	Visibility  string     `json:"visibility"`
+	CreatedBy   string     `json:"createdBy"`
+	CreatedAt   time.Time  `json:"createdAt"`
+	DeletedAt   *time.Time `json:"deletedAt,omitempty"`
+}
+
+type OrganizationsResponse struct {
+	Organizations []Organization `json:"organizations"`
+	TotalCount    int            `json:"totalCount"`
+	NextPage      int            `json:"nextPage"`
+	TotalPages    int            `json:"totalPages"`
+}
+
+// EmailJobDTO leaves out the invite token, which must not reach support
+// tooling.
+type EmailJobDTO struct {
+	Id             string     `db:"id"`
+	InviteId       string     `db:"invite_id"`
+	OrganizationId string     `db:"organization_id"`
+	Email          string     `db:"email"`
+	Status         string     `db:"status"`
+	Attempts       int        `db:"attempts"`
+	MaxAttempts    int        `db:"max_attempts"`
+	CreatedAt      time.Time  `db:"created_at"`
+	ProcessedAt    *time.Time `db:"processed_at"`
+	CompletedAt    *time.Time `db:"completed_at"`
+	ErrorMessage   *string    `db:"error_message"`
+}
+
+type EmailJob struct {
+	Id             string     `json:"id"`
+	InviteId       string     `json:"inviteId"`
+	OrganizationId string     `json:"organizationId"`
+	Email          string     `json:"email"`
+	Status         string     `json:"status"`
+	Attempts       int        `json:"attempts"`
+	MaxAttempts    int        `json:"maxAttempts"`
+	CreatedAt      time.Time  `json:"createdAt"`
+	ProcessedAt    *time.Time `json:"processedAt,omitempty"`
+	CompletedAt    *time.Time `json:"completedAt,omitempty"`
+	ErrorMessage   *string    `json:"errorMessage,omitempty"`
+}
+
+// ExpiredRepositoryDTO is a repository soft-deleted longer ago than the
+// retention period, with what is needed to find its files on disk.
+type ExpiredRepositoryDTO struct {
+	Id             string `db:"id"`
+	OrganizationId string `db:"organization_id"`
+	Path           string `db:"path"`
+}
@@ -0,0 +1,182 @@
+package admin
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// RepositoryFileRemover deletes what a repository left on disk.
+type RepositoryFileRemover interface {
+	RemoveRepositoryFiles(ctx context.Context, organizationId, repositoryId, repoPath string) error
+}
+
+// ReapResult counts the rows one pass removed for good.
+type ReapResult struct {
+	Repositories  int64
+	Organizations int64
+	Users         int64
+}
+
+// Reaper permanently removes users, organizations and repositories that were
+// soft-deleted longer ago than the retention period. Repositories go first,
+// each only after its files are gone, then organizations without any
+// repository left, then users nothing live points at; so ON DELETE CASCADE
+// never takes a row the reaper did not choose itself.
+type Reaper struct {
+	repository Repository
+	files      RepositoryFileRemover
+	retention  time.Duration
+	batchSize  int
+	now        func() time.Time
+
+	stopChan chan struct{}
+	stopOnce sync.Once
+	cancel   context.CancelFunc
+	wg       sync.WaitGroup
+}
+
+func NewReaper(repository Repository, files RepositoryFileRemover, retention time.Duration, batchSize int) *Reaper {
+	return &Reaper{
+		repository: repository,
+		files:      files,
+		retention:  retention,
+		batchSize:  batchSize,
+		now:        time.Now,
+		stopChan:   make(chan struct{}),
+	}
+}
+
+// Start runs a pass every interval until Stop is called. A zero retention
+// period leaves the reaper off.
+func (r *Reaper) Start(ctx context.Context, interval time.Duration) {
+	if r.retention <= 0 {
+		zap.L().Info("soft-delete reaper disabled")
+		return
+	}
+
+	ctx, r.cancel = context.WithCancel(ctx)
+	r.wg.Add(1)
+	go func() {
+		defer r.wg.Done()
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		zap.L().Info("soft-delete reaper started",
+			zap.Duration("retention", r.retention),
+			zap.Duration("interval", interval),
+			zap.Int("batchSize", r.batchSize))
+
+		for {
+			select {
+			case <-r.stopChan:
+				return
+			case <-ticker.C:
+				result, err := r.Reap(ctx)
+				if err != nil {
+					zap.L().Error("soft-delete reaper pass failed", zap.Error(err))
+				}
+				if result.Repositories > 0 || result.Organizations > 0 || result.Users > 0 {
+					zap.L().Info("removed expired soft-deleted rows",
+						zap.Int64("repositories", result.Repositories),
+						zap.Int64("organizations", result.Organizations),
+						zap.Int64("users", result.Users))
+				}
+			}
+		}
+	}()
+}
+
+// Stop interrupts a running pass between batches and waits for it to return.
+func (r *Reaper) Stop() {
+	r.stopOnce.Do(func() {
+		close(r.stopChan)
+		if r.cancel != nil {
+			r.cancel()
+		}
+		r.wg.Wait()
+	})
+}
+
+// Reap runs one pass, batch by batch, until nothing past the retention
+// period is left to remove. The counts cover what was removed before any
+// error.
+func (r *Reaper) Reap(ctx context.Context) (ReapResult, error) {
+	var result ReapResult
+	deletedBefore := r.now().UTC().Add(-r.retention)
+
+	var err error
+	if result.Repositories, err = r.reapRepositories(ctx, deletedBefore); err != nil {
+		return result, err
+	}
+	if result.Organizations, err = r.reapBatches(ctx, deletedBefore, r.repository.HardDeleteOrganizations); err != nil {
+		return result, err
+	}
+	if result.Users, err = r.reapBatches(ctx, deletedBefore, r.repository.HardDeleteUsers); err != nil {
+		return result, err
+	}
+
+	return result, nil
+}
+
+// reapRepositories keeps the record of a repository whose files could not be
+// removed, so the next pass retries it. A batch in which nothing could be
+// removed ends the pass rather than being fetched again.
+func (r *Reaper) reapRepositories(ctx context.Context, deletedBefore time.Time) (int64, error) {
+	var total int64
+	for {
+		if err := ctx.Err(); err != nil {
+			return total, err
+		}
+
+		expired, err := r.repository.GetExpiredRepositories(ctx, deletedBefore, r.batchSize)
+		if err != nil {
+			return total, err
+		}
+
+		ids := make([]string, 0, len(expired))
+		for _, repo := range expired {
+			if err := r.files.RemoveRepositoryFiles(ctx, repo.OrganizationId, repo.Id, repo.Path); err != nil {
+				zap.L().Error("failed to remove files of expired repository",
+					zap.String("id", repo.Id),
+					zap.String("path", repo.Path),
+					zap.Error(err))
+				continue
+			}
+			ids = append(ids, repo.Id)
+		}
+
+		if len(ids) > 0 {
+			deleted, err := r.repository.HardDeleteRepositories(ctx, ids, deletedBefore)
+			total += deleted
+			if err != nil {
+				return total, err
+			}
+		}
+
+		if len(expired) < r.batchSize || len(ids) == 0 {
+			return total, nil
+		}
+	}
+}
+
+func (r *Reaper) reapBatches(ctx context.Context, deletedBefore time.Time, hardDelete func(context.Context, time.Time, int) (int64, error)) (int64, error) {
+	var total int64
+	for {
+		if err := ctx.Err(); err != nil {
+			return total, err
+		}
+
+		deleted, err := hardDelete(ctx, deletedBefore, r.batchSize)
+		total += deleted
+		if err != nil {
+			return total, err
+		}
+
+		if deleted < int64(r.batchSize) {
+			return total, nil
+		}
+	}
+}
@@ -0,0 +1,122 @@
+package admin
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+
+	"hasir-api/internal/registry"
+)
+
+func TestReaper_Reap(t *testing.T) {
+	now := time.Date(2025, 6, 1, 12, 0, 0, 0, time.UTC)
+	retention := 30 * 24 * time.Hour
+	cutoff := now.Add(-retention)
+
+	setup := func(t *testing.T, batchSize int) (*Reaper, *MockRepository, *registry.MockService) {
+		ctrl := gomock.NewController(t)
+		mockRepo := NewMockRepository(ctrl)
+		mockFiles := registry.NewMockService(ctrl)
+
+		reaper := NewReaper(mockRepo, mockFiles, retention, batchSize)
+		reaper.now = func() time.Time { return now }
+
+		return reaper, mockRepo, mockFiles
+	}
+
+	t.Run("removes files before the records, then organizations and users", func(t *testing.T) {
+		reaper, mockRepo, mockFiles := setup(t, 10)
+		ctx := context.Background()
+
+		expired := []ExpiredRepositoryDTO{
+			{Id: "repo-1", OrganizationId: "org-1", Path: "/repos/repo-1"},
+			{Id: "repo-2", OrganizationId: "org-1", Path: "/repos/repo-2"},
+		}
+		gomock.InOrder(
+			mockRepo.EXPECT().GetExpiredRepositories(ctx, cutoff, 10).Return(expired, nil),
+			mockFiles.EXPECT().RemoveRepositoryFiles(ctx, "org-1", "repo-1", "/repos/repo-1").Return(nil),
+			mockFiles.EXPECT().RemoveRepositoryFiles(ctx, "org-1", "repo-2", "/repos/repo-2").Return(nil),
+			mockRepo.EXPECT().HardDeleteRepositories(ctx, []string{"repo-1", "repo-2"}, cutoff).Return(int64(2), nil),
+			mockRepo.EXPECT().HardDeleteOrganizations(ctx, cutoff, 10).Return(int64(1), nil),
+			mockRepo.EXPECT().HardDeleteUsers(ctx, cutoff, 10).Return(int64(3), nil),
+		)
+
+		result, err := reaper.Reap(ctx)
+		require.NoError(t, err)
+		assert.Equal(t, ReapResult{Repositories: 2, Organizations: 1, Users: 3}, result)
+	})
+
+	t.Run("keeps the record of a repository whose files could not be removed", func(t *testing.T) {
+		reaper, mockRepo, mockFiles := setup(t, 10)
+		ctx := context.Background()
+
+		mockRepo.EXPECT().
+			GetExpiredRepositories(ctx, cutoff, 10).
+			Return([]ExpiredRepositoryDTO{
+				{Id: "repo-1", OrganizationId: "org-1", Path: "/repos/repo-1"},
+				{Id: "repo-2", OrganizationId: "org-1", Path: "/repos/repo-2"},
+			}, nil)
+		mockFiles.EXPECT().RemoveRepositoryFiles(ctx, "org-1", "repo-1", "/repos/repo-1").Return(errors.New("permission denied"))
+		mockFiles.EXPECT().RemoveRepositoryFiles(ctx, "org-1", "repo-2", "/repos/repo-2").Return(nil)
+		mockRepo.EXPECT().HardDeleteRepositories(ctx, []string{"repo-2"}, cutoff).Return(int64(1), nil)
+		mockRepo.EXPECT().HardDeleteOrganizations(ctx, cutoff, 10).Return(int64(0), nil)
+		mockRepo.EXPECT().HardDeleteUsers(ctx, cutoff, 10).Return(int64(0), nil)
+
+		result, err := reaper.Reap(ctx)
+		require.NoError(t, err)
+		assert.Equal(t, int64(1), result.Repositories)
+	})
+
+	t.Run("works through full batches until one comes back short", func(t *testing.T) {
+		reaper, mockRepo, mockFiles := setup(t, 2)
+		ctx := context.Background()
+
+		first := []ExpiredRepositoryDTO{{Id: "repo-1", OrganizationId: "org-1"}, {Id: "repo-2", OrganizationId: "org-1"}}
+		second := []ExpiredRepositoryDTO{{Id: "repo-3", OrganizationId: "org-1"}}
+		mockFiles.EXPECT().RemoveRepositoryFiles(ctx, "org-1", gomock.Any(), "").Return(nil).Times(3)
+		gomock.InOrder(
+			mockRepo.EXPECT().GetExpiredRepositories(ctx, cutoff, 2).Return(first, nil),
+			mockRepo.EXPECT().HardDeleteRepositories(ctx, []string{"repo-1", "repo-2"}, cutoff).Return(int64(2), nil),
+			mockRepo.EXPECT().GetExpiredRepositories(ctx, cutoff, 2).Return(second, nil),
+			mockRepo.EXPECT().HardDeleteRepositories(ctx, []string{"repo-3"}, cutoff).Return(int64(1), nil),
+		)
+		gomock.InOrder(
+			mockRepo.EXPECT().HardDeleteOrganizations(ctx, cutoff, 2).Return(int64(2), nil),
+			mockRepo.EXPECT().HardDeleteOrganizations(ctx, cutoff, 2).Return(int64(0), nil),
+		)
+		gomock.InOrder(
+			mockRepo.EXPECT().HardDeleteUsers(ctx, cutoff, 2).Return(int64(2), nil),
+			mockRepo.EXPECT().HardDeleteUsers(ctx, cutoff, 2).Return(int64(2), nil),
+			mockRepo.EXPECT().HardDeleteUsers(ctx, cutoff, 2).Return(int64(1), nil),
+		)
+
+		result, err := reaper.Reap(ctx)
+		require.NoError(t, err)
+		assert.Equal(t, ReapResult{Repositories: 3, Organizations: 2, Users: 5}, result)
+	})
+
+	t.Run("stops at the first database error", func(t *testing.T) {
+		reaper, mockRepo, _ := setup(t, 10)
+		ctx := context.Background()
+
+		mockRepo.EXPECT().GetExpiredRepositories(ctx, cutoff, 10).Return(nil, nil)
+		mockRepo.EXPECT().HardDeleteOrganizations(ctx, cutoff, 10).Return(int64(0), errors.New("connection lost"))
+
+		_, err := reaper.Reap(ctx)
+		assert.Error(t, err)
+	})
+}
+
+func TestReaper_Disabled(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	reaper := NewReaper(NewMockRepository(ctrl), registry.NewMockService(ctrl), 0, 10)
+
+	reaper.Start(context.Background(), time.Millisecond)
+	time.Sleep(10 * time.Millisecond)
+	reaper.Stop()
+}
@@ -0,0 +1,17 @@
+package admin
+
+import (
+	"context"
+	"time"
+)
+
+type Repository interface {
+	GetEmailJobStats(ctx context.Context) (*QueueStatsDTO, error)
+	GetSdkGenerationJobStats(ctx context.Context) (*QueueStatsDTO, error)
+	GetOrganizations(ctx context.Context, includeDeleted bool, page, pageSize int) ([]OrganizationDTO, int, error)
+	GetEmailJobByInviteId(ctx context.Context, inviteId string) (*EmailJobDTO, error)
+	GetExpiredRepositories(ctx context.Context, deletedBefore time.Time, limit int) ([]ExpiredRepositoryDTO, error)
+	HardDeleteRepositories(ctx context.Context, ids []string, deletedBefore time.Time) (int64, error)
+	HardDeleteOrganizations(ctx context.Context, deletedBefore time.Time, limit int) (int64, error)
+	HardDeleteUsers(ctx context.Context, deletedBefore time.Time, limit int) (int64, error)
+}
@@ -0,0 +1,200 @@
+package admin
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"connectrpc.com/connect"
+
+	"hasir-api/pkg/authentication"
+	"hasir-api/pkg/pagination"
+)
+
+var ErrNotAdmin = connect.NewError(connect.CodePermissionDenied, errors.New("only administrators can perform this operation"))
+
+type Service interface {
+	RequireAdmin(ctx context.Context) error
+	GetQueueStats(ctx context.Context) (*QueueStatsResponse, error)
+	GetOrganizationsAdmin(ctx context.Context, includeDeleted bool, page, pageSize int) (*OrganizationsResponse, error)
+	GetEmailJobByInviteId(ctx context.Context, inviteId string) (*EmailJob, error)
+	FindOrphanedRepositories(ctx context.Context) ([]string, error)
+	PruneOrphan(ctx context.Context, path string) error
+}
+
+// RepositoryJanitor finds and removes repository directories on disk that no
+// repository record points at.
+type RepositoryJanitor interface {
+	FindOrphanedRepositories(ctx context.Context) ([]string, error)
+	PruneOrphan(ctx context.Context, path string) error
+}
+
+type service struct {
+	repository   Repository
+	janitor      RepositoryJanitor
+	adminUserIds map[string]struct{}
+	now          func() time.Time
+}
+
+func NewService(repository Repository, janitor RepositoryJanitor, adminUserIds []string) Service {
+	admins := make(map[string]struct{}, len(adminUserIds))
+	for _, id := range adminUserIds {
+		if id != "" {
+			admins[id] = struct{}{}
+		}
+	}
+
+	return &service{
+		repository:   repository,
+		janitor:      janitor,
+		adminUserIds: admins,
+		now:          time.Now,
+	}
+}
+
+// RequireAdmin returns ErrNotAdmin unless the caller in ctx is a configured
+// administrator.
+func (s *service) RequireAdmin(ctx context.Context) error {
+	userId, err := authentication.MustGetUserID(ctx)
+	if err != nil {
+		return err
+	}
+
+	if _, ok := s.adminUserIds[userId]; !ok {
+		return ErrNotAdmin
+	}
+
+	return nil
+}
+
+func (s *service) GetQueueStats(ctx context.Context) (*QueueStatsResponse, error) {
+	if err := s.RequireAdmin(ctx); err != nil {
+		return nil, err
+	}
+
+	emailStats, err := s.repository.GetEmailJobStats(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	sdkStats, err := s.repository.GetSdkGenerationJobStats(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	now := s.now()
+	return &QueueStatsResponse{
+		EmailJobs:         toQueueStats(emailStats, now),
+		SdkGenerationJobs: toQueueStats(sdkStats, now),
+	}, nil
+}
+
+// GetOrganizationsAdmin lists organizations newest first. Unlike the public
+// listing it can include soft-deleted organizations, which carry the time
+// they were deleted.
+func (s *service) GetOrganizationsAdmin(ctx context.Context, includeDeleted bool, page, pageSize int) (*OrganizationsResponse, error) {
+	if err := s.RequireAdmin(ctx); err != nil {
+		return nil, err
+	}
+
+	page = max(page, 1)
+	if pageSize < 1 {
+		pageSize = 10
+	}
+	if pageSize > 100 {
+		pageSize = 100
+	}
+
+	dtos, totalCount, err := s.repository.GetOrganizations(ctx, includeDeleted, page, pageSize)
+	if err != nil {
+		return nil, err
+	}
+
+	organizations := make([]Organization, 0, len(dtos))
+	for _, dto := range dtos {
+		organizations = append(organizations, Organization{
+			Id:          dto.Id,
+			Slug:        dto.Slug,
+			DisplayName: dto.DisplayName,
+			Visibility:  dto.Visibility,
+			CreatedBy:   dto.CreatedBy,
+			CreatedAt:   dto.CreatedAt,
+			DeletedAt:   dto.DeletedAt,
+		})
+	}
+
+	nextPage, totalPages := pagination.Paginate(totalCount, page, pageSize)
+	return &OrganizationsResponse{
+		Organizations: organizations,
+		TotalCount:    totalCount,
+		NextPage:      nextPage,
+		TotalPages:    totalPages,
+	}, nil
+}
+
+// GetEmailJobByInviteId returns the most recent email job sent for an
+// invite, for support to see why an invite email did not arrive.
+func (s *service) GetEmailJobByInviteId(ctx context.Context, inviteId string) (*EmailJob, error) {
+	if err := s.RequireAdmin(ctx); err != nil {
+		return nil, err
+	}
+
+	if inviteId == "" {
+		return nil, connect.NewError(connect.CodeInvalidArgument, errors.New("invite id is required"))
+	}
+
+	dto, err := s.repository.GetEmailJobByInviteId(ctx, inviteId)
+	if err != nil {
+		return nil, err
+	}
+
+	return &EmailJob{
+		Id:             dto.Id,
+		InviteId:       dto.InviteId,
+		OrganizationId: dto.OrganizationId,
+		Email:          dto.Email,
+		Status:         dto.Status,
+		Attempts:       dto.Attempts,
+		MaxAttempts:    dto.MaxAttempts,
+		CreatedAt:      dto.CreatedAt,
+		ProcessedAt:    dto.ProcessedAt,
+		CompletedAt:    dto.CompletedAt,
+		ErrorMessage:   dto.ErrorMessage,
+	}, nil
+}
+
+func (s *service) FindOrphanedRepositories(ctx context.Context) ([]string, error) {
+	if err := s.RequireAdmin(ctx); err != nil {
+		return nil, err
+	}
+
+	return s.janitor.FindOrphanedRepositories(ctx)
+}
+
+func (s *service) PruneOrphan(ctx context.Context, path string) error {
+	if err := s.RequireAdmin(ctx); err != nil {
+		return err
+	}
+
+	if path == "" {
+		return connect.NewError(connect.CodeInvalidArgument, errors.New("path is required"))
+	}
+
+	return s.janitor.PruneOrphan(ctx, path)
+}
+
+func toQueueStats(dto *QueueStatsDTO, now time.Time) QueueStats {
+	stats := QueueStats{
+		Pending:    dto.Pending,
+		Processing: dto.Processing,
+		Completed:  dto.Completed,
+		Failed:     dto.Failed,
+		Cancelled:  dto.Cancelled,
+	}
+
+	if dto.OldestPendingAt != nil {
+		stats.OldestPendingAgeSeconds = now.Sub(*dto.OldestPendingAt).Seconds()
+	}
+
+	return stats
+}
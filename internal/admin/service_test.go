@@ -0,0 +1,265 @@
+package admin
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"connectrpc.com/connect"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+
+	"hasir-api/internal/registry"
+	"hasir-api/pkg/authentication"
+)
+
+func newTestService(t *testing.T, now time.Time, adminUserIds ...string) (*service, *MockRepository) {
+	t.Helper()
+
+	ctrl := gomock.NewController(t)
+	mockRepository := NewMockRepository(ctrl)
+
+	svc := NewService(mockRepository, nil, adminUserIds).(*service)
+	svc.now = func() time.Time { return now }
+
+	return svc, mockRepository
+}
+
+func TestService_GetQueueStats(t *testing.T) {
+	now := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+	adminCtx := context.WithValue(context.Background(), authentication.UserIDKey, "admin-1")
+
+	t.Run("returns counts and oldest pending age for both queues", func(t *testing.T) {
+		svc, mockRepository := newTestService(t, now, "admin-1")
+
+		oldestEmail := now.Add(-90 * time.Second)
+		mockRepository.EXPECT().
+			GetEmailJobStats(adminCtx).
+			Return(&QueueStatsDTO{
+				Pending:         2,
+				Processing:      1,
+				Completed:       5,
+				Failed:          3,
+				Cancelled:       1,
+				OldestPendingAt: &oldestEmail,
+			}, nil)
+		mockRepository.EXPECT().
+			GetSdkGenerationJobStats(adminCtx).
+			Return(&QueueStatsDTO{Completed: 4}, nil)
+
+		stats, err := svc.GetQueueStats(adminCtx)
+		require.NoError(t, err)
+
+		assert.Equal(t, QueueStats{
+			Pending:                 2,
+			Processing:              1,
+			Completed:               5,
+			Failed:                  3,
+			Cancelled:               1,
+			OldestPendingAgeSeconds: 90,
+		}, stats.EmailJobs)
+		assert.Equal(t, QueueStats{Completed: 4}, stats.SdkGenerationJobs)
+	})
+
+	t.Run("rejects non-admin users", func(t *testing.T) {
+		svc, _ := newTestService(t, now, "admin-1")
+
+		ctx := context.WithValue(context.Background(), authentication.UserIDKey, "user-1")
+		_, err := svc.GetQueueStats(ctx)
+
+		require.ErrorIs(t, err, ErrNotAdmin)
+	})
+
+	t.Run("rejects everyone when no admins are configured", func(t *testing.T) {
+		svc, _ := newTestService(t, now)
+
+		_, err := svc.GetQueueStats(adminCtx)
+
+		require.ErrorIs(t, err, ErrNotAdmin)
+	})
+
+	t.Run("requires authentication", func(t *testing.T) {
+		svc, _ := newTestService(t, now, "admin-1")
+
+		_, err := svc.GetQueueStats(context.Background())
+
+		require.Error(t, err)
+		assert.Equal(t, connect.CodeUnauthenticated, connect.CodeOf(err))
+	})
+
+	t.Run("propagates repository errors", func(t *testing.T) {
+		svc, mockRepository := newTestService(t, now, "admin-1")
+
+		mockRepository.EXPECT().
+			GetEmailJobStats(adminCtx).
+			Return(nil, errors.New("boom"))
+
+		_, err := svc.GetQueueStats(adminCtx)
+
+		require.Error(t, err)
+	})
+}
+
+func TestService_GetOrganizationsAdmin(t *testing.T) {
+	now := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+	adminCtx := context.WithValue(context.Background(), authentication.UserIDKey, "admin-1")
+	deletedAt := now.Add(-time.Hour)
+
+	t.Run("includes deleted organizations when requested", func(t *testing.T) {
+		svc, mockRepository := newTestService(t, now, "admin-1")
+
+		mockRepository.EXPECT().
+			GetOrganizations(adminCtx, true, 1, 10).
+			Return([]OrganizationDTO{
+				{Id: "org-1", Slug: "live", Visibility: "public", CreatedAt: now},
+				{Id: "org-2", Slug: "gone", Visibility: "private", CreatedAt: now, DeletedAt: &deletedAt},
+			}, 2, nil)
+
+		resp, err := svc.GetOrganizationsAdmin(adminCtx, true, 1, 10)
+		require.NoError(t, err)
+
+		require.Len(t, resp.Organizations, 2)
+		assert.Nil(t, resp.Organizations[0].DeletedAt)
+		require.NotNil(t, resp.Organizations[1].DeletedAt)
+		assert.True(t, deletedAt.Equal(*resp.Organizations[1].DeletedAt))
+		assert.Equal(t, 2, resp.TotalCount)
+		assert.Equal(t, 1, resp.TotalPages)
+		assert.Equal(t, 0, resp.NextPage)
+	})
+
+	t.Run("excludes deleted organizations by default", func(t *testing.T) {
+		svc, mockRepository := newTestService(t, now, "admin-1")
+
+		mockRepository.EXPECT().
+			GetOrganizations(adminCtx, false, 1, 10).
+			Return([]OrganizationDTO{{Id: "org-1", Slug: "live", CreatedAt: now}}, 1, nil)
+
+		resp, err := svc.GetOrganizationsAdmin(adminCtx, false, 1, 10)
+		require.NoError(t, err)
+
+		require.Len(t, resp.Organizations, 1)
+		assert.Equal(t, "org-1", resp.Organizations[0].Id)
+	})
+
+	t.Run("clamps paging", func(t *testing.T) {
+		svc, mockRepository := newTestService(t, now, "admin-1")
+
+		mockRepository.EXPECT().
+			GetOrganizations(adminCtx, false, 1, 100).
+			Return(nil, 250, nil)
+
+		resp, err := svc.GetOrganizationsAdmin(adminCtx, false, 0, 1000)
+		require.NoError(t, err)
+
+		assert.Empty(t, resp.Organizations)
+		assert.Equal(t, 3, resp.TotalPages)
+		assert.Equal(t, 2, resp.NextPage)
+	})
+
+	t.Run("rejects non-admin users", func(t *testing.T) {
+		svc, _ := newTestService(t, now, "admin-1")
+
+		ctx := context.WithValue(context.Background(), authentication.UserIDKey, "user-1")
+		_, err := svc.GetOrganizationsAdmin(ctx, true, 1, 10)
+
+		require.ErrorIs(t, err, ErrNotAdmin)
+	})
+
+	t.Run("requires authentication", func(t *testing.T) {
+		svc, _ := newTestService(t, now, "admin-1")
+
+		_, err := svc.GetOrganizationsAdmin(context.Background(), true, 1, 10)
+
+		require.Error(t, err)
+		assert.Equal(t, connect.CodeUnauthenticated, connect.CodeOf(err))
+	})
+}
+
+func TestService_GetEmailJobByInviteId(t *testing.T) {
+	now := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+	adminCtx := context.WithValue(context.Background(), authentication.UserIDKey, "admin-1")
+	errorMessage := "smtp: connection refused"
+
+	t.Run("returns the job with its failure", func(t *testing.T) {
+		svc, mockRepository := newTestService(t, now, "admin-1")
+
+		mockRepository.EXPECT().
+			GetEmailJobByInviteId(adminCtx, "invite-1").
+			Return(&EmailJobDTO{
+				Id:           "job-1",
+				InviteId:     "invite-1",
+				Email:        "friend@example.com",
+				Status:       "failed",
+				Attempts:     3,
+				MaxAttempts:  3,
+				CreatedAt:    now,
+				ErrorMessage: &errorMessage,
+			}, nil)
+
+		job, err := svc.GetEmailJobByInviteId(adminCtx, "invite-1")
+		require.NoError(t, err)
+
+		assert.Equal(t, "job-1", job.Id)
+		assert.Equal(t, "failed", job.Status)
+		assert.Equal(t, 3, job.Attempts)
+		require.NotNil(t, job.ErrorMessage)
+		assert.Equal(t, errorMessage, *job.ErrorMessage)
+	})
+
+	t.Run("not found", func(t *testing.T) {
+		svc, mockRepository := newTestService(t, now, "admin-1")
+
+		notFound := connect.NewError(connect.CodeNotFound, errors.New("email job not found"))
+		mockRepository.EXPECT().
+			GetEmailJobByInviteId(adminCtx, "invite-2").
+			Return(nil, notFound)
+
+		_, err := svc.GetEmailJobByInviteId(adminCtx, "invite-2")
+
+		require.ErrorIs(t, err, notFound)
+	})
+
+	t.Run("rejects non-admin users", func(t *testing.T) {
+		svc, _ := newTestService(t, now, "admin-1")
+
+		ctx := context.WithValue(context.Background(), authentication.UserIDKey, "user-1")
+		_, err := svc.GetEmailJobByInviteId(ctx, "invite-1")
+
+		require.ErrorIs(t, err, ErrNotAdmin)
+	})
+}
+
+func TestService_OrphanedRepositories(t *testing.T) {
+	now := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+	adminCtx := context.WithValue(context.Background(), authentication.UserIDKey, "admin-1")
+
+	t.Run("admins reach the janitor", func(t *testing.T) {
+		svc, _ := newTestService(t, now, "admin-1")
+		janitor := registry.NewMockService(gomock.NewController(t))
+		svc.janitor = janitor
+
+		janitor.EXPECT().
+			FindOrphanedRepositories(adminCtx).
+			Return([]string{"/repos/orphan"}, nil)
+		janitor.EXPECT().
+			PruneOrphan(adminCtx, "/repos/orphan").
+			Return(nil)
+
+		paths, err := svc.FindOrphanedRepositories(adminCtx)
+		require.NoError(t, err)
+		assert.Equal(t, []string{"/repos/orphan"}, paths)
+		require.NoError(t, svc.PruneOrphan(adminCtx, "/repos/orphan"))
+	})
+
+	t.Run("rejects non-admin users", func(t *testing.T) {
+		svc, _ := newTestService(t, now, "admin-1")
+		svc.janitor = registry.NewMockService(gomock.NewController(t))
+
+		ctx := context.WithValue(context.Background(), authentication.UserIDKey, "user-1")
+		_, err := svc.FindOrphanedRepositories(ctx)
+		require.ErrorIs(t, err, ErrNotAdmin)
+		require.ErrorIs(t, svc.PruneOrphan(ctx, "/repos/orphan"), ErrNotAdmin)
+	})
+}
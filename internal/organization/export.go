@@ -0,0 +1,145 @@
+package organization
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"time"
+
+	"connectrpc.com/connect"
+
+	"hasir-api/pkg/authentication"
+)
+
+const errOnlyOwnersCanExport = "only organization owners can export the organization"
+
+// OrganizationExport is the document ExportOrganization produces. It carries
+// no secrets: invite tokens, deploy tokens and credentials are left out.
+type OrganizationExport struct {
+	ExportedAt     time.Time            `json:"exportedAt"`
+	Organization   ExportedOrganization `json:"organization"`
+	Members        []ExportedMember     `json:"members"`
+	Repositories   []ExportedRepository `json:"repositories"`
+	PendingInvites []ExportedInvite     `json:"pendingInvites"`
+}
+
+type ExportedOrganization struct {
+	Id          string    `json:"id"`
+	Name        string    `json:"name"`
+	DisplayName string    `json:"displayName"`
+	Visibility  string    `json:"visibility"`
+	CreatedBy   string    `json:"createdBy"`
+	CreatedAt   time.Time `json:"createdAt"`
+}
+
+type ExportedMember struct {
+	UserId    string    `json:"userId"`
+	Username  string    `json:"username"`
+	Email     string    `json:"email"`
+	Role      string    `json:"role"`
+	JoinedAt  time.Time `json:"joinedAt"`
+	JoinedVia string    `json:"joinedVia"`
+}
+
+type ExportedRepository struct {
+	Id         string    `json:"id"`
+	Name       string    `json:"name"`
+	Visibility string    `json:"visibility"`
+	Archived   bool      `json:"archived"`
+	CreatedAt  time.Time `json:"createdAt"`
+}
+
+type ExportedInvite struct {
+	Email     string    `json:"email"`
+	Role      string    `json:"role"`
+	InvitedBy string    `json:"invitedBy"`
+	CreatedAt time.Time `json:"createdAt"`
+	ExpiresAt time.Time `json:"expiresAt"`
+}
+
+// ExportOrganization returns a JSON dump of the organization, its members,
+// repositories and pending invites for the calling owner. Empty sections are
+// written as empty arrays so consumers need not special-case them.
+func (s *service) ExportOrganization(ctx context.Context, organizationId string) ([]byte, error) {
+	userId, err := authentication.MustGetUserID(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	org, err := s.repository.GetOrganizationById(ctx, organizationId)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.verifyOwnerRole(ctx, organizationId, userId, errOnlyOwnersCanExport); err != nil {
+		return nil, err
+	}
+
+	members, usernames, emails, err := s.repository.GetMembers(ctx, organizationId)
+	if err != nil {
+		return nil, err
+	}
+
+	repositories, err := s.repository.GetOrganizationRepositories(ctx, organizationId)
+	if err != nil {
+		return nil, err
+	}
+
+	invites, err := s.repository.GetPendingInvites(ctx, organizationId)
+	if err != nil {
+		return nil, err
+	}
+
+	export := OrganizationExport{
+		ExportedAt: time.Now().UTC(),
+		Organization: ExportedOrganization{
+			Id:          org.Id,
+			Name:        org.Slug,
+			DisplayName: org.DisplayName,
+			Visibility:  string(org.Visibility),
+			CreatedBy:   org.CreatedBy,
+			CreatedAt:   org.CreatedAt,
+		},
+		Members:        make([]ExportedMember, len(members)),
+		Repositories:   make([]ExportedRepository, len(repositories)),
+		PendingInvites: make([]ExportedInvite, len(invites)),
+	}
+
+	for i, member := range members {
+		export.Members[i] = ExportedMember{
+			UserId:    member.UserId,
+			Username:  usernames[i],
+			Email:     emails[i],
+			Role:      string(member.Role),
+			JoinedAt:  member.JoinedAt,
+			JoinedVia: string(member.JoinedVia),
+		}
+	}
+
+	for i, repo := range repositories {
+		export.Repositories[i] = ExportedRepository{
+			Id:         repo.Id,
+			Name:       repo.Name,
+			Visibility: string(repo.Visibility),
+			Archived:   repo.Archived,
+			CreatedAt:  repo.CreatedAt,
+		}
+	}
+
+	for i, invite := range invites {
+		export.PendingInvites[i] = ExportedInvite{
+			Email:     invite.Email,
+			Role:      string(invite.Role),
+			InvitedBy: invite.InviterUsername,
+			CreatedAt: invite.CreatedAt,
+			ExpiresAt: invite.ExpiresAt,
+		}
+	}
+
+	data, err := json.Marshal(export)
+	if err != nil {
+		return nil, connect.NewError(connect.CodeInternal, errors.New("failed to encode organization export"))
+	}
+
+	return data, nil
+}
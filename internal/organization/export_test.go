@@ -0,0 +1,154 @@
+package organization
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"connectrpc.com/connect"
+	"go.uber.org/mock/gomock"
+
+	"hasir-api/pkg/authentication"
+	"hasir-api/pkg/proto"
+)
+
+func TestExportOrganization(t *testing.T) {
+	const (
+		orgId   = "org-123"
+		ownerId = "owner-123"
+	)
+	createdAt := time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+
+	seed := func(mockRepo *MockRepository) {
+		mockRepo.EXPECT().GetOrganizationById(gomock.Any(), orgId).Return(&OrganizationDTO{
+			Id:          orgId,
+			Slug:        "acme",
+			DisplayName: "Acme",
+			Visibility:  proto.VisibilityPrivate,
+			CreatedBy:   ownerId,
+			CreatedAt:   createdAt,
+		}, nil)
+		mockRepo.EXPECT().GetMemberRole(gomock.Any(), orgId, ownerId).Return(MemberRoleOwner, nil)
+	}
+
+	t.Run("success", func(t *testing.T) {
+		svc, mockRepo, _, _, _, _, ctx := newTestService(t)
+		ctx = context.WithValue(ctx, authentication.UserIDKey, ownerId)
+
+		seed(mockRepo)
+		mockRepo.EXPECT().GetMembers(gomock.Any(), orgId).Return(
+			[]*OrganizationMemberDTO{
+				{UserId: ownerId, Role: MemberRoleOwner, JoinedAt: createdAt, JoinedVia: JoinedViaDirect},
+				{UserId: "user-2", Role: MemberRoleAuthor, JoinedAt: createdAt, JoinedVia: JoinedViaInvite},
+			},
+			[]string{"owner", "second"},
+			[]string{"owner@example.com", "second@example.com"},
+			nil,
+		)
+		mockRepo.EXPECT().GetOrganizationRepositories(gomock.Any(), orgId).Return([]OrganizationRepositoryDTO{
+			{Id: "repo-1", Name: "api", Visibility: proto.VisibilityPrivate, CreatedAt: createdAt},
+			{Id: "repo-2", Name: "legacy", Visibility: proto.VisibilityPublic, Archived: true, CreatedAt: createdAt},
+			{Id: "repo-3", Name: "web", Visibility: proto.VisibilityPrivate, CreatedAt: createdAt},
+		}, nil)
+		mockRepo.EXPECT().GetPendingInvites(gomock.Any(), orgId).Return([]OrganizationInviteDetailsDTO{
+			{
+				OrganizationInviteDTO: OrganizationInviteDTO{
+					Email:     "invitee@example.com",
+					Token:     "secret-invite-token",
+					Role:      MemberRoleReader,
+					Status:    InviteStatusPending,
+					CreatedAt: createdAt,
+					ExpiresAt: createdAt.Add(7 * 24 * time.Hour),
+				},
+				InviterUsername: "owner",
+			},
+		}, nil)
+
+		data, err := svc.ExportOrganization(ctx, orgId)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		if strings.Contains(string(data), "secret-invite-token") {
+			t.Errorf("export leaks the invite token: %s", data)
+		}
+
+		var sections map[string]json.RawMessage
+		if err := json.Unmarshal(data, &sections); err != nil {
+			t.Fatalf("export is not a JSON object: %v", err)
+		}
+		for _, key := range []string{"exportedAt", "organization", "members", "repositories", "pendingInvites"} {
+			if _, ok := sections[key]; !ok {
+				t.Errorf("export is missing the %q section", key)
+			}
+		}
+
+		var export OrganizationExport
+		if err := json.Unmarshal(data, &export); err != nil {
+			t.Fatalf("failed to decode export: %v", err)
+		}
+		if export.Organization.Name != "acme" || export.Organization.DisplayName != "Acme" {
+			t.Errorf("unexpected organization: %+v", export.Organization)
+		}
+		if len(export.Members) != 2 {
+			t.Errorf("expected 2 members, got %d", len(export.Members))
+		} else if export.Members[1].Username != "second" || export.Members[1].Role != string(MemberRoleAuthor) || export.Members[1].JoinedVia != string(JoinedViaInvite) {
+			t.Errorf("unexpected member: %+v", export.Members[1])
+		}
+		if len(export.Repositories) != 3 {
+			t.Errorf("expected 3 repositories, got %d", len(export.Repositories))
+		} else if !export.Repositories[1].Archived {
+			t.Errorf("expected repository %q to be archived", export.Repositories[1].Name)
+		}
+		if len(export.PendingInvites) != 1 {
+			t.Errorf("expected 1 pending invite, got %d", len(export.PendingInvites))
+		} else if export.PendingInvites[0].InvitedBy != "owner" || export.PendingInvites[0].Email != "invitee@example.com" {
+			t.Errorf("unexpected invite: %+v", export.PendingInvites[0])
+		}
+	})
+
+	t.Run("empty sections are arrays", func(t *testing.T) {
+		svc, mockRepo, _, _, _, _, ctx := newTestService(t)
+		ctx = context.WithValue(ctx, authentication.UserIDKey, ownerId)
+
+		seed(mockRepo)
+		mockRepo.EXPECT().GetMembers(gomock.Any(), orgId).Return(nil, nil, nil, nil)
+		mockRepo.EXPECT().GetOrganizationRepositories(gomock.Any(), orgId).Return(nil, nil)
+		mockRepo.EXPECT().GetPendingInvites(gomock.Any(), orgId).Return(nil, nil)
+
+		data, err := svc.ExportOrganization(ctx, orgId)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		for _, want := range []string{`"members":[]`, `"repositories":[]`, `"pendingInvites":[]`} {
+			if !strings.Contains(string(data), want) {
+				t.Errorf("expected %s in %s", want, data)
+			}
+		}
+	})
+
+	t.Run("non-owner is denied", func(t *testing.T) {
+		svc, mockRepo, _, _, _, _, ctx := newTestService(t)
+		ctx = context.WithValue(ctx, authentication.UserIDKey, "user-2")
+
+		mockRepo.EXPECT().GetOrganizationById(gomock.Any(), orgId).Return(&OrganizationDTO{Id: orgId}, nil)
+		mockRepo.EXPECT().GetMemberRole(gomock.Any(), orgId, "user-2").Return(MemberRoleAuthor, nil)
+
+		_, err := svc.ExportOrganization(ctx, orgId)
+		var connectErr *connect.Error
+		if !errors.As(err, &connectErr) || connectErr.Code() != connect.CodePermissionDenied {
+			t.Fatalf("expected permission denied, got %v", err)
+		}
+	})
+
+	t.Run("unauthenticated", func(t *testing.T) {
+		svc, _, _, _, _, _, ctx := newTestService(t)
+
+		if _, err := svc.ExportOrganization(ctx, orgId); err == nil {
+			t.Fatal("expected an error without a user in the context")
+		}
+	})
+}
@@ -3,8 +3,10 @@ package organization
 import (
 	"context"
 	"errors"
-	"math"
+	"fmt"
 	"net/http"
+	"strconv"
+	"strings"
 
 	"buf.build/gen/go/hasir/hasir/connectrpc/go/organization/v1/organizationv1connect"
 	organizationv1 "buf.build/gen/go/hasir/hasir/protocolbuffers/go/organization/v1"
@@ -14,14 +16,56 @@ import (
 
 	"hasir-api/internal/registry"
 	"hasir-api/pkg/authentication"
+	"hasir-api/pkg/pagination"
 	"hasir-api/pkg/proto"
 )
 
+// GetOrganizationResponse has no fields for these, so the organization page
+// gets them as response headers: the repository count, and one
+// RecentRepositoryHeader value per recently updated repository name, newest
+// first.
+const (
+	RepositoryCountHeader  = "Hasir-Repository-Count"
+	RecentRepositoryHeader = "Hasir-Recent-Repository"
+)
+
+const recentRepositoriesLimit = 5
+
+// Organization.name is the immutable slug used in URLs, so the editable
+// display name travels in this header: on CreateOrganization and
+// UpdateOrganization requests, and on the GetOrganization response. An update
+// without it leaves the display name as it was.
+const DisplayNameHeader = "Hasir-Display-Name"
+
+// DefaultSdkHeader carries the SDKs new repositories of an organization start
+// with enabled, one SDK enum name per value. UpdateOrganization replaces them
+// when the header is sent, and an empty value clears them; the GetOrganization
+// response lists them.
+const DefaultSdkHeader = "Hasir-Default-Sdk"
+
+// IsInvitationValid returns Empty, so the acceptance page reads who sent the
+// invite and for which organization from these headers.
+const (
+	InviteOrganizationHeader = "Hasir-Invite-Organization"
+	InviteInviterHeader      = "Hasir-Invite-Inviter"
+)
+
+// IncludeMemberIdentityHeader asks GetMembers for each member's display name
+// and avatar URL, since Member has no fields for them. They come back as one
+// MemberDisplayNameHeader and one MemberAvatarUrlHeader value per member, in
+// the order of the members list.
+const (
+	IncludeMemberIdentityHeader = "Hasir-Include-Member-Identity"
+	MemberDisplayNameHeader     = "Hasir-Member-Display-Name"
+	MemberAvatarUrlHeader       = "Hasir-Member-Avatar-Url"
+)
+
 type handler struct {
 	interceptors       []connect.Interceptor
 	service            Service
 	repository         Repository
 	registryRepository registry.Repository
+	search             *searchCoalescer
 }
 
 func NewHandler(service Service, repository Repository, registryRepository registry.Repository, interceptors ...connect.Interceptor) *handler {
@@ -30,6 +74,7 @@ func NewHandler(service Service, repository Repository, registryRepository regis
 		service:            service,
 		repository:         repository,
 		registryRepository: registryRepository,
+		search:             newSearchCoalescer(repository.SearchItems, searchResultTtl),
 	}
 }
 
@@ -49,7 +94,7 @@ func (h *handler) CreateOrganization(
 		return nil, err
 	}
 
-	if err := h.service.CreateOrganization(ctx, req.Msg, createdBy); err != nil {
+	if err := h.service.CreateOrganization(ctx, req.Msg, req.Header().Get(DisplayNameHeader), createdBy); err != nil {
 		return nil, err
 	}
 
@@ -99,29 +144,19 @@ func (h *handler) GetOrganizations(
 	for _, org := range *organizations {
 		resp = append(resp, &organizationv1.Organization{
 			Id:   org.Id,
-			Name: org.Name,
+			Name: org.Slug,
 		})
 	}
 
-	totalPages := (totalCount + pageSize - 1) / pageSize
-	if totalPages == 0 {
-		totalPages = 1
-	}
-	if totalPages > math.MaxInt32 {
-		return nil, connect.NewError(connect.CodeInternal, errors.New("total pages exceeds maximum value"))
-	}
-	nextPage := int32(0)
-	if page < totalPages {
-		if page+1 > math.MaxInt32 {
-			return nil, connect.NewError(connect.CodeInternal, errors.New("page number exceeds maximum value"))
-		}
-		nextPage = int32(page + 1) // #nosec G115 -- bounds checked above
+	nextPage, totalPages, err := pagination.PaginateInt32(totalCount, page, pageSize)
+	if err != nil {
+		return nil, err
 	}
 
 	return connect.NewResponse(&organizationv1.GetOrganizationsResponse{
 		Organizations: resp,
 		NextPage:      nextPage,
-		TotalPage:     int32(totalPages), // #nosec G115 -- bounds checked above
+		TotalPage:     totalPages,
 	}), nil
 }
 
@@ -129,18 +164,49 @@ func (h *handler) GetOrganization(
 	ctx context.Context,
 	req *connect.Request[organizationv1.GetOrganizationRequest],
 ) (*connect.Response[organizationv1.GetOrganizationResponse], error) {
-	org, err := h.repository.GetOrganizationById(ctx, req.Msg.GetId())
+	userId, err := authentication.MustGetUserID(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	org, isMember, err := h.service.GetOrganization(ctx, req.Msg.GetId(), userId)
+	if err != nil {
+		return nil, err
+	}
+
+	// Non-members of a public organization only see its public repositories.
+	repositoryCount, err := h.registryRepository.GetOrganizationRepositoriesCount(ctx, org.Id, !isMember)
 	if err != nil {
 		return nil, err
 	}
 
-	return connect.NewResponse(&organizationv1.GetOrganizationResponse{
+	recentRepositories, err := h.registryRepository.GetRecentOrganizationRepositories(ctx, org.Id, !isMember, recentRepositoriesLimit)
+	if err != nil {
+		return nil, err
+	}
+
+	resp := connect.NewResponse(&organizationv1.GetOrganizationResponse{
 		Organization: &organizationv1.Organization{
 			Id:         org.Id,
-			Name:       org.Name,
+			Name:       org.Slug,
 			Visibility: proto.ReverseVisibilityMap[org.Visibility],
 		},
-	}), nil
+	})
+	defaultSdks, err := h.repository.GetDefaultSdks(ctx, org.Id)
+	if err != nil {
+		return nil, err
+	}
+
+	resp.Header().Set(DisplayNameHeader, org.DisplayName)
+	for _, sdk := range defaultSdks {
+		resp.Header().Add(DefaultSdkHeader, registry.SdkDbToProtoEnum[sdk].String())
+	}
+	resp.Header().Set(RepositoryCountHeader, strconv.Itoa(repositoryCount))
+	for _, repo := range *recentRepositories {
+		resp.Header().Add(RecentRepositoryHeader, repo.Name)
+	}
+
+	return resp, nil
 }
 
 func (h *handler) UpdateOrganization(
@@ -152,10 +218,27 @@ func (h *handler) UpdateOrganization(
 		return nil, err
 	}
 
-	if err := h.service.UpdateOrganization(ctx, req.Msg, userId); err != nil {
+	var displayName *string
+	if values := req.Header().Values(DisplayNameHeader); len(values) > 0 {
+		displayName = &values[0]
+	}
+
+	values := req.Header().Values(DefaultSdkHeader)
+	defaultSdks, err := parseDefaultSdks(values)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := h.service.UpdateOrganization(ctx, req.Msg, displayName, userId); err != nil {
 		return nil, err
 	}
 
+	if len(values) > 0 {
+		if err := h.service.SetDefaultSdks(ctx, req.Msg.GetId(), userId, defaultSdks); err != nil {
+			return nil, err
+		}
+	}
+
 	return connect.NewResponse(new(emptypb.Empty)), nil
 }
 
@@ -227,7 +310,7 @@ func (h *handler) IsInvitationValid(
 		return nil, err
 	}
 
-	invite, err := h.repository.GetInviteByToken(
+	invite, err := h.repository.GetInviteDetailsByToken(
 		ctx,
 		req.Msg.GetToken(),
 	)
@@ -239,13 +322,26 @@ func (h *handler) IsInvitationValid(
 		return nil, connect.NewError(connect.CodePermissionDenied, errors.New("this invitation is not for your email address"))
 	}
 
-	return connect.NewResponse(new(emptypb.Empty)), nil
+	resp := connect.NewResponse(new(emptypb.Empty))
+	resp.Header().Set(InviteOrganizationHeader, invite.OrganizationName)
+	resp.Header().Set(InviteInviterHeader, invite.InviterUsername)
+
+	return resp, nil
 }
 
 func (h *handler) GetMembers(
 	ctx context.Context,
 	req *connect.Request[organizationv1.GetMembersRequest],
 ) (*connect.Response[organizationv1.GetMembersResponse], error) {
+	var includeIdentity bool
+	if value := req.Header().Get(IncludeMemberIdentityHeader); value != "" {
+		include, err := strconv.ParseBool(value)
+		if err != nil {
+			return nil, connect.NewError(connect.CodeInvalidArgument, fmt.Errorf("invalid %s header: %q", IncludeMemberIdentityHeader, value))
+		}
+		includeIdentity = include
+	}
+
 	members, usernames, emails, err := h.repository.GetMembers(ctx, req.Msg.GetId())
 	if err != nil {
 		return nil, err
@@ -262,9 +358,18 @@ func (h *handler) GetMembers(
 		})
 	}
 
-	return connect.NewResponse(&organizationv1.GetMembersResponse{
+	res := connect.NewResponse(&organizationv1.GetMembersResponse{
 		Members: resp,
-	}), nil
+	})
+	if includeIdentity {
+		for i := range members {
+			identity := memberIdentity(usernames[i], emails[i])
+			res.Header().Add(MemberDisplayNameHeader, identity.DisplayName)
+			res.Header().Add(MemberAvatarUrlHeader, identity.AvatarUrl)
+		}
+	}
+
+	return res, nil
 }
 
 func (h *handler) UpdateMemberRole(
@@ -329,7 +434,7 @@ func (h *handler) Search(
 	}
 
 	query := req.Msg.GetQuery()
-	items, totalCount, err := h.repository.SearchItems(ctx, userId, query, page, pageSize)
+	items, totalCount, err := h.search.Search(ctx, userId, query, page, pageSize)
 	if err != nil {
 		return nil, err
 	}
@@ -352,25 +457,38 @@ func (h *handler) Search(
 		}
 	}
 
-	totalPages := (totalCount + pageSize - 1) / pageSize
-	if totalPages == 0 {
-		totalPages = 1
-	}
-	if totalPages > math.MaxInt32 {
-		return nil, connect.NewError(connect.CodeInternal, errors.New("total pages exceeds maximum value"))
-	}
-	nextPage := int32(0)
-	if page < totalPages {
-		if page+1 > math.MaxInt32 {
-			return nil, connect.NewError(connect.CodeInternal, errors.New("page number exceeds maximum value"))
-		}
-		nextPage = int32(page + 1) // #nosec G115 -- bounds checked above
+	nextPage, totalPages, err := pagination.PaginateInt32(totalCount, page, pageSize)
+	if err != nil {
+		return nil, err
 	}
 
 	return connect.NewResponse(&organizationv1.SearchResponse{
 		Organizations: respOrgs,
 		Repositories:  respRepos,
 		NextPage:      nextPage,
-		TotalPage:     int32(totalPages), // #nosec G115 -- bounds checked above
+		TotalPage:     totalPages,
 	}), nil
 }
+
+// parseDefaultSdks accepts the SDKs as separate values or comma-separated in
+// one, since browsers join repeated request headers.
+func parseDefaultSdks(values []string) ([]registry.SDK, error) {
+	var sdks []registry.SDK
+	for _, value := range values {
+		for _, name := range strings.Split(value, ",") {
+			name = strings.TrimSpace(name)
+			if name == "" {
+				continue
+			}
+
+			protoSdk, ok := registryv1.SDK_value[name]
+			sdk, known := registry.SdkProtoToDbEnum[registryv1.SDK(protoSdk)]
+			if !ok || !known {
+				return nil, connect.NewError(connect.CodeInvalidArgument, fmt.Errorf("invalid %s header: unknown SDK %q", DefaultSdkHeader, name))
+			}
+			sdks = append(sdks, sdk)
+		}
+	}
+
+	return sdks, nil
+}
@@ -111,8 +111,8 @@ func TestHandler_CreateOrganization(t *testing.T) {
 		testUserID := "test-user-123"
 
 		mockService.EXPECT().
-			CreateOrganization(gomock.Any(), gomock.Any(), testUserID).
-			DoAndReturn(func(_ context.Context, req *organizationv1.CreateOrganizationRequest, createdBy string) error {
+			CreateOrganization(gomock.Any(), gomock.Any(), "", testUserID).
+			DoAndReturn(func(_ context.Context, req *organizationv1.CreateOrganizationRequest, _ string, createdBy string) error {
 				assert.Equal(t, "test-org", req.GetName())
 				assert.Equal(t, shared.Visibility_VISIBILITY_PRIVATE, req.GetVisibility())
 				assert.Equal(t, testUserID, createdBy)
@@ -148,8 +148,8 @@ func TestHandler_CreateOrganization(t *testing.T) {
 		testUserID := "test-user-456"
 
 		mockService.EXPECT().
-			CreateOrganization(gomock.Any(), gomock.Any(), testUserID).
-			DoAndReturn(func(_ context.Context, req *organizationv1.CreateOrganizationRequest, createdBy string) error {
+			CreateOrganization(gomock.Any(), gomock.Any(), "", testUserID).
+			DoAndReturn(func(_ context.Context, req *organizationv1.CreateOrganizationRequest, _ string, createdBy string) error {
 				assert.Equal(t, "public-org", req.GetName())
 				assert.Equal(t, shared.Visibility_VISIBILITY_PUBLIC, req.GetVisibility())
 				return nil
@@ -184,7 +184,7 @@ func TestHandler_CreateOrganization(t *testing.T) {
 		testUserID := "test-user-789"
 
 		mockService.EXPECT().
-			CreateOrganization(gomock.Any(), gomock.Any(), testUserID).
+			CreateOrganization(gomock.Any(), gomock.Any(), "", testUserID).
 			Return(connect.NewError(connect.CodeAlreadyExists, errors.New("organization already exists")))
 
 		h := NewHandler(mockService, mockRepository, mockRegistryRepository, testAuthInterceptor(testUserID))
@@ -252,8 +252,8 @@ func TestHandler_GetOrganizations(t *testing.T) {
 		testUserID := "test-user-123"
 
 		orgs := &[]OrganizationDTO{
-			{Id: "org-1", Name: "first-org", Visibility: proto.VisibilityPrivate},
-			{Id: "org-2", Name: "second-org", Visibility: proto.VisibilityPublic},
+			{Id: "org-1", Slug: "first-org", Visibility: proto.VisibilityPrivate},
+			{Id: "org-2", Slug: "second-org", Visibility: proto.VisibilityPublic},
 		}
 
 		mockRepository.EXPECT().
@@ -526,8 +526,8 @@ func TestHandler_UpdateOrganization(t *testing.T) {
 		orgID := "org-123"
 
 		mockService.EXPECT().
-			UpdateOrganization(gomock.Any(), gomock.Any(), testUserID).
-			DoAndReturn(func(_ context.Context, req *organizationv1.UpdateOrganizationRequest, userId string) error {
+			UpdateOrganization(gomock.Any(), gomock.Any(), nil, testUserID).
+			DoAndReturn(func(_ context.Context, req *organizationv1.UpdateOrganizationRequest, _ *string, userId string) error {
 				assert.Equal(t, orgID, req.GetId())
 				assert.Equal(t, "updated-name", req.GetName())
 				assert.Equal(t, shared.Visibility_VISIBILITY_PUBLIC, req.GetVisibility())
@@ -556,6 +556,98 @@ func TestHandler_UpdateOrganization(t *testing.T) {
 		require.NoError(t, err)
 	})
 
+	t.Run("forwards display name header", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		mockService := NewMockService(ctrl)
+		mockRepository := NewMockRepository(ctrl)
+		mockRegistryRepository := registry.NewMockRepository(ctrl)
+
+		testUserID := "test-user-123"
+
+		mockService.EXPECT().
+			UpdateOrganization(gomock.Any(), gomock.Any(), gomock.Any(), testUserID).
+			DoAndReturn(func(_ context.Context, _ *organizationv1.UpdateOrganizationRequest, displayName *string, _ string) error {
+				require.NotNil(t, displayName)
+				assert.Equal(t, "Acme Corporation", *displayName)
+				return nil
+			})
+
+		h := NewHandler(mockService, mockRepository, mockRegistryRepository, testAuthInterceptor(testUserID))
+		mux := http.NewServeMux()
+		path, handler := h.RegisterRoutes()
+		mux.Handle(path, handler)
+
+		server := httptest.NewServer(mux)
+		defer server.Close()
+
+		client := organizationv1connect.NewOrganizationServiceClient(
+			http.DefaultClient,
+			server.URL,
+		)
+
+		req := connect.NewRequest(&organizationv1.UpdateOrganizationRequest{
+			Id:   "org-123",
+			Name: "acme",
+		})
+		req.Header().Set(DisplayNameHeader, "Acme Corporation")
+		_, err := client.UpdateOrganization(context.Background(), req)
+		require.NoError(t, err)
+	})
+
+	t.Run("default sdk header", func(t *testing.T) {
+		newClient := func(t *testing.T, mockService *MockService) organizationv1connect.OrganizationServiceClient {
+			ctrl := gomock.NewController(t)
+			h := NewHandler(mockService, NewMockRepository(ctrl), registry.NewMockRepository(ctrl), testAuthInterceptor("test-user-123"))
+			mux := http.NewServeMux()
+			path, handler := h.RegisterRoutes()
+			mux.Handle(path, handler)
+
+			server := httptest.NewServer(mux)
+			t.Cleanup(server.Close)
+
+			return organizationv1connect.NewOrganizationServiceClient(http.DefaultClient, server.URL)
+		}
+
+		tests := []struct {
+			name   string
+			values []string
+			want   []registry.SDK
+		}{
+			{name: "one value per sdk", values: []string{"SDK_GO_PROTOBUF", "SDK_JS_BUFBUILD_ES"}, want: []registry.SDK{registry.SdkGoProtobuf, registry.SdkJsBufbuildEs}},
+			{name: "comma separated", values: []string{"SDK_GO_PROTOBUF, SDK_JS_BUFBUILD_ES"}, want: []registry.SDK{registry.SdkGoProtobuf, registry.SdkJsBufbuildEs}},
+			{name: "empty value clears", values: []string{""}, want: nil},
+		}
+
+		for _, tt := range tests {
+			t.Run(tt.name, func(t *testing.T) {
+				mockService := NewMockService(gomock.NewController(t))
+				mockService.EXPECT().
+					UpdateOrganization(gomock.Any(), gomock.Any(), nil, "test-user-123").
+					Return(nil)
+				mockService.EXPECT().
+					SetDefaultSdks(gomock.Any(), "org-123", "test-user-123", tt.want).
+					Return(nil)
+
+				req := connect.NewRequest(&organizationv1.UpdateOrganizationRequest{Id: "org-123", Name: "acme"})
+				for _, value := range tt.values {
+					req.Header().Add(DefaultSdkHeader, value)
+				}
+				_, err := newClient(t, mockService).UpdateOrganization(context.Background(), req)
+				require.NoError(t, err)
+			})
+		}
+
+		t.Run("unknown sdk is rejected before updating", func(t *testing.T) {
+			mockService := NewMockService(gomock.NewController(t))
+
+			req := connect.NewRequest(&organizationv1.UpdateOrganizationRequest{Id: "org-123", Name: "acme"})
+			req.Header().Set(DefaultSdkHeader, "SDK_COBOL")
+			_, err := newClient(t, mockService).UpdateOrganization(context.Background(), req)
+			require.Error(t, err)
+			assert.Equal(t, connect.CodeInvalidArgument, connect.CodeOf(err))
+		})
+	})
+
 	t.Run("service error - permission denied", func(t *testing.T) {
 		ctrl := gomock.NewController(t)
 		mockService := NewMockService(ctrl)
@@ -566,7 +658,7 @@ func TestHandler_UpdateOrganization(t *testing.T) {
 		orgID := "org-456"
 
 		mockService.EXPECT().
-			UpdateOrganization(gomock.Any(), gomock.Any(), testUserID).
+			UpdateOrganization(gomock.Any(), gomock.Any(), nil, testUserID).
 			Return(connect.NewError(connect.CodePermissionDenied, errors.New("only the organization creator can update it")))
 
 		h := NewHandler(mockService, mockRepository, mockRegistryRepository, testAuthInterceptor(testUserID))
@@ -1538,15 +1630,19 @@ func TestHandler_IsInvitationValid(t *testing.T) {
 		userEmail := "user@example.com"
 		token := "valid-token-123"
 
-		invite := &OrganizationInviteDTO{
-			Id:             "invite-id",
-			OrganizationId: "org-123",
-			Email:          userEmail,
-			Token:          token,
+		invite := &OrganizationInviteDetailsDTO{
+			OrganizationInviteDTO: OrganizationInviteDTO{
+				Id:             "invite-id",
+				OrganizationId: "org-123",
+				Email:          userEmail,
+				Token:          token,
+			},
+			OrganizationName: "acme",
+			InviterUsername:  "alice",
 		}
 
 		mockRepository.EXPECT().
-			GetInviteByToken(gomock.Any(), token).
+			GetInviteDetailsByToken(gomock.Any(), token).
 			Return(invite, nil)
 
 		handler := NewHandler(mockService, mockRepository, mockRegistryRepository, testAuthInterceptorWithEmail("user-123", userEmail))
@@ -1563,10 +1659,12 @@ func TestHandler_IsInvitationValid(t *testing.T) {
 			server.URL,
 		)
 
-		_, err := client.IsInvitationValid(context.Background(), connect.NewRequest(&organizationv1.IsInvitationValidRequest{
+		resp, err := client.IsInvitationValid(context.Background(), connect.NewRequest(&organizationv1.IsInvitationValidRequest{
 			Token: token,
 		}))
 		require.NoError(t, err)
+		assert.Equal(t, "acme", resp.Header().Get(InviteOrganizationHeader))
+		assert.Equal(t, "alice", resp.Header().Get(InviteInviterHeader))
 	})
 
 	t.Run("permission denied - email does not match", func(t *testing.T) {
@@ -1578,15 +1676,19 @@ func TestHandler_IsInvitationValid(t *testing.T) {
 		userEmail := "different@example.com"
 		token := "valid-token-123"
 
-		invite := &OrganizationInviteDTO{
-			Id:             "invite-id",
-			OrganizationId: "org-123",
-			Email:          "invited@example.com",
-			Token:          token,
+		invite := &OrganizationInviteDetailsDTO{
+			OrganizationInviteDTO: OrganizationInviteDTO{
+				Id:             "invite-id",
+				OrganizationId: "org-123",
+				Email:          "invited@example.com",
+				Token:          token,
+			},
+			OrganizationName: "acme",
+			InviterUsername:  "alice",
 		}
 
 		mockRepository.EXPECT().
-			GetInviteByToken(gomock.Any(), token).
+			GetInviteDetailsByToken(gomock.Any(), token).
 			Return(invite, nil)
 
 		handler := NewHandler(mockService, mockRepository, mockRegistryRepository, testAuthInterceptorWithEmail("user-123", userEmail))
@@ -1622,7 +1724,7 @@ func TestHandler_IsInvitationValid(t *testing.T) {
 		token := "invalid-token"
 
 		mockRepository.EXPECT().
-			GetInviteByToken(gomock.Any(), token).
+			GetInviteDetailsByToken(gomock.Any(), token).
 			Return(nil, connect.NewError(connect.CodeNotFound, errors.New("invite not found")))
 
 		handler := NewHandler(mockService, mockRepository, mockRegistryRepository, testAuthInterceptorWithEmail("user-123", "user@example.com"))
@@ -1681,35 +1783,52 @@ func TestHandler_IsInvitationValid(t *testing.T) {
 }
 
 func TestHandler_GetOrganization(t *testing.T) {
+	newClient := func(t *testing.T, h *handler) organizationv1connect.OrganizationServiceClient {
+		t.Helper()
+
+		mux := http.NewServeMux()
+		path, handler := h.RegisterRoutes()
+		mux.Handle(path, handler)
+
+		server := httptest.NewServer(mux)
+		t.Cleanup(server.Close)
+
+		return organizationv1connect.NewOrganizationServiceClient(
+			http.DefaultClient,
+			server.URL,
+		)
+	}
+
 	t.Run("success", func(t *testing.T) {
 		ctrl := gomock.NewController(t)
 		mockService := NewMockService(ctrl)
 		mockRepository := NewMockRepository(ctrl)
 		mockRegistryRepository := registry.NewMockRepository(ctrl)
 
+		testUserID := "test-user-123"
 		orgID := "org-123"
 		orgDTO := &OrganizationDTO{
-			Id:         orgID,
-			Name:       "test-org",
-			Visibility: proto.VisibilityPrivate,
+			Id:          orgID,
+			Slug:        "test-org",
+			DisplayName: "test-org",
+			Visibility:  proto.VisibilityPrivate,
 		}
 
+		mockService.EXPECT().
+			GetOrganization(gomock.Any(), orgID, testUserID).
+			Return(orgDTO, true, nil)
+		mockRegistryRepository.EXPECT().
+			GetOrganizationRepositoriesCount(gomock.Any(), orgID, false).
+			Return(7, nil)
+		mockRegistryRepository.EXPECT().
+			GetRecentOrganizationRepositories(gomock.Any(), orgID, false, recentRepositoriesLimit).
+			Return(&[]registry.RepositoryDTO{{Name: "newest"}, {Name: "older"}}, nil)
 		mockRepository.EXPECT().
-			GetOrganizationById(gomock.Any(), orgID).
-			Return(orgDTO, nil)
+			GetDefaultSdks(gomock.Any(), orgID).
+			Return([]registry.SDK{registry.SdkGoProtobuf, registry.SdkJsBufbuildEs}, nil)
 
-		h := NewHandler(mockService, mockRepository, mockRegistryRepository)
-		mux := http.NewServeMux()
-		path, handler := h.RegisterRoutes()
-		mux.Handle(path, handler)
-
-		server := httptest.NewServer(mux)
-		defer server.Close()
-
-		client := organizationv1connect.NewOrganizationServiceClient(
-			http.DefaultClient,
-			server.URL,
-		)
+		h := NewHandler(mockService, mockRepository, mockRegistryRepository, testAuthInterceptor(testUserID))
+		client := newClient(t, h)
 
 		resp, err := client.GetOrganization(context.Background(), connect.NewRequest(&organizationv1.GetOrganizationRequest{
 			Id: orgID,
@@ -1719,32 +1838,59 @@ func TestHandler_GetOrganization(t *testing.T) {
 		assert.Equal(t, orgID, resp.Msg.GetOrganization().GetId())
 		assert.Equal(t, "test-org", resp.Msg.GetOrganization().GetName())
 		assert.Equal(t, shared.Visibility_VISIBILITY_PRIVATE, resp.Msg.GetOrganization().GetVisibility())
+		assert.Equal(t, "7", resp.Header().Get(RepositoryCountHeader))
+		assert.Equal(t, []string{"newest", "older"}, resp.Header().Values(RecentRepositoryHeader))
+		assert.Equal(t, []string{"SDK_GO_PROTOBUF", "SDK_JS_BUFBUILD_ES"}, resp.Header().Values(DefaultSdkHeader))
 	})
 
-	t.Run("organization not found", func(t *testing.T) {
+	t.Run("non-member only sees public repositories", func(t *testing.T) {
 		ctrl := gomock.NewController(t)
 		mockService := NewMockService(ctrl)
 		mockRepository := NewMockRepository(ctrl)
 		mockRegistryRepository := registry.NewMockRepository(ctrl)
 
-		orgID := "non-existent-org"
+		testUserID := "test-user-123"
+		orgID := "org-123"
 
+		mockService.EXPECT().
+			GetOrganization(gomock.Any(), orgID, testUserID).
+			Return(&OrganizationDTO{Id: orgID, Slug: "public-org", Visibility: proto.VisibilityPublic}, false, nil)
+		mockRegistryRepository.EXPECT().
+			GetOrganizationRepositoriesCount(gomock.Any(), orgID, true).
+			Return(0, nil)
+		mockRegistryRepository.EXPECT().
+			GetRecentOrganizationRepositories(gomock.Any(), orgID, true, recentRepositoriesLimit).
+			Return(&[]registry.RepositoryDTO{}, nil)
 		mockRepository.EXPECT().
-			GetOrganizationById(gomock.Any(), orgID).
-			Return(nil, ErrOrganizationNotFound)
+			GetDefaultSdks(gomock.Any(), orgID).
+			Return(nil, nil)
 
-		h := NewHandler(mockService, mockRepository, mockRegistryRepository)
-		mux := http.NewServeMux()
-		path, handler := h.RegisterRoutes()
-		mux.Handle(path, handler)
+		h := NewHandler(mockService, mockRepository, mockRegistryRepository, testAuthInterceptor(testUserID))
+		client := newClient(t, h)
 
-		server := httptest.NewServer(mux)
-		defer server.Close()
+		resp, err := client.GetOrganization(context.Background(), connect.NewRequest(&organizationv1.GetOrganizationRequest{
+			Id: orgID,
+		}))
+		require.NoError(t, err)
+		assert.Equal(t, "0", resp.Header().Get(RepositoryCountHeader))
+		assert.Empty(t, resp.Header().Values(RecentRepositoryHeader))
+	})
 
-		client := organizationv1connect.NewOrganizationServiceClient(
-			http.DefaultClient,
-			server.URL,
-		)
+	t.Run("organization not found", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		mockService := NewMockService(ctrl)
+		mockRepository := NewMockRepository(ctrl)
+		mockRegistryRepository := registry.NewMockRepository(ctrl)
+
+		testUserID := "test-user-123"
+		orgID := "deleted-org"
+
+		mockService.EXPECT().
+			GetOrganization(gomock.Any(), orgID, testUserID).
+			Return(nil, false, ErrOrganizationNotFound)
+
+		h := NewHandler(mockService, mockRepository, mockRegistryRepository, testAuthInterceptor(testUserID))
+		client := newClient(t, h)
 
 		_, err := client.GetOrganization(context.Background(), connect.NewRequest(&organizationv1.GetOrganizationRequest{
 			Id: orgID,
@@ -1755,6 +1901,19 @@ func TestHandler_GetOrganization(t *testing.T) {
 		require.True(t, errors.As(err, &connectErr))
 		assert.Equal(t, connect.CodeNotFound, connectErr.Code())
 	})
+
+	t.Run("unauthenticated", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+
+		h := NewHandler(NewMockService(ctrl), NewMockRepository(ctrl), registry.NewMockRepository(ctrl))
+		client := newClient(t, h)
+
+		_, err := client.GetOrganization(context.Background(), connect.NewRequest(&organizationv1.GetOrganizationRequest{
+			Id: "org-123",
+		}))
+		require.Error(t, err)
+		assert.Equal(t, connect.CodeUnauthenticated, connect.CodeOf(err))
+	})
 }
 
 func TestHandler_RespondToInvitation(t *testing.T) {
@@ -1973,6 +2132,80 @@ func TestHandler_GetMembers(t *testing.T) {
 		assert.Equal(t, shared.Role_ROLE_AUTHOR, resp.Msg.GetMembers()[1].GetRole())
 	})
 
+	t.Run("identity headers on request", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		mockService := NewMockService(ctrl)
+		mockRepository := NewMockRepository(ctrl)
+		mockRegistryRepository := registry.NewMockRepository(ctrl)
+
+		orgID := "org-123"
+		members := []*OrganizationMemberDTO{
+			{UserId: "user-1", Role: "owner"},
+			{UserId: "user-2", Role: "author"},
+		}
+		usernames := []string{"user1", ""}
+		emails := []string{"User1@Example.com", "myemailaddress@example.com"}
+
+		mockRepository.EXPECT().
+			GetMembers(gomock.Any(), orgID).
+			Return(members, usernames, emails, nil).
+			Times(2)
+
+		h := NewHandler(mockService, mockRepository, mockRegistryRepository)
+		mux := http.NewServeMux()
+		path, handler := h.RegisterRoutes()
+		mux.Handle(path, handler)
+
+		server := httptest.NewServer(mux)
+		defer server.Close()
+
+		client := organizationv1connect.NewOrganizationServiceClient(
+			http.DefaultClient,
+			server.URL,
+		)
+
+		req := connect.NewRequest(&organizationv1.GetMembersRequest{Id: orgID})
+		req.Header().Set(IncludeMemberIdentityHeader, "true")
+		resp, err := client.GetMembers(context.Background(), req)
+		require.NoError(t, err)
+		assert.Equal(t, []string{"user1", "myemailaddress"}, resp.Header().Values(MemberDisplayNameHeader))
+		assert.Equal(t, []string{
+			"https://www.gravatar.com/avatar/111d68d06e2d317b5a59c2c6c5bad808?d=identicon",
+			"https://www.gravatar.com/avatar/0bc83cb571cd1c50ba6f3e8a78ef1346?d=identicon",
+		}, resp.Header().Values(MemberAvatarUrlHeader))
+
+		resp, err = client.GetMembers(context.Background(), connect.NewRequest(&organizationv1.GetMembersRequest{Id: orgID}))
+		require.NoError(t, err)
+		assert.Empty(t, resp.Header().Values(MemberDisplayNameHeader))
+		assert.Empty(t, resp.Header().Values(MemberAvatarUrlHeader))
+	})
+
+	t.Run("invalid identity header", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		mockService := NewMockService(ctrl)
+		mockRepository := NewMockRepository(ctrl)
+		mockRegistryRepository := registry.NewMockRepository(ctrl)
+
+		h := NewHandler(mockService, mockRepository, mockRegistryRepository)
+		mux := http.NewServeMux()
+		path, handler := h.RegisterRoutes()
+		mux.Handle(path, handler)
+
+		server := httptest.NewServer(mux)
+		defer server.Close()
+
+		client := organizationv1connect.NewOrganizationServiceClient(
+			http.DefaultClient,
+			server.URL,
+		)
+
+		req := connect.NewRequest(&organizationv1.GetMembersRequest{Id: "org-123"})
+		req.Header().Set(IncludeMemberIdentityHeader, "sometimes")
+		_, err := client.GetMembers(context.Background(), req)
+		require.Error(t, err)
+		assert.Equal(t, connect.CodeInvalidArgument, connect.CodeOf(err))
+	})
+
 	t.Run("success with empty members", func(t *testing.T) {
 		ctrl := gomock.NewController(t)
 		mockService := NewMockService(ctrl)
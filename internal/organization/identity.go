@@ -0,0 +1,45 @@
+package organization
+
+import (
+	"crypto/md5" // #nosec G501 -- Gravatar addresses avatars by the MD5 of the email
+	"encoding/hex"
+	"net/url"
+	"strings"
+)
+
+const gravatarBaseUrl = "https://www.gravatar.com/avatar/"
+
+// MemberIdentity is derived from the stored username and email on every read;
+// none of it is persisted.
+type MemberIdentity struct {
+	DisplayName string
+	AvatarUrl   string
+}
+
+func memberIdentity(username, email string) MemberIdentity {
+	return MemberIdentity{
+		DisplayName: memberDisplayName(username, email),
+		AvatarUrl:   gravatarUrl(email),
+	}
+}
+
+// memberDisplayName falls back from the username to the local part of the
+// email, since users have no display name of their own.
+func memberDisplayName(username, email string) string {
+	if name := strings.TrimSpace(username); name != "" {
+		return name
+	}
+
+	localPart, _, _ := strings.Cut(strings.TrimSpace(email), "@")
+	return localPart
+}
+
+// gravatarUrl follows Gravatar's hashing rules: the trimmed, lowercased email.
+// Members without a Gravatar get a generated identicon instead of a 404.
+func gravatarUrl(email string) string {
+	// #nosec G401 -- not used for security, see the import
+	sum := md5.Sum([]byte(strings.ToLower(strings.TrimSpace(email))))
+
+	query := url.Values{"d": []string{"identicon"}}
+	return gravatarBaseUrl + hex.EncodeToString(sum[:]) + "?" + query.Encode()
+}
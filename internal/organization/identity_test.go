@@ -0,0 +1,63 @@
+package organization
+
+import (
+	"regexp"
+	"testing"
+)
+
+func TestGravatarUrl(t *testing.T) {
+	tests := []struct {
+		name  string
+		email string
+		want  string
+	}{
+		{
+			name:  "hashes the address",
+			email: "myemailaddress@example.com",
+			want:  "https://www.gravatar.com/avatar/0bc83cb571cd1c50ba6f3e8a78ef1346?d=identicon",
+		},
+		{
+			name:  "trims and lowercases before hashing",
+			email: "  MyEmailAddress@Example.com ",
+			want:  "https://www.gravatar.com/avatar/0bc83cb571cd1c50ba6f3e8a78ef1346?d=identicon",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := gravatarUrl(tt.email); got != tt.want {
+				t.Errorf("gravatarUrl(%q) = %q, want %q", tt.email, got, tt.want)
+			}
+		})
+	}
+
+	t.Run("hash is 32 lowercase hex characters", func(t *testing.T) {
+		pattern := regexp.MustCompile(`^https://www\.gravatar\.com/avatar/[0-9a-f]{32}\?d=identicon$`)
+		if got := gravatarUrl("user1@example.com"); !pattern.MatchString(got) {
+			t.Errorf("gravatarUrl returned %q", got)
+		}
+	})
+}
+
+func TestMemberDisplayName(t *testing.T) {
+	tests := []struct {
+		name     string
+		username string
+		email    string
+		want     string
+	}{
+		{name: "uses the username", username: "alice", email: "alice.smith@example.com", want: "alice"},
+		{name: "trims the username", username: "  alice ", email: "alice.smith@example.com", want: "alice"},
+		{name: "falls back to the email local part", username: "", email: "alice.smith@example.com", want: "alice.smith"},
+		{name: "blank username falls back too", username: "   ", email: "bob@example.com", want: "bob"},
+		{name: "nothing to fall back to", username: "", email: "", want: ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := memberDisplayName(tt.username, tt.email); got != tt.want {
+				t.Errorf("memberDisplayName(%q, %q) = %q, want %q", tt.username, tt.email, got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,86 @@
+package organization
+
+import (
+	"errors"
+	"fmt"
+	"net/mail"
+	"strings"
+
+	"connectrpc.com/connect"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+
+	organizationv1 "buf.build/gen/go/hasir/hasir/protocolbuffers/go/organization/v1"
+	"buf.build/gen/go/hasir/hasir/protocolbuffers/go/shared"
+)
+
+// validateInvitationMembers checks every entry of a bulk invite up front and
+// reports all bad emails and roles together, so nothing is written unless the
+// whole list is valid.
+func validateInvitationMembers(members []*organizationv1.InvitationMember) error {
+	var violations []*errdetails.BadRequest_FieldViolation
+	for i, member := range members {
+		violations = append(violations, inviteViolations(fmt.Sprintf("members[%d].", i), member.GetEmail(), member.GetRole())...)
+	}
+
+	return inviteValidationError(violations)
+}
+
+func validateInvite(emailAddress string, role shared.Role) error {
+	return inviteValidationError(inviteViolations("", emailAddress, role))
+}
+
+// inviteViolations accepts an unspecified role, which falls back to the
+// configured default invite role.
+func inviteViolations(fieldPrefix, emailAddress string, role shared.Role) []*errdetails.BadRequest_FieldViolation {
+	var violations []*errdetails.BadRequest_FieldViolation
+	if reason := invalidEmailReason(emailAddress); reason != "" {
+		violations = append(violations, &errdetails.BadRequest_FieldViolation{
+			Field:       fieldPrefix + "email",
+			Description: reason,
+		})
+	}
+
+	if _, ok := SharedRoleToMemberRoleMap[role]; !ok && role != shared.Role_ROLE_UNSPECIFIED {
+		violations = append(violations, &errdetails.BadRequest_FieldViolation{
+			Field:       fieldPrefix + "role",
+			Description: fmt.Sprintf("%s is not a role that can be invited", role),
+		})
+	}
+
+	return violations
+}
+
+// invalidEmailReason requires a bare RFC 5322 address, without a display name
+// or angle brackets.
+func invalidEmailReason(emailAddress string) string {
+	if emailAddress == "" {
+		return "email is required"
+	}
+
+	address, err := mail.ParseAddress(emailAddress)
+	if err != nil || address.Name != "" || address.Address != emailAddress {
+		return fmt.Sprintf("%q is not a valid email address", emailAddress)
+	}
+
+	return ""
+}
+
+// inviteValidationError carries the violations as a BadRequest detail and
+// also lists them in the message for clients that do not read details.
+func inviteValidationError(violations []*errdetails.BadRequest_FieldViolation) error {
+	if len(violations) == 0 {
+		return nil
+	}
+
+	messages := make([]string, len(violations))
+	for i, violation := range violations {
+		messages[i] = violation.GetField() + ": " + violation.GetDescription()
+	}
+
+	err := connect.NewError(connect.CodeInvalidArgument, errors.New("invalid invites: "+strings.Join(messages, "; ")))
+	if detail, detailErr := connect.NewErrorDetail(&errdetails.BadRequest{FieldViolations: violations}); detailErr == nil {
+		err.AddDetail(detail)
+	}
+
+	return err
+}
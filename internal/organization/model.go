@@ -8,13 +8,22 @@ import (
 	"hasir-api/pkg/proto"
 )
 
+// OrganizationDTO keeps the slug, which appears in URLs and clone paths and
+// never changes, apart from the display name owners can edit freely. The
+// proto name field carries the slug.
 type OrganizationDTO struct {
-	Id         string           `db:"id"`
-	Name       string           `db:"name"`
-	Visibility proto.Visibility `db:"visibility"`
-	CreatedBy  string           `db:"created_by"`
-	CreatedAt  time.Time        `db:"created_at"`
-	DeletedAt  *time.Time       `db:"deleted_at"`
+	Id          string           `db:"id"`
+	Slug        string           `db:"slug"`
+	DisplayName string           `db:"display_name"`
+	Visibility  proto.Visibility `db:"visibility"`
+	CreatedBy   string           `db:"created_by"`
+	CreatedAt   time.Time        `db:"created_at"`
+	DeletedAt   *time.Time       `db:"deleted_at"`
+}
+
+type OrganizationWithRoleDTO struct {
+	OrganizationDTO
+	Role *MemberRole `db:"role"`
 }
 
 type InviteStatus string
@@ -26,6 +35,21 @@ const (
 	InviteStatusCancelled InviteStatus = "cancelled"
 )
 
+// CanTransitionTo reports whether an invite may move from s to next. Only a
+// pending invite changes status; accepted, expired and cancelled are final.
+func (s InviteStatus) CanTransitionTo(next InviteStatus) bool {
+	if s != InviteStatusPending {
+		return false
+	}
+
+	switch next {
+	case InviteStatusAccepted, InviteStatusExpired, InviteStatusCancelled:
+		return true
+	default:
+		return false
+	}
+}
+
 type OrganizationInviteDTO struct {
 	Id             string       `db:"id"`
 	OrganizationId string       `db:"organization_id"`
@@ -39,6 +63,28 @@ type OrganizationInviteDTO struct {
 	AcceptedAt     *time.Time   `db:"accepted_at"`
 }
 
+// DeletedInviterUsername stands in for an inviter whose account has since
+// been deleted.
+const DeletedInviterUsername = "ghost"
+
+// OrganizationInviteDetailsDTO is an invite with what the acceptance page
+// shows: "InviterUsername invited you to OrganizationName".
+type OrganizationInviteDetailsDTO struct {
+	OrganizationInviteDTO
+	OrganizationName string `db:"organization_name"`
+	InviterUsername  string `db:"inviter_username"`
+}
+
+// OrganizationRepositoryDTO is the summary of a repository that goes into an
+// organization export.
+type OrganizationRepositoryDTO struct {
+	Id         string           `db:"id"`
+	Name       string           `db:"name"`
+	Visibility proto.Visibility `db:"visibility"`
+	Archived   bool             `db:"archived"`
+	CreatedAt  time.Time        `db:"created_at"`
+}
+
 type MemberRole string
 
 const (
@@ -47,12 +93,22 @@ const (
 	MemberRoleOwner  MemberRole = "owner"
 )
 
+// JoinedVia records how a member came to be in an organization.
+type JoinedVia string
+
+const (
+	JoinedViaInvite   JoinedVia = "invite"
+	JoinedViaDirect   JoinedVia = "direct"
+	JoinedViaTransfer JoinedVia = "transfer"
+)
+
 type OrganizationMemberDTO struct {
 	Id             string     `json:"id" db:"id"`
 	OrganizationId string     `json:"organization_id" db:"organization_id"`
 	UserId         string     `json:"user_id" db:"user_id"`
 	Role           MemberRole `json:"role" db:"role"`
 	JoinedAt       time.Time  `json:"joined_at" db:"joined_at"`
+	JoinedVia      JoinedVia  `json:"joined_via" db:"joined_via"`
 }
 
 type EmailJobStatus string
@@ -62,6 +118,7 @@ const (
 	EmailJobStatusProcessing EmailJobStatus = "processing"
 	EmailJobStatusCompleted  EmailJobStatus = "completed"
 	EmailJobStatusFailed     EmailJobStatus = "failed"
+	EmailJobStatusCancelled  EmailJobStatus = "cancelled"
 )
 
 var SharedRoleToMemberRoleMap = map[shared.Role]MemberRole{
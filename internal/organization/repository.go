@@ -3,26 +3,39 @@ package organization
 import (
 	"context"
 	"time"
+
+	"hasir-api/internal/registry"
 )
 
 type Repository interface {
 	CreateOrganization(ctx context.Context, org *OrganizationDTO) error
+	CreateOrganizationWithOwner(ctx context.Context, org *OrganizationDTO, owner *OrganizationMemberDTO) error
 	GetOrganizations(ctx context.Context, page, pageSize int) (*[]OrganizationDTO, error)
 	GetOrganizationsCount(ctx context.Context) (int, error)
 	GetUserOrganizations(ctx context.Context, userId string, page, pageSize int) (*[]OrganizationDTO, error)
 	GetUserOrganizationsCount(ctx context.Context, userId string) (int, error)
 	GetOrganizationByName(ctx context.Context, name string) (*OrganizationDTO, error)
+	GetOrganizationByNameWithRole(ctx context.Context, name, userId string) (*OrganizationWithRoleDTO, error)
 	GetOrganizationById(ctx context.Context, id string) (*OrganizationDTO, error)
 	UpdateOrganization(ctx context.Context, org *OrganizationDTO) error
 	DeleteOrganization(ctx context.Context, id string) error
 	CreateInvites(ctx context.Context, invites []*OrganizationInviteDTO) error
 	GetInviteByToken(ctx context.Context, token string) (*OrganizationInviteDTO, error)
+	GetInviteDetailsByToken(ctx context.Context, token string) (*OrganizationInviteDetailsDTO, error)
+	GetInvitesForEmail(ctx context.Context, email string) ([]OrganizationInviteDetailsDTO, error)
+	GetPendingInvites(ctx context.Context, organizationId string) ([]OrganizationInviteDetailsDTO, error)
 	UpdateInviteStatus(ctx context.Context, id string, status InviteStatus, acceptedAt *time.Time) error
 	AddMember(ctx context.Context, member *OrganizationMemberDTO) error
 	GetMembers(ctx context.Context, organizationId string) ([]*OrganizationMemberDTO, []string, []string, error)
 	GetMemberRole(ctx context.Context, organizationId, userId string) (MemberRole, error)
 	GetOwnerCount(ctx context.Context, organizationId string) (int, error)
+	GetMembersCount(ctx context.Context, organizationId string) (int, error)
 	UpdateMemberRole(ctx context.Context, organizationId, userId string, role MemberRole) error
+	UpdateMemberRoles(ctx context.Context, organizationId string, changes map[string]MemberRole) error
 	DeleteMember(ctx context.Context, organizationId, userId string) error
 	SearchItems(ctx context.Context, userId, query string, page, pageSize int) (*[]SearchItemDTO, int, error)
+	SearchSuggestions(ctx context.Context, userId, prefix string, limit int) ([]SearchItemDTO, error)
+	GetDefaultSdks(ctx context.Context, organizationId string) ([]registry.SDK, error)
+	GetOrganizationRepositories(ctx context.Context, organizationId string) ([]OrganizationRepositoryDTO, error)
+	SetDefaultSdks(ctx context.Context, organizationId string, sdks []registry.SDK) error
 }
@@ -15,6 +15,8 @@ import (
 	time "time"
 
 	gomock "go.uber.org/mock/gomock"
+
+	registry "hasir-api/internal/registry"
 )
 
 // MockRepository is a mock of Repository interface.
@@ -83,6 +85,20 @@ func (mr *MockRepositoryMockRecorder) CreateOrganization(ctx, org any) *gomock.C
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateOrganization", reflect.TypeOf((*MockRepository)(nil).CreateOrganization), ctx, org)
 }
 
+// CreateOrganizationWithOwner mocks base method.
+func (m *MockRepository) CreateOrganizationWithOwner(ctx context.Context, org *OrganizationDTO, owner *OrganizationMemberDTO) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateOrganizationWithOwner", ctx, org, owner)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// CreateOrganizationWithOwner indicates an expected call of CreateOrganizationWithOwner.
+func (mr *MockRepositoryMockRecorder) CreateOrganizationWithOwner(ctx, org, owner any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateOrganizationWithOwner", reflect.TypeOf((*MockRepository)(nil).CreateOrganizationWithOwner), ctx, org, owner)
+}
+
 // DeleteMember mocks base method.
 func (m *MockRepository) DeleteMember(ctx context.Context, organizationId, userId string) error {
 	m.ctrl.T.Helper()
@@ -111,6 +127,21 @@ func (mr *MockRepositoryMockRecorder) DeleteOrganization(ctx, id any) *gomock.Ca
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteOrganization", reflect.TypeOf((*MockRepository)(nil).DeleteOrganization), ctx, id)
 }
 
+// GetDefaultSdks mocks base method.
+func (m *MockRepository) GetDefaultSdks(ctx context.Context, organizationId string) ([]registry.SDK, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetDefaultSdks", ctx, organizationId)
+	ret0, _ := ret[0].([]registry.SDK)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetDefaultSdks indicates an expected call of GetDefaultSdks.
+func (mr *MockRepositoryMockRecorder) GetDefaultSdks(ctx, organizationId any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetDefaultSdks", reflect.TypeOf((*MockRepository)(nil).GetDefaultSdks), ctx, organizationId)
+}
+
 // GetInviteByToken mocks base method.
 func (m *MockRepository) GetInviteByToken(ctx context.Context, token string) (*OrganizationInviteDTO, error) {
 	m.ctrl.T.Helper()
@@ -126,6 +157,36 @@ func (mr *MockRepositoryMockRecorder) GetInviteByToken(ctx, token any) *gomock.C
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetInviteByToken", reflect.TypeOf((*MockRepository)(nil).GetInviteByToken), ctx, token)
 }
 
+// GetInviteDetailsByToken mocks base method.
+func (m *MockRepository) GetInviteDetailsByToken(ctx context.Context, token string) (*OrganizationInviteDetailsDTO, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetInviteDetailsByToken", ctx, token)
+	ret0, _ := ret[0].(*OrganizationInviteDetailsDTO)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetInviteDetailsByToken indicates an expected call of GetInviteDetailsByToken.
+func (mr *MockRepositoryMockRecorder) GetInviteDetailsByToken(ctx, token any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetInviteDetailsByToken", reflect.TypeOf((*MockRepository)(nil).GetInviteDetailsByToken), ctx, token)
+}
+
+// GetInvitesForEmail mocks base method.
+func (m *MockRepository) GetInvitesForEmail(ctx context.Context, email string) ([]OrganizationInviteDetailsDTO, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetInvitesForEmail", ctx, email)
+	ret0, _ := ret[0].([]OrganizationInviteDetailsDTO)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetInvitesForEmail indicates an expected call of GetInvitesForEmail.
+func (mr *MockRepositoryMockRecorder) GetInvitesForEmail(ctx, email any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetInvitesForEmail", reflect.TypeOf((*MockRepository)(nil).GetInvitesForEmail), ctx, email)
+}
+
 // GetMemberRole mocks base method.
 func (m *MockRepository) GetMemberRole(ctx context.Context, organizationId, userId string) (MemberRole, error) {
 	m.ctrl.T.Helper()
@@ -158,6 +219,21 @@ func (mr *MockRepositoryMockRecorder) GetMembers(ctx, organizationId any) *gomoc
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetMembers", reflect.TypeOf((*MockRepository)(nil).GetMembers), ctx, organizationId)
 }
 
+// GetMembersCount mocks base method.
+func (m *MockRepository) GetMembersCount(ctx context.Context, organizationId string) (int, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetMembersCount", ctx, organizationId)
+	ret0, _ := ret[0].(int)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetMembersCount indicates an expected call of GetMembersCount.
+func (mr *MockRepositoryMockRecorder) GetMembersCount(ctx, organizationId any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetMembersCount", reflect.TypeOf((*MockRepository)(nil).GetMembersCount), ctx, organizationId)
+}
+
 // GetOrganizationById mocks base method.
 func (m *MockRepository) GetOrganizationById(ctx context.Context, id string) (*OrganizationDTO, error) {
 	m.ctrl.T.Helper()
@@ -188,6 +264,36 @@ func (mr *MockRepositoryMockRecorder) GetOrganizationByName(ctx, name any) *gomo
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetOrganizationByName", reflect.TypeOf((*MockRepository)(nil).GetOrganizationByName), ctx, name)
 }
 
+// GetOrganizationByNameWithRole mocks base method.
+func (m *MockRepository) GetOrganizationByNameWithRole(ctx context.Context, name, userId string) (*OrganizationWithRoleDTO, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetOrganizationByNameWithRole", ctx, name, userId)
+	ret0, _ := ret[0].(*OrganizationWithRoleDTO)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetOrganizationByNameWithRole indicates an expected call of GetOrganizationByNameWithRole.
+func (mr *MockRepositoryMockRecorder) GetOrganizationByNameWithRole(ctx, name, userId any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetOrganizationByNameWithRole", reflect.TypeOf((*MockRepository)(nil).GetOrganizationByNameWithRole), ctx, name, userId)
+}
+
+// GetOrganizationRepositories mocks base method.
+func (m *MockRepository) GetOrganizationRepositories(ctx context.Context, organizationId string) ([]OrganizationRepositoryDTO, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetOrganizationRepositories", ctx, organizationId)
+	ret0, _ := ret[0].([]OrganizationRepositoryDTO)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetOrganizationRepositories indicates an expected call of GetOrganizationRepositories.
+func (mr *MockRepositoryMockRecorder) GetOrganizationRepositories(ctx, organizationId any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetOrganizationRepositories", reflect.TypeOf((*MockRepository)(nil).GetOrganizationRepositories), ctx, organizationId)
+}
+
 // GetOrganizations mocks base method.
 func (m *MockRepository) GetOrganizations(ctx context.Context, page, pageSize int) (*[]OrganizationDTO, error) {
 	m.ctrl.T.Helper()
@@ -233,6 +339,21 @@ func (mr *MockRepositoryMockRecorder) GetOwnerCount(ctx, organizationId any) *go
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetOwnerCount", reflect.TypeOf((*MockRepository)(nil).GetOwnerCount), ctx, organizationId)
 }
 
+// GetPendingInvites mocks base method.
+func (m *MockRepository) GetPendingInvites(ctx context.Context, organizationId string) ([]OrganizationInviteDetailsDTO, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetPendingInvites", ctx, organizationId)
+	ret0, _ := ret[0].([]OrganizationInviteDetailsDTO)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetPendingInvites indicates an expected call of GetPendingInvites.
+func (mr *MockRepositoryMockRecorder) GetPendingInvites(ctx, organizationId any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetPendingInvites", reflect.TypeOf((*MockRepository)(nil).GetPendingInvites), ctx, organizationId)
+}
+
 // GetUserOrganizations mocks base method.
 func (m *MockRepository) GetUserOrganizations(ctx context.Context, userId string, page, pageSize int) (*[]OrganizationDTO, error) {
 	m.ctrl.T.Helper()
@@ -279,6 +400,35 @@ func (mr *MockRepositoryMockRecorder) SearchItems(ctx, userId, query, page, page
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SearchItems", reflect.TypeOf((*MockRepository)(nil).SearchItems), ctx, userId, query, page, pageSize)
 }
 
+// SearchSuggestions mocks base method.
+func (m *MockRepository) SearchSuggestions(ctx context.Context, userId, prefix string, limit int) ([]SearchItemDTO, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SearchSuggestions", ctx, userId, prefix, limit)
+	ret0, _ := ret[0].([]SearchItemDTO)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// SearchSuggestions indicates an expected call of SearchSuggestions.
+func (mr *MockRepositoryMockRecorder) SearchSuggestions(ctx, userId, prefix, limit any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SearchSuggestions", reflect.TypeOf((*MockRepository)(nil).SearchSuggestions), ctx, userId, prefix, limit)
+}
+
+// SetDefaultSdks mocks base method.
+func (m *MockRepository) SetDefaultSdks(ctx context.Context, organizationId string, sdks []registry.SDK) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SetDefaultSdks", ctx, organizationId, sdks)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SetDefaultSdks indicates an expected call of SetDefaultSdks.
+func (mr *MockRepositoryMockRecorder) SetDefaultSdks(ctx, organizationId, sdks any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetDefaultSdks", reflect.TypeOf((*MockRepository)(nil).SetDefaultSdks), ctx, organizationId, sdks)
+}
+
 // UpdateInviteStatus mocks base method.
 func (m *MockRepository) UpdateInviteStatus(ctx context.Context, id string, status InviteStatus, acceptedAt *time.Time) error {
 	m.ctrl.T.Helper()
@@ -307,6 +457,20 @@ func (mr *MockRepositoryMockRecorder) UpdateMemberRole(ctx, organizationId, user
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateMemberRole", reflect.TypeOf((*MockRepository)(nil).UpdateMemberRole), ctx, organizationId, userId, role)
 }
 
+// UpdateMemberRoles mocks base method.
+func (m *MockRepository) UpdateMemberRoles(ctx context.Context, organizationId string, changes map[string]MemberRole) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateMemberRoles", ctx, organizationId, changes)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpdateMemberRoles indicates an expected call of UpdateMemberRoles.
+func (mr *MockRepositoryMockRecorder) UpdateMemberRoles(ctx, organizationId, changes any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateMemberRoles", reflect.TypeOf((*MockRepository)(nil).UpdateMemberRoles), ctx, organizationId, changes)
+}
+
 // UpdateOrganization mocks base method.
 func (m *MockRepository) UpdateOrganization(ctx context.Context, org *OrganizationDTO) error {
 	m.ctrl.T.Helper()
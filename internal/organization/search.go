@@ -0,0 +1,104 @@
+package organization
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"golang.org/x/sync/singleflight"
+)
+
+// searchResultTtl is how long a search result is reused. It only has to
+// outlive a burst of identical requests, so staleness stays unnoticeable.
+const searchResultTtl = 2 * time.Second
+
+type searchFunc func(ctx context.Context, userId, query string, page, pageSize int) (*[]SearchItemDTO, int, error)
+
+type searchResult struct {
+	items      *[]SearchItemDTO
+	totalCount int
+	expiresAt  time.Time
+}
+
+// searchCoalescer lets concurrent identical searches share one database
+// query and briefly reuses the result. Results depend on the memberships of
+// the user, so the user is part of the key and never shared.
+type searchCoalescer struct {
+	search searchFunc
+	ttl    time.Duration
+	now    func() time.Time
+	group  singleflight.Group
+
+	mu      sync.Mutex
+	results map[string]searchResult
+}
+
+func newSearchCoalescer(search searchFunc, ttl time.Duration) *searchCoalescer {
+	return &searchCoalescer{
+		search:  search,
+		ttl:     ttl,
+		now:     time.Now,
+		results: make(map[string]searchResult),
+	}
+}
+
+func (c *searchCoalescer) Search(ctx context.Context, userId, query string, page, pageSize int) (*[]SearchItemDTO, int, error) {
+	key := fmt.Sprintf("%s\x00%s\x00%d\x00%d", userId, query, page, pageSize)
+	if result, ok := c.cached(key); ok {
+		return result.items, result.totalCount, nil
+	}
+
+	// The shared query must not fail because the caller that happened to
+	// start it went away, so it runs detached from that caller's
+	// cancellation; every caller still stops waiting on its own context.
+	resultCh := c.group.DoChan(key, func() (any, error) {
+		items, totalCount, err := c.search(context.WithoutCancel(ctx), userId, query, page, pageSize)
+		if err != nil {
+			return nil, err
+		}
+
+		result := searchResult{items: items, totalCount: totalCount, expiresAt: c.now().Add(c.ttl)}
+		c.store(key, result)
+		return result, nil
+	})
+
+	select {
+	case <-ctx.Done():
+		return nil, 0, ctx.Err()
+	case res := <-resultCh:
+		if res.Err != nil {
+			return nil, 0, res.Err
+		}
+		result := res.Val.(searchResult)
+		return result.items, result.totalCount, nil
+	}
+}
+
+func (c *searchCoalescer) cached(key string) (searchResult, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	result, ok := c.results[key]
+	if !ok || !c.now().Before(result.expiresAt) {
+		return searchResult{}, false
+	}
+
+	return result, true
+}
+
+// store also drops expired results, which keeps the map as small as the set
+// of searches made within the last ttl.
+func (c *searchCoalescer) store(key string, result searchResult) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	now := c.now()
+	for k, r := range c.results {
+		if !now.Before(r.expiresAt) {
+			delete(c.results, k)
+		}
+	}
+
+	c.results[key] = result
+}
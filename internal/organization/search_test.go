@@ -0,0 +1,119 @@
+package organization
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestSearchCoalescer(t *testing.T) {
+	t.Run("concurrent identical searches run one query", func(t *testing.T) {
+		var calls atomic.Int32
+		release := make(chan struct{})
+		coalescer := newSearchCoalescer(func(ctx context.Context, userId, query string, page, pageSize int) (*[]SearchItemDTO, int, error) {
+			calls.Add(1)
+			<-release
+			return &[]SearchItemDTO{{Id: "org-1", Name: query}}, 1, nil
+		}, time.Minute)
+
+		const searches = 20
+		var wg sync.WaitGroup
+		errs := make(chan error, searches)
+		for range searches {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				items, totalCount, err := coalescer.Search(context.Background(), "user-1", "popular", 1, 10)
+				if err == nil && (totalCount != 1 || len(*items) != 1) {
+					err = errors.New("unexpected result")
+				}
+				errs <- err
+			}()
+		}
+
+		for calls.Load() == 0 {
+			time.Sleep(time.Millisecond)
+		}
+		time.Sleep(20 * time.Millisecond)
+		close(release)
+		wg.Wait()
+		close(errs)
+
+		for err := range errs {
+			if err != nil {
+				t.Fatalf("expected no error, got %v", err)
+			}
+		}
+		if got := calls.Load(); got != 1 {
+			t.Errorf("expected 1 query, got %d", got)
+		}
+	})
+
+	t.Run("different users do not share results", func(t *testing.T) {
+		var calls atomic.Int32
+		coalescer := newSearchCoalescer(func(ctx context.Context, userId, query string, page, pageSize int) (*[]SearchItemDTO, int, error) {
+			calls.Add(1)
+			return &[]SearchItemDTO{{Id: userId}}, 1, nil
+		}, time.Minute)
+
+		for _, userId := range []string{"user-1", "user-2", "user-1"} {
+			items, _, err := coalescer.Search(context.Background(), userId, "popular", 1, 10)
+			if err != nil {
+				t.Fatalf("expected no error, got %v", err)
+			}
+			if (*items)[0].Id != userId {
+				t.Errorf("expected the results of %s, got those of %s", userId, (*items)[0].Id)
+			}
+		}
+
+		if got := calls.Load(); got != 2 {
+			t.Errorf("expected 2 queries, got %d", got)
+		}
+	})
+
+	t.Run("results expire", func(t *testing.T) {
+		var calls atomic.Int32
+		coalescer := newSearchCoalescer(func(ctx context.Context, userId, query string, page, pageSize int) (*[]SearchItemDTO, int, error) {
+			calls.Add(1)
+			return &[]SearchItemDTO{}, 0, nil
+		}, time.Second)
+		now := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+		coalescer.now = func() time.Time { return now }
+
+		search := func() {
+			if _, _, err := coalescer.Search(context.Background(), "user-1", "popular", 2, 10); err != nil {
+				t.Fatalf("expected no error, got %v", err)
+			}
+		}
+
+		search()
+		search()
+		now = now.Add(time.Second)
+		search()
+
+		if got := calls.Load(); got != 2 {
+			t.Errorf("expected 2 queries, got %d", got)
+		}
+	})
+
+	t.Run("errors are not cached", func(t *testing.T) {
+		var calls atomic.Int32
+		coalescer := newSearchCoalescer(func(ctx context.Context, userId, query string, page, pageSize int) (*[]SearchItemDTO, int, error) {
+			calls.Add(1)
+			return nil, 0, errors.New("database unavailable")
+		}, time.Minute)
+
+		for range 2 {
+			if _, _, err := coalescer.Search(context.Background(), "user-1", "popular", 1, 10); err == nil {
+				t.Fatal("expected an error")
+			}
+		}
+
+		if got := calls.Load(); got != 2 {
+			t.Errorf("expected 2 queries, got %d", got)
+		}
+	})
+}
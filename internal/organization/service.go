@@ -5,17 +5,26 @@ import (
 	"crypto/rand"
 	"encoding/hex"
 	"errors"
+	"fmt"
+	"slices"
+	"strings"
 	"time"
+	"unicode"
+	"unicode/utf8"
 
 	"connectrpc.com/connect"
 	"github.com/google/uuid"
 	"go.uber.org/zap"
 
 	organizationv1 "buf.build/gen/go/hasir/hasir/protocolbuffers/go/organization/v1"
+	"buf.build/gen/go/hasir/hasir/protocolbuffers/go/shared"
 
 	"hasir-api/internal/registry"
 	"hasir-api/internal/user"
+	"hasir-api/pkg/authentication"
+	"hasir-api/pkg/config"
 	"hasir-api/pkg/email"
+	"hasir-api/pkg/naming"
 	"hasir-api/pkg/proto"
 )
 
@@ -26,19 +35,38 @@ const (
 	errOnlyOwnersCanDelete   = "only organization owners can delete the organization"
 	errOnlyOwnersCanManage   = "only organization owners can update member roles"
 	errOnlyOwnersCanRemove   = "only organization owners can delete members"
+	errOnlyOwnersCanSetSdks  = "only organization owners can change the default SDKs"
 	errCannotModifyLastOwner = "cannot delete the last owner"
 	errCannotChangeLastOwner = "cannot change role of the last owner"
+	errBatchRemovesAllOwners = "role changes would leave the organization without an owner"
+	errMemberLimitReached    = "organization has reached its member limit of %d"
+	errSlugImmutable         = "organization name cannot be changed once created"
+	errTooManyInvites        = "at most %d invitations can be sent in one request, got %d"
 )
 
+const maxDisplayNameLength = 255
+
 type Service interface {
 	CreateOrganization(
 		ctx context.Context,
 		req *organizationv1.CreateOrganizationRequest,
+		displayName string,
 		createdBy string,
 	) error
+	GetOrganizationByNameWithRole(
+		ctx context.Context,
+		name string,
+		userId string,
+	) (*OrganizationDTO, *MemberRole, error)
+	GetOrganization(
+		ctx context.Context,
+		organizationId string,
+		userId string,
+	) (*OrganizationDTO, bool, error)
 	UpdateOrganization(
 		ctx context.Context,
 		req *organizationv1.UpdateOrganizationRequest,
+		displayName *string,
 		userId string,
 	) error
 	DeleteOrganization(
@@ -63,11 +91,26 @@ type Service interface {
 		req *organizationv1.UpdateMemberRoleRequest,
 		updatedBy string,
 	) error
+	UpdateMemberRolesBulk(
+		ctx context.Context,
+		organizationId string,
+		changes map[string]MemberRole,
+		updatedBy string,
+	) error
 	DeleteMember(
 		ctx context.Context,
 		req *organizationv1.DeleteMemberRequest,
 		deletedBy string,
 	) error
+	SearchSuggestions(
+		ctx context.Context,
+		userId string,
+		prefix string,
+		limit int,
+	) ([]SearchItemDTO, error)
+	GetInvitesForEmail(ctx context.Context, email string) ([]OrganizationInviteDetailsDTO, error)
+	SetDefaultSdks(ctx context.Context, organizationId, userId string, sdks []registry.SDK) error
+	ExportOrganization(ctx context.Context, organizationId string) ([]byte, error)
 }
 
 type inviteInfo struct {
@@ -81,15 +124,58 @@ type service struct {
 	emailService    email.Service
 	registryService registry.Service
 	userRepository  user.Repository
+	maxMembers      int
+	inviteTTL       time.Duration
+	reservedNames   *naming.ReservedNames
+	// defaultInviteRole applies to invites whose request leaves the role
+	// unspecified.
+	defaultInviteRole MemberRole
+	// maxInvitesPerRequest bounds how many invites, and so emails, a single
+	// request can create.
+	maxInvitesPerRequest int
+	sdkGeneration        config.SdkGenerationConfig
 }
 
-func NewService(repository Repository, queue Queue, registryService registry.Service, emailService email.Service, userRepository user.Repository) Service {
+func NewService(
+	repository Repository,
+	queue Queue,
+	registryService registry.Service,
+	emailService email.Service,
+	userRepository user.Repository,
+	cfg *config.Config,
+) Service {
+	var maxMembers int
+	var reservedNames []string
+	inviteTTL := config.DefaultInviteTTL
+	defaultInviteRole := MemberRoleAuthor
+	maxInvitesPerRequest := config.DefaultMaxInvitesPerRequest
+	var sdkGeneration config.SdkGenerationConfig
+	if cfg != nil {
+		maxMembers = cfg.Organization.MaxMembers
+		defaultInviteRole = MemberRole(cfg.Organization.GetDefaultInviteRole())
+		maxInvitesPerRequest = cfg.Organization.GetMaxInvitesPerRequest()
+		// An unparsable value or unreadable file is already rejected by
+		// config.Validate at startup.
+		if ttl, err := cfg.Organization.GetInviteTTL(); err == nil && ttl > 0 {
+			inviteTTL = ttl
+		}
+		reservedNames, _ = cfg.GetReservedNames()
+		sdkGeneration = cfg.SdkGeneration
+	}
+
 	return &service{
 		repository:      repository,
 		queue:           queue,
 		emailService:    emailService,
 		registryService: registryService,
 		userRepository:  userRepository,
+		maxMembers:      maxMembers,
+		inviteTTL:       inviteTTL,
+		reservedNames:   naming.NewReservedNames(reservedNames),
+
+		defaultInviteRole:    defaultInviteRole,
+		maxInvitesPerRequest: maxInvitesPerRequest,
+		sdkGeneration:        sdkGeneration,
 	}
 }
 
@@ -127,12 +213,54 @@ func (s *service) ensureNotLastOwner(ctx context.Context, organizationId string,
 	return nil
 }
 
+func (s *service) ensureMemberCapacity(ctx context.Context, organizationId string, additional int) error {
+	if s.maxMembers <= 0 {
+		return nil
+	}
+
+	memberCount, err := s.repository.GetMembersCount(ctx, organizationId)
+	if err != nil {
+		return err
+	}
+
+	if memberCount+additional > s.maxMembers {
+		return connect.NewError(connect.CodeFailedPrecondition, fmt.Errorf(errMemberLimitReached, s.maxMembers))
+	}
+
+	return nil
+}
+
 func (s *service) CreateOrganization(
 	ctx context.Context,
 	req *organizationv1.CreateOrganizationRequest,
+	displayName string,
 	createdBy string,
 ) error {
-	existingOrg, err := s.repository.GetOrganizationByName(ctx, req.GetName())
+	// Slugs are stored lower case so that names differing only in case cannot
+	// coexist; the name as typed stays the default display name.
+	slug := strings.ToLower(req.GetName())
+	if err := s.reservedNames.ValidateName(slug); err != nil {
+		return err
+	}
+
+	// Checked before anything is written, so an oversized request does not
+	// leave behind an organization without its invites.
+	if err := s.checkInviteBatchSize(len(req.GetMembers())); err != nil {
+		return err
+	}
+	if err := validateInvitationMembers(req.GetMembers()); err != nil {
+		return err
+	}
+
+	if displayName == "" {
+		displayName = req.GetName()
+	}
+	displayName, err := normalizeDisplayName(displayName)
+	if err != nil {
+		return err
+	}
+
+	existingOrg, err := s.repository.GetOrganizationByName(ctx, slug)
 	var connectErr *connect.Error
 	if err != nil && (errors.As(err, &connectErr) && connectErr.Code() != connect.CodeNotFound) {
 		return err
@@ -143,15 +271,12 @@ func (s *service) CreateOrganization(
 	}
 
 	org := &OrganizationDTO{
-		Id:         uuid.NewString(),
-		Name:       req.GetName(),
-		Visibility: proto.VisibilityMap[req.GetVisibility()],
-		CreatedBy:  createdBy,
-		CreatedAt:  time.Now().UTC(),
-	}
-
-	if err := s.repository.CreateOrganization(ctx, org); err != nil {
-		return err
+		Id:          uuid.NewString(),
+		Slug:        slug,
+		DisplayName: displayName,
+		Visibility:  proto.VisibilityMap[req.GetVisibility()],
+		CreatedBy:   createdBy,
+		CreatedAt:   time.Now().UTC(),
 	}
 
 	ownerMember := &OrganizationMemberDTO{
@@ -159,11 +284,13 @@ func (s *service) CreateOrganization(
 		OrganizationId: org.Id,
 		UserId:         createdBy,
 		Role:           MemberRoleOwner,
-		JoinedAt:       time.Now().UTC(),
+		JoinedAt:       org.CreatedAt,
+		JoinedVia:      JoinedViaDirect,
 	}
 
-	if err := s.repository.AddMember(ctx, ownerMember); err != nil {
-		zap.L().Error("failed to add creator as owner", zap.Error(err), zap.String("organizationId", org.Id))
+	// The creator's membership is written in the same transaction as the
+	// organization, so a failure cannot leave an organization without owners.
+	if err := s.repository.CreateOrganizationWithOwner(ctx, org, ownerMember); err != nil {
 		return err
 	}
 
@@ -194,15 +321,14 @@ func (s *service) CreateOrganization(
 			continue
 		}
 
-		role := SharedRoleToMemberRoleMap[member.GetRole()]
 		invites = append(invites, inviteInfo{
 			email: emailAddress,
-			role:  role,
+			role:  s.inviteRole(member.GetRole()),
 		})
 	}
 
 	if len(invites) > 0 {
-		if err := s.sendInvites(ctx, org.Id, org.Name, createdBy, invites); err != nil {
+		if err := s.sendInvites(ctx, org.Id, org.DisplayName, createdBy, invites); err != nil {
 			zap.L().Error("failed to send invites", zap.Error(err), zap.String("organizationId", org.Id))
 		}
 	}
@@ -215,6 +341,10 @@ func (s *service) InviteUser(
 	req *organizationv1.InviteMemberRequest,
 	invitedBy string,
 ) error {
+	if err := validateInvite(req.GetEmail(), req.GetRole()); err != nil {
+		return err
+	}
+
 	org, err := s.repository.GetOrganizationById(ctx, req.GetId())
 	if err != nil {
 		return err
@@ -242,12 +372,11 @@ func (s *service) InviteUser(
 		return err
 	}
 
-	role := SharedRoleToMemberRoleMap[req.GetRole()]
 	invites := []inviteInfo{
-		{email: emailAddress, role: role},
+		{email: emailAddress, role: s.inviteRole(req.GetRole())},
 	}
 
-	if err := s.sendInvites(ctx, org.Id, org.Name, invitedBy, invites); err != nil {
+	if err := s.sendInvites(ctx, org.Id, org.DisplayName, invitedBy, invites); err != nil {
 		zap.L().Error("failed to send invites", zap.Error(err), zap.String("organizationId", org.Id))
 		return err
 	}
@@ -255,7 +384,31 @@ func (s *service) InviteUser(
 	return nil
 }
 
+func (s *service) checkInviteBatchSize(count int) error {
+	if count > s.maxInvitesPerRequest {
+		return connect.NewError(connect.CodeInvalidArgument, fmt.Errorf(errTooManyInvites, s.maxInvitesPerRequest, count))
+	}
+
+	return nil
+}
+
+func (s *service) inviteRole(role shared.Role) MemberRole {
+	if memberRole, ok := SharedRoleToMemberRoleMap[role]; ok {
+		return memberRole
+	}
+
+	return s.defaultInviteRole
+}
+
 func (s *service) sendInvites(ctx context.Context, orgId, orgName, invitedBy string, invites []inviteInfo) error {
+	if err := s.checkInviteBatchSize(len(invites)); err != nil {
+		return err
+	}
+
+	if err := s.ensureMemberCapacity(ctx, orgId, len(invites)); err != nil {
+		return err
+	}
+
 	var organizationInvites []*OrganizationInviteDTO
 	var emailJobs []*EmailJobDTO
 	now := time.Now().UTC()
@@ -276,7 +429,7 @@ func (s *service) sendInvites(ctx context.Context, orgId, orgName, invitedBy str
 			Role:           inviteData.role,
 			Status:         InviteStatusPending,
 			CreatedAt:      now,
-			ExpiresAt:      now.AddDate(0, 0, 7),
+			ExpiresAt:      now.Add(s.inviteTTL),
 		}
 		organizationInvites = append(organizationInvites, invite)
 
@@ -311,9 +464,54 @@ func (s *service) sendInvites(ctx context.Context, orgId, orgName, invitedBy str
 	return nil
 }
 
+func (s *service) GetOrganizationByNameWithRole(
+	ctx context.Context,
+	name string,
+	userId string,
+) (*OrganizationDTO, *MemberRole, error) {
+	org, err := s.repository.GetOrganizationByNameWithRole(ctx, name, userId)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if org.Role == nil && org.Visibility != proto.VisibilityPublic {
+		return nil, nil, connect.NewError(connect.CodeNotFound, errors.New("organization not found"))
+	}
+
+	return &org.OrganizationDTO, org.Role, nil
+}
+
+// GetOrganization also reports whether userId is a member. Private
+// organizations look missing to non-members so their existence is not leaked.
+func (s *service) GetOrganization(
+	ctx context.Context,
+	organizationId string,
+	userId string,
+) (*OrganizationDTO, bool, error) {
+	org, err := s.repository.GetOrganizationById(ctx, organizationId)
+	if err != nil {
+		return nil, false, err
+	}
+
+	isMember := true
+	if _, err := s.repository.GetMemberRole(ctx, organizationId, userId); err != nil {
+		if connect.CodeOf(err) != connect.CodeNotFound {
+			return nil, false, err
+		}
+		isMember = false
+	}
+
+	if !isMember && org.Visibility != proto.VisibilityPublic {
+		return nil, false, connect.NewError(connect.CodeNotFound, errors.New("organization not found"))
+	}
+
+	return org, isMember, nil
+}
+
 func (s *service) UpdateOrganization(
 	ctx context.Context,
 	req *organizationv1.UpdateOrganizationRequest,
+	displayName *string,
 	userId string,
 ) error {
 	org, err := s.repository.GetOrganizationById(ctx, req.GetId())
@@ -325,7 +523,19 @@ func (s *service) UpdateOrganization(
 		return err
 	}
 
-	org.Name = req.GetName()
+	// The slug is in every URL and clone path, so only the display name moves.
+	if req.GetName() != "" && !strings.EqualFold(req.GetName(), org.Slug) {
+		return connect.NewError(connect.CodeInvalidArgument, errors.New(errSlugImmutable))
+	}
+
+	if displayName != nil {
+		normalized, err := normalizeDisplayName(*displayName)
+		if err != nil {
+			return err
+		}
+		org.DisplayName = normalized
+	}
+
 	org.Visibility = proto.VisibilityMap[req.GetVisibility()]
 	if err := s.repository.UpdateOrganization(ctx, org); err != nil {
 		return err
@@ -334,6 +544,22 @@ func (s *service) UpdateOrganization(
 	return nil
 }
 
+// SetDefaultSdks replaces the SDKs new repositories of the organization start
+// with enabled. Repositories that already exist keep their preferences.
+func (s *service) SetDefaultSdks(ctx context.Context, organizationId, userId string, sdks []registry.SDK) error {
+	if err := s.verifyOwnerRole(ctx, organizationId, userId, errOnlyOwnersCanSetSdks); err != nil {
+		return err
+	}
+
+	for _, sdk := range sdks {
+		if !s.sdkGeneration.IsSdkAllowed(string(sdk)) {
+			return connect.NewError(connect.CodeFailedPrecondition, fmt.Errorf("SDK %s is not available on this server", sdk))
+		}
+	}
+
+	return s.repository.SetDefaultSdks(ctx, organizationId, slices.Compact(slices.Sorted(slices.Values(sdks))))
+}
+
 func (s *service) DeleteOrganization(
 	ctx context.Context,
 	organizationId string,
@@ -343,11 +569,12 @@ func (s *service) DeleteOrganization(
 		return err
 	}
 
-	if err := s.registryService.DeleteRepositoriesByOrganization(ctx, organizationId); err != nil {
-		return err
-	}
-
-	if err := s.repository.DeleteOrganization(ctx, organizationId); err != nil {
+	// The repository rows go with the organization in one transaction; the
+	// registry removes their directories once it commits.
+	err := s.registryService.DeleteRepositoriesByOrganization(ctx, organizationId, func(ctx context.Context) error {
+		return s.repository.DeleteOrganization(ctx, organizationId)
+	})
+	if err != nil {
 		return err
 	}
 
@@ -359,6 +586,28 @@ func (s *service) DeleteOrganization(
 	return nil
 }
 
+// normalizeDisplayName trims the label and rejects only what cannot be shown:
+// blank names, overlong ones and control characters.
+func normalizeDisplayName(name string) (string, error) {
+	name = strings.TrimSpace(name)
+	if name == "" {
+		return "", connect.NewError(connect.CodeInvalidArgument, errors.New("display name cannot be empty"))
+	}
+
+	if utf8.RuneCountInString(name) > maxDisplayNameLength {
+		return "", connect.NewError(
+			connect.CodeInvalidArgument,
+			fmt.Errorf("display name cannot be longer than %d characters", maxDisplayNameLength),
+		)
+	}
+
+	if strings.ContainsFunc(name, unicode.IsControl) {
+		return "", connect.NewError(connect.CodeInvalidArgument, errors.New("display name cannot contain control characters"))
+	}
+
+	return name, nil
+}
+
 func generateInviteToken() (string, error) {
 	bytes := make([]byte, 32)
 	if _, err := rand.Read(bytes); err != nil {
@@ -420,6 +669,10 @@ func (s *service) RespondToInvitation(
 		return nil
 	}
 
+	if err := s.ensureMemberCapacity(ctx, invite.OrganizationId, 1); err != nil {
+		return err
+	}
+
 	now := time.Now().UTC()
 	if err = s.repository.UpdateInviteStatus(ctx, invite.Id, InviteStatusAccepted, &now); err != nil {
 		return err
@@ -431,6 +684,7 @@ func (s *service) RespondToInvitation(
 		UserId:         userId,
 		Role:           invite.Role,
 		JoinedAt:       now,
+		JoinedVia:      JoinedViaInvite,
 	}
 
 	if err := s.repository.AddMember(ctx, member); err != nil {
@@ -502,6 +756,72 @@ func (s *service) UpdateMemberRole(
 	return nil
 }
 
+// UpdateMemberRolesBulk applies every change in changes, keyed by member user
+// id, or none of them. The batch is rejected when it would leave the
+// organization without an owner.
+func (s *service) UpdateMemberRolesBulk(
+	ctx context.Context,
+	organizationId string,
+	changes map[string]MemberRole,
+	updatedBy string,
+) error {
+	if len(changes) == 0 {
+		return connect.NewError(connect.CodeInvalidArgument, errors.New("no role changes given"))
+	}
+	for memberUserId, role := range changes {
+		if _, ok := MemberRoleToSharedRoleMap[role]; !ok {
+			return connect.NewError(connect.CodeInvalidArgument, fmt.Errorf("invalid role %q for member %s", role, memberUserId))
+		}
+	}
+
+	if _, err := s.repository.GetOrganizationById(ctx, organizationId); err != nil {
+		return err
+	}
+
+	if err := s.verifyOwnerRole(ctx, organizationId, updatedBy, errOnlyOwnersCanManage); err != nil {
+		return err
+	}
+
+	members, _, _, err := s.repository.GetMembers(ctx, organizationId)
+	if err != nil {
+		return err
+	}
+
+	owners := 0
+	known := make(map[string]struct{}, len(members))
+	for _, member := range members {
+		known[member.UserId] = struct{}{}
+
+		role := member.Role
+		if newRole, ok := changes[member.UserId]; ok {
+			role = newRole
+		}
+		if role == MemberRoleOwner {
+			owners++
+		}
+	}
+	for memberUserId := range changes {
+		if _, ok := known[memberUserId]; !ok {
+			return connect.NewError(connect.CodeNotFound, fmt.Errorf("member %s not found", memberUserId))
+		}
+	}
+	if owners == 0 {
+		return connect.NewError(connect.CodeFailedPrecondition, errors.New(errBatchRemovesAllOwners))
+	}
+
+	if err := s.repository.UpdateMemberRoles(ctx, organizationId, changes); err != nil {
+		return err
+	}
+
+	zap.L().Info("member roles updated",
+		zap.String("organizationId", organizationId),
+		zap.Int("changes", len(changes)),
+		zap.String("updatedBy", updatedBy),
+	)
+
+	return nil
+}
+
 func (s *service) DeleteMember(
 	ctx context.Context,
 	req *organizationv1.DeleteMemberRequest,
@@ -544,3 +864,39 @@ func (s *service) DeleteMember(
 
 	return nil
 }
+
+const (
+	defaultSearchSuggestionLimit = 5
+	maxSearchSuggestionLimit     = 20
+)
+
+// SearchSuggestions backs the search box's type-ahead, so it returns a short
+// ranked list of names and leaves counting to the full search.
+func (s *service) SearchSuggestions(ctx context.Context, userId, prefix string, limit int) ([]SearchItemDTO, error) {
+	prefix = strings.TrimSpace(prefix)
+	if prefix == "" {
+		return []SearchItemDTO{}, nil
+	}
+
+	if limit < 1 {
+		limit = defaultSearchSuggestionLimit
+	}
+	limit = min(limit, maxSearchSuggestionLimit)
+
+	return s.repository.SearchSuggestions(ctx, userId, prefix, limit)
+}
+
+// GetInvitesForEmail lists the pending, unexpired invites sent to email, which
+// must be the authenticated user's own address.
+func (s *service) GetInvitesForEmail(ctx context.Context, email string) ([]OrganizationInviteDetailsDTO, error) {
+	userEmail, err := authentication.MustGetUserEmail(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	if email != userEmail {
+		return nil, connect.NewError(connect.CodePermissionDenied, errors.New("you can only list invitations sent to your own email address"))
+	}
+
+	return s.repository.GetInvitesForEmail(ctx, userEmail)
+}
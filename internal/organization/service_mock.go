@@ -15,6 +15,8 @@ import (
 
 	organizationv1 "buf.build/gen/go/hasir/hasir/protocolbuffers/go/organization/v1"
 	gomock "go.uber.org/mock/gomock"
+
+	registry "hasir-api/internal/registry"
 )
 
 // MockService is a mock of Service interface.
@@ -42,17 +44,17 @@ func (m *MockService) EXPECT() *MockServiceMockRecorder {
 }
 
 // CreateOrganization mocks base method.
-func (m *MockService) CreateOrganization(ctx context.Context, req *organizationv1.CreateOrganizationRequest, createdBy string) error {
+func (m *MockService) CreateOrganization(ctx context.Context, req *organizationv1.CreateOrganizationRequest, displayName, createdBy string) error {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "CreateOrganization", ctx, req, createdBy)
+	ret := m.ctrl.Call(m, "CreateOrganization", ctx, req, displayName, createdBy)
 	ret0, _ := ret[0].(error)
 	return ret0
 }
 
 // CreateOrganization indicates an expected call of CreateOrganization.
-func (mr *MockServiceMockRecorder) CreateOrganization(ctx, req, createdBy any) *gomock.Call {
+func (mr *MockServiceMockRecorder) CreateOrganization(ctx, req, displayName, createdBy any) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateOrganization", reflect.TypeOf((*MockService)(nil).CreateOrganization), ctx, req, createdBy)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateOrganization", reflect.TypeOf((*MockService)(nil).CreateOrganization), ctx, req, displayName, createdBy)
 }
 
 // DeleteMember mocks base method.
@@ -83,6 +85,68 @@ func (mr *MockServiceMockRecorder) DeleteOrganization(ctx, organizationId, userI
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteOrganization", reflect.TypeOf((*MockService)(nil).DeleteOrganization), ctx, organizationId, userId)
 }
 
+// ExportOrganization mocks base method.
+func (m *MockService) ExportOrganization(ctx context.Context, organizationId string) ([]byte, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ExportOrganization", ctx, organizationId)
+	ret0, _ := ret[0].([]byte)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ExportOrganization indicates an expected call of ExportOrganization.
+func (mr *MockServiceMockRecorder) ExportOrganization(ctx, organizationId any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ExportOrganization", reflect.TypeOf((*MockService)(nil).ExportOrganization), ctx, organizationId)
+}
+
+// GetInvitesForEmail mocks base method.
+func (m *MockService) GetInvitesForEmail(ctx context.Context, email string) ([]OrganizationInviteDetailsDTO, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetInvitesForEmail", ctx, email)
+	ret0, _ := ret[0].([]OrganizationInviteDetailsDTO)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetInvitesForEmail indicates an expected call of GetInvitesForEmail.
+func (mr *MockServiceMockRecorder) GetInvitesForEmail(ctx, email any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetInvitesForEmail", reflect.TypeOf((*MockService)(nil).GetInvitesForEmail), ctx, email)
+}
+
+// GetOrganization mocks base method.
+func (m *MockService) GetOrganization(ctx context.Context, organizationId, userId string) (*OrganizationDTO, bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetOrganization", ctx, organizationId, userId)
+	ret0, _ := ret[0].(*OrganizationDTO)
+	ret1, _ := ret[1].(bool)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
+}
+
+// GetOrganization indicates an expected call of GetOrganization.
+func (mr *MockServiceMockRecorder) GetOrganization(ctx, organizationId, userId any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetOrganization", reflect.TypeOf((*MockService)(nil).GetOrganization), ctx, organizationId, userId)
+}
+
+// GetOrganizationByNameWithRole mocks base method.
+func (m *MockService) GetOrganizationByNameWithRole(ctx context.Context, name, userId string) (*OrganizationDTO, *MemberRole, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetOrganizationByNameWithRole", ctx, name, userId)
+	ret0, _ := ret[0].(*OrganizationDTO)
+	ret1, _ := ret[1].(*MemberRole)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
+}
+
+// GetOrganizationByNameWithRole indicates an expected call of GetOrganizationByNameWithRole.
+func (mr *MockServiceMockRecorder) GetOrganizationByNameWithRole(ctx, name, userId any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetOrganizationByNameWithRole", reflect.TypeOf((*MockService)(nil).GetOrganizationByNameWithRole), ctx, name, userId)
+}
+
 // InviteUser mocks base method.
 func (m *MockService) InviteUser(ctx context.Context, req *organizationv1.InviteMemberRequest, invitedBy string) error {
 	m.ctrl.T.Helper()
@@ -111,6 +175,35 @@ func (mr *MockServiceMockRecorder) RespondToInvitation(ctx, token, userId, userE
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RespondToInvitation", reflect.TypeOf((*MockService)(nil).RespondToInvitation), ctx, token, userId, userEmail, accept)
 }
 
+// SearchSuggestions mocks base method.
+func (m *MockService) SearchSuggestions(ctx context.Context, userId, prefix string, limit int) ([]SearchItemDTO, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SearchSuggestions", ctx, userId, prefix, limit)
+	ret0, _ := ret[0].([]SearchItemDTO)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// SearchSuggestions indicates an expected call of SearchSuggestions.
+func (mr *MockServiceMockRecorder) SearchSuggestions(ctx, userId, prefix, limit any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SearchSuggestions", reflect.TypeOf((*MockService)(nil).SearchSuggestions), ctx, userId, prefix, limit)
+}
+
+// SetDefaultSdks mocks base method.
+func (m *MockService) SetDefaultSdks(ctx context.Context, organizationId, userId string, sdks []registry.SDK) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SetDefaultSdks", ctx, organizationId, userId, sdks)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SetDefaultSdks indicates an expected call of SetDefaultSdks.
+func (mr *MockServiceMockRecorder) SetDefaultSdks(ctx, organizationId, userId, sdks any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetDefaultSdks", reflect.TypeOf((*MockService)(nil).SetDefaultSdks), ctx, organizationId, userId, sdks)
+}
+
 // UpdateMemberRole mocks base method.
 func (m *MockService) UpdateMemberRole(ctx context.Context, req *organizationv1.UpdateMemberRoleRequest, updatedBy string) error {
 	m.ctrl.T.Helper()
@@ -125,16 +218,30 @@ func (mr *MockServiceMockRecorder) UpdateMemberRole(ctx, req, updatedBy any) *go
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateMemberRole", reflect.TypeOf((*MockService)(nil).UpdateMemberRole), ctx, req, updatedBy)
 }
 
+// UpdateMemberRolesBulk mocks base method.
+func (m *MockService) UpdateMemberRolesBulk(ctx context.Context, organizationId string, changes map[string]MemberRole, updatedBy string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateMemberRolesBulk", ctx, organizationId, changes, updatedBy)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpdateMemberRolesBulk indicates an expected call of UpdateMemberRolesBulk.
+func (mr *MockServiceMockRecorder) UpdateMemberRolesBulk(ctx, organizationId, changes, updatedBy any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateMemberRolesBulk", reflect.TypeOf((*MockService)(nil).UpdateMemberRolesBulk), ctx, organizationId, changes, updatedBy)
+}
+
 // UpdateOrganization mocks base method.
-func (m *MockService) UpdateOrganization(ctx context.Context, req *organizationv1.UpdateOrganizationRequest, userId string) error {
+func (m *MockService) UpdateOrganization(ctx context.Context, req *organizationv1.UpdateOrganizationRequest, displayName *string, userId string) error {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "UpdateOrganization", ctx, req, userId)
+	ret := m.ctrl.Call(m, "UpdateOrganization", ctx, req, displayName, userId)
 	ret0, _ := ret[0].(error)
 	return ret0
 }
 
 // UpdateOrganization indicates an expected call of UpdateOrganization.
-func (mr *MockServiceMockRecorder) UpdateOrganization(ctx, req, userId any) *gomock.Call {
+func (mr *MockServiceMockRecorder) UpdateOrganization(ctx, req, displayName, userId any) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateOrganization", reflect.TypeOf((*MockService)(nil).UpdateOrganization), ctx, req, userId)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateOrganization", reflect.TypeOf((*MockService)(nil).UpdateOrganization), ctx, req, displayName, userId)
 }
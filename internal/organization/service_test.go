@@ -3,6 +3,9 @@ package organization
 import (
 	"context"
 	"errors"
+	"os"
+	"path/filepath"
+	"strings"
 	"testing"
 	"time"
 
@@ -10,9 +13,12 @@ import (
 	"buf.build/gen/go/hasir/hasir/protocolbuffers/go/shared"
 	"connectrpc.com/connect"
 	"go.uber.org/mock/gomock"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
 
 	"hasir-api/internal/registry"
 	"hasir-api/internal/user"
+	"hasir-api/pkg/authentication"
+	"hasir-api/pkg/config"
 	"hasir-api/pkg/email"
 	"hasir-api/pkg/proto"
 )
@@ -37,7 +43,7 @@ func newTestService(t *testing.T) (Service, *MockRepository, *MockQueue, *regist
 	mockEmail := email.NewMockService(ctrl)
 	mockUserRepo := user.NewMockRepository(ctrl)
 
-	svc := NewService(mockRepo, mockQueue, mockRegistry, mockEmail, mockUserRepo)
+	svc := NewService(mockRepo, mockQueue, mockRegistry, mockEmail, mockUserRepo, nil)
 
 	return svc, mockRepo, mockQueue, mockRegistry, mockEmail, mockUserRepo, context.Background()
 }
@@ -56,10 +62,13 @@ func TestCreateOrganization(t *testing.T) {
 			Return(nil, ErrOrganizationNotFound)
 
 		mockRepo.EXPECT().
-			CreateOrganization(ctx, gomock.Any()).
-			DoAndReturn(func(_ context.Context, org *OrganizationDTO) error {
-				if org.Name != "test-org" {
-					t.Errorf("expected name 'test-org', got %s", org.Name)
+			CreateOrganizationWithOwner(ctx, gomock.Any(), gomock.Any()).
+			DoAndReturn(func(_ context.Context, org *OrganizationDTO, owner *OrganizationMemberDTO) error {
+				if org.Slug != "test-org" {
+					t.Errorf("expected slug 'test-org', got %s", org.Slug)
+				}
+				if org.DisplayName != "test-org" {
+					t.Errorf("expected display name to default to the slug, got %s", org.DisplayName)
 				}
 				if org.Visibility != proto.VisibilityPrivate {
 					t.Errorf("expected visibility 'private', got %s", org.Visibility)
@@ -67,14 +76,22 @@ func TestCreateOrganization(t *testing.T) {
 				if org.CreatedBy != createdBy {
 					t.Errorf("expected createdBy '%s', got %s", createdBy, org.CreatedBy)
 				}
+				if owner.OrganizationId != org.Id {
+					t.Errorf("expected owner of organization %s, got %s", org.Id, owner.OrganizationId)
+				}
+				if owner.UserId != createdBy {
+					t.Errorf("expected owner '%s', got %s", createdBy, owner.UserId)
+				}
+				if owner.Role != MemberRoleOwner {
+					t.Errorf("expected role %s, got %s", MemberRoleOwner, owner.Role)
+				}
+				if owner.JoinedVia != JoinedViaDirect {
+					t.Errorf("expected joinedVia %s, got %s", JoinedViaDirect, owner.JoinedVia)
+				}
 				return nil
 			})
 
-		mockRepo.EXPECT().
-			AddMember(ctx, gomock.Any()).
-			Return(nil)
-
-		err := svc.CreateOrganization(ctx, req, createdBy)
+		err := svc.CreateOrganization(ctx, req, "", createdBy)
 		if err != nil {
 			t.Fatalf("expected no error, got %v", err)
 		}
@@ -97,11 +114,7 @@ func TestCreateOrganization(t *testing.T) {
 			Return(nil, ErrOrganizationNotFound)
 
 		mockRepo.EXPECT().
-			CreateOrganization(ctx, gomock.Any()).
-			Return(nil)
-
-		mockRepo.EXPECT().
-			AddMember(ctx, gomock.Any()).
+			CreateOrganizationWithOwner(ctx, gomock.Any(), gomock.Any()).
 			Return(nil)
 
 		mockUserRepo.EXPECT().
@@ -148,7 +161,7 @@ func TestCreateOrganization(t *testing.T) {
 				return nil
 			})
 
-		err := svc.CreateOrganization(ctx, req, createdBy)
+		err := svc.CreateOrganization(ctx, req, "", createdBy)
 		if err != nil {
 			t.Fatalf("expected no error, got %v", err)
 		}
@@ -163,15 +176,16 @@ func TestCreateOrganization(t *testing.T) {
 		createdBy := "user-123"
 
 		existingOrg := &OrganizationDTO{
-			Id:   "existing-id",
-			Name: "existing-org",
+			Id:          "existing-id",
+			Slug:        "existing-org",
+			DisplayName: "existing-org",
 		}
 
 		mockRepo.EXPECT().
 			GetOrganizationByName(ctx, "existing-org").
 			Return(existingOrg, nil)
 
-		err := svc.CreateOrganization(ctx, req, createdBy)
+		err := svc.CreateOrganization(ctx, req, "", createdBy)
 		if err == nil {
 			t.Fatal("expected error, got nil")
 		}
@@ -199,10 +213,10 @@ func TestCreateOrganization(t *testing.T) {
 			Return(nil, ErrOrganizationNotFound)
 
 		mockRepo.EXPECT().
-			CreateOrganization(ctx, gomock.Any()).
+			CreateOrganizationWithOwner(ctx, gomock.Any(), gomock.Any()).
 			Return(connect.NewError(connect.CodeInternal, errors.New("database error")))
 
-		err := svc.CreateOrganization(ctx, req, createdBy)
+		err := svc.CreateOrganization(ctx, req, "", createdBy)
 		if err == nil {
 			t.Fatal("expected error, got nil")
 		}
@@ -229,7 +243,7 @@ func TestCreateOrganization(t *testing.T) {
 			GetOrganizationByName(ctx, "test-org").
 			Return(nil, connect.NewError(connect.CodeInternal, errors.New("database error")))
 
-		err := svc.CreateOrganization(ctx, req, createdBy)
+		err := svc.CreateOrganization(ctx, req, "", createdBy)
 		if err == nil {
 			t.Fatal("expected error, got nil")
 		}
@@ -251,11 +265,7 @@ func TestCreateOrganization(t *testing.T) {
 			Return(nil, ErrOrganizationNotFound)
 
 		mockRepo.EXPECT().
-			CreateOrganization(ctx, gomock.Any()).
-			Return(nil)
-
-		mockRepo.EXPECT().
-			AddMember(ctx, gomock.Any()).
+			CreateOrganizationWithOwner(ctx, gomock.Any(), gomock.Any()).
 			Return(nil)
 
 		mockUserRepo.EXPECT().
@@ -268,7 +278,7 @@ func TestCreateOrganization(t *testing.T) {
 			CreateInvites(ctx, gomock.Any()).
 			Return(connect.NewError(connect.CodeInternal, errors.New("invite creation failed")))
 
-		err := svc.CreateOrganization(ctx, req, createdBy)
+		err := svc.CreateOrganization(ctx, req, "", createdBy)
 		if err != nil {
 			t.Fatalf("expected no error, got %v", err)
 		}
@@ -290,11 +300,7 @@ func TestCreateOrganization(t *testing.T) {
 			Return(nil, ErrOrganizationNotFound)
 
 		mockRepo.EXPECT().
-			CreateOrganization(ctx, gomock.Any()).
-			Return(nil)
-
-		mockRepo.EXPECT().
-			AddMember(ctx, gomock.Any()).
+			CreateOrganizationWithOwner(ctx, gomock.Any(), gomock.Any()).
 			Return(nil)
 
 		mockUserRepo.EXPECT().
@@ -311,7 +317,7 @@ func TestCreateOrganization(t *testing.T) {
 			EnqueueEmailJobs(ctx, gomock.Any()).
 			Return(errors.New("queue error"))
 
-		err := svc.CreateOrganization(ctx, req, createdBy)
+		err := svc.CreateOrganization(ctx, req, "", createdBy)
 		if err != nil {
 			t.Fatalf("expected no error, got %v", err)
 		}
@@ -350,19 +356,15 @@ func TestCreateOrganization(t *testing.T) {
 					Return(nil, ErrOrganizationNotFound)
 
 				mockRepo.EXPECT().
-					CreateOrganization(ctx, gomock.Any()).
-					DoAndReturn(func(_ context.Context, org *OrganizationDTO) error {
+					CreateOrganizationWithOwner(ctx, gomock.Any(), gomock.Any()).
+					DoAndReturn(func(_ context.Context, org *OrganizationDTO, owner *OrganizationMemberDTO) error {
 						if org.Visibility != tt.expectedVisibility {
 							t.Errorf("expected visibility %s, got %s", tt.expectedVisibility, org.Visibility)
 						}
 						return nil
 					})
 
-				mockRepo.EXPECT().
-					AddMember(ctx, gomock.Any()).
-					Return(nil)
-
-				err := svc.CreateOrganization(ctx, req, createdBy)
+				err := svc.CreateOrganization(ctx, req, "", createdBy)
 				if err != nil {
 					t.Fatalf("expected no error, got %v", err)
 				}
@@ -382,9 +384,10 @@ func TestInviteUser(t *testing.T) {
 		invitedBy := "user-123"
 
 		org := &OrganizationDTO{
-			Id:        "org-123",
-			Name:      "test-org",
-			CreatedBy: invitedBy,
+			Id:          "org-123",
+			Slug:        "test-org",
+			DisplayName: "test-org",
+			CreatedBy:   invitedBy,
 		}
 
 		mockRepo.EXPECT().
@@ -434,6 +437,111 @@ func TestInviteUser(t *testing.T) {
 		}
 	})
 
+	t.Run("uses configured invite ttl", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		mockRepo := NewMockRepository(ctrl)
+		mockQueue := NewMockQueue(ctrl)
+		mockUserRepo := user.NewMockRepository(ctrl)
+		cfg := &config.Config{Organization: config.OrganizationConfig{InviteTTL: "24h"}}
+		svc := NewService(mockRepo, mockQueue, registry.NewMockService(ctrl), email.NewMockService(ctrl), mockUserRepo, cfg)
+		ctx := context.Background()
+		invitedBy := "user-123"
+
+		mockRepo.EXPECT().
+			GetOrganizationById(ctx, "org-123").
+			Return(&OrganizationDTO{Id: "org-123", Slug: "test-org", CreatedBy: invitedBy}, nil)
+		mockRepo.EXPECT().
+			GetMemberRole(ctx, "org-123", invitedBy).
+			Return(MemberRoleOwner, nil)
+		mockUserRepo.EXPECT().
+			GetUserByEmail(ctx, "friend1@example.com").
+			Return(&user.UserDTO{Id: "target-user-id"}, nil)
+		mockRepo.EXPECT().
+			GetMemberRole(ctx, "org-123", "target-user-id").
+			Return(MemberRole(""), ErrMemberNotFound)
+
+		var invite *OrganizationInviteDTO
+		mockRepo.EXPECT().
+			CreateInvites(ctx, gomock.Any()).
+			DoAndReturn(func(_ context.Context, invites []*OrganizationInviteDTO) error {
+				invite = invites[0]
+				return nil
+			})
+		mockQueue.EXPECT().
+			EnqueueEmailJobs(ctx, gomock.Any()).
+			Return(nil)
+
+		err := svc.InviteUser(ctx, &organizationv1.InviteMemberRequest{
+			Id:    "org-123",
+			Email: "friend1@example.com",
+			Role:  shared.Role_ROLE_READER,
+		}, invitedBy)
+		if err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+
+		if ttl := invite.ExpiresAt.Sub(invite.CreatedAt); ttl != 24*time.Hour {
+			t.Errorf("expected invite to expire after 24h, got %s", ttl)
+		}
+	})
+
+	for _, tt := range []struct {
+		name     string
+		role     shared.Role
+		expected MemberRole
+	}{
+		{name: "applies configured default role when unspecified", role: shared.Role_ROLE_UNSPECIFIED, expected: MemberRoleReader},
+		{name: "explicit role overrides configured default", role: shared.Role_ROLE_AUTHOR, expected: MemberRoleAuthor},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			mockRepo := NewMockRepository(ctrl)
+			mockQueue := NewMockQueue(ctrl)
+			mockUserRepo := user.NewMockRepository(ctrl)
+			cfg := &config.Config{Organization: config.OrganizationConfig{DefaultInviteRole: "reader"}}
+			svc := NewService(mockRepo, mockQueue, registry.NewMockService(ctrl), email.NewMockService(ctrl), mockUserRepo, cfg)
+			ctx := context.Background()
+			invitedBy := "user-123"
+
+			mockRepo.EXPECT().
+				GetOrganizationById(ctx, "org-123").
+				Return(&OrganizationDTO{Id: "org-123", Slug: "test-org", CreatedBy: invitedBy}, nil)
+			mockRepo.EXPECT().
+				GetMemberRole(ctx, "org-123", invitedBy).
+				Return(MemberRoleOwner, nil)
+			mockUserRepo.EXPECT().
+				GetUserByEmail(ctx, "friend1@example.com").
+				Return(&user.UserDTO{Id: "target-user-id"}, nil)
+			mockRepo.EXPECT().
+				GetMemberRole(ctx, "org-123", "target-user-id").
+				Return(MemberRole(""), ErrMemberNotFound)
+
+			var invite *OrganizationInviteDTO
+			mockRepo.EXPECT().
+				CreateInvites(ctx, gomock.Any()).
+				DoAndReturn(func(_ context.Context, invites []*OrganizationInviteDTO) error {
+					invite = invites[0]
+					return nil
+				})
+			mockQueue.EXPECT().
+				EnqueueEmailJobs(ctx, gomock.Any()).
+				Return(nil)
+
+			err := svc.InviteUser(ctx, &organizationv1.InviteMemberRequest{
+				Id:    "org-123",
+				Email: "friend1@example.com",
+				Role:  tt.role,
+			}, invitedBy)
+			if err != nil {
+				t.Fatalf("expected no error, got %v", err)
+			}
+
+			if invite.Role != tt.expected {
+				t.Errorf("expected role %s, got %s", tt.expected, invite.Role)
+			}
+		})
+	}
+
 	t.Run("permission denied when not creator", func(t *testing.T) {
 		svc, mockRepo, _, _, _, _, ctx := newTestService(t)
 		req := &organizationv1.InviteMemberRequest{
@@ -443,9 +551,10 @@ func TestInviteUser(t *testing.T) {
 		invitedBy := "user-123"
 
 		org := &OrganizationDTO{
-			Id:        "org-123",
-			Name:      "test-org",
-			CreatedBy: "other-user",
+			Id:          "org-123",
+			Slug:        "test-org",
+			DisplayName: "test-org",
+			CreatedBy:   "other-user",
 		}
 
 		mockRepo.EXPECT().
@@ -480,9 +589,10 @@ func TestInviteUser(t *testing.T) {
 		invitedBy := "user-123"
 
 		org := &OrganizationDTO{
-			Id:        "org-123",
-			Name:      "test-org",
-			CreatedBy: invitedBy,
+			Id:          "org-123",
+			Slug:        "test-org",
+			DisplayName: "test-org",
+			CreatedBy:   invitedBy,
 		}
 
 		mockRepo.EXPECT().
@@ -521,9 +631,10 @@ func TestInviteUser(t *testing.T) {
 		invitedBy := "user-123"
 
 		org := &OrganizationDTO{
-			Id:        "org-123",
-			Name:      "test-org",
-			CreatedBy: invitedBy,
+			Id:          "org-123",
+			Slug:        "test-org",
+			DisplayName: "test-org",
+			CreatedBy:   invitedBy,
 		}
 
 		mockRepo.EXPECT().
@@ -618,6 +729,9 @@ func TestRespondToInvitation(t *testing.T) {
 				if member.Role != invite.Role {
 					t.Errorf("expected role %s, got %s", invite.Role, member.Role)
 				}
+				if member.JoinedVia != JoinedViaInvite {
+					t.Errorf("expected joinedVia %s, got %s", JoinedViaInvite, member.JoinedVia)
+				}
 				return nil
 			})
 
@@ -967,8 +1081,10 @@ func TestDeleteOrganization(t *testing.T) {
 			Return(MemberRoleOwner, nil)
 
 		mockRegistry.EXPECT().
-			DeleteRepositoriesByOrganization(ctx, orgID).
-			Return(nil)
+			DeleteRepositoriesByOrganization(ctx, orgID, gomock.Any()).
+			DoAndReturn(func(ctx context.Context, _ string, deleteOrganization func(context.Context) error) error {
+				return deleteOrganization(ctx)
+			})
 
 		mockRepo.EXPECT().
 			DeleteOrganization(ctx, orgID).
@@ -1038,8 +1154,10 @@ func TestDeleteOrganization(t *testing.T) {
 			Return(MemberRoleOwner, nil)
 
 		mockRegistry.EXPECT().
-			DeleteRepositoriesByOrganization(ctx, orgID).
-			Return(nil)
+			DeleteRepositoriesByOrganization(ctx, orgID, gomock.Any()).
+			DoAndReturn(func(ctx context.Context, _ string, deleteOrganization func(context.Context) error) error {
+				return deleteOrganization(ctx)
+			})
 
 		mockRepo.EXPECT().
 			DeleteOrganization(ctx, orgID).
@@ -1061,6 +1179,172 @@ func TestDeleteOrganization(t *testing.T) {
 	})
 }
 
+func TestGetOrganizationByNameWithRole(t *testing.T) {
+	t.Run("member gets role", func(t *testing.T) {
+		svc, mockRepo, _, _, _, _, ctx := newTestService(t)
+		role := MemberRoleAuthor
+
+		mockRepo.EXPECT().
+			GetOrganizationByNameWithRole(ctx, "test-org", "user-123").
+			Return(&OrganizationWithRoleDTO{
+				OrganizationDTO: OrganizationDTO{
+					Id:          "org-123",
+					Slug:        "test-org",
+					DisplayName: "test-org",
+					Visibility:  proto.VisibilityPrivate,
+				},
+				Role: &role,
+			}, nil)
+
+		org, memberRole, err := svc.GetOrganizationByNameWithRole(ctx, "test-org", "user-123")
+		if err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+		if org.Id != "org-123" {
+			t.Errorf("expected id 'org-123', got %s", org.Id)
+		}
+		if memberRole == nil || *memberRole != MemberRoleAuthor {
+			t.Errorf("expected role 'author', got %v", memberRole)
+		}
+	})
+
+	t.Run("non-member of public organization gets nil role", func(t *testing.T) {
+		svc, mockRepo, _, _, _, _, ctx := newTestService(t)
+
+		mockRepo.EXPECT().
+			GetOrganizationByNameWithRole(ctx, "public-org", "user-123").
+			Return(&OrganizationWithRoleDTO{
+				OrganizationDTO: OrganizationDTO{
+					Id:          "org-123",
+					Slug:        "public-org",
+					DisplayName: "public-org",
+					Visibility:  proto.VisibilityPublic,
+				},
+			}, nil)
+
+		org, memberRole, err := svc.GetOrganizationByNameWithRole(ctx, "public-org", "user-123")
+		if err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+		if org == nil || org.Slug != "public-org" {
+			t.Fatalf("expected organization 'public-org', got %v", org)
+		}
+		if memberRole != nil {
+			t.Errorf("expected nil role, got %v", *memberRole)
+		}
+	})
+
+	t.Run("non-member of private organization gets not found", func(t *testing.T) {
+		svc, mockRepo, _, _, _, _, ctx := newTestService(t)
+
+		mockRepo.EXPECT().
+			GetOrganizationByNameWithRole(ctx, "private-org", "user-123").
+			Return(&OrganizationWithRoleDTO{
+				OrganizationDTO: OrganizationDTO{
+					Id:          "org-123",
+					Slug:        "private-org",
+					DisplayName: "private-org",
+					Visibility:  proto.VisibilityPrivate,
+				},
+			}, nil)
+
+		_, _, err := svc.GetOrganizationByNameWithRole(ctx, "private-org", "user-123")
+		var connectErr *connect.Error
+		if !errors.As(err, &connectErr) || connectErr.Code() != connect.CodeNotFound {
+			t.Fatalf("expected not found error, got %v", err)
+		}
+	})
+
+	t.Run("organization not found", func(t *testing.T) {
+		svc, mockRepo, _, _, _, _, ctx := newTestService(t)
+
+		mockRepo.EXPECT().
+			GetOrganizationByNameWithRole(ctx, "missing-org", "user-123").
+			Return(nil, ErrOrganizationNotFound)
+
+		_, _, err := svc.GetOrganizationByNameWithRole(ctx, "missing-org", "user-123")
+		if !errors.Is(err, ErrOrganizationNotFound) {
+			t.Fatalf("expected ErrOrganizationNotFound, got %v", err)
+		}
+	})
+}
+
+func TestGetOrganization(t *testing.T) {
+	t.Run("member of private organization", func(t *testing.T) {
+		svc, mockRepo, _, _, _, _, ctx := newTestService(t)
+
+		mockRepo.EXPECT().
+			GetOrganizationById(ctx, "org-123").
+			Return(&OrganizationDTO{Id: "org-123", Slug: "private-org", Visibility: proto.VisibilityPrivate}, nil)
+		mockRepo.EXPECT().
+			GetMemberRole(ctx, "org-123", "user-123").
+			Return(MemberRoleReader, nil)
+
+		org, isMember, err := svc.GetOrganization(ctx, "org-123", "user-123")
+		if err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+		if org.Id != "org-123" {
+			t.Errorf("expected id 'org-123', got %s", org.Id)
+		}
+		if !isMember {
+			t.Error("expected user to be a member")
+		}
+	})
+
+	t.Run("non-member of public organization", func(t *testing.T) {
+		svc, mockRepo, _, _, _, _, ctx := newTestService(t)
+
+		mockRepo.EXPECT().
+			GetOrganizationById(ctx, "org-123").
+			Return(&OrganizationDTO{Id: "org-123", Slug: "public-org", Visibility: proto.VisibilityPublic}, nil)
+		mockRepo.EXPECT().
+			GetMemberRole(ctx, "org-123", "user-123").
+			Return(MemberRole(""), ErrMemberNotFound)
+
+		org, isMember, err := svc.GetOrganization(ctx, "org-123", "user-123")
+		if err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+		if org.Slug != "public-org" {
+			t.Errorf("expected slug 'public-org', got %s", org.Slug)
+		}
+		if isMember {
+			t.Error("expected user not to be a member")
+		}
+	})
+
+	t.Run("non-member of private organization gets not found", func(t *testing.T) {
+		svc, mockRepo, _, _, _, _, ctx := newTestService(t)
+
+		mockRepo.EXPECT().
+			GetOrganizationById(ctx, "org-123").
+			Return(&OrganizationDTO{Id: "org-123", Slug: "private-org", Visibility: proto.VisibilityPrivate}, nil)
+		mockRepo.EXPECT().
+			GetMemberRole(ctx, "org-123", "user-123").
+			Return(MemberRole(""), ErrMemberNotFound)
+
+		_, _, err := svc.GetOrganization(ctx, "org-123", "user-123")
+		var connectErr *connect.Error
+		if !errors.As(err, &connectErr) || connectErr.Code() != connect.CodeNotFound {
+			t.Fatalf("expected not found error, got %v", err)
+		}
+	})
+
+	t.Run("deleted organization gets not found", func(t *testing.T) {
+		svc, mockRepo, _, _, _, _, ctx := newTestService(t)
+
+		mockRepo.EXPECT().
+			GetOrganizationById(ctx, "org-123").
+			Return(nil, ErrOrganizationNotFound)
+
+		_, _, err := svc.GetOrganization(ctx, "org-123", "user-123")
+		if !errors.Is(err, ErrOrganizationNotFound) {
+			t.Fatalf("expected ErrOrganizationNotFound, got %v", err)
+		}
+	})
+}
+
 func TestUpdateOrganization(t *testing.T) {
 	t.Run("success", func(t *testing.T) {
 		svc, mockRepo, _, _, _, _, ctx := newTestService(t)
@@ -1068,15 +1352,16 @@ func TestUpdateOrganization(t *testing.T) {
 		userID := "user-123"
 
 		existingOrg := &OrganizationDTO{
-			Id:         orgID,
-			Name:       "old-name",
-			Visibility: proto.VisibilityPrivate,
-			CreatedBy:  userID,
+			Id:          orgID,
+			Slug:        "acme",
+			DisplayName: "Acme",
+			Visibility:  proto.VisibilityPrivate,
+			CreatedBy:   userID,
 		}
 
 		req := &organizationv1.UpdateOrganizationRequest{
 			Id:         orgID,
-			Name:       "new-name",
+			Name:       "acme",
 			Visibility: shared.Visibility_VISIBILITY_PUBLIC,
 		}
 
@@ -1094,8 +1379,11 @@ func TestUpdateOrganization(t *testing.T) {
 				if org.Id != orgID {
 					t.Errorf("expected id %s, got %s", orgID, org.Id)
 				}
-				if org.Name != "new-name" {
-					t.Errorf("expected name 'new-name', got %s", org.Name)
+				if org.Slug != "acme" {
+					t.Errorf("expected slug 'acme', got %s", org.Slug)
+				}
+				if org.DisplayName != "Acme" {
+					t.Errorf("expected display name to stay 'Acme', got %s", org.DisplayName)
 				}
 				if org.Visibility != proto.VisibilityPublic {
 					t.Errorf("expected visibility 'public', got %s", org.Visibility)
@@ -1103,12 +1391,96 @@ func TestUpdateOrganization(t *testing.T) {
 				return nil
 			})
 
-		err := svc.UpdateOrganization(ctx, req, userID)
+		err := svc.UpdateOrganization(ctx, req, nil, userID)
 		if err != nil {
 			t.Fatalf("expected no error, got %v", err)
 		}
 	})
 
+	t.Run("display name changes freely", func(t *testing.T) {
+		for _, displayName := range []string{"Acme Corporation", "acme", "Ácme & Söns, Ltd.", "  ACME  "} {
+			t.Run(displayName, func(t *testing.T) {
+				svc, mockRepo, _, _, _, _, ctx := newTestService(t)
+				orgID := "org-123"
+				userID := "user-123"
+
+				mockRepo.EXPECT().
+					GetOrganizationById(ctx, orgID).
+					Return(&OrganizationDTO{Id: orgID, Slug: "acme", DisplayName: "Acme", CreatedBy: userID}, nil)
+
+				mockRepo.EXPECT().
+					GetMemberRole(ctx, orgID, userID).
+					Return(MemberRoleOwner, nil)
+
+				mockRepo.EXPECT().
+					UpdateOrganization(ctx, gomock.Any()).
+					DoAndReturn(func(_ context.Context, org *OrganizationDTO) error {
+						if org.Slug != "acme" {
+							t.Errorf("expected slug 'acme', got %s", org.Slug)
+						}
+						if want := strings.TrimSpace(displayName); org.DisplayName != want {
+							t.Errorf("expected display name %q, got %q", want, org.DisplayName)
+						}
+						return nil
+					})
+
+				req := &organizationv1.UpdateOrganizationRequest{Id: orgID, Name: "acme"}
+				if err := svc.UpdateOrganization(ctx, req, &displayName, userID); err != nil {
+					t.Fatalf("expected no error, got %v", err)
+				}
+			})
+		}
+	})
+
+	t.Run("slug is immutable", func(t *testing.T) {
+		svc, mockRepo, _, _, _, _, ctx := newTestService(t)
+		orgID := "org-123"
+		userID := "user-123"
+		displayName := "Renamed"
+
+		mockRepo.EXPECT().
+			GetOrganizationById(ctx, orgID).
+			Return(&OrganizationDTO{Id: orgID, Slug: "acme", DisplayName: "Acme", CreatedBy: userID}, nil)
+
+		mockRepo.EXPECT().
+			GetMemberRole(ctx, orgID, userID).
+			Return(MemberRoleOwner, nil)
+
+		req := &organizationv1.UpdateOrganizationRequest{Id: orgID, Name: "renamed"}
+		err := svc.UpdateOrganization(ctx, req, &displayName, userID)
+		if connect.CodeOf(err) != connect.CodeInvalidArgument {
+			t.Fatalf("expected CodeInvalidArgument, got %v", err)
+		}
+	})
+
+	t.Run("invalid display name", func(t *testing.T) {
+		for name, displayName := range map[string]string{
+			"blank":   "   ",
+			"control": "Acme\nCorp",
+			"long":    strings.Repeat("a", maxDisplayNameLength+1),
+		} {
+			t.Run(name, func(t *testing.T) {
+				svc, mockRepo, _, _, _, _, ctx := newTestService(t)
+				orgID := "org-123"
+				userID := "user-123"
+
+				mockRepo.EXPECT().
+					GetOrganizationById(ctx, orgID).
+					Return(&OrganizationDTO{Id: orgID, Slug: "acme", DisplayName: "Acme", CreatedBy: userID}, nil)
+
+				mockRepo.EXPECT().
+					GetMemberRole(ctx, orgID, userID).
+					Return(MemberRoleOwner, nil)
+
+				req := &organizationv1.UpdateOrganizationRequest{Id: orgID, Name: "acme"}
+				err := svc.UpdateOrganization(ctx, req, &displayName, userID)
+				if connect.CodeOf(err) != connect.CodeInvalidArgument {
+					t.Fatalf("expected CodeInvalidArgument, got %v", err)
+				}
+			})
+		}
+	})
+
 	t.Run("permission denied when not creator", func(t *testing.T) {
 		svc, mockRepo, _, _, _, _, ctx := newTestService(t)
 		orgID := "org-123"
@@ -1117,14 +1489,14 @@ func TestUpdateOrganization(t *testing.T) {
 
 		existingOrg := &OrganizationDTO{
 			Id:         orgID,
-			Name:       "org-name",
+			Slug:       "org-name",
 			Visibility: proto.VisibilityPrivate,
 			CreatedBy:  creatorID,
 		}
 
 		req := &organizationv1.UpdateOrganizationRequest{
 			Id:   orgID,
-			Name: "updated-name",
+			Name: "org-name",
 		}
 
 		mockRepo.EXPECT().
@@ -1135,7 +1507,7 @@ func TestUpdateOrganization(t *testing.T) {
 			GetMemberRole(ctx, orgID, otherUserID).
 			Return(MemberRoleReader, nil)
 
-		err := svc.UpdateOrganization(ctx, req, otherUserID)
+		err := svc.UpdateOrganization(ctx, req, nil, otherUserID)
 		if err == nil {
 			t.Fatal("expected error, got nil")
 		}
@@ -1149,52 +1521,59 @@ func TestUpdateOrganization(t *testing.T) {
 			t.Errorf("expected CodePermissionDenied, got %v", connectErr.Code())
 		}
 	})
+}
 
-	t.Run("name already exists", func(t *testing.T) {
+func TestSetDefaultSdks(t *testing.T) {
+	t.Run("owner replaces defaults", func(t *testing.T) {
 		svc, mockRepo, _, _, _, _, ctx := newTestService(t)
-		orgID := "org-123"
-		userID := "user-123"
 
-		existingOrg := &OrganizationDTO{
-			Id:         orgID,
-			Name:       "old-name",
-			Visibility: proto.VisibilityPrivate,
-			CreatedBy:  userID,
-		}
+		mockRepo.EXPECT().
+			GetMemberRole(ctx, "org-123", "user-123").
+			Return(MemberRoleOwner, nil)
+		mockRepo.EXPECT().
+			SetDefaultSdks(ctx, "org-123", []registry.SDK{registry.SdkGoProtobuf, registry.SdkJsBufbuildEs}).
+			Return(nil)
 
-		req := &organizationv1.UpdateOrganizationRequest{
-			Id:   orgID,
-			Name: "conflict-name",
+		err := svc.SetDefaultSdks(ctx, "org-123", "user-123", []registry.SDK{
+			registry.SdkJsBufbuildEs,
+			registry.SdkGoProtobuf,
+			registry.SdkJsBufbuildEs,
+		})
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
 		}
+	})
 
-		mockRepo.EXPECT().
-			GetOrganizationById(ctx, orgID).
-			Return(existingOrg, nil)
-
-		mockRepo.EXPECT().
-			GetMemberRole(ctx, orgID, userID).
-			Return(MemberRoleOwner, nil)
+	t.Run("non-owner is denied", func(t *testing.T) {
+		svc, mockRepo, _, _, _, _, ctx := newTestService(t)
 
 		mockRepo.EXPECT().
-			UpdateOrganization(ctx, gomock.Any()).
-			Return(ErrOrganizationAlreadyExists)
+			GetMemberRole(ctx, "org-123", "user-123").
+			Return(MemberRoleAuthor, nil)
 
-		err := svc.UpdateOrganization(ctx, req, userID)
-		if err == nil {
-			t.Fatal("expected error, got nil")
+		err := svc.SetDefaultSdks(ctx, "org-123", "user-123", []registry.SDK{registry.SdkGoProtobuf})
+		if connect.CodeOf(err) != connect.CodePermissionDenied {
+			t.Errorf("expected permission denied, got %v", err)
 		}
+	})
 
-		var connectErr *connect.Error
-		if !errors.As(err, &connectErr) {
-			t.Fatalf("expected connect.Error, got %T", err)
-		}
+	t.Run("SDK not available on this server is rejected", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		mockRepo := NewMockRepository(ctrl)
+		cfg := &config.Config{SdkGeneration: config.SdkGenerationConfig{AllowedSdks: []string{"GO_PROTOBUF"}}}
+		svc := NewService(mockRepo, NewMockQueue(ctrl), registry.NewMockService(ctrl), email.NewMockService(ctrl), user.NewMockRepository(ctrl), cfg)
+		ctx := context.Background()
 
-		if connectErr.Code() != connect.CodeAlreadyExists {
-			t.Errorf("expected CodeAlreadyExists, got %v", connectErr.Code())
+		mockRepo.EXPECT().
+			GetMemberRole(ctx, "org-123", "user-123").
+			Return(MemberRoleOwner, nil)
+
+		err := svc.SetDefaultSdks(ctx, "org-123", "user-123", []registry.SDK{registry.SdkGoProtobuf, registry.SdkJsProtobuf})
+		if connect.CodeOf(err) != connect.CodeFailedPrecondition {
+			t.Errorf("expected failed precondition, got %v", err)
 		}
 	})
 }
-
 func TestUpdateMemberRole(t *testing.T) {
 	t.Run("success - owner updating another member's role", func(t *testing.T) {
 		svc, mockRepo, _, _, _, _, ctx := newTestService(t)
@@ -1203,8 +1582,9 @@ func TestUpdateMemberRole(t *testing.T) {
 		memberID := "member-456"
 
 		existingOrg := &OrganizationDTO{
-			Id:   orgID,
-			Name: "test-org",
+			Id:          orgID,
+			Slug:        "test-org",
+			DisplayName: "test-org",
 		}
 
 		req := &organizationv1.UpdateMemberRoleRequest{
@@ -1241,8 +1621,9 @@ func TestUpdateMemberRole(t *testing.T) {
 		memberID := "member-456"
 
 		existingOrg := &OrganizationDTO{
-			Id:   orgID,
-			Name: "test-org",
+			Id:          orgID,
+			Slug:        "test-org",
+			DisplayName: "test-org",
 		}
 
 		req := &organizationv1.UpdateMemberRoleRequest{
@@ -1280,8 +1661,9 @@ func TestUpdateMemberRole(t *testing.T) {
 		ownerID := "owner-123"
 
 		existingOrg := &OrganizationDTO{
-			Id:   orgID,
-			Name: "test-org",
+			Id:          orgID,
+			Slug:        "test-org",
+			DisplayName: "test-org",
 		}
 
 		req := &organizationv1.UpdateMemberRoleRequest{
@@ -1319,8 +1701,9 @@ func TestUpdateMemberRole(t *testing.T) {
 		ownerID := "owner-123"
 
 		existingOrg := &OrganizationDTO{
-			Id:   orgID,
-			Name: "test-org",
+			Id:          orgID,
+			Slug:        "test-org",
+			DisplayName: "test-org",
 		}
 
 		req := &organizationv1.UpdateMemberRoleRequest{
@@ -1368,8 +1751,9 @@ func TestUpdateMemberRole(t *testing.T) {
 		memberID := "member-789"
 
 		existingOrg := &OrganizationDTO{
-			Id:   orgID,
-			Name: "test-org",
+			Id:          orgID,
+			Slug:        "test-org",
+			DisplayName: "test-org",
 		}
 
 		req := &organizationv1.UpdateMemberRoleRequest{
@@ -1411,8 +1795,9 @@ func TestUpdateMemberRole(t *testing.T) {
 		onlyOwnerID := "only-owner-456"
 
 		existingOrg := &OrganizationDTO{
-			Id:   orgID,
-			Name: "test-org",
+			Id:          orgID,
+			Slug:        "test-org",
+			DisplayName: "test-org",
 		}
 
 		req := &organizationv1.UpdateMemberRoleRequest{
@@ -1459,8 +1844,9 @@ func TestUpdateMemberRole(t *testing.T) {
 		nonExistentMemberID := "non-existent-456"
 
 		existingOrg := &OrganizationDTO{
-			Id:   orgID,
-			Name: "test-org",
+			Id:          orgID,
+			Slug:        "test-org",
+			DisplayName: "test-org",
 		}
 
 		req := &organizationv1.UpdateMemberRoleRequest{
@@ -1534,8 +1920,9 @@ func TestUpdateMemberRole(t *testing.T) {
 		memberID := "member-456"
 
 		existingOrg := &OrganizationDTO{
-			Id:   orgID,
-			Name: "test-org",
+			Id:          orgID,
+			Slug:        "test-org",
+			DisplayName: "test-org",
 		}
 
 		req := &organizationv1.UpdateMemberRoleRequest{
@@ -1574,8 +1961,9 @@ func TestUpdateMemberRole(t *testing.T) {
 		memberID := "member-456"
 
 		existingOrg := &OrganizationDTO{
-			Id:   orgID,
-			Name: "test-org",
+			Id:          orgID,
+			Slug:        "test-org",
+			DisplayName: "test-org",
 		}
 
 		req := &organizationv1.UpdateMemberRoleRequest{
@@ -1607,16 +1995,130 @@ func TestUpdateMemberRole(t *testing.T) {
 	})
 }
 
-func TestDeleteMember(t *testing.T) {
-	t.Run("success - owner deleting non-owner member", func(t *testing.T) {
-		svc, mockRepo, _, _, _, _, ctx := newTestService(t)
-		orgID := "org-123"
-		ownerID := "owner-123"
-		memberID := "member-456"
+func TestUpdateMemberRolesBulk(t *testing.T) {
+	const (
+		orgID    = "org-123"
+		ownerID  = "owner-123"
+		authorID = "author-456"
+		readerID = "reader-789"
+	)
+
+	members := []*OrganizationMemberDTO{
+		{OrganizationId: orgID, UserId: ownerID, Role: MemberRoleOwner},
+		{OrganizationId: orgID, UserId: authorID, Role: MemberRoleAuthor},
+		{OrganizationId: orgID, UserId: readerID, Role: MemberRoleReader},
+	}
+
+	expectOwnerLookup := func(mockRepo *MockRepository, ctx context.Context) {
+		mockRepo.EXPECT().
+			GetOrganizationById(ctx, orgID).
+			Return(&OrganizationDTO{Id: orgID, Slug: "test-org"}, nil)
+		mockRepo.EXPECT().
+			GetMemberRole(ctx, orgID, ownerID).
+			Return(MemberRoleOwner, nil)
+		mockRepo.EXPECT().
+			GetMembers(ctx, orgID).
+			Return(members, nil, nil, nil)
+	}
+
+	t.Run("applies several changes in one call", func(t *testing.T) {
+		svc, mockRepo, _, _, _, _, ctx := newTestService(t)
+		changes := map[string]MemberRole{
+			authorID: MemberRoleOwner,
+			readerID: MemberRoleAuthor,
+		}
+
+		expectOwnerLookup(mockRepo, ctx)
+		mockRepo.EXPECT().
+			UpdateMemberRoles(ctx, orgID, changes).
+			Return(nil)
+
+		if err := svc.UpdateMemberRolesBulk(ctx, orgID, changes, ownerID); err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+	})
+
+	t.Run("batch demoting every owner is rejected before any write", func(t *testing.T) {
+		svc, mockRepo, _, _, _, _, ctx := newTestService(t)
+
+		expectOwnerLookup(mockRepo, ctx)
+
+		err := svc.UpdateMemberRolesBulk(ctx, orgID, map[string]MemberRole{
+			ownerID:  MemberRoleReader,
+			readerID: MemberRoleAuthor,
+		}, ownerID)
+		if connect.CodeOf(err) != connect.CodeFailedPrecondition {
+			t.Fatalf("expected CodeFailedPrecondition, got %v", err)
+		}
+	})
+
+	t.Run("handing ownership over within the batch is allowed", func(t *testing.T) {
+		svc, mockRepo, _, _, _, _, ctx := newTestService(t)
+		changes := map[string]MemberRole{
+			ownerID:  MemberRoleAuthor,
+			authorID: MemberRoleOwner,
+		}
+
+		expectOwnerLookup(mockRepo, ctx)
+		mockRepo.EXPECT().
+			UpdateMemberRoles(ctx, orgID, changes).
+			Return(nil)
+
+		if err := svc.UpdateMemberRolesBulk(ctx, orgID, changes, ownerID); err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+	})
+
+	t.Run("unknown member is rejected before any write", func(t *testing.T) {
+		svc, mockRepo, _, _, _, _, ctx := newTestService(t)
+
+		expectOwnerLookup(mockRepo, ctx)
+
+		err := svc.UpdateMemberRolesBulk(ctx, orgID, map[string]MemberRole{
+			"stranger": MemberRoleAuthor,
+		}, ownerID)
+		if connect.CodeOf(err) != connect.CodeNotFound {
+			t.Fatalf("expected CodeNotFound, got %v", err)
+		}
+	})
+
+	t.Run("non-owner is denied", func(t *testing.T) {
+		svc, mockRepo, _, _, _, _, ctx := newTestService(t)
+
+		mockRepo.EXPECT().
+			GetOrganizationById(ctx, orgID).
+			Return(&OrganizationDTO{Id: orgID, Slug: "test-org"}, nil)
+		mockRepo.EXPECT().
+			GetMemberRole(ctx, orgID, authorID).
+			Return(MemberRoleAuthor, nil)
+
+		err := svc.UpdateMemberRolesBulk(ctx, orgID, map[string]MemberRole{readerID: MemberRoleAuthor}, authorID)
+		if connect.CodeOf(err) != connect.CodePermissionDenied {
+			t.Fatalf("expected CodePermissionDenied, got %v", err)
+		}
+	})
+
+	t.Run("invalid role is rejected", func(t *testing.T) {
+		svc, _, _, _, _, _, ctx := newTestService(t)
+
+		err := svc.UpdateMemberRolesBulk(ctx, orgID, map[string]MemberRole{readerID: "admin"}, ownerID)
+		if connect.CodeOf(err) != connect.CodeInvalidArgument {
+			t.Fatalf("expected CodeInvalidArgument, got %v", err)
+		}
+	})
+}
+
+func TestDeleteMember(t *testing.T) {
+	t.Run("success - owner deleting non-owner member", func(t *testing.T) {
+		svc, mockRepo, _, _, _, _, ctx := newTestService(t)
+		orgID := "org-123"
+		ownerID := "owner-123"
+		memberID := "member-456"
 
 		existingOrg := &OrganizationDTO{
-			Id:   orgID,
-			Name: "test-org",
+			Id:          orgID,
+			Slug:        "test-org",
+			DisplayName: "test-org",
 		}
 
 		req := &organizationv1.DeleteMemberRequest{
@@ -1653,8 +2155,9 @@ func TestDeleteMember(t *testing.T) {
 		otherOwnerID := "owner-456"
 
 		existingOrg := &OrganizationDTO{
-			Id:   orgID,
-			Name: "test-org",
+			Id:          orgID,
+			Slug:        "test-org",
+			DisplayName: "test-org",
 		}
 
 		req := &organizationv1.DeleteMemberRequest{
@@ -1695,8 +2198,9 @@ func TestDeleteMember(t *testing.T) {
 		memberID := "member-456"
 
 		existingOrg := &OrganizationDTO{
-			Id:   orgID,
-			Name: "test-org",
+			Id:          orgID,
+			Slug:        "test-org",
+			DisplayName: "test-org",
 		}
 
 		req := &organizationv1.DeleteMemberRequest{
@@ -1734,8 +2238,9 @@ func TestDeleteMember(t *testing.T) {
 		memberID := "member-456"
 
 		existingOrg := &OrganizationDTO{
-			Id:   orgID,
-			Name: "test-org",
+			Id:          orgID,
+			Slug:        "test-org",
+			DisplayName: "test-org",
 		}
 
 		req := &organizationv1.DeleteMemberRequest{
@@ -1773,8 +2278,9 @@ func TestDeleteMember(t *testing.T) {
 		lastOwnerID := "last-owner-456"
 
 		existingOrg := &OrganizationDTO{
-			Id:   orgID,
-			Name: "test-org",
+			Id:          orgID,
+			Slug:        "test-org",
+			DisplayName: "test-org",
 		}
 
 		req := &organizationv1.DeleteMemberRequest{
@@ -1820,8 +2326,9 @@ func TestDeleteMember(t *testing.T) {
 		nonExistentMemberID := "non-existent-456"
 
 		existingOrg := &OrganizationDTO{
-			Id:   orgID,
-			Name: "test-org",
+			Id:          orgID,
+			Slug:        "test-org",
+			DisplayName: "test-org",
 		}
 
 		req := &organizationv1.DeleteMemberRequest{
@@ -1886,3 +2393,485 @@ func TestDeleteMember(t *testing.T) {
 		}
 	})
 }
+
+func TestMemberLimit(t *testing.T) {
+	setupInvite := func(t *testing.T, maxMembers int) (Service, *MockRepository, *MockQueue, context.Context) {
+		t.Helper()
+
+		svc, mockRepo, mockQueue, _, _, mockUserRepo, ctx := newTestService(t)
+		svc.(*service).maxMembers = maxMembers
+
+		mockRepo.EXPECT().
+			GetOrganizationById(ctx, "org-123").
+			Return(&OrganizationDTO{Id: "org-123", Slug: "test-org"}, nil)
+		mockRepo.EXPECT().
+			GetMemberRole(ctx, "org-123", "owner-id").
+			Return(MemberRoleOwner, nil)
+		mockUserRepo.EXPECT().
+			GetUserByEmail(ctx, "friend@example.com").
+			Return(&user.UserDTO{Id: "friend-id"}, nil)
+		mockRepo.EXPECT().
+			GetMemberRole(ctx, "org-123", "friend-id").
+			Return(MemberRole(""), ErrMemberNotFound)
+
+		return svc, mockRepo, mockQueue, ctx
+	}
+
+	inviteReq := &organizationv1.InviteMemberRequest{
+		Id:    "org-123",
+		Email: "friend@example.com",
+		Role:  shared.Role_ROLE_READER,
+	}
+
+	t.Run("invite allowed below limit", func(t *testing.T) {
+		svc, mockRepo, mockQueue, ctx := setupInvite(t, 3)
+
+		mockRepo.EXPECT().
+			GetMembersCount(ctx, "org-123").
+			Return(2, nil)
+		mockRepo.EXPECT().
+			CreateInvites(ctx, gomock.Any()).
+			Return(nil)
+		mockQueue.EXPECT().
+			EnqueueEmailJobs(ctx, gomock.Any()).
+			Return(nil)
+
+		if err := svc.InviteUser(ctx, inviteReq, "owner-id"); err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+	})
+
+	t.Run("invite rejected at limit", func(t *testing.T) {
+		svc, mockRepo, _, ctx := setupInvite(t, 3)
+
+		mockRepo.EXPECT().
+			GetMembersCount(ctx, "org-123").
+			Return(3, nil)
+
+		err := svc.InviteUser(ctx, inviteReq, "owner-id")
+		if err == nil {
+			t.Fatal("expected error, got nil")
+		}
+
+		var connectErr *connect.Error
+		if !errors.As(err, &connectErr) {
+			t.Fatalf("expected connect.Error, got %T", err)
+		}
+
+		if connectErr.Code() != connect.CodeFailedPrecondition {
+			t.Errorf("expected CodeFailedPrecondition, got %v", connectErr.Code())
+		}
+	})
+
+	t.Run("accepting invite rejected at limit", func(t *testing.T) {
+		svc, mockRepo, _, _, _, _, ctx := newTestService(t)
+		svc.(*service).maxMembers = 3
+
+		invite := &OrganizationInviteDTO{
+			Id:             "invite-id",
+			OrganizationId: "org-123",
+			Email:          "user@example.com",
+			Token:          "token",
+			Role:           MemberRoleReader,
+			Status:         InviteStatusPending,
+			CreatedAt:      time.Now().UTC(),
+			ExpiresAt:      time.Now().UTC().AddDate(0, 0, 7),
+		}
+
+		mockRepo.EXPECT().
+			GetInviteByToken(ctx, "token").
+			Return(invite, nil)
+		mockRepo.EXPECT().
+			GetMembersCount(ctx, "org-123").
+			Return(3, nil)
+
+		err := svc.RespondToInvitation(ctx, "token", "user-id", "user@example.com", true)
+		if err == nil {
+			t.Fatal("expected error, got nil")
+		}
+
+		var connectErr *connect.Error
+		if !errors.As(err, &connectErr) {
+			t.Fatalf("expected connect.Error, got %T", err)
+		}
+
+		if connectErr.Code() != connect.CodeFailedPrecondition {
+			t.Errorf("expected CodeFailedPrecondition, got %v", connectErr.Code())
+		}
+	})
+
+	t.Run("no limit skips count", func(t *testing.T) {
+		svc, mockRepo, mockQueue, ctx := setupInvite(t, 0)
+
+		mockRepo.EXPECT().
+			CreateInvites(ctx, gomock.Any()).
+			Return(nil)
+		mockQueue.EXPECT().
+			EnqueueEmailJobs(ctx, gomock.Any()).
+			Return(nil)
+
+		if err := svc.InviteUser(ctx, inviteReq, "owner-id"); err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+	})
+}
+
+func TestSearchSuggestions(t *testing.T) {
+	t.Run("passes the trimmed prefix and limit through", func(t *testing.T) {
+		svc, mockRepo, _, _, _, _, ctx := newTestService(t)
+
+		expected := []SearchItemDTO{{Id: "org-1", Name: "acme", ItemType: SearchItemTypeOrganization}}
+		mockRepo.EXPECT().
+			SearchSuggestions(ctx, "user-1", "ac", 3).
+			Return(expected, nil)
+
+		items, err := svc.SearchSuggestions(ctx, "user-1", "  ac ", 3)
+		if err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+		if len(items) != 1 || items[0].Id != "org-1" {
+			t.Errorf("expected the repository results, got %+v", items)
+		}
+	})
+
+	t.Run("defaults and caps the limit", func(t *testing.T) {
+		svc, mockRepo, _, _, _, _, ctx := newTestService(t)
+
+		mockRepo.EXPECT().
+			SearchSuggestions(ctx, "user-1", "ac", defaultSearchSuggestionLimit).
+			Return(nil, nil)
+		mockRepo.EXPECT().
+			SearchSuggestions(ctx, "user-1", "ac", maxSearchSuggestionLimit).
+			Return(nil, nil)
+
+		if _, err := svc.SearchSuggestions(ctx, "user-1", "ac", 0); err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+		if _, err := svc.SearchSuggestions(ctx, "user-1", "ac", 1000); err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+	})
+
+	t.Run("empty prefix skips the query", func(t *testing.T) {
+		svc, _, _, _, _, _, ctx := newTestService(t)
+
+		items, err := svc.SearchSuggestions(ctx, "user-1", "   ", 5)
+		if err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+		if len(items) != 0 {
+			t.Errorf("expected no suggestions, got %+v", items)
+		}
+	})
+}
+
+func TestInviteStatus_CanTransitionTo(t *testing.T) {
+	statuses := []InviteStatus{InviteStatusPending, InviteStatusAccepted, InviteStatusExpired, InviteStatusCancelled}
+
+	for _, from := range statuses {
+		for _, to := range statuses {
+			expected := from == InviteStatusPending && to != InviteStatusPending
+			if got := from.CanTransitionTo(to); got != expected {
+				t.Errorf("%s -> %s: expected %v, got %v", from, to, expected, got)
+			}
+		}
+	}
+}
+
+func TestCreateOrganization_ReservedNamesFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "reserved-names.txt")
+	if err := os.WriteFile(path, []byte("# brands\nacme\n"), 0o600); err != nil {
+		t.Fatalf("failed to write reserved names file: %v", err)
+	}
+
+	newService := func(t *testing.T) (Service, *MockRepository) {
+		ctrl := gomock.NewController(t)
+		mockRepo := NewMockRepository(ctrl)
+		cfg := &config.Config{ReservedNamesFile: path}
+		svc := NewService(mockRepo, NewMockQueue(ctrl), registry.NewMockService(ctrl), email.NewMockService(ctrl), user.NewMockRepository(ctrl), cfg)
+		return svc, mockRepo
+	}
+
+	t.Run("file-supplied name is rejected", func(t *testing.T) {
+		svc, _ := newService(t)
+
+		err := svc.CreateOrganization(context.Background(), &organizationv1.CreateOrganizationRequest{
+			Name:       "Acme",
+			Visibility: shared.Visibility_VISIBILITY_PRIVATE,
+		}, "", "user-123")
+		if connect.CodeOf(err) != connect.CodeInvalidArgument {
+			t.Fatalf("expected invalid argument, got %v", err)
+		}
+	})
+
+	t.Run("other names pass", func(t *testing.T) {
+		svc, mockRepo := newService(t)
+		ctx := context.Background()
+
+		mockRepo.EXPECT().
+			GetOrganizationByName(ctx, "acme-labs").
+			Return(nil, ErrOrganizationNotFound)
+		mockRepo.EXPECT().
+			CreateOrganizationWithOwner(ctx, gomock.Any(), gomock.Any()).
+			Return(nil)
+
+		err := svc.CreateOrganization(ctx, &organizationv1.CreateOrganizationRequest{
+			Name:       "acme-labs",
+			Visibility: shared.Visibility_VISIBILITY_PRIVATE,
+		}, "", "user-123")
+		if err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+	})
+}
+
+func TestGetInvitesForEmail(t *testing.T) {
+	t.Run("returns the invites for the caller's email", func(t *testing.T) {
+		svc, mockRepo, _, _, _, _, _ := newTestService(t)
+		ctx := context.WithValue(context.Background(), authentication.UserEmailKey, "friend@example.com")
+
+		expected := []OrganizationInviteDetailsDTO{
+			{OrganizationInviteDTO: OrganizationInviteDTO{Id: "invite-1", Role: MemberRoleReader, Status: InviteStatusPending}, OrganizationName: "Acme", InviterUsername: "inviter"},
+		}
+		mockRepo.EXPECT().
+			GetInvitesForEmail(ctx, "friend@example.com").
+			Return(expected, nil)
+
+		invites, err := svc.GetInvitesForEmail(ctx, "friend@example.com")
+		if err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+		if len(invites) != 1 || invites[0].Id != "invite-1" {
+			t.Errorf("expected invite-1, got %+v", invites)
+		}
+	})
+
+	t.Run("rejects a case variant of the caller's email", func(t *testing.T) {
+		svc, _, _, _, _, _, _ := newTestService(t)
+		ctx := context.WithValue(context.Background(), authentication.UserEmailKey, "friend@example.com")
+
+		_, err := svc.GetInvitesForEmail(ctx, "Friend@example.com")
+		if connect.CodeOf(err) != connect.CodePermissionDenied {
+			t.Fatalf("expected permission denied, got %v", err)
+		}
+	})
+
+	t.Run("rejects another user's email", func(t *testing.T) {
+		svc, _, _, _, _, _, _ := newTestService(t)
+		ctx := context.WithValue(context.Background(), authentication.UserEmailKey, "friend@example.com")
+
+		_, err := svc.GetInvitesForEmail(ctx, "someone@example.com")
+		if connect.CodeOf(err) != connect.CodePermissionDenied {
+			t.Fatalf("expected permission denied, got %v", err)
+		}
+	})
+
+	t.Run("requires authentication", func(t *testing.T) {
+		svc, _, _, _, _, _, _ := newTestService(t)
+
+		_, err := svc.GetInvitesForEmail(context.Background(), "friend@example.com")
+		if err == nil {
+			t.Fatal("expected error, got nil")
+		}
+	})
+}
+
+func TestCreateOrganization_MaxInvitesPerRequest(t *testing.T) {
+	newService := func(t *testing.T) (Service, *MockRepository, *MockQueue, *user.MockRepository) {
+		ctrl := gomock.NewController(t)
+		mockRepo := NewMockRepository(ctrl)
+		mockQueue := NewMockQueue(ctrl)
+		mockUserRepo := user.NewMockRepository(ctrl)
+		cfg := &config.Config{Organization: config.OrganizationConfig{MaxInvitesPerRequest: 2}}
+		svc := NewService(mockRepo, mockQueue, registry.NewMockService(ctrl), email.NewMockService(ctrl), mockUserRepo, cfg)
+		return svc, mockRepo, mockQueue, mockUserRepo
+	}
+
+	t.Run("batch at the limit is accepted", func(t *testing.T) {
+		svc, mockRepo, mockQueue, mockUserRepo := newService(t)
+		ctx := context.Background()
+
+		mockRepo.EXPECT().
+			GetOrganizationByName(ctx, "test-org").
+			Return(nil, ErrOrganizationNotFound)
+		mockRepo.EXPECT().
+			CreateOrganizationWithOwner(ctx, gomock.Any(), gomock.Any()).
+			Return(nil)
+		mockUserRepo.EXPECT().
+			GetUsersByEmails(ctx, []string{"friend1@example.com", "friend2@example.com"}).
+			Return(map[string]*user.UserDTO{
+				"friend1@example.com": {},
+				"friend2@example.com": {},
+			}, nil)
+		mockRepo.EXPECT().
+			CreateInvites(ctx, gomock.Any()).
+			DoAndReturn(func(_ context.Context, invites []*OrganizationInviteDTO) error {
+				if len(invites) != 2 {
+					t.Errorf("expected 2 invites, got %d", len(invites))
+				}
+				return nil
+			})
+		mockQueue.EXPECT().
+			EnqueueEmailJobs(ctx, gomock.Any()).
+			Return(nil)
+
+		err := svc.CreateOrganization(ctx, &organizationv1.CreateOrganizationRequest{
+			Name:       "test-org",
+			Visibility: shared.Visibility_VISIBILITY_PRIVATE,
+			Members: []*organizationv1.InvitationMember{
+				{Email: "friend1@example.com", Role: shared.Role_ROLE_AUTHOR},
+				{Email: "friend2@example.com", Role: shared.Role_ROLE_READER},
+			},
+		}, "", "user-123")
+		if err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+	})
+
+	t.Run("batch over the limit is rejected before anything is created", func(t *testing.T) {
+		svc, _, _, _ := newService(t)
+
+		err := svc.CreateOrganization(context.Background(), &organizationv1.CreateOrganizationRequest{
+			Name:       "test-org",
+			Visibility: shared.Visibility_VISIBILITY_PRIVATE,
+			Members: []*organizationv1.InvitationMember{
+				{Email: "friend1@example.com"},
+				{Email: "friend2@example.com"},
+				{Email: "friend3@example.com"},
+			},
+		}, "", "user-123")
+		if connect.CodeOf(err) != connect.CodeInvalidArgument {
+			t.Fatalf("expected invalid argument, got %v", err)
+		}
+	})
+}
+
+func TestCreateOrganization_CaseInsensitiveName(t *testing.T) {
+	t.Run("slug is stored lower case and the name as typed is the display name", func(t *testing.T) {
+		svc, mockRepo, _, _, _, _, ctx := newTestService(t)
+
+		mockRepo.EXPECT().
+			GetOrganizationByName(ctx, "myorg").
+			Return(nil, ErrOrganizationNotFound)
+
+		var created *OrganizationDTO
+		mockRepo.EXPECT().
+			CreateOrganizationWithOwner(ctx, gomock.Any(), gomock.Any()).
+			DoAndReturn(func(_ context.Context, org *OrganizationDTO, _ *OrganizationMemberDTO) error {
+				created = org
+				return nil
+			})
+
+		err := svc.CreateOrganization(ctx, &organizationv1.CreateOrganizationRequest{
+			Name:       "MyOrg",
+			Visibility: shared.Visibility_VISIBILITY_PRIVATE,
+		}, "", "user-123")
+		if err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+
+		if created.Slug != "myorg" {
+			t.Errorf("expected slug %q, got %q", "myorg", created.Slug)
+		}
+		if created.DisplayName != "MyOrg" {
+			t.Errorf("expected display name %q, got %q", "MyOrg", created.DisplayName)
+		}
+	})
+
+	t.Run("case-variant duplicate is rejected", func(t *testing.T) {
+		svc, mockRepo, _, _, _, _, ctx := newTestService(t)
+
+		mockRepo.EXPECT().
+			GetOrganizationByName(ctx, "myorg").
+			Return(&OrganizationDTO{Id: "org-1", Slug: "myorg"}, nil)
+
+		err := svc.CreateOrganization(ctx, &organizationv1.CreateOrganizationRequest{
+			Name:       "MYORG",
+			Visibility: shared.Visibility_VISIBILITY_PRIVATE,
+		}, "", "user-123")
+		if connect.CodeOf(err) != connect.CodeAlreadyExists {
+			t.Fatalf("expected already exists, got %v", err)
+		}
+	})
+}
+
+func badRequestViolations(t *testing.T, err error) map[string]string {
+	t.Helper()
+
+	var connectErr *connect.Error
+	if !errors.As(err, &connectErr) || connectErr.Code() != connect.CodeInvalidArgument {
+		t.Fatalf("expected an InvalidArgument error, got %v", err)
+	}
+
+	violations := make(map[string]string)
+	for _, detail := range connectErr.Details() {
+		value, err := detail.Value()
+		if err != nil {
+			t.Fatalf("failed to decode error detail: %v", err)
+		}
+		if badRequest, ok := value.(*errdetails.BadRequest); ok {
+			for _, violation := range badRequest.GetFieldViolations() {
+				violations[violation.GetField()] = violation.GetDescription()
+			}
+		}
+	}
+
+	return violations
+}
+
+func TestCreateOrganization_InvalidInvites(t *testing.T) {
+	svc, _, _, _, _, _, ctx := newTestService(t)
+	req := &organizationv1.CreateOrganizationRequest{
+		Name:       "test-org",
+		Visibility: shared.Visibility_VISIBILITY_PUBLIC,
+		Members: []*organizationv1.InvitationMember{
+			{Email: "valid@example.com", Role: shared.Role_ROLE_AUTHOR},
+			{Email: "not-an-email", Role: shared.Role_ROLE_READER},
+			{Email: "also-valid@example.com"},
+			{Email: "Friend <friend@example.com>", Role: shared.Role_ROLE_AUTHOR},
+			{Email: "", Role: shared.Role(42)},
+			{Email: "third@example.com", Role: shared.Role(7)},
+		},
+	}
+
+	// Nothing is looked up or written, so no mock expectations are set.
+	err := svc.CreateOrganization(ctx, req, "", "user-123")
+	violations := badRequestViolations(t, err)
+
+	expected := []string{"members[1].email", "members[3].email", "members[4].email", "members[4].role", "members[5].role"}
+	if len(violations) != len(expected) {
+		t.Fatalf("expected %d violations, got %v", len(expected), violations)
+	}
+	for _, field := range expected {
+		if _, ok := violations[field]; !ok {
+			t.Errorf("expected a violation for %s, got %v", field, violations)
+		}
+		if !strings.Contains(err.Error(), field) {
+			t.Errorf("expected the message to mention %s, got %q", field, err.Error())
+		}
+	}
+	if violations["members[4].email"] != "email is required" {
+		t.Errorf("unexpected description for a missing email: %q", violations["members[4].email"])
+	}
+}
+
+func TestInviteUser_InvalidInvite(t *testing.T) {
+	svc, _, _, _, _, _, ctx := newTestService(t)
+
+	err := svc.InviteUser(ctx, &organizationv1.InviteMemberRequest{
+		Id:    "org-123",
+		Email: "friend@",
+		Role:  shared.Role(9),
+	}, "user-123")
+	violations := badRequestViolations(t, err)
+
+	if len(violations) != 2 {
+		t.Fatalf("expected 2 violations, got %v", violations)
+	}
+	if _, ok := violations["email"]; !ok {
+		t.Errorf("expected a violation for email, got %v", violations)
+	}
+	if _, ok := violations["role"]; !ok {
+		t.Errorf("expected a violation for role, got %v", violations)
+	}
+}
@@ -0,0 +1,74 @@
+package registry
+
+import (
+	"errors"
+	"sync"
+
+	"connectrpc.com/connect"
+)
+
+var ErrTooManyClones = connect.NewError(connect.CodeResourceExhausted, errors.New("too many concurrent clones; wait for one to finish and try again"))
+
+// cloneSlots counts the git reads each user or deploy token has in flight.
+// Like pathLocks, entries are dropped once a key has nothing running and the
+// zero value is ready to use.
+type cloneSlots struct {
+	mu     sync.Mutex
+	active map[string]int
+}
+
+// tryAcquire takes one of the limit slots of key without waiting and reports
+// whether there was one free.
+func (c *cloneSlots) tryAcquire(key string, limit int) (release func(), ok bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.active[key] >= limit {
+		return nil, false
+	}
+	if c.active == nil {
+		c.active = make(map[string]int)
+	}
+	c.active[key]++
+
+	var once sync.Once
+	return func() {
+		once.Do(func() {
+			c.mu.Lock()
+			defer c.mu.Unlock()
+
+			c.active[key]--
+			if c.active[key] == 0 {
+				delete(c.active, key)
+			}
+		})
+	}, true
+}
+
+// deployTokenCloneKey is the clone slot key of a deploy token. The prefix keeps
+// token ids apart from user ids.
+func deployTokenCloneKey(tokenId string) string {
+	return "deploy:" + tokenId
+}
+
+// AcquireCloneSlot admits a fetch or clone by key, the user id or the
+// deployTokenCloneKey of the caller, when it is below the configured number of
+// concurrent ones, and fails with ErrTooManyClones otherwise instead of
+// queueing. Reads without a key, such as anonymous clones of public
+// repositories, are not limited.
+func (s *service) AcquireCloneSlot(key string) (release func(), err error) {
+	limit := 0
+	if s.cfg != nil {
+		limit = s.cfg.Repository.MaxConcurrentClonesPerUser
+	}
+	if limit <= 0 || key == "" {
+		return func() {}, nil
+	}
+
+	release, ok := s.cloneSlots.tryAcquire(key, limit)
+	if !ok {
+		return nil, ErrTooManyClones
+	}
+
+	return release, nil
+}
@@ -0,0 +1,79 @@
+package registry
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"hasir-api/pkg/config"
+)
+
+func TestService_AcquireCloneSlot(t *testing.T) {
+	newService := func(limit int) *service {
+		return &service{cfg: &config.Config{Repository: config.RepositoryConfig{MaxConcurrentClonesPerUser: limit}}}
+	}
+
+	t.Run("clone beyond the limit is rejected while others proceed", func(t *testing.T) {
+		const limit = 3
+		s := newService(limit)
+
+		releases := make([]func(), 0, limit)
+		for range limit {
+			release, err := s.AcquireCloneSlot("user-1")
+			require.NoError(t, err)
+			releases = append(releases, release)
+		}
+
+		_, err := s.AcquireCloneSlot("user-1")
+		assert.ErrorIs(t, err, ErrTooManyClones)
+
+		release, err := s.AcquireCloneSlot("user-2")
+		require.NoError(t, err)
+		release()
+
+		releases[0]()
+		release, err = s.AcquireCloneSlot("user-1")
+		require.NoError(t, err)
+		release()
+
+		for _, release := range releases[1:] {
+			release()
+		}
+		assert.Empty(t, s.cloneSlots.active)
+	})
+
+	t.Run("releasing twice frees one slot", func(t *testing.T) {
+		s := newService(1)
+
+		release, err := s.AcquireCloneSlot("user-1")
+		require.NoError(t, err)
+		release()
+		release()
+
+		release, err = s.AcquireCloneSlot("user-1")
+		require.NoError(t, err)
+		defer release()
+
+		_, err = s.AcquireCloneSlot("user-1")
+		assert.ErrorIs(t, err, ErrTooManyClones)
+	})
+
+	t.Run("zero means no limit", func(t *testing.T) {
+		s := newService(0)
+
+		for range 10 {
+			_, err := s.AcquireCloneSlot("user-1")
+			require.NoError(t, err)
+		}
+	})
+
+	t.Run("reads without a user are not limited", func(t *testing.T) {
+		s := newService(1)
+
+		for range 3 {
+			_, err := s.AcquireCloneSlot("")
+			require.NoError(t, err)
+		}
+	})
+}
@@ -0,0 +1,109 @@
+package registry
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"connectrpc.com/connect"
+
+	"hasir-api/pkg/authentication"
+	"hasir-api/pkg/authorization"
+)
+
+// CommitActivityBucket is the width of one commit activity data point.
+type CommitActivityBucket string
+
+const (
+	CommitActivityBucketDay  CommitActivityBucket = "day"
+	CommitActivityBucketWeek CommitActivityBucket = "week"
+)
+
+// maxCommitActivityBuckets bounds the size of a single response, a year of
+// daily counts.
+const maxCommitActivityBuckets = 366
+
+// CommitActivity counts the commits made in the bucket starting at Start.
+type CommitActivity struct {
+	Start time.Time
+	Count int
+}
+
+// Truncate returns the start of the bucket t falls into, in UTC. Weeks start
+// on Monday.
+func (b CommitActivityBucket) Truncate(t time.Time) time.Time {
+	t = t.UTC()
+	day := time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+	if b == CommitActivityBucketWeek {
+		daysSinceMonday := (int(day.Weekday()) + 6) % 7
+		return day.AddDate(0, 0, -daysSinceMonday)
+	}
+
+	return day
+}
+
+// Next returns the start of the bucket after the one starting at start.
+func (b CommitActivityBucket) Next(start time.Time) time.Time {
+	if b == CommitActivityBucketWeek {
+		return start.AddDate(0, 0, 7)
+	}
+
+	return start.AddDate(0, 0, 1)
+}
+
+// NewCommitActivity lays out one empty bucket for every bucket overlapping
+// [from, to).
+func NewCommitActivity(bucket CommitActivityBucket, from, to time.Time) []CommitActivity {
+	var activity []CommitActivity
+	for start := bucket.Truncate(from); start.Before(to); start = bucket.Next(start) {
+		activity = append(activity, CommitActivity{Start: start})
+	}
+
+	return activity
+}
+
+func validateCommitActivityRange(bucket CommitActivityBucket, from, to time.Time) error {
+	if bucket != CommitActivityBucketDay && bucket != CommitActivityBucketWeek {
+		return connect.NewError(connect.CodeInvalidArgument, fmt.Errorf("bucket must be %q or %q", CommitActivityBucketDay, CommitActivityBucketWeek))
+	}
+
+	if from.IsZero() || to.IsZero() || !from.Before(to) {
+		return connect.NewError(connect.CodeInvalidArgument, errors.New("from must be before to"))
+	}
+
+	buckets := 0
+	for start := bucket.Truncate(from); start.Before(to); start = bucket.Next(start) {
+		buckets++
+		if buckets > maxCommitActivityBuckets {
+			return connect.NewError(connect.CodeInvalidArgument, fmt.Errorf("time range spans more than %d buckets", maxCommitActivityBuckets))
+		}
+	}
+
+	return nil
+}
+
+// GetCommitActivity counts the commits reachable from HEAD per bucket over
+// [from, to), including empty buckets, for the repository insights sparkline.
+func (s *service) GetCommitActivity(ctx context.Context, repoId, bucket string, from, to time.Time) ([]CommitActivity, error) {
+	activityBucket := CommitActivityBucket(bucket)
+	if err := validateCommitActivityRange(activityBucket, from, to); err != nil {
+		return nil, err
+	}
+
+	userId, err := authentication.MustGetUserID(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	repo, err := s.repository.GetRepositoryById(ctx, repoId)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := authorization.IsUserMember(ctx, s.orgRepo, repo.OrganizationId, userId); err != nil {
+		return nil, err
+	}
+
+	return s.repository.GetCommitActivity(ctx, repo.Path, activityBucket, from, to)
+}
@@ -0,0 +1,91 @@
+package registry
+
+import (
+	"testing"
+	"time"
+
+	"connectrpc.com/connect"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+
+	"hasir-api/pkg/authorization"
+)
+
+func TestService_GetCommitActivity(t *testing.T) {
+	from := time.Date(2024, time.January, 1, 0, 0, 0, 0, time.UTC)
+	to := from.AddDate(0, 0, 7)
+
+	t.Run("members get the activity of the repository", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		mockRepo := NewMockRepository(ctrl)
+		mockOrgRepo := authorization.NewMockMemberRoleChecker(ctrl)
+		svc := &service{repository: mockRepo, orgRepo: mockOrgRepo}
+		ctx := testAuthInterceptor("user-1")
+
+		expected := []CommitActivity{{Start: from, Count: 4}}
+		mockRepo.EXPECT().
+			GetRepositoryById(ctx, "repo-1").
+			Return(&RepositoryDTO{Id: "repo-1", OrganizationId: "org-1", Path: "/repos/repo-1"}, nil)
+		mockOrgRepo.EXPECT().
+			GetMemberRole(ctx, "org-1", "user-1").
+			Return(authorization.MemberRoleReader, nil)
+		mockRepo.EXPECT().
+			GetCommitActivity(ctx, "/repos/repo-1", CommitActivityBucketWeek, from, to).
+			Return(expected, nil)
+
+		activity, err := svc.GetCommitActivity(ctx, "repo-1", "week", from, to)
+		require.NoError(t, err)
+		assert.Equal(t, expected, activity)
+	})
+
+	t.Run("non-members are denied", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		mockRepo := NewMockRepository(ctrl)
+		mockOrgRepo := authorization.NewMockMemberRoleChecker(ctrl)
+		svc := &service{repository: mockRepo, orgRepo: mockOrgRepo}
+		ctx := testAuthInterceptor("user-1")
+
+		mockRepo.EXPECT().
+			GetRepositoryById(ctx, "repo-1").
+			Return(&RepositoryDTO{Id: "repo-1", OrganizationId: "org-1"}, nil)
+		mockOrgRepo.EXPECT().
+			GetMemberRole(ctx, "org-1", "user-1").
+			Return("", authorization.ErrMemberNotFound)
+
+		_, err := svc.GetCommitActivity(ctx, "repo-1", "day", from, to)
+		assert.Equal(t, connect.CodePermissionDenied, connect.CodeOf(err))
+	})
+
+	t.Run("invalid arguments", func(t *testing.T) {
+		tests := []struct {
+			name     string
+			bucket   string
+			from, to time.Time
+		}{
+			{name: "unknown bucket", bucket: "month", from: from, to: to},
+			{name: "empty bucket", bucket: "", from: from, to: to},
+			{name: "reversed range", bucket: "day", from: to, to: from},
+			{name: "empty range", bucket: "day", from: from, to: from},
+			{name: "missing bounds", bucket: "day"},
+			{name: "too many daily buckets", bucket: "day", from: from, to: from.AddDate(2, 0, 0)},
+			{name: "too many weekly buckets", bucket: "week", from: from, to: from.AddDate(10, 0, 0)},
+		}
+
+		for _, tt := range tests {
+			t.Run(tt.name, func(t *testing.T) {
+				svc := &service{}
+
+				_, err := svc.GetCommitActivity(testAuthInterceptor("user-1"), "repo-1", tt.bucket, tt.from, tt.to)
+				assert.Equal(t, connect.CodeInvalidArgument, connect.CodeOf(err))
+			})
+		}
+	})
+}
+
+func TestCommitActivityBucket_Truncate(t *testing.T) {
+	wednesday := time.Date(2024, time.January, 3, 17, 30, 0, 0, time.FixedZone("UTC+3", 3*60*60))
+
+	assert.Equal(t, time.Date(2024, time.January, 3, 0, 0, 0, 0, time.UTC), CommitActivityBucketDay.Truncate(wednesday))
+	assert.Equal(t, time.Date(2024, time.January, 1, 0, 0, 0, 0, time.UTC), CommitActivityBucketWeek.Truncate(wednesday))
+}
@@ -0,0 +1,84 @@
+package registry
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"connectrpc.com/connect"
+
+	registryv1 "buf.build/gen/go/hasir/hasir/protocolbuffers/go/registry/v1"
+
+	"hasir-api/pkg/authentication"
+	"hasir-api/pkg/authorization"
+)
+
+// MaxCommitRangeSize bounds the commits one GetCommitRange call returns.
+const MaxCommitRangeSize = 1000
+
+// maxRevisionLength is far above any real branch, tag or hash.
+const maxRevisionLength = 255
+
+var ErrUnrelatedRefs = connect.NewError(connect.CodeFailedPrecondition, errors.New("base and head share no history"))
+
+// CommitRange lists the commits reachable from head but not from base, newest
+// first. Truncated is set when there were more than MaxCommitRangeSize.
+type CommitRange struct {
+	Commits   []*registryv1.Commit
+	Truncated bool
+}
+
+// validateRevision accepts branch and tag names and commit hashes. It rejects
+// anything git could read as an option or as revision syntax, so a ref can
+// never name more than one commit or change what the command does.
+func validateRevision(field, rev string) error {
+	if rev == "" {
+		return connect.NewError(connect.CodeInvalidArgument, fmt.Errorf("%s is required", field))
+	}
+	if len(rev) > maxRevisionLength {
+		return connect.NewError(connect.CodeInvalidArgument, fmt.Errorf("%s must be at most %d characters", field, maxRevisionLength))
+	}
+	if strings.HasPrefix(rev, "-") || strings.HasPrefix(rev, "/") || strings.HasSuffix(rev, "/") ||
+		strings.HasSuffix(rev, ".") || strings.HasSuffix(rev, ".lock") ||
+		strings.Contains(rev, "..") || strings.Contains(rev, "//") || strings.Contains(rev, "@{") ||
+		rev == "@" || strings.ContainsAny(rev, " ~^:?*[\\") {
+		return connect.NewError(connect.CodeInvalidArgument, fmt.Errorf("%s %q is not a valid ref", field, rev))
+	}
+	for _, r := range rev {
+		if r < 0x20 || r == 0x7f {
+			return connect.NewError(connect.CodeInvalidArgument, fmt.Errorf("%s %q is not a valid ref", field, rev))
+		}
+	}
+
+	return nil
+}
+
+// GetCommitRange lists the commits between baseRef and headRef, as
+// `git log base..head` would, for release notes. Either ref may be a branch,
+// a tag or a commit hash. Refs with no common history are rejected with
+// ErrUnrelatedRefs rather than listing all of head.
+func (s *service) GetCommitRange(ctx context.Context, repoId, baseRef, headRef string) (*CommitRange, error) {
+	if err := validateRevision("base ref", baseRef); err != nil {
+		return nil, err
+	}
+	if err := validateRevision("head ref", headRef); err != nil {
+		return nil, err
+	}
+
+	userId, err := authentication.MustGetUserID(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	repo, err := s.repository.GetRepositoryById(ctx, repoId)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := authorization.IsUserMember(ctx, s.orgRepo, repo.OrganizationId, userId); err != nil {
+		return nil, err
+	}
+
+	return s.repository.GetCommitRange(ctx, repo.Path, baseRef, headRef, MaxCommitRangeSize)
+}
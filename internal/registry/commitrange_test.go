@@ -0,0 +1,98 @@
+package registry
+
+import (
+	"testing"
+
+	registryv1 "buf.build/gen/go/hasir/hasir/protocolbuffers/go/registry/v1"
+	"connectrpc.com/connect"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+
+	"hasir-api/pkg/authorization"
+)
+
+func TestValidateRevision(t *testing.T) {
+	for _, rev := range []string{"main", "v1.2.0", "release/2024-01", "feature_x", "0f3c1a9", "a3b5e1f0c2d4a6b8c0e2f4a6b8d0c2e4f6a8b0c2"} {
+		assert.NoError(t, validateRevision("ref", rev), rev)
+	}
+
+	for _, rev := range []string{
+		"",
+		"--output=/tmp/x",
+		"-n1",
+		"main..evil",
+		"main...evil",
+		"HEAD~1",
+		"HEAD^",
+		"main@{1}",
+		"@",
+		"main:file",
+		"a b",
+		"tag\n",
+		"/abs",
+		"trailing/",
+		"a//b",
+		"refs/heads/x.lock",
+		"glob*",
+	} {
+		err := validateRevision("ref", rev)
+		assert.Equal(t, connect.CodeInvalidArgument, connect.CodeOf(err), "%q", rev)
+	}
+}
+
+func TestService_GetCommitRange(t *testing.T) {
+	setup := func(t *testing.T) (*service, *MockRepository, *authorization.MockMemberRoleChecker) {
+		ctrl := gomock.NewController(t)
+		mockRepo := NewMockRepository(ctrl)
+		mockOrgRepo := authorization.NewMockMemberRoleChecker(ctrl)
+
+		return &service{repository: mockRepo, orgRepo: mockOrgRepo}, mockRepo, mockOrgRepo
+	}
+
+	t.Run("members get the commits between the refs", func(t *testing.T) {
+		svc, mockRepo, mockOrgRepo := setup(t)
+		ctx := testAuthInterceptor("user-1")
+
+		expected := &CommitRange{Commits: []*registryv1.Commit{{Id: "abc"}}}
+		mockRepo.EXPECT().
+			GetRepositoryById(ctx, "repo-1").
+			Return(&RepositoryDTO{Id: "repo-1", OrganizationId: "org-1", Path: "/repos/repo-1"}, nil)
+		mockOrgRepo.EXPECT().
+			GetMemberRole(ctx, "org-1", "user-1").
+			Return(authorization.MemberRoleReader, nil)
+		mockRepo.EXPECT().
+			GetCommitRange(ctx, "/repos/repo-1", "v1.0.0", "main", MaxCommitRangeSize).
+			Return(expected, nil)
+
+		commitRange, err := svc.GetCommitRange(ctx, "repo-1", "v1.0.0", "main")
+		require.NoError(t, err)
+		assert.Equal(t, expected, commitRange)
+	})
+
+	t.Run("non-members are denied", func(t *testing.T) {
+		svc, mockRepo, mockOrgRepo := setup(t)
+		ctx := testAuthInterceptor("user-1")
+
+		mockRepo.EXPECT().
+			GetRepositoryById(ctx, "repo-1").
+			Return(&RepositoryDTO{Id: "repo-1", OrganizationId: "org-1", Path: "/repos/repo-1"}, nil)
+		mockOrgRepo.EXPECT().
+			GetMemberRole(ctx, "org-1", "user-1").
+			Return("", authorization.ErrMemberNotFound)
+
+		_, err := svc.GetCommitRange(ctx, "repo-1", "v1.0.0", "main")
+		assert.Equal(t, connect.CodePermissionDenied, connect.CodeOf(err))
+	})
+
+	t.Run("invalid refs are rejected before any lookup", func(t *testing.T) {
+		svc, _, _ := setup(t)
+		ctx := testAuthInterceptor("user-1")
+
+		_, err := svc.GetCommitRange(ctx, "repo-1", "--all", "main")
+		assert.Equal(t, connect.CodeInvalidArgument, connect.CodeOf(err))
+
+		_, err = svc.GetCommitRange(ctx, "repo-1", "v1.0.0", "")
+		assert.Equal(t, connect.CodeInvalidArgument, connect.CodeOf(err))
+	})
+}
@@ -0,0 +1,154 @@
+package registry
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"connectrpc.com/connect"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+
+	"hasir-api/pkg/authentication"
+	"hasir-api/pkg/authorization"
+)
+
+// DeployTokenPrefix marks deploy tokens, so the git handlers can tell them
+// apart from user API keys sent in the same basic auth password field.
+const DeployTokenPrefix = "hdt_"
+
+// IsDeployToken reports whether credential has the shape of a deploy token.
+func IsDeployToken(credential string) bool {
+	return strings.HasPrefix(credential, DeployTokenPrefix)
+}
+
+func generateDeployToken() (string, error) {
+	bytes := make([]byte, 32)
+	if _, err := rand.Read(bytes); err != nil {
+		return "", err
+	}
+
+	return DeployTokenPrefix + hex.EncodeToString(bytes), nil
+}
+
+// CreateDeployToken issues a token that grants access to repoId alone; a
+// read-only token can clone and fetch but never push. Only organization
+// owners can create one.
+func (s *service) CreateDeployToken(ctx context.Context, repoId string, readOnly bool) (*DeployTokenDTO, error) {
+	userId, err := authentication.MustGetUserID(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	repo, err := s.repository.GetRepositoryById(ctx, repoId)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := authorization.IsUserOwner(ctx, s.orgRepo, repo.OrganizationId, userId); err != nil {
+		return nil, err
+	}
+
+	token, err := generateDeployToken()
+	if err != nil {
+		return nil, connect.NewError(connect.CodeInternal, fmt.Errorf("failed to generate deploy token: %w", err))
+	}
+
+	deployToken := &DeployTokenDTO{
+		Id:           uuid.NewString(),
+		RepositoryId: repo.Id,
+		Token:        token,
+		ReadOnly:     readOnly,
+		CreatedBy:    userId,
+		CreatedAt:    time.Now().UTC(),
+	}
+	if err := s.repository.CreateDeployToken(ctx, deployToken); err != nil {
+		return nil, err
+	}
+
+	zap.L().Info("deploy token created",
+		zap.String("tokenId", deployToken.Id),
+		zap.String("repositoryId", repo.Id),
+		zap.Bool("readOnly", readOnly),
+		zap.String("createdBy", userId))
+
+	return deployToken, nil
+}
+
+// RevokeDeployToken stops tokenId from opening repoId. Like creation, it is
+// limited to organization owners.
+func (s *service) RevokeDeployToken(ctx context.Context, repoId, tokenId string) error {
+	userId, err := authentication.MustGetUserID(ctx)
+	if err != nil {
+		return err
+	}
+
+	repo, err := s.repository.GetRepositoryById(ctx, repoId)
+	if err != nil {
+		return err
+	}
+
+	if err := authorization.IsUserOwner(ctx, s.orgRepo, repo.OrganizationId, userId); err != nil {
+		return err
+	}
+
+	if err := s.repository.RevokeDeployToken(ctx, repo.Id, tokenId); err != nil {
+		return err
+	}
+
+	zap.L().Info("deploy token revoked",
+		zap.String("tokenId", tokenId),
+		zap.String("repositoryId", repo.Id),
+		zap.String("revokedBy", userId))
+
+	return nil
+}
+
+// ValidateDeployTokenAccess is the deploy token counterpart of
+// ValidateSshAccess. It also returns the id of the token so the caller can
+// key per-token limits on it. An unknown token is returned as NotFound so the
+// caller can ask for credentials again; a token for another repository is
+// denied.
+func (s *service) ValidateDeployTokenAccess(ctx context.Context, token, repoPath string, operation SshOperation) (string, bool, error) {
+	deployToken, err := s.repository.GetDeployToken(ctx, token)
+	if err != nil {
+		return "", false, err
+	}
+
+	repoId := filepath.Base(repoPath)
+	if deployToken.RepositoryId != repoId {
+		zap.L().Warn("deploy token access denied: token belongs to another repository",
+			zap.String("tokenId", deployToken.Id),
+			zap.String("repoPath", repoPath))
+		return deployToken.Id, false, nil
+	}
+
+	switch operation {
+	case SshOperationRead:
+		return deployToken.Id, true, nil
+	case SshOperationWrite:
+		if deployToken.ReadOnly {
+			zap.L().Warn("deploy token write access denied: token is read-only",
+				zap.String("tokenId", deployToken.Id),
+				zap.String("repoPath", repoPath))
+			return deployToken.Id, false, nil
+		}
+
+		repo, err := s.repository.GetRepositoryById(ctx, repoId)
+		if err != nil {
+			return deployToken.Id, false, err
+		}
+		if repo.Archived {
+			return deployToken.Id, false, ErrRepositoryArchived
+		}
+
+		return deployToken.Id, true, nil
+	default:
+		return deployToken.Id, false, errors.New("unknown SSH operation")
+	}
+}
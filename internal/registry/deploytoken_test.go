@@ -0,0 +1,172 @@
+package registry
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"connectrpc.com/connect"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+
+	"hasir-api/pkg/authorization"
+)
+
+func TestService_CreateDeployToken(t *testing.T) {
+	setup := func(t *testing.T, role string) (*service, *MockRepository, context.Context) {
+		ctrl := gomock.NewController(t)
+		mockRepo := NewMockRepository(ctrl)
+		mockOrgRepo := authorization.NewMockMemberRoleChecker(ctrl)
+		ctx := testAuthInterceptor("user-1")
+
+		mockRepo.EXPECT().
+			GetRepositoryById(ctx, "repo-1").
+			Return(&RepositoryDTO{Id: "repo-1", OrganizationId: "org-1"}, nil)
+		mockOrgRepo.EXPECT().
+			GetMemberRole(ctx, "org-1", "user-1").
+			Return(role, nil)
+
+		return &service{repository: mockRepo, orgRepo: mockOrgRepo}, mockRepo, ctx
+	}
+
+	t.Run("owner gets a prefixed token for the repository", func(t *testing.T) {
+		svc, mockRepo, ctx := setup(t, authorization.MemberRoleOwner)
+
+		var stored *DeployTokenDTO
+		mockRepo.EXPECT().
+			CreateDeployToken(ctx, gomock.Any()).
+			DoAndReturn(func(_ context.Context, token *DeployTokenDTO) error {
+				stored = token
+				return nil
+			})
+
+		token, err := svc.CreateDeployToken(ctx, "repo-1", true)
+		require.NoError(t, err)
+
+		assert.Same(t, stored, token)
+		assert.True(t, IsDeployToken(token.Token))
+		assert.Equal(t, "repo-1", token.RepositoryId)
+		assert.Equal(t, "user-1", token.CreatedBy)
+		assert.True(t, token.ReadOnly)
+	})
+
+	t.Run("authors cannot create tokens", func(t *testing.T) {
+		svc, _, ctx := setup(t, authorization.MemberRoleAuthor)
+
+		_, err := svc.CreateDeployToken(ctx, "repo-1", false)
+		assert.Equal(t, connect.CodePermissionDenied, connect.CodeOf(err))
+	})
+}
+
+func TestService_RevokeDeployToken(t *testing.T) {
+	setup := func(t *testing.T, role string) (*service, *MockRepository, context.Context) {
+		ctrl := gomock.NewController(t)
+		mockRepo := NewMockRepository(ctrl)
+		mockOrgRepo := authorization.NewMockMemberRoleChecker(ctrl)
+		ctx := testAuthInterceptor("user-1")
+
+		mockRepo.EXPECT().
+			GetRepositoryById(ctx, "repo-1").
+			Return(&RepositoryDTO{Id: "repo-1", OrganizationId: "org-1"}, nil)
+		mockOrgRepo.EXPECT().
+			GetMemberRole(ctx, "org-1", "user-1").
+			Return(role, nil)
+
+		return &service{repository: mockRepo, orgRepo: mockOrgRepo}, mockRepo, ctx
+	}
+
+	t.Run("owner revokes a token of the repository", func(t *testing.T) {
+		svc, mockRepo, ctx := setup(t, authorization.MemberRoleOwner)
+		mockRepo.EXPECT().
+			RevokeDeployToken(ctx, "repo-1", "token-1").
+			Return(nil)
+
+		require.NoError(t, svc.RevokeDeployToken(ctx, "repo-1", "token-1"))
+	})
+
+	t.Run("authors cannot revoke tokens", func(t *testing.T) {
+		svc, _, ctx := setup(t, authorization.MemberRoleAuthor)
+
+		err := svc.RevokeDeployToken(ctx, "repo-1", "token-1")
+		assert.Equal(t, connect.CodePermissionDenied, connect.CodeOf(err))
+	})
+}
+
+func TestService_ValidateDeployTokenAccess(t *testing.T) {
+	const token = DeployTokenPrefix + "abc"
+	ctx := context.Background()
+
+	setup := func(t *testing.T, deployToken *DeployTokenDTO) (*service, *MockRepository) {
+		ctrl := gomock.NewController(t)
+		mockRepo := NewMockRepository(ctrl)
+
+		mockRepo.EXPECT().
+			GetDeployToken(ctx, token).
+			Return(deployToken, nil)
+
+		return &service{repository: mockRepo}, mockRepo
+	}
+
+	readOnly := &DeployTokenDTO{Id: "token-1", RepositoryId: "repo-1", Token: token, ReadOnly: true}
+	readWrite := &DeployTokenDTO{Id: "token-2", RepositoryId: "repo-1", Token: token}
+
+	t.Run("read-only token can clone its repository", func(t *testing.T) {
+		svc, _ := setup(t, readOnly)
+
+		tokenId, hasAccess, err := svc.ValidateDeployTokenAccess(ctx, token, "./repos/repo-1", SshOperationRead)
+		require.NoError(t, err)
+		assert.True(t, hasAccess)
+		assert.Equal(t, "token-1", tokenId)
+	})
+
+	t.Run("read-only token cannot push", func(t *testing.T) {
+		svc, _ := setup(t, readOnly)
+
+		_, hasAccess, err := svc.ValidateDeployTokenAccess(ctx, token, "./repos/repo-1", SshOperationWrite)
+		require.NoError(t, err)
+		assert.False(t, hasAccess)
+	})
+
+	t.Run("token is rejected on another repository", func(t *testing.T) {
+		for _, operation := range []SshOperation{SshOperationRead, SshOperationWrite} {
+			svc, _ := setup(t, readWrite)
+
+			_, hasAccess, err := svc.ValidateDeployTokenAccess(ctx, token, "./repos/repo-2", operation)
+			require.NoError(t, err)
+			assert.False(t, hasAccess, operation)
+		}
+	})
+
+	t.Run("read-write token can push", func(t *testing.T) {
+		svc, mockRepo := setup(t, readWrite)
+		mockRepo.EXPECT().
+			GetRepositoryById(ctx, "repo-1").
+			Return(&RepositoryDTO{Id: "repo-1"}, nil)
+
+		_, hasAccess, err := svc.ValidateDeployTokenAccess(ctx, token, "./repos/repo-1", SshOperationWrite)
+		require.NoError(t, err)
+		assert.True(t, hasAccess)
+	})
+
+	t.Run("read-write token cannot push to an archived repository", func(t *testing.T) {
+		svc, mockRepo := setup(t, readWrite)
+		mockRepo.EXPECT().
+			GetRepositoryById(ctx, "repo-1").
+			Return(&RepositoryDTO{Id: "repo-1", Archived: true}, nil)
+
+		_, _, err := svc.ValidateDeployTokenAccess(ctx, token, "./repos/repo-1", SshOperationWrite)
+		assert.ErrorIs(t, err, ErrRepositoryArchived)
+	})
+
+	t.Run("unknown token is not found", func(t *testing.T) {
+		mockRepo := NewMockRepository(gomock.NewController(t))
+		mockRepo.EXPECT().
+			GetDeployToken(ctx, token).
+			Return(nil, connect.NewError(connect.CodeNotFound, errors.New("deploy token not found")))
+
+		svc := &service{repository: mockRepo}
+		_, _, err := svc.ValidateDeployTokenAccess(ctx, token, "./repos/repo-1", SshOperationRead)
+		assert.Equal(t, connect.CodeNotFound, connect.CodeOf(err))
+	})
+}
@@ -0,0 +1,100 @@
+package registry
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"time"
+
+	"hasir-api/pkg/authentication"
+	"hasir-api/pkg/httpjson"
+)
+
+// maxDeployTokenRequestSize bounds the create body, which only carries the
+// read-only flag.
+const maxDeployTokenRequestSize = 1 << 10
+
+type deployTokenHandler struct {
+	service       Service
+	authenticator *authentication.AuthInterceptor
+}
+
+// NewDeployTokenHandler serves creation and revocation of deploy tokens.
+// RegistryService has no RPCs for them, so they are plain JSON endpoints that
+// take the same bearer token; the service limits both to organization owners.
+func NewDeployTokenHandler(service Service, authenticator *authentication.AuthInterceptor) *deployTokenHandler {
+	return &deployTokenHandler{
+		service:       service,
+		authenticator: authenticator,
+	}
+}
+
+func (h *deployTokenHandler) RegisterRoutes() (string, http.Handler) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("POST /deploy-tokens/{repositoryId}", h.requireUser(h.CreateDeployToken))
+	mux.HandleFunc("DELETE /deploy-tokens/{repositoryId}/{tokenId}", h.requireUser(h.RevokeDeployToken))
+
+	return "/deploy-tokens/", mux
+}
+
+func (h *deployTokenHandler) requireUser(next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		ctx, err := h.authenticator.Authenticate(r.Context(), r.Header.Get("Authorization"))
+		if err != nil {
+			w.Header().Set("WWW-Authenticate", `Bearer realm="Hasir"`)
+			http.Error(w, "Authentication required", http.StatusUnauthorized)
+			return
+		}
+
+		next(w, r.WithContext(ctx))
+	}
+}
+
+type createDeployTokenRequest struct {
+	ReadOnly *bool `json:"readOnly"`
+}
+
+type deployTokenResponse struct {
+	Id           string    `json:"id"`
+	RepositoryId string    `json:"repositoryId"`
+	Token        string    `json:"token"`
+	ReadOnly     bool      `json:"readOnly"`
+	CreatedAt    time.Time `json:"createdAt"`
+}
+
+// CreateDeployToken takes an optional {"readOnly": false} body; tokens are
+// read-only unless asked otherwise. The token is shown only in this response,
+// so the caller has to store it.
+func (h *deployTokenHandler) CreateDeployToken(w http.ResponseWriter, r *http.Request) {
+	var req createDeployTokenRequest
+	err := json.NewDecoder(http.MaxBytesReader(w, r.Body, maxDeployTokenRequestSize)).Decode(&req)
+	if err != nil && !errors.Is(err, io.EOF) {
+		http.Error(w, "Request body must be a JSON object", http.StatusBadRequest)
+		return
+	}
+
+	readOnly := req.ReadOnly == nil || *req.ReadOnly
+	token, err := h.service.CreateDeployToken(r.Context(), r.PathValue("repositoryId"), readOnly)
+	if err != nil {
+		httpjson.WriteError(w, "failed to create deploy token", err)
+		return
+	}
+
+	httpjson.Write(w, "deploy token", deployTokenResponse{
+		Id:           token.Id,
+		RepositoryId: token.RepositoryId,
+		Token:        token.Token,
+		ReadOnly:     token.ReadOnly,
+		CreatedAt:    token.CreatedAt,
+	})
+}
+
+func (h *deployTokenHandler) RevokeDeployToken(w http.ResponseWriter, r *http.Request) {
+	if err := h.service.RevokeDeployToken(r.Context(), r.PathValue("repositoryId"), r.PathValue("tokenId")); err != nil {
+		httpjson.WriteError(w, "failed to revoke deploy token", err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
@@ -0,0 +1,245 @@
+package registry
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"connectrpc.com/connect"
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+
+	"hasir-api/pkg/authentication"
+	"hasir-api/pkg/authorization"
+	"hasir-api/pkg/proto"
+)
+
+func TestDeployTokenHandler(t *testing.T) {
+	const (
+		repoId = "repo-1"
+		orgId  = "org-1"
+		userId = "user-1"
+	)
+	jwtSecret := []byte("jwt-secret")
+
+	type fixture struct {
+		server      *httptest.Server
+		mockRepo    *MockRepository
+		mockOrgRepo *authorization.MockMemberRoleChecker
+		reposPath   string
+	}
+
+	newFixture := func(t *testing.T) *fixture {
+		t.Helper()
+
+		ctrl := gomock.NewController(t)
+		f := &fixture{
+			mockRepo:    NewMockRepository(ctrl),
+			mockOrgRepo: authorization.NewMockMemberRoleChecker(ctrl),
+			reposPath:   t.TempDir(),
+		}
+		svc := &service{repository: f.mockRepo, orgRepo: f.mockOrgRepo}
+
+		mux := http.NewServeMux()
+		path, handler := NewDeployTokenHandler(svc, authentication.NewAuthInterceptor(jwtSecret)).RegisterRoutes()
+		mux.Handle(path, handler)
+		mux.Handle("/git/", NewGitHttpHandler(svc, nil, f.reposPath))
+
+		f.server = httptest.NewServer(mux)
+		t.Cleanup(f.server.Close)
+
+		f.mockRepo.EXPECT().
+			GetRepositoryById(gomock.Any(), repoId).
+			Return(&RepositoryDTO{Id: repoId, OrganizationId: orgId, Visibility: proto.VisibilityPrivate}, nil).
+			AnyTimes()
+
+		return f
+	}
+
+	bearer := func(t *testing.T) string {
+		t.Helper()
+
+		claims := &authentication.JwtClaims{
+			RegisteredClaims: jwt.RegisteredClaims{
+				Subject:   userId,
+				ExpiresAt: jwt.NewNumericDate(time.Now().Add(time.Hour)),
+			},
+		}
+		signed, err := jwt.NewWithClaims(jwt.SigningMethodHS256, claims).SignedString(jwtSecret)
+		require.NoError(t, err)
+
+		return "Bearer " + signed
+	}
+
+	do := func(t *testing.T, method, target, body string) *http.Response {
+		t.Helper()
+
+		req, err := http.NewRequest(method, target, strings.NewReader(body))
+		require.NoError(t, err)
+		req.Header.Set("Authorization", bearer(t))
+		resp, err := http.DefaultClient.Do(req)
+		require.NoError(t, err)
+		t.Cleanup(func() {
+			_ = resp.Body.Close()
+		})
+
+		return resp
+	}
+
+	t.Run("token created through the endpoint clones until revoked", func(t *testing.T) {
+		if _, err := exec.LookPath("git"); err != nil {
+			t.Skip("git not installed")
+		}
+
+		f := newFixture(t)
+
+		git := func(dir string, args ...string) error {
+			cmd := exec.Command("git", append([]string{"-c", "user.name=Test", "-c", "user.email=test@mail.com"}, args...)...)
+			cmd.Dir = dir
+			cmd.Env = append(os.Environ(), "GIT_TERMINAL_PROMPT=0")
+			output, err := cmd.CombinedOutput()
+			if err != nil {
+				return errors.New(string(output))
+			}
+			return nil
+		}
+
+		workDir := t.TempDir()
+		require.NoError(t, git(workDir, "init"))
+		require.NoError(t, os.WriteFile(filepath.Join(workDir, "api.proto"), []byte(`syntax = "proto3";`), 0644))
+		require.NoError(t, git(workDir, "add", "."))
+		require.NoError(t, git(workDir, "commit", "-m", "initial"))
+		require.NoError(t, git(workDir, "clone", "--bare", workDir, filepath.Join(f.reposPath, repoId)))
+
+		var stored *DeployTokenDTO
+		revoked := false
+		f.mockOrgRepo.EXPECT().
+			GetMemberRole(gomock.Any(), orgId, userId).
+			Return(authorization.MemberRoleOwner, nil).
+			Times(2)
+		f.mockRepo.EXPECT().
+			CreateDeployToken(gomock.Any(), gomock.Any()).
+			DoAndReturn(func(_ context.Context, token *DeployTokenDTO) error {
+				stored = token
+				return nil
+			})
+		f.mockRepo.EXPECT().
+			GetDeployToken(gomock.Any(), gomock.Any()).
+			DoAndReturn(func(_ context.Context, token string) (*DeployTokenDTO, error) {
+				if stored == nil || revoked || token != stored.Token {
+					return nil, connect.NewError(connect.CodeNotFound, errors.New("deploy token not found"))
+				}
+				return stored, nil
+			}).
+			AnyTimes()
+		f.mockRepo.EXPECT().
+			RevokeDeployToken(gomock.Any(), repoId, gomock.Any()).
+			DoAndReturn(func(_ context.Context, _, tokenId string) error {
+				if tokenId != stored.Id {
+					return connect.NewError(connect.CodeNotFound, errors.New("deploy token not found"))
+				}
+				revoked = true
+				return nil
+			})
+
+		resp := do(t, http.MethodPost, f.server.URL+"/deploy-tokens/"+repoId, "")
+		require.Equal(t, http.StatusOK, resp.StatusCode)
+
+		var created deployTokenResponse
+		require.NoError(t, json.NewDecoder(resp.Body).Decode(&created))
+		assert.Equal(t, repoId, created.RepositoryId)
+		assert.True(t, created.ReadOnly)
+		require.True(t, IsDeployToken(created.Token))
+
+		cloneUrl, err := url.Parse(f.server.URL + "/git/" + repoId + ".git")
+		require.NoError(t, err)
+		cloneUrl.User = url.UserPassword("deploy", created.Token)
+
+		cloneDir := filepath.Join(t.TempDir(), "clone")
+		require.NoError(t, git(t.TempDir(), "clone", cloneUrl.String(), cloneDir))
+		assert.FileExists(t, filepath.Join(cloneDir, "api.proto"))
+
+		resp = do(t, http.MethodDelete, f.server.URL+"/deploy-tokens/"+repoId+"/"+created.Id, "")
+		require.Equal(t, http.StatusNoContent, resp.StatusCode)
+
+		err = git(t.TempDir(), "clone", cloneUrl.String(), filepath.Join(t.TempDir(), "clone"))
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "Authentication failed")
+	})
+
+	t.Run("readOnly false creates a read-write token", func(t *testing.T) {
+		f := newFixture(t)
+
+		f.mockOrgRepo.EXPECT().
+			GetMemberRole(gomock.Any(), orgId, userId).
+			Return(authorization.MemberRoleOwner, nil)
+		f.mockRepo.EXPECT().
+			CreateDeployToken(gomock.Any(), gomock.Any()).
+			Return(nil)
+
+		resp := do(t, http.MethodPost, f.server.URL+"/deploy-tokens/"+repoId, `{"readOnly":false}`)
+		require.Equal(t, http.StatusOK, resp.StatusCode)
+
+		var created deployTokenResponse
+		require.NoError(t, json.NewDecoder(resp.Body).Decode(&created))
+		assert.False(t, created.ReadOnly)
+	})
+
+	t.Run("authors cannot create or revoke tokens", func(t *testing.T) {
+		f := newFixture(t)
+
+		f.mockOrgRepo.EXPECT().
+			GetMemberRole(gomock.Any(), orgId, userId).
+			Return(authorization.MemberRoleAuthor, nil).
+			Times(2)
+
+		resp := do(t, http.MethodPost, f.server.URL+"/deploy-tokens/"+repoId, "")
+		assert.Equal(t, http.StatusForbidden, resp.StatusCode)
+
+		resp = do(t, http.MethodDelete, f.server.URL+"/deploy-tokens/"+repoId+"/token-1", "")
+		assert.Equal(t, http.StatusForbidden, resp.StatusCode)
+	})
+
+	t.Run("revoking an unknown token is not found", func(t *testing.T) {
+		f := newFixture(t)
+
+		f.mockOrgRepo.EXPECT().
+			GetMemberRole(gomock.Any(), orgId, userId).
+			Return(authorization.MemberRoleOwner, nil)
+		f.mockRepo.EXPECT().
+			RevokeDeployToken(gomock.Any(), repoId, "token-1").
+			Return(connect.NewError(connect.CodeNotFound, errors.New("deploy token not found")))
+
+		resp := do(t, http.MethodDelete, f.server.URL+"/deploy-tokens/"+repoId+"/token-1", "")
+		assert.Equal(t, http.StatusNotFound, resp.StatusCode)
+	})
+
+	t.Run("requires a bearer token", func(t *testing.T) {
+		f := newFixture(t)
+
+		resp, err := http.Post(f.server.URL+"/deploy-tokens/"+repoId, "application/json", nil)
+		require.NoError(t, err)
+		defer func() {
+			_ = resp.Body.Close()
+		}()
+		assert.Equal(t, http.StatusUnauthorized, resp.StatusCode)
+	})
+
+	t.Run("rejects a malformed body", func(t *testing.T) {
+		f := newFixture(t)
+
+		resp := do(t, http.MethodPost, f.server.URL+"/deploy-tokens/"+repoId, `{"readOnly":`)
+		assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+	})
+}
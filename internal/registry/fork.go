@@ -0,0 +1,198 @@
+package registry
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"time"
+
+	"connectrpc.com/connect"
+	"github.com/go-git/go-git/v5"
+	gitconfig "github.com/go-git/go-git/v5/config"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/transport"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+
+	"hasir-api/pkg/authentication"
+	"hasir-api/pkg/authorization"
+	"hasir-api/pkg/proto"
+)
+
+// ForkRepository copies a repository with all of its branches and tags into
+// targetOrganizationId under the same name and visibility, and records the
+// source as the fork's parent. The caller needs read access to the source and
+// must own the target organization. Per-repository settings such as SDK
+// preferences, deploy tokens and the push lint hook stay behind; the fork
+// starts with the target organization's default SDKs like any new repository.
+func (s *service) ForkRepository(ctx context.Context, sourceRepoId, targetOrganizationId string) (*RepositoryDTO, error) {
+	userId, err := authentication.MustGetUserID(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	source, err := s.repository.GetRepositoryById(ctx, sourceRepoId)
+	if err != nil {
+		return nil, err
+	}
+
+	if source.Visibility != proto.VisibilityPublic {
+		if err := authorization.IsUserMember(ctx, s.orgRepo, source.OrganizationId, userId); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := authorization.IsUserOwner(ctx, s.orgRepo, targetOrganizationId, userId); err != nil {
+		return nil, err
+	}
+
+	if err := s.checkVisibilityPolicy(ctx, targetOrganizationId, source.Visibility); err != nil {
+		return nil, err
+	}
+
+	if err := s.ensureRepositoryCapacity(ctx, targetOrganizationId); err != nil {
+		return nil, err
+	}
+
+	defaultSdks, err := s.repository.GetOrganizationDefaultSdks(ctx, targetOrganizationId)
+	if err != nil {
+		return nil, err
+	}
+
+	repoId := uuid.NewString()
+	repoPath := filepath.Join(s.rootPath, repoId)
+
+	unlock := s.pathLocks.lock(repoPath)
+	defer unlock()
+
+	if err := os.MkdirAll(s.rootPath, 0o750); err != nil {
+		return nil, connect.NewError(connect.CodeInternal, errors.New("failed to create repository directory"))
+	}
+
+	if err := os.Mkdir(repoPath, 0o750); err != nil {
+		if errors.Is(err, os.ErrExist) {
+			zap.L().Warn("repository already exists on filesystem", zap.String("path", repoPath))
+			return nil, connect.NewError(connect.CodeAlreadyExists, errors.New("repository path already exists"))
+		}
+
+		return nil, connect.NewError(connect.CodeInternal, errors.New("failed to create repository directory"))
+	}
+
+	if err := cloneBareRepository(ctx, source.Path, repoPath); err != nil {
+		zap.L().Error("failed to copy repository for fork",
+			zap.String("sourceId", source.Id),
+			zap.String("path", repoPath),
+			zap.Error(err),
+		)
+		removeRepositoryDir(repoPath, "clone error")
+
+		return nil, connect.NewError(connect.CodeInternal, errors.New("failed to copy repository"))
+	}
+
+	now := time.Now().UTC()
+	repoDTO := &RepositoryDTO{
+		Id:             repoId,
+		Name:           source.Name,
+		CreatedBy:      userId,
+		OrganizationId: targetOrganizationId,
+		Path:           repoPath,
+		Visibility:     source.Visibility,
+		CreatedAt:      now,
+		UpdatedAt:      &now,
+		ForkedFrom:     &source.Id,
+	}
+
+	if err := s.repository.CreateRepository(ctx, repoDTO); err != nil {
+		removeRepositoryDir(repoPath, "db error")
+
+		if connect.CodeOf(err) == connect.CodeAlreadyExists {
+			return nil, err
+		}
+
+		return nil, connect.NewError(connect.CodeInternal, errors.New("failed to save repository to database"))
+	}
+
+	zap.L().Info("repository forked",
+		zap.String("id", repoId),
+		zap.String("sourceId", source.Id),
+		zap.String("organizationId", targetOrganizationId),
+	)
+
+	s.applyDefaultSdks(ctx, repoId, defaultSdks)
+
+	return repoDTO, nil
+}
+
+// GetForks lists the direct forks of repoId, oldest first. The caller must be
+// able to read repoId, and only sees private forks in organizations they
+// belong to.
+func (s *service) GetForks(ctx context.Context, repoId string) ([]RepositoryDTO, error) {
+	userId, err := authentication.MustGetUserID(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	repo, err := s.repository.GetRepositoryById(ctx, repoId)
+	if err != nil {
+		return nil, err
+	}
+
+	if repo.Visibility != proto.VisibilityPublic {
+		if err := authorization.IsUserMember(ctx, s.orgRepo, repo.OrganizationId, userId); err != nil {
+			return nil, err
+		}
+	}
+
+	forks, err := s.repository.GetForks(ctx, repoId, userId)
+	if err != nil {
+		return nil, err
+	}
+
+	return *forks, nil
+}
+
+// cloneBareRepository copies every ref of the bare repository at sourcePath
+// into a new bare repository at targetPath, with HEAD pointing at the same
+// branch. It fetches rather than clones because a clone insists on HEAD
+// resolving, and repositories pushed to under a branch other than the one
+// HEAD names would fail. The remote is never saved, so the copy keeps no link
+// back to the source.
+func cloneBareRepository(ctx context.Context, sourcePath, targetPath string) error {
+	absSourcePath, err := filepath.Abs(sourcePath)
+	if err != nil {
+		return err
+	}
+
+	source, err := git.PlainOpen(absSourcePath)
+	if err != nil {
+		return err
+	}
+
+	head, err := source.Storer.Reference(plumbing.HEAD)
+	if err != nil {
+		return err
+	}
+
+	target, err := git.PlainInit(targetPath, true)
+	if err != nil {
+		return err
+	}
+
+	if err := target.Storer.SetReference(head); err != nil {
+		return err
+	}
+
+	remote := git.NewRemote(target.Storer, &gitconfig.RemoteConfig{
+		Name: git.DefaultRemoteName,
+		URLs: []string{absSourcePath},
+	})
+	err = remote.FetchContext(ctx, &git.FetchOptions{
+		RefSpecs: []gitconfig.RefSpec{"+refs/*:refs/*"},
+	})
+	if err != nil && !errors.Is(err, git.NoErrAlreadyUpToDate) && !errors.Is(err, transport.ErrEmptyRemoteRepository) {
+		return err
+	}
+
+	return nil
+}
@@ -0,0 +1,294 @@
+package registry
+
+import (
+	"context"
+	"errors"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"connectrpc.com/connect"
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+
+	"hasir-api/pkg/authorization"
+	"hasir-api/pkg/proto"
+)
+
+// initBareRepoWithHistory pushes two commits on main and a tag into a new
+// bare repository at repoPath and returns the hash of the last commit. HEAD
+// keeps naming go-git's default branch, as it does for repositories made by
+// CreateRepository, so it does not resolve.
+func initBareRepoWithHistory(t *testing.T, repoPath string) string {
+	t.Helper()
+
+	_, err := git.PlainInit(repoPath, true)
+	require.NoError(t, err)
+
+	workDir := t.TempDir()
+	cmds := [][]string{
+		{"git", "init", "-b", "main"},
+		{"git", "config", "user.email", "test@test.com"},
+		{"git", "config", "user.name", "Test"},
+		{"git", "commit", "--allow-empty", "-m", "first"},
+		{"git", "commit", "--allow-empty", "-m", "second"},
+		{"git", "tag", "v1.0.0"},
+		{"git", "push", repoPath, "main", "v1.0.0"},
+	}
+	for _, args := range cmds {
+		cmd := exec.Command(args[0], args[1:]...)
+		cmd.Dir = workDir
+		out, err := cmd.CombinedOutput()
+		require.NoError(t, err, "command %v failed: %s", args, string(out))
+	}
+
+	cmd := exec.Command("git", "rev-parse", "HEAD")
+	cmd.Dir = workDir
+	out, err := cmd.Output()
+	require.NoError(t, err)
+	return strings.TrimSpace(string(out))
+}
+
+func TestService_ForkRepository(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not installed")
+	}
+
+	const (
+		userId    = "user-1"
+		sourceOrg = "source-org"
+		targetOrg = "target-org"
+	)
+
+	setup := func(t *testing.T, visibility proto.Visibility) (*service, *MockRepository, *authorization.MockMemberRoleChecker, context.Context, *RepositoryDTO) {
+		ctrl := gomock.NewController(t)
+		mockRepo := NewMockRepository(ctrl)
+		mockOrgRepo := authorization.NewMockMemberRoleChecker(ctrl)
+		rootPath := t.TempDir()
+
+		source := &RepositoryDTO{
+			Id:             "source-repo",
+			Name:           "protos",
+			OrganizationId: sourceOrg,
+			Path:           filepath.Join(rootPath, "source-repo"),
+			Visibility:     visibility,
+		}
+
+		svc := &service{
+			rootPath:   rootPath,
+			repository: mockRepo,
+			orgRepo:    mockOrgRepo,
+		}
+
+		return svc, mockRepo, mockOrgRepo, testAuthInterceptor(userId), source
+	}
+
+	t.Run("fork copies history into the target organization", func(t *testing.T) {
+		svc, mockRepo, mockOrgRepo, ctx, source := setup(t, proto.VisibilityPrivate)
+		head := initBareRepoWithHistory(t, source.Path)
+
+		mockRepo.EXPECT().GetRepositoryById(ctx, source.Id).Return(source, nil)
+		mockOrgRepo.EXPECT().GetMemberRole(ctx, sourceOrg, userId).Return(authorization.MemberRoleReader, nil)
+		mockOrgRepo.EXPECT().GetMemberRole(ctx, targetOrg, userId).Return(authorization.MemberRoleOwner, nil)
+		mockRepo.EXPECT().GetOrganizationDefaultSdks(ctx, targetOrg).Return(nil, nil)
+
+		var saved *RepositoryDTO
+		mockRepo.EXPECT().
+			CreateRepository(ctx, gomock.Any()).
+			DoAndReturn(func(_ context.Context, repo *RepositoryDTO) error {
+				saved = repo
+				return nil
+			})
+
+		fork, err := svc.ForkRepository(ctx, source.Id, targetOrg)
+		require.NoError(t, err)
+		require.Same(t, saved, fork)
+
+		assert.NotEqual(t, source.Id, fork.Id)
+		assert.Equal(t, "protos", fork.Name)
+		assert.Equal(t, targetOrg, fork.OrganizationId)
+		assert.Equal(t, userId, fork.CreatedBy)
+		assert.Equal(t, proto.VisibilityPrivate, fork.Visibility)
+		require.NotNil(t, fork.ForkedFrom)
+		assert.Equal(t, source.Id, *fork.ForkedFrom)
+		assert.Equal(t, filepath.Join(svc.rootPath, fork.Id), fork.Path)
+
+		gitRepo, err := git.PlainOpen(fork.Path)
+		require.NoError(t, err)
+
+		branch, err := gitRepo.Reference(plumbing.NewBranchReferenceName("main"), true)
+		require.NoError(t, err)
+		assert.Equal(t, head, branch.Hash().String())
+
+		tag, err := gitRepo.Reference(plumbing.NewTagReferenceName("v1.0.0"), true)
+		require.NoError(t, err)
+		assert.Equal(t, head, tag.Hash().String())
+
+		commit, err := gitRepo.CommitObject(branch.Hash())
+		require.NoError(t, err)
+		assert.Equal(t, 1, commit.NumParents())
+
+		headRef, err := gitRepo.Storer.Reference(plumbing.HEAD)
+		require.NoError(t, err)
+		assert.Equal(t, plumbing.Master, headRef.Target())
+
+		remotes, err := gitRepo.Remotes()
+		require.NoError(t, err)
+		assert.Empty(t, remotes)
+	})
+
+	t.Run("empty repository can be forked", func(t *testing.T) {
+		svc, mockRepo, mockOrgRepo, ctx, source := setup(t, proto.VisibilityPublic)
+		_, err := git.PlainInit(source.Path, true)
+		require.NoError(t, err)
+
+		mockRepo.EXPECT().GetRepositoryById(ctx, source.Id).Return(source, nil)
+		mockOrgRepo.EXPECT().GetMemberRole(ctx, targetOrg, userId).Return(authorization.MemberRoleOwner, nil)
+		mockRepo.EXPECT().GetOrganizationDefaultSdks(ctx, targetOrg).Return(nil, nil)
+		mockRepo.EXPECT().CreateRepository(ctx, gomock.Any()).Return(nil)
+
+		fork, err := svc.ForkRepository(ctx, source.Id, targetOrg)
+		require.NoError(t, err)
+
+		_, err = git.PlainOpen(fork.Path)
+		require.NoError(t, err)
+	})
+
+	t.Run("public source needs no membership", func(t *testing.T) {
+		svc, mockRepo, mockOrgRepo, ctx, source := setup(t, proto.VisibilityPublic)
+		initBareRepoWithHistory(t, source.Path)
+
+		mockRepo.EXPECT().GetRepositoryById(ctx, source.Id).Return(source, nil)
+		mockOrgRepo.EXPECT().GetMemberRole(ctx, targetOrg, userId).Return(authorization.MemberRoleOwner, nil)
+		mockRepo.EXPECT().GetOrganizationDefaultSdks(ctx, targetOrg).Return(nil, nil)
+		mockRepo.EXPECT().CreateRepository(ctx, gomock.Any()).Return(nil)
+
+		fork, err := svc.ForkRepository(ctx, source.Id, targetOrg)
+		require.NoError(t, err)
+		assert.Equal(t, proto.VisibilityPublic, fork.Visibility)
+	})
+
+	t.Run("private source requires membership", func(t *testing.T) {
+		svc, mockRepo, mockOrgRepo, ctx, source := setup(t, proto.VisibilityPrivate)
+
+		mockRepo.EXPECT().GetRepositoryById(ctx, source.Id).Return(source, nil)
+		mockOrgRepo.EXPECT().GetMemberRole(ctx, sourceOrg, userId).Return("", authorization.ErrMemberNotFound)
+
+		_, err := svc.ForkRepository(ctx, source.Id, targetOrg)
+		require.Error(t, err)
+		assert.Equal(t, connect.CodePermissionDenied, connect.CodeOf(err))
+		assertNoRepositoryDirs(t, svc.rootPath)
+	})
+
+	t.Run("target organization requires ownership", func(t *testing.T) {
+		svc, mockRepo, mockOrgRepo, ctx, source := setup(t, proto.VisibilityPrivate)
+
+		mockRepo.EXPECT().GetRepositoryById(ctx, source.Id).Return(source, nil)
+		mockOrgRepo.EXPECT().GetMemberRole(ctx, sourceOrg, userId).Return(authorization.MemberRoleOwner, nil)
+		mockOrgRepo.EXPECT().GetMemberRole(ctx, targetOrg, userId).Return(authorization.MemberRoleAuthor, nil)
+
+		_, err := svc.ForkRepository(ctx, source.Id, targetOrg)
+		require.Error(t, err)
+		assert.Equal(t, connect.CodePermissionDenied, connect.CodeOf(err))
+		assertNoRepositoryDirs(t, svc.rootPath)
+	})
+
+	t.Run("database failure removes the copy", func(t *testing.T) {
+		svc, mockRepo, mockOrgRepo, ctx, source := setup(t, proto.VisibilityPrivate)
+		initBareRepoWithHistory(t, source.Path)
+
+		mockRepo.EXPECT().GetRepositoryById(ctx, source.Id).Return(source, nil)
+		mockOrgRepo.EXPECT().GetMemberRole(ctx, sourceOrg, userId).Return(authorization.MemberRoleReader, nil)
+		mockOrgRepo.EXPECT().GetMemberRole(ctx, targetOrg, userId).Return(authorization.MemberRoleOwner, nil)
+		mockRepo.EXPECT().GetOrganizationDefaultSdks(ctx, targetOrg).Return(nil, nil)
+		mockRepo.EXPECT().CreateRepository(ctx, gomock.Any()).Return(connect.NewError(connect.CodeAlreadyExists, errors.New("repository already exists")))
+
+		_, err := svc.ForkRepository(ctx, source.Id, targetOrg)
+		require.Error(t, err)
+		assert.Equal(t, connect.CodeAlreadyExists, connect.CodeOf(err))
+		assertNoRepositoryDirs(t, svc.rootPath, source.Id)
+	})
+}
+
+func TestService_GetForks(t *testing.T) {
+	const (
+		userId = "user-1"
+		orgId  = "org-1"
+	)
+
+	setup := func(t *testing.T) (*service, *MockRepository, *authorization.MockMemberRoleChecker, context.Context) {
+		ctrl := gomock.NewController(t)
+		mockRepo := NewMockRepository(ctrl)
+		mockOrgRepo := authorization.NewMockMemberRoleChecker(ctrl)
+
+		svc := &service{repository: mockRepo, orgRepo: mockOrgRepo}
+
+		return svc, mockRepo, mockOrgRepo, testAuthInterceptor(userId)
+	}
+
+	parentId := "parent-repo"
+	forks := []RepositoryDTO{
+		{Id: "fork-1", Name: "protos", OrganizationId: "org-2", Visibility: proto.VisibilityPublic, ForkedFrom: &parentId},
+		{Id: "fork-2", Name: "protos", OrganizationId: "org-3", Visibility: proto.VisibilityPrivate, ForkedFrom: &parentId},
+	}
+
+	t.Run("lists the forks visible to the caller", func(t *testing.T) {
+		svc, mockRepo, mockOrgRepo, ctx := setup(t)
+
+		mockRepo.EXPECT().
+			GetRepositoryById(ctx, parentId).
+			Return(&RepositoryDTO{Id: parentId, OrganizationId: orgId, Visibility: proto.VisibilityPrivate}, nil)
+		mockOrgRepo.EXPECT().GetMemberRole(ctx, orgId, userId).Return(authorization.MemberRoleReader, nil)
+		mockRepo.EXPECT().GetForks(ctx, parentId, userId).Return(&forks, nil)
+
+		got, err := svc.GetForks(ctx, parentId)
+		require.NoError(t, err)
+		assert.Equal(t, forks, got)
+	})
+
+	t.Run("public parent needs no membership", func(t *testing.T) {
+		svc, mockRepo, _, ctx := setup(t)
+
+		mockRepo.EXPECT().
+			GetRepositoryById(ctx, parentId).
+			Return(&RepositoryDTO{Id: parentId, OrganizationId: orgId, Visibility: proto.VisibilityPublic}, nil)
+		mockRepo.EXPECT().GetForks(ctx, parentId, userId).Return(&[]RepositoryDTO{}, nil)
+
+		got, err := svc.GetForks(ctx, parentId)
+		require.NoError(t, err)
+		assert.Empty(t, got)
+	})
+
+	t.Run("private parent hides its forks from non-members", func(t *testing.T) {
+		svc, mockRepo, mockOrgRepo, ctx := setup(t)
+
+		mockRepo.EXPECT().
+			GetRepositoryById(ctx, parentId).
+			Return(&RepositoryDTO{Id: parentId, OrganizationId: orgId, Visibility: proto.VisibilityPrivate}, nil)
+		mockOrgRepo.EXPECT().GetMemberRole(ctx, orgId, userId).Return("", authorization.ErrMemberNotFound)
+
+		_, err := svc.GetForks(ctx, parentId)
+		require.Error(t, err)
+		assert.Equal(t, connect.CodePermissionDenied, connect.CodeOf(err))
+	})
+}
+
+// assertNoRepositoryDirs fails if rootPath holds anything but the named
+// entries.
+func assertNoRepositoryDirs(t *testing.T, rootPath string, keep ...string) {
+	t.Helper()
+
+	entries, err := os.ReadDir(rootPath)
+	require.NoError(t, err)
+
+	var names []string
+	for _, entry := range entries {
+		names = append(names, entry.Name())
+	}
+	assert.ElementsMatch(t, keep, names)
+}
@@ -0,0 +1,102 @@
+package registry
+
+import (
+	"context"
+	"errors"
+	"io"
+	"os/exec"
+	"sync/atomic"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const gitTracerName = "hasir-api/registry/git"
+
+// runGitCommand runs a git pack subprocess inside a span parented to ctx, so
+// traces show the transfer under the request that caused it. Stdin and Stdout
+// must already be set; they are wrapped to count the bytes moved. A Stderr
+// that is the same writer as Stdout gets the same wrapper, so exec keeps a
+// single copy goroutine and the two streams never write to it concurrently.
+func runGitCommand(ctx context.Context, repoId, operation string, cmd *exec.Cmd) error {
+	// Resolved per call so a provider installed after startup is picked up.
+	_, span := otel.GetTracerProvider().Tracer(gitTracerName).Start(ctx, operation, trace.WithAttributes(
+		attribute.KeyValue{
+			Key:   "repoId",
+			Value: attribute.StringValue(repoId),
+		},
+		attribute.KeyValue{
+			Key:   "operation",
+			Value: attribute.StringValue(operation),
+		},
+	))
+	defer span.End()
+
+	var bytesIn, bytesOut atomic.Int64
+	if cmd.Stdin != nil {
+		cmd.Stdin = &countingReader{r: cmd.Stdin, n: &bytesIn}
+	}
+	if cmd.Stdout != nil {
+		stdout := &countingWriter{w: cmd.Stdout, n: &bytesOut}
+		if sameWriter(cmd.Stderr, cmd.Stdout) {
+			cmd.Stderr = stdout
+		}
+		cmd.Stdout = stdout
+	}
+
+	err := cmd.Run()
+
+	exitCode := 0
+	if err != nil {
+		exitCode = -1
+		var exitErr *exec.ExitError
+		if errors.As(err, &exitErr) {
+			exitCode = exitErr.ExitCode()
+		}
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+
+	span.SetAttributes(
+		attribute.Int64("bytesIn", bytesIn.Load()),
+		attribute.Int64("bytesOut", bytesOut.Load()),
+		attribute.Int("exitCode", exitCode),
+	)
+
+	return err
+}
+
+// sameWriter reports whether a and b are the same writer, treating writers
+// of an incomparable type as different the way os/exec does.
+func sameWriter(a, b io.Writer) (same bool) {
+	defer func() {
+		if recover() != nil {
+			same = false
+		}
+	}()
+	return a != nil && a == b
+}
+
+type countingReader struct {
+	r io.Reader
+	n *atomic.Int64
+}
+
+func (c *countingReader) Read(p []byte) (int, error) {
+	n, err := c.r.Read(p)
+	c.n.Add(int64(n))
+	return n, err
+}
+
+type countingWriter struct {
+	w io.Writer
+	n *atomic.Int64
+}
+
+func (c *countingWriter) Write(p []byte) (int, error) {
+	n, err := c.w.Write(p)
+	c.n.Add(int64(n))
+	return n, err
+}
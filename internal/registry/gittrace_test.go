@@ -0,0 +1,36 @@
+package registry
+
+import (
+	"bytes"
+	"context"
+	"os/exec"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRunGitCommand_SharedStdoutAndStderr(t *testing.T) {
+	var out bytes.Buffer
+	cmd := exec.Command("sh", "-c", "echo out; echo err >&2")
+	cmd.Stdout = &out
+	cmd.Stderr = &out
+
+	require.NoError(t, runGitCommand(context.Background(), "repo", "test", cmd))
+
+	assert.Same(t, cmd.Stdout, cmd.Stderr)
+	assert.Equal(t, "out\nerr\n", out.String())
+}
+
+func TestRunGitCommand_SeparateStderr(t *testing.T) {
+	var out, errOut bytes.Buffer
+	cmd := exec.Command("sh", "-c", "echo out; echo err >&2")
+	cmd.Stdout = &out
+	cmd.Stderr = &errOut
+
+	require.NoError(t, runGitCommand(context.Background(), "repo", "test", cmd))
+
+	assert.Equal(t, &errOut, cmd.Stderr)
+	assert.Equal(t, "out\n", out.String())
+	assert.Equal(t, "err\n", errOut.String())
+}
@@ -1,13 +1,17 @@
 package registry
 
 import (
+	"compress/gzip"
 	"context"
+	"encoding/json"
 	"errors"
 	"fmt"
+	"io"
 	"net/http"
 	"os"
 	"os/exec"
 	"path/filepath"
+	"strconv"
 	"strings"
 
 	"buf.build/gen/go/hasir/hasir/connectrpc/go/registry/v1/registryv1connect"
@@ -20,6 +24,7 @@ import (
 
 	"hasir-api/internal/user"
 	"hasir-api/pkg/authentication"
+	"hasir-api/pkg/middleware"
 )
 
 const banner = `
@@ -33,6 +38,134 @@ const banner = `
 Protocol Buffer Schema Registry
 `
 
+// The Repository message has no clone URL fields, so GetRepository returns
+// them as response headers.
+const (
+	HttpCloneUrlHeader = "Hasir-Http-Clone-Url"
+	SshCloneUrlHeader  = "Hasir-Ssh-Clone-Url"
+)
+
+// GetFilePreviewResponse has no fields for these, so they are returned as
+// response headers.
+const (
+	PreviewTruncatedHeader = "Hasir-Preview-Truncated"
+	PreviewBinaryHeader    = "Hasir-Preview-Binary"
+	PreviewLanguageHeader  = "Hasir-Preview-Language"
+)
+
+// AvailableSdkHeader lists, one value per SDK, the SDKs this deployment can
+// generate. GetRepository and UpdateSdkPreferences return it since neither
+// response has a field for it.
+const AvailableSdkHeader = "Hasir-Available-Sdk"
+
+// RepositoryEmptyHeader is set on GetRepository, GetCommits and
+// GetRecentCommit responses for a repository nothing has been pushed to yet.
+const RepositoryEmptyHeader = "Hasir-Repository-Empty"
+
+// RefHeader selects the branch GetCommits, GetFileTree and GetFilePreview
+// read from, since their requests have no field for it. HEAD or no header
+// means the branch HEAD points at.
+const RefHeader = "Hasir-Ref"
+
+// GetFileTreeRequest has no pagination fields, so a page of one directory is
+// requested with these headers and the directory's entry count is returned in
+// FileTreeTotalCountHeader.
+const (
+	FileTreeOffsetHeader     = "Hasir-Page-Offset"
+	FileTreeLimitHeader      = "Hasir-Page-Limit"
+	FileTreeTotalCountHeader = "Hasir-Total-Count"
+)
+
+// FileTreeTruncatedHeader lists, one value per path, the directories a
+// recursive GetFileTree listing returned without children because they sit at
+// the configured maximum depth. Clients fetch them by path.
+const FileTreeTruncatedHeader = "Hasir-Truncated-Path"
+
+// ApplyTemplateHeader opts a CreateRepository call into seeding the new
+// repository with the configured template, since the request message has no
+// field for it.
+const ApplyTemplateHeader = "Hasir-Apply-Template"
+
+// IncludeSdkPreferencesHeader set to "false" lets GetRepository leave out the
+// repository's SDK preferences, which it returns by default, since the request
+// message has no field for it.
+const IncludeSdkPreferencesHeader = "Hasir-Include-Sdk-Preferences"
+
+// IncludeSdkHealthHeader asks GetRepository for the outcome of the latest
+// generation of each enabled SDK. Each comes back as one SdkHealthHeader
+// value of the form "<SDK>=<ok|failed|pending>"; SDKs never generated are
+// left out.
+const (
+	IncludeSdkHealthHeader = "Hasir-Include-Sdk-Health"
+	SdkHealthHeader        = "Hasir-Sdk-Health"
+)
+
+// ForkedFromHeader carries the id of the repository a GetRepository result
+// was forked from, since Repository has no field for it.
+const ForkedFromHeader = "Hasir-Forked-From"
+
+// PushLintHeader turns lint-on-push on or off when sent with UpdateRepository
+// and reports it on GetRepository, since neither message has a field for it.
+const PushLintHeader = "Hasir-Push-Lint"
+
+// ArchivedHeader archives or unarchives a repository when sent with
+// UpdateRepository and reports the state on GetRepository. GetRepositories
+// lists the archived repositories of the page as one ArchivedRepositoryHeader
+// value per id.
+const (
+	ArchivedHeader           = "Hasir-Archived"
+	ArchivedRepositoryHeader = "Hasir-Archived-Repository"
+)
+
+type httpErrorBody struct {
+	Error httpErrorDetail `json:"error"`
+}
+
+type httpErrorDetail struct {
+	Code    string `json:"code"`
+	Message string `json:"message"`
+}
+
+// writeHttpError writes the JSON error envelope used by the plain HTTP
+// handlers. Codes follow the Connect names so clients can share one mapping
+// with the RPC API. Git clients only look at the status line, so the envelope
+// is safe on the git endpoints as long as nothing has been streamed yet.
+func writeHttpError(w http.ResponseWriter, status int, message string) {
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("X-Content-Type-Options", "nosniff")
+	w.WriteHeader(status)
+
+	_ = json.NewEncoder(w).Encode(httpErrorBody{
+		Error: httpErrorDetail{
+			Code:    httpStatusToCode(status).String(),
+			Message: message,
+		},
+	})
+}
+
+func httpStatusToCode(status int) connect.Code {
+	switch status {
+	case http.StatusBadRequest:
+		return connect.CodeInvalidArgument
+	case http.StatusUnauthorized:
+		return connect.CodeUnauthenticated
+	case http.StatusForbidden:
+		return connect.CodePermissionDenied
+	case http.StatusNotFound:
+		return connect.CodeNotFound
+	case http.StatusConflict:
+		return connect.CodeAlreadyExists
+	case http.StatusTooManyRequests:
+		return connect.CodeResourceExhausted
+	case http.StatusNotImplemented:
+		return connect.CodeUnimplemented
+	case http.StatusServiceUnavailable:
+		return connect.CodeUnavailable
+	default:
+		return connect.CodeInternal
+	}
+}
+
 type handler struct {
 	interceptors []connect.Interceptor
 	service      Service
@@ -58,7 +191,16 @@ func (h *handler) CreateRepository(
 	ctx context.Context,
 	req *connect.Request[registryv1.CreateRepositoryRequest],
 ) (*connect.Response[emptypb.Empty], error) {
-	if err := h.service.CreateRepository(ctx, req.Msg); err != nil {
+	var opts CreateRepositoryOptions
+	if value := req.Header().Get(ApplyTemplateHeader); value != "" {
+		applyTemplate, err := strconv.ParseBool(value)
+		if err != nil {
+			return nil, connect.NewError(connect.CodeInvalidArgument, fmt.Errorf("invalid %s header: %q", ApplyTemplateHeader, value))
+		}
+		opts.ApplyTemplate = applyTemplate
+	}
+
+	if err := h.service.CreateRepository(ctx, req.Msg, opts); err != nil {
 		return nil, err
 	}
 
@@ -69,12 +211,49 @@ func (h *handler) GetRepository(
 	ctx context.Context,
 	req *connect.Request[registryv1.GetRepositoryRequest],
 ) (*connect.Response[registryv1.Repository], error) {
-	repo, err := h.service.GetRepository(ctx, req.Msg)
+	opts := GetRepositoryOptions{IncludeSdkPreferences: true}
+	if value := req.Header().Get(IncludeSdkPreferencesHeader); value != "" {
+		include, err := strconv.ParseBool(value)
+		if err != nil {
+			return nil, connect.NewError(connect.CodeInvalidArgument, fmt.Errorf("invalid %s header: %q", IncludeSdkPreferencesHeader, value))
+		}
+		opts.IncludeSdkPreferences = include
+	}
+	if value := req.Header().Get(IncludeSdkHealthHeader); value != "" {
+		include, err := strconv.ParseBool(value)
+		if err != nil {
+			return nil, connect.NewError(connect.CodeInvalidArgument, fmt.Errorf("invalid %s header: %q", IncludeSdkHealthHeader, value))
+		}
+		opts.IncludeSdkHealth = include
+	}
+
+	repo, err := h.service.GetRepository(ctx, req.Msg, opts)
 	if err != nil {
 		return nil, err
 	}
 
-	return connect.NewResponse(repo), nil
+	res := connect.NewResponse(repo.Repository)
+	if repo.Empty {
+		res.Header().Set(RepositoryEmptyHeader, "true")
+	}
+	res.Header().Set(PushLintHeader, strconv.FormatBool(repo.PushLint))
+	res.Header().Set(ArchivedHeader, strconv.FormatBool(repo.Archived))
+	if repo.ForkedFrom != nil {
+		res.Header().Set(ForkedFromHeader, *repo.ForkedFrom)
+	}
+	for _, health := range repo.SdkHealth {
+		res.Header().Add(SdkHealthHeader, fmt.Sprintf("%s=%s", SdkDbToProtoEnum[health.Sdk], health.Status))
+	}
+	h.setAvailableSdks(res.Header())
+	cloneUrls := h.service.GetCloneUrls(repo.GetId())
+	if cloneUrls.Http != "" {
+		res.Header().Set(HttpCloneUrlHeader, cloneUrls.Http)
+	}
+	if cloneUrls.Ssh != "" {
+		res.Header().Set(SshCloneUrlHeader, cloneUrls.Ssh)
+	}
+
+	return res, nil
 }
 
 func (h *handler) GetRepositories(
@@ -107,22 +286,55 @@ func (h *handler) GetRepositories(
 		organizationId = &orgId
 	}
 
-	resp, err := h.service.GetRepositories(ctx, organizationId, page, pageSize)
+	repositories, err := h.service.GetRepositories(ctx, organizationId, page, pageSize)
 	if err != nil {
 		return nil, err
 	}
 
-	return connect.NewResponse(resp), nil
+	res := connect.NewResponse(repositories.GetRepositoriesResponse)
+	for _, id := range repositories.ArchivedIds {
+		res.Header().Add(ArchivedRepositoryHeader, id)
+	}
+
+	return res, nil
 }
 
 func (h *handler) UpdateRepository(
 	ctx context.Context,
 	req *connect.Request[registryv1.UpdateRepositoryRequest],
 ) (*connect.Response[emptypb.Empty], error) {
+	var pushLint *bool
+	if value := req.Header().Get(PushLintHeader); value != "" {
+		enabled, err := strconv.ParseBool(value)
+		if err != nil {
+			return nil, connect.NewError(connect.CodeInvalidArgument, fmt.Errorf("invalid %s header: %q", PushLintHeader, value))
+		}
+		pushLint = &enabled
+	}
+	var archived *bool
+	if value := req.Header().Get(ArchivedHeader); value != "" {
+		enabled, err := strconv.ParseBool(value)
+		if err != nil {
+			return nil, connect.NewError(connect.CodeInvalidArgument, fmt.Errorf("invalid %s header: %q", ArchivedHeader, value))
+		}
+		archived = &enabled
+	}
+
 	if err := h.service.UpdateRepository(ctx, req.Msg); err != nil {
 		return nil, err
 	}
 
+	if pushLint != nil {
+		if err := h.service.SetPushLint(ctx, req.Msg.GetId(), *pushLint); err != nil {
+			return nil, err
+		}
+	}
+	if archived != nil {
+		if err := h.service.SetRepositoryArchived(ctx, req.Msg.GetId(), *archived); err != nil {
+			return nil, err
+		}
+	}
+
 	return connect.NewResponse(new(emptypb.Empty)), nil
 }
 
@@ -145,19 +357,33 @@ func (h *handler) UpdateSdkPreferences(
 		return nil, err
 	}
 
-	return connect.NewResponse(new(emptypb.Empty)), nil
+	res := connect.NewResponse(new(emptypb.Empty))
+	h.setAvailableSdks(res.Header())
+
+	return res, nil
+}
+
+func (h *handler) setAvailableSdks(header http.Header) {
+	for _, sdk := range h.service.AvailableSdks() {
+		header.Add(AvailableSdkHeader, sdk.String())
+	}
 }
 
 func (h *handler) GetCommits(
 	ctx context.Context,
 	req *connect.Request[registryv1.GetCommitsRequest],
 ) (*connect.Response[registryv1.GetCommitsResponse], error) {
-	commits, err := h.service.GetCommits(ctx, req.Msg)
+	commits, err := h.service.GetCommits(ctx, req.Msg, req.Header().Get(RefHeader))
 	if err != nil {
 		return nil, err
 	}
 
-	return connect.NewResponse(commits), nil
+	res := connect.NewResponse(commits.GetCommitsResponse)
+	if commits.Empty {
+		res.Header().Set(RepositoryEmptyHeader, "true")
+	}
+
+	return res, nil
 }
 
 func (h *handler) GetRecentCommit(
@@ -169,31 +395,76 @@ func (h *handler) GetRecentCommit(
 		return nil, err
 	}
 
-	return connect.NewResponse(commit), nil
+	res := connect.NewResponse(commit.Commit)
+	if commit.Empty {
+		res.Header().Set(RepositoryEmptyHeader, "true")
+	}
+
+	return res, nil
 }
 
 func (h *handler) GetFileTree(
 	ctx context.Context,
 	req *connect.Request[registryv1.GetFileTreeRequest],
 ) (*connect.Response[registryv1.GetFileTreeResponse], error) {
-	fileTree, err := h.service.GetFileTree(ctx, req.Msg)
+	var page FileTreePage
+	var err error
+	if page.Offset, err = nonNegativeIntHeader(req.Header(), FileTreeOffsetHeader); err != nil {
+		return nil, err
+	}
+	if page.Limit, err = nonNegativeIntHeader(req.Header(), FileTreeLimitHeader); err != nil {
+		return nil, err
+	}
+
+	fileTree, err := h.service.GetFileTree(ctx, req.Msg, req.Header().Get(RefHeader), page)
 	if err != nil {
 		return nil, err
 	}
 
-	return connect.NewResponse(fileTree), nil
+	res := connect.NewResponse(fileTree.GetFileTreeResponse)
+	res.Header().Set(FileTreeTotalCountHeader, strconv.Itoa(fileTree.TotalCount))
+	for _, path := range fileTree.TruncatedPaths {
+		res.Header().Add(FileTreeTruncatedHeader, path)
+	}
+
+	return res, nil
+}
+
+func nonNegativeIntHeader(header http.Header, name string) (int, error) {
+	value := header.Get(name)
+	if value == "" {
+		return 0, nil
+	}
+
+	n, err := strconv.Atoi(value)
+	if err != nil || n < 0 {
+		return 0, connect.NewError(connect.CodeInvalidArgument, fmt.Errorf("invalid %s header: %q", name, value))
+	}
+
+	return n, nil
 }
 
 func (h *handler) GetFilePreview(
 	ctx context.Context,
 	req *connect.Request[registryv1.GetFilePreviewRequest],
 ) (*connect.Response[registryv1.GetFilePreviewResponse], error) {
-	filePreview, err := h.service.GetFilePreview(ctx, req.Msg)
+	filePreview, err := h.service.GetFilePreview(ctx, req.Msg, req.Header().Get(RefHeader))
 	if err != nil {
 		return nil, err
 	}
 
-	return connect.NewResponse(filePreview), nil
+	res := connect.NewResponse(filePreview.GetFilePreviewResponse)
+	if filePreview.Truncated {
+		res.Header().Set(PreviewTruncatedHeader, "true")
+	}
+	if filePreview.Binary {
+		res.Header().Set(PreviewBinaryHeader, "true")
+	}
+	if filePreview.Language != "" {
+		res.Header().Set(PreviewLanguageHeader, filePreview.Language)
+	}
+
+	return res, nil
 }
 
 type GitSshHandler struct {
@@ -242,6 +513,9 @@ func (h *GitSshHandler) HandleSession(session ssh.Session, userId string) error
 	fullRepoPath := h.reposPath + "/" + strings.TrimSuffix(repoPath, ".git")
 
 	hasAccess, err := h.service.ValidateSshAccess(context.Background(), userId, fullRepoPath, operation)
+	if errors.Is(err, ErrRepositoryArchived) {
+		return errors.New(ErrRepositoryArchived.Message())
+	}
 	if err != nil {
 		zap.L().Error("Access validation failed", zap.String("userId", userId), zap.Error(err))
 		return fmt.Errorf("access validation failed: %w", err)
@@ -273,6 +547,28 @@ func (h *GitSshHandler) HandleSession(session ssh.Session, userId string) error
 		return fmt.Errorf("not a git repository: %s", absRepoPath)
 	}
 
+	if operation == SshOperationRead {
+		release, err := h.service.AcquireCloneSlot(userId)
+		if errors.Is(err, ErrTooManyClones) {
+			return errors.New(ErrTooManyClones.Message())
+		}
+		if err != nil {
+			return fmt.Errorf("failed to start clone: %w", err)
+		}
+		defer release()
+	}
+
+	if operation == SshOperationWrite {
+		unlock, err := h.service.LockRepositoryForPush(session.Context(), fullRepoPath)
+		if errors.Is(err, ErrPushInProgress) {
+			return errors.New(ErrPushInProgress.Message())
+		}
+		if err != nil {
+			return fmt.Errorf("failed to lock repository: %w", err)
+		}
+		defer unlock()
+	}
+
 	var execCmd *exec.Cmd
 	switch safeGitCmd {
 	case "git-upload-pack":
@@ -294,12 +590,7 @@ func (h *GitSshHandler) HandleSession(session ssh.Session, userId string) error
 		zap.String("command", gitCmd),
 		zap.String("repoPath", absRepoPath))
 
-	if err := execCmd.Start(); err != nil {
-		zap.L().Error("Failed to start git command", zap.Error(err))
-		return fmt.Errorf("failed to start %s: %w", gitCmd, err)
-	}
-
-	if err := execCmd.Wait(); err != nil {
+	if err := runGitCommand(session.Context(), filepath.Base(absRepoPath), safeGitCmd, execCmd); err != nil {
 		return err
 	}
 
@@ -322,6 +613,13 @@ func (h *GitSshHandler) triggerPostPushActions(repoPath string) {
 		return
 	}
 
+	if err := h.service.RecordPush(ctx, repoId, commitHash); err != nil {
+		zap.L().Warn("failed to record push",
+			zap.String("repoId", repoId),
+			zap.String("commitHash", commitHash),
+			zap.Error(err))
+	}
+
 	hasProtoFiles, err := h.service.HasProtoFiles(ctx, repoPath)
 	if err != nil {
 		zap.L().Warn("failed to check for proto files",
@@ -364,10 +662,53 @@ func (h *GitSshHandler) getLatestCommitHash(repoPath string) (string, error) {
 	return strings.TrimSpace(string(output)), nil
 }
 
+const (
+	gitUploadPack  = "git-upload-pack"
+	gitReceivePack = "git-receive-pack"
+)
+
+// gitHttpServices is the complete set of git programs the HTTP handlers may
+// run, mapped to the access they require.
+var gitHttpServices = map[string]SshOperation{
+	gitUploadPack:  SshOperationRead,
+	gitReceivePack: SshOperationWrite,
+}
+
+var errInvalidGitService = errors.New("invalid git service")
+
+// resolveGitService returns the git program a smart HTTP request targets.
+// info/refs names it in the service query parameter and the RPC endpoints in
+// the path; any value outside gitHttpServices is rejected so it never reaches
+// exec. An empty result means the path does not target a git program.
+func resolveGitService(r *http.Request, subPath string) (string, error) {
+	query := r.URL.Query()
+	if query.Has("service") {
+		if _, ok := gitHttpServices[query.Get("service")]; !ok {
+			return "", errInvalidGitService
+		}
+	}
+
+	switch subPath {
+	case "info/refs":
+		if !query.Has("service") {
+			return "", errInvalidGitService
+		}
+		return query.Get("service"), nil
+	case gitUploadPack, gitReceivePack:
+		if query.Has("service") && query.Get("service") != subPath {
+			return "", errInvalidGitService
+		}
+		return subPath, nil
+	default:
+		return "", nil
+	}
+}
+
 type GitHttpHandler struct {
 	service   Service
 	userRepo  user.Repository
 	reposPath string
+	command   func(name string, arg ...string) *exec.Cmd
 }
 
 func NewGitHttpHandler(service Service, userRepo user.Repository, reposPath string) *GitHttpHandler {
@@ -375,20 +716,15 @@ func NewGitHttpHandler(service Service, userRepo user.Repository, reposPath stri
 		service:   service,
 		userRepo:  userRepo,
 		reposPath: reposPath,
+		command:   exec.Command,
 	}
 }
 
 func (h *GitHttpHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
-	userId, err := h.authenticate(r)
-	if err != nil {
-		h.requireAuth(w)
-		return
-	}
-
 	path := strings.TrimPrefix(r.URL.Path, "/git/")
 	parts := strings.SplitN(path, "/", 2)
 	if len(parts) < 1 || parts[0] == "" {
-		http.Error(w, "Repository not found", http.StatusNotFound)
+		writeHttpError(w, http.StatusNotFound, "Repository not found")
 		return
 	}
 
@@ -399,40 +735,122 @@ func (h *GitHttpHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 		subPath = parts[1]
 	}
 
-	var operation SshOperation
-	serviceName := r.URL.Query().Get("service")
-	if serviceName == "git-receive-pack" || subPath == "git-receive-pack" {
-		operation = SshOperationWrite
-	} else {
-		operation = SshOperationRead
+	gitService, err := resolveGitService(r, subPath)
+	if err != nil {
+		writeHttpError(w, http.StatusBadRequest, "Invalid service")
+		return
 	}
 
-	hasAccess, err := h.service.ValidateSshAccess(r.Context(), userId, repoPath, operation)
+	operation := SshOperationRead
+	if gitService != "" {
+		operation = gitHttpServices[gitService]
+	}
+
+	if operation == SshOperationRead && !hasBasicAuth(r) && h.isPublicRepository(r.Context(), repoPath) {
+		h.serveGit(w, r, subPath, gitService, repoPath)
+		return
+	}
+
+	cloneKey, hasAccess, err := h.authorize(r, repoPath, operation)
+	if errors.Is(err, errGitHttpUnauthenticated) {
+		zap.L().Warn("Git HTTP authentication failed",
+			zap.String("clientIp", middleware.ClientIPFromContext(r.Context())),
+			zap.Error(err),
+		)
+		h.requireAuth(w)
+		return
+	}
+	if errors.Is(err, ErrRepositoryArchived) {
+		writeHttpError(w, http.StatusForbidden, ErrRepositoryArchived.Message())
+		return
+	}
 	if err != nil {
 		zap.L().Error("Access validation failed", zap.Error(err))
-		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		writeHttpError(w, http.StatusInternalServerError, "Internal server error")
 		return
 	}
 	if !hasAccess {
-		http.Error(w, "Permission denied", http.StatusForbidden)
+		writeHttpError(w, http.StatusForbidden, "Permission denied")
 		return
 	}
 
+	if operation == SshOperationRead {
+		release, err := h.service.AcquireCloneSlot(cloneKey)
+		if errors.Is(err, ErrTooManyClones) {
+			writeHttpError(w, http.StatusTooManyRequests, ErrTooManyClones.Message())
+			return
+		}
+		if err != nil {
+			writeHttpError(w, http.StatusInternalServerError, "Internal server error")
+			return
+		}
+		defer release()
+	}
+
+	h.serveGit(w, r, subPath, gitService, repoPath)
+}
+
+var errGitHttpUnauthenticated = errors.New("git http authentication failed")
+
+// authorize checks the basic auth password either as a deploy token, which
+// only opens its own repository, or as the API key of a user. Credentials
+// that identify nobody are reported as errGitHttpUnauthenticated. The
+// returned key is what concurrent clones are counted against: the user id, or
+// the deployTokenCloneKey of a deploy token.
+func (h *GitHttpHandler) authorize(r *http.Request, repoPath string, operation SshOperation) (string, bool, error) {
+	if _, password, ok := r.BasicAuth(); ok && IsDeployToken(password) {
+		tokenId, hasAccess, err := h.service.ValidateDeployTokenAccess(r.Context(), password, repoPath, operation)
+		if connect.CodeOf(err) == connect.CodeNotFound {
+			return "", false, fmt.Errorf("%w: %w", errGitHttpUnauthenticated, err)
+		}
+		return deployTokenCloneKey(tokenId), hasAccess, err
+	}
+
+	userId, err := h.authenticate(r)
+	if err != nil {
+		return "", false, fmt.Errorf("%w: %w", errGitHttpUnauthenticated, err)
+	}
+
+	hasAccess, err := h.service.ValidateSshAccess(r.Context(), userId, repoPath, operation)
+	return userId, hasAccess, err
+}
+
+func (h *GitHttpHandler) serveGit(w http.ResponseWriter, r *http.Request, subPath, gitService, repoPath string) {
 	switch {
 	case subPath == "info/refs":
-		h.handleInfoRefs(w, r, repoPath)
-	case subPath == "git-upload-pack" && r.Method == http.MethodPost:
+		h.handleInfoRefs(w, r, gitService, repoPath)
+	case subPath == gitUploadPack && r.Method == http.MethodPost:
 		h.handleUploadPack(w, r, repoPath)
-	case subPath == "git-receive-pack" && r.Method == http.MethodPost:
+	case subPath == gitReceivePack && r.Method == http.MethodPost:
 		h.handleReceivePack(w, r, repoPath)
 	default:
-		http.Error(w, "Not found", http.StatusNotFound)
+		writeHttpError(w, http.StatusNotFound, "Not found")
+	}
+}
+
+// isPublicRepository treats lookup failures as private so that anonymous
+// clients cannot tell missing repositories apart from private ones.
+func (h *GitHttpHandler) isPublicRepository(ctx context.Context, repoPath string) bool {
+	isPublic, err := h.service.IsPublicRepository(ctx, repoPath)
+	if err != nil {
+		zap.L().Debug("Failed to resolve repository visibility",
+			zap.String("repoPath", repoPath),
+			zap.Error(err),
+		)
+		return false
 	}
+
+	return isPublic
+}
+
+func hasBasicAuth(r *http.Request) bool {
+	_, _, ok := r.BasicAuth()
+	return ok
 }
 
 func (h *GitHttpHandler) requireAuth(w http.ResponseWriter) {
 	w.Header().Set("WWW-Authenticate", `Basic realm="Git Repository"`)
-	http.Error(w, "Authentication required", http.StatusUnauthorized)
+	writeHttpError(w, http.StatusUnauthorized, "Authentication required")
 }
 
 func (h *GitHttpHandler) authenticate(r *http.Request) (string, error) {
@@ -449,61 +867,77 @@ func (h *GitHttpHandler) authenticate(r *http.Request) (string, error) {
 	return userDTO.Id, nil
 }
 
-func (h *GitHttpHandler) handleInfoRefs(w http.ResponseWriter, r *http.Request, repoPath string) {
-	serviceName := r.URL.Query().Get("service")
-
-	var gitCommand string
-	switch serviceName {
-	case "git-upload-pack":
-		gitCommand = "git-upload-pack"
-	case "git-receive-pack":
-		gitCommand = "git-receive-pack"
-	default:
-		http.Error(w, "Invalid service", http.StatusBadRequest)
-		return
-	}
-
-	w.Header().Set("Content-Type", fmt.Sprintf("application/x-%s-advertisement", serviceName))
+func (h *GitHttpHandler) handleInfoRefs(w http.ResponseWriter, r *http.Request, gitService, repoPath string) {
+	w.Header().Set("Content-Type", fmt.Sprintf("application/x-%s-advertisement", gitService))
 	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Add("Vary", "Accept-Encoding")
 
-	pktLine := fmt.Sprintf("# service=%s\n", serviceName)
-	_, _ = fmt.Fprintf(w, "%04x%s", len(pktLine)+4, pktLine)
-	_, _ = fmt.Fprint(w, "0000")
-
-	var cmd *exec.Cmd
-	switch gitCommand {
-	case "git-upload-pack":
-		cmd = exec.Command("git-upload-pack", "--stateless-rpc", "--advertise-refs", repoPath)
-	case "git-receive-pack":
-		cmd = exec.Command("git-receive-pack", "--stateless-rpc", "--advertise-refs", repoPath)
-	default:
-		http.Error(w, "Invalid service", http.StatusBadRequest)
-		return
+	// The ref advertisement of a repository with many branches and tags is
+	// large and compresses well.
+	var out io.Writer = w
+	if acceptsGzip(r) {
+		w.Header().Set("Content-Encoding", "gzip")
+		gz := gzip.NewWriter(w)
+		defer func() {
+			_ = gz.Close()
+		}()
+		out = gz
 	}
 
-	cmd.Stdout = w
-	cmd.Stderr = w
+	pktLine := fmt.Sprintf("# service=%s\n", gitService)
+	_, _ = fmt.Fprintf(out, "%04x%s", len(pktLine)+4, pktLine)
+	_, _ = fmt.Fprint(out, "0000")
 
-	if err := cmd.Run(); err != nil {
-		zap.L().Error("Failed to run git command", zap.String("service", serviceName), zap.Error(err))
+	cmd := h.command(gitService, "--stateless-rpc", "--advertise-refs", repoPath)
+	cmd.Stdout = out
+	cmd.Stderr = out
+
+	if err := runGitCommand(r.Context(), filepath.Base(repoPath), gitService+" --advertise-refs", cmd); err != nil {
+		zap.L().Error("Failed to run git command", zap.String("service", gitService), zap.Error(err))
 	}
 }
 
 func (h *GitHttpHandler) handleUploadPack(w http.ResponseWriter, r *http.Request, repoPath string) {
+	body, ok := gitRequestBody(w, r)
+	if !ok {
+		return
+	}
+	defer func() {
+		_ = body.Close()
+	}()
+
 	w.Header().Set("Content-Type", "application/x-git-upload-pack-result")
 	w.Header().Set("Cache-Control", "no-cache")
 
-	cmd := exec.Command("git-upload-pack", "--stateless-rpc", repoPath)
-	cmd.Stdin = r.Body
+	cmd := h.command(gitUploadPack, "--stateless-rpc", repoPath)
+	cmd.Stdin = body
 	cmd.Stdout = w
 	cmd.Stderr = w
 
-	if err := cmd.Run(); err != nil {
+	if err := runGitCommand(r.Context(), filepath.Base(repoPath), gitUploadPack, cmd); err != nil {
 		zap.L().Error("git-upload-pack failed", zap.Error(err))
 	}
 }
 
 func (h *GitHttpHandler) handleReceivePack(w http.ResponseWriter, r *http.Request, repoPath string) {
+	body, ok := gitRequestBody(w, r)
+	if !ok {
+		return
+	}
+	defer func() {
+		_ = body.Close()
+	}()
+
+	unlock, err := h.service.LockRepositoryForPush(r.Context(), repoPath)
+	if errors.Is(err, ErrPushInProgress) {
+		writeHttpError(w, http.StatusConflict, ErrPushInProgress.Message())
+		return
+	}
+	if err != nil {
+		return
+	}
+	defer unlock()
+
 	w.Header().Set("Content-Type", "application/x-git-receive-pack-result")
 	w.Header().Set("Cache-Control", "no-cache")
 
@@ -511,12 +945,12 @@ func (h *GitHttpHandler) handleReceivePack(w http.ResponseWriter, r *http.Reques
 	pktLine := fmt.Sprintf("%04x%s", len(message)+4, message)
 	_, _ = w.Write([]byte(pktLine))
 
-	cmd := exec.Command("git-receive-pack", "--stateless-rpc", repoPath)
-	cmd.Stdin = r.Body
+	cmd := h.command(gitReceivePack, "--stateless-rpc", repoPath)
+	cmd.Stdin = body
 	cmd.Stdout = w
 	cmd.Stderr = w
 
-	if err := cmd.Run(); err != nil {
+	if err := runGitCommand(r.Context(), filepath.Base(repoPath), gitReceivePack, cmd); err != nil {
 		zap.L().Error("git-receive-pack failed", zap.Error(err))
 		return
 	}
@@ -524,6 +958,48 @@ func (h *GitHttpHandler) handleReceivePack(w http.ResponseWriter, r *http.Reques
 	h.triggerPostPushActions(r.Context(), repoPath)
 }
 
+// gitRequestBody undoes the Content-Encoding git clients apply to larger
+// upload-pack and receive-pack requests. It writes the error response itself
+// when the body cannot be decoded.
+func gitRequestBody(w http.ResponseWriter, r *http.Request) (io.ReadCloser, bool) {
+	switch encoding := strings.ToLower(strings.TrimSpace(r.Header.Get("Content-Encoding"))); encoding {
+	case "", "identity":
+		return r.Body, true
+	case "gzip", "x-gzip":
+		body, err := gzip.NewReader(r.Body)
+		if err != nil {
+			writeHttpError(w, http.StatusBadRequest, "Invalid gzip request body")
+			return nil, false
+		}
+		return body, true
+	default:
+		writeHttpError(w, http.StatusUnsupportedMediaType, fmt.Sprintf("Unsupported content encoding %q", encoding))
+		return nil, false
+	}
+}
+
+// acceptsGzip reports whether the client listed gzip in Accept-Encoding
+// without refusing it with q=0.
+func acceptsGzip(r *http.Request) bool {
+	for _, value := range r.Header.Values("Accept-Encoding") {
+		for _, part := range strings.Split(value, ",") {
+			coding, params, _ := strings.Cut(part, ";")
+			if !strings.EqualFold(strings.TrimSpace(coding), "gzip") {
+				continue
+			}
+
+			qValue, hasQ := strings.CutPrefix(strings.TrimSpace(params), "q=")
+			if !hasQ {
+				return true
+			}
+			q, err := strconv.ParseFloat(qValue, 64)
+			return err == nil && q > 0
+		}
+	}
+
+	return false
+}
+
 func (h *GitHttpHandler) triggerPostPushActions(ctx context.Context, repoPath string) {
 	repoId := filepath.Base(repoPath)
 	commitHash, err := h.getLatestCommitHash(repoPath)
@@ -534,6 +1010,13 @@ func (h *GitHttpHandler) triggerPostPushActions(ctx context.Context, repoPath st
 		return
 	}
 
+	if err := h.service.RecordPush(ctx, repoId, commitHash); err != nil {
+		zap.L().Warn("failed to record push",
+			zap.String("repoId", repoId),
+			zap.String("commitHash", commitHash),
+			zap.Error(err))
+	}
+
 	hasProtoFiles, err := h.service.HasProtoFiles(ctx, repoPath)
 	if err != nil {
 		zap.L().Warn("failed to check for proto files",
@@ -612,7 +1095,7 @@ func (h *SdkHttpHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 	parts := strings.SplitN(path, "/", 4)
 
 	if len(parts) < 3 {
-		http.Error(w, "Invalid SDK path. Format: /sdk/{orgId}/{repoId}/{sdkType}/", http.StatusNotFound)
+		writeHttpError(w, http.StatusNotFound, "Invalid SDK path. Format: /sdk/{orgId}/{repoId}/{sdkType}/")
 		return
 	}
 
@@ -620,28 +1103,28 @@ func (h *SdkHttpHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 	repoId := strings.TrimSuffix(parts[1], ".git")
 	sdkType := strings.TrimSuffix(parts[2], ".git")
 	if !isValidPathComponent(orgId) || !isValidPathComponent(repoId) || !isValidPathComponent(sdkType) {
-		http.Error(w, "Invalid path component", http.StatusBadRequest)
+		writeHttpError(w, http.StatusBadRequest, "Invalid path component")
 		return
 	}
 
 	repoPath := filepath.Join(h.sdkReposPath, orgId, repoId, sdkType)
 	absRepoPath, err := filepath.Abs(repoPath)
 	if err != nil {
-		http.Error(w, "Invalid path", http.StatusBadRequest)
+		writeHttpError(w, http.StatusBadRequest, "Invalid path")
 		return
 	}
 	absSdkReposPath, err := filepath.Abs(h.sdkReposPath)
 	if err != nil {
-		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		writeHttpError(w, http.StatusInternalServerError, "Internal server error")
 		return
 	}
 	if !strings.HasPrefix(absRepoPath, absSdkReposPath+string(filepath.Separator)) {
-		http.Error(w, "Invalid path", http.StatusBadRequest)
+		writeHttpError(w, http.StatusBadRequest, "Invalid path")
 		return
 	}
 
 	if _, err := os.Stat(filepath.Join(repoPath, ".git")); os.IsNotExist(err) {
-		http.Error(w, "SDK repository not found", http.StatusNotFound)
+		writeHttpError(w, http.StatusNotFound, "SDK repository not found")
 		return
 	}
 
@@ -650,31 +1133,75 @@ func (h *SdkHttpHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 		subPath = parts[3]
 	}
 
-	serviceName := r.URL.Query().Get("service")
-	if serviceName == "git-receive-pack" || subPath == "git-receive-pack" {
-		http.Error(w, "SDK repositories are read-only", http.StatusForbidden)
+	gitService, err := resolveGitService(r, subPath)
+	if err != nil {
+		writeHttpError(w, http.StatusBadRequest, "Invalid service")
+		return
+	}
+	if gitService == gitReceivePack {
+		writeHttpError(w, http.StatusForbidden, "SDK repositories are read-only")
 		return
 	}
 
 	switch {
 	case subPath == "info/refs":
 		h.handleInfoRefs(w, r, repoPath)
-	case subPath == "git-upload-pack" && r.Method == http.MethodPost:
+	case subPath == gitUploadPack && r.Method == http.MethodPost:
 		h.handleUploadPack(w, r, repoPath)
+	case subPath != "" && (r.Method == http.MethodGet || r.Method == http.MethodHead):
+		h.serveFile(w, r, absRepoPath, subPath)
 	default:
-		http.Error(w, "Not found", http.StatusNotFound)
+		writeHttpError(w, http.StatusNotFound, "Not found")
+	}
+}
+
+// serveFile serves a file from the SDK's working tree. http.ServeContent
+// answers Range requests, so interrupted downloads of large generated files
+// can be resumed.
+func (h *SdkHttpHandler) serveFile(w http.ResponseWriter, r *http.Request, absRepoPath, subPath string) {
+	for _, component := range strings.Split(subPath, "/") {
+		if component == ".git" || !isValidPathComponent(component) {
+			writeHttpError(w, http.StatusNotFound, "Not found")
+			return
+		}
+	}
+
+	filePath := filepath.Join(absRepoPath, filepath.FromSlash(subPath))
+	if !strings.HasPrefix(filePath, absRepoPath+string(filepath.Separator)) {
+		writeHttpError(w, http.StatusBadRequest, "Invalid path")
+		return
+	}
+
+	// #nosec G304 -- filePath is confined to the SDK repository above
+	file, err := os.Open(filePath)
+	if err != nil {
+		writeHttpError(w, http.StatusNotFound, "Not found")
+		return
+	}
+	defer func() {
+		_ = file.Close()
+	}()
+
+	info, err := file.Stat()
+	if err != nil || !info.Mode().IsRegular() {
+		writeHttpError(w, http.StatusNotFound, "Not found")
+		return
 	}
+
+	http.ServeContent(w, r, info.Name(), info.ModTime(), file)
 }
 
 func (h *SdkHttpHandler) handleInfoRefs(w http.ResponseWriter, r *http.Request, repoPath string) {
 	serviceName := r.URL.Query().Get("service")
 	if serviceName != "git-upload-pack" {
-		http.Error(w, "Only git-upload-pack is supported for SDK repos", http.StatusBadRequest)
+		writeHttpError(w, http.StatusBadRequest, "Only git-upload-pack is supported for SDK repos")
 		return
 	}
 
 	w.Header().Set("Content-Type", fmt.Sprintf("application/x-%s-advertisement", serviceName))
 	w.Header().Set("Cache-Control", "no-cache")
+	// Pack responses are generated per request and cannot be resumed.
+	w.Header().Set("Accept-Ranges", "none")
 
 	pktLine := fmt.Sprintf("# service=%s\n", serviceName)
 	_, _ = fmt.Fprintf(w, "%04x%s", len(pktLine)+4, pktLine)
@@ -690,11 +1217,20 @@ func (h *SdkHttpHandler) handleInfoRefs(w http.ResponseWriter, r *http.Request,
 }
 
 func (h *SdkHttpHandler) handleUploadPack(w http.ResponseWriter, r *http.Request, repoPath string) {
+	body, ok := gitRequestBody(w, r)
+	if !ok {
+		return
+	}
+	defer func() {
+		_ = body.Close()
+	}()
+
 	w.Header().Set("Content-Type", "application/x-git-upload-pack-result")
 	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Accept-Ranges", "none")
 
 	cmd := exec.Command("git-upload-pack", "--stateless-rpc", repoPath)
-	cmd.Stdin = r.Body
+	cmd.Stdin = body
 	cmd.Stdout = w
 	cmd.Stderr = w
 
@@ -807,26 +1343,30 @@ type DocumentationHttpHandler struct {
 	service    Service
 	repository Repository
 	jwtSecret  []byte
-	sdkPath    string
+	docsPath   string
 }
 
 func NewDocumentationHttpHandler(
 	service Service,
 	repository Repository,
 	jwtSecret []byte,
-	sdkPath string,
+	docsPath string,
 ) *DocumentationHttpHandler {
 	return &DocumentationHttpHandler{
 		service:    service,
 		repository: repository,
 		jwtSecret:  jwtSecret,
-		sdkPath:    sdkPath,
+		docsPath:   docsPath,
 	}
 }
 
 func (h *DocumentationHttpHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 	userId, err := h.authenticate(r)
 	if err != nil {
+		zap.L().Warn("documentation authentication failed",
+			zap.String("clientIp", middleware.ClientIPFromContext(r.Context())),
+			zap.Error(err),
+		)
 		h.requireAuth(w)
 		return
 	}
@@ -835,7 +1375,7 @@ func (h *DocumentationHttpHandler) ServeHTTP(w http.ResponseWriter, r *http.Requ
 	parts := strings.SplitN(path, "/", 3)
 
 	if len(parts) < 3 {
-		http.Error(w, "Invalid documentation path. Format: /docs/{orgId}/{repoId}/{commitHash}", http.StatusBadRequest)
+		writeHttpError(w, http.StatusBadRequest, "Invalid documentation path. Format: /docs/{orgId}/{repoId}/{commitHash}")
 		return
 	}
 
@@ -844,63 +1384,63 @@ func (h *DocumentationHttpHandler) ServeHTTP(w http.ResponseWriter, r *http.Requ
 	commitHash := parts[2]
 
 	if !isValidPathComponent(orgId) || !isValidPathComponent(repoId) || !isValidPathComponent(commitHash) {
-		http.Error(w, "Invalid path component", http.StatusBadRequest)
+		writeHttpError(w, http.StatusBadRequest, "Invalid path component")
 		return
 	}
 
-	docPath := filepath.Join(h.sdkPath, orgId, repoId, commitHash, "docs", "index.md")
+	docPath := filepath.Join(h.docsPath, orgId, repoId, commitHash, "docs", "index.md")
 	absDocPath, err := filepath.Abs(docPath)
 	if err != nil {
-		http.Error(w, "Invalid path", http.StatusBadRequest)
+		writeHttpError(w, http.StatusBadRequest, "Invalid path")
 		return
 	}
-	absSdkPath, err := filepath.Abs(h.sdkPath)
+	absDocsPath, err := filepath.Abs(h.docsPath)
 	if err != nil {
-		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		writeHttpError(w, http.StatusInternalServerError, "Internal server error")
 		return
 	}
-	if !strings.HasPrefix(absDocPath, absSdkPath+string(filepath.Separator)) {
-		http.Error(w, "Invalid path", http.StatusBadRequest)
+	if !strings.HasPrefix(absDocPath, absDocsPath+string(filepath.Separator)) {
+		writeHttpError(w, http.StatusBadRequest, "Invalid path")
 		return
 	}
 
 	repo, err := h.repository.GetRepositoryById(r.Context(), repoId)
 	if err != nil {
 		zap.L().Error("Failed to get repository", zap.Error(err))
-		http.Error(w, "Repository not found", http.StatusNotFound)
+		writeHttpError(w, http.StatusNotFound, "Repository not found")
 		return
 	}
 
 	if repo.OrganizationId != orgId {
-		http.Error(w, "Repository not found", http.StatusNotFound)
+		writeHttpError(w, http.StatusNotFound, "Repository not found")
 		return
 	}
 
 	repoPath := repo.Path
 	if repoPath == "" {
-		repoPath = filepath.Join(DefaultReposPath, repoId)
+		repoPath = repoId
 	}
 	hasAccess, err := h.service.ValidateSshAccess(r.Context(), userId, repoPath, SshOperationRead)
 	if err != nil {
 		zap.L().Error("Access validation failed", zap.Error(err))
-		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		writeHttpError(w, http.StatusInternalServerError, "Internal server error")
 		return
 	}
 	if !hasAccess {
-		http.Error(w, "Permission denied", http.StatusForbidden)
+		writeHttpError(w, http.StatusForbidden, "Permission denied")
 		return
 	}
 
 	if _, err := os.Stat(absDocPath); os.IsNotExist(err) {
-		http.Error(w, "Documentation not found", http.StatusNotFound)
+		writeHttpError(w, http.StatusNotFound, "Documentation not found")
 		return
 	}
 
-	// #nosec G304 -- absDocPath is validated on line 846 to ensure it's within absSdkPath, preventing directory traversal
+	// #nosec G304 -- absDocPath is validated on line 846 to ensure it's within absDocsPath, preventing directory traversal
 	markdownContent, err := os.ReadFile(absDocPath)
 	if err != nil {
 		zap.L().Error("Failed to read documentation file", zap.Error(err))
-		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		writeHttpError(w, http.StatusInternalServerError, "Internal server error")
 		return
 	}
 
@@ -911,7 +1451,7 @@ func (h *DocumentationHttpHandler) ServeHTTP(w http.ResponseWriter, r *http.Requ
 
 func (h *DocumentationHttpHandler) requireAuth(w http.ResponseWriter) {
 	w.Header().Set("WWW-Authenticate", `Bearer realm="Documentation"`)
-	http.Error(w, "Authentication required", http.StatusUnauthorized)
+	writeHttpError(w, http.StatusUnauthorized, "Authentication required")
 }
 
 func (h *DocumentationHttpHandler) authenticate(r *http.Request) (string, error) {
@@ -1,7 +1,10 @@
 package registry
 
 import (
+	"bytes"
+	"compress/gzip"
 	"context"
+	"encoding/json"
 	"errors"
 	"fmt"
 	"io"
@@ -10,12 +13,18 @@ import (
 	"os"
 	"os/exec"
 	"path/filepath"
+	"slices"
+	"sync"
 	"testing"
 
 	"connectrpc.com/connect"
 	"github.com/golang-jwt/jwt/v5"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
 	"go.uber.org/mock/gomock"
 
 	"buf.build/gen/go/hasir/hasir/connectrpc/go/registry/v1/registryv1connect"
@@ -24,6 +33,8 @@ import (
 
 	"hasir-api/internal/user"
 	"hasir-api/pkg/authentication"
+	"hasir-api/pkg/authorization"
+	"hasir-api/pkg/config"
 )
 
 var ErrRepositoryNotFound = connect.NewError(connect.CodeNotFound, errors.New("repository not found"))
@@ -82,8 +93,8 @@ func TestHandler_CreateRepository(t *testing.T) {
 		mockRepository := NewMockRepository(ctrl)
 
 		mockService.EXPECT().
-			CreateRepository(gomock.Any(), gomock.Any()).
-			DoAndReturn(func(_ context.Context, req *registryv1.CreateRepositoryRequest) error {
+			CreateRepository(gomock.Any(), gomock.Any(), CreateRepositoryOptions{}).
+			DoAndReturn(func(_ context.Context, req *registryv1.CreateRepositoryRequest, _ CreateRepositoryOptions) error {
 				assert.Equal(t, "test-repo", req.GetName())
 				return nil
 			})
@@ -113,7 +124,7 @@ func TestHandler_CreateRepository(t *testing.T) {
 		mockRepository := NewMockRepository(ctrl)
 
 		mockService.EXPECT().
-			CreateRepository(gomock.Any(), gomock.Any()).
+			CreateRepository(gomock.Any(), gomock.Any(), gomock.Any()).
 			Return(connect.NewError(connect.CodeAlreadyExists, errors.New("repository already exists")))
 
 		h := NewHandler(mockService, mockRepository)
@@ -140,6 +151,160 @@ func TestHandler_CreateRepository(t *testing.T) {
 	})
 }
 
+func TestHandler_CreateRepository_ApplyTemplateHeader(t *testing.T) {
+	newClient := func(t *testing.T, mockService *MockService) registryv1connect.RegistryServiceClient {
+		h := NewHandler(mockService, NewMockRepository(gomock.NewController(t)))
+		mux := http.NewServeMux()
+		path, handler := h.RegisterRoutes()
+		mux.Handle(path, handler)
+
+		server := httptest.NewServer(mux)
+		t.Cleanup(server.Close)
+
+		return registryv1connect.NewRegistryServiceClient(http.DefaultClient, server.URL)
+	}
+
+	t.Run("header enables template", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		mockService := NewMockService(ctrl)
+
+		mockService.EXPECT().
+			CreateRepository(gomock.Any(), gomock.Any(), CreateRepositoryOptions{ApplyTemplate: true}).
+			Return(nil)
+
+		req := connect.NewRequest(&registryv1.CreateRepositoryRequest{Name: "test-repo"})
+		req.Header().Set(ApplyTemplateHeader, "true")
+
+		_, err := newClient(t, mockService).CreateRepository(context.Background(), req)
+		require.NoError(t, err)
+	})
+
+	t.Run("invalid header value", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		mockService := NewMockService(ctrl)
+
+		req := connect.NewRequest(&registryv1.CreateRepositoryRequest{Name: "test-repo"})
+		req.Header().Set(ApplyTemplateHeader, "maybe")
+
+		_, err := newClient(t, mockService).CreateRepository(context.Background(), req)
+		require.Error(t, err)
+		assert.Equal(t, connect.CodeInvalidArgument, connect.CodeOf(err))
+	})
+}
+
+func TestHandler_GetRepository_IncludeSdkPreferencesHeader(t *testing.T) {
+	newClient := func(t *testing.T, mockService *MockService) registryv1connect.RegistryServiceClient {
+		h := NewHandler(mockService, NewMockRepository(gomock.NewController(t)))
+		mux := http.NewServeMux()
+		path, handler := h.RegisterRoutes()
+		mux.Handle(path, handler)
+
+		server := httptest.NewServer(mux)
+		t.Cleanup(server.Close)
+
+		return registryv1connect.NewRegistryServiceClient(http.DefaultClient, server.URL)
+	}
+
+	t.Run("sdk preferences are included by default", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		mockService := NewMockService(ctrl)
+
+		mockService.EXPECT().
+			GetRepository(gomock.Any(), gomock.Any(), GetRepositoryOptions{IncludeSdkPreferences: true}).
+			Return(&RepositoryDetails{Repository: &registryv1.Repository{Id: "test-repo-id"}}, nil)
+		mockService.EXPECT().AvailableSdks().Return(nil)
+		mockService.EXPECT().GetCloneUrls("test-repo-id").Return(CloneUrls{})
+
+		req := connect.NewRequest(&registryv1.GetRepositoryRequest{Id: "test-repo-id"})
+
+		_, err := newClient(t, mockService).GetRepository(context.Background(), req)
+		require.NoError(t, err)
+	})
+
+	t.Run("reports archived state", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		mockService := NewMockService(ctrl)
+
+		mockService.EXPECT().
+			GetRepository(gomock.Any(), gomock.Any(), GetRepositoryOptions{IncludeSdkPreferences: true}).
+			Return(&RepositoryDetails{Repository: &registryv1.Repository{Id: "test-repo-id"}, Archived: true}, nil)
+		mockService.EXPECT().AvailableSdks().Return(nil)
+		mockService.EXPECT().GetCloneUrls("test-repo-id").Return(CloneUrls{})
+
+		req := connect.NewRequest(&registryv1.GetRepositoryRequest{Id: "test-repo-id"})
+
+		resp, err := newClient(t, mockService).GetRepository(context.Background(), req)
+		require.NoError(t, err)
+		assert.Equal(t, "true", resp.Header().Get(ArchivedHeader))
+	})
+
+	t.Run("header opts out of sdk preferences", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		mockService := NewMockService(ctrl)
+
+		mockService.EXPECT().
+			GetRepository(gomock.Any(), gomock.Any(), GetRepositoryOptions{}).
+			Return(&RepositoryDetails{Repository: &registryv1.Repository{Id: "test-repo-id"}}, nil)
+		mockService.EXPECT().AvailableSdks().Return(nil)
+		mockService.EXPECT().GetCloneUrls("test-repo-id").Return(CloneUrls{})
+
+		req := connect.NewRequest(&registryv1.GetRepositoryRequest{Id: "test-repo-id"})
+		req.Header().Set(IncludeSdkPreferencesHeader, "false")
+
+		_, err := newClient(t, mockService).GetRepository(context.Background(), req)
+		require.NoError(t, err)
+	})
+
+	t.Run("header includes sdk health", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		mockService := NewMockService(ctrl)
+
+		mockService.EXPECT().
+			GetRepository(gomock.Any(), gomock.Any(), GetRepositoryOptions{IncludeSdkPreferences: true, IncludeSdkHealth: true}).
+			Return(&RepositoryDetails{
+				Repository: &registryv1.Repository{Id: "test-repo-id"},
+				SdkHealth: []SdkHealth{
+					{Sdk: SdkGoProtobuf, Status: SdkHealthOk},
+					{Sdk: SdkGoConnectRpc, Status: SdkHealthFailed},
+				},
+			}, nil)
+		mockService.EXPECT().AvailableSdks().Return(nil)
+		mockService.EXPECT().GetCloneUrls("test-repo-id").Return(CloneUrls{})
+
+		req := connect.NewRequest(&registryv1.GetRepositoryRequest{Id: "test-repo-id"})
+		req.Header().Set(IncludeSdkHealthHeader, "true")
+
+		resp, err := newClient(t, mockService).GetRepository(context.Background(), req)
+		require.NoError(t, err)
+		assert.Equal(t, []string{"SDK_GO_PROTOBUF=ok", "SDK_GO_CONNECTRPC=failed"}, resp.Header().Values(SdkHealthHeader))
+		assert.Empty(t, resp.Header().Values(ForkedFromHeader))
+	})
+
+	t.Run("rejects invalid sdk health header", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		mockService := NewMockService(ctrl)
+
+		req := connect.NewRequest(&registryv1.GetRepositoryRequest{Id: "test-repo-id"})
+		req.Header().Set(IncludeSdkHealthHeader, "maybe")
+
+		_, err := newClient(t, mockService).GetRepository(context.Background(), req)
+		require.Error(t, err)
+		assert.Equal(t, connect.CodeInvalidArgument, connect.CodeOf(err))
+	})
+
+	t.Run("rejects invalid header", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		mockService := NewMockService(ctrl)
+
+		req := connect.NewRequest(&registryv1.GetRepositoryRequest{Id: "test-repo-id"})
+		req.Header().Set(IncludeSdkPreferencesHeader, "sometimes")
+
+		_, err := newClient(t, mockService).GetRepository(context.Background(), req)
+		require.Error(t, err)
+		assert.Equal(t, connect.CodeInvalidArgument, connect.CodeOf(err))
+	})
+}
+
 func TestHandler_GetRepository(t *testing.T) {
 	t.Run("success", func(t *testing.T) {
 		ctrl := gomock.NewController(t)
@@ -147,14 +312,28 @@ func TestHandler_GetRepository(t *testing.T) {
 		mockRepository := NewMockRepository(ctrl)
 
 		mockService.EXPECT().
-			GetRepository(gomock.Any(), gomock.Any()).
-			DoAndReturn(func(_ context.Context, req *registryv1.GetRepositoryRequest) (*registryv1.Repository, error) {
+			GetRepository(gomock.Any(), gomock.Any(), GetRepositoryOptions{IncludeSdkPreferences: true}).
+			DoAndReturn(func(_ context.Context, req *registryv1.GetRepositoryRequest, _ GetRepositoryOptions) (*RepositoryDetails, error) {
 				assert.Equal(t, "test-repo-id", req.GetId())
-				return &registryv1.Repository{
-					Id:   "test-repo-id",
-					Name: "test-repo",
+				parentId := "parent-repo-id"
+				return &RepositoryDetails{
+					Repository: &registryv1.Repository{
+						Id:   "test-repo-id",
+						Name: "test-repo",
+					},
+					Empty:      true,
+					ForkedFrom: &parentId,
 				}, nil
 			})
+		mockService.EXPECT().
+			AvailableSdks().
+			Return([]registryv1.SDK{registryv1.SDK_SDK_GO_PROTOBUF})
+		mockService.EXPECT().
+			GetCloneUrls("test-repo-id").
+			Return(CloneUrls{
+				Http: "https://hasir.example.com/git/test-repo-id",
+				Ssh:  "git@hasir.example.com:test-repo-id.git",
+			})
 
 		h := NewHandler(mockService, mockRepository)
 		mux := http.NewServeMux()
@@ -176,6 +355,11 @@ func TestHandler_GetRepository(t *testing.T) {
 		assert.NotNil(t, resp)
 		assert.Equal(t, "test-repo-id", resp.Msg.GetId())
 		assert.Equal(t, "test-repo", resp.Msg.GetName())
+		assert.Equal(t, "https://hasir.example.com/git/test-repo-id", resp.Header().Get(HttpCloneUrlHeader))
+		assert.Equal(t, "git@hasir.example.com:test-repo-id.git", resp.Header().Get(SshCloneUrlHeader))
+		assert.Equal(t, "true", resp.Header().Get(RepositoryEmptyHeader))
+		assert.Equal(t, []string{"SDK_GO_PROTOBUF"}, resp.Header().Values(AvailableSdkHeader))
+		assert.Equal(t, "parent-repo-id", resp.Header().Get(ForkedFromHeader))
 	})
 
 	t.Run("service error - repository not found", func(t *testing.T) {
@@ -184,7 +368,7 @@ func TestHandler_GetRepository(t *testing.T) {
 		mockRepository := NewMockRepository(ctrl)
 
 		mockService.EXPECT().
-			GetRepository(gomock.Any(), gomock.Any()).
+			GetRepository(gomock.Any(), gomock.Any(), GetRepositoryOptions{IncludeSdkPreferences: true}).
 			Return(nil, ErrRepositoryNotFound)
 
 		h := NewHandler(mockService, mockRepository)
@@ -216,7 +400,7 @@ func TestHandler_GetRepository(t *testing.T) {
 		mockRepository := NewMockRepository(ctrl)
 
 		mockService.EXPECT().
-			GetRepository(gomock.Any(), gomock.Any()).
+			GetRepository(gomock.Any(), gomock.Any(), GetRepositoryOptions{IncludeSdkPreferences: true}).
 			Return(nil, connect.NewError(connect.CodePermissionDenied, errors.New("you are not a member of this organization")))
 
 		h := NewHandler(mockService, mockRepository)
@@ -251,13 +435,16 @@ func TestHandler_GetRepositories(t *testing.T) {
 
 		mockService.EXPECT().
 			GetRepositories(gomock.Any(), (*string)(nil), 1, 10).
-			Return(&registryv1.GetRepositoriesResponse{
-				Repositories: []*registryv1.Repository{
-					{Id: "repo-1", Name: "first-repo"},
-					{Id: "repo-2", Name: "second-repo"},
+			Return(&RepositoryList{
+				GetRepositoriesResponse: &registryv1.GetRepositoriesResponse{
+					Repositories: []*registryv1.Repository{
+						{Id: "repo-1", Name: "first-repo"},
+						{Id: "repo-2", Name: "second-repo"},
+					},
+					NextPage:  0,
+					TotalPage: 1,
 				},
-				NextPage:  0,
-				TotalPage: 1,
+				ArchivedIds: []string{"repo-2"},
 			}, nil)
 
 		h := NewHandler(mockService, mockRepository)
@@ -280,6 +467,7 @@ func TestHandler_GetRepositories(t *testing.T) {
 		assert.Equal(t, "first-repo", resp.Msg.GetRepositories()[0].GetName())
 		assert.Equal(t, "repo-2", resp.Msg.GetRepositories()[1].GetId())
 		assert.Equal(t, "second-repo", resp.Msg.GetRepositories()[1].GetName())
+		assert.Equal(t, []string{"repo-2"}, resp.Header().Values(ArchivedRepositoryHeader))
 	})
 
 	t.Run("success with empty repositories", func(t *testing.T) {
@@ -289,10 +477,12 @@ func TestHandler_GetRepositories(t *testing.T) {
 
 		mockService.EXPECT().
 			GetRepositories(gomock.Any(), (*string)(nil), 1, 10).
-			Return(&registryv1.GetRepositoriesResponse{
-				Repositories: []*registryv1.Repository{},
-				NextPage:     0,
-				TotalPage:    1,
+			Return(&RepositoryList{
+				GetRepositoriesResponse: &registryv1.GetRepositoriesResponse{
+					Repositories: []*registryv1.Repository{},
+					NextPage:     0,
+					TotalPage:    1,
+				},
 			}, nil)
 
 		h := NewHandler(mockService, mockRepository)
@@ -311,6 +501,7 @@ func TestHandler_GetRepositories(t *testing.T) {
 		resp, err := client.GetRepositories(context.Background(), connect.NewRequest(&registryv1.GetRepositoriesRequest{}))
 		require.NoError(t, err)
 		assert.Empty(t, resp.Msg.GetRepositories())
+		assert.Empty(t, resp.Header().Values(ArchivedRepositoryHeader))
 	})
 
 	t.Run("repository error", func(t *testing.T) {
@@ -378,6 +569,160 @@ func TestHandler_UpdateRepository(t *testing.T) {
 		assert.NotNil(t, resp)
 	})
 
+	t.Run("toggles push lint from header", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		mockService := NewMockService(ctrl)
+		mockRepository := NewMockRepository(ctrl)
+
+		mockService.EXPECT().
+			UpdateRepository(gomock.Any(), gomock.Any()).
+			Return(nil)
+		mockService.EXPECT().
+			SetPushLint(gomock.Any(), "test-repo-id", true).
+			Return(nil)
+
+		h := NewHandler(mockService, mockRepository)
+		mux := http.NewServeMux()
+		path, handler := h.RegisterRoutes()
+		mux.Handle(path, handler)
+
+		server := httptest.NewServer(mux)
+		defer server.Close()
+
+		client := registryv1connect.NewRegistryServiceClient(
+			http.DefaultClient,
+			server.URL,
+		)
+
+		req := connect.NewRequest(&registryv1.UpdateRepositoryRequest{
+			Id:         "test-repo-id",
+			Name:       "test-repo",
+			Visibility: shared.Visibility_VISIBILITY_PRIVATE,
+		})
+		req.Header().Set(PushLintHeader, "true")
+
+		_, err := client.UpdateRepository(context.Background(), req)
+		assert.NoError(t, err)
+	})
+
+	t.Run("toggles archived from header", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		mockService := NewMockService(ctrl)
+		mockRepository := NewMockRepository(ctrl)
+
+		mockService.EXPECT().
+			UpdateRepository(gomock.Any(), gomock.Any()).
+			Return(nil)
+		mockService.EXPECT().
+			SetRepositoryArchived(gomock.Any(), "test-repo-id", false).
+			Return(nil)
+
+		h := NewHandler(mockService, mockRepository)
+		mux := http.NewServeMux()
+		path, handler := h.RegisterRoutes()
+		mux.Handle(path, handler)
+
+		server := httptest.NewServer(mux)
+		defer server.Close()
+
+		client := registryv1connect.NewRegistryServiceClient(
+			http.DefaultClient,
+			server.URL,
+		)
+
+		req := connect.NewRequest(&registryv1.UpdateRepositoryRequest{
+			Id:         "test-repo-id",
+			Name:       "test-repo",
+			Visibility: shared.Visibility_VISIBILITY_PRIVATE,
+		})
+		req.Header().Set(ArchivedHeader, "false")
+
+		_, err := client.UpdateRepository(context.Background(), req)
+		assert.NoError(t, err)
+	})
+
+	t.Run("archiving is refused for non-owners", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		mockService := NewMockService(ctrl)
+		mockRepository := NewMockRepository(ctrl)
+
+		mockService.EXPECT().
+			UpdateRepository(gomock.Any(), gomock.Any()).
+			Return(nil)
+		mockService.EXPECT().
+			SetRepositoryArchived(gomock.Any(), "test-repo-id", true).
+			Return(connect.NewError(connect.CodePermissionDenied, errors.New("only owners can archive")))
+
+		h := NewHandler(mockService, mockRepository)
+		mux := http.NewServeMux()
+		path, handler := h.RegisterRoutes()
+		mux.Handle(path, handler)
+
+		server := httptest.NewServer(mux)
+		defer server.Close()
+
+		client := registryv1connect.NewRegistryServiceClient(
+			http.DefaultClient,
+			server.URL,
+		)
+
+		req := connect.NewRequest(&registryv1.UpdateRepositoryRequest{Id: "test-repo-id"})
+		req.Header().Set(ArchivedHeader, "true")
+
+		_, err := client.UpdateRepository(context.Background(), req)
+		assert.Equal(t, connect.CodePermissionDenied, connect.CodeOf(err))
+	})
+
+	t.Run("invalid archived header", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		mockService := NewMockService(ctrl)
+		mockRepository := NewMockRepository(ctrl)
+
+		h := NewHandler(mockService, mockRepository)
+		mux := http.NewServeMux()
+		path, handler := h.RegisterRoutes()
+		mux.Handle(path, handler)
+
+		server := httptest.NewServer(mux)
+		defer server.Close()
+
+		client := registryv1connect.NewRegistryServiceClient(
+			http.DefaultClient,
+			server.URL,
+		)
+
+		req := connect.NewRequest(&registryv1.UpdateRepositoryRequest{Id: "test-repo-id"})
+		req.Header().Set(ArchivedHeader, "maybe")
+
+		_, err := client.UpdateRepository(context.Background(), req)
+		assert.Equal(t, connect.CodeInvalidArgument, connect.CodeOf(err))
+	})
+
+	t.Run("invalid push lint header", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		mockService := NewMockService(ctrl)
+		mockRepository := NewMockRepository(ctrl)
+
+		h := NewHandler(mockService, mockRepository)
+		mux := http.NewServeMux()
+		path, handler := h.RegisterRoutes()
+		mux.Handle(path, handler)
+
+		server := httptest.NewServer(mux)
+		defer server.Close()
+
+		client := registryv1connect.NewRegistryServiceClient(
+			http.DefaultClient,
+			server.URL,
+		)
+
+		req := connect.NewRequest(&registryv1.UpdateRepositoryRequest{Id: "test-repo-id"})
+		req.Header().Set(PushLintHeader, "sometimes")
+
+		_, err := client.UpdateRepository(context.Background(), req)
+		assert.Equal(t, connect.CodeInvalidArgument, connect.CodeOf(err))
+	})
+
 	t.Run("service error", func(t *testing.T) {
 		ctrl := gomock.NewController(t)
 		mockService := NewMockService(ctrl)
@@ -541,10 +886,6 @@ func TestGitHttpHandler_ServeHTTP(t *testing.T) {
 		mockService := NewMockService(ctrl)
 		mockUserRepo := user.NewMockRepository(ctrl)
 
-		mockUserRepo.EXPECT().
-			GetUserByApiKey(gomock.Any(), "valid-key").
-			Return(&user.UserDTO{Id: "user-123"}, nil)
-
 		h := NewGitHttpHandler(mockService, mockUserRepo, DefaultReposPath)
 
 		req := httptest.NewRequest(http.MethodGet, "/git/", nil)
@@ -560,6 +901,10 @@ func TestGitHttpHandler_ServeHTTP(t *testing.T) {
 		ctrl := gomock.NewController(t)
 		mockService := NewMockService(ctrl)
 
+		mockService.EXPECT().
+			IsPublicRepository(gomock.Any(), "./repos/repo-uuid").
+			Return(false, nil)
+
 		h := NewGitHttpHandler(mockService, nil, DefaultReposPath)
 
 		req := httptest.NewRequest(http.MethodGet, "/git/repo-uuid/info/refs?service=git-upload-pack", nil)
@@ -627,6 +972,9 @@ func TestGitHttpHandler_ServeHTTP(t *testing.T) {
 		mockService.EXPECT().
 			ValidateSshAccess(gomock.Any(), "user-123", "./repos/repo-uuid", SshOperationRead).
 			Return(true, nil)
+		mockService.EXPECT().
+			AcquireCloneSlot("user-123").
+			Return(func() {}, nil)
 
 		h := NewGitHttpHandler(mockService, mockUserRepo, DefaultReposPath)
 
@@ -670,6 +1018,9 @@ func TestGitHttpHandler_ServeHTTP(t *testing.T) {
 		mockService.EXPECT().
 			ValidateSshAccess(gomock.Any(), "user-123", repoPath, SshOperationRead).
 			Return(true, nil)
+		mockService.EXPECT().
+			AcquireCloneSlot("user-123").
+			Return(func() {}, nil)
 
 		h := NewGitHttpHandler(mockService, mockUserRepo, tempDir)
 
@@ -715,6 +1066,9 @@ func TestGitHttpHandler_ServeHTTP(t *testing.T) {
 		mockService.EXPECT().
 			ValidateSshAccess(gomock.Any(), "user-123", repoPath, SshOperationRead).
 			Return(true, nil)
+		mockService.EXPECT().
+			AcquireCloneSlot("user-123").
+			Return(func() {}, nil)
 
 		h := NewGitHttpHandler(mockService, mockUserRepo, tempDir)
 
@@ -729,35 +1083,204 @@ func TestGitHttpHandler_ServeHTTP(t *testing.T) {
 	})
 }
 
-func TestHandler_GetCommits(t *testing.T) {
-	t.Run("success", func(t *testing.T) {
+func TestGitHttpHandler_AnonymousRead(t *testing.T) {
+	t.Run("anonymous clone of public repository succeeds", func(t *testing.T) {
+		if _, err := exec.LookPath("git"); err != nil {
+			t.Skip("git not installed")
+		}
+
 		ctrl := gomock.NewController(t)
 		mockService := NewMockService(ctrl)
-		mockRepository := NewMockRepository(ctrl)
 
-		expectedCommits := &registryv1.GetCommitsResponse{
-			Commits: []*registryv1.Commit{
-				{
-					Id:      "abc123",
-					Message: "Initial commit",
-					User: &registryv1.Commit_User{
-						Id:       "user@example.com",
-						Username: "Test User",
-					},
-				},
-			},
-			NextPage:  int32(0),
-			TotalPage: int32(1),
-		}
+		tempDir := t.TempDir()
+		repoPath := filepath.Join(tempDir, "public-repo")
+		require.NoError(t, exec.Command("git", "init", "--bare", repoPath).Run())
 
 		mockService.EXPECT().
-			GetCommits(gomock.Any(), gomock.Any()).
-			DoAndReturn(func(_ context.Context, req *registryv1.GetCommitsRequest) (*registryv1.GetCommitsResponse, error) {
-				require.Equal(t, "test-repo-id", req.GetId())
-				return expectedCommits, nil
-			})
+			IsPublicRepository(gomock.Any(), repoPath).
+			Return(true, nil)
 
-		h := NewHandler(mockService, mockRepository)
+		h := NewGitHttpHandler(mockService, nil, tempDir)
+
+		req := httptest.NewRequest(http.MethodGet, "/git/public-repo/info/refs?service=git-upload-pack", nil)
+		w := httptest.NewRecorder()
+
+		h.ServeHTTP(w, req)
+
+		require.Equal(t, http.StatusOK, w.Code)
+		assert.Contains(t, w.Body.String(), "# service=git-upload-pack")
+	})
+
+	t.Run("anonymous clone of private repository returns 401", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		mockService := NewMockService(ctrl)
+
+		mockService.EXPECT().
+			IsPublicRepository(gomock.Any(), "./repos/private-repo").
+			Return(false, nil)
+
+		h := NewGitHttpHandler(mockService, nil, DefaultReposPath)
+
+		req := httptest.NewRequest(http.MethodGet, "/git/private-repo/info/refs?service=git-upload-pack", nil)
+		w := httptest.NewRecorder()
+
+		h.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusUnauthorized, w.Code)
+		assert.Contains(t, w.Header().Get("WWW-Authenticate"), "Basic")
+	})
+
+	t.Run("anonymous request for unknown repository returns 401", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		mockService := NewMockService(ctrl)
+
+		mockService.EXPECT().
+			IsPublicRepository(gomock.Any(), "./repos/missing-repo").
+			Return(false, connect.NewError(connect.CodeNotFound, errors.New("repository not found")))
+
+		h := NewGitHttpHandler(mockService, nil, DefaultReposPath)
+
+		req := httptest.NewRequest(http.MethodGet, "/git/missing-repo/info/refs?service=git-upload-pack", nil)
+		w := httptest.NewRecorder()
+
+		h.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusUnauthorized, w.Code)
+	})
+
+	t.Run("anonymous push to public repository returns 401", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		mockService := NewMockService(ctrl)
+
+		h := NewGitHttpHandler(mockService, nil, DefaultReposPath)
+
+		req := httptest.NewRequest(http.MethodGet, "/git/public-repo/info/refs?service=git-receive-pack", nil)
+		w := httptest.NewRecorder()
+
+		h.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusUnauthorized, w.Code)
+	})
+}
+
+func TestGitHttpHandler_DeployToken(t *testing.T) {
+	const token = DeployTokenPrefix + "abc"
+
+	serve := func(h *GitHttpHandler, target string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, target, nil)
+		req.SetBasicAuth("deploy", token)
+		w := httptest.NewRecorder()
+		h.ServeHTTP(w, req)
+		return w
+	}
+
+	t.Run("is checked instead of the user API key", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		mockService := NewMockService(ctrl)
+
+		mockService.EXPECT().
+			ValidateDeployTokenAccess(gomock.Any(), token, "./repos/repo-uuid", SshOperationWrite).
+			Return("token-1", false, nil)
+
+		h := NewGitHttpHandler(mockService, user.NewMockRepository(ctrl), DefaultReposPath)
+		w := serve(h, "/git/repo-uuid/info/refs?service=git-receive-pack")
+
+		assert.Equal(t, http.StatusForbidden, w.Code)
+	})
+
+	t.Run("unknown token asks for credentials", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		mockService := NewMockService(ctrl)
+
+		mockService.EXPECT().
+			ValidateDeployTokenAccess(gomock.Any(), token, "./repos/repo-uuid", SshOperationRead).
+			Return("", false, connect.NewError(connect.CodeNotFound, errors.New("deploy token not found")))
+
+		h := NewGitHttpHandler(mockService, nil, DefaultReposPath)
+		w := serve(h, "/git/repo-uuid/info/refs?service=git-upload-pack")
+
+		assert.Equal(t, http.StatusUnauthorized, w.Code)
+	})
+
+	t.Run("concurrent clones with one token share its limit", func(t *testing.T) {
+		mockRepo := NewMockRepository(gomock.NewController(t))
+		mockRepo.EXPECT().
+			GetDeployToken(gomock.Any(), token).
+			Return(&DeployTokenDTO{Id: "token-1", RepositoryId: "repo-uuid", Token: token, ReadOnly: true}, nil).
+			AnyTimes()
+
+		svc := &service{
+			repository: mockRepo,
+			cfg:        &config.Config{Repository: config.RepositoryConfig{MaxConcurrentClonesPerUser: 1}},
+		}
+
+		// The first clone holds its slot until the test closes the pipe
+		// feeding the stand-in git command; later ones finish at once.
+		blocker, unblock, err := os.Pipe()
+		require.NoError(t, err)
+		defer func() {
+			_ = blocker.Close()
+		}()
+		started := make(chan struct{})
+		var once sync.Once
+
+		h := NewGitHttpHandler(svc, nil, DefaultReposPath)
+		h.command = func(name string, arg ...string) *exec.Cmd {
+			cmd := exec.Command("true")
+			once.Do(func() {
+				cmd = exec.Command("cat")
+				cmd.Stdin = blocker
+				close(started)
+			})
+			return cmd
+		}
+
+		first := make(chan *httptest.ResponseRecorder)
+		go func() {
+			first <- serve(h, "/git/repo-uuid/info/refs?service=git-upload-pack")
+		}()
+		<-started
+
+		w := serve(h, "/git/repo-uuid/info/refs?service=git-upload-pack")
+		assert.Equal(t, http.StatusTooManyRequests, w.Code)
+
+		require.NoError(t, unblock.Close())
+		assert.Equal(t, http.StatusOK, (<-first).Code)
+
+		w = serve(h, "/git/repo-uuid/info/refs?service=git-upload-pack")
+		assert.Equal(t, http.StatusOK, w.Code)
+	})
+}
+
+func TestHandler_GetCommits(t *testing.T) {
+	t.Run("success", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		mockService := NewMockService(ctrl)
+		mockRepository := NewMockRepository(ctrl)
+
+		expectedCommits := &registryv1.GetCommitsResponse{
+			Commits: []*registryv1.Commit{
+				{
+					Id:      "abc123",
+					Message: "Initial commit",
+					User: &registryv1.Commit_User{
+						Id:       "user@example.com",
+						Username: "Test User",
+					},
+				},
+			},
+			NextPage:  int32(0),
+			TotalPage: int32(1),
+		}
+
+		mockService.EXPECT().
+			GetCommits(gomock.Any(), gomock.Any(), "release/v2").
+			DoAndReturn(func(_ context.Context, req *registryv1.GetCommitsRequest, _ string) (*CommitLog, error) {
+				require.Equal(t, "test-repo-id", req.GetId())
+				return &CommitLog{GetCommitsResponse: expectedCommits}, nil
+			})
+
+		h := NewHandler(mockService, mockRepository)
 		mux := http.NewServeMux()
 		path, handler := h.RegisterRoutes()
 		mux.Handle(path, handler)
@@ -770,9 +1293,11 @@ func TestHandler_GetCommits(t *testing.T) {
 			server.URL,
 		)
 
-		resp, err := client.GetCommits(context.Background(), connect.NewRequest(&registryv1.GetCommitsRequest{
+		req := connect.NewRequest(&registryv1.GetCommitsRequest{
 			Id: "test-repo-id",
-		}))
+		})
+		req.Header().Set(RefHeader, "release/v2")
+		resp, err := client.GetCommits(context.Background(), req)
 		require.NoError(t, err)
 		assert.NotNil(t, resp)
 		assert.Len(t, resp.Msg.GetCommits(), 1)
@@ -788,7 +1313,7 @@ func TestHandler_GetCommits(t *testing.T) {
 		mockRepository := NewMockRepository(ctrl)
 
 		mockService.EXPECT().
-			GetCommits(gomock.Any(), gomock.Any()).
+			GetCommits(gomock.Any(), gomock.Any(), gomock.Any()).
 			Return(nil, ErrRepositoryNotFound)
 
 		h := NewHandler(mockService, mockRepository)
@@ -844,11 +1369,11 @@ func TestHandler_GetFileTree(t *testing.T) {
 		}
 
 		mockService.EXPECT().
-			GetFileTree(gomock.Any(), gomock.Any()).
-			DoAndReturn(func(_ context.Context, req *registryv1.GetFileTreeRequest) (*registryv1.GetFileTreeResponse, error) {
+			GetFileTree(gomock.Any(), gomock.Any(), "", FileTreePage{}).
+			DoAndReturn(func(_ context.Context, req *registryv1.GetFileTreeRequest, _ string, _ FileTreePage) (*FileTree, error) {
 				assert.Equal(t, "test-repo-id", req.GetId())
 				assert.False(t, req.HasPath())
-				return expectedFileTree, nil
+				return &FileTree{GetFileTreeResponse: expectedFileTree, TotalCount: len(expectedFileTree.Nodes)}, nil
 			})
 
 		h := NewHandler(mockService, mockRepository)
@@ -875,6 +1400,7 @@ func TestHandler_GetFileTree(t *testing.T) {
 		assert.Equal(t, "src", resp.Msg.GetNodes()[1].GetName())
 		assert.Equal(t, registryv1.NodeType_NODE_TYPE_DIRECTORY, resp.Msg.GetNodes()[1].GetType())
 		assert.Len(t, resp.Msg.GetNodes()[1].GetChildren(), 1)
+		assert.Equal(t, "2", resp.Header().Get(FileTreeTotalCountHeader))
 	})
 
 	t.Run("success - subdirectory", func(t *testing.T) {
@@ -893,12 +1419,12 @@ func TestHandler_GetFileTree(t *testing.T) {
 		}
 
 		mockService.EXPECT().
-			GetFileTree(gomock.Any(), gomock.Any()).
-			DoAndReturn(func(_ context.Context, req *registryv1.GetFileTreeRequest) (*registryv1.GetFileTreeResponse, error) {
+			GetFileTree(gomock.Any(), gomock.Any(), "", FileTreePage{}).
+			DoAndReturn(func(_ context.Context, req *registryv1.GetFileTreeRequest, _ string, _ FileTreePage) (*FileTree, error) {
 				assert.Equal(t, "test-repo-id", req.GetId())
 				assert.True(t, req.HasPath())
 				assert.Equal(t, "src", req.GetPath())
-				return expectedFileTree, nil
+				return &FileTree{GetFileTreeResponse: expectedFileTree, TotalCount: len(expectedFileTree.Nodes)}, nil
 			})
 
 		h := NewHandler(mockService, mockRepository)
@@ -931,7 +1457,7 @@ func TestHandler_GetFileTree(t *testing.T) {
 		mockRepository := NewMockRepository(ctrl)
 
 		mockService.EXPECT().
-			GetFileTree(gomock.Any(), gomock.Any()).
+			GetFileTree(gomock.Any(), gomock.Any(), "", gomock.Any()).
 			Return(nil, ErrRepositoryNotFound)
 
 		h := NewHandler(mockService, mockRepository)
@@ -956,6 +1482,105 @@ func TestHandler_GetFileTree(t *testing.T) {
 		require.True(t, errors.As(err, &connectErr))
 		assert.Equal(t, connect.CodeNotFound, connectErr.Code())
 	})
+
+	t.Run("success - paginated", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		mockService := NewMockService(ctrl)
+		mockRepository := NewMockRepository(ctrl)
+
+		mockService.EXPECT().
+			GetFileTree(gomock.Any(), gomock.Any(), "", FileTreePage{Offset: 20, Limit: 10}).
+			Return(&FileTree{
+				GetFileTreeResponse: &registryv1.GetFileTreeResponse{
+					Nodes: []*registryv1.FileTreeNode{
+						{Name: "file-20.proto", Path: "file-20.proto", Type: registryv1.NodeType_NODE_TYPE_FILE},
+					},
+				},
+				TotalCount: 250,
+			}, nil)
+
+		h := NewHandler(mockService, mockRepository)
+		mux := http.NewServeMux()
+		path, handler := h.RegisterRoutes()
+		mux.Handle(path, handler)
+
+		server := httptest.NewServer(mux)
+		defer server.Close()
+
+		client := registryv1connect.NewRegistryServiceClient(
+			http.DefaultClient,
+			server.URL,
+		)
+
+		req := connect.NewRequest(&registryv1.GetFileTreeRequest{Id: "test-repo-id"})
+		req.Header().Set(FileTreeOffsetHeader, "20")
+		req.Header().Set(FileTreeLimitHeader, "10")
+		resp, err := client.GetFileTree(context.Background(), req)
+		require.NoError(t, err)
+		assert.Len(t, resp.Msg.GetNodes(), 1)
+		assert.Equal(t, "250", resp.Header().Get(FileTreeTotalCountHeader))
+	})
+
+	t.Run("success - reports truncated directories", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		mockService := NewMockService(ctrl)
+		mockRepository := NewMockRepository(ctrl)
+
+		mockService.EXPECT().
+			GetFileTree(gomock.Any(), gomock.Any(), "", FileTreePage{}).
+			Return(&FileTree{
+				GetFileTreeResponse: &registryv1.GetFileTreeResponse{
+					Nodes: []*registryv1.FileTreeNode{
+						{Name: "a", Path: "a", Type: registryv1.NodeType_NODE_TYPE_DIRECTORY},
+						{Name: "b", Path: "b", Type: registryv1.NodeType_NODE_TYPE_DIRECTORY},
+					},
+				},
+				TotalCount:     2,
+				TruncatedPaths: []string{"a", "b"},
+			}, nil)
+
+		h := NewHandler(mockService, mockRepository)
+		mux := http.NewServeMux()
+		path, handler := h.RegisterRoutes()
+		mux.Handle(path, handler)
+
+		server := httptest.NewServer(mux)
+		defer server.Close()
+
+		client := registryv1connect.NewRegistryServiceClient(
+			http.DefaultClient,
+			server.URL,
+		)
+
+		resp, err := client.GetFileTree(context.Background(), connect.NewRequest(&registryv1.GetFileTreeRequest{Id: "test-repo-id"}))
+		require.NoError(t, err)
+		assert.Equal(t, []string{"a", "b"}, resp.Header().Values(FileTreeTruncatedHeader))
+	})
+
+	t.Run("invalid pagination header", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		mockService := NewMockService(ctrl)
+		mockRepository := NewMockRepository(ctrl)
+
+		h := NewHandler(mockService, mockRepository)
+		mux := http.NewServeMux()
+		path, handler := h.RegisterRoutes()
+		mux.Handle(path, handler)
+
+		server := httptest.NewServer(mux)
+		defer server.Close()
+
+		client := registryv1connect.NewRegistryServiceClient(
+			http.DefaultClient,
+			server.URL,
+		)
+
+		req := connect.NewRequest(&registryv1.GetFileTreeRequest{Id: "test-repo-id"})
+		req.Header().Set(FileTreeLimitHeader, "-5")
+		_, err := client.GetFileTree(context.Background(), req)
+		require.Error(t, err)
+		assert.Equal(t, connect.CodeInvalidArgument, connect.CodeOf(err))
+	})
 }
 
 func TestHandler_GetFilePreview(t *testing.T) {
@@ -969,11 +1594,11 @@ func TestHandler_GetFilePreview(t *testing.T) {
 		}
 
 		mockService.EXPECT().
-			GetFilePreview(gomock.Any(), gomock.Any()).
-			DoAndReturn(func(_ context.Context, req *registryv1.GetFilePreviewRequest) (*registryv1.GetFilePreviewResponse, error) {
+			GetFilePreview(gomock.Any(), gomock.Any(), gomock.Any()).
+			DoAndReturn(func(_ context.Context, req *registryv1.GetFilePreviewRequest, _ string) (*FilePreview, error) {
 				assert.Equal(t, "test-repo-id", req.GetId())
 				assert.Equal(t, "main.go", req.GetPath())
-				return expectedFilePreview, nil
+				return &FilePreview{GetFilePreviewResponse: expectedFilePreview}, nil
 			})
 
 		h := NewHandler(mockService, mockRepository)
@@ -1008,11 +1633,11 @@ func TestHandler_GetFilePreview(t *testing.T) {
 		}
 
 		mockService.EXPECT().
-			GetFilePreview(gomock.Any(), gomock.Any()).
-			DoAndReturn(func(_ context.Context, req *registryv1.GetFilePreviewRequest) (*registryv1.GetFilePreviewResponse, error) {
+			GetFilePreview(gomock.Any(), gomock.Any(), gomock.Any()).
+			DoAndReturn(func(_ context.Context, req *registryv1.GetFilePreviewRequest, _ string) (*FilePreview, error) {
 				assert.Equal(t, "test-repo-id", req.GetId())
 				assert.Equal(t, "docs/README.md", req.GetPath())
-				return expectedFilePreview, nil
+				return &FilePreview{GetFilePreviewResponse: expectedFilePreview}, nil
 			})
 
 		h := NewHandler(mockService, mockRepository)
@@ -1047,11 +1672,11 @@ func TestHandler_GetFilePreview(t *testing.T) {
 		}
 
 		mockService.EXPECT().
-			GetFilePreview(gomock.Any(), gomock.Any()).
-			DoAndReturn(func(_ context.Context, req *registryv1.GetFilePreviewRequest) (*registryv1.GetFilePreviewResponse, error) {
+			GetFilePreview(gomock.Any(), gomock.Any(), gomock.Any()).
+			DoAndReturn(func(_ context.Context, req *registryv1.GetFilePreviewRequest, _ string) (*FilePreview, error) {
 				assert.Equal(t, "test-repo-id", req.GetId())
 				assert.Equal(t, "empty.txt", req.GetPath())
-				return expectedFilePreview, nil
+				return &FilePreview{GetFilePreviewResponse: expectedFilePreview}, nil
 			})
 
 		h := NewHandler(mockService, mockRepository)
@@ -1082,7 +1707,7 @@ func TestHandler_GetFilePreview(t *testing.T) {
 		mockRepository := NewMockRepository(ctrl)
 
 		mockService.EXPECT().
-			GetFilePreview(gomock.Any(), gomock.Any()).
+			GetFilePreview(gomock.Any(), gomock.Any(), gomock.Any()).
 			Return(nil, ErrRepositoryNotFound)
 
 		h := NewHandler(mockService, mockRepository)
@@ -1115,7 +1740,7 @@ func TestHandler_GetFilePreview(t *testing.T) {
 		mockRepository := NewMockRepository(ctrl)
 
 		mockService.EXPECT().
-			GetFilePreview(gomock.Any(), gomock.Any()).
+			GetFilePreview(gomock.Any(), gomock.Any(), gomock.Any()).
 			Return(nil, connect.NewError(connect.CodeNotFound, errors.New("file not found")))
 
 		h := NewHandler(mockService, mockRepository)
@@ -1148,7 +1773,7 @@ func TestHandler_GetFilePreview(t *testing.T) {
 		mockRepository := NewMockRepository(ctrl)
 
 		mockService.EXPECT().
-			GetFilePreview(gomock.Any(), gomock.Any()).
+			GetFilePreview(gomock.Any(), gomock.Any(), gomock.Any()).
 			Return(nil, connect.NewError(connect.CodePermissionDenied, errors.New("you are not a member of this organization")))
 
 		h := NewHandler(mockService, mockRepository)
@@ -1181,7 +1806,7 @@ func TestHandler_GetFilePreview(t *testing.T) {
 		mockRepository := NewMockRepository(ctrl)
 
 		mockService.EXPECT().
-			GetFilePreview(gomock.Any(), gomock.Any()).
+			GetFilePreview(gomock.Any(), gomock.Any(), gomock.Any()).
 			Return(nil, connect.NewError(connect.CodeInternal, errors.New("git error")))
 
 		h := NewHandler(mockService, mockRepository)
@@ -1219,11 +1844,11 @@ func TestHandler_GetFilePreview(t *testing.T) {
 		}
 
 		mockService.EXPECT().
-			GetFilePreview(gomock.Any(), gomock.Any()).
-			DoAndReturn(func(_ context.Context, req *registryv1.GetFilePreviewRequest) (*registryv1.GetFilePreviewResponse, error) {
+			GetFilePreview(gomock.Any(), gomock.Any(), gomock.Any()).
+			DoAndReturn(func(_ context.Context, req *registryv1.GetFilePreviewRequest, _ string) (*FilePreview, error) {
 				assert.Equal(t, "test-repo-id", req.GetId())
 				assert.Equal(t, "special.txt", req.GetPath())
-				return expectedFilePreview, nil
+				return &FilePreview{GetFilePreviewResponse: expectedFilePreview}, nil
 			})
 
 		h := NewHandler(mockService, mockRepository)
@@ -1231,169 +1856,624 @@ func TestHandler_GetFilePreview(t *testing.T) {
 		path, handler := h.RegisterRoutes()
 		mux.Handle(path, handler)
 
-		server := httptest.NewServer(mux)
-		defer server.Close()
+		server := httptest.NewServer(mux)
+		defer server.Close()
+
+		client := registryv1connect.NewRegistryServiceClient(
+			http.DefaultClient,
+			server.URL,
+		)
+
+		resp, err := client.GetFilePreview(context.Background(), connect.NewRequest(&registryv1.GetFilePreviewRequest{
+			Id:   "test-repo-id",
+			Path: "special.txt",
+		}))
+		require.NoError(t, err)
+		assert.NotNil(t, resp)
+		assert.Equal(t, specialContent, resp.Msg.GetContent())
+	})
+
+	t.Run("sets preview metadata headers", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		mockService := NewMockService(ctrl)
+		mockRepository := NewMockRepository(ctrl)
+
+		mockService.EXPECT().
+			GetFilePreview(gomock.Any(), gomock.Any(), gomock.Any()).
+			Return(&FilePreview{
+				GetFilePreviewResponse: &registryv1.GetFilePreviewResponse{
+					MimeType: "application/octet-stream",
+					Size:     4096,
+				},
+				Truncated: true,
+				Binary:    true,
+				Language:  "protobuf",
+			}, nil)
+
+		h := NewHandler(mockService, mockRepository)
+		mux := http.NewServeMux()
+		path, handler := h.RegisterRoutes()
+		mux.Handle(path, handler)
+
+		server := httptest.NewServer(mux)
+		defer server.Close()
+
+		client := registryv1connect.NewRegistryServiceClient(
+			http.DefaultClient,
+			server.URL,
+		)
+
+		resp, err := client.GetFilePreview(context.Background(), connect.NewRequest(&registryv1.GetFilePreviewRequest{
+			Id:   "test-repo-id",
+			Path: "image.bin",
+		}))
+		require.NoError(t, err)
+		assert.Empty(t, resp.Msg.GetContent())
+		assert.Equal(t, "true", resp.Header().Get(PreviewTruncatedHeader))
+		assert.Equal(t, "true", resp.Header().Get(PreviewBinaryHeader))
+		assert.Equal(t, "protobuf", resp.Header().Get(PreviewLanguageHeader))
+	})
+}
+
+func TestHandler_UpdateSdkPreferences(t *testing.T) {
+	t.Run("success", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		mockService := NewMockService(ctrl)
+		mockRepository := NewMockRepository(ctrl)
+
+		mockService.EXPECT().
+			UpdateSdkPreferences(gomock.Any(), gomock.Any()).
+			DoAndReturn(func(_ context.Context, req *registryv1.UpdateSdkPreferencesRequest) error {
+				assert.Equal(t, "test-repo-id", req.GetId())
+				return nil
+			})
+		mockService.EXPECT().
+			AvailableSdks().
+			Return([]registryv1.SDK{registryv1.SDK_SDK_GO_PROTOBUF, registryv1.SDK_SDK_GO_CONNECTRPC})
+
+		h := NewHandler(mockService, mockRepository)
+		mux := http.NewServeMux()
+		path, handler := h.RegisterRoutes()
+		mux.Handle(path, handler)
+
+		server := httptest.NewServer(mux)
+		defer server.Close()
+
+		client := registryv1connect.NewRegistryServiceClient(
+			http.DefaultClient,
+			server.URL,
+		)
+
+		resp, err := client.UpdateSdkPreferences(context.Background(), connect.NewRequest(&registryv1.UpdateSdkPreferencesRequest{
+			Id: "test-repo-id",
+			SdkPreferences: []*registryv1.SdkPreference{
+				{
+					Sdk:    registryv1.SDK_SDK_GO_PROTOBUF,
+					Status: true,
+				},
+			},
+		}))
+		require.NoError(t, err)
+		assert.Equal(t, []string{"SDK_GO_PROTOBUF", "SDK_GO_CONNECTRPC"}, resp.Header().Values(AvailableSdkHeader))
+	})
+
+	t.Run("service error", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		mockService := NewMockService(ctrl)
+		mockRepository := NewMockRepository(ctrl)
+
+		mockService.EXPECT().
+			UpdateSdkPreferences(gomock.Any(), gomock.Any()).
+			Return(connect.NewError(connect.CodeNotFound, errors.New("repository not found")))
+
+		h := NewHandler(mockService, mockRepository)
+		mux := http.NewServeMux()
+		path, handler := h.RegisterRoutes()
+		mux.Handle(path, handler)
+
+		server := httptest.NewServer(mux)
+		defer server.Close()
+
+		client := registryv1connect.NewRegistryServiceClient(
+			http.DefaultClient,
+			server.URL,
+		)
+
+		_, err := client.UpdateSdkPreferences(context.Background(), connect.NewRequest(&registryv1.UpdateSdkPreferencesRequest{
+			Id: "nonexistent-repo-id",
+		}))
+		require.Error(t, err)
+
+		var connectErr *connect.Error
+		require.True(t, errors.As(err, &connectErr))
+		assert.Equal(t, connect.CodeNotFound, connectErr.Code())
+	})
+}
+
+func TestHandler_GetRecentCommit(t *testing.T) {
+	t.Run("success", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		mockService := NewMockService(ctrl)
+		mockRepository := NewMockRepository(ctrl)
+
+		expectedCommit := &registryv1.Commit{
+			Id:      "abc123",
+			Message: "Recent commit",
+			User: &registryv1.Commit_User{
+				Id:       "user@example.com",
+				Username: "Test User",
+			},
+		}
+
+		mockService.EXPECT().
+			GetRecentCommit(gomock.Any(), gomock.Any()).
+			DoAndReturn(func(_ context.Context, req *registryv1.GetRecentCommitRequest) (*RecentCommit, error) {
+				assert.Equal(t, "test-repo-id", req.GetRepositoryId())
+				return &RecentCommit{Commit: expectedCommit}, nil
+			})
+
+		h := NewHandler(mockService, mockRepository)
+		mux := http.NewServeMux()
+		path, handler := h.RegisterRoutes()
+		mux.Handle(path, handler)
+
+		server := httptest.NewServer(mux)
+		defer server.Close()
+
+		client := registryv1connect.NewRegistryServiceClient(
+			http.DefaultClient,
+			server.URL,
+		)
+
+		resp, err := client.GetRecentCommit(context.Background(), connect.NewRequest(&registryv1.GetRecentCommitRequest{
+			RepositoryId: "test-repo-id",
+		}))
+		require.NoError(t, err)
+		assert.NotNil(t, resp)
+		assert.Equal(t, "abc123", resp.Msg.GetId())
+		assert.Equal(t, "Recent commit", resp.Msg.GetMessage())
+	})
+
+	t.Run("service error - repository not found", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		mockService := NewMockService(ctrl)
+		mockRepository := NewMockRepository(ctrl)
+
+		mockService.EXPECT().
+			GetRecentCommit(gomock.Any(), gomock.Any()).
+			Return(nil, ErrRepositoryNotFound)
+
+		h := NewHandler(mockService, mockRepository)
+		mux := http.NewServeMux()
+		path, handler := h.RegisterRoutes()
+		mux.Handle(path, handler)
+
+		server := httptest.NewServer(mux)
+		defer server.Close()
+
+		client := registryv1connect.NewRegistryServiceClient(
+			http.DefaultClient,
+			server.URL,
+		)
+
+		_, err := client.GetRecentCommit(context.Background(), connect.NewRequest(&registryv1.GetRecentCommitRequest{
+			RepositoryId: "non-existent-repo",
+		}))
+		require.Error(t, err)
+
+		var connectErr *connect.Error
+		require.True(t, errors.As(err, &connectErr))
+		assert.Equal(t, connect.CodeNotFound, connectErr.Code())
+	})
+}
+
+func TestGitHttpHandler_ServiceAllowlist(t *testing.T) {
+	tests := []struct {
+		name   string
+		target string
+		method string
+	}{
+		{name: "unknown service on info/refs", target: "/git/repo-uuid/info/refs?service=git-upload-archive", method: http.MethodGet},
+		{name: "option injection in service", target: "/git/repo-uuid/info/refs?service=--upload-pack%3Dtouch%20pwned", method: http.MethodGet},
+		{name: "empty service on info/refs", target: "/git/repo-uuid/info/refs?service=", method: http.MethodGet},
+		{name: "missing service on info/refs", target: "/git/repo-uuid/info/refs", method: http.MethodGet},
+		{name: "service does not match rpc path", target: "/git/repo-uuid/git-upload-pack?service=git-receive-pack", method: http.MethodPost},
+		{name: "unknown service on other path", target: "/git/repo-uuid/objects/info/packs?service=sh", method: http.MethodGet},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			mockService := NewMockService(ctrl)
+			mockUserRepo := user.NewMockRepository(ctrl)
+
+			h := NewGitHttpHandler(mockService, mockUserRepo, DefaultReposPath)
+			h.command = func(name string, arg ...string) *exec.Cmd {
+				t.Errorf("unexpected subprocess %s %v", name, arg)
+				return exec.Command("false")
+			}
+
+			req := httptest.NewRequest(tt.method, tt.target, nil)
+			req.SetBasicAuth("user", "valid-key")
+			w := httptest.NewRecorder()
+
+			h.ServeHTTP(w, req)
+
+			assert.Equal(t, http.StatusBadRequest, w.Code)
+		})
+	}
+}
+
+func TestGitHttpHandler_ContentEncoding(t *testing.T) {
+	const payload = "0032want 0123456789abcdef0123456789abcdef01234567\n00000009done\n"
+
+	gzipped := func(t *testing.T, data string) []byte {
+		t.Helper()
+
+		var buf bytes.Buffer
+		gz := gzip.NewWriter(&buf)
+		_, err := gz.Write([]byte(data))
+		require.NoError(t, err)
+		require.NoError(t, gz.Close())
+
+		return buf.Bytes()
+	}
+
+	// newHandler echoes the decoded request body back through `cat` in place
+	// of the git service.
+	newHandler := func(t *testing.T, operation SshOperation) *GitHttpHandler {
+		t.Helper()
+
+		ctrl := gomock.NewController(t)
+		mockService := NewMockService(ctrl)
+		mockUserRepo := user.NewMockRepository(ctrl)
+
+		mockUserRepo.EXPECT().
+			GetUserByApiKey(gomock.Any(), "valid-key").
+			Return(&user.UserDTO{Id: "user-123"}, nil)
+		mockService.EXPECT().
+			ValidateSshAccess(gomock.Any(), "user-123", "./repos/repo-uuid", operation).
+			Return(true, nil)
+		mockService.EXPECT().
+			LockRepositoryForPush(gomock.Any(), "./repos/repo-uuid").
+			Return(func() {}, nil).
+			AnyTimes()
+		mockService.EXPECT().
+			AcquireCloneSlot("user-123").
+			Return(func() {}, nil).
+			AnyTimes()
+
+		h := NewGitHttpHandler(mockService, mockUserRepo, DefaultReposPath)
+		h.command = func(name string, arg ...string) *exec.Cmd {
+			if slices.Contains(arg, "--advertise-refs") {
+				return exec.Command("echo", "advertised-refs")
+			}
+			return exec.Command("cat")
+		}
+
+		return h
+	}
+
+	post := func(h *GitHttpHandler, service string, body []byte, encoding string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPost, "/git/repo-uuid/"+service, bytes.NewReader(body))
+		req.SetBasicAuth("user", "valid-key")
+		if encoding != "" {
+			req.Header.Set("Content-Encoding", encoding)
+		}
+		w := httptest.NewRecorder()
+		h.ServeHTTP(w, req)
+		return w
+	}
+
+	t.Run("gzip receive-pack body is decoded", func(t *testing.T) {
+		h := newHandler(t, SshOperationWrite)
+
+		w := post(h, gitReceivePack, gzipped(t, payload), "gzip")
+
+		require.Equal(t, http.StatusOK, w.Code)
+		assert.Contains(t, w.Body.String(), payload)
+	})
+
+	t.Run("plain receive-pack body still works", func(t *testing.T) {
+		h := newHandler(t, SshOperationWrite)
+
+		w := post(h, gitReceivePack, []byte(payload), "")
+
+		require.Equal(t, http.StatusOK, w.Code)
+		assert.Contains(t, w.Body.String(), payload)
+	})
+
+	t.Run("gzip upload-pack body is decoded", func(t *testing.T) {
+		h := newHandler(t, SshOperationRead)
+
+		w := post(h, gitUploadPack, gzipped(t, payload), "x-gzip")
+
+		require.Equal(t, http.StatusOK, w.Code)
+		assert.Equal(t, payload, w.Body.String())
+	})
+
+	t.Run("corrupt gzip body is rejected", func(t *testing.T) {
+		h := newHandler(t, SshOperationWrite)
+
+		w := post(h, gitReceivePack, []byte(payload), "gzip")
+
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+	})
+
+	t.Run("unsupported encoding is rejected", func(t *testing.T) {
+		h := newHandler(t, SshOperationWrite)
+
+		w := post(h, gitReceivePack, []byte(payload), "br")
+
+		assert.Equal(t, http.StatusUnsupportedMediaType, w.Code)
+	})
+
+	t.Run("info/refs is compressed when the client accepts gzip", func(t *testing.T) {
+		h := newHandler(t, SshOperationRead)
+
+		req := httptest.NewRequest(http.MethodGet, "/git/repo-uuid/info/refs?service=git-upload-pack", nil)
+		req.SetBasicAuth("user", "valid-key")
+		req.Header.Set("Accept-Encoding", "deflate, gzip;q=0.8")
+		w := httptest.NewRecorder()
+		h.ServeHTTP(w, req)
+
+		require.Equal(t, http.StatusOK, w.Code)
+		require.Equal(t, "gzip", w.Header().Get("Content-Encoding"))
+
+		gz, err := gzip.NewReader(w.Body)
+		require.NoError(t, err)
+		advertisement, err := io.ReadAll(gz)
+		require.NoError(t, err)
+		assert.Contains(t, string(advertisement), "# service=git-upload-pack")
+		assert.Contains(t, string(advertisement), "advertised-refs")
+	})
+
+	t.Run("info/refs is not compressed when gzip is refused", func(t *testing.T) {
+		h := newHandler(t, SshOperationRead)
+
+		req := httptest.NewRequest(http.MethodGet, "/git/repo-uuid/info/refs?service=git-upload-pack", nil)
+		req.SetBasicAuth("user", "valid-key")
+		req.Header.Set("Accept-Encoding", "gzip;q=0")
+		w := httptest.NewRecorder()
+		h.ServeHTTP(w, req)
+
+		require.Equal(t, http.StatusOK, w.Code)
+		assert.Empty(t, w.Header().Get("Content-Encoding"))
+		assert.Contains(t, w.Body.String(), "advertised-refs")
+	})
+}
+
+func TestGitHttpHandler_PushLock(t *testing.T) {
+	newHandler := func(t *testing.T) (*GitHttpHandler, *MockService) {
+		t.Helper()
+
+		ctrl := gomock.NewController(t)
+		mockService := NewMockService(ctrl)
+		mockUserRepo := user.NewMockRepository(ctrl)
+
+		mockUserRepo.EXPECT().
+			GetUserByApiKey(gomock.Any(), "valid-key").
+			Return(&user.UserDTO{Id: "user-123"}, nil)
+		mockService.EXPECT().
+			ValidateSshAccess(gomock.Any(), "user-123", "./repos/repo-uuid", SshOperationWrite).
+			Return(true, nil)
+
+		return NewGitHttpHandler(mockService, mockUserRepo, DefaultReposPath), mockService
+	}
+
+	push := func(h *GitHttpHandler) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPost, "/git/repo-uuid/"+gitReceivePack, bytes.NewReader([]byte("0000")))
+		req.SetBasicAuth("user", "valid-key")
+		w := httptest.NewRecorder()
+		h.ServeHTTP(w, req)
+		return w
+	}
+
+	t.Run("receive-pack runs while holding the lock", func(t *testing.T) {
+		h, mockService := newHandler(t)
+
+		var locked, ranLocked bool
+		mockService.EXPECT().
+			LockRepositoryForPush(gomock.Any(), "./repos/repo-uuid").
+			DoAndReturn(func(context.Context, string) (func(), error) {
+				locked = true
+				return func() { locked = false }, nil
+			})
+		h.command = func(name string, arg ...string) *exec.Cmd {
+			ranLocked = locked
+			return exec.Command("true")
+		}
+
+		w := push(h)
+
+		require.Equal(t, http.StatusOK, w.Code)
+		assert.True(t, ranLocked)
+		assert.False(t, locked)
+	})
+
+	t.Run("push in progress is rejected", func(t *testing.T) {
+		h, mockService := newHandler(t)
 
-		client := registryv1connect.NewRegistryServiceClient(
-			http.DefaultClient,
-			server.URL,
-		)
+		mockService.EXPECT().
+			LockRepositoryForPush(gomock.Any(), "./repos/repo-uuid").
+			Return(nil, ErrPushInProgress)
+		h.command = func(name string, arg ...string) *exec.Cmd {
+			t.Fatal("receive-pack must not run without the lock")
+			return nil
+		}
 
-		resp, err := client.GetFilePreview(context.Background(), connect.NewRequest(&registryv1.GetFilePreviewRequest{
-			Id:   "test-repo-id",
-			Path: "special.txt",
-		}))
-		require.NoError(t, err)
-		assert.NotNil(t, resp)
-		assert.Equal(t, specialContent, resp.Msg.GetContent())
+		w := push(h)
+
+		assert.Equal(t, http.StatusConflict, w.Code)
+		assert.Contains(t, w.Body.String(), "another push to this repository is in progress")
 	})
 }
 
-func TestHandler_UpdateSdkPreferences(t *testing.T) {
-	t.Run("success", func(t *testing.T) {
+func TestGitHttpHandler_CloneLimit(t *testing.T) {
+	newHandler := func(t *testing.T) (*GitHttpHandler, *MockService) {
+		t.Helper()
+
 		ctrl := gomock.NewController(t)
 		mockService := NewMockService(ctrl)
-		mockRepository := NewMockRepository(ctrl)
+		mockUserRepo := user.NewMockRepository(ctrl)
 
+		mockUserRepo.EXPECT().
+			GetUserByApiKey(gomock.Any(), "valid-key").
+			Return(&user.UserDTO{Id: "user-123"}, nil)
 		mockService.EXPECT().
-			UpdateSdkPreferences(gomock.Any(), gomock.Any()).
-			DoAndReturn(func(_ context.Context, req *registryv1.UpdateSdkPreferencesRequest) error {
-				assert.Equal(t, "test-repo-id", req.GetId())
-				return nil
-			})
+			ValidateSshAccess(gomock.Any(), "user-123", "./repos/repo-uuid", SshOperationRead).
+			Return(true, nil)
 
-		h := NewHandler(mockService, mockRepository)
-		mux := http.NewServeMux()
-		path, handler := h.RegisterRoutes()
-		mux.Handle(path, handler)
+		return NewGitHttpHandler(mockService, mockUserRepo, DefaultReposPath), mockService
+	}
 
-		server := httptest.NewServer(mux)
-		defer server.Close()
+	fetch := func(h *GitHttpHandler) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPost, "/git/repo-uuid/"+gitUploadPack, bytes.NewReader([]byte("0000")))
+		req.SetBasicAuth("user", "valid-key")
+		w := httptest.NewRecorder()
+		h.ServeHTTP(w, req)
+		return w
+	}
 
-		client := registryv1connect.NewRegistryServiceClient(
-			http.DefaultClient,
-			server.URL,
-		)
+	t.Run("upload-pack runs while holding a slot", func(t *testing.T) {
+		h, mockService := newHandler(t)
 
-		_, err := client.UpdateSdkPreferences(context.Background(), connect.NewRequest(&registryv1.UpdateSdkPreferencesRequest{
-			Id: "test-repo-id",
-			SdkPreferences: []*registryv1.SdkPreference{
-				{
-					Sdk:    registryv1.SDK_SDK_GO_PROTOBUF,
-					Status: true,
-				},
-			},
-		}))
-		require.NoError(t, err)
+		var held, ranHeld bool
+		mockService.EXPECT().
+			AcquireCloneSlot("user-123").
+			DoAndReturn(func(string) (func(), error) {
+				held = true
+				return func() { held = false }, nil
+			})
+		h.command = func(name string, arg ...string) *exec.Cmd {
+			ranHeld = held
+			return exec.Command("true")
+		}
+
+		w := fetch(h)
+
+		require.Equal(t, http.StatusOK, w.Code)
+		assert.True(t, ranHeld)
+		assert.False(t, held)
 	})
 
-	t.Run("service error", func(t *testing.T) {
-		ctrl := gomock.NewController(t)
-		mockService := NewMockService(ctrl)
-		mockRepository := NewMockRepository(ctrl)
+	t.Run("clone over the limit is rejected", func(t *testing.T) {
+		h, mockService := newHandler(t)
 
 		mockService.EXPECT().
-			UpdateSdkPreferences(gomock.Any(), gomock.Any()).
-			Return(connect.NewError(connect.CodeNotFound, errors.New("repository not found")))
+			AcquireCloneSlot("user-123").
+			Return(nil, ErrTooManyClones)
+		h.command = func(name string, arg ...string) *exec.Cmd {
+			t.Fatal("upload-pack must not run over the clone limit")
+			return nil
+		}
 
-		h := NewHandler(mockService, mockRepository)
-		mux := http.NewServeMux()
-		path, handler := h.RegisterRoutes()
-		mux.Handle(path, handler)
+		w := fetch(h)
 
-		server := httptest.NewServer(mux)
-		defer server.Close()
+		assert.Equal(t, http.StatusTooManyRequests, w.Code)
+		assert.Contains(t, w.Body.String(), "too many concurrent clones")
+	})
+}
 
-		client := registryv1connect.NewRegistryServiceClient(
-			http.DefaultClient,
-			server.URL,
-		)
+func TestSdkHttpHandler_ReadOnlyErrorEnvelope(t *testing.T) {
+	sdkPath := t.TempDir()
+	require.NoError(t, os.MkdirAll(filepath.Join(sdkPath, "org-1", "repo-1", "go-protobuf", ".git"), 0o750))
 
-		_, err := client.UpdateSdkPreferences(context.Background(), connect.NewRequest(&registryv1.UpdateSdkPreferencesRequest{
-			Id: "nonexistent-repo-id",
-		}))
-		require.Error(t, err)
+	h := NewSdkHttpHandler(sdkPath)
 
-		var connectErr *connect.Error
-		require.True(t, errors.As(err, &connectErr))
-		assert.Equal(t, connect.CodeNotFound, connectErr.Code())
-	})
+	req := httptest.NewRequest(http.MethodGet, "/sdk/org-1/repo-1/go-protobuf/info/refs?service=git-receive-pack", nil)
+	w := httptest.NewRecorder()
+
+	h.ServeHTTP(w, req)
+
+	require.Equal(t, http.StatusForbidden, w.Code)
+	assert.Equal(t, "application/json", w.Header().Get("Content-Type"))
+
+	body := decodeHttpError(t, w)
+	assert.Equal(t, "permission_denied", body.Error.Code)
+	assert.Equal(t, "SDK repositories are read-only", body.Error.Message)
 }
 
-func TestHandler_GetRecentCommit(t *testing.T) {
-	t.Run("success", func(t *testing.T) {
-		ctrl := gomock.NewController(t)
-		mockService := NewMockService(ctrl)
-		mockRepository := NewMockRepository(ctrl)
+func decodeHttpError(t *testing.T, w *httptest.ResponseRecorder) httpErrorBody {
+	t.Helper()
 
-		expectedCommit := &registryv1.Commit{
-			Id:      "abc123",
-			Message: "Recent commit",
-			User: &registryv1.Commit_User{
-				Id:       "user@example.com",
-				Username: "Test User",
-			},
-		}
+	var body httpErrorBody
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &body))
 
-		mockService.EXPECT().
-			GetRecentCommit(gomock.Any(), gomock.Any()).
-			DoAndReturn(func(_ context.Context, req *registryv1.GetRecentCommitRequest) (*registryv1.Commit, error) {
-				assert.Equal(t, "test-repo-id", req.GetRepositoryId())
-				return expectedCommit, nil
-			})
+	return body
+}
 
-		h := NewHandler(mockService, mockRepository)
-		mux := http.NewServeMux()
-		path, handler := h.RegisterRoutes()
-		mux.Handle(path, handler)
+func TestSdkHttpHandler_ServiceAllowlist(t *testing.T) {
+	sdkPath := t.TempDir()
+	require.NoError(t, os.MkdirAll(filepath.Join(sdkPath, "org-1", "repo-1", "go-protobuf", ".git"), 0o750))
 
-		server := httptest.NewServer(mux)
-		defer server.Close()
+	h := NewSdkHttpHandler(sdkPath)
 
-		client := registryv1connect.NewRegistryServiceClient(
-			http.DefaultClient,
-			server.URL,
-		)
+	req := httptest.NewRequest(http.MethodGet, "/sdk/org-1/repo-1/go-protobuf/info/refs?service=git-upload-archive", nil)
+	w := httptest.NewRecorder()
 
-		resp, err := client.GetRecentCommit(context.Background(), connect.NewRequest(&registryv1.GetRecentCommitRequest{
-			RepositoryId: "test-repo-id",
-		}))
-		require.NoError(t, err)
-		assert.NotNil(t, resp)
-		assert.Equal(t, "abc123", resp.Msg.GetId())
-		assert.Equal(t, "Recent commit", resp.Msg.GetMessage())
+	h.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+}
+
+func TestSdkHttpHandler_ServeFile(t *testing.T) {
+	sdkPath := t.TempDir()
+	sdkRepo := filepath.Join(sdkPath, "org-1", "repo-1", "go-protobuf")
+	require.NoError(t, os.MkdirAll(filepath.Join(sdkRepo, ".git"), 0o750))
+	require.NoError(t, os.MkdirAll(filepath.Join(sdkRepo, "gen"), 0o750))
+	content := []byte("0123456789abcdefghij")
+	require.NoError(t, os.WriteFile(filepath.Join(sdkRepo, "gen", "api.pb.go"), content, 0o600))
+	require.NoError(t, os.WriteFile(filepath.Join(sdkRepo, ".git", "config"), []byte("[core]"), 0o600))
+
+	h := NewSdkHttpHandler(sdkPath)
+
+	serve := func(path, rangeHeader string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, path, nil)
+		if rangeHeader != "" {
+			req.Header.Set("Range", rangeHeader)
+		}
+		w := httptest.NewRecorder()
+		h.ServeHTTP(w, req)
+		return w
+	}
+
+	t.Run("full download advertises byte ranges", func(t *testing.T) {
+		w := serve("/sdk/org-1/repo-1/go-protobuf/gen/api.pb.go", "")
+
+		require.Equal(t, http.StatusOK, w.Code)
+		assert.Equal(t, "bytes", w.Header().Get("Accept-Ranges"))
+		assert.Equal(t, content, w.Body.Bytes())
 	})
 
-	t.Run("service error - repository not found", func(t *testing.T) {
-		ctrl := gomock.NewController(t)
-		mockService := NewMockService(ctrl)
-		mockRepository := NewMockRepository(ctrl)
+	t.Run("ranged request resumes mid-file", func(t *testing.T) {
+		w := serve("/sdk/org-1/repo-1/go-protobuf/gen/api.pb.go", "bytes=10-")
 
-		mockService.EXPECT().
-			GetRecentCommit(gomock.Any(), gomock.Any()).
-			Return(nil, ErrRepositoryNotFound)
+		require.Equal(t, http.StatusPartialContent, w.Code)
+		assert.Equal(t, "bytes 10-19/20", w.Header().Get("Content-Range"))
+		assert.Equal(t, content[10:], w.Body.Bytes())
+	})
 
-		h := NewHandler(mockService, mockRepository)
-		mux := http.NewServeMux()
-		path, handler := h.RegisterRoutes()
-		mux.Handle(path, handler)
+	t.Run("git metadata is not served", func(t *testing.T) {
+		w := serve("/sdk/org-1/repo-1/go-protobuf/.git/config", "")
 
-		server := httptest.NewServer(mux)
-		defer server.Close()
+		assert.Equal(t, http.StatusNotFound, w.Code)
+	})
 
-		client := registryv1connect.NewRegistryServiceClient(
-			http.DefaultClient,
-			server.URL,
-		)
+	t.Run("directories are not served", func(t *testing.T) {
+		w := serve("/sdk/org-1/repo-1/go-protobuf/gen", "")
 
-		_, err := client.GetRecentCommit(context.Background(), connect.NewRequest(&registryv1.GetRecentCommitRequest{
-			RepositoryId: "non-existent-repo",
-		}))
-		require.Error(t, err)
+		assert.Equal(t, http.StatusNotFound, w.Code)
+	})
 
-		var connectErr *connect.Error
-		require.True(t, errors.As(err, &connectErr))
-		assert.Equal(t, connect.CodeNotFound, connectErr.Code())
+	t.Run("pack advertisement cannot be resumed", func(t *testing.T) {
+		if _, err := exec.LookPath("git"); err != nil {
+			t.Skip("git not installed")
+		}
+
+		w := serve("/sdk/org-1/repo-1/go-protobuf/info/refs?service=git-upload-pack", "")
+
+		assert.Equal(t, "none", w.Header().Get("Accept-Ranges"))
 	})
 }
 
@@ -1540,6 +2620,11 @@ func TestDocumentationHttpHandler(t *testing.T) {
 
 		res := rec.Result()
 		assert.Equal(t, http.StatusNotFound, res.StatusCode)
+		assert.Equal(t, "application/json", res.Header.Get("Content-Type"))
+
+		body := decodeHttpError(t, rec)
+		assert.Equal(t, "not_found", body.Error.Code)
+		assert.Equal(t, "Repository not found", body.Error.Message)
 	})
 
 	t.Run("organization mismatch", func(t *testing.T) {
@@ -1715,6 +2800,10 @@ func TestGitSshHandler_triggerPostPushActions(t *testing.T) {
 			reposPath: tempDir,
 		}
 
+		mockService.EXPECT().
+			RecordPush(gomock.Any(), repoID, gomock.Any()).
+			Return(nil)
+
 		mockService.EXPECT().
 			HasProtoFiles(gomock.Any(), repoPath).
 			Return(true, nil)
@@ -1746,6 +2835,10 @@ func TestGitSshHandler_triggerPostPushActions(t *testing.T) {
 			reposPath: tempDir,
 		}
 
+		mockService.EXPECT().
+			RecordPush(gomock.Any(), repoID, gomock.Any()).
+			Return(nil)
+
 		mockService.EXPECT().
 			HasProtoFiles(gomock.Any(), repoPath).
 			Return(false, nil)
@@ -1787,6 +2880,10 @@ func TestGitSshHandler_triggerPostPushActions(t *testing.T) {
 			reposPath: tempDir,
 		}
 
+		mockService.EXPECT().
+			RecordPush(gomock.Any(), repoID, gomock.Any()).
+			Return(nil)
+
 		mockService.EXPECT().
 			HasProtoFiles(gomock.Any(), repoPath).
 			Return(false, errors.New("check failed"))
@@ -1810,6 +2907,10 @@ func TestGitSshHandler_triggerPostPushActions(t *testing.T) {
 			reposPath: tempDir,
 		}
 
+		mockService.EXPECT().
+			RecordPush(gomock.Any(), repoID, gomock.Any()).
+			Return(nil)
+
 		mockService.EXPECT().
 			HasProtoFiles(gomock.Any(), repoPath).
 			Return(true, nil)
@@ -1841,6 +2942,10 @@ func TestGitSshHandler_triggerPostPushActions(t *testing.T) {
 			reposPath: tempDir,
 		}
 
+		mockService.EXPECT().
+			RecordPush(gomock.Any(), repoID, gomock.Any()).
+			Return(nil)
+
 		mockService.EXPECT().
 			HasProtoFiles(gomock.Any(), repoPath).
 			Return(true, nil)
@@ -1876,6 +2981,10 @@ func TestGitHttpHandler_triggerPostPushActions(t *testing.T) {
 
 		ctx := context.Background()
 
+		mockService.EXPECT().
+			RecordPush(ctx, repoID, gomock.Any()).
+			Return(nil)
+
 		mockService.EXPECT().
 			HasProtoFiles(ctx, repoPath).
 			Return(true, nil)
@@ -1909,6 +3018,10 @@ func TestGitHttpHandler_triggerPostPushActions(t *testing.T) {
 
 		ctx := context.Background()
 
+		mockService.EXPECT().
+			RecordPush(ctx, repoID, gomock.Any()).
+			Return(nil)
+
 		mockService.EXPECT().
 			HasProtoFiles(ctx, repoPath).
 			Return(false, nil)
@@ -1954,6 +3067,10 @@ func TestGitHttpHandler_triggerPostPushActions(t *testing.T) {
 
 		ctx := context.Background()
 
+		mockService.EXPECT().
+			RecordPush(ctx, repoID, gomock.Any()).
+			Return(nil)
+
 		mockService.EXPECT().
 			HasProtoFiles(ctx, repoPath).
 			Return(false, errors.New("check failed"))
@@ -1979,6 +3096,10 @@ func TestGitHttpHandler_triggerPostPushActions(t *testing.T) {
 
 		ctx := context.Background()
 
+		mockService.EXPECT().
+			RecordPush(ctx, repoID, gomock.Any()).
+			Return(nil)
+
 		mockService.EXPECT().
 			HasProtoFiles(ctx, repoPath).
 			Return(true, nil)
@@ -2012,6 +3133,10 @@ func TestGitHttpHandler_triggerPostPushActions(t *testing.T) {
 
 		ctx := context.Background()
 
+		mockService.EXPECT().
+			RecordPush(ctx, repoID, gomock.Any()).
+			Return(nil)
+
 		mockService.EXPECT().
 			HasProtoFiles(ctx, repoPath).
 			Return(true, nil)
@@ -2027,3 +3152,142 @@ func TestGitHttpHandler_triggerPostPushActions(t *testing.T) {
 		handler.triggerPostPushActions(ctx, repoPath)
 	})
 }
+
+func TestGitHttpHandler_ArchivedRepository(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not installed")
+	}
+
+	const (
+		userID = "user-123"
+		orgID  = "org-123"
+		repoID = "repo-uuid"
+	)
+
+	setup := func(t *testing.T, archived bool) *GitHttpHandler {
+		t.Helper()
+
+		tempDir := t.TempDir()
+		repoPath := filepath.Join(tempDir, repoID)
+		require.NoError(t, exec.Command("git", "init", "--bare", repoPath).Run())
+
+		ctrl := gomock.NewController(t)
+		mockRepo := NewMockRepository(ctrl)
+		mockOrgRepo := authorization.NewMockMemberRoleChecker(ctrl)
+		mockUserRepo := user.NewMockRepository(ctrl)
+
+		mockUserRepo.EXPECT().
+			GetUserByApiKey(gomock.Any(), "valid-key").
+			Return(&user.UserDTO{Id: userID}, nil)
+		mockRepo.EXPECT().
+			GetRepositoryById(gomock.Any(), repoID).
+			Return(&RepositoryDTO{
+				Id:             repoID,
+				OrganizationId: orgID,
+				Path:           repoPath,
+				Archived:       archived,
+			}, nil)
+		mockOrgRepo.EXPECT().
+			GetMemberRole(gomock.Any(), orgID, userID).
+			Return(authorization.MemberRoleOwner, nil)
+
+		svc := &service{
+			rootPath:   tempDir,
+			repository: mockRepo,
+			orgRepo:    mockOrgRepo,
+		}
+
+		return NewGitHttpHandler(svc, mockUserRepo, tempDir)
+	}
+
+	serve := func(h *GitHttpHandler, gitService string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, fmt.Sprintf("/git/%s/info/refs?service=%s", repoID, gitService), nil)
+		req.SetBasicAuth("user", "valid-key")
+		w := httptest.NewRecorder()
+		h.ServeHTTP(w, req)
+		return w
+	}
+
+	t.Run("push is blocked while archived", func(t *testing.T) {
+		h := setup(t, true)
+
+		w := serve(h, "git-receive-pack")
+
+		require.Equal(t, http.StatusForbidden, w.Code)
+		assert.Contains(t, w.Body.String(), "repository is archived")
+	})
+
+	t.Run("clone still works while archived", func(t *testing.T) {
+		h := setup(t, true)
+
+		w := serve(h, "git-upload-pack")
+
+		require.Equal(t, http.StatusOK, w.Code)
+		assert.Contains(t, w.Body.String(), "# service=git-upload-pack")
+	})
+
+	t.Run("push is allowed again after unarchiving", func(t *testing.T) {
+		h := setup(t, false)
+
+		w := serve(h, "git-receive-pack")
+
+		require.Equal(t, http.StatusOK, w.Code)
+		assert.Contains(t, w.Body.String(), "# service=git-receive-pack")
+	})
+}
+
+func TestGitHttpHandler_GitSpans(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not installed")
+	}
+
+	exporter := tracetest.NewInMemoryExporter()
+	provider := sdktrace.NewTracerProvider(sdktrace.WithSyncer(exporter))
+	previous := otel.GetTracerProvider()
+	otel.SetTracerProvider(provider)
+	t.Cleanup(func() {
+		otel.SetTracerProvider(previous)
+		_ = provider.Shutdown(context.Background())
+	})
+
+	ctrl := gomock.NewController(t)
+	mockService := NewMockService(ctrl)
+
+	tempDir := t.TempDir()
+	repoPath := filepath.Join(tempDir, "traced-repo")
+	require.NoError(t, exec.Command("git", "init", "--bare", repoPath).Run())
+
+	mockService.EXPECT().
+		IsPublicRepository(gomock.Any(), repoPath).
+		Return(true, nil)
+
+	ctx, parent := provider.Tracer("test").Start(context.Background(), "request")
+	req := httptest.NewRequestWithContext(ctx, http.MethodGet, "/git/traced-repo/info/refs?service=git-upload-pack", nil)
+	w := httptest.NewRecorder()
+
+	NewGitHttpHandler(mockService, nil, tempDir).ServeHTTP(w, req)
+	parent.End()
+
+	require.Equal(t, http.StatusOK, w.Code)
+
+	var gitSpan *tracetest.SpanStub
+	spans := exporter.GetSpans()
+	for i := range spans {
+		if spans[i].Name == "git-upload-pack --advertise-refs" {
+			gitSpan = &spans[i]
+		}
+	}
+	require.NotNil(t, gitSpan, "expected a git span, got %v", spans.Snapshots())
+
+	assert.Equal(t, parent.SpanContext().SpanID(), gitSpan.Parent.SpanID())
+	assert.Equal(t, parent.SpanContext().TraceID(), gitSpan.SpanContext.TraceID())
+
+	attrs := make(map[attribute.Key]attribute.Value)
+	for _, kv := range gitSpan.Attributes {
+		attrs[kv.Key] = kv.Value
+	}
+	assert.Equal(t, "traced-repo", attrs["repoId"].AsString())
+	assert.Equal(t, "git-upload-pack --advertise-refs", attrs["operation"].AsString())
+	assert.Equal(t, int64(0), attrs["exitCode"].AsInt64())
+	assert.Positive(t, attrs["bytesOut"].AsInt64())
+}
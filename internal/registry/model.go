@@ -15,9 +15,119 @@ type RepositoryDTO struct {
 	OrganizationId string           `db:"organization_id"`
 	Path           string           `db:"path"`
 	Visibility     proto.Visibility `db:"visibility"`
+	Archived       bool             `db:"archived"`
 	CreatedAt      time.Time        `db:"created_at"`
 	UpdatedAt      *time.Time       `db:"updated_at"`
 	DeletedAt      *time.Time       `db:"deleted_at"`
+	// ForkedFrom is the id of the repository this one was forked from.
+	ForkedFrom *string `db:"forked_from"`
+}
+
+// FilePreview carries the preview metadata that GetFilePreviewResponse has no
+// fields for. Binary previews have no content.
+type FilePreview struct {
+	*registryv1.GetFilePreviewResponse
+	Truncated bool
+	Binary    bool
+	Language  string
+}
+
+// HeadRef names whatever branch a repository's HEAD points at. An empty ref
+// means the same.
+const HeadRef = "HEAD"
+
+// FileTreePage selects a window of one directory's entries. A zero Limit lists
+// every entry.
+type FileTreePage struct {
+	Offset int
+	Limit  int
+}
+
+// FileTree is a directory listing plus the number of entries in the listed
+// directory and the directories whose children were left out for being deeper
+// than the listing goes, which GetFileTreeResponse has no fields for.
+type FileTree struct {
+	*registryv1.GetFileTreeResponse
+	TotalCount     int
+	TruncatedPaths []string
+}
+
+// SdkManifest lists the SDKs generated for one commit of a repository. Sdks
+// is empty until the first generation for that commit finishes.
+type SdkManifest struct {
+	RepositoryId string
+	CommitHash   string
+	Sdks         []SdkManifestEntry
+}
+
+// SdkManifestEntry.Path is the SDK's directory relative to the SDK output
+// root; artifact paths are relative to it.
+type SdkManifestEntry struct {
+	Sdk       SDK
+	Path      string
+	Artifacts []SdkArtifact
+	TotalSize int64
+}
+
+type SdkArtifact struct {
+	Path string
+	Size int64
+}
+
+// RepositoryDetails, CommitLog and RecentCommit also report whether the
+// repository has no commits yet, which their response messages have no field
+// for. RepositoryDetails likewise reports whether pushes are lint-checked,
+// and the SDK health when it was asked for.
+type RepositoryDetails struct {
+	*registryv1.Repository
+	Empty      bool
+	PushLint   bool
+	SdkHealth  []SdkHealth
+	ForkedFrom *string
+	Archived   bool
+}
+
+type RepositoryList struct {
+	*registryv1.GetRepositoriesResponse
+	// ArchivedIds lists the repositories of this page that are archived, since
+	// Repository has no field for it.
+	ArchivedIds []string
+}
+
+type CommitLog struct {
+	*registryv1.GetCommitsResponse
+	Empty bool
+}
+
+type RecentCommit struct {
+	*registryv1.Commit
+	Empty bool
+}
+
+type CreateRepositoryOptions struct {
+	ApplyTemplate bool
+}
+
+// GetRepositoryOptions.IncludeSdkPreferences loads the SDK preferences with
+// the repository in one query, and IncludeSdkHealth the outcome of the latest
+// generation of every enabled SDK. The handler asks for the preferences unless
+// the client opts out; the health is left out by default.
+type GetRepositoryOptions struct {
+	IncludeSdkPreferences bool
+	IncludeSdkHealth      bool
+}
+
+type SdkHealthStatus string
+
+const (
+	SdkHealthOk      SdkHealthStatus = "ok"
+	SdkHealthFailed  SdkHealthStatus = "failed"
+	SdkHealthPending SdkHealthStatus = "pending"
+)
+
+type SdkHealth struct {
+	Sdk    SDK
+	Status SdkHealthStatus
 }
 
 type SDK string
@@ -49,13 +159,53 @@ var SdkDbToProtoEnum = map[SDK]registryv1.SDK{
 	SdkJsConnectrpc: registryv1.SDK_SDK_JS_CONNECTRPC,
 }
 
+// SdkPreferencesDTO.LastGeneratedCommit is the commit this SDK was last
+// generated from, used to skip pushes that leave its inputs unchanged.
 type SdkPreferencesDTO struct {
-	Id           string     `db:"id"`
-	RepositoryId string     `db:"repository_id"`
-	Sdk          SDK        `db:"sdk"`
-	Status       bool       `db:"status"`
-	CreatedAt    time.Time  `db:"created_at"`
-	UpdatedAt    *time.Time `db:"updated_at"`
+	Id                  string     `db:"id"`
+	RepositoryId        string     `db:"repository_id"`
+	Sdk                 SDK        `db:"sdk"`
+	Status              bool       `db:"status"`
+	CreatedAt           time.Time  `db:"created_at"`
+	UpdatedAt           *time.Time `db:"updated_at"`
+	LastGeneratedCommit *string    `db:"last_generated_commit"`
+}
+
+type CloneUrls struct {
+	Http string
+	Ssh  string
+}
+
+type RefInfo struct {
+	Name   string
+	Target string
+}
+
+// BranchDivergence compares a branch with the default branch. Ahead counts
+// the commits only the branch has, Behind those only the default branch has.
+// Unrelated branches share no history with the default branch and carry no
+// counts.
+type BranchDivergence struct {
+	Name      string
+	Target    string
+	Ahead     int
+	Behind    int
+	Unrelated bool
+}
+
+type Contributor struct {
+	Name        string
+	Email       string
+	CommitCount int
+	// UserId and Username are set when the commit email belongs to a Hasir user.
+	UserId   string
+	Username string
+}
+
+type UserIdentity struct {
+	Id       string `db:"id"`
+	Username string `db:"username"`
+	Email    string `db:"email"`
 }
 
 type SshOperation string
@@ -72,6 +222,7 @@ const (
 	SdkGenerationJobStatusProcessing SdkGenerationJobStatus = "processing"
 	SdkGenerationJobStatusCompleted  SdkGenerationJobStatus = "completed"
 	SdkGenerationJobStatusFailed     SdkGenerationJobStatus = "failed"
+	SdkGenerationJobStatusCancelled  SdkGenerationJobStatus = "cancelled"
 )
 
 type SdkGenerationJobDTO struct {
@@ -88,6 +239,12 @@ type SdkGenerationJobDTO struct {
 	ErrorMessage *string                `db:"error_message"`
 }
 
+// SdkJobStatusDTO is the status of the latest generation job of one SDK.
+type SdkJobStatusDTO struct {
+	Sdk    SDK                    `db:"sdk"`
+	Status SdkGenerationJobStatus `db:"status"`
+}
+
 type SdkTriggerJobDTO struct {
 	Id           string                 `db:"id"`
 	RepositoryId string                 `db:"repository_id"`
@@ -100,3 +257,58 @@ type SdkTriggerJobDTO struct {
 	CompletedAt  *time.Time             `db:"completed_at"`
 	ErrorMessage *string                `db:"error_message"`
 }
+
+// DocsGenerationJobDTO is a queued documentation build for one commit. At most
+// one job per repository is pending or processing at a time.
+type DocsGenerationJobDTO struct {
+	Id           string                 `db:"id"`
+	RepositoryId string                 `db:"repository_id"`
+	CommitHash   string                 `db:"commit_hash"`
+	Status       SdkGenerationJobStatus `db:"status"`
+	Attempts     int                    `db:"attempts"`
+	MaxAttempts  int                    `db:"max_attempts"`
+	CreatedAt    time.Time              `db:"created_at"`
+	ProcessedAt  *time.Time             `db:"processed_at"`
+	CompletedAt  *time.Time             `db:"completed_at"`
+	ErrorMessage *string                `db:"error_message"`
+}
+
+type PushDTO struct {
+	Id           string    `db:"id"`
+	RepositoryId string    `db:"repository_id"`
+	CommitHash   string    `db:"commit_hash"`
+	PushedAt     time.Time `db:"pushed_at"`
+}
+
+// DeployTokenDTO is a credential scoped to a single repository, for CI
+// pipelines that should not act as a user.
+type DeployTokenDTO struct {
+	Id           string     `db:"id"`
+	RepositoryId string     `db:"repository_id"`
+	Token        string     `db:"token"`
+	ReadOnly     bool       `db:"read_only"`
+	CreatedBy    string     `db:"created_by"`
+	CreatedAt    time.Time  `db:"created_at"`
+	DeletedAt    *time.Time `db:"deleted_at"`
+}
+
+type ActivityType string
+
+const (
+	ActivityTypePush          ActivityType = "push"
+	ActivityTypeSdkGeneration ActivityType = "sdk_generation"
+)
+
+// ActivityDTO is one entry of an activity feed. Sdk and Status are only set
+// for SDK generations.
+type ActivityDTO struct {
+	Id             string                  `db:"id"`
+	Type           ActivityType            `db:"type"`
+	RepositoryId   string                  `db:"repository_id"`
+	RepositoryName string                  `db:"repository_name"`
+	OrganizationId string                  `db:"organization_id"`
+	CommitHash     string                  `db:"commit_hash"`
+	Sdk            *SDK                    `db:"sdk"`
+	Status         *SdkGenerationJobStatus `db:"status"`
+	OccurredAt     time.Time               `db:"occurred_at"`
+}
@@ -0,0 +1,100 @@
+package registry
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"connectrpc.com/connect"
+	"go.uber.org/zap"
+
+	"hasir-api/pkg/postgres"
+)
+
+var ErrRepositoryNotOrphaned = connect.NewError(connect.CodeFailedPrecondition, errors.New("repository directory belongs to a repository"))
+
+// FindOrphanedRepositories lists the directories under the repository root
+// that no live repository points at, such as leftovers of a failed delete or
+// of manual work on the disk.
+func (s *service) FindOrphanedRepositories(ctx context.Context) ([]string, error) {
+	entries, err := os.ReadDir(s.rootPath)
+	if errors.Is(err, os.ErrNotExist) {
+		return []string{}, nil
+	}
+	if err != nil {
+		return nil, connect.NewError(connect.CodeInternal, fmt.Errorf("failed to read repository root: %w", err))
+	}
+
+	orphans := []string{}
+	for _, entry := range entries {
+		if !entry.IsDir() {
+			continue
+		}
+
+		repoPath := filepath.Join(s.rootPath, entry.Name())
+		orphaned, err := s.isOrphaned(ctx, repoPath)
+		if err != nil {
+			return nil, err
+		}
+		if orphaned {
+			orphans = append(orphans, repoPath)
+		}
+	}
+
+	return orphans, nil
+}
+
+// PruneOrphan removes one directory reported by FindOrphanedRepositories. The
+// check is repeated under the path lock, so a repository created in the
+// meantime is never removed.
+func (s *service) PruneOrphan(ctx context.Context, repoPath string) error {
+	repoPath = filepath.Clean(repoPath)
+	if filepath.Dir(repoPath) != filepath.Clean(s.rootPath) {
+		return connect.NewError(connect.CodeInvalidArgument, fmt.Errorf("%q is not a directory under the repository root", repoPath))
+	}
+
+	unlock := s.pathLocks.lock(repoPath)
+	defer unlock()
+
+	info, err := os.Stat(repoPath)
+	if errors.Is(err, os.ErrNotExist) {
+		return connect.NewError(connect.CodeNotFound, errors.New("repository directory not found"))
+	}
+	if err != nil {
+		return connect.NewError(connect.CodeInternal, fmt.Errorf("failed to stat repository directory: %w", err))
+	}
+	if !info.IsDir() {
+		return connect.NewError(connect.CodeInvalidArgument, fmt.Errorf("%q is not a directory", repoPath))
+	}
+
+	orphaned, err := s.isOrphaned(ctx, repoPath)
+	if err != nil {
+		return err
+	}
+	if !orphaned {
+		return ErrRepositoryNotOrphaned
+	}
+
+	if err := os.RemoveAll(repoPath); err != nil {
+		return connect.NewError(connect.CodeInternal, fmt.Errorf("failed to remove repository directory: %w", err))
+	}
+
+	zap.L().Info("pruned orphaned repository directory", zap.String("path", repoPath))
+	return nil
+}
+
+// isOrphaned reads from the primary: a repository created moments ago may not
+// have reached the replica yet, and deleting its directory cannot be undone.
+func (s *service) isOrphaned(ctx context.Context, repoPath string) (bool, error) {
+	_, err := s.repository.GetRepositoryByPath(postgres.WithPrimary(ctx), repoPath)
+	if err == nil {
+		return false, nil
+	}
+	if connect.CodeOf(err) == connect.CodeNotFound {
+		return true, nil
+	}
+
+	return false, err
+}
@@ -0,0 +1,131 @@
+package registry
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"connectrpc.com/connect"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+
+	"hasir-api/pkg/postgres"
+)
+
+var errRepositoryRowNotFound = connect.NewError(connect.CodeNotFound, errors.New("repository not found"))
+
+// primaryCtx matches a context whose reads are routed to the primary.
+func primaryCtx() gomock.Matcher {
+	primary, replica := new(pgxpool.Pool), new(pgxpool.Pool)
+	return gomock.Cond(func(ctx context.Context) bool {
+		return postgres.ReadPool(ctx, primary, replica) == primary
+	})
+}
+
+func newOrphanTestService(t *testing.T) (*service, *MockRepository, string) {
+	t.Helper()
+
+	ctrl := gomock.NewController(t)
+	mockRepo := NewMockRepository(ctrl)
+	rootPath := t.TempDir()
+
+	return &service{rootPath: rootPath, repository: mockRepo}, mockRepo, rootPath
+}
+
+func TestService_FindOrphanedRepositories(t *testing.T) {
+	t.Run("reports only directories without a repository row", func(t *testing.T) {
+		svc, mockRepo, rootPath := newOrphanTestService(t)
+		ctx := context.Background()
+
+		orphanPath := filepath.Join(rootPath, "orphan")
+		livePath := filepath.Join(rootPath, "live")
+		require.NoError(t, os.Mkdir(orphanPath, 0o750))
+		require.NoError(t, os.Mkdir(livePath, 0o750))
+		require.NoError(t, os.WriteFile(filepath.Join(rootPath, "stray-file"), nil, 0o600))
+
+		mockRepo.EXPECT().
+			GetRepositoryByPath(primaryCtx(), orphanPath).
+			Return(nil, errRepositoryRowNotFound)
+		mockRepo.EXPECT().
+			GetRepositoryByPath(primaryCtx(), livePath).
+			Return(&RepositoryDTO{Id: "live", Path: livePath}, nil)
+
+		orphans, err := svc.FindOrphanedRepositories(ctx)
+		require.NoError(t, err)
+		assert.Equal(t, []string{orphanPath}, orphans)
+	})
+
+	t.Run("missing root has no orphans", func(t *testing.T) {
+		svc, _, rootPath := newOrphanTestService(t)
+		svc.rootPath = filepath.Join(rootPath, "missing")
+
+		orphans, err := svc.FindOrphanedRepositories(context.Background())
+		require.NoError(t, err)
+		assert.Empty(t, orphans)
+	})
+
+	t.Run("lookup failure is returned", func(t *testing.T) {
+		svc, mockRepo, rootPath := newOrphanTestService(t)
+		require.NoError(t, os.Mkdir(filepath.Join(rootPath, "repo"), 0o750))
+
+		mockRepo.EXPECT().
+			GetRepositoryByPath(gomock.Any(), gomock.Any()).
+			Return(nil, connect.NewError(connect.CodeInternal, errors.New("db down")))
+
+		_, err := svc.FindOrphanedRepositories(context.Background())
+		assert.Equal(t, connect.CodeInternal, connect.CodeOf(err))
+	})
+}
+
+func TestService_PruneOrphan(t *testing.T) {
+	t.Run("removes an orphaned directory", func(t *testing.T) {
+		svc, mockRepo, rootPath := newOrphanTestService(t)
+		ctx := context.Background()
+
+		orphanPath := filepath.Join(rootPath, "orphan")
+		require.NoError(t, os.MkdirAll(filepath.Join(orphanPath, "objects"), 0o750))
+
+		mockRepo.EXPECT().
+			GetRepositoryByPath(primaryCtx(), orphanPath).
+			Return(nil, errRepositoryRowNotFound)
+
+		require.NoError(t, svc.PruneOrphan(ctx, orphanPath))
+		assert.NoDirExists(t, orphanPath)
+	})
+
+	t.Run("keeps a directory that has a repository row", func(t *testing.T) {
+		svc, mockRepo, rootPath := newOrphanTestService(t)
+		ctx := context.Background()
+
+		livePath := filepath.Join(rootPath, "live")
+		require.NoError(t, os.Mkdir(livePath, 0o750))
+
+		mockRepo.EXPECT().
+			GetRepositoryByPath(primaryCtx(), livePath).
+			Return(&RepositoryDTO{Id: "live", Path: livePath}, nil)
+
+		err := svc.PruneOrphan(ctx, livePath)
+		assert.Equal(t, connect.CodeFailedPrecondition, connect.CodeOf(err))
+		assert.DirExists(t, livePath)
+	})
+
+	t.Run("rejects paths outside the repository root", func(t *testing.T) {
+		svc, _, rootPath := newOrphanTestService(t)
+
+		for _, path := range []string{rootPath, filepath.Join(rootPath, "a", "b"), filepath.Join(rootPath, "..", "other")} {
+			err := svc.PruneOrphan(context.Background(), path)
+			assert.Equal(t, connect.CodeInvalidArgument, connect.CodeOf(err), path)
+		}
+	})
+
+	t.Run("missing directory is not found", func(t *testing.T) {
+		svc, _, rootPath := newOrphanTestService(t)
+
+		err := svc.PruneOrphan(context.Background(), filepath.Join(rootPath, "gone"))
+		assert.Equal(t, connect.CodeNotFound, connect.CodeOf(err))
+	})
+}
@@ -0,0 +1,71 @@
+package registry
+
+import (
+	"context"
+	"sync"
+)
+
+// pathLocks serializes work on the same repository directory, such as a
+// create racing a delete or two pushes to one repository. Locks are created
+// on demand and dropped once nobody holds or waits for them; the zero value
+// is ready to use.
+type pathLocks struct {
+	mu    sync.Mutex
+	locks map[string]*pathLock
+}
+
+type pathLock struct {
+	// held is a one-slot semaphore rather than a mutex so that waiting for it
+	// can be abandoned.
+	held chan struct{}
+	refs int
+}
+
+// lock blocks until path is free and returns the function that releases it.
+func (l *pathLocks) lock(path string) (unlock func()) {
+	unlock, _ = l.lockContext(context.Background(), path)
+	return unlock
+}
+
+// lockContext is lock that gives up with the context's error once ctx is
+// done.
+func (l *pathLocks) lockContext(ctx context.Context, path string) (unlock func(), err error) {
+	l.mu.Lock()
+	if l.locks == nil {
+		l.locks = make(map[string]*pathLock)
+	}
+	entry, ok := l.locks[path]
+	if !ok {
+		entry = &pathLock{held: make(chan struct{}, 1)}
+		l.locks[path] = entry
+	}
+	entry.refs++
+	l.mu.Unlock()
+
+	release := func() {
+		l.mu.Lock()
+		entry.refs--
+		if entry.refs == 0 {
+			delete(l.locks, path)
+		}
+		l.mu.Unlock()
+	}
+
+	// A free lock is taken even when ctx is already done, so a zero wait
+	// still succeeds when nobody holds it.
+	select {
+	case entry.held <- struct{}{}:
+	default:
+		select {
+		case entry.held <- struct{}{}:
+		case <-ctx.Done():
+			release()
+			return nil, ctx.Err()
+		}
+	}
+
+	return func() {
+		<-entry.held
+		release()
+	}, nil
+}
@@ -0,0 +1,216 @@
+package registry
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"hasir-api/pkg/sdkgenerator"
+)
+
+// PreReceiveHookCommand is the argument list main recognises as a git
+// pre-receive hook invocation rather than a server start.
+var PreReceiveHookCommand = []string{"hook", "pre-receive"}
+
+// pushLintHookMarker identifies hook scripts written by installPushLintHook,
+// so a hook an operator put in place by hand is never reported as ours or
+// removed.
+const pushLintHookMarker = "# hasir: lint protos on push"
+
+const zeroCommitHash = "0000000000000000000000000000000000000000"
+
+var errCustomPreReceiveHook = errors.New("repository already has a custom pre-receive hook")
+
+// ProtoLinter checks the proto files of a checked-out tree and returns the
+// findings as the error when it does not pass.
+type ProtoLinter interface {
+	Lint(ctx context.Context, dir string) error
+}
+
+// BufLinter lints with the buf CLI, which also fails on files that do not
+// compile. A buf.yaml in the tree selects the rules.
+type BufLinter struct {
+	runner sdkgenerator.CommandRunner
+}
+
+func NewBufLinter(runner sdkgenerator.CommandRunner) *BufLinter {
+	return &BufLinter{runner: runner}
+}
+
+func (l *BufLinter) Lint(ctx context.Context, dir string) error {
+	if _, err := l.runner.Run(ctx, "buf", []string{"lint"}, dir); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// RefUpdate is one "<old> <new> <ref>" line git feeds a pre-receive hook.
+type RefUpdate struct {
+	OldRev  string
+	NewRev  string
+	RefName string
+}
+
+func parseRefUpdates(r io.Reader) ([]RefUpdate, error) {
+	var updates []RefUpdate
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" {
+			continue
+		}
+
+		fields := strings.Fields(line)
+		if len(fields) != 3 {
+			return nil, fmt.Errorf("malformed ref update %q", line)
+		}
+
+		updates = append(updates, RefUpdate{OldRev: fields[0], NewRev: fields[1], RefName: fields[2]})
+	}
+
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read ref updates: %w", err)
+	}
+
+	return updates, nil
+}
+
+// ValidatePush lints the tree every updated ref would point at. Ref deletions
+// and trees without proto files pass untouched; the failures of every ref are
+// returned together.
+func ValidatePush(ctx context.Context, linter ProtoLinter, repoPath string, updates []RefUpdate) error {
+	var errs []error
+	linted := make(map[string]error)
+	for _, update := range updates {
+		if update.NewRev == zeroCommitHash {
+			continue
+		}
+
+		err, ok := linted[update.NewRev]
+		if !ok {
+			err = lintCommit(ctx, linter, repoPath, update.NewRev)
+			linted[update.NewRev] = err
+		}
+
+		if err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", update.RefName, err))
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+func lintCommit(ctx context.Context, linter ProtoLinter, repoPath, commitHash string) error {
+	workDir, err := checkoutCommitToTempDir(ctx, repoPath, commitHash)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		_ = os.RemoveAll(workDir)
+	}()
+
+	protoFiles, err := sdkgenerator.FindProtoFiles(workDir)
+	if err != nil {
+		return fmt.Errorf("failed to find proto files: %w", err)
+	}
+	if len(protoFiles) == 0 {
+		return nil
+	}
+
+	if err := linter.Lint(ctx, workDir); err != nil {
+		return fmt.Errorf("proto lint failed:\n%w", err)
+	}
+
+	return nil
+}
+
+// RunPreReceiveHook is the body of the hook installPushLintHook writes. Git
+// runs it inside the bare repository with the pushed objects visible but not
+// yet accepted, so a non-zero result rejects the whole push and whatever was
+// written to stderr is relayed to the client.
+func RunPreReceiveHook(ctx context.Context, linter ProtoLinter, repoPath string, stdin io.Reader, stderr io.Writer) int {
+	updates, err := parseRefUpdates(stdin)
+	if err != nil {
+		_, _ = fmt.Fprintln(stderr, err)
+		return 1
+	}
+
+	if err := ValidatePush(ctx, linter, repoPath, updates); err != nil {
+		_, _ = fmt.Fprintf(stderr, "push rejected:\n%s\n", err)
+		return 1
+	}
+
+	return 0
+}
+
+func pushLintHookPath(repoPath string) string {
+	return filepath.Join(repoPath, "hooks", "pre-receive")
+}
+
+// isPushLintEnabled reports whether the repository carries the hook written
+// by installPushLintHook.
+func isPushLintEnabled(repoPath string) (bool, error) {
+	// #nosec G304 -- the hook path is derived from a stored repository path
+	content, err := os.ReadFile(pushLintHookPath(repoPath))
+	if errors.Is(err, os.ErrNotExist) {
+		return false, nil
+	}
+	if err != nil {
+		return false, fmt.Errorf("failed to read pre-receive hook: %w", err)
+	}
+
+	return strings.Contains(string(content), pushLintHookMarker), nil
+}
+
+// installPushLintHook writes a pre-receive hook that re-runs executable in
+// hook mode. An existing hook not written by us is left alone and reported.
+func installPushLintHook(repoPath, executable string) error {
+	hookPath := pushLintHookPath(repoPath)
+	if _, err := os.Stat(hookPath); err == nil {
+		enabled, err := isPushLintEnabled(repoPath)
+		if err != nil {
+			return err
+		}
+		if !enabled {
+			return errCustomPreReceiveHook
+		}
+	}
+
+	if err := os.MkdirAll(filepath.Dir(hookPath), 0o750); err != nil {
+		return fmt.Errorf("failed to create hooks directory: %w", err)
+	}
+
+	script := fmt.Sprintf("#!/bin/sh\n%s\nexec %s %s\n",
+		pushLintHookMarker, shellQuote(executable), strings.Join(PreReceiveHookCommand, " "))
+
+	// #nosec G306 -- git only runs executable hooks
+	if err := os.WriteFile(hookPath, []byte(script), 0o755); err != nil {
+		return fmt.Errorf("failed to write pre-receive hook: %w", err)
+	}
+
+	return nil
+}
+
+// removePushLintHook deletes the hook written by installPushLintHook, if any.
+func removePushLintHook(repoPath string) error {
+	enabled, err := isPushLintEnabled(repoPath)
+	if err != nil || !enabled {
+		return err
+	}
+
+	if err := os.Remove(pushLintHookPath(repoPath)); err != nil {
+		return fmt.Errorf("failed to remove pre-receive hook: %w", err)
+	}
+
+	return nil
+}
+
+func shellQuote(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
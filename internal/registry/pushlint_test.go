@@ -0,0 +1,151 @@
+package registry
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type fakeLinter struct {
+	err   error
+	calls int
+}
+
+func (l *fakeLinter) Lint(_ context.Context, dir string) error {
+	l.calls++
+	if _, err := os.Stat(filepath.Join(dir, "test.proto")); err != nil {
+		return err
+	}
+	return l.err
+}
+
+func TestValidatePush(t *testing.T) {
+	t.Run("failing lint rejects the push", func(t *testing.T) {
+		repoPath := t.TempDir()
+		commitHash := initGitRepoWithProtoFile(t, repoPath)
+		linter := &fakeLinter{err: errors.New("test.proto:1:1:Files must have a package defined.")}
+
+		err := ValidatePush(t.Context(), linter, repoPath, []RefUpdate{
+			{OldRev: zeroCommitHash, NewRev: commitHash, RefName: "refs/heads/main"},
+		})
+
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "refs/heads/main")
+		assert.Contains(t, err.Error(), "Files must have a package defined.")
+	})
+
+	t.Run("passing lint allows the push", func(t *testing.T) {
+		repoPath := t.TempDir()
+		commitHash := initGitRepoWithProtoFile(t, repoPath)
+		linter := &fakeLinter{}
+
+		err := ValidatePush(t.Context(), linter, repoPath, []RefUpdate{
+			{OldRev: zeroCommitHash, NewRev: commitHash, RefName: "refs/heads/main"},
+			{OldRev: zeroCommitHash, NewRev: commitHash, RefName: "refs/heads/release"},
+		})
+
+		require.NoError(t, err)
+		assert.Equal(t, 1, linter.calls)
+	})
+
+	t.Run("trees without proto files are not linted", func(t *testing.T) {
+		repoPath := t.TempDir()
+		commitHash := initGitRepoWithEmptyCommit(t, repoPath)
+		linter := &fakeLinter{err: errors.New("should not run")}
+
+		err := ValidatePush(t.Context(), linter, repoPath, []RefUpdate{
+			{OldRev: zeroCommitHash, NewRev: commitHash, RefName: "refs/heads/main"},
+		})
+
+		require.NoError(t, err)
+		assert.Zero(t, linter.calls)
+	})
+
+	t.Run("ref deletions are not linted", func(t *testing.T) {
+		linter := &fakeLinter{err: errors.New("should not run")}
+
+		err := ValidatePush(t.Context(), linter, t.TempDir(), []RefUpdate{
+			{OldRev: "1111111111111111111111111111111111111111", NewRev: zeroCommitHash, RefName: "refs/heads/old"},
+		})
+
+		require.NoError(t, err)
+		assert.Zero(t, linter.calls)
+	})
+}
+
+func TestRunPreReceiveHook(t *testing.T) {
+	repoPath := t.TempDir()
+	commitHash := initGitRepoWithProtoFile(t, repoPath)
+	stdin := zeroCommitHash + " " + commitHash + " refs/heads/main\n"
+
+	t.Run("lint failure exits non-zero with the findings", func(t *testing.T) {
+		var stderr bytes.Buffer
+		code := RunPreReceiveHook(t.Context(), &fakeLinter{err: errors.New("bad field name")}, repoPath, strings.NewReader(stdin), &stderr)
+
+		assert.Equal(t, 1, code)
+		assert.Contains(t, stderr.String(), "push rejected")
+		assert.Contains(t, stderr.String(), "bad field name")
+	})
+
+	t.Run("clean push exits zero", func(t *testing.T) {
+		var stderr bytes.Buffer
+		code := RunPreReceiveHook(t.Context(), &fakeLinter{}, repoPath, strings.NewReader(stdin), &stderr)
+
+		assert.Equal(t, 0, code)
+		assert.Empty(t, stderr.String())
+	})
+
+	t.Run("malformed input exits non-zero", func(t *testing.T) {
+		var stderr bytes.Buffer
+		code := RunPreReceiveHook(t.Context(), &fakeLinter{}, repoPath, strings.NewReader("garbage\n"), &stderr)
+
+		assert.Equal(t, 1, code)
+	})
+}
+
+func TestPushLintHook(t *testing.T) {
+	t.Run("install and remove", func(t *testing.T) {
+		repoPath := t.TempDir()
+
+		enabled, err := isPushLintEnabled(repoPath)
+		require.NoError(t, err)
+		assert.False(t, enabled)
+
+		require.NoError(t, installPushLintHook(repoPath, "/opt/hasir/hasir-api"))
+		enabled, err = isPushLintEnabled(repoPath)
+		require.NoError(t, err)
+		assert.True(t, enabled)
+
+		content, err := os.ReadFile(pushLintHookPath(repoPath))
+		require.NoError(t, err)
+		assert.Contains(t, string(content), "exec '/opt/hasir/hasir-api' hook pre-receive")
+
+		require.NoError(t, installPushLintHook(repoPath, "/opt/hasir/hasir-api"))
+
+		require.NoError(t, removePushLintHook(repoPath))
+		enabled, err = isPushLintEnabled(repoPath)
+		require.NoError(t, err)
+		assert.False(t, enabled)
+	})
+
+	t.Run("custom hook is left alone", func(t *testing.T) {
+		repoPath := t.TempDir()
+		hookPath := pushLintHookPath(repoPath)
+		require.NoError(t, os.MkdirAll(filepath.Dir(hookPath), 0o750))
+		require.NoError(t, os.WriteFile(hookPath, []byte("#!/bin/sh\nexit 0\n"), 0o600))
+
+		err := installPushLintHook(repoPath, "/opt/hasir/hasir-api")
+		assert.ErrorIs(t, err, errCustomPreReceiveHook)
+
+		require.NoError(t, removePushLintHook(repoPath))
+		_, err = os.Stat(hookPath)
+		assert.NoError(t, err)
+	})
+}
@@ -0,0 +1,38 @@
+package registry
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+
+	"connectrpc.com/connect"
+
+	"hasir-api/pkg/config"
+)
+
+var ErrPushInProgress = connect.NewError(connect.CodeAborted, errors.New("another push to this repository is in progress; try again shortly"))
+
+// LockRepositoryForPush serializes receive-pack runs on one repository, over
+// SSH and HTTP alike, so concurrent pushes do not fight over git's ref locks.
+// Fetches take no lock. A push waits up to the configured push lock timeout
+// for the one ahead of it and then fails with ErrPushInProgress.
+func (s *service) LockRepositoryForPush(ctx context.Context, repoPath string) (unlock func(), err error) {
+	timeout := config.DefaultPushLockTimeout
+	if s.cfg != nil {
+		// An invalid value is already rejected by config.Validate at startup.
+		timeout, _ = s.cfg.Repository.GetPushLockTimeout()
+	}
+
+	waitCtx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	unlock, err = s.pathLocks.lockContext(waitCtx, filepath.Clean(repoPath))
+	if err != nil {
+		if ctx.Err() != nil {
+			return nil, connect.NewError(connect.CodeCanceled, ctx.Err())
+		}
+		return nil, ErrPushInProgress
+	}
+
+	return unlock, nil
+}
@@ -0,0 +1,151 @@
+package registry
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"hasir-api/pkg/config"
+)
+
+func TestService_LockRepositoryForPush(t *testing.T) {
+	// simulatePush holds the push lock of repoPath for hold, tracking how many
+	// pushes held it at once.
+	simulatePush := func(s *service, repoPath string, hold time.Duration, active, maxActive *atomic.Int32) error {
+		unlock, err := s.LockRepositoryForPush(context.Background(), repoPath)
+		if err != nil {
+			return err
+		}
+		defer unlock()
+
+		n := active.Add(1)
+		for {
+			current := maxActive.Load()
+			if n <= current || maxActive.CompareAndSwap(current, n) {
+				break
+			}
+		}
+		time.Sleep(hold)
+		active.Add(-1)
+
+		return nil
+	}
+
+	t.Run("pushes to one repository serialize", func(t *testing.T) {
+		s := &service{}
+
+		var active, maxActive atomic.Int32
+		var wg sync.WaitGroup
+		errs := make([]error, 2)
+		for i := range errs {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				errs[i] = simulatePush(s, "./repos/repo-1", 50*time.Millisecond, &active, &maxActive)
+			}()
+		}
+		wg.Wait()
+
+		require.NoError(t, errs[0])
+		require.NoError(t, errs[1])
+		assert.Equal(t, int32(1), maxActive.Load())
+	})
+
+	t.Run("equivalent paths share a lock", func(t *testing.T) {
+		s := &service{cfg: &config.Config{Repository: config.RepositoryConfig{PushLockTimeout: "0s"}}}
+
+		unlock, err := s.LockRepositoryForPush(context.Background(), "./repos/repo-1")
+		require.NoError(t, err)
+		defer unlock()
+
+		_, err = s.LockRepositoryForPush(context.Background(), "repos//repo-1")
+		assert.ErrorIs(t, err, ErrPushInProgress)
+	})
+
+	t.Run("pushes to different repositories run concurrently", func(t *testing.T) {
+		s := &service{}
+
+		firstLocked := make(chan struct{})
+		secondLocked := make(chan struct{})
+		done := make(chan error, 2)
+
+		go func() {
+			unlock, err := s.LockRepositoryForPush(context.Background(), "./repos/repo-1")
+			if err != nil {
+				done <- err
+				return
+			}
+			defer unlock()
+			close(firstLocked)
+			<-secondLocked
+			done <- nil
+		}()
+		go func() {
+			<-firstLocked
+			unlock, err := s.LockRepositoryForPush(context.Background(), "./repos/repo-2")
+			if err != nil {
+				done <- err
+				return
+			}
+			defer unlock()
+			close(secondLocked)
+			done <- nil
+		}()
+
+		for range 2 {
+			select {
+			case err := <-done:
+				require.NoError(t, err)
+			case <-time.After(5 * time.Second):
+				t.Fatal("push to another repository waited for the first push")
+			}
+		}
+	})
+
+	t.Run("push gives up after the timeout", func(t *testing.T) {
+		s := &service{cfg: &config.Config{Repository: config.RepositoryConfig{PushLockTimeout: "20ms"}}}
+
+		unlock, err := s.LockRepositoryForPush(context.Background(), "./repos/repo-1")
+		require.NoError(t, err)
+
+		start := time.Now()
+		_, err = s.LockRepositoryForPush(context.Background(), "./repos/repo-1")
+		assert.ErrorIs(t, err, ErrPushInProgress)
+		assert.GreaterOrEqual(t, time.Since(start), 20*time.Millisecond)
+
+		unlock()
+
+		unlock, err = s.LockRepositoryForPush(context.Background(), "./repos/repo-1")
+		require.NoError(t, err)
+		unlock()
+	})
+
+	t.Run("zero timeout still takes a free lock", func(t *testing.T) {
+		s := &service{cfg: &config.Config{Repository: config.RepositoryConfig{PushLockTimeout: "0s"}}}
+
+		for range 100 {
+			unlock, err := s.LockRepositoryForPush(context.Background(), "./repos/repo-1")
+			require.NoError(t, err)
+			unlock()
+		}
+	})
+
+	t.Run("canceled wait is not reported as a busy repository", func(t *testing.T) {
+		s := &service{}
+
+		unlock, err := s.LockRepositoryForPush(context.Background(), "./repos/repo-1")
+		require.NoError(t, err)
+		defer unlock()
+
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+		_, err = s.LockRepositoryForPush(ctx, "./repos/repo-1")
+		require.Error(t, err)
+		assert.NotErrorIs(t, err, ErrPushInProgress)
+	})
+}
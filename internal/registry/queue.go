@@ -13,13 +13,27 @@ type SdkTriggerProcessor interface {
 	ProcessSdkTrigger(ctx context.Context, repositoryId, repoPath string) error
 }
 
+type DocsGenerator interface {
+	TriggerDocumentationGeneration(ctx context.Context, repositoryId, commitHash string) error
+}
+
 type SdkGenerationQueue interface {
-	Start(ctx context.Context, sdkGenerator SdkGenerator, triggerProcessor SdkTriggerProcessor, batchSize int, pollInterval time.Duration)
+	Start(ctx context.Context, sdkGenerator SdkGenerator, triggerProcessor SdkTriggerProcessor, docsGenerator DocsGenerator, batchSize int, pollInterval time.Duration)
 	Stop()
 	EnqueueSdkGenerationJobs(ctx context.Context, jobs []*SdkGenerationJobDTO) error
 	EnqueueSdkTriggerJob(ctx context.Context, job *SdkTriggerJobDTO) error
+	// EnqueueDocsGenerationJob reports false without enqueueing when the
+	// repository already has a docs build pending or processing.
+	EnqueueDocsGenerationJob(ctx context.Context, job *DocsGenerationJobDTO) (bool, error)
 	GetPendingSdkGenerationJobs(ctx context.Context, limit int) ([]*SdkGenerationJobDTO, error)
 	GetPendingSdkTriggerJobs(ctx context.Context, limit int) ([]*SdkTriggerJobDTO, error)
+	GetPendingDocsGenerationJobs(ctx context.Context, limit int) ([]*DocsGenerationJobDTO, error)
 	UpdateSdkGenerationJobStatus(ctx context.Context, jobId string, status SdkGenerationJobStatus, errorMsg *string) error
 	UpdateSdkTriggerJobStatus(ctx context.Context, jobId string, status SdkGenerationJobStatus, errorMsg *string) error
+	UpdateDocsGenerationJobStatus(ctx context.Context, jobId string, status SdkGenerationJobStatus, errorMsg *string) error
+	RenewLease(ctx context.Context, jobId string) error
+	RequeueStaleSdkGenerationJobs(ctx context.Context, leaseTimeout time.Duration) (int, error)
+	// GetLatestSdkJobStatuses returns, for each of sdks that has ever been
+	// queued, the status of its most recent job that was not cancelled.
+	GetLatestSdkJobStatuses(ctx context.Context, repositoryId string, sdks []SDK) ([]SdkJobStatusDTO, error)
 }
@@ -41,6 +41,21 @@ func (m *MockSdkGenerationQueue) EXPECT() *MockSdkGenerationQueueMockRecorder {
 	return m.recorder
 }
 
+// EnqueueDocsGenerationJob mocks base method.
+func (m *MockSdkGenerationQueue) EnqueueDocsGenerationJob(ctx context.Context, job *DocsGenerationJobDTO) (bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "EnqueueDocsGenerationJob", ctx, job)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// EnqueueDocsGenerationJob indicates an expected call of EnqueueDocsGenerationJob.
+func (mr *MockSdkGenerationQueueMockRecorder) EnqueueDocsGenerationJob(ctx, job any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "EnqueueDocsGenerationJob", reflect.TypeOf((*MockSdkGenerationQueue)(nil).EnqueueDocsGenerationJob), ctx, job)
+}
+
 // EnqueueSdkGenerationJobs mocks base method.
 func (m *MockSdkGenerationQueue) EnqueueSdkGenerationJobs(ctx context.Context, jobs []*SdkGenerationJobDTO) error {
 	m.ctrl.T.Helper()
@@ -69,6 +84,36 @@ func (mr *MockSdkGenerationQueueMockRecorder) EnqueueSdkTriggerJob(ctx, job any)
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "EnqueueSdkTriggerJob", reflect.TypeOf((*MockSdkGenerationQueue)(nil).EnqueueSdkTriggerJob), ctx, job)
 }
 
+// GetLatestSdkJobStatuses mocks base method.
+func (m *MockSdkGenerationQueue) GetLatestSdkJobStatuses(ctx context.Context, repositoryId string, sdks []SDK) ([]SdkJobStatusDTO, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetLatestSdkJobStatuses", ctx, repositoryId, sdks)
+	ret0, _ := ret[0].([]SdkJobStatusDTO)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetLatestSdkJobStatuses indicates an expected call of GetLatestSdkJobStatuses.
+func (mr *MockSdkGenerationQueueMockRecorder) GetLatestSdkJobStatuses(ctx, repositoryId, sdks any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetLatestSdkJobStatuses", reflect.TypeOf((*MockSdkGenerationQueue)(nil).GetLatestSdkJobStatuses), ctx, repositoryId, sdks)
+}
+
+// GetPendingDocsGenerationJobs mocks base method.
+func (m *MockSdkGenerationQueue) GetPendingDocsGenerationJobs(ctx context.Context, limit int) ([]*DocsGenerationJobDTO, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetPendingDocsGenerationJobs", ctx, limit)
+	ret0, _ := ret[0].([]*DocsGenerationJobDTO)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetPendingDocsGenerationJobs indicates an expected call of GetPendingDocsGenerationJobs.
+func (mr *MockSdkGenerationQueueMockRecorder) GetPendingDocsGenerationJobs(ctx, limit any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetPendingDocsGenerationJobs", reflect.TypeOf((*MockSdkGenerationQueue)(nil).GetPendingDocsGenerationJobs), ctx, limit)
+}
+
 // GetPendingSdkGenerationJobs mocks base method.
 func (m *MockSdkGenerationQueue) GetPendingSdkGenerationJobs(ctx context.Context, limit int) ([]*SdkGenerationJobDTO, error) {
 	m.ctrl.T.Helper()
@@ -99,16 +144,45 @@ func (mr *MockSdkGenerationQueueMockRecorder) GetPendingSdkTriggerJobs(ctx, limi
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetPendingSdkTriggerJobs", reflect.TypeOf((*MockSdkGenerationQueue)(nil).GetPendingSdkTriggerJobs), ctx, limit)
 }
 
+// RenewLease mocks base method.
+func (m *MockSdkGenerationQueue) RenewLease(ctx context.Context, jobId string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RenewLease", ctx, jobId)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RenewLease indicates an expected call of RenewLease.
+func (mr *MockSdkGenerationQueueMockRecorder) RenewLease(ctx, jobId any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RenewLease", reflect.TypeOf((*MockSdkGenerationQueue)(nil).RenewLease), ctx, jobId)
+}
+
+// RequeueStaleSdkGenerationJobs mocks base method.
+func (m *MockSdkGenerationQueue) RequeueStaleSdkGenerationJobs(ctx context.Context, leaseTimeout time.Duration) (int, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RequeueStaleSdkGenerationJobs", ctx, leaseTimeout)
+	ret0, _ := ret[0].(int)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// RequeueStaleSdkGenerationJobs indicates an expected call of RequeueStaleSdkGenerationJobs.
+func (mr *MockSdkGenerationQueueMockRecorder) RequeueStaleSdkGenerationJobs(ctx, leaseTimeout any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RequeueStaleSdkGenerationJobs", reflect.TypeOf((*MockSdkGenerationQueue)(nil).RequeueStaleSdkGenerationJobs), ctx, leaseTimeout)
+}
+
 // Start mocks base method.
-func (m *MockSdkGenerationQueue) Start(ctx context.Context, sdkGenerator SdkGenerator, triggerProcessor SdkTriggerProcessor, batchSize int, pollInterval time.Duration) {
+func (m *MockSdkGenerationQueue) Start(ctx context.Context, sdkGenerator SdkGenerator, triggerProcessor SdkTriggerProcessor, docsGenerator DocsGenerator, batchSize int, pollInterval time.Duration) {
 	m.ctrl.T.Helper()
-	m.ctrl.Call(m, "Start", ctx, sdkGenerator, triggerProcessor, batchSize, pollInterval)
+	m.ctrl.Call(m, "Start", ctx, sdkGenerator, triggerProcessor, docsGenerator, batchSize, pollInterval)
 }
 
 // Start indicates an expected call of Start.
-func (mr *MockSdkGenerationQueueMockRecorder) Start(ctx, sdkGenerator, triggerProcessor, batchSize, pollInterval any) *gomock.Call {
+func (mr *MockSdkGenerationQueueMockRecorder) Start(ctx, sdkGenerator, triggerProcessor, docsGenerator, batchSize, pollInterval any) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Start", reflect.TypeOf((*MockSdkGenerationQueue)(nil).Start), ctx, sdkGenerator, triggerProcessor, batchSize, pollInterval)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Start", reflect.TypeOf((*MockSdkGenerationQueue)(nil).Start), ctx, sdkGenerator, triggerProcessor, docsGenerator, batchSize, pollInterval)
 }
 
 // Stop mocks base method.
@@ -123,6 +197,20 @@ func (mr *MockSdkGenerationQueueMockRecorder) Stop() *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Stop", reflect.TypeOf((*MockSdkGenerationQueue)(nil).Stop))
 }
 
+// UpdateDocsGenerationJobStatus mocks base method.
+func (m *MockSdkGenerationQueue) UpdateDocsGenerationJobStatus(ctx context.Context, jobId string, status SdkGenerationJobStatus, errorMsg *string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateDocsGenerationJobStatus", ctx, jobId, status, errorMsg)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpdateDocsGenerationJobStatus indicates an expected call of UpdateDocsGenerationJobStatus.
+func (mr *MockSdkGenerationQueueMockRecorder) UpdateDocsGenerationJobStatus(ctx, jobId, status, errorMsg any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateDocsGenerationJobStatus", reflect.TypeOf((*MockSdkGenerationQueue)(nil).UpdateDocsGenerationJobStatus), ctx, jobId, status, errorMsg)
+}
+
 // UpdateSdkGenerationJobStatus mocks base method.
 func (m *MockSdkGenerationQueue) UpdateSdkGenerationJobStatus(ctx context.Context, jobId string, status SdkGenerationJobStatus, errorMsg *string) error {
 	m.ctrl.T.Helper()
@@ -0,0 +1,52 @@
+package registry
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"connectrpc.com/connect"
+	"go.uber.org/zap"
+)
+
+// RemoveRepositoryFiles deletes what a deleted repository left on disk: its
+// git directory, if still present, and the SDKs and documentation generated
+// for it. It is called before the repository record is dropped for good, so a
+// failure leaves the record in place for the next attempt.
+func (s *service) RemoveRepositoryFiles(ctx context.Context, organizationId, repositoryId, repoPath string) error {
+	if organizationId == "" || repositoryId == "" {
+		return connect.NewError(connect.CodeInvalidArgument, errors.New("organization and repository ids are required"))
+	}
+
+	if repoPath != "" {
+		repoPath = filepath.Clean(repoPath)
+		if filepath.Dir(repoPath) != filepath.Clean(s.rootPath) {
+			return connect.NewError(connect.CodeInvalidArgument, fmt.Errorf("%q is not a directory under the repository root", repoPath))
+		}
+
+		unlock := s.pathLocks.lock(repoPath)
+		err := os.RemoveAll(repoPath)
+		unlock()
+		if err != nil {
+			return connect.NewError(connect.CodeInternal, fmt.Errorf("failed to remove repository directory: %w", err))
+		}
+	}
+
+	outputRoots := []string{s.sdkPath}
+	if s.docsPath != "" && s.docsPath != s.sdkPath {
+		outputRoots = append(outputRoots, s.docsPath)
+	}
+	for _, root := range outputRoots {
+		if err := os.RemoveAll(filepath.Join(root, organizationId, repositoryId)); err != nil {
+			return connect.NewError(connect.CodeInternal, fmt.Errorf("failed to remove generated output: %w", err))
+		}
+	}
+
+	zap.L().Debug("removed repository files",
+		zap.String("id", repositoryId),
+		zap.String("path", repoPath))
+
+	return nil
+}
@@ -0,0 +1,72 @@
+package registry
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"connectrpc.com/connect"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestService_RemoveRepositoryFiles(t *testing.T) {
+	newService := func(t *testing.T) *service {
+		base := t.TempDir()
+		return &service{
+			rootPath: filepath.Join(base, "repos"),
+			sdkPath:  filepath.Join(base, "sdk"),
+			docsPath: filepath.Join(base, "docs"),
+		}
+	}
+	mkdir := func(t *testing.T, path string) {
+		t.Helper()
+		require.NoError(t, os.MkdirAll(path, 0o750))
+	}
+
+	t.Run("removes the repository directory and its generated output", func(t *testing.T) {
+		svc := newService(t)
+		repoPath := filepath.Join(svc.rootPath, "repo-1")
+		otherRepo := filepath.Join(svc.rootPath, "repo-2")
+		sdkOutput := filepath.Join(svc.sdkPath, "org-1", "repo-1", "abc123", "go-protobuf")
+		docsOutput := filepath.Join(svc.docsPath, "org-1", "repo-1", "abc123", "docs")
+		otherOutput := filepath.Join(svc.sdkPath, "org-1", "repo-2")
+		for _, dir := range []string{repoPath, otherRepo, sdkOutput, docsOutput, otherOutput} {
+			mkdir(t, dir)
+		}
+
+		require.NoError(t, svc.RemoveRepositoryFiles(context.Background(), "org-1", "repo-1", repoPath))
+
+		assert.NoDirExists(t, repoPath)
+		assert.NoDirExists(t, filepath.Join(svc.sdkPath, "org-1", "repo-1"))
+		assert.NoDirExists(t, filepath.Join(svc.docsPath, "org-1", "repo-1"))
+		assert.DirExists(t, otherRepo)
+		assert.DirExists(t, otherOutput)
+	})
+
+	t.Run("succeeds when nothing is left on disk", func(t *testing.T) {
+		svc := newService(t)
+
+		err := svc.RemoveRepositoryFiles(context.Background(), "org-1", "repo-1", filepath.Join(svc.rootPath, "repo-1"))
+		assert.NoError(t, err)
+	})
+
+	t.Run("refuses paths outside the repository root", func(t *testing.T) {
+		svc := newService(t)
+		outside := t.TempDir()
+
+		err := svc.RemoveRepositoryFiles(context.Background(), "org-1", "repo-1", outside)
+		assert.Equal(t, connect.CodeInvalidArgument, connect.CodeOf(err))
+		assert.DirExists(t, outside)
+	})
+
+	t.Run("requires both ids", func(t *testing.T) {
+		svc := newService(t)
+		mkdir(t, filepath.Join(svc.sdkPath, "org-1"))
+
+		err := svc.RemoveRepositoryFiles(context.Background(), "org-1", "", "")
+		assert.Equal(t, connect.CodeInvalidArgument, connect.CodeOf(err))
+		assert.DirExists(t, filepath.Join(svc.sdkPath, "org-1"))
+	})
+}
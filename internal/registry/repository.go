@@ -2,28 +2,54 @@ package registry
 
 import (
 	"context"
+	"time"
 
 	registryv1 "buf.build/gen/go/hasir/hasir/protocolbuffers/go/registry/v1"
+
+	"hasir-api/pkg/proto"
 )
 
 type Repository interface {
 	CreateRepository(ctx context.Context, repo *RepositoryDTO) error
 	GetRepositoryByName(ctx context.Context, name string) (*RepositoryDTO, error)
 	GetRepositoryById(ctx context.Context, id string) (*RepositoryDTO, error)
+	GetRepositoryByPath(ctx context.Context, path string) (*RepositoryDTO, error)
 	GetRepositories(ctx context.Context, page, pageSize int) (*[]RepositoryDTO, error)
 	GetRepositoriesByOrganizationId(ctx context.Context, organizationId string) (*[]RepositoryDTO, error)
+	GetForks(ctx context.Context, repoId, userId string) (*[]RepositoryDTO, error)
+	GetOrganizationRepositoriesCount(ctx context.Context, organizationId string, publicOnly bool) (int, error)
+	GetRecentOrganizationRepositories(ctx context.Context, organizationId string, publicOnly bool, limit int) (*[]RepositoryDTO, error)
 	GetRepositoriesByUser(ctx context.Context, userId string, page, pageSize int) (*[]RepositoryDTO, error)
 	GetRepositoriesByUserCount(ctx context.Context, userId string) (int, error)
 	GetRepositoriesByUserAndOrganization(ctx context.Context, userId, organizationId string, page, pageSize int) (*[]RepositoryDTO, error)
 	GetRepositoriesByUserAndOrganizationCount(ctx context.Context, userId, organizationId string) (int, error)
+	GetOrganizationVisibility(ctx context.Context, organizationId string) (proto.Visibility, error)
+	GetOrganizationDefaultSdks(ctx context.Context, organizationId string) ([]SDK, error)
 	UpdateRepository(ctx context.Context, repo *RepositoryDTO) error
+	SetRepositoryArchived(ctx context.Context, id string, archived bool) error
+	TransferRepository(ctx context.Context, id, targetOrganizationId string) error
+	SetRepositoriesVisibilityByOrganizationId(ctx context.Context, organizationId string, visibility proto.Visibility) (int, error)
 	DeleteRepository(ctx context.Context, id string) error
-	DeleteRepositoriesByOrganizationId(ctx context.Context, organizationId string) error
 	UpdateSdkPreferences(ctx context.Context, repositoryId string, preferences []SdkPreferencesDTO) error
 	GetSdkPreferences(ctx context.Context, repositoryId string) ([]SdkPreferencesDTO, error)
+	GetRepositoryWithSdkPreferences(ctx context.Context, id string) (*RepositoryDTO, []SdkPreferencesDTO, error)
 	GetSdkPreferencesByRepositoryIds(ctx context.Context, repositoryIds []string) (map[string][]SdkPreferencesDTO, error)
-	GetCommits(ctx context.Context, repoPath string, page, pageSize int) ([]*registryv1.Commit, int, error)
+	SetLastGeneratedCommit(ctx context.Context, repositoryId string, sdk SDK, commitHash string) error
+	HasSdkInputChanges(ctx context.Context, repoPath, fromCommit, toCommit string) (bool, error)
+	GetCommits(ctx context.Context, repoPath, ref string, page, pageSize int) ([]*registryv1.Commit, int, error)
 	GetRecentCommit(ctx context.Context, repoPath string) (*registryv1.Commit, error)
-	GetFileTree(ctx context.Context, repoPath string, subPath *string) (*registryv1.GetFileTreeResponse, error)
-	GetFilePreview(ctx context.Context, repoPath, filePath string) (*registryv1.GetFilePreviewResponse, error)
+	GetFileTree(ctx context.Context, repoPath, ref string, subPath *string, page FileTreePage) (*FileTree, error)
+	GetFilePreview(ctx context.Context, repoPath, ref, filePath string) (*FilePreview, error)
+	ListRefs(ctx context.Context, repoPath string) ([]RefInfo, []RefInfo, error)
+	GetBranchDivergence(ctx context.Context, repoPath string) (string, []BranchDivergence, error)
+	IsRepositoryEmpty(ctx context.Context, repoPath string) (bool, error)
+	GetContributors(ctx context.Context, repoPath string) ([]Contributor, error)
+	GetCommitActivity(ctx context.Context, repoPath string, bucket CommitActivityBucket, from, to time.Time) ([]CommitActivity, error)
+	GetCommitRange(ctx context.Context, repoPath, baseRef, headRef string, limit int) (*CommitRange, error)
+	GetUsersByEmails(ctx context.Context, emails []string) ([]UserIdentity, error)
+	RecordPush(ctx context.Context, push *PushDTO) error
+	GetUserActivityFeed(ctx context.Context, userId string, page, pageSize int) ([]ActivityDTO, error)
+	CreateDeployToken(ctx context.Context, token *DeployTokenDTO) error
+	GetDeployToken(ctx context.Context, token string) (*DeployTokenDTO, error)
+	RevokeDeployToken(ctx context.Context, repositoryId, tokenId string) error
 }
@@ -12,9 +12,11 @@ package registry
 import (
 	context "context"
 	reflect "reflect"
+	time "time"
 
 	registryv1 "buf.build/gen/go/hasir/hasir/protocolbuffers/go/registry/v1"
 	gomock "go.uber.org/mock/gomock"
+	proto "hasir-api/pkg/proto"
 )
 
 // MockRepository is a mock of Repository interface.
@@ -41,32 +43,32 @@ func (m *MockRepository) EXPECT() *MockRepositoryMockRecorder {
 	return m.recorder
 }
 
-// CreateRepository mocks base method.
-func (m *MockRepository) CreateRepository(ctx context.Context, repo *RepositoryDTO) error {
+// CreateDeployToken mocks base method.
+func (m *MockRepository) CreateDeployToken(ctx context.Context, token *DeployTokenDTO) error {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "CreateRepository", ctx, repo)
+	ret := m.ctrl.Call(m, "CreateDeployToken", ctx, token)
 	ret0, _ := ret[0].(error)
 	return ret0
 }
 
-// CreateRepository indicates an expected call of CreateRepository.
-func (mr *MockRepositoryMockRecorder) CreateRepository(ctx, repo any) *gomock.Call {
+// CreateDeployToken indicates an expected call of CreateDeployToken.
+func (mr *MockRepositoryMockRecorder) CreateDeployToken(ctx, token any) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateRepository", reflect.TypeOf((*MockRepository)(nil).CreateRepository), ctx, repo)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateDeployToken", reflect.TypeOf((*MockRepository)(nil).CreateDeployToken), ctx, token)
 }
 
-// DeleteRepositoriesByOrganizationId mocks base method.
-func (m *MockRepository) DeleteRepositoriesByOrganizationId(ctx context.Context, organizationId string) error {
+// CreateRepository mocks base method.
+func (m *MockRepository) CreateRepository(ctx context.Context, repo *RepositoryDTO) error {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "DeleteRepositoriesByOrganizationId", ctx, organizationId)
+	ret := m.ctrl.Call(m, "CreateRepository", ctx, repo)
 	ret0, _ := ret[0].(error)
 	return ret0
 }
 
-// DeleteRepositoriesByOrganizationId indicates an expected call of DeleteRepositoriesByOrganizationId.
-func (mr *MockRepositoryMockRecorder) DeleteRepositoriesByOrganizationId(ctx, organizationId any) *gomock.Call {
+// CreateRepository indicates an expected call of CreateRepository.
+func (mr *MockRepositoryMockRecorder) CreateRepository(ctx, repo any) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteRepositoriesByOrganizationId", reflect.TypeOf((*MockRepository)(nil).DeleteRepositoriesByOrganizationId), ctx, organizationId)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateRepository", reflect.TypeOf((*MockRepository)(nil).CreateRepository), ctx, repo)
 }
 
 // DeleteRepository mocks base method.
@@ -83,10 +85,56 @@ func (mr *MockRepositoryMockRecorder) DeleteRepository(ctx, id any) *gomock.Call
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteRepository", reflect.TypeOf((*MockRepository)(nil).DeleteRepository), ctx, id)
 }
 
+// GetBranchDivergence mocks base method.
+func (m *MockRepository) GetBranchDivergence(ctx context.Context, repoPath string) (string, []BranchDivergence, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetBranchDivergence", ctx, repoPath)
+	ret0, _ := ret[0].(string)
+	ret1, _ := ret[1].([]BranchDivergence)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
+}
+
+// GetBranchDivergence indicates an expected call of GetBranchDivergence.
+func (mr *MockRepositoryMockRecorder) GetBranchDivergence(ctx, repoPath any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetBranchDivergence", reflect.TypeOf((*MockRepository)(nil).GetBranchDivergence), ctx, repoPath)
+}
+
+// GetCommitActivity mocks base method.
+func (m *MockRepository) GetCommitActivity(ctx context.Context, repoPath string, bucket CommitActivityBucket, from, to time.Time) ([]CommitActivity, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetCommitActivity", ctx, repoPath, bucket, from, to)
+	ret0, _ := ret[0].([]CommitActivity)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetCommitActivity indicates an expected call of GetCommitActivity.
+func (mr *MockRepositoryMockRecorder) GetCommitActivity(ctx, repoPath, bucket, from, to any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetCommitActivity", reflect.TypeOf((*MockRepository)(nil).GetCommitActivity), ctx, repoPath, bucket, from, to)
+}
+
+// GetCommitRange mocks base method.
+func (m *MockRepository) GetCommitRange(ctx context.Context, repoPath, baseRef, headRef string, limit int) (*CommitRange, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetCommitRange", ctx, repoPath, baseRef, headRef, limit)
+	ret0, _ := ret[0].(*CommitRange)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetCommitRange indicates an expected call of GetCommitRange.
+func (mr *MockRepositoryMockRecorder) GetCommitRange(ctx, repoPath, baseRef, headRef, limit any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetCommitRange", reflect.TypeOf((*MockRepository)(nil).GetCommitRange), ctx, repoPath, baseRef, headRef, limit)
+}
+
 // GetCommits mocks base method.
-func (m *MockRepository) GetCommits(ctx context.Context, repoPath string, page, pageSize int) ([]*registryv1.Commit, int, error) {
+func (m *MockRepository) GetCommits(ctx context.Context, repoPath, ref string, page, pageSize int) ([]*registryv1.Commit, int, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "GetCommits", ctx, repoPath, page, pageSize)
+	ret := m.ctrl.Call(m, "GetCommits", ctx, repoPath, ref, page, pageSize)
 	ret0, _ := ret[0].([]*registryv1.Commit)
 	ret1, _ := ret[1].(int)
 	ret2, _ := ret[2].(error)
@@ -94,39 +142,129 @@ func (m *MockRepository) GetCommits(ctx context.Context, repoPath string, page,
 }
 
 // GetCommits indicates an expected call of GetCommits.
-func (mr *MockRepositoryMockRecorder) GetCommits(ctx, repoPath, page, pageSize any) *gomock.Call {
+func (mr *MockRepositoryMockRecorder) GetCommits(ctx, repoPath, ref, page, pageSize any) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetCommits", reflect.TypeOf((*MockRepository)(nil).GetCommits), ctx, repoPath, page, pageSize)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetCommits", reflect.TypeOf((*MockRepository)(nil).GetCommits), ctx, repoPath, ref, page, pageSize)
+}
+
+// GetContributors mocks base method.
+func (m *MockRepository) GetContributors(ctx context.Context, repoPath string) ([]Contributor, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetContributors", ctx, repoPath)
+	ret0, _ := ret[0].([]Contributor)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetContributors indicates an expected call of GetContributors.
+func (mr *MockRepositoryMockRecorder) GetContributors(ctx, repoPath any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetContributors", reflect.TypeOf((*MockRepository)(nil).GetContributors), ctx, repoPath)
+}
+
+// GetDeployToken mocks base method.
+func (m *MockRepository) GetDeployToken(ctx context.Context, token string) (*DeployTokenDTO, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetDeployToken", ctx, token)
+	ret0, _ := ret[0].(*DeployTokenDTO)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetDeployToken indicates an expected call of GetDeployToken.
+func (mr *MockRepositoryMockRecorder) GetDeployToken(ctx, token any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetDeployToken", reflect.TypeOf((*MockRepository)(nil).GetDeployToken), ctx, token)
 }
 
 // GetFilePreview mocks base method.
-func (m *MockRepository) GetFilePreview(ctx context.Context, repoPath, filePath string) (*registryv1.GetFilePreviewResponse, error) {
+func (m *MockRepository) GetFilePreview(ctx context.Context, repoPath, ref, filePath string) (*FilePreview, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "GetFilePreview", ctx, repoPath, filePath)
-	ret0, _ := ret[0].(*registryv1.GetFilePreviewResponse)
+	ret := m.ctrl.Call(m, "GetFilePreview", ctx, repoPath, ref, filePath)
+	ret0, _ := ret[0].(*FilePreview)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
 // GetFilePreview indicates an expected call of GetFilePreview.
-func (mr *MockRepositoryMockRecorder) GetFilePreview(ctx, repoPath, filePath any) *gomock.Call {
+func (mr *MockRepositoryMockRecorder) GetFilePreview(ctx, repoPath, ref, filePath any) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetFilePreview", reflect.TypeOf((*MockRepository)(nil).GetFilePreview), ctx, repoPath, filePath)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetFilePreview", reflect.TypeOf((*MockRepository)(nil).GetFilePreview), ctx, repoPath, ref, filePath)
 }
 
 // GetFileTree mocks base method.
-func (m *MockRepository) GetFileTree(ctx context.Context, repoPath string, subPath *string) (*registryv1.GetFileTreeResponse, error) {
+func (m *MockRepository) GetFileTree(ctx context.Context, repoPath, ref string, subPath *string, page FileTreePage) (*FileTree, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "GetFileTree", ctx, repoPath, subPath)
-	ret0, _ := ret[0].(*registryv1.GetFileTreeResponse)
+	ret := m.ctrl.Call(m, "GetFileTree", ctx, repoPath, ref, subPath, page)
+	ret0, _ := ret[0].(*FileTree)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
 // GetFileTree indicates an expected call of GetFileTree.
-func (mr *MockRepositoryMockRecorder) GetFileTree(ctx, repoPath, subPath any) *gomock.Call {
+func (mr *MockRepositoryMockRecorder) GetFileTree(ctx, repoPath, ref, subPath, page any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetFileTree", reflect.TypeOf((*MockRepository)(nil).GetFileTree), ctx, repoPath, ref, subPath, page)
+}
+
+// GetForks mocks base method.
+func (m *MockRepository) GetForks(ctx context.Context, repoId, userId string) (*[]RepositoryDTO, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetForks", ctx, repoId, userId)
+	ret0, _ := ret[0].(*[]RepositoryDTO)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetForks indicates an expected call of GetForks.
+func (mr *MockRepositoryMockRecorder) GetForks(ctx, repoId, userId any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetForks", reflect.TypeOf((*MockRepository)(nil).GetForks), ctx, repoId, userId)
+}
+
+// GetOrganizationDefaultSdks mocks base method.
+func (m *MockRepository) GetOrganizationDefaultSdks(ctx context.Context, organizationId string) ([]SDK, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetOrganizationDefaultSdks", ctx, organizationId)
+	ret0, _ := ret[0].([]SDK)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetOrganizationDefaultSdks indicates an expected call of GetOrganizationDefaultSdks.
+func (mr *MockRepositoryMockRecorder) GetOrganizationDefaultSdks(ctx, organizationId any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetOrganizationDefaultSdks", reflect.TypeOf((*MockRepository)(nil).GetOrganizationDefaultSdks), ctx, organizationId)
+}
+
+// GetOrganizationRepositoriesCount mocks base method.
+func (m *MockRepository) GetOrganizationRepositoriesCount(ctx context.Context, organizationId string, publicOnly bool) (int, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetOrganizationRepositoriesCount", ctx, organizationId, publicOnly)
+	ret0, _ := ret[0].(int)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetOrganizationRepositoriesCount indicates an expected call of GetOrganizationRepositoriesCount.
+func (mr *MockRepositoryMockRecorder) GetOrganizationRepositoriesCount(ctx, organizationId, publicOnly any) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetFileTree", reflect.TypeOf((*MockRepository)(nil).GetFileTree), ctx, repoPath, subPath)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetOrganizationRepositoriesCount", reflect.TypeOf((*MockRepository)(nil).GetOrganizationRepositoriesCount), ctx, organizationId, publicOnly)
+}
+
+// GetOrganizationVisibility mocks base method.
+func (m *MockRepository) GetOrganizationVisibility(ctx context.Context, organizationId string) (proto.Visibility, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetOrganizationVisibility", ctx, organizationId)
+	ret0, _ := ret[0].(proto.Visibility)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetOrganizationVisibility indicates an expected call of GetOrganizationVisibility.
+func (mr *MockRepositoryMockRecorder) GetOrganizationVisibility(ctx, organizationId any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetOrganizationVisibility", reflect.TypeOf((*MockRepository)(nil).GetOrganizationVisibility), ctx, organizationId)
 }
 
 // GetRecentCommit mocks base method.
@@ -144,6 +282,21 @@ func (mr *MockRepositoryMockRecorder) GetRecentCommit(ctx, repoPath any) *gomock
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetRecentCommit", reflect.TypeOf((*MockRepository)(nil).GetRecentCommit), ctx, repoPath)
 }
 
+// GetRecentOrganizationRepositories mocks base method.
+func (m *MockRepository) GetRecentOrganizationRepositories(ctx context.Context, organizationId string, publicOnly bool, limit int) (*[]RepositoryDTO, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetRecentOrganizationRepositories", ctx, organizationId, publicOnly, limit)
+	ret0, _ := ret[0].(*[]RepositoryDTO)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetRecentOrganizationRepositories indicates an expected call of GetRecentOrganizationRepositories.
+func (mr *MockRepositoryMockRecorder) GetRecentOrganizationRepositories(ctx, organizationId, publicOnly, limit any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetRecentOrganizationRepositories", reflect.TypeOf((*MockRepository)(nil).GetRecentOrganizationRepositories), ctx, organizationId, publicOnly, limit)
+}
+
 // GetRepositories mocks base method.
 func (m *MockRepository) GetRepositories(ctx context.Context, page, pageSize int) (*[]RepositoryDTO, error) {
 	m.ctrl.T.Helper()
@@ -264,6 +417,37 @@ func (mr *MockRepositoryMockRecorder) GetRepositoryByName(ctx, name any) *gomock
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetRepositoryByName", reflect.TypeOf((*MockRepository)(nil).GetRepositoryByName), ctx, name)
 }
 
+// GetRepositoryByPath mocks base method.
+func (m *MockRepository) GetRepositoryByPath(ctx context.Context, path string) (*RepositoryDTO, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetRepositoryByPath", ctx, path)
+	ret0, _ := ret[0].(*RepositoryDTO)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetRepositoryByPath indicates an expected call of GetRepositoryByPath.
+func (mr *MockRepositoryMockRecorder) GetRepositoryByPath(ctx, path any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetRepositoryByPath", reflect.TypeOf((*MockRepository)(nil).GetRepositoryByPath), ctx, path)
+}
+
+// GetRepositoryWithSdkPreferences mocks base method.
+func (m *MockRepository) GetRepositoryWithSdkPreferences(ctx context.Context, id string) (*RepositoryDTO, []SdkPreferencesDTO, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetRepositoryWithSdkPreferences", ctx, id)
+	ret0, _ := ret[0].(*RepositoryDTO)
+	ret1, _ := ret[1].([]SdkPreferencesDTO)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
+}
+
+// GetRepositoryWithSdkPreferences indicates an expected call of GetRepositoryWithSdkPreferences.
+func (mr *MockRepositoryMockRecorder) GetRepositoryWithSdkPreferences(ctx, id any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetRepositoryWithSdkPreferences", reflect.TypeOf((*MockRepository)(nil).GetRepositoryWithSdkPreferences), ctx, id)
+}
+
 // GetSdkPreferences mocks base method.
 func (m *MockRepository) GetSdkPreferences(ctx context.Context, repositoryId string) ([]SdkPreferencesDTO, error) {
 	m.ctrl.T.Helper()
@@ -294,6 +478,167 @@ func (mr *MockRepositoryMockRecorder) GetSdkPreferencesByRepositoryIds(ctx, repo
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetSdkPreferencesByRepositoryIds", reflect.TypeOf((*MockRepository)(nil).GetSdkPreferencesByRepositoryIds), ctx, repositoryIds)
 }
 
+// GetUserActivityFeed mocks base method.
+func (m *MockRepository) GetUserActivityFeed(ctx context.Context, userId string, page, pageSize int) ([]ActivityDTO, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetUserActivityFeed", ctx, userId, page, pageSize)
+	ret0, _ := ret[0].([]ActivityDTO)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetUserActivityFeed indicates an expected call of GetUserActivityFeed.
+func (mr *MockRepositoryMockRecorder) GetUserActivityFeed(ctx, userId, page, pageSize any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetUserActivityFeed", reflect.TypeOf((*MockRepository)(nil).GetUserActivityFeed), ctx, userId, page, pageSize)
+}
+
+// GetUsersByEmails mocks base method.
+func (m *MockRepository) GetUsersByEmails(ctx context.Context, emails []string) ([]UserIdentity, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetUsersByEmails", ctx, emails)
+	ret0, _ := ret[0].([]UserIdentity)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetUsersByEmails indicates an expected call of GetUsersByEmails.
+func (mr *MockRepositoryMockRecorder) GetUsersByEmails(ctx, emails any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetUsersByEmails", reflect.TypeOf((*MockRepository)(nil).GetUsersByEmails), ctx, emails)
+}
+
+// HasSdkInputChanges mocks base method.
+func (m *MockRepository) HasSdkInputChanges(ctx context.Context, repoPath, fromCommit, toCommit string) (bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "HasSdkInputChanges", ctx, repoPath, fromCommit, toCommit)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// HasSdkInputChanges indicates an expected call of HasSdkInputChanges.
+func (mr *MockRepositoryMockRecorder) HasSdkInputChanges(ctx, repoPath, fromCommit, toCommit any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "HasSdkInputChanges", reflect.TypeOf((*MockRepository)(nil).HasSdkInputChanges), ctx, repoPath, fromCommit, toCommit)
+}
+
+// IsRepositoryEmpty mocks base method.
+func (m *MockRepository) IsRepositoryEmpty(ctx context.Context, repoPath string) (bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "IsRepositoryEmpty", ctx, repoPath)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// IsRepositoryEmpty indicates an expected call of IsRepositoryEmpty.
+func (mr *MockRepositoryMockRecorder) IsRepositoryEmpty(ctx, repoPath any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "IsRepositoryEmpty", reflect.TypeOf((*MockRepository)(nil).IsRepositoryEmpty), ctx, repoPath)
+}
+
+// ListRefs mocks base method.
+func (m *MockRepository) ListRefs(ctx context.Context, repoPath string) ([]RefInfo, []RefInfo, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListRefs", ctx, repoPath)
+	ret0, _ := ret[0].([]RefInfo)
+	ret1, _ := ret[1].([]RefInfo)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
+}
+
+// ListRefs indicates an expected call of ListRefs.
+func (mr *MockRepositoryMockRecorder) ListRefs(ctx, repoPath any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListRefs", reflect.TypeOf((*MockRepository)(nil).ListRefs), ctx, repoPath)
+}
+
+// RecordPush mocks base method.
+func (m *MockRepository) RecordPush(ctx context.Context, push *PushDTO) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RecordPush", ctx, push)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RecordPush indicates an expected call of RecordPush.
+func (mr *MockRepositoryMockRecorder) RecordPush(ctx, push any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RecordPush", reflect.TypeOf((*MockRepository)(nil).RecordPush), ctx, push)
+}
+
+// RevokeDeployToken mocks base method.
+func (m *MockRepository) RevokeDeployToken(ctx context.Context, repositoryId, tokenId string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RevokeDeployToken", ctx, repositoryId, tokenId)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RevokeDeployToken indicates an expected call of RevokeDeployToken.
+func (mr *MockRepositoryMockRecorder) RevokeDeployToken(ctx, repositoryId, tokenId any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RevokeDeployToken", reflect.TypeOf((*MockRepository)(nil).RevokeDeployToken), ctx, repositoryId, tokenId)
+}
+
+// SetLastGeneratedCommit mocks base method.
+func (m *MockRepository) SetLastGeneratedCommit(ctx context.Context, repositoryId string, sdk SDK, commitHash string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SetLastGeneratedCommit", ctx, repositoryId, sdk, commitHash)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SetLastGeneratedCommit indicates an expected call of SetLastGeneratedCommit.
+func (mr *MockRepositoryMockRecorder) SetLastGeneratedCommit(ctx, repositoryId, sdk, commitHash any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetLastGeneratedCommit", reflect.TypeOf((*MockRepository)(nil).SetLastGeneratedCommit), ctx, repositoryId, sdk, commitHash)
+}
+
+// SetRepositoriesVisibilityByOrganizationId mocks base method.
+func (m *MockRepository) SetRepositoriesVisibilityByOrganizationId(ctx context.Context, organizationId string, visibility proto.Visibility) (int, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SetRepositoriesVisibilityByOrganizationId", ctx, organizationId, visibility)
+	ret0, _ := ret[0].(int)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// SetRepositoriesVisibilityByOrganizationId indicates an expected call of SetRepositoriesVisibilityByOrganizationId.
+func (mr *MockRepositoryMockRecorder) SetRepositoriesVisibilityByOrganizationId(ctx, organizationId, visibility any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetRepositoriesVisibilityByOrganizationId", reflect.TypeOf((*MockRepository)(nil).SetRepositoriesVisibilityByOrganizationId), ctx, organizationId, visibility)
+}
+
+// SetRepositoryArchived mocks base method.
+func (m *MockRepository) SetRepositoryArchived(ctx context.Context, id string, archived bool) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SetRepositoryArchived", ctx, id, archived)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SetRepositoryArchived indicates an expected call of SetRepositoryArchived.
+func (mr *MockRepositoryMockRecorder) SetRepositoryArchived(ctx, id, archived any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetRepositoryArchived", reflect.TypeOf((*MockRepository)(nil).SetRepositoryArchived), ctx, id, archived)
+}
+
+// TransferRepository mocks base method.
+func (m *MockRepository) TransferRepository(ctx context.Context, id, targetOrganizationId string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "TransferRepository", ctx, id, targetOrganizationId)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// TransferRepository indicates an expected call of TransferRepository.
+func (mr *MockRepositoryMockRecorder) TransferRepository(ctx, id, targetOrganizationId any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "TransferRepository", reflect.TypeOf((*MockRepository)(nil).TransferRepository), ctx, id, targetOrganizationId)
+}
+
 // UpdateRepository mocks base method.
 func (m *MockRepository) UpdateRepository(ctx context.Context, repo *RepositoryDTO) error {
 	m.ctrl.T.Helper()
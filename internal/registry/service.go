@@ -5,10 +5,10 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
-	"math"
 	"os"
 	"os/exec"
 	"path/filepath"
+	"slices"
 	"strings"
 	"time"
 
@@ -17,35 +17,75 @@ import (
 	"github.com/go-git/go-git/v5"
 	"github.com/google/uuid"
 	"go.uber.org/zap"
-	"golang.org/x/sync/errgroup"
 
 	"hasir-api/pkg/authentication"
 	"hasir-api/pkg/authorization"
 	"hasir-api/pkg/config"
+	"hasir-api/pkg/naming"
+	"hasir-api/pkg/pagination"
+	"hasir-api/pkg/postgres"
 	"hasir-api/pkg/proto"
 	"hasir-api/pkg/sdkgenerator"
 )
 
 const DefaultReposPath = "./repos"
 
+// maxFileTreePageLimit caps how many entries one paginated GetFileTree call
+// returns.
+const maxFileTreePageLimit = 1000
+
+var (
+	ErrTemplateNotConfigured  = connect.NewError(connect.CodeFailedPrecondition, errors.New("repository templates are not configured"))
+	ErrRepositoryArchived     = connect.NewError(connect.CodeFailedPrecondition, errors.New("repository is archived and read-only; unarchive it to push"))
+	ErrPublicRepoInPrivateOrg = connect.NewError(connect.CodeFailedPrecondition, errors.New("public repositories are not allowed in private organizations"))
+	ErrRepositoryEmpty        = connect.NewError(connect.CodeFailedPrecondition, errors.New("repository is empty: nothing has been pushed yet"))
+)
+
 type Service interface {
 	SdkGenerator
 	SdkTriggerProcessor
-	CreateRepository(ctx context.Context, req *registryv1.CreateRepositoryRequest) error
-	GetRepository(ctx context.Context, req *registryv1.GetRepositoryRequest) (*registryv1.Repository, error)
-	GetRepositories(ctx context.Context, organizationId *string, page, pageSize int) (*registryv1.GetRepositoriesResponse, error)
+	CreateRepository(ctx context.Context, req *registryv1.CreateRepositoryRequest, opts CreateRepositoryOptions) error
+	GetRepository(ctx context.Context, req *registryv1.GetRepositoryRequest, opts GetRepositoryOptions) (*RepositoryDetails, error)
+	GetRepositories(ctx context.Context, organizationId *string, page, pageSize int) (*RepositoryList, error)
 	UpdateRepository(ctx context.Context, req *registryv1.UpdateRepositoryRequest) error
+	SetRepositoryArchived(ctx context.Context, repoId string, archived bool) error
+	SetPushLint(ctx context.Context, repoId string, enabled bool) error
+	TransferRepository(ctx context.Context, repoId, targetOrgId string) error
+	SetOrganizationReposVisibility(ctx context.Context, organizationId string, visibility proto.Visibility) (int, error)
 	DeleteRepository(ctx context.Context, req *registryv1.DeleteRepositoryRequest) error
-	DeleteRepositoriesByOrganization(ctx context.Context, organizationId string) error
+	DeleteRepositoriesByOrganization(ctx context.Context, organizationId string, deleteOrganization func(context.Context) error) error
 	UpdateSdkPreferences(ctx context.Context, req *registryv1.UpdateSdkPreferencesRequest) error
-	GetCommits(ctx context.Context, req *registryv1.GetCommitsRequest) (*registryv1.GetCommitsResponse, error)
-	GetRecentCommit(ctx context.Context, req *registryv1.GetRecentCommitRequest) (*registryv1.Commit, error)
-	GetFileTree(ctx context.Context, req *registryv1.GetFileTreeRequest) (*registryv1.GetFileTreeResponse, error)
-	GetFilePreview(ctx context.Context, req *registryv1.GetFilePreviewRequest) (*registryv1.GetFilePreviewResponse, error)
+	AvailableSdks() []registryv1.SDK
+	GetCommits(ctx context.Context, req *registryv1.GetCommitsRequest, ref string) (*CommitLog, error)
+	GetRecentCommit(ctx context.Context, req *registryv1.GetRecentCommitRequest) (*RecentCommit, error)
+	GetFileTree(ctx context.Context, req *registryv1.GetFileTreeRequest, ref string, page FileTreePage) (*FileTree, error)
+	GetFilePreview(ctx context.Context, req *registryv1.GetFilePreviewRequest, ref string) (*FilePreview, error)
+	ListRefs(ctx context.Context, repoId string) ([]RefInfo, []RefInfo, error)
+	GetBranchDivergence(ctx context.Context, repoId string) (string, []BranchDivergence, error)
+	GetContributors(ctx context.Context, repoId string) ([]Contributor, error)
+	GetCommitActivity(ctx context.Context, repoId, bucket string, from, to time.Time) ([]CommitActivity, error)
+	GetCommitRange(ctx context.Context, repoId, baseRef, headRef string) (*CommitRange, error)
+	RemoveRepositoryFiles(ctx context.Context, organizationId, repositoryId, repoPath string) error
+	GetSdkManifest(ctx context.Context, repoId, commit string) (*SdkManifest, error)
+	GetRepositorySize(ctx context.Context, repoId string) (int64, error)
+	GetCloneUrls(repoId string) CloneUrls
 	ValidateSshAccess(ctx context.Context, userId, repoPath string, operation SshOperation) (bool, error)
+	LockRepositoryForPush(ctx context.Context, repoPath string) (func(), error)
+	AcquireCloneSlot(key string) (func(), error)
+	ForkRepository(ctx context.Context, sourceRepoId, targetOrganizationId string) (*RepositoryDTO, error)
+	GetForks(ctx context.Context, repoId string) ([]RepositoryDTO, error)
+	IsPublicRepository(ctx context.Context, repoPath string) (bool, error)
 	HasProtoFiles(ctx context.Context, repoPath string) (bool, error)
 	TriggerSdkGeneration(ctx context.Context, repositoryId, commitHash string) error
 	TriggerDocumentationGeneration(ctx context.Context, repositoryId, commitHash string) error
+	RegenerateDocs(ctx context.Context, repoId string) error
+	RecordPush(ctx context.Context, repositoryId, commitHash string) error
+	GetUserActivityFeed(ctx context.Context, userId string, page, pageSize int) ([]ActivityDTO, error)
+	FindOrphanedRepositories(ctx context.Context) ([]string, error)
+	PruneOrphan(ctx context.Context, repoPath string) error
+	CreateDeployToken(ctx context.Context, repoId string, readOnly bool) (*DeployTokenDTO, error)
+	RevokeDeployToken(ctx context.Context, repoId, tokenId string) error
+	ValidateDeployTokenAccess(ctx context.Context, token, repoPath string, operation SshOperation) (tokenId string, hasAccess bool, err error)
 }
 
 type service struct {
@@ -55,36 +95,65 @@ type service struct {
 	sdkQueue     SdkGenerationQueue
 	cfg          *config.Config
 	sdkPath      string
+	docsPath     string
 	sdkRegistry  *sdkgenerator.Registry
 	docGenerator *sdkgenerator.DocumentationGenerator
+	// hookExecutable is the binary push lint hooks call back into; empty when
+	// it could not be resolved, which leaves push linting unavailable.
+	hookExecutable string
+	sizeCache      repositorySizeCache
+	pathLocks      pathLocks
+	cloneSlots     cloneSlots
+	reservedNames  *naming.ReservedNames
 }
 
 func NewService(repository Repository, orgRepo authorization.MemberRoleChecker, sdkQueue SdkGenerationQueue, cfg *config.Config) Service {
+	rootPath := DefaultReposPath
 	sdkPath := "./sdk"
-	if cfg != nil && cfg.SdkGeneration.OutputPath != "" {
-		sdkPath = cfg.SdkGeneration.OutputPath
+	docsPath := sdkPath
+	var reservedNames []string
+	if cfg != nil {
+		rootPath = cfg.Repository.GetPath()
+		sdkPath = cfg.SdkGeneration.GetOutputPath()
+		docsPath = cfg.SdkGeneration.GetDocsPath()
+		// An unreadable file is already rejected by config.Validate at startup.
+		reservedNames, _ = cfg.GetReservedNames()
+	}
+
+	hookExecutable, err := os.Executable()
+	if err != nil {
+		zap.L().Warn("failed to resolve server executable; push linting is unavailable", zap.Error(err))
 	}
 
 	runner := sdkgenerator.NewDefaultCommandRunner()
 	return &service{
-		rootPath:     DefaultReposPath,
+		rootPath:     rootPath,
 		repository:   repository,
 		orgRepo:      orgRepo,
 		sdkQueue:     sdkQueue,
 		cfg:          cfg,
 		sdkPath:      sdkPath,
+		docsPath:     docsPath,
 		sdkRegistry:  sdkgenerator.NewRegistry(runner),
 		docGenerator: sdkgenerator.NewDocumentationGenerator(runner),
+
+		hookExecutable: hookExecutable,
+		reservedNames:  naming.NewReservedNames(reservedNames),
 	}
 }
 
 func (s *service) CreateRepository(
 	ctx context.Context,
 	req *registryv1.CreateRepositoryRequest,
+	opts CreateRepositoryOptions,
 ) error {
 	repoName := req.GetName()
 	organizationId := req.GetOrganizationId()
 
+	if err := s.reservedNames.ValidateName(repoName); err != nil {
+		return err
+	}
+
 	visibility, ok := proto.VisibilityMap[req.GetVisibility()]
 	if !ok {
 		visibility = proto.VisibilityPrivate
@@ -99,23 +168,67 @@ func (s *service) CreateRepository(
 		return err
 	}
 
+	if err := s.checkVisibilityPolicy(ctx, organizationId, visibility); err != nil {
+		return err
+	}
+
+	if err := s.ensureRepositoryCapacity(ctx, organizationId); err != nil {
+		return err
+	}
+
+	defaultSdks, err := s.repository.GetOrganizationDefaultSdks(ctx, organizationId)
+	if err != nil {
+		return err
+	}
+
+	templatePath := ""
+	if opts.ApplyTemplate {
+		if s.cfg == nil || s.cfg.Repository.TemplatePath == "" {
+			return ErrTemplateNotConfigured
+		}
+		templatePath = s.cfg.Repository.TemplatePath
+	}
+
 	repoId := uuid.NewString()
 	repoPath := filepath.Join(s.rootPath, repoId)
 
-	if err := os.MkdirAll(repoPath, 0o750); err != nil {
+	unlock := s.pathLocks.lock(repoPath)
+	defer unlock()
+
+	if err := os.MkdirAll(s.rootPath, 0o750); err != nil {
 		return connect.NewError(connect.CodeInternal, errors.New("failed to create repository directory"))
 	}
 
-	_, err = git.PlainInit(repoPath, true)
-	if err != nil {
-		if errors.Is(err, git.ErrRepositoryAlreadyExists) {
+	// Mkdir rather than MkdirAll: a directory that is already there belongs
+	// to someone else and must be neither reused nor removed on rollback.
+	if err := os.Mkdir(repoPath, 0o750); err != nil {
+		if errors.Is(err, os.ErrExist) {
 			zap.L().Warn("repository already exists on filesystem", zap.String("path", repoPath))
 			return connect.NewError(connect.CodeAlreadyExists, errors.New("repository path already exists"))
 		}
 
+		return connect.NewError(connect.CodeInternal, errors.New("failed to create repository directory"))
+	}
+
+	gitRepo, err := git.PlainInit(repoPath, true)
+	if err != nil {
+		removeRepositoryDir(repoPath, "init error")
 		return connect.NewError(connect.CodeInternal, errors.New("failed to initialize git repository"))
 	}
 
+	if templatePath != "" {
+		if err := commitTemplate(gitRepo, templatePath, s.templateAuthor()); err != nil {
+			zap.L().Error("failed to commit repository template",
+				zap.String("path", repoPath),
+				zap.String("templatePath", templatePath),
+				zap.Error(err),
+			)
+			removeRepositoryDir(repoPath, "template error")
+
+			return connect.NewError(connect.CodeInternal, errors.New("failed to apply repository template"))
+		}
+	}
+
 	now := time.Now().UTC()
 	repoDTO := &RepositoryDTO{
 		Id:             repoId,
@@ -129,11 +242,10 @@ func (s *service) CreateRepository(
 	}
 
 	if err := s.repository.CreateRepository(ctx, repoDTO); err != nil {
-		if removeErr := os.RemoveAll(repoPath); removeErr != nil {
-			zap.L().Error("failed to rollback git repository after db error",
-				zap.String("path", repoPath),
-				zap.Error(removeErr),
-			)
+		removeRepositoryDir(repoPath, "db error")
+
+		if connect.CodeOf(err) == connect.CodeAlreadyExists {
+			return err
 		}
 
 		return connect.NewError(connect.CodeInternal, errors.New("failed to save repository to database"))
@@ -146,16 +258,71 @@ func (s *service) CreateRepository(
 		zap.String("organizationId", organizationId),
 	)
 
+	s.applyDefaultSdks(ctx, repoId, defaultSdks)
+
 	return nil
 }
 
+// applyDefaultSdks enables the organization's default SDKs on a new
+// repository; the first push then generates them like any other enabled SDK.
+// The repository already exists at this point, so a failure is only logged
+// and the preferences are left for the owner to set.
+func (s *service) applyDefaultSdks(ctx context.Context, repoId string, sdks []SDK) {
+	preferences := make([]SdkPreferencesDTO, 0, len(sdks))
+	for _, sdk := range sdks {
+		// Defaults saved before the SDK was disallowed are skipped, as
+		// UpdateSdkPreferences would refuse to enable them.
+		if !s.isSdkAllowed(sdk) {
+			zap.L().Debug("skipping default SDK not available on this server",
+				zap.String("repositoryId", repoId),
+				zap.String("sdk", string(sdk)))
+			continue
+		}
+		preferences = append(preferences, SdkPreferencesDTO{
+			Id:           uuid.NewString(),
+			RepositoryId: repoId,
+			Sdk:          sdk,
+			Status:       true,
+		})
+	}
+	if len(preferences) == 0 {
+		return
+	}
+
+	if err := s.repository.UpdateSdkPreferences(ctx, repoId, preferences); err != nil {
+		zap.L().Error("failed to apply organization default sdks",
+			zap.String("repositoryId", repoId),
+			zap.Error(err),
+		)
+	}
+}
+
+// removeRepositoryDir rolls back a directory CreateRepository made. The caller
+// holds the path lock, so nothing else can be using it.
+func removeRepositoryDir(repoPath, reason string) {
+	if err := os.RemoveAll(repoPath); err != nil {
+		zap.L().Error("failed to rollback git repository after "+reason,
+			zap.String("path", repoPath),
+			zap.Error(err),
+		)
+	}
+}
+
 func (s *service) GetRepository(
 	ctx context.Context,
 	req *registryv1.GetRepositoryRequest,
-) (*registryv1.Repository, error) {
+	opts GetRepositoryOptions,
+) (*RepositoryDetails, error) {
 	repoId := req.GetId()
 
-	repo, err := s.repository.GetRepositoryById(ctx, repoId)
+	var repo *RepositoryDTO
+	var sdkPreferences []SdkPreferencesDTO
+	var err error
+	if opts.IncludeSdkPreferences || opts.IncludeSdkHealth {
+		repo, sdkPreferences, err = s.repository.GetRepositoryWithSdkPreferences(ctx, repoId)
+	} else {
+		repo, err = s.repository.GetRepositoryById(ctx, repoId)
+	}
 	if err != nil {
 		return nil, err
 	}
@@ -169,33 +336,140 @@ func (s *service) GetRepository(
 		return nil, err
 	}
 
-	sdkPreferences, err := s.repository.GetSdkPreferences(ctx, repoId)
+	var protoSdkPreferences []*registryv1.SdkPreference
+	if opts.IncludeSdkPreferences {
+		for _, pref := range sdkPreferences {
+			protoSdkPreferences = append(protoSdkPreferences, &registryv1.SdkPreference{
+				Sdk:    SdkDbToProtoEnum[pref.Sdk],
+				Status: pref.Status,
+			})
+		}
+	}
+
+	var sdkHealth []SdkHealth
+	if opts.IncludeSdkHealth {
+		sdkHealth, err = s.getSdkHealth(ctx, repo.Id, sdkPreferences)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	empty, err := s.repository.IsRepositoryEmpty(ctx, repo.Path)
 	if err != nil {
 		return nil, err
 	}
 
-	var protoSdkPreferences []*registryv1.SdkPreference
-	for _, pref := range sdkPreferences {
-		protoSdkPreferences = append(protoSdkPreferences, &registryv1.SdkPreference{
-			Sdk:    SdkDbToProtoEnum[pref.Sdk],
-			Status: pref.Status,
-		})
+	pushLint, err := isPushLintEnabled(repo.Path)
+	if err != nil {
+		return nil, connect.NewError(connect.CodeInternal, err)
 	}
 
-	return &registryv1.Repository{
-		Id:             repo.Id,
-		Name:           repo.Name,
-		OrganizationId: repo.OrganizationId,
-		Visibility:     proto.ReverseVisibilityMap[repo.Visibility],
-		SdkPreferences: protoSdkPreferences,
+	return &RepositoryDetails{
+		Repository: &registryv1.Repository{
+			Id:             repo.Id,
+			Name:           repo.Name,
+			OrganizationId: repo.OrganizationId,
+			Visibility:     proto.ReverseVisibilityMap[repo.Visibility],
+			SdkPreferences: protoSdkPreferences,
+		},
+		Empty:      empty,
+		PushLint:   pushLint,
+		SdkHealth:  sdkHealth,
+		ForkedFrom: repo.ForkedFrom,
+		Archived:   repo.Archived,
 	}, nil
 }
 
+// getSdkHealth reports the latest generation outcome of each enabled SDK, in
+// preference order. SDKs that have never been generated are left out.
+func (s *service) getSdkHealth(ctx context.Context, repoId string, preferences []SdkPreferencesDTO) ([]SdkHealth, error) {
+	var enabled []SDK
+	for _, pref := range preferences {
+		if pref.Status {
+			enabled = append(enabled, pref.Sdk)
+		}
+	}
+	if len(enabled) == 0 {
+		return nil, nil
+	}
+
+	latest, err := s.sdkQueue.GetLatestSdkJobStatuses(ctx, repoId, enabled)
+	if err != nil {
+		return nil, err
+	}
+
+	statuses := make(map[SDK]SdkGenerationJobStatus, len(latest))
+	for _, job := range latest {
+		statuses[job.Sdk] = job.Status
+	}
+
+	var health []SdkHealth
+	for _, sdk := range enabled {
+		status, ok := statuses[sdk]
+		if !ok {
+			continue
+		}
+
+		switch status {
+		case SdkGenerationJobStatusCompleted:
+			health = append(health, SdkHealth{Sdk: sdk, Status: SdkHealthOk})
+		case SdkGenerationJobStatusFailed:
+			health = append(health, SdkHealth{Sdk: sdk, Status: SdkHealthFailed})
+		default:
+			health = append(health, SdkHealth{Sdk: sdk, Status: SdkHealthPending})
+		}
+	}
+
+	return health, nil
+}
+
+// GetCloneUrls builds the URLs served by GitHttpHandler under /git/ and by the
+// SSH server. The scp-like SSH form cannot carry a port, so non-standard ports
+// use an ssh:// URL instead.
+func (s *service) GetCloneUrls(repoId string) CloneUrls {
+	if s.cfg == nil {
+		return CloneUrls{}
+	}
+
+	var urls CloneUrls
+	if s.cfg.Server.PublicUrl != "" {
+		urls.Http = strings.TrimSuffix(s.cfg.Server.PublicUrl, "/") + "/git/" + repoId
+	}
+
+	if s.cfg.Server.SshHost != "" {
+		port := s.cfg.GetPublicSshPort()
+		if port == "22" {
+			urls.Ssh = s.cfg.Server.SshHost + ":" + repoId + ".git"
+		} else {
+			urls.Ssh = "ssh://" + s.cfg.Server.SshHost + ":" + port + "/" + repoId + ".git"
+		}
+	}
+
+	return urls
+}
+
+// AvailableSdks lists the SDKs this deployment can generate, in enum order.
+func (s *service) AvailableSdks() []registryv1.SDK {
+	var sdks []registryv1.SDK
+	for protoSdk, sdk := range SdkProtoToDbEnum {
+		if s.isSdkAllowed(sdk) {
+			sdks = append(sdks, protoSdk)
+		}
+	}
+	slices.Sort(sdks)
+
+	return sdks
+}
+
+func (s *service) isSdkAllowed(sdk SDK) bool {
+	return s.cfg == nil || s.cfg.SdkGeneration.IsSdkAllowed(string(sdk))
+}
+
 func (s *service) GetRepositories(
 	ctx context.Context,
 	organizationId *string,
 	page, pageSize int,
-) (*registryv1.GetRepositoriesResponse, error) {
+) (*RepositoryList, error) {
 	userId, err := authentication.MustGetUserID(ctx)
 	if err != nil {
 		return nil, err
@@ -244,7 +518,12 @@ func (s *service) GetRepositories(
 	}
 
 	var resp []*registryv1.Repository
+	var archivedIds []string
 	for _, repository := range *repositories {
+		if repository.Archived {
+			archivedIds = append(archivedIds, repository.Id)
+		}
+
 		var protoSdkPreferences []*registryv1.SdkPreference
 		if sdkPrefs, exists := sdkPrefsMap[repository.Id]; exists {
 			for _, pref := range sdkPrefs {
@@ -263,25 +542,18 @@ func (s *service) GetRepositories(
 		})
 	}
 
-	totalPages := (totalCount + pageSize - 1) / pageSize
-	if totalPages == 0 {
-		totalPages = 1
-	}
-	if totalPages > math.MaxInt32 {
-		return nil, connect.NewError(connect.CodeInternal, errors.New("total pages exceeds maximum value"))
-	}
-	nextPage := int32(0)
-	if page < totalPages {
-		if page+1 > math.MaxInt32 {
-			return nil, connect.NewError(connect.CodeInternal, errors.New("page number exceeds maximum value"))
-		}
-		nextPage = int32(page + 1) // #nosec G115 -- bounds checked above
+	nextPage, totalPages, err := pagination.PaginateInt32(totalCount, page, pageSize)
+	if err != nil {
+		return nil, err
 	}
 
-	return &registryv1.GetRepositoriesResponse{
-		Repositories: resp,
-		NextPage:     nextPage,
-		TotalPage:    int32(totalPages), // #nosec G115 -- bounds checked above
+	return &RepositoryList{
+		GetRepositoriesResponse: &registryv1.GetRepositoriesResponse{
+			Repositories: resp,
+			NextPage:     nextPage,
+			TotalPage:    totalPages,
+		},
+		ArchivedIds: archivedIds,
 	}, nil
 }
 
@@ -304,8 +576,30 @@ func (s *service) UpdateRepository(
 		return err
 	}
 
+	// Only a new name is checked, so a repository whose name was reserved
+	// after it was created can still be updated.
+	if req.GetName() != repo.Name {
+		if err := s.reservedNames.ValidateName(req.GetName()); err != nil {
+			return err
+		}
+	}
+
+	visibility := proto.VisibilityMap[req.GetVisibility()]
+	if visibility != repo.Visibility {
+		if err := s.checkVisibilityPolicy(ctx, repo.OrganizationId, visibility); err != nil {
+			return err
+		}
+
+		if repo.Visibility == proto.VisibilityPublic && visibility == proto.VisibilityPrivate {
+			zap.L().Warn("public repository made private; existing clones keep their copies",
+				zap.String("id", repoId),
+				zap.String("organizationId", repo.OrganizationId),
+			)
+		}
+	}
+
 	repo.Name = req.GetName()
-	repo.Visibility = proto.VisibilityMap[req.GetVisibility()]
+	repo.Visibility = visibility
 
 	if err := s.repository.UpdateRepository(ctx, repo); err != nil {
 		return err
@@ -320,6 +614,209 @@ func (s *service) UpdateRepository(
 	return nil
 }
 
+func (s *service) checkVisibilityPolicy(ctx context.Context, organizationId string, visibility proto.Visibility) error {
+	if visibility != proto.VisibilityPublic || s.cfg == nil || !s.cfg.Organization.ForbidPublicReposInPrivateOrgs {
+		return nil
+	}
+
+	orgVisibility, err := s.repository.GetOrganizationVisibility(ctx, organizationId)
+	if err != nil {
+		return err
+	}
+
+	if orgVisibility == proto.VisibilityPrivate {
+		return ErrPublicRepoInPrivateOrg
+	}
+
+	return nil
+}
+
+// ensureRepositoryCapacity rejects adding a repository to an organization
+// that is at its configured limit. Deleted repositories do not count.
+func (s *service) ensureRepositoryCapacity(ctx context.Context, organizationId string) error {
+	if s.cfg == nil || s.cfg.Organization.MaxRepositories <= 0 {
+		return nil
+	}
+
+	count, err := s.repository.GetOrganizationRepositoriesCount(ctx, organizationId, false)
+	if err != nil {
+		return err
+	}
+
+	if count >= s.cfg.Organization.MaxRepositories {
+		return connect.NewError(connect.CodeFailedPrecondition,
+			fmt.Errorf("organization has reached its repository limit of %d", s.cfg.Organization.MaxRepositories))
+	}
+
+	return nil
+}
+
+func (s *service) SetRepositoryArchived(ctx context.Context, repoId string, archived bool) error {
+	repo, err := s.repository.GetRepositoryById(ctx, repoId)
+	if err != nil {
+		return err
+	}
+
+	userId, err := authentication.MustGetUserID(ctx)
+	if err != nil {
+		return err
+	}
+
+	if err := authorization.IsUserOwner(ctx, s.orgRepo, repo.OrganizationId, userId); err != nil {
+		return err
+	}
+
+	return s.repository.SetRepositoryArchived(ctx, repoId, archived)
+}
+
+// TransferRepository moves a repository to another organization, which needs
+// an owner of both. The git directory is keyed by repository id and stays
+// put; generated SDKs and docs, which live under the organization id, follow
+// the repository.
+func (s *service) TransferRepository(ctx context.Context, repoId, targetOrgId string) error {
+	repo, err := s.repository.GetRepositoryById(ctx, repoId)
+	if err != nil {
+		return err
+	}
+
+	if repo.OrganizationId == targetOrgId {
+		return connect.NewError(connect.CodeInvalidArgument, errors.New("repository already belongs to this organization"))
+	}
+
+	userId, err := authentication.MustGetUserID(ctx)
+	if err != nil {
+		return err
+	}
+
+	if err := authorization.IsUserOwner(ctx, s.orgRepo, repo.OrganizationId, userId); err != nil {
+		return err
+	}
+	if err := authorization.IsUserOwner(ctx, s.orgRepo, targetOrgId, userId); err != nil {
+		return err
+	}
+
+	if err := s.checkVisibilityPolicy(ctx, targetOrgId, repo.Visibility); err != nil {
+		return err
+	}
+
+	if err := s.ensureRepositoryCapacity(ctx, targetOrgId); err != nil {
+		return err
+	}
+
+	if err := s.repository.TransferRepository(ctx, repoId, targetOrgId); err != nil {
+		return err
+	}
+
+	outputRoots := []string{s.sdkPath}
+	if s.docsPath != "" && s.docsPath != s.sdkPath {
+		outputRoots = append(outputRoots, s.docsPath)
+	}
+	for _, root := range outputRoots {
+		if err := moveRepositoryOutput(root, repo.OrganizationId, targetOrgId, repoId); err != nil {
+			zap.L().Error("failed to move generated output after repository transfer",
+				zap.String("id", repoId),
+				zap.String("root", root),
+				zap.Error(err),
+			)
+		}
+	}
+
+	zap.L().Info("repository transferred",
+		zap.String("id", repoId),
+		zap.String("fromOrganizationId", repo.OrganizationId),
+		zap.String("toOrganizationId", targetOrgId),
+		zap.String("transferredBy", userId),
+	)
+
+	return nil
+}
+
+func moveRepositoryOutput(root, fromOrgId, toOrgId, repoId string) error {
+	from := filepath.Join(root, fromOrgId, repoId)
+	if _, err := os.Stat(from); errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+
+	to := filepath.Join(root, toOrgId, repoId)
+	if err := os.MkdirAll(filepath.Dir(to), 0o750); err != nil {
+		return err
+	}
+
+	return os.Rename(from, to)
+}
+
+// SetPushLint installs or removes the pre-receive hook that rejects pushes
+// whose proto files fail to lint.
+func (s *service) SetPushLint(ctx context.Context, repoId string, enabled bool) error {
+	repo, err := s.repository.GetRepositoryById(ctx, repoId)
+	if err != nil {
+		return err
+	}
+
+	userId, err := authentication.MustGetUserID(ctx)
+	if err != nil {
+		return err
+	}
+
+	if err := authorization.IsUserOwner(ctx, s.orgRepo, repo.OrganizationId, userId); err != nil {
+		return err
+	}
+
+	if !enabled {
+		if err := removePushLintHook(repo.Path); err != nil {
+			return connect.NewError(connect.CodeInternal, err)
+		}
+		return nil
+	}
+
+	if s.hookExecutable == "" {
+		return connect.NewError(connect.CodeUnavailable, errors.New("push linting is unavailable on this server"))
+	}
+
+	if err := installPushLintHook(repo.Path, s.hookExecutable); err != nil {
+		if errors.Is(err, errCustomPreReceiveHook) {
+			return connect.NewError(connect.CodeFailedPrecondition, err)
+		}
+		return connect.NewError(connect.CodeInternal, err)
+	}
+
+	return nil
+}
+
+// SetOrganizationReposVisibility moves every repository of the organization to
+// visibility and returns how many actually changed.
+func (s *service) SetOrganizationReposVisibility(
+	ctx context.Context,
+	organizationId string,
+	visibility proto.Visibility,
+) (int, error) {
+	userId, err := authentication.MustGetUserID(ctx)
+	if err != nil {
+		return 0, err
+	}
+
+	if err := authorization.IsUserOwner(ctx, s.orgRepo, organizationId, userId); err != nil {
+		return 0, err
+	}
+
+	if err := s.checkVisibilityPolicy(ctx, organizationId, visibility); err != nil {
+		return 0, err
+	}
+
+	updated, err := s.repository.SetRepositoriesVisibilityByOrganizationId(ctx, organizationId, visibility)
+	if err != nil {
+		return 0, err
+	}
+
+	zap.L().Info("organization repositories visibility updated",
+		zap.String("organizationId", organizationId),
+		zap.String("visibility", string(visibility)),
+		zap.Int("updated", updated),
+	)
+
+	return updated, nil
+}
+
 func (s *service) DeleteRepository(
 	ctx context.Context,
 	req *registryv1.DeleteRepositoryRequest,
@@ -340,6 +837,9 @@ func (s *service) DeleteRepository(
 		return err
 	}
 
+	unlock := s.pathLocks.lock(repo.Path)
+	defer unlock()
+
 	if err := s.repository.DeleteRepository(ctx, repoId); err != nil {
 		return err
 	}
@@ -363,46 +863,48 @@ func (s *service) DeleteRepository(
 	return nil
 }
 
+// DeleteRepositoriesByOrganization removes the directories of an
+// organization's repositories around deleteOrganization, which soft-deletes the
+// organization and its repositories in one transaction. The paths stay locked
+// from before the rows go until the directories are gone, as in
+// DeleteRepository. A directory that cannot be removed, or belongs to a
+// repository created after the listing, is left for the reaper.
 func (s *service) DeleteRepositoriesByOrganization(
 	ctx context.Context,
 	organizationId string,
+	deleteOrganization func(context.Context) error,
 ) error {
-	repos, err := s.repository.GetRepositoriesByOrganizationId(ctx, organizationId)
+	repos, err := s.repository.GetRepositoriesByOrganizationId(postgres.WithPrimary(ctx), organizationId)
 	if err != nil {
 		return err
 	}
 
-	if err := s.repository.DeleteRepositoriesByOrganizationId(ctx, organizationId); err != nil {
-		return err
+	for _, repo := range *repos {
+		unlock := s.pathLocks.lock(repo.Path)
+		defer unlock()
 	}
 
-	errGroup := &errgroup.Group{}
+	if err := deleteOrganization(ctx); err != nil {
+		return err
+	}
 
 	for _, repo := range *repos {
-		errGroup.Go(func() error {
-			if err := os.RemoveAll(repo.Path); err != nil {
-				zap.L().Error("failed to remove repository directory after database deletion",
-					zap.String("id", repo.Id),
-					zap.String("path", repo.Path),
-					zap.Error(err),
-				)
-
-				return connect.NewError(connect.CodeInternal, errors.New("failed to remove repository directory"))
-			}
-
-			zap.L().Info("repository deleted as part of organization deletion",
+		if err := os.RemoveAll(repo.Path); err != nil {
+			zap.L().Error("failed to remove repository directory after organization deletion",
 				zap.String("id", repo.Id),
-				zap.String("name", repo.Name),
 				zap.String("path", repo.Path),
 				zap.String("organizationId", organizationId),
+				zap.Error(err),
 			)
+			continue
+		}
 
-			return nil
-		})
-	}
-
-	if err := errGroup.Wait(); err != nil {
-		return err
+		zap.L().Info("repository deleted as part of organization deletion",
+			zap.String("id", repo.Id),
+			zap.String("name", repo.Name),
+			zap.String("path", repo.Path),
+			zap.String("organizationId", organizationId),
+		)
 	}
 
 	return nil
@@ -430,10 +932,15 @@ func (s *service) UpdateSdkPreferences(
 
 	preferences := make([]SdkPreferencesDTO, 0, len(req.GetSdkPreferences()))
 	for _, pref := range req.GetSdkPreferences() {
+		sdk := SdkProtoToDbEnum[pref.GetSdk()]
+		if pref.GetStatus() && !s.isSdkAllowed(sdk) {
+			return connect.NewError(connect.CodeFailedPrecondition, fmt.Errorf("SDK %s is not available on this server", sdk))
+		}
+
 		preferences = append(preferences, SdkPreferencesDTO{
 			Id:           uuid.NewString(),
 			RepositoryId: repositoryId,
-			Sdk:          SdkProtoToDbEnum[pref.GetSdk()],
+			Sdk:          sdk,
 			Status:       pref.GetStatus(),
 		})
 	}
@@ -507,9 +1014,18 @@ func (s *service) ProcessSdkTrigger(ctx context.Context, repositoryId, repoPath
 
 	var enabledSdks []SdkPreferencesDTO
 	for _, pref := range sdkPreferences {
-		if pref.Status {
-			enabledSdks = append(enabledSdks, pref)
+		if !pref.Status {
+			continue
+		}
+		// Preferences saved before the SDK was disallowed stay in the
+		// database but no longer produce jobs.
+		if !s.isSdkAllowed(pref.Sdk) {
+			zap.L().Debug("skipping SDK not available on this server",
+				zap.String("repositoryId", repositoryId),
+				zap.String("sdk", string(pref.Sdk)))
+			continue
 		}
+		enabledSdks = append(enabledSdks, pref)
 	}
 
 	if len(enabledSdks) == 0 {
@@ -518,7 +1034,7 @@ func (s *service) ProcessSdkTrigger(ctx context.Context, repositoryId, repoPath
 		return nil
 	}
 
-	commits, _, err := s.repository.GetCommits(ctx, repoPath, 1, 10000)
+	commits, _, err := s.repository.GetCommits(ctx, repoPath, HeadRef, 1, 10000)
 	if err != nil {
 		zap.L().Debug("no commits found in repository, skipping SDK generation",
 			zap.String("repositoryId", repositoryId),
@@ -569,7 +1085,8 @@ func (s *service) ProcessSdkTrigger(ctx context.Context, repositoryId, repoPath
 func (s *service) GetCommits(
 	ctx context.Context,
 	req *registryv1.GetCommitsRequest,
-) (*registryv1.GetCommitsResponse, error) {
+	ref string,
+) (*CommitLog, error) {
 	userId, err := authentication.MustGetUserID(ctx)
 	if err != nil {
 		return nil, err
@@ -607,37 +1124,35 @@ func (s *service) GetCommits(
 		page = 1
 	}
 
-	commits, totalCount, err := s.repository.GetCommits(ctx, repo.Path, page, pageSize)
+	commits, totalCount, err := s.repository.GetCommits(ctx, repo.Path, ref, page, pageSize)
+	if errors.Is(err, ErrRepositoryEmpty) {
+		return &CommitLog{
+			GetCommitsResponse: &registryv1.GetCommitsResponse{TotalPage: 1},
+			Empty:              true,
+		}, nil
+	}
 	if err != nil {
 		return nil, err
 	}
 
-	totalPages := (totalCount + pageSize - 1) / pageSize
-	if totalPages == 0 {
-		totalPages = 1
-	}
-	if totalPages > math.MaxInt32 {
-		return nil, connect.NewError(connect.CodeInternal, errors.New("total pages exceeds maximum value"))
-	}
-	nextPage := int32(0)
-	if page < totalPages {
-		if page+1 > math.MaxInt32 {
-			return nil, connect.NewError(connect.CodeInternal, errors.New("page number exceeds maximum value"))
-		}
-		nextPage = int32(page + 1) // #nosec G115 -- bounds checked above
+	nextPage, totalPages, err := pagination.PaginateInt32(totalCount, page, pageSize)
+	if err != nil {
+		return nil, err
 	}
 
-	return &registryv1.GetCommitsResponse{
-		Commits:   commits,
-		NextPage:  nextPage,
-		TotalPage: int32(totalPages), // #nosec G115 -- bounds checked above
+	return &CommitLog{
+		GetCommitsResponse: &registryv1.GetCommitsResponse{
+			Commits:   commits,
+			NextPage:  nextPage,
+			TotalPage: totalPages,
+		},
 	}, nil
 }
 
 func (s *service) GetRecentCommit(
 	ctx context.Context,
 	req *registryv1.GetRecentCommitRequest,
-) (*registryv1.Commit, error) {
+) (*RecentCommit, error) {
 	userId, err := authentication.MustGetUserID(ctx)
 	if err != nil {
 		return nil, err
@@ -654,17 +1169,22 @@ func (s *service) GetRecentCommit(
 	}
 
 	commit, err := s.repository.GetRecentCommit(ctx, repo.Path)
+	if errors.Is(err, ErrRepositoryEmpty) {
+		return &RecentCommit{Commit: &registryv1.Commit{}, Empty: true}, nil
+	}
 	if err != nil {
 		return nil, err
 	}
 
-	return commit, nil
+	return &RecentCommit{Commit: commit}, nil
 }
 
 func (s *service) GetFileTree(
 	ctx context.Context,
 	req *registryv1.GetFileTreeRequest,
-) (*registryv1.GetFileTreeResponse, error) {
+	ref string,
+	page FileTreePage,
+) (*FileTree, error) {
 	userId, err := authentication.MustGetUserID(ctx)
 	if err != nil {
 		return nil, err
@@ -686,7 +1206,11 @@ func (s *service) GetFileTree(
 		subPath = &path
 	}
 
-	fileTree, err := s.repository.GetFileTree(ctx, repo.Path, subPath)
+	if page.Limit > maxFileTreePageLimit {
+		page.Limit = maxFileTreePageLimit
+	}
+
+	fileTree, err := s.repository.GetFileTree(ctx, repo.Path, ref, subPath, page)
 	if err != nil {
 		return nil, err
 	}
@@ -697,7 +1221,8 @@ func (s *service) GetFileTree(
 func (s *service) GetFilePreview(
 	ctx context.Context,
 	req *registryv1.GetFilePreviewRequest,
-) (*registryv1.GetFilePreviewResponse, error) {
+	ref string,
+) (*FilePreview, error) {
 	userId, err := authentication.MustGetUserID(ctx)
 	if err != nil {
 		return nil, err
@@ -714,7 +1239,7 @@ func (s *service) GetFilePreview(
 	}
 
 	filePath := req.GetPath()
-	filePreview, err := s.repository.GetFilePreview(ctx, repo.Path, filePath)
+	filePreview, err := s.repository.GetFilePreview(ctx, repo.Path, ref, filePath)
 	if err != nil {
 		return nil, err
 	}
@@ -722,6 +1247,92 @@ func (s *service) GetFilePreview(
 	return filePreview, nil
 }
 
+func (s *service) ListRefs(ctx context.Context, repoId string) ([]RefInfo, []RefInfo, error) {
+	userId, err := authentication.MustGetUserID(ctx)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	repo, err := s.repository.GetRepositoryById(ctx, repoId)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if err := authorization.IsUserMember(ctx, s.orgRepo, repo.OrganizationId, userId); err != nil {
+		return nil, nil, err
+	}
+
+	return s.repository.ListRefs(ctx, repo.Path)
+}
+
+// GetBranchDivergence returns the default branch and how far every branch is
+// ahead of and behind it.
+func (s *service) GetBranchDivergence(ctx context.Context, repoId string) (string, []BranchDivergence, error) {
+	userId, err := authentication.MustGetUserID(ctx)
+	if err != nil {
+		return "", nil, err
+	}
+
+	repo, err := s.repository.GetRepositoryById(ctx, repoId)
+	if err != nil {
+		return "", nil, err
+	}
+
+	if err := authorization.IsUserMember(ctx, s.orgRepo, repo.OrganizationId, userId); err != nil {
+		return "", nil, err
+	}
+
+	return s.repository.GetBranchDivergence(ctx, repo.Path)
+}
+
+func (s *service) GetContributors(ctx context.Context, repoId string) ([]Contributor, error) {
+	userId, err := authentication.MustGetUserID(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	repo, err := s.repository.GetRepositoryById(ctx, repoId)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := authorization.IsUserMember(ctx, s.orgRepo, repo.OrganizationId, userId); err != nil {
+		return nil, err
+	}
+
+	contributors, err := s.repository.GetContributors(ctx, repo.Path)
+	if err != nil {
+		return nil, err
+	}
+	if len(contributors) == 0 {
+		return contributors, nil
+	}
+
+	emails := make([]string, 0, len(contributors))
+	for _, contributor := range contributors {
+		emails = append(emails, strings.ToLower(contributor.Email))
+	}
+
+	users, err := s.repository.GetUsersByEmails(ctx, emails)
+	if err != nil {
+		return nil, err
+	}
+
+	usersByEmail := make(map[string]UserIdentity, len(users))
+	for _, user := range users {
+		usersByEmail[strings.ToLower(user.Email)] = user
+	}
+
+	for i := range contributors {
+		if user, ok := usersByEmail[strings.ToLower(contributors[i].Email)]; ok {
+			contributors[i].UserId = user.Id
+			contributors[i].Username = user.Username
+		}
+	}
+
+	return contributors, nil
+}
+
 func (s *service) ValidateSshAccess(
 	ctx context.Context,
 	userId, repoPath string,
@@ -735,6 +1346,20 @@ func (s *service) ValidateSshAccess(
 
 	role, err := s.orgRepo.GetMemberRole(ctx, repo.OrganizationId, userId)
 	if err != nil {
+		if operation == SshOperationRead {
+			isPublic, err := s.isPubliclyReadable(ctx, repo)
+			if err != nil {
+				return false, err
+			}
+			if isPublic {
+				zap.L().Info("SSH read access granted: public repository",
+					zap.String("userId", userId),
+					zap.String("repoPath", repoPath),
+				)
+				return true, nil
+			}
+		}
+
 		zap.L().Warn("SSH access denied: user not member of organization",
 			zap.String("userId", userId),
 			zap.String("repoPath", repoPath),
@@ -745,6 +1370,13 @@ func (s *service) ValidateSshAccess(
 
 	switch operation {
 	case SshOperationWrite:
+		if repo.Archived {
+			zap.L().Warn("SSH write access denied: repository is archived",
+				zap.String("userId", userId),
+				zap.String("repoPath", repoPath),
+			)
+			return false, ErrRepositoryArchived
+		}
 		if role == authorization.MemberRoleOwner || role == authorization.MemberRoleAuthor {
 			zap.L().Info("SSH write access granted",
 				zap.String("userId", userId),
@@ -771,6 +1403,32 @@ func (s *service) ValidateSshAccess(
 	}
 }
 
+// IsPublicRepository reports whether the repository at repoPath is public and
+// may therefore be read without credentials.
+func (s *service) IsPublicRepository(ctx context.Context, repoPath string) (bool, error) {
+	repo, err := s.repository.GetRepositoryById(ctx, filepath.Base(repoPath))
+	if err != nil {
+		return false, err
+	}
+
+	return s.isPubliclyReadable(ctx, repo)
+}
+
+// isPubliclyReadable reports whether anyone may read repo. A public
+// repository inside a private organization stays hidden with it.
+func (s *service) isPubliclyReadable(ctx context.Context, repo *RepositoryDTO) (bool, error) {
+	if repo.Visibility != proto.VisibilityPublic {
+		return false, nil
+	}
+
+	orgVisibility, err := s.repository.GetOrganizationVisibility(ctx, repo.OrganizationId)
+	if err != nil {
+		return false, err
+	}
+
+	return orgVisibility == proto.VisibilityPublic, nil
+}
+
 func (s *service) HasProtoFiles(ctx context.Context, repoPath string) (bool, error) {
 	protoFiles, err := sdkgenerator.FindProtoFiles(repoPath)
 	if err != nil {
@@ -779,6 +1437,232 @@ func (s *service) HasProtoFiles(ctx context.Context, repoPath string) (bool, err
 	return len(protoFiles) > 0, nil
 }
 
+func (s *service) RecordPush(ctx context.Context, repositoryId, commitHash string) error {
+	return s.repository.RecordPush(ctx, &PushDTO{
+		Id:           uuid.NewString(),
+		RepositoryId: repositoryId,
+		CommitHash:   commitHash,
+		PushedAt:     time.Now().UTC(),
+	})
+}
+
+// GetSdkManifest lists the SDK artifacts generated for commit, read from the
+// SDK output directory. An SDK skipped for commit because its inputs did not
+// change is listed from the commit it was last generated from, and its Path
+// points there. SDKs without output are left out.
+func (s *service) GetSdkManifest(ctx context.Context, repoId, commit string) (*SdkManifest, error) {
+	if !isValidPathComponent(commit) {
+		return nil, connect.NewError(connect.CodeInvalidArgument, errors.New("invalid commit"))
+	}
+
+	userId, err := authentication.MustGetUserID(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	repo, err := s.repository.GetRepositoryById(ctx, repoId)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := authorization.IsUserMember(ctx, s.orgRepo, repo.OrganizationId, userId); err != nil {
+		return nil, err
+	}
+
+	sdks := make([]SDK, 0, len(SdkDbToProtoEnum))
+	for sdk := range SdkDbToProtoEnum {
+		sdks = append(sdks, sdk)
+	}
+	slices.Sort(sdks)
+
+	manifest := &SdkManifest{
+		RepositoryId: repo.Id,
+		CommitHash:   commit,
+		Sdks:         []SdkManifestEntry{},
+	}
+	resolver := &sdkOutputResolver{service: s, repoId: repo.Id, commit: commit}
+	for _, sdk := range sdks {
+		relPath := filepath.Join(repo.OrganizationId, repo.Id, commit, sdkgenerator.SDK(sdk).DirName())
+		artifacts, totalSize, err := listSdkArtifacts(filepath.Join(s.sdkPath, relPath))
+		if err != nil {
+			return nil, connect.NewError(connect.CodeInternal, err)
+		}
+		if len(artifacts) == 0 {
+			base, err := resolver.generatedFrom(ctx, sdk)
+			if err != nil {
+				return nil, err
+			}
+			if base == "" {
+				continue
+			}
+
+			relPath = filepath.Join(repo.OrganizationId, repo.Id, base, sdkgenerator.SDK(sdk).DirName())
+			artifacts, totalSize, err = listSdkArtifacts(filepath.Join(s.sdkPath, relPath))
+			if err != nil {
+				return nil, connect.NewError(connect.CodeInternal, err)
+			}
+			if len(artifacts) == 0 {
+				continue
+			}
+		}
+
+		manifest.Sdks = append(manifest.Sdks, SdkManifestEntry{
+			Sdk:       sdk,
+			Path:      filepath.ToSlash(relPath),
+			Artifacts: artifacts,
+			TotalSize: totalSize,
+		})
+	}
+
+	return manifest, nil
+}
+
+// sdkOutputResolver finds the commit an SDK of commit was generated from.
+// TriggerSdkGeneration generates nothing for a push that leaves the SDK
+// inputs unchanged, so the output for such a commit is the one generated
+// from the SDK's last generated commit.
+type sdkOutputResolver struct {
+	service        *service
+	repoId         string
+	commit         string
+	lastGenerated  map[SDK]string
+	unchangedSince map[string]bool
+}
+
+// generatedFrom returns the commit whose output serves sdk for the resolver's
+// commit, or "" when there is none.
+func (r *sdkOutputResolver) generatedFrom(ctx context.Context, sdk SDK) (string, error) {
+	if r.lastGenerated == nil {
+		preferences, err := r.service.repository.GetSdkPreferences(ctx, r.repoId)
+		if err != nil {
+			return "", err
+		}
+
+		r.lastGenerated = make(map[SDK]string, len(preferences))
+		for _, pref := range preferences {
+			if pref.LastGeneratedCommit != nil {
+				r.lastGenerated[pref.Sdk] = *pref.LastGeneratedCommit
+			}
+		}
+		r.unchangedSince = make(map[string]bool)
+	}
+
+	base, ok := r.lastGenerated[sdk]
+	if !ok || base == r.commit {
+		return "", nil
+	}
+
+	unchanged, seen := r.unchangedSince[base]
+	if !seen {
+		repoFullPath := filepath.Join(r.service.rootPath, r.repoId)
+		changed, err := r.service.repository.HasSdkInputChanges(ctx, repoFullPath, base, r.commit)
+		// An unknown commit or a failed comparison has no output to point at.
+		unchanged = err == nil && !changed
+		r.unchangedSince[base] = unchanged
+	}
+	if !unchanged {
+		return "", nil
+	}
+
+	return base, nil
+}
+
+// GetRepositorySize reports the on-disk size of a repository in bytes. Results
+// are cached for repositorySizeTTL, so a push can take that long to show up.
+func (s *service) GetRepositorySize(ctx context.Context, repoId string) (int64, error) {
+	userId, err := authentication.MustGetUserID(ctx)
+	if err != nil {
+		return 0, err
+	}
+
+	repo, err := s.repository.GetRepositoryById(ctx, repoId)
+	if err != nil {
+		return 0, err
+	}
+
+	if err := authorization.IsUserMember(ctx, s.orgRepo, repo.OrganizationId, userId); err != nil {
+		return 0, err
+	}
+
+	now := time.Now()
+	if size, ok := s.sizeCache.get(repo.Path, now); ok {
+		return size, nil
+	}
+
+	size, err := repositoryDiskSize(repo.Path)
+	if err != nil {
+		zap.L().Error("failed to compute repository size",
+			zap.String("id", repo.Id),
+			zap.String("path", repo.Path),
+			zap.Error(err),
+		)
+
+		return 0, connect.NewError(connect.CodeInternal, errors.New("failed to compute repository size"))
+	}
+
+	s.sizeCache.set(repo.Path, size, now)
+
+	return size, nil
+}
+
+// listSdkArtifacts walks one generated SDK directory, skipping the git
+// metadata commitSdkToRepo keeps there. A missing directory has no artifacts.
+func listSdkArtifacts(dir string) ([]SdkArtifact, int64, error) {
+	var artifacts []SdkArtifact
+	var totalSize int64
+	err := filepath.WalkDir(dir, func(path string, d os.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() {
+			if d.Name() == ".git" {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if !d.Type().IsRegular() {
+			return nil
+		}
+
+		info, err := d.Info()
+		if err != nil {
+			return err
+		}
+		relPath, err := filepath.Rel(dir, path)
+		if err != nil {
+			return err
+		}
+
+		artifacts = append(artifacts, SdkArtifact{Path: filepath.ToSlash(relPath), Size: info.Size()})
+		totalSize += info.Size()
+		return nil
+	})
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, 0, nil
+	}
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to list SDK artifacts: %w", err)
+	}
+
+	return artifacts, totalSize, nil
+}
+
+// GetUserActivityFeed returns one page of the pushes and SDK generations across
+// every organization userId is a member of, newest first.
+func (s *service) GetUserActivityFeed(ctx context.Context, userId string, page, pageSize int) ([]ActivityDTO, error) {
+	if pageSize < 1 {
+		pageSize = 10
+	}
+	if pageSize > 100 {
+		pageSize = 100
+	}
+	if page < 1 {
+		page = 1
+	}
+
+	return s.repository.GetUserActivityFeed(ctx, userId, page, pageSize)
+}
+
 func (s *service) TriggerSdkGeneration(ctx context.Context, repositoryId, commitHash string) error {
 	if s.sdkQueue == nil {
 		zap.L().Debug("SDK generation queue is not configured, skipping SDK generation")
@@ -792,12 +1676,42 @@ func (s *service) TriggerSdkGeneration(ctx context.Context, repositoryId, commit
 
 	var jobs []*SdkGenerationJobDTO
 	now := time.Now().UTC()
+	repoFullPath := filepath.Join(s.rootPath, repositoryId)
+	// SDKs are often generated from the same commit, so diff each base once.
+	inputsChanged := make(map[string]bool)
 
 	for _, pref := range sdkPreferences {
 		if !pref.Status {
 			continue
 		}
 
+		if pref.LastGeneratedCommit != nil {
+			base := *pref.LastGeneratedCommit
+			changed, seen := inputsChanged[base]
+			if !seen {
+				var err error
+				changed, err = s.repository.HasSdkInputChanges(ctx, repoFullPath, base, commitHash)
+				if err != nil {
+					// Regenerating needlessly is cheaper than serving a stale SDK.
+					zap.L().Warn("failed to compare SDK inputs, regenerating",
+						zap.String("repositoryId", repositoryId),
+						zap.String("commitHash", commitHash),
+						zap.Error(err))
+					changed = true
+				}
+				inputsChanged[base] = changed
+			}
+
+			if !changed {
+				zap.L().Debug("proto files unchanged since last generation, skipping SDK",
+					zap.String("repositoryId", repositoryId),
+					zap.String("sdk", string(pref.Sdk)),
+					zap.String("lastGeneratedCommit", base),
+					zap.String("commitHash", commitHash))
+				continue
+			}
+		}
+
 		job := &SdkGenerationJobDTO{
 			Id:           uuid.NewString(),
 			RepositoryId: repositoryId,
@@ -829,6 +1743,62 @@ func (s *service) TriggerSdkGeneration(ctx context.Context, repositoryId, commit
 	return nil
 }
 
+// RegenerateDocs queues a documentation build for the repository's current
+// commit. It is a no-op while a build for the repository is still pending or
+// running.
+func (s *service) RegenerateDocs(ctx context.Context, repoId string) error {
+	userId, err := authentication.MustGetUserID(ctx)
+	if err != nil {
+		return err
+	}
+
+	repo, err := s.repository.GetRepositoryById(ctx, repoId)
+	if err != nil {
+		return err
+	}
+
+	if err := authorization.IsUserAuthor(ctx, s.orgRepo, repo.OrganizationId, userId); err != nil {
+		return err
+	}
+
+	if s.sdkQueue == nil {
+		return connect.NewError(connect.CodeUnavailable, errors.New("documentation generation is not configured"))
+	}
+
+	commit, err := s.repository.GetRecentCommit(ctx, repo.Path)
+	if err != nil {
+		return err
+	}
+
+	job := &DocsGenerationJobDTO{
+		Id:           uuid.NewString(),
+		RepositoryId: repoId,
+		CommitHash:   commit.GetId(),
+		Status:       SdkGenerationJobStatusPending,
+		Attempts:     0,
+		MaxAttempts:  3,
+		CreatedAt:    time.Now().UTC(),
+	}
+
+	enqueued, err := s.sdkQueue.EnqueueDocsGenerationJob(ctx, job)
+	if err != nil {
+		return err
+	}
+
+	if !enqueued {
+		zap.L().Debug("docs generation already in flight, skipping",
+			zap.String("repositoryId", repoId))
+		return nil
+	}
+
+	zap.L().Info("docs regeneration requested",
+		zap.String("repositoryId", repoId),
+		zap.String("commitHash", job.CommitHash),
+		zap.String("userId", userId))
+
+	return nil
+}
+
 func (s *service) TriggerDocumentationGeneration(ctx context.Context, repositoryId, commitHash string) error {
 	repoFullPath := filepath.Join(s.rootPath, repositoryId)
 
@@ -849,7 +1819,7 @@ func (s *service) TriggerDocumentationGeneration(ctx context.Context, repository
 		return fmt.Errorf("failed to fetch repository: %w", err)
 	}
 
-	workDir, err := s.checkoutCommitToTempDir(ctx, repoFullPath, commitHash)
+	workDir, err := checkoutCommitToTempDir(ctx, repoFullPath, commitHash)
 	if err != nil {
 		return fmt.Errorf("failed to checkout commit: %w", err)
 	}
@@ -882,7 +1852,7 @@ func (s *service) GenerateSDK(ctx context.Context, repositoryId, commitHash stri
 		return fmt.Errorf("failed to fetch repository: %w", err)
 	}
 
-	workDir, err := s.checkoutCommitToTempDir(ctx, repoFullPath, commitHash)
+	workDir, err := checkoutCommitToTempDir(ctx, repoFullPath, commitHash)
 	if err != nil {
 		return fmt.Errorf("failed to checkout commit: %w", err)
 	}
@@ -949,6 +1919,11 @@ func (s *service) GenerateSDK(ctx context.Context, repositoryId, commitHash stri
 			zap.Error(err))
 	}
 
+	if err := s.repository.SetLastGeneratedCommit(ctx, repositoryId, sdk, commitHash); err != nil {
+		zap.L().Warn("failed to record last generated commit, but SDK generation succeeded",
+			zap.Error(err))
+	}
+
 	if err := s.GenerateDocumentation(ctx, repositoryId, commitHash, workDir, repo.OrganizationId); err != nil {
 		zap.L().Warn("documentation generation failed, but SDK generation succeeded",
 			zap.Error(err))
@@ -957,7 +1932,7 @@ func (s *service) GenerateSDK(ctx context.Context, repositoryId, commitHash stri
 	return nil
 }
 
-func (s *service) checkoutCommitToTempDir(ctx context.Context, repoPath, commitHash string) (string, error) {
+func checkoutCommitToTempDir(ctx context.Context, repoPath, commitHash string) (string, error) {
 	tempDir, err := os.MkdirTemp("", "hasir-sdk-gen-*")
 	if err != nil {
 		return "", fmt.Errorf("failed to create temp directory: %w", err)
@@ -1003,7 +1978,11 @@ func (s *service) checkoutCommitToTempDir(ctx context.Context, repoPath, commitH
 }
 
 func (s *service) GenerateDocumentation(ctx context.Context, repositoryId, commitHash, repoPath, organizationId string) error {
-	outputPath := filepath.Join(s.sdkPath, organizationId, repositoryId, commitHash, "docs")
+	docsPath := s.docsPath
+	if docsPath == "" {
+		docsPath = s.sdkPath
+	}
+	outputPath := filepath.Join(docsPath, organizationId, repositoryId, commitHash, "docs")
 
 	if _, err := sdkgenerator.GenerateFromRepo(ctx, s.docGenerator, repoPath, outputPath); err != nil {
 		zap.L().Error("documentation generation failed",
@@ -12,9 +12,11 @@ package registry
 import (
 	context "context"
 	reflect "reflect"
+	time "time"
 
 	registryv1 "buf.build/gen/go/hasir/hasir/protocolbuffers/go/registry/v1"
 	gomock "go.uber.org/mock/gomock"
+	proto "hasir-api/pkg/proto"
 )
 
 // MockService is a mock of Service interface.
@@ -41,32 +43,76 @@ func (m *MockService) EXPECT() *MockServiceMockRecorder {
 	return m.recorder
 }
 
+// AcquireCloneSlot mocks base method.
+func (m *MockService) AcquireCloneSlot(key string) (func(), error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "AcquireCloneSlot", key)
+	ret0, _ := ret[0].(func())
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// AcquireCloneSlot indicates an expected call of AcquireCloneSlot.
+func (mr *MockServiceMockRecorder) AcquireCloneSlot(key any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AcquireCloneSlot", reflect.TypeOf((*MockService)(nil).AcquireCloneSlot), key)
+}
+
+// AvailableSdks mocks base method.
+func (m *MockService) AvailableSdks() []registryv1.SDK {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "AvailableSdks")
+	ret0, _ := ret[0].([]registryv1.SDK)
+	return ret0
+}
+
+// AvailableSdks indicates an expected call of AvailableSdks.
+func (mr *MockServiceMockRecorder) AvailableSdks() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AvailableSdks", reflect.TypeOf((*MockService)(nil).AvailableSdks))
+}
+
+// CreateDeployToken mocks base method.
+func (m *MockService) CreateDeployToken(ctx context.Context, repoId string, readOnly bool) (*DeployTokenDTO, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateDeployToken", ctx, repoId, readOnly)
+	ret0, _ := ret[0].(*DeployTokenDTO)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateDeployToken indicates an expected call of CreateDeployToken.
+func (mr *MockServiceMockRecorder) CreateDeployToken(ctx, repoId, readOnly any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateDeployToken", reflect.TypeOf((*MockService)(nil).CreateDeployToken), ctx, repoId, readOnly)
+}
+
 // CreateRepository mocks base method.
-func (m *MockService) CreateRepository(ctx context.Context, req *registryv1.CreateRepositoryRequest) error {
+func (m *MockService) CreateRepository(ctx context.Context, req *registryv1.CreateRepositoryRequest, opts CreateRepositoryOptions) error {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "CreateRepository", ctx, req)
+	ret := m.ctrl.Call(m, "CreateRepository", ctx, req, opts)
 	ret0, _ := ret[0].(error)
 	return ret0
 }
 
 // CreateRepository indicates an expected call of CreateRepository.
-func (mr *MockServiceMockRecorder) CreateRepository(ctx, req any) *gomock.Call {
+func (mr *MockServiceMockRecorder) CreateRepository(ctx, req, opts any) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateRepository", reflect.TypeOf((*MockService)(nil).CreateRepository), ctx, req)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateRepository", reflect.TypeOf((*MockService)(nil).CreateRepository), ctx, req, opts)
 }
 
 // DeleteRepositoriesByOrganization mocks base method.
-func (m *MockService) DeleteRepositoriesByOrganization(ctx context.Context, organizationId string) error {
+func (m *MockService) DeleteRepositoriesByOrganization(ctx context.Context, organizationId string, deleteOrganization func(context.Context) error) error {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "DeleteRepositoriesByOrganization", ctx, organizationId)
+	ret := m.ctrl.Call(m, "DeleteRepositoriesByOrganization", ctx, organizationId, deleteOrganization)
 	ret0, _ := ret[0].(error)
 	return ret0
 }
 
 // DeleteRepositoriesByOrganization indicates an expected call of DeleteRepositoriesByOrganization.
-func (mr *MockServiceMockRecorder) DeleteRepositoriesByOrganization(ctx, organizationId any) *gomock.Call {
+func (mr *MockServiceMockRecorder) DeleteRepositoriesByOrganization(ctx, organizationId, deleteOrganization any) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteRepositoriesByOrganization", reflect.TypeOf((*MockService)(nil).DeleteRepositoriesByOrganization), ctx, organizationId)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteRepositoriesByOrganization", reflect.TypeOf((*MockService)(nil).DeleteRepositoriesByOrganization), ctx, organizationId, deleteOrganization)
 }
 
 // DeleteRepository mocks base method.
@@ -83,6 +129,36 @@ func (mr *MockServiceMockRecorder) DeleteRepository(ctx, req any) *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteRepository", reflect.TypeOf((*MockService)(nil).DeleteRepository), ctx, req)
 }
 
+// FindOrphanedRepositories mocks base method.
+func (m *MockService) FindOrphanedRepositories(ctx context.Context) ([]string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FindOrphanedRepositories", ctx)
+	ret0, _ := ret[0].([]string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FindOrphanedRepositories indicates an expected call of FindOrphanedRepositories.
+func (mr *MockServiceMockRecorder) FindOrphanedRepositories(ctx any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindOrphanedRepositories", reflect.TypeOf((*MockService)(nil).FindOrphanedRepositories), ctx)
+}
+
+// ForkRepository mocks base method.
+func (m *MockService) ForkRepository(ctx context.Context, sourceRepoId, targetOrganizationId string) (*RepositoryDTO, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ForkRepository", ctx, sourceRepoId, targetOrganizationId)
+	ret0, _ := ret[0].(*RepositoryDTO)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ForkRepository indicates an expected call of ForkRepository.
+func (mr *MockServiceMockRecorder) ForkRepository(ctx, sourceRepoId, targetOrganizationId any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ForkRepository", reflect.TypeOf((*MockService)(nil).ForkRepository), ctx, sourceRepoId, targetOrganizationId)
+}
+
 // GenerateSDK mocks base method.
 func (m *MockService) GenerateSDK(ctx context.Context, repositoryId, commitHash string, sdk SDK) error {
 	m.ctrl.T.Helper()
@@ -97,56 +173,146 @@ func (mr *MockServiceMockRecorder) GenerateSDK(ctx, repositoryId, commitHash, sd
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GenerateSDK", reflect.TypeOf((*MockService)(nil).GenerateSDK), ctx, repositoryId, commitHash, sdk)
 }
 
+// GetBranchDivergence mocks base method.
+func (m *MockService) GetBranchDivergence(ctx context.Context, repoId string) (string, []BranchDivergence, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetBranchDivergence", ctx, repoId)
+	ret0, _ := ret[0].(string)
+	ret1, _ := ret[1].([]BranchDivergence)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
+}
+
+// GetBranchDivergence indicates an expected call of GetBranchDivergence.
+func (mr *MockServiceMockRecorder) GetBranchDivergence(ctx, repoId any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetBranchDivergence", reflect.TypeOf((*MockService)(nil).GetBranchDivergence), ctx, repoId)
+}
+
+// GetCloneUrls mocks base method.
+func (m *MockService) GetCloneUrls(repoId string) CloneUrls {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetCloneUrls", repoId)
+	ret0, _ := ret[0].(CloneUrls)
+	return ret0
+}
+
+// GetCloneUrls indicates an expected call of GetCloneUrls.
+func (mr *MockServiceMockRecorder) GetCloneUrls(repoId any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetCloneUrls", reflect.TypeOf((*MockService)(nil).GetCloneUrls), repoId)
+}
+
+// GetCommitActivity mocks base method.
+func (m *MockService) GetCommitActivity(ctx context.Context, repoId, bucket string, from, to time.Time) ([]CommitActivity, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetCommitActivity", ctx, repoId, bucket, from, to)
+	ret0, _ := ret[0].([]CommitActivity)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetCommitActivity indicates an expected call of GetCommitActivity.
+func (mr *MockServiceMockRecorder) GetCommitActivity(ctx, repoId, bucket, from, to any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetCommitActivity", reflect.TypeOf((*MockService)(nil).GetCommitActivity), ctx, repoId, bucket, from, to)
+}
+
+// GetCommitRange mocks base method.
+func (m *MockService) GetCommitRange(ctx context.Context, repoId, baseRef, headRef string) (*CommitRange, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetCommitRange", ctx, repoId, baseRef, headRef)
+	ret0, _ := ret[0].(*CommitRange)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetCommitRange indicates an expected call of GetCommitRange.
+func (mr *MockServiceMockRecorder) GetCommitRange(ctx, repoId, baseRef, headRef any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetCommitRange", reflect.TypeOf((*MockService)(nil).GetCommitRange), ctx, repoId, baseRef, headRef)
+}
+
 // GetCommits mocks base method.
-func (m *MockService) GetCommits(ctx context.Context, req *registryv1.GetCommitsRequest) (*registryv1.GetCommitsResponse, error) {
+func (m *MockService) GetCommits(ctx context.Context, req *registryv1.GetCommitsRequest, ref string) (*CommitLog, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "GetCommits", ctx, req)
-	ret0, _ := ret[0].(*registryv1.GetCommitsResponse)
+	ret := m.ctrl.Call(m, "GetCommits", ctx, req, ref)
+	ret0, _ := ret[0].(*CommitLog)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
 // GetCommits indicates an expected call of GetCommits.
-func (mr *MockServiceMockRecorder) GetCommits(ctx, req any) *gomock.Call {
+func (mr *MockServiceMockRecorder) GetCommits(ctx, req, ref any) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetCommits", reflect.TypeOf((*MockService)(nil).GetCommits), ctx, req)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetCommits", reflect.TypeOf((*MockService)(nil).GetCommits), ctx, req, ref)
+}
+
+// GetContributors mocks base method.
+func (m *MockService) GetContributors(ctx context.Context, repoId string) ([]Contributor, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetContributors", ctx, repoId)
+	ret0, _ := ret[0].([]Contributor)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetContributors indicates an expected call of GetContributors.
+func (mr *MockServiceMockRecorder) GetContributors(ctx, repoId any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetContributors", reflect.TypeOf((*MockService)(nil).GetContributors), ctx, repoId)
 }
 
 // GetFilePreview mocks base method.
-func (m *MockService) GetFilePreview(ctx context.Context, req *registryv1.GetFilePreviewRequest) (*registryv1.GetFilePreviewResponse, error) {
+func (m *MockService) GetFilePreview(ctx context.Context, req *registryv1.GetFilePreviewRequest, ref string) (*FilePreview, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "GetFilePreview", ctx, req)
-	ret0, _ := ret[0].(*registryv1.GetFilePreviewResponse)
+	ret := m.ctrl.Call(m, "GetFilePreview", ctx, req, ref)
+	ret0, _ := ret[0].(*FilePreview)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
 // GetFilePreview indicates an expected call of GetFilePreview.
-func (mr *MockServiceMockRecorder) GetFilePreview(ctx, req any) *gomock.Call {
+func (mr *MockServiceMockRecorder) GetFilePreview(ctx, req, ref any) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetFilePreview", reflect.TypeOf((*MockService)(nil).GetFilePreview), ctx, req)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetFilePreview", reflect.TypeOf((*MockService)(nil).GetFilePreview), ctx, req, ref)
 }
 
 // GetFileTree mocks base method.
-func (m *MockService) GetFileTree(ctx context.Context, req *registryv1.GetFileTreeRequest) (*registryv1.GetFileTreeResponse, error) {
+func (m *MockService) GetFileTree(ctx context.Context, req *registryv1.GetFileTreeRequest, ref string, page FileTreePage) (*FileTree, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "GetFileTree", ctx, req)
-	ret0, _ := ret[0].(*registryv1.GetFileTreeResponse)
+	ret := m.ctrl.Call(m, "GetFileTree", ctx, req, ref, page)
+	ret0, _ := ret[0].(*FileTree)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
 // GetFileTree indicates an expected call of GetFileTree.
-func (mr *MockServiceMockRecorder) GetFileTree(ctx, req any) *gomock.Call {
+func (mr *MockServiceMockRecorder) GetFileTree(ctx, req, ref, page any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetFileTree", reflect.TypeOf((*MockService)(nil).GetFileTree), ctx, req, ref, page)
+}
+
+// GetForks mocks base method.
+func (m *MockService) GetForks(ctx context.Context, repoId string) ([]RepositoryDTO, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetForks", ctx, repoId)
+	ret0, _ := ret[0].([]RepositoryDTO)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetForks indicates an expected call of GetForks.
+func (mr *MockServiceMockRecorder) GetForks(ctx, repoId any) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetFileTree", reflect.TypeOf((*MockService)(nil).GetFileTree), ctx, req)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetForks", reflect.TypeOf((*MockService)(nil).GetForks), ctx, repoId)
 }
 
 // GetRecentCommit mocks base method.
-func (m *MockService) GetRecentCommit(ctx context.Context, req *registryv1.GetRecentCommitRequest) (*registryv1.Commit, error) {
+func (m *MockService) GetRecentCommit(ctx context.Context, req *registryv1.GetRecentCommitRequest) (*RecentCommit, error) {
 	m.ctrl.T.Helper()
 	ret := m.ctrl.Call(m, "GetRecentCommit", ctx, req)
-	ret0, _ := ret[0].(*registryv1.Commit)
+	ret0, _ := ret[0].(*RecentCommit)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
@@ -158,10 +324,10 @@ func (mr *MockServiceMockRecorder) GetRecentCommit(ctx, req any) *gomock.Call {
 }
 
 // GetRepositories mocks base method.
-func (m *MockService) GetRepositories(ctx context.Context, organizationId *string, page, pageSize int) (*registryv1.GetRepositoriesResponse, error) {
+func (m *MockService) GetRepositories(ctx context.Context, organizationId *string, page, pageSize int) (*RepositoryList, error) {
 	m.ctrl.T.Helper()
 	ret := m.ctrl.Call(m, "GetRepositories", ctx, organizationId, page, pageSize)
-	ret0, _ := ret[0].(*registryv1.GetRepositoriesResponse)
+	ret0, _ := ret[0].(*RepositoryList)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
@@ -173,18 +339,63 @@ func (mr *MockServiceMockRecorder) GetRepositories(ctx, organizationId, page, pa
 }
 
 // GetRepository mocks base method.
-func (m *MockService) GetRepository(ctx context.Context, req *registryv1.GetRepositoryRequest) (*registryv1.Repository, error) {
+func (m *MockService) GetRepository(ctx context.Context, req *registryv1.GetRepositoryRequest, opts GetRepositoryOptions) (*RepositoryDetails, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "GetRepository", ctx, req)
-	ret0, _ := ret[0].(*registryv1.Repository)
+	ret := m.ctrl.Call(m, "GetRepository", ctx, req, opts)
+	ret0, _ := ret[0].(*RepositoryDetails)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
 // GetRepository indicates an expected call of GetRepository.
-func (mr *MockServiceMockRecorder) GetRepository(ctx, req any) *gomock.Call {
+func (mr *MockServiceMockRecorder) GetRepository(ctx, req, opts any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetRepository", reflect.TypeOf((*MockService)(nil).GetRepository), ctx, req, opts)
+}
+
+// GetRepositorySize mocks base method.
+func (m *MockService) GetRepositorySize(ctx context.Context, repoId string) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetRepositorySize", ctx, repoId)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetRepositorySize indicates an expected call of GetRepositorySize.
+func (mr *MockServiceMockRecorder) GetRepositorySize(ctx, repoId any) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetRepository", reflect.TypeOf((*MockService)(nil).GetRepository), ctx, req)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetRepositorySize", reflect.TypeOf((*MockService)(nil).GetRepositorySize), ctx, repoId)
+}
+
+// GetSdkManifest mocks base method.
+func (m *MockService) GetSdkManifest(ctx context.Context, repoId, commit string) (*SdkManifest, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetSdkManifest", ctx, repoId, commit)
+	ret0, _ := ret[0].(*SdkManifest)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetSdkManifest indicates an expected call of GetSdkManifest.
+func (mr *MockServiceMockRecorder) GetSdkManifest(ctx, repoId, commit any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetSdkManifest", reflect.TypeOf((*MockService)(nil).GetSdkManifest), ctx, repoId, commit)
+}
+
+// GetUserActivityFeed mocks base method.
+func (m *MockService) GetUserActivityFeed(ctx context.Context, userId string, page, pageSize int) ([]ActivityDTO, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetUserActivityFeed", ctx, userId, page, pageSize)
+	ret0, _ := ret[0].([]ActivityDTO)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetUserActivityFeed indicates an expected call of GetUserActivityFeed.
+func (mr *MockServiceMockRecorder) GetUserActivityFeed(ctx, userId, page, pageSize any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetUserActivityFeed", reflect.TypeOf((*MockService)(nil).GetUserActivityFeed), ctx, userId, page, pageSize)
 }
 
 // HasProtoFiles mocks base method.
@@ -202,6 +413,52 @@ func (mr *MockServiceMockRecorder) HasProtoFiles(ctx, repoPath any) *gomock.Call
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "HasProtoFiles", reflect.TypeOf((*MockService)(nil).HasProtoFiles), ctx, repoPath)
 }
 
+// IsPublicRepository mocks base method.
+func (m *MockService) IsPublicRepository(ctx context.Context, repoPath string) (bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "IsPublicRepository", ctx, repoPath)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// IsPublicRepository indicates an expected call of IsPublicRepository.
+func (mr *MockServiceMockRecorder) IsPublicRepository(ctx, repoPath any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "IsPublicRepository", reflect.TypeOf((*MockService)(nil).IsPublicRepository), ctx, repoPath)
+}
+
+// ListRefs mocks base method.
+func (m *MockService) ListRefs(ctx context.Context, repoId string) ([]RefInfo, []RefInfo, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListRefs", ctx, repoId)
+	ret0, _ := ret[0].([]RefInfo)
+	ret1, _ := ret[1].([]RefInfo)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
+}
+
+// ListRefs indicates an expected call of ListRefs.
+func (mr *MockServiceMockRecorder) ListRefs(ctx, repoId any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListRefs", reflect.TypeOf((*MockService)(nil).ListRefs), ctx, repoId)
+}
+
+// LockRepositoryForPush mocks base method.
+func (m *MockService) LockRepositoryForPush(ctx context.Context, repoPath string) (func(), error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "LockRepositoryForPush", ctx, repoPath)
+	ret0, _ := ret[0].(func())
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// LockRepositoryForPush indicates an expected call of LockRepositoryForPush.
+func (mr *MockServiceMockRecorder) LockRepositoryForPush(ctx, repoPath any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "LockRepositoryForPush", reflect.TypeOf((*MockService)(nil).LockRepositoryForPush), ctx, repoPath)
+}
+
 // ProcessSdkTrigger mocks base method.
 func (m *MockService) ProcessSdkTrigger(ctx context.Context, repositoryId, repoPath string) error {
 	m.ctrl.T.Helper()
@@ -216,6 +473,133 @@ func (mr *MockServiceMockRecorder) ProcessSdkTrigger(ctx, repositoryId, repoPath
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ProcessSdkTrigger", reflect.TypeOf((*MockService)(nil).ProcessSdkTrigger), ctx, repositoryId, repoPath)
 }
 
+// PruneOrphan mocks base method.
+func (m *MockService) PruneOrphan(ctx context.Context, repoPath string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "PruneOrphan", ctx, repoPath)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// PruneOrphan indicates an expected call of PruneOrphan.
+func (mr *MockServiceMockRecorder) PruneOrphan(ctx, repoPath any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "PruneOrphan", reflect.TypeOf((*MockService)(nil).PruneOrphan), ctx, repoPath)
+}
+
+// RecordPush mocks base method.
+func (m *MockService) RecordPush(ctx context.Context, repositoryId, commitHash string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RecordPush", ctx, repositoryId, commitHash)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RecordPush indicates an expected call of RecordPush.
+func (mr *MockServiceMockRecorder) RecordPush(ctx, repositoryId, commitHash any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RecordPush", reflect.TypeOf((*MockService)(nil).RecordPush), ctx, repositoryId, commitHash)
+}
+
+// RegenerateDocs mocks base method.
+func (m *MockService) RegenerateDocs(ctx context.Context, repoId string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RegenerateDocs", ctx, repoId)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RegenerateDocs indicates an expected call of RegenerateDocs.
+func (mr *MockServiceMockRecorder) RegenerateDocs(ctx, repoId any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RegenerateDocs", reflect.TypeOf((*MockService)(nil).RegenerateDocs), ctx, repoId)
+}
+
+// RemoveRepositoryFiles mocks base method.
+func (m *MockService) RemoveRepositoryFiles(ctx context.Context, organizationId, repositoryId, repoPath string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RemoveRepositoryFiles", ctx, organizationId, repositoryId, repoPath)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RemoveRepositoryFiles indicates an expected call of RemoveRepositoryFiles.
+func (mr *MockServiceMockRecorder) RemoveRepositoryFiles(ctx, organizationId, repositoryId, repoPath any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RemoveRepositoryFiles", reflect.TypeOf((*MockService)(nil).RemoveRepositoryFiles), ctx, organizationId, repositoryId, repoPath)
+}
+
+// RevokeDeployToken mocks base method.
+func (m *MockService) RevokeDeployToken(ctx context.Context, repoId, tokenId string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RevokeDeployToken", ctx, repoId, tokenId)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RevokeDeployToken indicates an expected call of RevokeDeployToken.
+func (mr *MockServiceMockRecorder) RevokeDeployToken(ctx, repoId, tokenId any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RevokeDeployToken", reflect.TypeOf((*MockService)(nil).RevokeDeployToken), ctx, repoId, tokenId)
+}
+
+// SetOrganizationReposVisibility mocks base method.
+func (m *MockService) SetOrganizationReposVisibility(ctx context.Context, organizationId string, visibility proto.Visibility) (int, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SetOrganizationReposVisibility", ctx, organizationId, visibility)
+	ret0, _ := ret[0].(int)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// SetOrganizationReposVisibility indicates an expected call of SetOrganizationReposVisibility.
+func (mr *MockServiceMockRecorder) SetOrganizationReposVisibility(ctx, organizationId, visibility any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetOrganizationReposVisibility", reflect.TypeOf((*MockService)(nil).SetOrganizationReposVisibility), ctx, organizationId, visibility)
+}
+
+// SetPushLint mocks base method.
+func (m *MockService) SetPushLint(ctx context.Context, repoId string, enabled bool) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SetPushLint", ctx, repoId, enabled)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SetPushLint indicates an expected call of SetPushLint.
+func (mr *MockServiceMockRecorder) SetPushLint(ctx, repoId, enabled any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetPushLint", reflect.TypeOf((*MockService)(nil).SetPushLint), ctx, repoId, enabled)
+}
+
+// SetRepositoryArchived mocks base method.
+func (m *MockService) SetRepositoryArchived(ctx context.Context, repoId string, archived bool) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SetRepositoryArchived", ctx, repoId, archived)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SetRepositoryArchived indicates an expected call of SetRepositoryArchived.
+func (mr *MockServiceMockRecorder) SetRepositoryArchived(ctx, repoId, archived any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetRepositoryArchived", reflect.TypeOf((*MockService)(nil).SetRepositoryArchived), ctx, repoId, archived)
+}
+
+// TransferRepository mocks base method.
+func (m *MockService) TransferRepository(ctx context.Context, repoId, targetOrgId string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "TransferRepository", ctx, repoId, targetOrgId)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// TransferRepository indicates an expected call of TransferRepository.
+func (mr *MockServiceMockRecorder) TransferRepository(ctx, repoId, targetOrgId any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "TransferRepository", reflect.TypeOf((*MockService)(nil).TransferRepository), ctx, repoId, targetOrgId)
+}
+
 // TriggerDocumentationGeneration mocks base method.
 func (m *MockService) TriggerDocumentationGeneration(ctx context.Context, repositoryId, commitHash string) error {
 	m.ctrl.T.Helper()
@@ -272,6 +656,22 @@ func (mr *MockServiceMockRecorder) UpdateSdkPreferences(ctx, req any) *gomock.Ca
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateSdkPreferences", reflect.TypeOf((*MockService)(nil).UpdateSdkPreferences), ctx, req)
 }
 
+// ValidateDeployTokenAccess mocks base method.
+func (m *MockService) ValidateDeployTokenAccess(ctx context.Context, token, repoPath string, operation SshOperation) (string, bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ValidateDeployTokenAccess", ctx, token, repoPath, operation)
+	ret0, _ := ret[0].(string)
+	ret1, _ := ret[1].(bool)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
+}
+
+// ValidateDeployTokenAccess indicates an expected call of ValidateDeployTokenAccess.
+func (mr *MockServiceMockRecorder) ValidateDeployTokenAccess(ctx, token, repoPath, operation any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ValidateDeployTokenAccess", reflect.TypeOf((*MockService)(nil).ValidateDeployTokenAccess), ctx, token, repoPath, operation)
+}
+
 // ValidateSshAccess mocks base method.
 func (m *MockService) ValidateSshAccess(ctx context.Context, userId, repoPath string, operation SshOperation) (bool, error) {
 	m.ctrl.T.Helper()
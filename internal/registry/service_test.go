@@ -2,14 +2,21 @@ package registry
 
 import (
 	"context"
+	"crypto/rand"
 	"errors"
+	"fmt"
 	"os"
 	"os/exec"
 	"path/filepath"
 	"strings"
+	"sync"
 	"testing"
 	"time"
 
+	"connectrpc.com/connect"
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/object"
 	"github.com/google/uuid"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -18,6 +25,7 @@ import (
 	"hasir-api/pkg/authentication"
 	"hasir-api/pkg/authorization"
 	"hasir-api/pkg/config"
+	"hasir-api/pkg/naming"
 	"hasir-api/pkg/proto"
 	"hasir-api/pkg/sdkgenerator"
 
@@ -135,6 +143,7 @@ func TestService_CreateRepository(t *testing.T) {
 	t.Run("success with default visibility (private)", func(t *testing.T) {
 		ctrl := gomock.NewController(t)
 		mockRepo := NewMockRepository(ctrl)
+		mockRepo.EXPECT().GetOrganizationDefaultSdks(gomock.Any(), gomock.Any()).Return(nil, nil).AnyTimes()
 		mockOrgRepo := authorization.NewMockMemberRoleChecker(ctrl)
 		tmpDir := t.TempDir()
 
@@ -167,7 +176,7 @@ func TestService_CreateRepository(t *testing.T) {
 		err := svc.CreateRepository(ctx, &registryv1.CreateRepositoryRequest{
 			Name:           repoName,
 			OrganizationId: orgID,
-		})
+		}, CreateRepositoryOptions{})
 		require.NoError(t, err)
 
 		dirs, err := os.ReadDir(tmpDir)
@@ -182,6 +191,7 @@ func TestService_CreateRepository(t *testing.T) {
 	t.Run("success with explicit public visibility", func(t *testing.T) {
 		ctrl := gomock.NewController(t)
 		mockRepo := NewMockRepository(ctrl)
+		mockRepo.EXPECT().GetOrganizationDefaultSdks(gomock.Any(), gomock.Any()).Return(nil, nil).AnyTimes()
 		mockOrgRepo := authorization.NewMockMemberRoleChecker(ctrl)
 		tmpDir := t.TempDir()
 
@@ -215,13 +225,14 @@ func TestService_CreateRepository(t *testing.T) {
 			Name:           repoName,
 			OrganizationId: orgID,
 			Visibility:     shared.Visibility_VISIBILITY_PUBLIC,
-		})
+		}, CreateRepositoryOptions{})
 		require.NoError(t, err)
 	})
 
 	t.Run("database save error rolls back git directory", func(t *testing.T) {
 		ctrl := gomock.NewController(t)
 		mockRepo := NewMockRepository(ctrl)
+		mockRepo.EXPECT().GetOrganizationDefaultSdks(gomock.Any(), gomock.Any()).Return(nil, nil).AnyTimes()
 		mockOrgRepo := authorization.NewMockMemberRoleChecker(ctrl)
 		tmpDir := t.TempDir()
 
@@ -248,7 +259,7 @@ func TestService_CreateRepository(t *testing.T) {
 		err := svc.CreateRepository(ctx, &registryv1.CreateRepositoryRequest{
 			Name:           repoName,
 			OrganizationId: orgID,
-		})
+		}, CreateRepositoryOptions{})
 		require.ErrorContains(t, err, "failed to save repository to database")
 
 		repoPath := filepath.Join(tmpDir, repoName)
@@ -256,280 +267,361 @@ func TestService_CreateRepository(t *testing.T) {
 	})
 }
 
-func TestService_GetRepository(t *testing.T) {
-	t.Run("success", func(t *testing.T) {
-		ctrl := gomock.NewController(t)
-		mockRepo := NewMockRepository(ctrl)
-		mockOrgRepo := authorization.NewMockMemberRoleChecker(ctrl)
+func TestService_CreateRepository_Limit(t *testing.T) {
+	const (
+		orgID  = "org-123"
+		userID = "test-user-id"
+	)
 
-		svc := &service{
-			rootPath:   t.TempDir(),
-			repository: mockRepo,
-			orgRepo:    mockOrgRepo,
-		}
+	ctrl := gomock.NewController(t)
+	mockRepo := NewMockRepository(ctrl)
+	mockRepo.EXPECT().GetOrganizationDefaultSdks(gomock.Any(), gomock.Any()).Return(nil, nil).AnyTimes()
+	mockOrgRepo := authorization.NewMockMemberRoleChecker(ctrl)
+	ctx := testAuthInterceptor(userID)
+
+	svc := &service{
+		rootPath:   t.TempDir(),
+		repository: mockRepo,
+		orgRepo:    mockOrgRepo,
+		cfg:        &config.Config{Organization: config.OrganizationConfig{MaxRepositories: 2}},
+	}
 
-		const repoID = "repo-123"
-		const orgID = "org-123"
-		const userID = "user-123"
-		ctx := testAuthInterceptor(userID)
+	// live mirrors the repositories the count query would see; deleting one
+	// takes it out of the count.
+	live := 1
+	mockOrgRepo.EXPECT().
+		GetMemberRole(ctx, orgID, userID).
+		Return(authorization.MemberRoleOwner, nil).
+		AnyTimes()
+	mockRepo.EXPECT().
+		GetOrganizationRepositoriesCount(ctx, orgID, false).
+		DoAndReturn(func(context.Context, string, bool) (int, error) {
+			return live, nil
+		}).
+		AnyTimes()
+	mockRepo.EXPECT().
+		CreateRepository(ctx, gomock.Any()).
+		DoAndReturn(func(context.Context, *RepositoryDTO) error {
+			live++
+			return nil
+		}).
+		AnyTimes()
+
+	create := func(name string) error {
+		return svc.CreateRepository(ctx, &registryv1.CreateRepositoryRequest{
+			Name:           name,
+			OrganizationId: orgID,
+		}, CreateRepositoryOptions{})
+	}
 
-		mockRepo.EXPECT().
-			GetRepositoryById(ctx, repoID).
-			Return(&RepositoryDTO{
-				Id:             repoID,
-				Name:           "test-repo",
-				OrganizationId: orgID,
-				Visibility:     proto.VisibilityPrivate,
-			}, nil)
+	t.Run("creation under the limit succeeds", func(t *testing.T) {
+		require.NoError(t, create("second"))
+		assert.Equal(t, 2, live)
+	})
 
-		mockOrgRepo.EXPECT().
-			GetMemberRole(ctx, orgID, userID).
-			Return(authorization.MemberRoleReader, nil)
+	t.Run("creation at the limit is rejected", func(t *testing.T) {
+		err := create("third")
 
-		sdkPrefs := []SdkPreferencesDTO{
-			{
-				Id:           "pref-1",
-				RepositoryId: repoID,
-				Sdk:          SdkGoConnectRpc,
-				Status:       true,
-			},
-		}
+		require.Error(t, err)
+		assert.Equal(t, connect.CodeFailedPrecondition, connect.CodeOf(err))
+		assert.Contains(t, err.Error(), "repository limit of 2")
+		assert.Equal(t, 2, live)
+	})
 
-		mockRepo.EXPECT().
-			GetSdkPreferences(ctx, repoID).
-			Return(sdkPrefs, nil)
+	t.Run("deleting a repository frees a slot", func(t *testing.T) {
+		live--
 
-		repo, err := svc.GetRepository(ctx, &registryv1.GetRepositoryRequest{
-			Id: repoID,
-		})
-		assert.NoError(t, err)
-		assert.NotNil(t, repo)
-		assert.Equal(t, repoID, repo.GetId())
-		assert.Equal(t, "test-repo", repo.GetName())
-		assert.Equal(t, orgID, repo.GetOrganizationId())
-		assert.Equal(t, shared.Visibility_VISIBILITY_PRIVATE, repo.GetVisibility())
-		assert.Len(t, repo.GetSdkPreferences(), 1)
-		assert.Equal(t, registryv1.SDK_SDK_GO_CONNECTRPC, repo.GetSdkPreferences()[0].GetSdk())
-		assert.True(t, repo.GetSdkPreferences()[0].GetStatus())
+		require.NoError(t, create("third"))
+		assert.Equal(t, 2, live)
 	})
+}
 
-	t.Run("repository not found", func(t *testing.T) {
+func TestService_CreateRepository_Rollback(t *testing.T) {
+	const orgID = "org-123"
+	const userID = "test-user-id"
+
+	newService := func(t *testing.T) (*service, *MockRepository, string, context.Context) {
 		ctrl := gomock.NewController(t)
 		mockRepo := NewMockRepository(ctrl)
+		mockRepo.EXPECT().GetOrganizationDefaultSdks(gomock.Any(), gomock.Any()).Return(nil, nil).AnyTimes()
 		mockOrgRepo := authorization.NewMockMemberRoleChecker(ctrl)
+		tmpDir := t.TempDir()
+		ctx := testAuthInterceptor(userID)
 
-		svc := &service{
-			rootPath:   t.TempDir(),
-			repository: mockRepo,
-			orgRepo:    mockOrgRepo,
-		}
+		mockOrgRepo.EXPECT().
+			GetMemberRole(ctx, orgID, userID).
+			Return(authorization.MemberRoleOwner, nil).
+			AnyTimes()
 
-		const repoID = "nonexistent-repo"
-		const userID = "user-123"
-		ctx := testAuthInterceptor(userID)
+		return &service{rootPath: tmpDir, repository: mockRepo, orgRepo: mockOrgRepo}, mockRepo, tmpDir, ctx
+	}
 
-		mockRepo.EXPECT().
-			GetRepositoryById(ctx, repoID).
-			Return(nil, ErrRepositoryNotFound)
+	t.Run("insert failure removes the directory it created", func(t *testing.T) {
+		svc, mockRepo, tmpDir, ctx := newService(t)
 
-		repo, err := svc.GetRepository(ctx, &registryv1.GetRepositoryRequest{
-			Id: repoID,
-		})
-		assert.Error(t, err)
-		assert.Nil(t, repo)
-		assert.ErrorContains(t, err, "repository not found")
-	})
+		var createdPath string
+		mockRepo.EXPECT().
+			CreateRepository(ctx, gomock.Any()).
+			DoAndReturn(func(_ context.Context, repo *RepositoryDTO) error {
+				createdPath = repo.Path
+				assert.FileExists(t, filepath.Join(repo.Path, "HEAD"))
+				return errors.New("connection reset")
+			})
 
-	t.Run("user not member of organization", func(t *testing.T) {
-		ctrl := gomock.NewController(t)
-		mockRepo := NewMockRepository(ctrl)
-		mockOrgRepo := authorization.NewMockMemberRoleChecker(ctrl)
+		err := svc.CreateRepository(ctx, &registryv1.CreateRepositoryRequest{Name: "my-repo", OrganizationId: orgID}, CreateRepositoryOptions{})
 
-		svc := &service{
-			rootPath:   t.TempDir(),
-			repository: mockRepo,
-			orgRepo:    mockOrgRepo,
-		}
+		assert.Equal(t, connect.CodeInternal, connect.CodeOf(err))
+		assert.NoDirExists(t, createdPath)
+		entries, err := os.ReadDir(tmpDir)
+		require.NoError(t, err)
+		assert.Empty(t, entries)
+	})
 
-		const repoID = "repo-123"
-		const orgID = "org-123"
-		const userID = "user-123"
-		ctx := testAuthInterceptor(userID)
+	t.Run("already exists from the database is passed through", func(t *testing.T) {
+		svc, mockRepo, tmpDir, ctx := newService(t)
 
 		mockRepo.EXPECT().
-			GetRepositoryById(ctx, repoID).
-			Return(&RepositoryDTO{
-				Id:             repoID,
-				Name:           "test-repo",
-				OrganizationId: orgID,
-				Visibility:     proto.VisibilityPrivate,
-			}, nil)
+			CreateRepository(ctx, gomock.Any()).
+			Return(connect.NewError(connect.CodeAlreadyExists, errors.New("repository already exists")))
 
-		mockOrgRepo.EXPECT().
-			GetMemberRole(ctx, orgID, userID).
-			Return("", authorization.ErrMemberNotFound)
+		err := svc.CreateRepository(ctx, &registryv1.CreateRepositoryRequest{Name: "my-repo", OrganizationId: orgID}, CreateRepositoryOptions{})
 
-		repo, err := svc.GetRepository(ctx, &registryv1.GetRepositoryRequest{
-			Id: repoID,
-		})
-		assert.Error(t, err)
-		assert.Nil(t, repo)
-		assert.ErrorContains(t, err, "you are not a member of this organization")
+		assert.Equal(t, connect.CodeAlreadyExists, connect.CodeOf(err))
+		entries, err := os.ReadDir(tmpDir)
+		require.NoError(t, err)
+		assert.Empty(t, entries)
 	})
 
-	t.Run("missing user ID in context", func(t *testing.T) {
-		ctrl := gomock.NewController(t)
-		mockRepo := NewMockRepository(ctrl)
-		mockOrgRepo := authorization.NewMockMemberRoleChecker(ctrl)
+	t.Run("concurrent creates each get an intact repository", func(t *testing.T) {
+		svc, mockRepo, tmpDir, ctx := newService(t)
 
-		svc := &service{
-			rootPath:   t.TempDir(),
-			repository: mockRepo,
-			orgRepo:    mockOrgRepo,
-		}
+		const creates = 8
+		var mu sync.Mutex
+		paths := make(map[string]bool)
+		mockRepo.EXPECT().
+			CreateRepository(ctx, gomock.Any()).
+			DoAndReturn(func(_ context.Context, repo *RepositoryDTO) error {
+				mu.Lock()
+				defer mu.Unlock()
+				// Every other insert fails, so rollbacks run alongside successful creates.
+				if len(paths)%2 == 1 {
+					paths[repo.Path] = false
+					return errors.New("connection reset")
+				}
+				paths[repo.Path] = true
+				return nil
+			}).
+			Times(creates)
 
-		const repoID = "repo-123"
-		const orgID = "org-123"
-		ctx := context.Background()
+		var wg sync.WaitGroup
+		for range creates {
+			wg.Go(func() {
+				_ = svc.CreateRepository(ctx, &registryv1.CreateRepositoryRequest{Name: "same-name", OrganizationId: orgID}, CreateRepositoryOptions{})
+			})
+		}
+		wg.Wait()
 
-		mockRepo.EXPECT().
-			GetRepositoryById(ctx, repoID).
-			Return(&RepositoryDTO{
-				Id:             repoID,
-				Name:           "test-repo",
-				OrganizationId: orgID,
-				Visibility:     proto.VisibilityPrivate,
-			}, nil)
+		entries, err := os.ReadDir(tmpDir)
+		require.NoError(t, err)
 
-		repo, err := svc.GetRepository(ctx, &registryv1.GetRepositoryRequest{
-			Id: repoID,
-		})
-		assert.Error(t, err)
-		assert.Nil(t, repo)
-		assert.ErrorContains(t, err, "user not authenticated")
+		var kept []string
+		for path, saved := range paths {
+			if !saved {
+				assert.NoDirExists(t, path)
+				continue
+			}
+			kept = append(kept, filepath.Base(path))
+
+			gitRepo, err := git.PlainOpen(path)
+			require.NoError(t, err)
+			_, err = gitRepo.Config()
+			assert.NoError(t, err)
+		}
+		assert.Len(t, entries, len(kept))
+		assert.Len(t, kept, creates/2)
 	})
 }
 
-func TestService_UpdateRepository(t *testing.T) {
-	ctrl := gomock.NewController(t)
-	defer ctrl.Finish()
-
-	t.Run("success", func(t *testing.T) {
-		mockRepo := NewMockRepository(ctrl)
-		mockOrgRepo := authorization.NewMockMemberRoleChecker(ctrl)
+func TestPathLocks(t *testing.T) {
+	var locks pathLocks
 
-		svc := &service{
-			rootPath:   t.TempDir(),
-			repository: mockRepo,
-			orgRepo:    mockOrgRepo,
-		}
+	unlock := locks.lock("/repos/a")
 
-		const repoID = "repo-123"
-		const repoName = "test-repo"
-		const orgID = "org-123"
-		const userID = "user-123"
-		ctx := testAuthInterceptor(userID)
+	acquired := make(chan struct{})
+	go func() {
+		release := locks.lock("/repos/a")
+		close(acquired)
+		release()
+	}()
 
-		mockRepo.EXPECT().
-			GetRepositoryById(ctx, repoID).
-			Return(&RepositoryDTO{
-				Id:             repoID,
-				Name:           repoName,
-				OrganizationId: orgID,
-				Visibility:     proto.VisibilityPrivate,
-			}, nil)
+	otherUnlock := locks.lock("/repos/b")
+	otherUnlock()
 
-		mockOrgRepo.EXPECT().
-			GetMemberRole(ctx, orgID, userID).
-			Return(authorization.MemberRoleOwner, nil)
+	select {
+	case <-acquired:
+		t.Fatal("second lock on the same path was acquired while held")
+	case <-time.After(50 * time.Millisecond):
+	}
 
-		mockRepo.EXPECT().
-			UpdateRepository(ctx, gomock.Any()).
-			Return(nil)
+	unlock()
+	select {
+	case <-acquired:
+	case <-time.After(time.Second):
+		t.Fatal("second lock was not acquired after release")
+	}
 
-		err := svc.UpdateRepository(ctx, &registryv1.UpdateRepositoryRequest{
-			Id:         repoID,
-			Name:       repoName,
-			Visibility: shared.Visibility_VISIBILITY_PRIVATE,
-		})
-		assert.NoError(t, err)
-	})
+	assert.Eventually(t, func() bool {
+		locks.mu.Lock()
+		defer locks.mu.Unlock()
+		return len(locks.locks) == 0
+	}, time.Second, 10*time.Millisecond, "released locks are dropped")
 }
 
-func TestService_DeleteRepository(t *testing.T) {
-	t.Run("success", func(t *testing.T) {
+func TestService_CreateRepository_Template(t *testing.T) {
+	const orgID = "org-123"
+	const userID = "test-user-id"
+
+	newService := func(t *testing.T, templatePath string) (*service, *MockRepository, string) {
 		ctrl := gomock.NewController(t)
 		mockRepo := NewMockRepository(ctrl)
+		mockRepo.EXPECT().GetOrganizationDefaultSdks(gomock.Any(), gomock.Any()).Return(nil, nil).AnyTimes()
 		mockOrgRepo := authorization.NewMockMemberRoleChecker(ctrl)
 		tmpDir := t.TempDir()
 
-		svc := &service{
+		mockOrgRepo.EXPECT().
+			GetMemberRole(gomock.Any(), orgID, userID).
+			Return(authorization.MemberRoleOwner, nil)
+
+		cfg := &config.Config{Repository: config.RepositoryConfig{TemplatePath: templatePath}}
+
+		return &service{
 			rootPath:   tmpDir,
 			repository: mockRepo,
 			orgRepo:    mockOrgRepo,
-		}
+			cfg:        cfg,
+		}, mockRepo, tmpDir
+	}
 
-		repoId := "test-repo-id"
-		orgID := "org-123"
-		userID := "user-123"
-		repoPath := filepath.Join(tmpDir, repoId)
-		repoName := "test-repo"
-		ctx := testAuthInterceptor(userID)
+	t.Run("commits template files as initial commit", func(t *testing.T) {
+		templateDir := t.TempDir()
+		require.NoError(t, os.WriteFile(filepath.Join(templateDir, "README.md"), []byte("# Protos\n"), 0o600))
+		require.NoError(t, os.WriteFile(filepath.Join(templateDir, "buf.yaml"), []byte("version: v2\n"), 0o600))
+		require.NoError(t, os.MkdirAll(filepath.Join(templateDir, "proto", "v1"), 0o750))
+		require.NoError(t, os.WriteFile(filepath.Join(templateDir, "proto", "v1", "service.proto"), []byte("syntax = \"proto3\";\n"), 0o600))
+		require.NoError(t, os.MkdirAll(filepath.Join(templateDir, "empty"), 0o750))
 
-		require.NoError(t, os.MkdirAll(repoPath, 0o755))
+		svc, mockRepo, tmpDir := newService(t, templateDir)
+		ctx := testAuthInterceptor(userID)
 
+		var repoPath string
 		mockRepo.EXPECT().
-			GetRepositoryById(ctx, repoId).
-			Return(&RepositoryDTO{
-				Id:             repoId,
-				Name:           repoName,
-				Path:           repoPath,
-				OrganizationId: orgID,
-			}, nil)
+			CreateRepository(ctx, gomock.Any()).
+			DoAndReturn(func(_ context.Context, repo *RepositoryDTO) error {
+				repoPath = repo.Path
+				return nil
+			})
 
-		mockOrgRepo.EXPECT().
-			GetMemberRole(ctx, orgID, userID).
-			Return(authorization.MemberRoleOwner, nil)
+		err := svc.CreateRepository(ctx, &registryv1.CreateRepositoryRequest{
+			Name:           "templated-repo",
+			OrganizationId: orgID,
+		}, CreateRepositoryOptions{ApplyTemplate: true})
+		require.NoError(t, err)
+		require.Equal(t, tmpDir, filepath.Dir(repoPath))
 
-		mockRepo.EXPECT().
-			DeleteRepository(ctx, repoId).
-			Return(nil)
+		gitRepo, err := git.PlainOpen(repoPath)
+		require.NoError(t, err)
 
-		err := svc.DeleteRepository(ctx, &registryv1.DeleteRepositoryRequest{
-			RepositoryId: repoId,
-		})
+		head, err := gitRepo.Head()
+		require.NoError(t, err)
 
-		assert.NoError(t, err)
-		assert.NoDirExists(t, repoPath)
-	})
+		commit, err := gitRepo.CommitObject(head.Hash())
+		require.NoError(t, err)
+		assert.Equal(t, templateCommitMessage, commit.Message)
+		assert.Empty(t, commit.ParentHashes)
 
-	t.Run("repository not found", func(t *testing.T) {
-		ctrl := gomock.NewController(t)
-		mockRepo := NewMockRepository(ctrl)
-		mockOrgRepo := authorization.NewMockMemberRoleChecker(ctrl)
+		tree, err := commit.Tree()
+		require.NoError(t, err)
 
-		svc := &service{
-			rootPath:   t.TempDir(),
-			repository: mockRepo,
-			orgRepo:    mockOrgRepo,
+		var files []string
+		require.NoError(t, tree.Files().ForEach(func(f *object.File) error {
+			files = append(files, f.Name)
+			return nil
+		}))
+		assert.ElementsMatch(t, []string{"README.md", "buf.yaml", "proto/v1/service.proto"}, files)
+
+		readme, err := tree.File("README.md")
+		require.NoError(t, err)
+		content, err := readme.Contents()
+		require.NoError(t, err)
+		assert.Equal(t, "# Protos\n", content)
+
+		if _, err := exec.LookPath("git"); err == nil {
+			out, err := exec.Command("git", "-C", repoPath, "fsck", "--strict").CombinedOutput()
+			require.NoError(t, err, string(out))
 		}
+	})
 
-		repoId := "nonexistent-repo-id"
-		userID := "user-123"
+	t.Run("without option repository stays empty", func(t *testing.T) {
+		svc, mockRepo, _ := newService(t, t.TempDir())
 		ctx := testAuthInterceptor(userID)
 
+		var repoPath string
 		mockRepo.EXPECT().
-			GetRepositoryById(ctx, repoId).
-			Return(nil, ErrRepositoryNotFound)
+			CreateRepository(ctx, gomock.Any()).
+			DoAndReturn(func(_ context.Context, repo *RepositoryDTO) error {
+				repoPath = repo.Path
+				return nil
+			})
 
-		err := svc.DeleteRepository(ctx, &registryv1.DeleteRepositoryRequest{
-			RepositoryId: repoId,
-		})
+		err := svc.CreateRepository(ctx, &registryv1.CreateRepositoryRequest{
+			Name:           "plain-repo",
+			OrganizationId: orgID,
+		}, CreateRepositoryOptions{})
+		require.NoError(t, err)
+
+		gitRepo, err := git.PlainOpen(repoPath)
+		require.NoError(t, err)
+		_, err = gitRepo.Head()
+		assert.ErrorIs(t, err, plumbing.ErrReferenceNotFound)
+	})
+
+	t.Run("fails when templates are not configured", func(t *testing.T) {
+		svc, _, tmpDir := newService(t, "")
+		ctx := testAuthInterceptor(userID)
+
+		err := svc.CreateRepository(ctx, &registryv1.CreateRepositoryRequest{
+			Name:           "templated-repo",
+			OrganizationId: orgID,
+		}, CreateRepositoryOptions{ApplyTemplate: true})
+		require.ErrorIs(t, err, ErrTemplateNotConfigured)
+
+		dirs, err := os.ReadDir(tmpDir)
+		require.NoError(t, err)
+		assert.Empty(t, dirs)
+	})
+
+	t.Run("missing template directory rolls back git directory", func(t *testing.T) {
+		svc, _, tmpDir := newService(t, filepath.Join(t.TempDir(), "missing"))
+		ctx := testAuthInterceptor(userID)
+
+		err := svc.CreateRepository(ctx, &registryv1.CreateRepositoryRequest{
+			Name:           "templated-repo",
+			OrganizationId: orgID,
+		}, CreateRepositoryOptions{ApplyTemplate: true})
 		require.Error(t, err)
-		assert.ErrorContains(t, err, "repository not found")
+		assert.Equal(t, connect.CodeInternal, connect.CodeOf(err))
+
+		dirs, err := os.ReadDir(tmpDir)
+		require.NoError(t, err)
+		assert.Empty(t, dirs)
 	})
+}
 
-	t.Run("database delete error", func(t *testing.T) {
+func TestService_CreateRepository_DefaultSdks(t *testing.T) {
+	const orgID = "org-123"
+	const userID = "test-user-id"
+
+	setup := func(t *testing.T) (*service, *MockRepository, context.Context, string) {
 		ctrl := gomock.NewController(t)
 		mockRepo := NewMockRepository(ctrl)
 		mockOrgRepo := authorization.NewMockMemberRoleChecker(ctrl)
@@ -541,99 +633,251 @@ func TestService_DeleteRepository(t *testing.T) {
 			orgRepo:    mockOrgRepo,
 		}
 
-		repoId := "test-repo-id"
-		orgID := "org-123"
-		userID := "user-123"
-		repoPath := filepath.Join(tmpDir, repoId)
-		repoName := "test-repo"
 		ctx := testAuthInterceptor(userID)
+		mockOrgRepo.EXPECT().
+			GetMemberRole(ctx, orgID, userID).
+			Return(authorization.MemberRoleOwner, nil)
 
-		require.NoError(t, os.MkdirAll(repoPath, 0o755))
+		return svc, mockRepo, ctx, tmpDir
+	}
 
-		dbErr := errors.New("database delete failed")
+	t.Run("new repository starts with the organization defaults enabled", func(t *testing.T) {
+		svc, mockRepo, ctx, _ := setup(t)
 
+		var createdId string
 		mockRepo.EXPECT().
-			GetRepositoryById(ctx, repoId).
-			Return(&RepositoryDTO{
-				Id:             repoId,
-				Name:           repoName,
-				Path:           repoPath,
-				OrganizationId: orgID,
-			}, nil)
+			GetOrganizationDefaultSdks(ctx, orgID).
+			Return([]SDK{SdkGoProtobuf, SdkJsBufbuildEs}, nil)
+		mockRepo.EXPECT().
+			CreateRepository(ctx, gomock.Any()).
+			DoAndReturn(func(_ context.Context, repo *RepositoryDTO) error {
+				createdId = repo.Id
+				return nil
+			})
+		mockRepo.EXPECT().
+			UpdateSdkPreferences(ctx, gomock.Any(), gomock.Any()).
+			DoAndReturn(func(_ context.Context, repoId string, preferences []SdkPreferencesDTO) error {
+				assert.Equal(t, createdId, repoId)
+				require.Len(t, preferences, 2)
+				assert.Equal(t, SdkGoProtobuf, preferences[0].Sdk)
+				assert.Equal(t, SdkJsBufbuildEs, preferences[1].Sdk)
+				for _, pref := range preferences {
+					assert.True(t, pref.Status)
+					assert.Equal(t, createdId, pref.RepositoryId)
+					assert.NotEmpty(t, pref.Id)
+				}
+				return nil
+			})
 
-		mockOrgRepo.EXPECT().
-			GetMemberRole(ctx, orgID, userID).
-			Return(authorization.MemberRoleOwner, nil)
+		err := svc.CreateRepository(ctx, &registryv1.CreateRepositoryRequest{
+			Name:           "my-repo",
+			OrganizationId: orgID,
+		}, CreateRepositoryOptions{})
+		require.NoError(t, err)
+	})
+
+	t.Run("defaults not available on this server are skipped", func(t *testing.T) {
+		svc, mockRepo, ctx, _ := setup(t)
+		svc.cfg = &config.Config{
+			SdkGeneration: config.SdkGenerationConfig{AllowedSdks: []string{"JS_BUFBUILD_ES"}},
+		}
 
 		mockRepo.EXPECT().
-			DeleteRepository(ctx, repoId).
-			Return(dbErr)
+			GetOrganizationDefaultSdks(ctx, orgID).
+			Return([]SDK{SdkGoProtobuf, SdkJsBufbuildEs}, nil)
+		mockRepo.EXPECT().
+			CreateRepository(ctx, gomock.Any()).
+			Return(nil)
+		mockRepo.EXPECT().
+			UpdateSdkPreferences(ctx, gomock.Any(), gomock.Any()).
+			DoAndReturn(func(_ context.Context, _ string, preferences []SdkPreferencesDTO) error {
+				require.Len(t, preferences, 1)
+				assert.Equal(t, SdkJsBufbuildEs, preferences[0].Sdk)
+				return nil
+			})
 
-		err := svc.DeleteRepository(ctx, &registryv1.DeleteRepositoryRequest{
-			RepositoryId: repoId,
-		})
+		err := svc.CreateRepository(ctx, &registryv1.CreateRepositoryRequest{
+			Name:           "my-repo",
+			OrganizationId: orgID,
+		}, CreateRepositoryOptions{})
+		require.NoError(t, err)
+	})
+
+	t.Run("no defaults leaves preferences untouched", func(t *testing.T) {
+		svc, mockRepo, ctx, _ := setup(t)
+
+		mockRepo.EXPECT().
+			GetOrganizationDefaultSdks(ctx, orgID).
+			Return(nil, nil)
+		mockRepo.EXPECT().
+			CreateRepository(ctx, gomock.Any()).
+			Return(nil)
+
+		err := svc.CreateRepository(ctx, &registryv1.CreateRepositoryRequest{
+			Name:           "my-repo",
+			OrganizationId: orgID,
+		}, CreateRepositoryOptions{})
+		require.NoError(t, err)
+	})
+
+	t.Run("failing to apply defaults keeps the repository", func(t *testing.T) {
+		svc, mockRepo, ctx, tmpDir := setup(t)
+
+		mockRepo.EXPECT().
+			GetOrganizationDefaultSdks(ctx, orgID).
+			Return([]SDK{SdkGoProtobuf}, nil)
+		mockRepo.EXPECT().
+			CreateRepository(ctx, gomock.Any()).
+			Return(nil)
+		mockRepo.EXPECT().
+			UpdateSdkPreferences(ctx, gomock.Any(), gomock.Any()).
+			Return(connect.NewError(connect.CodeInternal, errors.New("db down")))
+
+		err := svc.CreateRepository(ctx, &registryv1.CreateRepositoryRequest{
+			Name:           "my-repo",
+			OrganizationId: orgID,
+		}, CreateRepositoryOptions{})
+		require.NoError(t, err)
+
+		dirs, err := os.ReadDir(tmpDir)
+		require.NoError(t, err)
+		assert.Len(t, dirs, 1)
+	})
+
+	t.Run("failing to read defaults creates nothing", func(t *testing.T) {
+		svc, mockRepo, ctx, tmpDir := setup(t)
+
+		mockRepo.EXPECT().
+			GetOrganizationDefaultSdks(ctx, orgID).
+			Return(nil, connect.NewError(connect.CodeInternal, errors.New("db down")))
+
+		err := svc.CreateRepository(ctx, &registryv1.CreateRepositoryRequest{
+			Name:           "my-repo",
+			OrganizationId: orgID,
+		}, CreateRepositoryOptions{})
 		require.Error(t, err)
-		assert.ErrorContains(t, err, "database delete failed")
-		assert.ErrorIs(t, err, dbErr)
 
-		assert.DirExists(t, repoPath)
+		dirs, err := os.ReadDir(tmpDir)
+		require.NoError(t, err)
+		assert.Empty(t, dirs)
 	})
+}
 
-	t.Run("filesystem removal failure returns error", func(t *testing.T) {
+func TestService_GetRepository(t *testing.T) {
+	t.Run("success with sdk preferences", func(t *testing.T) {
 		ctrl := gomock.NewController(t)
 		mockRepo := NewMockRepository(ctrl)
 		mockOrgRepo := authorization.NewMockMemberRoleChecker(ctrl)
-		tmpDir := t.TempDir()
 
 		svc := &service{
-			rootPath:   tmpDir,
+			rootPath:   t.TempDir(),
 			repository: mockRepo,
 			orgRepo:    mockOrgRepo,
 		}
 
-		repoId := "test-repo-id"
-		orgID := "org-123"
-		userID := "user-123"
-		repoPath := filepath.Join(tmpDir, repoId)
-		repoName := "test-repo"
+		const repoID = "repo-123"
+		const orgID = "org-123"
+		const userID = "user-123"
 		ctx := testAuthInterceptor(userID)
 
-		require.NoError(t, os.MkdirAll(repoPath, 0o755))
+		sdkPrefs := []SdkPreferencesDTO{
+			{
+				RepositoryId: repoID,
+				Sdk:          SdkGoConnectRpc,
+				Status:       true,
+			},
+		}
 
 		mockRepo.EXPECT().
-			GetRepositoryById(ctx, repoId).
+			GetRepositoryWithSdkPreferences(ctx, repoID).
 			Return(&RepositoryDTO{
-				Id:             repoId,
-				Name:           repoName,
-				Path:           repoPath,
+				Id:             repoID,
+				Name:           "test-repo",
 				OrganizationId: orgID,
-			}, nil)
+				Visibility:     proto.VisibilityPrivate,
+				Path:           "/repos/" + repoID,
+			}, sdkPrefs, nil)
 
 		mockOrgRepo.EXPECT().
 			GetMemberRole(ctx, orgID, userID).
-			Return(authorization.MemberRoleOwner, nil)
+			Return(authorization.MemberRoleReader, nil)
 
 		mockRepo.EXPECT().
-			DeleteRepository(ctx, repoId).
-			Return(nil)
-
-		testFile := filepath.Join(repoPath, "test-file")
-		require.NoError(t, os.WriteFile(testFile, []byte("test"), 0o644))
-		require.NoError(t, os.Chmod(repoPath, 0o444))
+			IsRepositoryEmpty(ctx, "/repos/"+repoID).
+			Return(false, nil)
 
-		err := svc.DeleteRepository(ctx, &registryv1.DeleteRepositoryRequest{
-			RepositoryId: repoId,
-		})
+		repo, err := svc.GetRepository(ctx, &registryv1.GetRepositoryRequest{
+			Id: repoID,
+		}, GetRepositoryOptions{IncludeSdkPreferences: true})
+		assert.NoError(t, err)
+		assert.NotNil(t, repo)
+		assert.Equal(t, repoID, repo.GetId())
+		assert.Equal(t, "test-repo", repo.GetName())
+		assert.Equal(t, orgID, repo.GetOrganizationId())
+		assert.Equal(t, shared.Visibility_VISIBILITY_PRIVATE, repo.GetVisibility())
+		assert.Len(t, repo.GetSdkPreferences(), 1)
+		assert.Equal(t, registryv1.SDK_SDK_GO_CONNECTRPC, repo.GetSdkPreferences()[0].GetSdk())
+		assert.True(t, repo.GetSdkPreferences()[0].GetStatus())
+	})
 
-		require.Error(t, err)
-		require.ErrorContains(t, err, "failed to remove repository directory")
+	t.Run("sdk health summarizes the latest jobs", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		mockRepo := NewMockRepository(ctrl)
+		mockOrgRepo := authorization.NewMockMemberRoleChecker(ctrl)
+		mockQueue := NewMockSdkGenerationQueue(ctrl)
 
-		if _, err := os.Stat(repoPath); err == nil {
-			require.NoError(t, os.Chmod(repoPath, 0o755))
+		svc := &service{
+			rootPath:   t.TempDir(),
+			repository: mockRepo,
+			orgRepo:    mockOrgRepo,
+			sdkQueue:   mockQueue,
 		}
+
+		const repoID = "repo-123"
+		const orgID = "org-123"
+		const userID = "user-123"
+		ctx := testAuthInterceptor(userID)
+
+		mockRepo.EXPECT().
+			GetRepositoryWithSdkPreferences(ctx, repoID).
+			Return(&RepositoryDTO{
+				Id:             repoID,
+				OrganizationId: orgID,
+				Path:           "/repos/" + repoID,
+			}, []SdkPreferencesDTO{
+				{RepositoryId: repoID, Sdk: SdkGoProtobuf, Status: true},
+				{RepositoryId: repoID, Sdk: SdkGoConnectRpc, Status: true},
+				{RepositoryId: repoID, Sdk: SdkGoGrpc, Status: false},
+				{RepositoryId: repoID, Sdk: SdkJsBufbuildEs, Status: true},
+				{RepositoryId: repoID, Sdk: SdkJsProtobuf, Status: true},
+			}, nil)
+		mockOrgRepo.EXPECT().
+			GetMemberRole(ctx, orgID, userID).
+			Return(authorization.MemberRoleReader, nil)
+		mockQueue.EXPECT().
+			GetLatestSdkJobStatuses(ctx, repoID, []SDK{SdkGoProtobuf, SdkGoConnectRpc, SdkJsBufbuildEs, SdkJsProtobuf}).
+			Return([]SdkJobStatusDTO{
+				{Sdk: SdkGoProtobuf, Status: SdkGenerationJobStatusCompleted},
+				{Sdk: SdkGoConnectRpc, Status: SdkGenerationJobStatusFailed},
+				{Sdk: SdkJsBufbuildEs, Status: SdkGenerationJobStatusProcessing},
+			}, nil)
+		mockRepo.EXPECT().
+			IsRepositoryEmpty(ctx, "/repos/"+repoID).
+			Return(false, nil)
+
+		repo, err := svc.GetRepository(ctx, &registryv1.GetRepositoryRequest{
+			Id: repoID,
+		}, GetRepositoryOptions{IncludeSdkHealth: true})
+		require.NoError(t, err)
+		assert.Equal(t, []SdkHealth{
+			{Sdk: SdkGoProtobuf, Status: SdkHealthOk},
+			{Sdk: SdkGoConnectRpc, Status: SdkHealthFailed},
+			{Sdk: SdkJsBufbuildEs, Status: SdkHealthPending},
+		}, repo.SdkHealth)
+		assert.Empty(t, repo.GetSdkPreferences(), "preferences were not asked for")
 	})
 
-	t.Run("GetRepositories returns repositories for authenticated user", func(t *testing.T) {
+	t.Run("sdk health skips the queue without enabled sdks", func(t *testing.T) {
 		ctrl := gomock.NewController(t)
 		mockRepo := NewMockRepository(ctrl)
 		mockOrgRepo := authorization.NewMockMemberRoleChecker(ctrl)
@@ -642,53 +886,1649 @@ func TestService_DeleteRepository(t *testing.T) {
 			rootPath:   t.TempDir(),
 			repository: mockRepo,
 			orgRepo:    mockOrgRepo,
+			sdkQueue:   NewMockSdkGenerationQueue(ctrl),
 		}
 
+		const repoID = "repo-123"
+		const orgID = "org-123"
 		const userID = "user-123"
 		ctx := testAuthInterceptor(userID)
 
 		mockRepo.EXPECT().
-			GetRepositoriesByUserCount(ctx, userID).
-			Return(2, nil)
+			GetRepositoryWithSdkPreferences(ctx, repoID).
+			Return(&RepositoryDTO{
+				Id:             repoID,
+				OrganizationId: orgID,
+				Path:           "/repos/" + repoID,
+			}, []SdkPreferencesDTO{{RepositoryId: repoID, Sdk: SdkGoProtobuf, Status: false}}, nil)
+		mockOrgRepo.EXPECT().
+			GetMemberRole(ctx, orgID, userID).
+			Return(authorization.MemberRoleReader, nil)
+		mockRepo.EXPECT().
+			IsRepositoryEmpty(ctx, "/repos/"+repoID).
+			Return(false, nil)
 
-		repos := &[]RepositoryDTO{
-			{Id: "repo-1", Name: "first-repo", Visibility: proto.VisibilityPrivate},
-			{Id: "repo-2", Name: "second-repo", Visibility: proto.VisibilityPublic},
+		repo, err := svc.GetRepository(ctx, &registryv1.GetRepositoryRequest{
+			Id: repoID,
+		}, GetRepositoryOptions{IncludeSdkHealth: true})
+		require.NoError(t, err)
+		assert.Empty(t, repo.SdkHealth)
+	})
+
+	t.Run("default omits sdk preferences", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		mockRepo := NewMockRepository(ctrl)
+		mockOrgRepo := authorization.NewMockMemberRoleChecker(ctrl)
+
+		svc := &service{
+			rootPath:   t.TempDir(),
+			repository: mockRepo,
+			orgRepo:    mockOrgRepo,
 		}
 
+		const repoID = "repo-123"
+		const orgID = "org-123"
+		const userID = "user-123"
+		ctx := testAuthInterceptor(userID)
+
 		mockRepo.EXPECT().
-			GetRepositoriesByUser(ctx, userID, 1, 10).
-			Return(repos, nil)
+			GetRepositoryById(ctx, repoID).
+			Return(&RepositoryDTO{
+				Id:             repoID,
+				Name:           "test-repo",
+				OrganizationId: orgID,
+				Visibility:     proto.VisibilityPrivate,
+				Path:           "/repos/" + repoID,
+			}, nil)
+
+		mockOrgRepo.EXPECT().
+			GetMemberRole(ctx, orgID, userID).
+			Return(authorization.MemberRoleReader, nil)
+
+		mockRepo.EXPECT().
+			IsRepositoryEmpty(ctx, "/repos/"+repoID).
+			Return(false, nil)
+
+		repo, err := svc.GetRepository(ctx, &registryv1.GetRepositoryRequest{
+			Id: repoID,
+		}, GetRepositoryOptions{})
+		require.NoError(t, err)
+		assert.Equal(t, repoID, repo.GetId())
+		assert.Empty(t, repo.GetSdkPreferences())
+	})
+
+	t.Run("repository not found", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		mockRepo := NewMockRepository(ctrl)
+		mockOrgRepo := authorization.NewMockMemberRoleChecker(ctrl)
+
+		svc := &service{
+			rootPath:   t.TempDir(),
+			repository: mockRepo,
+			orgRepo:    mockOrgRepo,
+		}
+
+		const repoID = "nonexistent-repo"
+		const userID = "user-123"
+		ctx := testAuthInterceptor(userID)
+
+		mockRepo.EXPECT().
+			GetRepositoryById(ctx, repoID).
+			Return(nil, ErrRepositoryNotFound)
+
+		repo, err := svc.GetRepository(ctx, &registryv1.GetRepositoryRequest{
+			Id: repoID,
+		}, GetRepositoryOptions{})
+		assert.Error(t, err)
+		assert.Nil(t, repo)
+		assert.ErrorContains(t, err, "repository not found")
+	})
+
+	t.Run("user not member of organization", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		mockRepo := NewMockRepository(ctrl)
+		mockOrgRepo := authorization.NewMockMemberRoleChecker(ctrl)
+
+		svc := &service{
+			rootPath:   t.TempDir(),
+			repository: mockRepo,
+			orgRepo:    mockOrgRepo,
+		}
+
+		const repoID = "repo-123"
+		const orgID = "org-123"
+		const userID = "user-123"
+		ctx := testAuthInterceptor(userID)
+
+		mockRepo.EXPECT().
+			GetRepositoryById(ctx, repoID).
+			Return(&RepositoryDTO{
+				Id:             repoID,
+				Name:           "test-repo",
+				OrganizationId: orgID,
+				Visibility:     proto.VisibilityPrivate,
+			}, nil)
+
+		mockOrgRepo.EXPECT().
+			GetMemberRole(ctx, orgID, userID).
+			Return("", authorization.ErrMemberNotFound)
+
+		repo, err := svc.GetRepository(ctx, &registryv1.GetRepositoryRequest{
+			Id: repoID,
+		}, GetRepositoryOptions{})
+		assert.Error(t, err)
+		assert.Nil(t, repo)
+		assert.ErrorContains(t, err, "you are not a member of this organization")
+	})
+
+	t.Run("missing user ID in context", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		mockRepo := NewMockRepository(ctrl)
+		mockOrgRepo := authorization.NewMockMemberRoleChecker(ctrl)
+
+		svc := &service{
+			rootPath:   t.TempDir(),
+			repository: mockRepo,
+			orgRepo:    mockOrgRepo,
+		}
+
+		const repoID = "repo-123"
+		const orgID = "org-123"
+		ctx := context.Background()
+
+		mockRepo.EXPECT().
+			GetRepositoryById(ctx, repoID).
+			Return(&RepositoryDTO{
+				Id:             repoID,
+				Name:           "test-repo",
+				OrganizationId: orgID,
+				Visibility:     proto.VisibilityPrivate,
+			}, nil)
+
+		repo, err := svc.GetRepository(ctx, &registryv1.GetRepositoryRequest{
+			Id: repoID,
+		}, GetRepositoryOptions{})
+		assert.Error(t, err)
+		assert.Nil(t, repo)
+		assert.ErrorContains(t, err, "user not authenticated")
+	})
+}
+
+func TestService_UpdateRepository(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	t.Run("success", func(t *testing.T) {
+		mockRepo := NewMockRepository(ctrl)
+		mockOrgRepo := authorization.NewMockMemberRoleChecker(ctrl)
+
+		svc := &service{
+			rootPath:   t.TempDir(),
+			repository: mockRepo,
+			orgRepo:    mockOrgRepo,
+		}
+
+		const repoID = "repo-123"
+		const repoName = "test-repo"
+		const orgID = "org-123"
+		const userID = "user-123"
+		ctx := testAuthInterceptor(userID)
+
+		mockRepo.EXPECT().
+			GetRepositoryById(ctx, repoID).
+			Return(&RepositoryDTO{
+				Id:             repoID,
+				Name:           repoName,
+				OrganizationId: orgID,
+				Visibility:     proto.VisibilityPrivate,
+			}, nil)
+
+		mockOrgRepo.EXPECT().
+			GetMemberRole(ctx, orgID, userID).
+			Return(authorization.MemberRoleOwner, nil)
+
+		mockRepo.EXPECT().
+			UpdateRepository(ctx, gomock.Any()).
+			Return(nil)
+
+		err := svc.UpdateRepository(ctx, &registryv1.UpdateRepositoryRequest{
+			Id:         repoID,
+			Name:       repoName,
+			Visibility: shared.Visibility_VISIBILITY_PRIVATE,
+		})
+		assert.NoError(t, err)
+	})
+
+	t.Run("rename to a reserved name is rejected", func(t *testing.T) {
+		mockRepo := NewMockRepository(ctrl)
+		mockOrgRepo := authorization.NewMockMemberRoleChecker(ctrl)
+
+		svc := &service{
+			rootPath:      t.TempDir(),
+			repository:    mockRepo,
+			orgRepo:       mockOrgRepo,
+			reservedNames: naming.NewReservedNames([]string{"internal"}),
+		}
+
+		const repoID = "repo-123"
+		const orgID = "org-123"
+		const userID = "user-123"
+		ctx := testAuthInterceptor(userID)
+
+		mockRepo.EXPECT().
+			GetRepositoryById(ctx, repoID).
+			Return(&RepositoryDTO{
+				Id:             repoID,
+				Name:           "test-repo",
+				OrganizationId: orgID,
+				Visibility:     proto.VisibilityPrivate,
+			}, nil)
+
+		mockOrgRepo.EXPECT().
+			GetMemberRole(ctx, orgID, userID).
+			Return(authorization.MemberRoleOwner, nil)
+
+		err := svc.UpdateRepository(ctx, &registryv1.UpdateRepositoryRequest{
+			Id:         repoID,
+			Name:       "Internal",
+			Visibility: shared.Visibility_VISIBILITY_PRIVATE,
+		})
+		assert.Equal(t, connect.CodeInvalidArgument, connect.CodeOf(err))
+	})
+}
+
+func TestService_UpdateRepository_VisibilityPolicy(t *testing.T) {
+	const (
+		repoID = "repo-123"
+		orgID  = "org-123"
+		userID = "user-123"
+	)
+
+	tests := []struct {
+		name          string
+		forbidPublic  bool
+		orgVisibility proto.Visibility
+		current       proto.Visibility
+		requested     shared.Visibility
+		lookupOrg     bool
+		expectedCode  connect.Code
+	}{
+		{
+			name:         "policy off allows private to public",
+			current:      proto.VisibilityPrivate,
+			requested:    shared.Visibility_VISIBILITY_PUBLIC,
+			expectedCode: 0,
+		},
+		{
+			name:          "policy on rejects public repo in private org",
+			forbidPublic:  true,
+			orgVisibility: proto.VisibilityPrivate,
+			current:       proto.VisibilityPrivate,
+			requested:     shared.Visibility_VISIBILITY_PUBLIC,
+			lookupOrg:     true,
+			expectedCode:  connect.CodeFailedPrecondition,
+		},
+		{
+			name:          "policy on allows public repo in public org",
+			forbidPublic:  true,
+			orgVisibility: proto.VisibilityPublic,
+			current:       proto.VisibilityPrivate,
+			requested:     shared.Visibility_VISIBILITY_PUBLIC,
+			lookupOrg:     true,
+			expectedCode:  0,
+		},
+		{
+			name:         "policy on allows public to private",
+			forbidPublic: true,
+			current:      proto.VisibilityPublic,
+			requested:    shared.Visibility_VISIBILITY_PRIVATE,
+			expectedCode: 0,
+		},
+		{
+			name:         "policy on ignores unchanged public visibility",
+			forbidPublic: true,
+			current:      proto.VisibilityPublic,
+			requested:    shared.Visibility_VISIBILITY_PUBLIC,
+			expectedCode: 0,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			mockRepo := NewMockRepository(ctrl)
+			mockOrgRepo := authorization.NewMockMemberRoleChecker(ctrl)
+
+			cfg := &config.Config{}
+			cfg.Organization.ForbidPublicReposInPrivateOrgs = tt.forbidPublic
+			svc := &service{
+				repository: mockRepo,
+				orgRepo:    mockOrgRepo,
+				cfg:        cfg,
+			}
+
+			ctx := testAuthInterceptor(userID)
+
+			mockRepo.EXPECT().
+				GetRepositoryById(ctx, repoID).
+				Return(&RepositoryDTO{
+					Id:             repoID,
+					Name:           "test-repo",
+					OrganizationId: orgID,
+					Visibility:     tt.current,
+				}, nil)
+			mockOrgRepo.EXPECT().
+				GetMemberRole(ctx, orgID, userID).
+				Return(authorization.MemberRoleOwner, nil)
+
+			if tt.lookupOrg {
+				mockRepo.EXPECT().
+					GetOrganizationVisibility(ctx, orgID).
+					Return(tt.orgVisibility, nil)
+			}
+
+			if tt.expectedCode == 0 {
+				mockRepo.EXPECT().
+					UpdateRepository(ctx, gomock.Any()).
+					DoAndReturn(func(_ context.Context, repo *RepositoryDTO) error {
+						assert.Equal(t, proto.VisibilityMap[tt.requested], repo.Visibility)
+						return nil
+					})
+			}
+
+			err := svc.UpdateRepository(ctx, &registryv1.UpdateRepositoryRequest{
+				Id:         repoID,
+				Name:       "test-repo",
+				Visibility: tt.requested,
+			})
+
+			if tt.expectedCode == 0 {
+				assert.NoError(t, err)
+				return
+			}
+
+			require.Error(t, err)
+			assert.Equal(t, tt.expectedCode, connect.CodeOf(err))
+		})
+	}
+}
+
+func TestService_GetCloneUrls(t *testing.T) {
+	tests := []struct {
+		name     string
+		server   config.ServerConfig
+		sshPort  string
+		expected CloneUrls
+	}{
+		{
+			name: "standard ssh port uses scp-like form",
+			server: config.ServerConfig{
+				PublicUrl: "https://hasir.example.com",
+				SshHost:   "git@hasir.example.com",
+				SshPort:   "22",
+			},
+			expected: CloneUrls{
+				Http: "https://hasir.example.com/git/repo-123",
+				Ssh:  "git@hasir.example.com:repo-123.git",
+			},
+		},
+		{
+			name: "custom ssh port uses ssh url",
+			server: config.ServerConfig{
+				PublicUrl: "http://localhost:8080/",
+				SshHost:   "git@localhost",
+			},
+			sshPort: "2222",
+			expected: CloneUrls{
+				Http: "http://localhost:8080/git/repo-123",
+				Ssh:  "ssh://git@localhost:2222/repo-123.git",
+			},
+		},
+		{
+			name: "public ssh port overrides listen port",
+			server: config.ServerConfig{
+				PublicUrl: "https://hasir.example.com",
+				SshHost:   "git@hasir.example.com",
+				SshPort:   "22",
+			},
+			sshPort: "2222",
+			expected: CloneUrls{
+				Http: "https://hasir.example.com/git/repo-123",
+				Ssh:  "git@hasir.example.com:repo-123.git",
+			},
+		},
+		{
+			name:     "unset hosts produce no urls",
+			expected: CloneUrls{},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			svc := &service{cfg: &config.Config{
+				Server: tt.server,
+				Ssh:    config.SshConfig{Port: tt.sshPort},
+			}}
+
+			assert.Equal(t, tt.expected, svc.GetCloneUrls("repo-123"))
+		})
+	}
+}
+
+func TestService_SetOrganizationReposVisibility(t *testing.T) {
+	const (
+		orgID  = "org-123"
+		userID = "user-123"
+	)
+
+	t.Run("owner updates all repositories", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		mockRepo := NewMockRepository(ctrl)
+		mockOrgRepo := authorization.NewMockMemberRoleChecker(ctrl)
+
+		svc := &service{
+			repository: mockRepo,
+			orgRepo:    mockOrgRepo,
+		}
+
+		ctx := testAuthInterceptor(userID)
+
+		mockOrgRepo.EXPECT().
+			GetMemberRole(ctx, orgID, userID).
+			Return(authorization.MemberRoleOwner, nil)
+		mockRepo.EXPECT().
+			SetRepositoriesVisibilityByOrganizationId(ctx, orgID, proto.VisibilityPublic).
+			Return(3, nil)
+
+		updated, err := svc.SetOrganizationReposVisibility(ctx, orgID, proto.VisibilityPublic)
+
+		require.NoError(t, err)
+		assert.Equal(t, 3, updated)
+	})
+
+	t.Run("non-owner is denied", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		mockRepo := NewMockRepository(ctrl)
+		mockOrgRepo := authorization.NewMockMemberRoleChecker(ctrl)
+
+		svc := &service{
+			repository: mockRepo,
+			orgRepo:    mockOrgRepo,
+		}
+
+		ctx := testAuthInterceptor(userID)
+
+		mockOrgRepo.EXPECT().
+			GetMemberRole(ctx, orgID, userID).
+			Return(authorization.MemberRoleAuthor, nil)
+
+		_, err := svc.SetOrganizationReposVisibility(ctx, orgID, proto.VisibilityPublic)
+
+		require.Error(t, err)
+		assert.Equal(t, connect.CodePermissionDenied, connect.CodeOf(err))
+	})
+
+	t.Run("policy forbids public repositories in private organization", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		mockRepo := NewMockRepository(ctrl)
+		mockOrgRepo := authorization.NewMockMemberRoleChecker(ctrl)
+
+		cfg := &config.Config{}
+		cfg.Organization.ForbidPublicReposInPrivateOrgs = true
+		svc := &service{
+			repository: mockRepo,
+			orgRepo:    mockOrgRepo,
+			cfg:        cfg,
+		}
+
+		ctx := testAuthInterceptor(userID)
+
+		mockOrgRepo.EXPECT().
+			GetMemberRole(ctx, orgID, userID).
+			Return(authorization.MemberRoleOwner, nil)
+		mockRepo.EXPECT().
+			GetOrganizationVisibility(ctx, orgID).
+			Return(proto.VisibilityPrivate, nil)
+
+		_, err := svc.SetOrganizationReposVisibility(ctx, orgID, proto.VisibilityPublic)
+
+		require.ErrorIs(t, err, ErrPublicRepoInPrivateOrg)
+	})
+}
+
+func TestService_SetRepositoryArchived(t *testing.T) {
+	const (
+		repoID = "repo-123"
+		orgID  = "org-123"
+		userID = "user-123"
+	)
+
+	for _, archived := range []bool{true, false} {
+		t.Run(fmt.Sprintf("owner sets archived=%t", archived), func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			mockRepo := NewMockRepository(ctrl)
+			mockOrgRepo := authorization.NewMockMemberRoleChecker(ctrl)
+
+			svc := &service{
+				repository: mockRepo,
+				orgRepo:    mockOrgRepo,
+			}
+
+			ctx := testAuthInterceptor(userID)
+
+			mockRepo.EXPECT().
+				GetRepositoryById(ctx, repoID).
+				Return(&RepositoryDTO{Id: repoID, OrganizationId: orgID, Archived: !archived}, nil)
+			mockOrgRepo.EXPECT().
+				GetMemberRole(ctx, orgID, userID).
+				Return(authorization.MemberRoleOwner, nil)
+			mockRepo.EXPECT().
+				SetRepositoryArchived(ctx, repoID, archived).
+				Return(nil)
+
+			err := svc.SetRepositoryArchived(ctx, repoID, archived)
+
+			require.NoError(t, err)
+		})
+	}
+
+	t.Run("non-owner is denied", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		mockRepo := NewMockRepository(ctrl)
+		mockOrgRepo := authorization.NewMockMemberRoleChecker(ctrl)
+
+		svc := &service{
+			repository: mockRepo,
+			orgRepo:    mockOrgRepo,
+		}
+
+		ctx := testAuthInterceptor(userID)
+
+		mockRepo.EXPECT().
+			GetRepositoryById(ctx, repoID).
+			Return(&RepositoryDTO{Id: repoID, OrganizationId: orgID}, nil)
+		mockOrgRepo.EXPECT().
+			GetMemberRole(ctx, orgID, userID).
+			Return(authorization.MemberRoleAuthor, nil)
+
+		err := svc.SetRepositoryArchived(ctx, repoID, true)
+
+		require.Error(t, err)
+		assert.Equal(t, connect.CodePermissionDenied, connect.CodeOf(err))
+	})
+
+	t.Run("repository not found", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		mockRepo := NewMockRepository(ctrl)
+
+		svc := &service{
+			repository: mockRepo,
+		}
+
+		ctx := testAuthInterceptor(userID)
+
+		mockRepo.EXPECT().
+			GetRepositoryById(ctx, "missing").
+			Return(nil, connect.NewError(connect.CodeNotFound, errors.New("repository not found")))
+
+		err := svc.SetRepositoryArchived(ctx, "missing", true)
+
+		require.Error(t, err)
+	})
+}
+
+func TestService_IsPublicRepository(t *testing.T) {
+	tests := []struct {
+		name          string
+		repo          *RepositoryDTO
+		repoErr       error
+		orgVisibility proto.Visibility
+		wantPublic    bool
+		wantErr       bool
+	}{
+		{
+			name:          "public repository",
+			repo:          &RepositoryDTO{Id: "repo-123", OrganizationId: "org-123", Visibility: proto.VisibilityPublic},
+			orgVisibility: proto.VisibilityPublic,
+			wantPublic:    true,
+		},
+		{
+			name:          "public repository in a private organization",
+			repo:          &RepositoryDTO{Id: "repo-123", OrganizationId: "org-123", Visibility: proto.VisibilityPublic},
+			orgVisibility: proto.VisibilityPrivate,
+			wantPublic:    false,
+		},
+		{
+			name:       "private repository",
+			repo:       &RepositoryDTO{Id: "repo-123", OrganizationId: "org-123", Visibility: proto.VisibilityPrivate},
+			wantPublic: false,
+		},
+		{
+			name:    "repository lookup fails",
+			repoErr: connect.NewError(connect.CodeNotFound, errors.New("repository not found")),
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			mockRepo := NewMockRepository(ctrl)
+
+			mockRepo.EXPECT().
+				GetRepositoryById(gomock.Any(), "repo-123").
+				Return(tt.repo, tt.repoErr)
+			if tt.orgVisibility != "" {
+				mockRepo.EXPECT().
+					GetOrganizationVisibility(gomock.Any(), "org-123").
+					Return(tt.orgVisibility, nil)
+			}
+
+			svc := &service{repository: mockRepo}
+
+			isPublic, err := svc.IsPublicRepository(context.Background(), "./repos/repo-123")
+			if tt.wantErr {
+				require.Error(t, err)
+				return
+			}
+
+			require.NoError(t, err)
+			assert.Equal(t, tt.wantPublic, isPublic)
+		})
+	}
+}
+
+func TestService_ValidateSshAccess_ArchivedRepository(t *testing.T) {
+	const (
+		repoID = "repo-123"
+		orgID  = "org-123"
+		userID = "user-123"
+	)
+
+	newService := func(t *testing.T, archived bool) *service {
+		ctrl := gomock.NewController(t)
+		mockRepo := NewMockRepository(ctrl)
+		mockOrgRepo := authorization.NewMockMemberRoleChecker(ctrl)
+
+		mockRepo.EXPECT().
+			GetRepositoryById(gomock.Any(), repoID).
+			Return(&RepositoryDTO{Id: repoID, OrganizationId: orgID, Archived: archived}, nil)
+		mockOrgRepo.EXPECT().
+			GetMemberRole(gomock.Any(), orgID, userID).
+			Return(authorization.MemberRoleOwner, nil)
+
+		return &service{
+			repository: mockRepo,
+			orgRepo:    mockOrgRepo,
+		}
+	}
+
+	t.Run("write is rejected while archived", func(t *testing.T) {
+		svc := newService(t, true)
+
+		hasAccess, err := svc.ValidateSshAccess(context.Background(), userID, "./repos/"+repoID, SshOperationWrite)
+
+		assert.False(t, hasAccess)
+		assert.ErrorIs(t, err, ErrRepositoryArchived)
+	})
+
+	t.Run("read is allowed while archived", func(t *testing.T) {
+		svc := newService(t, true)
+
+		hasAccess, err := svc.ValidateSshAccess(context.Background(), userID, "./repos/"+repoID, SshOperationRead)
+
+		require.NoError(t, err)
+		assert.True(t, hasAccess)
+	})
+
+	t.Run("write is allowed once unarchived", func(t *testing.T) {
+		svc := newService(t, false)
+
+		hasAccess, err := svc.ValidateSshAccess(context.Background(), userID, "./repos/"+repoID, SshOperationWrite)
+
+		require.NoError(t, err)
+		assert.True(t, hasAccess)
+	})
+}
+
+func TestService_ValidateSshAccess_NonMember(t *testing.T) {
+	const (
+		repoID = "repo-123"
+		orgID  = "org-123"
+		userID = "user-123"
+	)
+
+	newService := func(t *testing.T, repoVisibility proto.Visibility) (*service, *MockRepository) {
+		ctrl := gomock.NewController(t)
+		mockRepo := NewMockRepository(ctrl)
+		mockOrgRepo := authorization.NewMockMemberRoleChecker(ctrl)
+
+		mockRepo.EXPECT().
+			GetRepositoryById(gomock.Any(), repoID).
+			Return(&RepositoryDTO{Id: repoID, OrganizationId: orgID, Visibility: repoVisibility}, nil)
+		mockOrgRepo.EXPECT().
+			GetMemberRole(gomock.Any(), orgID, userID).
+			Return("", authorization.ErrMemberNotFound)
+
+		return &service{
+			repository: mockRepo,
+			orgRepo:    mockOrgRepo,
+		}, mockRepo
+	}
+
+	t.Run("read of a public repository is allowed", func(t *testing.T) {
+		svc, mockRepo := newService(t, proto.VisibilityPublic)
+		mockRepo.EXPECT().
+			GetOrganizationVisibility(gomock.Any(), orgID).
+			Return(proto.VisibilityPublic, nil)
+
+		hasAccess, err := svc.ValidateSshAccess(context.Background(), userID, "./repos/"+repoID, SshOperationRead)
+
+		require.NoError(t, err)
+		assert.True(t, hasAccess)
+	})
+
+	t.Run("read of a public repository in a private organization is denied", func(t *testing.T) {
+		svc, mockRepo := newService(t, proto.VisibilityPublic)
+		mockRepo.EXPECT().
+			GetOrganizationVisibility(gomock.Any(), orgID).
+			Return(proto.VisibilityPrivate, nil)
+
+		hasAccess, err := svc.ValidateSshAccess(context.Background(), userID, "./repos/"+repoID, SshOperationRead)
+
+		require.NoError(t, err)
+		assert.False(t, hasAccess)
+	})
+
+	t.Run("read of a private repository is denied", func(t *testing.T) {
+		svc, _ := newService(t, proto.VisibilityPrivate)
+
+		hasAccess, err := svc.ValidateSshAccess(context.Background(), userID, "./repos/"+repoID, SshOperationRead)
+
+		require.NoError(t, err)
+		assert.False(t, hasAccess)
+	})
+
+	t.Run("write to a public repository is denied", func(t *testing.T) {
+		svc, _ := newService(t, proto.VisibilityPublic)
+
+		hasAccess, err := svc.ValidateSshAccess(context.Background(), userID, "./repos/"+repoID, SshOperationWrite)
+
+		require.NoError(t, err)
+		assert.False(t, hasAccess)
+	})
+}
+
+func TestService_TransferRepository(t *testing.T) {
+	const (
+		repoID    = "repo-123"
+		sourceOrg = "org-source"
+		targetOrg = "org-target"
+		userID    = "user-123"
+	)
+
+	setup := func(t *testing.T) (*service, *MockRepository, *authorization.MockMemberRoleChecker, context.Context) {
+		ctrl := gomock.NewController(t)
+		mockRepo := NewMockRepository(ctrl)
+		mockOrgRepo := authorization.NewMockMemberRoleChecker(ctrl)
+		ctx := testAuthInterceptor(userID)
+
+		mockRepo.EXPECT().
+			GetRepositoryById(ctx, repoID).
+			Return(&RepositoryDTO{
+				Id:             repoID,
+				Name:           "api",
+				OrganizationId: sourceOrg,
+				Visibility:     proto.VisibilityPrivate,
+			}, nil)
+
+		sdkPath := t.TempDir()
+		svc := &service{repository: mockRepo, orgRepo: mockOrgRepo, sdkPath: sdkPath, docsPath: sdkPath}
+		return svc, mockRepo, mockOrgRepo, ctx
+	}
+
+	t.Run("moves the repository and its generated output", func(t *testing.T) {
+		svc, mockRepo, mockOrgRepo, ctx := setup(t)
+		artifact := filepath.Join(svc.sdkPath, sourceOrg, repoID, "abc123", "go-protobuf", "go.mod")
+		require.NoError(t, os.MkdirAll(filepath.Dir(artifact), 0o750))
+		require.NoError(t, os.WriteFile(artifact, []byte("module example\n"), 0o600))
+
+		mockOrgRepo.EXPECT().GetMemberRole(ctx, sourceOrg, userID).Return(authorization.MemberRoleOwner, nil)
+		mockOrgRepo.EXPECT().GetMemberRole(ctx, targetOrg, userID).Return(authorization.MemberRoleOwner, nil)
+		mockRepo.EXPECT().TransferRepository(ctx, repoID, targetOrg).Return(nil)
+
+		require.NoError(t, svc.TransferRepository(ctx, repoID, targetOrg))
+
+		assert.NoFileExists(t, artifact)
+		assert.FileExists(t, filepath.Join(svc.sdkPath, targetOrg, repoID, "abc123", "go-protobuf", "go.mod"))
+	})
+
+	t.Run("name taken in the target organization", func(t *testing.T) {
+		svc, mockRepo, mockOrgRepo, ctx := setup(t)
+		artifact := filepath.Join(svc.sdkPath, sourceOrg, repoID, "abc123", "go-protobuf", "go.mod")
+		require.NoError(t, os.MkdirAll(filepath.Dir(artifact), 0o750))
+		require.NoError(t, os.WriteFile(artifact, []byte("module example\n"), 0o600))
+
+		mockOrgRepo.EXPECT().GetMemberRole(ctx, sourceOrg, userID).Return(authorization.MemberRoleOwner, nil)
+		mockOrgRepo.EXPECT().GetMemberRole(ctx, targetOrg, userID).Return(authorization.MemberRoleOwner, nil)
+		mockRepo.EXPECT().
+			TransferRepository(ctx, repoID, targetOrg).
+			Return(connect.NewError(connect.CodeAlreadyExists, errors.New("repository already exists")))
+
+		err := svc.TransferRepository(ctx, repoID, targetOrg)
+		assert.Equal(t, connect.CodeAlreadyExists, connect.CodeOf(err))
+		assert.FileExists(t, artifact)
+	})
+
+	t.Run("requires ownership of the target organization", func(t *testing.T) {
+		svc, _, mockOrgRepo, ctx := setup(t)
+
+		mockOrgRepo.EXPECT().GetMemberRole(ctx, sourceOrg, userID).Return(authorization.MemberRoleOwner, nil)
+		mockOrgRepo.EXPECT().GetMemberRole(ctx, targetOrg, userID).Return(authorization.MemberRoleAuthor, nil)
+
+		err := svc.TransferRepository(ctx, repoID, targetOrg)
+		assert.Equal(t, connect.CodePermissionDenied, connect.CodeOf(err))
+	})
+
+	t.Run("requires ownership of the source organization", func(t *testing.T) {
+		svc, _, mockOrgRepo, ctx := setup(t)
+
+		mockOrgRepo.EXPECT().GetMemberRole(ctx, sourceOrg, userID).Return(authorization.MemberRoleReader, nil)
+
+		err := svc.TransferRepository(ctx, repoID, targetOrg)
+		assert.Equal(t, connect.CodePermissionDenied, connect.CodeOf(err))
+	})
+
+	t.Run("transfer to the current organization is rejected", func(t *testing.T) {
+		svc, _, _, ctx := setup(t)
+
+		err := svc.TransferRepository(ctx, repoID, sourceOrg)
+		assert.Equal(t, connect.CodeInvalidArgument, connect.CodeOf(err))
+	})
+}
+
+func TestService_DeleteRepository(t *testing.T) {
+	t.Run("success", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		mockRepo := NewMockRepository(ctrl)
+		mockOrgRepo := authorization.NewMockMemberRoleChecker(ctrl)
+		tmpDir := t.TempDir()
+
+		svc := &service{
+			rootPath:   tmpDir,
+			repository: mockRepo,
+			orgRepo:    mockOrgRepo,
+		}
+
+		repoId := "test-repo-id"
+		orgID := "org-123"
+		userID := "user-123"
+		repoPath := filepath.Join(tmpDir, repoId)
+		repoName := "test-repo"
+		ctx := testAuthInterceptor(userID)
+
+		require.NoError(t, os.MkdirAll(repoPath, 0o755))
+
+		mockRepo.EXPECT().
+			GetRepositoryById(ctx, repoId).
+			Return(&RepositoryDTO{
+				Id:             repoId,
+				Name:           repoName,
+				Path:           repoPath,
+				OrganizationId: orgID,
+			}, nil)
+
+		mockOrgRepo.EXPECT().
+			GetMemberRole(ctx, orgID, userID).
+			Return(authorization.MemberRoleOwner, nil)
+
+		mockRepo.EXPECT().
+			DeleteRepository(ctx, repoId).
+			Return(nil)
+
+		err := svc.DeleteRepository(ctx, &registryv1.DeleteRepositoryRequest{
+			RepositoryId: repoId,
+		})
+
+		assert.NoError(t, err)
+		assert.NoDirExists(t, repoPath)
+	})
+
+	t.Run("repository not found", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		mockRepo := NewMockRepository(ctrl)
+		mockOrgRepo := authorization.NewMockMemberRoleChecker(ctrl)
+
+		svc := &service{
+			rootPath:   t.TempDir(),
+			repository: mockRepo,
+			orgRepo:    mockOrgRepo,
+		}
+
+		repoId := "nonexistent-repo-id"
+		userID := "user-123"
+		ctx := testAuthInterceptor(userID)
+
+		mockRepo.EXPECT().
+			GetRepositoryById(ctx, repoId).
+			Return(nil, ErrRepositoryNotFound)
+
+		err := svc.DeleteRepository(ctx, &registryv1.DeleteRepositoryRequest{
+			RepositoryId: repoId,
+		})
+		require.Error(t, err)
+		assert.ErrorContains(t, err, "repository not found")
+	})
+
+	t.Run("database delete error", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		mockRepo := NewMockRepository(ctrl)
+		mockOrgRepo := authorization.NewMockMemberRoleChecker(ctrl)
+		tmpDir := t.TempDir()
+
+		svc := &service{
+			rootPath:   tmpDir,
+			repository: mockRepo,
+			orgRepo:    mockOrgRepo,
+		}
+
+		repoId := "test-repo-id"
+		orgID := "org-123"
+		userID := "user-123"
+		repoPath := filepath.Join(tmpDir, repoId)
+		repoName := "test-repo"
+		ctx := testAuthInterceptor(userID)
+
+		require.NoError(t, os.MkdirAll(repoPath, 0o755))
+
+		dbErr := errors.New("database delete failed")
+
+		mockRepo.EXPECT().
+			GetRepositoryById(ctx, repoId).
+			Return(&RepositoryDTO{
+				Id:             repoId,
+				Name:           repoName,
+				Path:           repoPath,
+				OrganizationId: orgID,
+			}, nil)
+
+		mockOrgRepo.EXPECT().
+			GetMemberRole(ctx, orgID, userID).
+			Return(authorization.MemberRoleOwner, nil)
+
+		mockRepo.EXPECT().
+			DeleteRepository(ctx, repoId).
+			Return(dbErr)
+
+		err := svc.DeleteRepository(ctx, &registryv1.DeleteRepositoryRequest{
+			RepositoryId: repoId,
+		})
+		require.Error(t, err)
+		assert.ErrorContains(t, err, "database delete failed")
+		assert.ErrorIs(t, err, dbErr)
+
+		assert.DirExists(t, repoPath)
+	})
+
+	t.Run("filesystem removal failure returns error", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		mockRepo := NewMockRepository(ctrl)
+		mockOrgRepo := authorization.NewMockMemberRoleChecker(ctrl)
+		tmpDir := t.TempDir()
+
+		svc := &service{
+			rootPath:   tmpDir,
+			repository: mockRepo,
+			orgRepo:    mockOrgRepo,
+		}
+
+		repoId := "test-repo-id"
+		orgID := "org-123"
+		userID := "user-123"
+		repoPath := filepath.Join(tmpDir, repoId)
+		repoName := "test-repo"
+		ctx := testAuthInterceptor(userID)
+
+		require.NoError(t, os.MkdirAll(repoPath, 0o755))
+
+		mockRepo.EXPECT().
+			GetRepositoryById(ctx, repoId).
+			Return(&RepositoryDTO{
+				Id:             repoId,
+				Name:           repoName,
+				Path:           repoPath,
+				OrganizationId: orgID,
+			}, nil)
+
+		mockOrgRepo.EXPECT().
+			GetMemberRole(ctx, orgID, userID).
+			Return(authorization.MemberRoleOwner, nil)
+
+		mockRepo.EXPECT().
+			DeleteRepository(ctx, repoId).
+			Return(nil)
+
+		testFile := filepath.Join(repoPath, "test-file")
+		require.NoError(t, os.WriteFile(testFile, []byte("test"), 0o644))
+		require.NoError(t, os.Chmod(repoPath, 0o444))
+
+		err := svc.DeleteRepository(ctx, &registryv1.DeleteRepositoryRequest{
+			RepositoryId: repoId,
+		})
+
+		require.Error(t, err)
+		require.ErrorContains(t, err, "failed to remove repository directory")
+
+		if _, err := os.Stat(repoPath); err == nil {
+			require.NoError(t, os.Chmod(repoPath, 0o755))
+		}
+	})
+
+	t.Run("GetRepositories returns repositories for authenticated user", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		mockRepo := NewMockRepository(ctrl)
+		mockOrgRepo := authorization.NewMockMemberRoleChecker(ctrl)
+
+		svc := &service{
+			rootPath:   t.TempDir(),
+			repository: mockRepo,
+			orgRepo:    mockOrgRepo,
+		}
+
+		const userID = "user-123"
+		ctx := testAuthInterceptor(userID)
+
+		mockRepo.EXPECT().
+			GetRepositoriesByUserCount(ctx, userID).
+			Return(2, nil)
+
+		repos := &[]RepositoryDTO{
+			{Id: "repo-1", Name: "first-repo", Visibility: proto.VisibilityPrivate},
+			{Id: "repo-2", Name: "second-repo", Visibility: proto.VisibilityPublic, Archived: true},
+		}
+
+		mockRepo.EXPECT().
+			GetRepositoriesByUser(ctx, userID, 1, 10).
+			Return(repos, nil)
+
+		sdkPrefsMap := map[string][]SdkPreferencesDTO{
+			"repo-1": {
+				{
+					Id:           "pref-1",
+					RepositoryId: "repo-1",
+					Sdk:          SdkGoConnectRpc,
+					Status:       true,
+				},
+			},
+		}
+
+		mockRepo.EXPECT().
+			GetSdkPreferencesByRepositoryIds(ctx, []string{"repo-1", "repo-2"}).
+			Return(sdkPrefsMap, nil)
+
+		resp, err := svc.GetRepositories(ctx, nil, 1, 10)
+		require.NoError(t, err)
+		assert.Len(t, resp.GetRepositories(), 2)
+		assert.Equal(t, "repo-1", resp.GetRepositories()[0].GetId())
+		assert.Equal(t, "first-repo", resp.GetRepositories()[0].GetName())
+		assert.Len(t, resp.GetRepositories()[0].GetSdkPreferences(), 1)
+		assert.Equal(t, "repo-2", resp.GetRepositories()[1].GetId())
+		assert.Equal(t, "second-repo", resp.GetRepositories()[1].GetName())
+		assert.Len(t, resp.GetRepositories()[1].GetSdkPreferences(), 0)
+		assert.Equal(t, []string{"repo-2"}, resp.ArchivedIds)
+	})
+}
+
+func TestService_DeleteRepositoriesByOrganization(t *testing.T) {
+	const orgID = "org-123"
+
+	setup := func(t *testing.T) (*service, []string) {
+		ctrl := gomock.NewController(t)
+		mockRepo := NewMockRepository(ctrl)
+		tmpDir := t.TempDir()
+
+		svc := &service{
+			rootPath:   tmpDir,
+			repository: mockRepo,
+		}
+
+		var repos []RepositoryDTO
+		var paths []string
+		for _, id := range []string{"repo-1", "repo-2"} {
+			repoPath := filepath.Join(tmpDir, id)
+			require.NoError(t, os.MkdirAll(repoPath, 0o755))
+			repos = append(repos, RepositoryDTO{Id: id, Path: repoPath, OrganizationId: orgID})
+			paths = append(paths, repoPath)
+		}
+
+		mockRepo.EXPECT().
+			GetRepositoriesByOrganizationId(primaryCtx(), orgID).
+			Return(&repos, nil)
+
+		return svc, paths
+	}
+
+	t.Run("removes directories once the organization is deleted", func(t *testing.T) {
+		svc, paths := setup(t)
+
+		err := svc.DeleteRepositoriesByOrganization(context.Background(), orgID, func(context.Context) error {
+			for _, repoPath := range paths {
+				assert.DirExists(t, repoPath)
+
+				ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+				_, err := svc.pathLocks.lockContext(ctx, repoPath)
+				cancel()
+				assert.Error(t, err, "path should be locked while the rows are deleted")
+			}
+			return nil
+		})
+		require.NoError(t, err)
+
+		for _, repoPath := range paths {
+			assert.NoDirExists(t, repoPath)
+		}
+	})
+
+	t.Run("failed deletion keeps the directories", func(t *testing.T) {
+		svc, paths := setup(t)
+
+		err := svc.DeleteRepositoriesByOrganization(context.Background(), orgID, func(context.Context) error {
+			return connect.NewError(connect.CodeInternal, errors.New("db down"))
+		})
+		require.Error(t, err)
+
+		for _, repoPath := range paths {
+			assert.DirExists(t, repoPath)
+		}
+	})
+}
+
+func TestService_GetCommits(t *testing.T) {
+	t.Run("success", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		mockRepo := NewMockRepository(ctrl)
+		mockOrgRepo := authorization.NewMockMemberRoleChecker(ctrl)
+
+		svc := &service{
+			rootPath:   "./repos",
+			repository: mockRepo,
+			orgRepo:    mockOrgRepo,
+		}
+
+		const userID = "user-123"
+		const orgID = "org-123"
+		const repoID = "repo-123"
+		repoPath := filepath.Join("./repos", repoID)
+
+		ctx := testAuthInterceptor(userID)
+
+		mockRepo.EXPECT().
+			GetRepositoryById(ctx, repoID).
+			Return(&RepositoryDTO{
+				Id:             repoID,
+				Name:           "test-repo",
+				OrganizationId: orgID,
+				Path:           repoPath,
+			}, nil)
+
+		mockOrgRepo.EXPECT().
+			GetMemberRole(ctx, orgID, userID).
+			Return(authorization.MemberRoleReader, nil)
+
+		expectedCommits := []*registryv1.Commit{
+			{
+				Id:      "abc123",
+				Message: "Initial commit",
+				User: &registryv1.Commit_User{
+					Id:       "user@example.com",
+					Username: "Test User",
+				},
+			},
+		}
+
+		mockRepo.EXPECT().
+			GetCommits(ctx, repoPath, "", 1, 10).
+			Return(expectedCommits, 1, nil)
+
+		req := &registryv1.GetCommitsRequest{Id: repoID}
+		resp, err := svc.GetCommits(ctx, req, "")
+
+		require.NoError(t, err)
+		assert.NotNil(t, resp)
+		assert.Len(t, resp.GetCommits(), 1)
+		assert.Equal(t, "abc123", resp.GetCommits()[0].GetId())
+		assert.Equal(t, int32(1), resp.GetTotalPage())
+		assert.Equal(t, int32(0), resp.GetNextPage())
+	})
+
+	t.Run("repository not found", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		mockRepo := NewMockRepository(ctrl)
+		mockOrgRepo := authorization.NewMockMemberRoleChecker(ctrl)
+
+		svc := &service{
+			rootPath:   "./repos",
+			repository: mockRepo,
+			orgRepo:    mockOrgRepo,
+		}
+
+		const userID = "user-123"
+		ctx := testAuthInterceptor(userID)
+
+		mockRepo.EXPECT().
+			GetRepositoryById(ctx, "non-existent").
+			Return(nil, errors.New("repository not found"))
+
+		req := &registryv1.GetCommitsRequest{Id: "non-existent"}
+		_, err := svc.GetCommits(ctx, req, "")
+
+		require.Error(t, err)
+	})
+
+	t.Run("user not member of organization", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		mockRepo := NewMockRepository(ctrl)
+		mockOrgRepo := authorization.NewMockMemberRoleChecker(ctrl)
+
+		svc := &service{
+			rootPath:   "./repos",
+			repository: mockRepo,
+			orgRepo:    mockOrgRepo,
+		}
+
+		const userID = "user-123"
+		const orgID = "org-123"
+		const repoID = "repo-123"
+
+		ctx := testAuthInterceptor(userID)
+
+		mockRepo.EXPECT().
+			GetRepositoryById(ctx, repoID).
+			Return(&RepositoryDTO{
+				Id:             repoID,
+				Name:           "test-repo",
+				OrganizationId: orgID,
+				Path:           "./repos/repo-123",
+			}, nil)
+
+		mockOrgRepo.EXPECT().
+			GetMemberRole(ctx, orgID, userID).
+			Return("", errors.New("user is not a member"))
+
+		req := &registryv1.GetCommitsRequest{Id: repoID}
+		_, err := svc.GetCommits(ctx, req, "")
+
+		require.Error(t, err)
+	})
+
+	t.Run("success with pagination", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		mockRepo := NewMockRepository(ctrl)
+		mockOrgRepo := authorization.NewMockMemberRoleChecker(ctrl)
+
+		svc := &service{
+			rootPath:   "./repos",
+			repository: mockRepo,
+			orgRepo:    mockOrgRepo,
+		}
+
+		const userID = "user-123"
+		const orgID = "org-123"
+		const repoID = "repo-123"
+		repoPath := filepath.Join("./repos", repoID)
+
+		ctx := testAuthInterceptor(userID)
+
+		mockRepo.EXPECT().
+			GetRepositoryById(ctx, repoID).
+			Return(&RepositoryDTO{
+				Id:             repoID,
+				Name:           "test-repo",
+				OrganizationId: orgID,
+				Path:           repoPath,
+			}, nil)
+
+		mockOrgRepo.EXPECT().
+			GetMemberRole(ctx, orgID, userID).
+			Return(authorization.MemberRoleReader, nil)
+
+		expectedCommits := []*registryv1.Commit{
+			{
+				Id:      "commit-1",
+				Message: "First commit",
+				User: &registryv1.Commit_User{
+					Id:       "user1@example.com",
+					Username: "User 1",
+				},
+			},
+			{
+				Id:      "commit-2",
+				Message: "Second commit",
+				User: &registryv1.Commit_User{
+					Id:       "user2@example.com",
+					Username: "User 2",
+				},
+			},
+		}
+
+		mockRepo.EXPECT().
+			GetCommits(ctx, repoPath, "", 2, 5).
+			Return(expectedCommits, 12, nil)
+
+		req := &registryv1.GetCommitsRequest{
+			Id: repoID,
+			Pagination: &shared.Pagination{
+				Page:      2,
+				PageLimit: 5,
+			},
+		}
+		resp, err := svc.GetCommits(ctx, req, "")
+
+		require.NoError(t, err)
+		assert.NotNil(t, resp)
+		assert.Len(t, resp.GetCommits(), 2)
+		assert.Equal(t, int32(3), resp.GetTotalPage())
+		assert.Equal(t, int32(3), resp.GetNextPage())
+	})
+
+	t.Run("empty repository", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		mockRepo := NewMockRepository(ctrl)
+		mockOrgRepo := authorization.NewMockMemberRoleChecker(ctrl)
+
+		svc := &service{
+			rootPath:   "./repos",
+			repository: mockRepo,
+			orgRepo:    mockOrgRepo,
+		}
+
+		const userID = "user-123"
+		const orgID = "org-123"
+		const repoID = "repo-123"
+		repoPath := filepath.Join("./repos", repoID)
+
+		ctx := testAuthInterceptor(userID)
+
+		mockRepo.EXPECT().
+			GetRepositoryById(ctx, repoID).
+			Return(&RepositoryDTO{Id: repoID, OrganizationId: orgID, Path: repoPath}, nil)
+		mockOrgRepo.EXPECT().
+			GetMemberRole(ctx, orgID, userID).
+			Return(authorization.MemberRoleReader, nil)
+		mockRepo.EXPECT().
+			GetCommits(ctx, repoPath, "", 1, 10).
+			Return(nil, 0, ErrRepositoryEmpty)
+
+		resp, err := svc.GetCommits(ctx, &registryv1.GetCommitsRequest{Id: repoID}, "")
+		require.NoError(t, err)
+		assert.True(t, resp.Empty)
+		assert.Empty(t, resp.GetCommits())
+		assert.Equal(t, int32(1), resp.GetTotalPage())
+	})
+}
+
+func TestService_GetRecentCommit(t *testing.T) {
+	t.Run("empty repository", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		mockRepo := NewMockRepository(ctrl)
+		mockOrgRepo := authorization.NewMockMemberRoleChecker(ctrl)
+
+		svc := &service{
+			rootPath:   "./repos",
+			repository: mockRepo,
+			orgRepo:    mockOrgRepo,
+		}
+
+		const userID = "user-123"
+		const orgID = "org-123"
+		const repoID = "repo-123"
+		repoPath := filepath.Join("./repos", repoID)
+
+		ctx := testAuthInterceptor(userID)
+
+		mockRepo.EXPECT().
+			GetRepositoryById(ctx, repoID).
+			Return(&RepositoryDTO{Id: repoID, OrganizationId: orgID, Path: repoPath}, nil)
+		mockOrgRepo.EXPECT().
+			GetMemberRole(ctx, orgID, userID).
+			Return(authorization.MemberRoleReader, nil)
+		mockRepo.EXPECT().
+			GetRecentCommit(ctx, repoPath).
+			Return(nil, ErrRepositoryEmpty)
+
+		resp, err := svc.GetRecentCommit(ctx, &registryv1.GetRecentCommitRequest{RepositoryId: repoID})
+		require.NoError(t, err)
+		assert.True(t, resp.Empty)
+		assert.Empty(t, resp.GetId())
+	})
+}
+
+func TestService_GetSdkManifest(t *testing.T) {
+	const (
+		repoID     = "repo-123"
+		orgID      = "org-123"
+		userID     = "user-123"
+		commitHash = "abc123def456"
+	)
+
+	sdkPath := t.TempDir()
+	writeArtifact := func(sdkDir, name, content string) {
+		path := filepath.Join(sdkPath, orgID, repoID, commitHash, sdkDir, name)
+		require.NoError(t, os.MkdirAll(filepath.Dir(path), 0o750))
+		require.NoError(t, os.WriteFile(path, []byte(content), 0o600))
+	}
+	writeArtifact("go-protobuf", "go.mod", "module example\n")
+	writeArtifact("go-protobuf", "v1/user.pb.go", "package v1\n")
+	writeArtifact("go-protobuf", ".git/HEAD", "ref: refs/heads/main\n")
+	writeArtifact("js-bufbuild-es", "package.json", "{}")
+
+	setup := func(t *testing.T, role string, roleErr error) (*service, *MockRepository, context.Context) {
+		ctrl := gomock.NewController(t)
+		mockRepo := NewMockRepository(ctrl)
+		mockOrgRepo := authorization.NewMockMemberRoleChecker(ctrl)
+		ctx := testAuthInterceptor(userID)
+
+		mockRepo.EXPECT().
+			GetRepositoryById(ctx, repoID).
+			Return(&RepositoryDTO{Id: repoID, OrganizationId: orgID}, nil)
+		mockOrgRepo.EXPECT().
+			GetMemberRole(ctx, orgID, userID).
+			Return(role, roleErr)
+
+		return &service{repository: mockRepo, orgRepo: mockOrgRepo, sdkPath: sdkPath}, mockRepo, ctx
+	}
+
+	t.Run("lists generated SDKs", func(t *testing.T) {
+		svc, mockRepo, ctx := setup(t, authorization.MemberRoleReader, nil)
+		mockRepo.EXPECT().GetSdkPreferences(ctx, repoID).Return(nil, nil)
+
+		manifest, err := svc.GetSdkManifest(ctx, repoID, commitHash)
+		require.NoError(t, err)
+		require.Len(t, manifest.Sdks, 2)
+
+		goSdk := manifest.Sdks[0]
+		assert.Equal(t, SdkGoProtobuf, goSdk.Sdk)
+		assert.Equal(t, orgID+"/"+repoID+"/"+commitHash+"/go-protobuf", goSdk.Path)
+		assert.ElementsMatch(t, []SdkArtifact{
+			{Path: "go.mod", Size: int64(len("module example\n"))},
+			{Path: "v1/user.pb.go", Size: int64(len("package v1\n"))},
+		}, goSdk.Artifacts)
+		assert.Equal(t, int64(len("module example\n")+len("package v1\n")), goSdk.TotalSize)
+
+		jsSdk := manifest.Sdks[1]
+		assert.Equal(t, SdkJsBufbuildEs, jsSdk.Sdk)
+		assert.Equal(t, []SdkArtifact{{Path: "package.json", Size: 2}}, jsSdk.Artifacts)
+	})
+
+	t.Run("nothing generated yet returns an empty manifest", func(t *testing.T) {
+		svc, mockRepo, ctx := setup(t, authorization.MemberRoleReader, nil)
+		mockRepo.EXPECT().GetSdkPreferences(ctx, repoID).Return(nil, nil)
+
+		manifest, err := svc.GetSdkManifest(ctx, repoID, "fedcba987654")
+		require.NoError(t, err)
+		assert.Empty(t, manifest.Sdks)
+	})
+
+	t.Run("skipped commit lists the output of the last generated commit", func(t *testing.T) {
+		const skippedCommit = "fedcba987654"
+		svc, mockRepo, ctx := setup(t, authorization.MemberRoleReader, nil)
+
+		lastGenerated := commitHash
+		mockRepo.EXPECT().GetSdkPreferences(ctx, repoID).Return([]SdkPreferencesDTO{
+			{Sdk: SdkGoProtobuf, Status: true, LastGeneratedCommit: &lastGenerated},
+			{Sdk: SdkJsBufbuildEs, Status: true, LastGeneratedCommit: &lastGenerated},
+		}, nil)
+		mockRepo.EXPECT().
+			HasSdkInputChanges(ctx, gomock.Any(), commitHash, skippedCommit).
+			Return(false, nil)
+
+		manifest, err := svc.GetSdkManifest(ctx, repoID, skippedCommit)
+		require.NoError(t, err)
+		assert.Equal(t, skippedCommit, manifest.CommitHash)
+		require.Len(t, manifest.Sdks, 2)
+		assert.Equal(t, orgID+"/"+repoID+"/"+commitHash+"/go-protobuf", manifest.Sdks[0].Path)
+		assert.Equal(t, orgID+"/"+repoID+"/"+commitHash+"/js-bufbuild-es", manifest.Sdks[1].Path)
+	})
+
+	t.Run("commit with changed inputs is not pointed at older output", func(t *testing.T) {
+		const changedCommit = "fedcba987654"
+		svc, mockRepo, ctx := setup(t, authorization.MemberRoleReader, nil)
+
+		lastGenerated := commitHash
+		mockRepo.EXPECT().GetSdkPreferences(ctx, repoID).Return([]SdkPreferencesDTO{
+			{Sdk: SdkGoProtobuf, Status: true, LastGeneratedCommit: &lastGenerated},
+		}, nil)
+		mockRepo.EXPECT().
+			HasSdkInputChanges(ctx, gomock.Any(), commitHash, changedCommit).
+			Return(true, nil)
+
+		manifest, err := svc.GetSdkManifest(ctx, repoID, changedCommit)
+		require.NoError(t, err)
+		assert.Empty(t, manifest.Sdks)
+	})
+
+	t.Run("non-member is denied", func(t *testing.T) {
+		svc, _, ctx := setup(t, "", authorization.ErrMemberNotFound)
+
+		_, err := svc.GetSdkManifest(ctx, repoID, commitHash)
+		assert.Equal(t, connect.CodePermissionDenied, connect.CodeOf(err))
+	})
+
+	t.Run("commit must not escape the output directory", func(t *testing.T) {
+		svc := &service{sdkPath: sdkPath}
+
+		_, err := svc.GetSdkManifest(testAuthInterceptor(userID), repoID, "../../etc")
+		assert.Equal(t, connect.CodeInvalidArgument, connect.CodeOf(err))
+	})
+}
+
+func TestService_GetUserActivityFeed(t *testing.T) {
+	t.Run("returns the repository feed", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		mockRepo := NewMockRepository(ctrl)
+
+		svc := &service{repository: mockRepo}
+
+		activity := []ActivityDTO{
+			{Id: "push-1", Type: ActivityTypePush, RepositoryId: "repo-1", OrganizationId: "org-1"},
+			{Id: "job-1", Type: ActivityTypeSdkGeneration, RepositoryId: "repo-2", OrganizationId: "org-2"},
+		}
+		mockRepo.EXPECT().
+			GetUserActivityFeed(gomock.Any(), "user-123", 2, 20).
+			Return(activity, nil)
+
+		feed, err := svc.GetUserActivityFeed(t.Context(), "user-123", 2, 20)
+		require.NoError(t, err)
+		assert.Equal(t, activity, feed)
+	})
+
+	t.Run("normalizes paging", func(t *testing.T) {
+		for name, tc := range map[string]struct {
+			page, pageSize         int
+			wantPage, wantPageSize int
+		}{
+			"defaults":      {page: 0, pageSize: 0, wantPage: 1, wantPageSize: 10},
+			"caps pageSize": {page: 3, pageSize: 500, wantPage: 3, wantPageSize: 100},
+		} {
+			t.Run(name, func(t *testing.T) {
+				ctrl := gomock.NewController(t)
+				mockRepo := NewMockRepository(ctrl)
+
+				svc := &service{repository: mockRepo}
+
+				mockRepo.EXPECT().
+					GetUserActivityFeed(gomock.Any(), "user-123", tc.wantPage, tc.wantPageSize).
+					Return(nil, nil)
+
+				_, err := svc.GetUserActivityFeed(t.Context(), "user-123", tc.page, tc.pageSize)
+				require.NoError(t, err)
+			})
+		}
+	})
+}
+
+func TestService_GetFileTree(t *testing.T) {
+	t.Run("success - root directory", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		mockRepo := NewMockRepository(ctrl)
+		mockOrgRepo := authorization.NewMockMemberRoleChecker(ctrl)
+
+		svc := &service{
+			rootPath:   "./repos",
+			repository: mockRepo,
+			orgRepo:    mockOrgRepo,
+		}
+
+		const userID = "user-123"
+		const orgID = "org-123"
+		const repoID = "repo-123"
+		repoPath := filepath.Join("./repos", repoID)
+
+		ctx := testAuthInterceptor(userID)
+
+		mockRepo.EXPECT().
+			GetRepositoryById(ctx, repoID).
+			Return(&RepositoryDTO{
+				Id:             repoID,
+				Name:           "test-repo",
+				OrganizationId: orgID,
+				Path:           repoPath,
+			}, nil)
 
-		sdkPrefsMap := map[string][]SdkPreferencesDTO{
-			"repo-1": {
+		mockOrgRepo.EXPECT().
+			GetMemberRole(ctx, orgID, userID).
+			Return(authorization.MemberRoleReader, nil)
+
+		expectedFileTree := &registryv1.GetFileTreeResponse{
+			Nodes: []*registryv1.FileTreeNode{
 				{
-					Id:           "pref-1",
-					RepositoryId: "repo-1",
-					Sdk:          SdkGoConnectRpc,
-					Status:       true,
+					Name: "README.md",
+					Path: "README.md",
+					Type: registryv1.NodeType_NODE_TYPE_FILE,
+				},
+				{
+					Name: "src",
+					Path: "src",
+					Type: registryv1.NodeType_NODE_TYPE_DIRECTORY,
 				},
 			},
 		}
 
 		mockRepo.EXPECT().
-			GetSdkPreferencesByRepositoryIds(ctx, []string{"repo-1", "repo-2"}).
-			Return(sdkPrefsMap, nil)
+			GetFileTree(ctx, repoPath, "", (*string)(nil), FileTreePage{}).
+			Return(&FileTree{GetFileTreeResponse: expectedFileTree}, nil)
 
-		resp, err := svc.GetRepositories(ctx, nil, 1, 10)
-		require.NoError(t, err)
-		assert.Len(t, resp.GetRepositories(), 2)
-		assert.Equal(t, "repo-1", resp.GetRepositories()[0].GetId())
-		assert.Equal(t, "first-repo", resp.GetRepositories()[0].GetName())
-		assert.Len(t, resp.GetRepositories()[0].GetSdkPreferences(), 1)
-		assert.Equal(t, "repo-2", resp.GetRepositories()[1].GetId())
-		assert.Equal(t, "second-repo", resp.GetRepositories()[1].GetName())
-		assert.Len(t, resp.GetRepositories()[1].GetSdkPreferences(), 0)
+		req := &registryv1.GetFileTreeRequest{Id: repoID}
+		resp, err := svc.GetFileTree(ctx, req, "", FileTreePage{})
+
+		assert.NoError(t, err)
+		assert.NotNil(t, resp)
+		assert.Len(t, resp.GetNodes(), 2)
+		assert.Equal(t, "README.md", resp.GetNodes()[0].GetName())
+		assert.Equal(t, "src", resp.GetNodes()[1].GetName())
 	})
-}
 
-func TestService_GetCommits(t *testing.T) {
-	t.Run("success", func(t *testing.T) {
+	t.Run("success - subdirectory", func(t *testing.T) {
 		ctrl := gomock.NewController(t)
 		mockRepo := NewMockRepository(ctrl)
 		mockOrgRepo := authorization.NewMockMemberRoleChecker(ctrl)
@@ -703,6 +2543,7 @@ func TestService_GetCommits(t *testing.T) {
 		const orgID = "org-123"
 		const repoID = "repo-123"
 		repoPath := filepath.Join("./repos", repoID)
+		subPath := "src"
 
 		ctx := testAuthInterceptor(userID)
 
@@ -719,30 +2560,30 @@ func TestService_GetCommits(t *testing.T) {
 			GetMemberRole(ctx, orgID, userID).
 			Return(authorization.MemberRoleReader, nil)
 
-		expectedCommits := []*registryv1.Commit{
-			{
-				Id:      "abc123",
-				Message: "Initial commit",
-				User: &registryv1.Commit_User{
-					Id:       "user@example.com",
-					Username: "Test User",
+		expectedFileTree := &registryv1.GetFileTreeResponse{
+			Nodes: []*registryv1.FileTreeNode{
+				{
+					Name: "main.go",
+					Path: "src/main.go",
+					Type: registryv1.NodeType_NODE_TYPE_FILE,
 				},
 			},
 		}
 
 		mockRepo.EXPECT().
-			GetCommits(ctx, repoPath, 1, 10).
-			Return(expectedCommits, 1, nil)
+			GetFileTree(ctx, repoPath, "", &subPath, FileTreePage{}).
+			Return(&FileTree{GetFileTreeResponse: expectedFileTree}, nil)
 
-		req := &registryv1.GetCommitsRequest{Id: repoID}
-		resp, err := svc.GetCommits(ctx, req)
+		req := &registryv1.GetFileTreeRequest{
+			Id:   repoID,
+			Path: &subPath,
+		}
+		resp, err := svc.GetFileTree(ctx, req, "", FileTreePage{})
 
 		require.NoError(t, err)
 		assert.NotNil(t, resp)
-		assert.Len(t, resp.GetCommits(), 1)
-		assert.Equal(t, "abc123", resp.GetCommits()[0].GetId())
-		assert.Equal(t, int32(1), resp.GetTotalPage())
-		assert.Equal(t, int32(0), resp.GetNextPage())
+		assert.Len(t, resp.GetNodes(), 1)
+		assert.Equal(t, "main.go", resp.GetNodes()[0].GetName())
 	})
 
 	t.Run("repository not found", func(t *testing.T) {
@@ -763,8 +2604,8 @@ func TestService_GetCommits(t *testing.T) {
 			GetRepositoryById(ctx, "non-existent").
 			Return(nil, errors.New("repository not found"))
 
-		req := &registryv1.GetCommitsRequest{Id: "non-existent"}
-		_, err := svc.GetCommits(ctx, req)
+		req := &registryv1.GetFileTreeRequest{Id: "non-existent"}
+		_, err := svc.GetFileTree(ctx, req, "", FileTreePage{})
 
 		require.Error(t, err)
 	})
@@ -799,13 +2640,13 @@ func TestService_GetCommits(t *testing.T) {
 			GetMemberRole(ctx, orgID, userID).
 			Return("", errors.New("user is not a member"))
 
-		req := &registryv1.GetCommitsRequest{Id: repoID}
-		_, err := svc.GetCommits(ctx, req)
+		req := &registryv1.GetFileTreeRequest{Id: repoID}
+		_, err := svc.GetFileTree(ctx, req, "", FileTreePage{})
 
 		require.Error(t, err)
 	})
 
-	t.Run("success with pagination", func(t *testing.T) {
+	t.Run("caps the page limit", func(t *testing.T) {
 		ctrl := gomock.NewController(t)
 		mockRepo := NewMockRepository(ctrl)
 		mockOrgRepo := authorization.NewMockMemberRoleChecker(ctrl)
@@ -825,59 +2666,21 @@ func TestService_GetCommits(t *testing.T) {
 
 		mockRepo.EXPECT().
 			GetRepositoryById(ctx, repoID).
-			Return(&RepositoryDTO{
-				Id:             repoID,
-				Name:           "test-repo",
-				OrganizationId: orgID,
-				Path:           repoPath,
-			}, nil)
-
+			Return(&RepositoryDTO{Id: repoID, OrganizationId: orgID, Path: repoPath}, nil)
 		mockOrgRepo.EXPECT().
 			GetMemberRole(ctx, orgID, userID).
 			Return(authorization.MemberRoleReader, nil)
-
-		expectedCommits := []*registryv1.Commit{
-			{
-				Id:      "commit-1",
-				Message: "First commit",
-				User: &registryv1.Commit_User{
-					Id:       "user1@example.com",
-					Username: "User 1",
-				},
-			},
-			{
-				Id:      "commit-2",
-				Message: "Second commit",
-				User: &registryv1.Commit_User{
-					Id:       "user2@example.com",
-					Username: "User 2",
-				},
-			},
-		}
-
 		mockRepo.EXPECT().
-			GetCommits(ctx, repoPath, 2, 5).
-			Return(expectedCommits, 12, nil)
-
-		req := &registryv1.GetCommitsRequest{
-			Id: repoID,
-			Pagination: &shared.Pagination{
-				Page:      2,
-				PageLimit: 5,
-			},
-		}
-		resp, err := svc.GetCommits(ctx, req)
+			GetFileTree(ctx, repoPath, "", (*string)(nil), FileTreePage{Offset: 5, Limit: maxFileTreePageLimit}).
+			Return(&FileTree{GetFileTreeResponse: &registryv1.GetFileTreeResponse{}}, nil)
 
-		require.NoError(t, err)
-		assert.NotNil(t, resp)
-		assert.Len(t, resp.GetCommits(), 2)
-		assert.Equal(t, int32(3), resp.GetTotalPage())
-		assert.Equal(t, int32(3), resp.GetNextPage())
+		_, err := svc.GetFileTree(ctx, &registryv1.GetFileTreeRequest{Id: repoID}, "", FileTreePage{Offset: 5, Limit: 1_000_000})
+		assert.NoError(t, err)
 	})
 }
 
-func TestService_GetFileTree(t *testing.T) {
-	t.Run("success - root directory", func(t *testing.T) {
+func TestService_GetFilePreview(t *testing.T) {
+	t.Run("success - text file", func(t *testing.T) {
 		ctrl := gomock.NewController(t)
 		mockRepo := NewMockRepository(ctrl)
 		mockOrgRepo := authorization.NewMockMemberRoleChecker(ctrl)
@@ -891,6 +2694,7 @@ func TestService_GetFileTree(t *testing.T) {
 		const userID = "user-123"
 		const orgID = "org-123"
 		const repoID = "repo-123"
+		const filePath = "README.md"
 		repoPath := filepath.Join("./repos", repoID)
 
 		ctx := testAuthInterceptor(userID)
@@ -908,36 +2712,30 @@ func TestService_GetFileTree(t *testing.T) {
 			GetMemberRole(ctx, orgID, userID).
 			Return(authorization.MemberRoleReader, nil)
 
-		expectedFileTree := &registryv1.GetFileTreeResponse{
-			Nodes: []*registryv1.FileTreeNode{
-				{
-					Name: "README.md",
-					Path: "README.md",
-					Type: registryv1.NodeType_NODE_TYPE_FILE,
-				},
-				{
-					Name: "src",
-					Path: "src",
-					Type: registryv1.NodeType_NODE_TYPE_DIRECTORY,
-				},
-			},
+		expectedPreview := &registryv1.GetFilePreviewResponse{
+			Content:  "# Test Repository\n\nThis is a test.",
+			MimeType: "text/markdown",
+			Size:     35,
 		}
 
 		mockRepo.EXPECT().
-			GetFileTree(ctx, repoPath, (*string)(nil)).
-			Return(expectedFileTree, nil)
+			GetFilePreview(ctx, repoPath, "", filePath).
+			Return(&FilePreview{GetFilePreviewResponse: expectedPreview}, nil)
 
-		req := &registryv1.GetFileTreeRequest{Id: repoID}
-		resp, err := svc.GetFileTree(ctx, req)
+		req := &registryv1.GetFilePreviewRequest{
+			Id:   repoID,
+			Path: filePath,
+		}
+		resp, err := svc.GetFilePreview(ctx, req, "")
 
-		assert.NoError(t, err)
+		require.NoError(t, err)
 		assert.NotNil(t, resp)
-		assert.Len(t, resp.GetNodes(), 2)
-		assert.Equal(t, "README.md", resp.GetNodes()[0].GetName())
-		assert.Equal(t, "src", resp.GetNodes()[1].GetName())
+		assert.Equal(t, expectedPreview.GetContent(), resp.GetContent())
+		assert.Equal(t, expectedPreview.GetMimeType(), resp.GetMimeType())
+		assert.Equal(t, expectedPreview.GetSize(), resp.GetSize())
 	})
 
-	t.Run("success - subdirectory", func(t *testing.T) {
+	t.Run("success - go file", func(t *testing.T) {
 		ctrl := gomock.NewController(t)
 		mockRepo := NewMockRepository(ctrl)
 		mockOrgRepo := authorization.NewMockMemberRoleChecker(ctrl)
@@ -951,8 +2749,8 @@ func TestService_GetFileTree(t *testing.T) {
 		const userID = "user-123"
 		const orgID = "org-123"
 		const repoID = "repo-123"
+		const filePath = "main.go"
 		repoPath := filepath.Join("./repos", repoID)
-		subPath := "src"
 
 		ctx := testAuthInterceptor(userID)
 
@@ -969,30 +2767,26 @@ func TestService_GetFileTree(t *testing.T) {
 			GetMemberRole(ctx, orgID, userID).
 			Return(authorization.MemberRoleReader, nil)
 
-		expectedFileTree := &registryv1.GetFileTreeResponse{
-			Nodes: []*registryv1.FileTreeNode{
-				{
-					Name: "main.go",
-					Path: "src/main.go",
-					Type: registryv1.NodeType_NODE_TYPE_FILE,
-				},
-			},
+		expectedPreview := &registryv1.GetFilePreviewResponse{
+			Content:  "package main\n\nfunc main() {}",
+			MimeType: "text/x-go",
+			Size:     30,
 		}
 
 		mockRepo.EXPECT().
-			GetFileTree(ctx, repoPath, &subPath).
-			Return(expectedFileTree, nil)
+			GetFilePreview(ctx, repoPath, "", filePath).
+			Return(&FilePreview{GetFilePreviewResponse: expectedPreview}, nil)
 
-		req := &registryv1.GetFileTreeRequest{
+		req := &registryv1.GetFilePreviewRequest{
 			Id:   repoID,
-			Path: &subPath,
+			Path: filePath,
 		}
-		resp, err := svc.GetFileTree(ctx, req)
+		resp, err := svc.GetFilePreview(ctx, req, "")
 
 		require.NoError(t, err)
 		assert.NotNil(t, resp)
-		assert.Len(t, resp.GetNodes(), 1)
-		assert.Equal(t, "main.go", resp.GetNodes()[0].GetName())
+		assert.Equal(t, expectedPreview.GetContent(), resp.GetContent())
+		assert.Equal(t, expectedPreview.GetMimeType(), resp.GetMimeType())
 	})
 
 	t.Run("repository not found", func(t *testing.T) {
@@ -1013,8 +2807,11 @@ func TestService_GetFileTree(t *testing.T) {
 			GetRepositoryById(ctx, "non-existent").
 			Return(nil, errors.New("repository not found"))
 
-		req := &registryv1.GetFileTreeRequest{Id: "non-existent"}
-		_, err := svc.GetFileTree(ctx, req)
+		req := &registryv1.GetFilePreviewRequest{
+			Id:   "non-existent",
+			Path: "README.md",
+		}
+		_, err := svc.GetFilePreview(ctx, req, "")
 
 		require.Error(t, err)
 	})
@@ -1049,15 +2846,16 @@ func TestService_GetFileTree(t *testing.T) {
 			GetMemberRole(ctx, orgID, userID).
 			Return("", errors.New("user is not a member"))
 
-		req := &registryv1.GetFileTreeRequest{Id: repoID}
-		_, err := svc.GetFileTree(ctx, req)
+		req := &registryv1.GetFilePreviewRequest{
+			Id:   repoID,
+			Path: "README.md",
+		}
+		_, err := svc.GetFilePreview(ctx, req, "")
 
 		require.Error(t, err)
 	})
-}
 
-func TestService_GetFilePreview(t *testing.T) {
-	t.Run("success - text file", func(t *testing.T) {
+	t.Run("file not found", func(t *testing.T) {
 		ctrl := gomock.NewController(t)
 		mockRepo := NewMockRepository(ctrl)
 		mockOrgRepo := authorization.NewMockMemberRoleChecker(ctrl)
@@ -1071,7 +2869,7 @@ func TestService_GetFilePreview(t *testing.T) {
 		const userID = "user-123"
 		const orgID = "org-123"
 		const repoID = "repo-123"
-		const filePath = "README.md"
+		const filePath = "nonexistent.txt"
 		repoPath := filepath.Join("./repos", repoID)
 
 		ctx := testAuthInterceptor(userID)
@@ -1089,30 +2887,23 @@ func TestService_GetFilePreview(t *testing.T) {
 			GetMemberRole(ctx, orgID, userID).
 			Return(authorization.MemberRoleReader, nil)
 
-		expectedPreview := &registryv1.GetFilePreviewResponse{
-			Content:  "# Test Repository\n\nThis is a test.",
-			MimeType: "text/markdown",
-			Size:     35,
-		}
-
 		mockRepo.EXPECT().
-			GetFilePreview(ctx, repoPath, filePath).
-			Return(expectedPreview, nil)
+			GetFilePreview(ctx, repoPath, "", filePath).
+			Return(nil, errors.New("file not found in repository"))
 
 		req := &registryv1.GetFilePreviewRequest{
 			Id:   repoID,
 			Path: filePath,
 		}
-		resp, err := svc.GetFilePreview(ctx, req)
+		_, err := svc.GetFilePreview(ctx, req, "")
 
-		require.NoError(t, err)
-		assert.NotNil(t, resp)
-		assert.Equal(t, expectedPreview.GetContent(), resp.GetContent())
-		assert.Equal(t, expectedPreview.GetMimeType(), resp.GetMimeType())
-		assert.Equal(t, expectedPreview.GetSize(), resp.GetSize())
+		require.Error(t, err)
+		assert.ErrorContains(t, err, "file not found")
 	})
+}
 
-	t.Run("success - go file", func(t *testing.T) {
+func TestService_ListRefs(t *testing.T) {
+	t.Run("success", func(t *testing.T) {
 		ctrl := gomock.NewController(t)
 		mockRepo := NewMockRepository(ctrl)
 		mockOrgRepo := authorization.NewMockMemberRoleChecker(ctrl)
@@ -1126,7 +2917,6 @@ func TestService_GetFilePreview(t *testing.T) {
 		const userID = "user-123"
 		const orgID = "org-123"
 		const repoID = "repo-123"
-		const filePath = "main.go"
 		repoPath := filepath.Join("./repos", repoID)
 
 		ctx := testAuthInterceptor(userID)
@@ -1144,26 +2934,18 @@ func TestService_GetFilePreview(t *testing.T) {
 			GetMemberRole(ctx, orgID, userID).
 			Return(authorization.MemberRoleReader, nil)
 
-		expectedPreview := &registryv1.GetFilePreviewResponse{
-			Content:  "package main\n\nfunc main() {}",
-			MimeType: "text/x-go",
-			Size:     30,
-		}
+		expectedBranches := []RefInfo{{Name: "main", Target: "abc123"}}
+		expectedTags := []RefInfo{{Name: "v1.0.0", Target: "abc123"}}
 
 		mockRepo.EXPECT().
-			GetFilePreview(ctx, repoPath, filePath).
-			Return(expectedPreview, nil)
+			ListRefs(ctx, repoPath).
+			Return(expectedBranches, expectedTags, nil)
 
-		req := &registryv1.GetFilePreviewRequest{
-			Id:   repoID,
-			Path: filePath,
-		}
-		resp, err := svc.GetFilePreview(ctx, req)
+		branches, tags, err := svc.ListRefs(ctx, repoID)
 
 		require.NoError(t, err)
-		assert.NotNil(t, resp)
-		assert.Equal(t, expectedPreview.GetContent(), resp.GetContent())
-		assert.Equal(t, expectedPreview.GetMimeType(), resp.GetMimeType())
+		assert.Equal(t, expectedBranches, branches)
+		assert.Equal(t, expectedTags, tags)
 	})
 
 	t.Run("repository not found", func(t *testing.T) {
@@ -1177,18 +2959,13 @@ func TestService_GetFilePreview(t *testing.T) {
 			orgRepo:    mockOrgRepo,
 		}
 
-		const userID = "user-123"
-		ctx := testAuthInterceptor(userID)
+		ctx := testAuthInterceptor("user-123")
 
 		mockRepo.EXPECT().
 			GetRepositoryById(ctx, "non-existent").
 			Return(nil, errors.New("repository not found"))
 
-		req := &registryv1.GetFilePreviewRequest{
-			Id:   "non-existent",
-			Path: "README.md",
-		}
-		_, err := svc.GetFilePreview(ctx, req)
+		_, _, err := svc.ListRefs(ctx, "non-existent")
 
 		require.Error(t, err)
 	})
@@ -1223,59 +3000,141 @@ func TestService_GetFilePreview(t *testing.T) {
 			GetMemberRole(ctx, orgID, userID).
 			Return("", errors.New("user is not a member"))
 
-		req := &registryv1.GetFilePreviewRequest{
-			Id:   repoID,
-			Path: "README.md",
-		}
-		_, err := svc.GetFilePreview(ctx, req)
+		_, _, err := svc.ListRefs(ctx, repoID)
+
+		require.Error(t, err)
+	})
+}
+
+func TestService_GetBranchDivergence(t *testing.T) {
+	const userID = "user-123"
+	const orgID = "org-123"
+	const repoID = "repo-123"
+	repoPath := filepath.Join("./repos", repoID)
+
+	setup := func(t *testing.T) (*service, *MockRepository, *authorization.MockMemberRoleChecker, context.Context) {
+		ctrl := gomock.NewController(t)
+		mockRepo := NewMockRepository(ctrl)
+		mockOrgRepo := authorization.NewMockMemberRoleChecker(ctrl)
+		ctx := testAuthInterceptor(userID)
+
+		mockRepo.EXPECT().
+			GetRepositoryById(ctx, repoID).
+			Return(&RepositoryDTO{Id: repoID, OrganizationId: orgID, Path: repoPath}, nil)
+
+		return &service{rootPath: "./repos", repository: mockRepo, orgRepo: mockOrgRepo}, mockRepo, mockOrgRepo, ctx
+	}
+
+	t.Run("members get the divergence", func(t *testing.T) {
+		svc, mockRepo, mockOrgRepo, ctx := setup(t)
+
+		expected := []BranchDivergence{{Name: "main"}, {Name: "feature", Ahead: 2, Behind: 1}}
+		mockOrgRepo.EXPECT().
+			GetMemberRole(ctx, orgID, userID).
+			Return(authorization.MemberRoleReader, nil)
+		mockRepo.EXPECT().
+			GetBranchDivergence(ctx, repoPath).
+			Return("main", expected, nil)
+
+		defaultBranch, divergence, err := svc.GetBranchDivergence(ctx, repoID)
+		require.NoError(t, err)
+		assert.Equal(t, "main", defaultBranch)
+		assert.Equal(t, expected, divergence)
+	})
+
+	t.Run("non-members are denied", func(t *testing.T) {
+		svc, _, mockOrgRepo, ctx := setup(t)
 
-		require.Error(t, err)
+		mockOrgRepo.EXPECT().
+			GetMemberRole(ctx, orgID, userID).
+			Return("", authorization.ErrMemberNotFound)
+
+		_, _, err := svc.GetBranchDivergence(ctx, repoID)
+		assert.Equal(t, connect.CodePermissionDenied, connect.CodeOf(err))
 	})
+}
 
-	t.Run("file not found", func(t *testing.T) {
+func TestService_GetContributors(t *testing.T) {
+	const userID = "user-123"
+	const orgID = "org-123"
+	const repoID = "repo-123"
+	repoPath := filepath.Join("./repos", repoID)
+
+	newService := func(t *testing.T) (*service, *MockRepository, *authorization.MockMemberRoleChecker) {
 		ctrl := gomock.NewController(t)
 		mockRepo := NewMockRepository(ctrl)
 		mockOrgRepo := authorization.NewMockMemberRoleChecker(ctrl)
 
-		svc := &service{
+		return &service{
 			rootPath:   "./repos",
 			repository: mockRepo,
 			orgRepo:    mockOrgRepo,
-		}
-
-		const userID = "user-123"
-		const orgID = "org-123"
-		const repoID = "repo-123"
-		const filePath = "nonexistent.txt"
-		repoPath := filepath.Join("./repos", repoID)
+		}, mockRepo, mockOrgRepo
+	}
 
+	t.Run("maps contributors to known users by email", func(t *testing.T) {
+		svc, mockRepo, mockOrgRepo := newService(t)
 		ctx := testAuthInterceptor(userID)
 
 		mockRepo.EXPECT().
 			GetRepositoryById(ctx, repoID).
-			Return(&RepositoryDTO{
-				Id:             repoID,
-				Name:           "test-repo",
-				OrganizationId: orgID,
-				Path:           repoPath,
+			Return(&RepositoryDTO{Id: repoID, OrganizationId: orgID, Path: repoPath}, nil)
+		mockOrgRepo.EXPECT().
+			GetMemberRole(ctx, orgID, userID).
+			Return(authorization.MemberRoleReader, nil)
+		mockRepo.EXPECT().
+			GetContributors(ctx, repoPath).
+			Return([]Contributor{
+				{Name: "Alice", Email: "Alice@Example.com", CommitCount: 3},
+				{Name: "Bob", Email: "bob@example.com", CommitCount: 1},
 			}, nil)
+		mockRepo.EXPECT().
+			GetUsersByEmails(ctx, []string{"alice@example.com", "bob@example.com"}).
+			Return([]UserIdentity{{Id: "user-alice", Username: "alice", Email: "alice@example.com"}}, nil)
+
+		contributors, err := svc.GetContributors(ctx, repoID)
+
+		require.NoError(t, err)
+		assert.Equal(t, []Contributor{
+			{Name: "Alice", Email: "Alice@Example.com", CommitCount: 3, UserId: "user-alice", Username: "alice"},
+			{Name: "Bob", Email: "bob@example.com", CommitCount: 1},
+		}, contributors)
+	})
+
+	t.Run("empty repository skips user lookup", func(t *testing.T) {
+		svc, mockRepo, mockOrgRepo := newService(t)
+		ctx := testAuthInterceptor(userID)
 
+		mockRepo.EXPECT().
+			GetRepositoryById(ctx, repoID).
+			Return(&RepositoryDTO{Id: repoID, OrganizationId: orgID, Path: repoPath}, nil)
 		mockOrgRepo.EXPECT().
 			GetMemberRole(ctx, orgID, userID).
 			Return(authorization.MemberRoleReader, nil)
+		mockRepo.EXPECT().
+			GetContributors(ctx, repoPath).
+			Return([]Contributor{}, nil)
+
+		contributors, err := svc.GetContributors(ctx, repoID)
+
+		require.NoError(t, err)
+		assert.Empty(t, contributors)
+	})
+
+	t.Run("non-member is denied", func(t *testing.T) {
+		svc, mockRepo, mockOrgRepo := newService(t)
+		ctx := testAuthInterceptor(userID)
 
 		mockRepo.EXPECT().
-			GetFilePreview(ctx, repoPath, filePath).
-			Return(nil, errors.New("file not found in repository"))
+			GetRepositoryById(ctx, repoID).
+			Return(&RepositoryDTO{Id: repoID, OrganizationId: orgID, Path: repoPath}, nil)
+		mockOrgRepo.EXPECT().
+			GetMemberRole(ctx, orgID, userID).
+			Return("", errors.New("not a member"))
 
-		req := &registryv1.GetFilePreviewRequest{
-			Id:   repoID,
-			Path: filePath,
-		}
-		_, err := svc.GetFilePreview(ctx, req)
+		_, err := svc.GetContributors(ctx, repoID)
 
 		require.Error(t, err)
-		assert.ErrorContains(t, err, "file not found")
 	})
 }
 
@@ -1335,6 +3194,120 @@ func TestService_TriggerSdkGeneration(t *testing.T) {
 		assert.NoError(t, err)
 	})
 
+	t.Run("success - enqueues when proto files changed since last generation", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		mockRepo := NewMockRepository(ctrl)
+		mockQueue := NewMockSdkGenerationQueue(ctrl)
+
+		svc := &service{
+			rootPath:   "./repos",
+			repository: mockRepo,
+			sdkQueue:   mockQueue,
+		}
+
+		ctx := context.Background()
+		repoID := "repo-123"
+		commitHash := "abc123def456"
+
+		lastGenerated := "0000aaaa"
+		sdkPreferences := []SdkPreferencesDTO{
+			{RepositoryId: repoID, Sdk: SdkGoProtobuf, Status: true, LastGeneratedCommit: &lastGenerated},
+			{RepositoryId: repoID, Sdk: SdkGoConnectRpc, Status: true, LastGeneratedCommit: &lastGenerated},
+		}
+
+		mockRepo.EXPECT().
+			GetSdkPreferences(ctx, repoID).
+			Return(sdkPreferences, nil)
+
+		// Both SDKs share a base commit, so the trees are compared once.
+		mockRepo.EXPECT().
+			HasSdkInputChanges(ctx, filepath.Join("./repos", repoID), lastGenerated, commitHash).
+			Return(true, nil)
+
+		mockQueue.EXPECT().
+			EnqueueSdkGenerationJobs(ctx, gomock.Any()).
+			DoAndReturn(func(_ context.Context, jobs []*SdkGenerationJobDTO) error {
+				assert.Len(t, jobs, 2)
+				return nil
+			})
+
+		err := svc.TriggerSdkGeneration(ctx, repoID, commitHash)
+		assert.NoError(t, err)
+	})
+
+	t.Run("success - skips when only non-proto files changed", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		mockRepo := NewMockRepository(ctrl)
+		mockQueue := NewMockSdkGenerationQueue(ctrl)
+
+		svc := &service{
+			rootPath:   "./repos",
+			repository: mockRepo,
+			sdkQueue:   mockQueue,
+		}
+
+		ctx := context.Background()
+		repoID := "repo-123"
+		commitHash := "abc123def456"
+
+		lastGenerated := "0000aaaa"
+		sdkPreferences := []SdkPreferencesDTO{
+			{RepositoryId: repoID, Sdk: SdkGoProtobuf, Status: true, LastGeneratedCommit: &lastGenerated},
+			{RepositoryId: repoID, Sdk: SdkGoConnectRpc, Status: true, LastGeneratedCommit: &lastGenerated},
+		}
+
+		mockRepo.EXPECT().
+			GetSdkPreferences(ctx, repoID).
+			Return(sdkPreferences, nil)
+
+		mockRepo.EXPECT().
+			HasSdkInputChanges(ctx, filepath.Join("./repos", repoID), lastGenerated, commitHash).
+			Return(false, nil)
+
+		err := svc.TriggerSdkGeneration(ctx, repoID, commitHash)
+		assert.NoError(t, err)
+	})
+
+	t.Run("success - regenerates when the comparison fails", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		mockRepo := NewMockRepository(ctrl)
+		mockQueue := NewMockSdkGenerationQueue(ctrl)
+
+		svc := &service{
+			rootPath:   "./repos",
+			repository: mockRepo,
+			sdkQueue:   mockQueue,
+		}
+
+		ctx := context.Background()
+		repoID := "repo-123"
+		commitHash := "abc123def456"
+
+		lastGenerated := "0000aaaa"
+		sdkPreferences := []SdkPreferencesDTO{
+			{RepositoryId: repoID, Sdk: SdkGoProtobuf, Status: true, LastGeneratedCommit: &lastGenerated},
+			{RepositoryId: repoID, Sdk: SdkGoConnectRpc, Status: true, LastGeneratedCommit: &lastGenerated},
+		}
+
+		mockRepo.EXPECT().
+			GetSdkPreferences(ctx, repoID).
+			Return(sdkPreferences, nil)
+
+		mockRepo.EXPECT().
+			HasSdkInputChanges(ctx, filepath.Join("./repos", repoID), lastGenerated, commitHash).
+			Return(false, connect.NewError(connect.CodeNotFound, errors.New("failed to open git repository")))
+
+		mockQueue.EXPECT().
+			EnqueueSdkGenerationJobs(ctx, gomock.Any()).
+			DoAndReturn(func(_ context.Context, jobs []*SdkGenerationJobDTO) error {
+				assert.Len(t, jobs, 2)
+				return nil
+			})
+
+		err := svc.TriggerSdkGeneration(ctx, repoID, commitHash)
+		assert.NoError(t, err)
+	})
+
 	t.Run("success - no jobs when no SDK preferences enabled", func(t *testing.T) {
 		ctrl := gomock.NewController(t)
 		mockRepo := NewMockRepository(ctrl)
@@ -2030,6 +4003,84 @@ func TestService_UpdateSdkPreferences(t *testing.T) {
 		require.NoError(t, err)
 	})
 
+	t.Run("allowlist", func(t *testing.T) {
+		const repoID = "repo-123"
+		const orgID = "org-123"
+		const userID = "user-123"
+		const repoPath = "./repos/repo-123"
+
+		newAllowlistService := func(t *testing.T) (*service, *MockRepository, context.Context) {
+			ctrl := gomock.NewController(t)
+			mockRepo := NewMockRepository(ctrl)
+			mockOrgRepo := authorization.NewMockMemberRoleChecker(ctrl)
+			ctx := testAuthInterceptor(userID)
+
+			mockRepo.EXPECT().
+				GetRepositoryById(ctx, repoID).
+				Return(&RepositoryDTO{Id: repoID, OrganizationId: orgID, Path: repoPath}, nil)
+			mockOrgRepo.EXPECT().
+				GetMemberRole(ctx, orgID, userID).
+				Return(authorization.MemberRoleOwner, nil)
+
+			return &service{
+				repository: mockRepo,
+				orgRepo:    mockOrgRepo,
+				cfg: &config.Config{
+					SdkGeneration: config.SdkGenerationConfig{AllowedSdks: []string{"GO_PROTOBUF", "go_connectrpc"}},
+				},
+			}, mockRepo, ctx
+		}
+
+		t.Run("rejects enabling a disallowed SDK", func(t *testing.T) {
+			svc, _, ctx := newAllowlistService(t)
+
+			err := svc.UpdateSdkPreferences(ctx, &registryv1.UpdateSdkPreferencesRequest{
+				Id: repoID,
+				SdkPreferences: []*registryv1.SdkPreference{
+					{Sdk: registryv1.SDK_SDK_GO_PROTOBUF, Status: true},
+					{Sdk: registryv1.SDK_SDK_JS_PROTOBUF, Status: true},
+				},
+			})
+			require.Error(t, err)
+			assert.Equal(t, connect.CodeFailedPrecondition, connect.CodeOf(err))
+			assert.Contains(t, err.Error(), "JS_PROTOBUF")
+		})
+
+		t.Run("accepts allowed SDKs and disabling any SDK", func(t *testing.T) {
+			svc, mockRepo, ctx := newAllowlistService(t)
+
+			mockRepo.EXPECT().
+				UpdateSdkPreferences(ctx, repoID, gomock.Any()).
+				Return(nil)
+
+			err := svc.UpdateSdkPreferences(ctx, &registryv1.UpdateSdkPreferencesRequest{
+				Id: repoID,
+				SdkPreferences: []*registryv1.SdkPreference{
+					{Sdk: registryv1.SDK_SDK_GO_CONNECTRPC, Status: true},
+					{Sdk: registryv1.SDK_SDK_JS_PROTOBUF, Status: false},
+				},
+			})
+			require.NoError(t, err)
+		})
+
+		t.Run("lists available SDKs", func(t *testing.T) {
+			svc := &service{cfg: &config.Config{
+				SdkGeneration: config.SdkGenerationConfig{AllowedSdks: []string{"JS_PROTOBUF", "GO_PROTOBUF"}},
+			}}
+			assert.Equal(t, []registryv1.SDK{registryv1.SDK_SDK_GO_PROTOBUF, registryv1.SDK_SDK_JS_PROTOBUF}, svc.AvailableSdks())
+
+			assert.Len(t, (&service{}).AvailableSdks(), len(SdkProtoToDbEnum))
+		})
+
+		t.Run("config accepts every SDK", func(t *testing.T) {
+			var names []string
+			for _, sdk := range SdkProtoToDbEnum {
+				names = append(names, string(sdk))
+			}
+			assert.ElementsMatch(t, config.SupportedSdks, names)
+		})
+	})
+
 	t.Run("success - no trigger job when queue is nil", func(t *testing.T) {
 		ctrl := gomock.NewController(t)
 		mockRepo := NewMockRepository(ctrl)
@@ -2166,7 +4217,7 @@ func TestService_ProcessSdkTrigger(t *testing.T) {
 			}, nil)
 
 		mockRepo.EXPECT().
-			GetCommits(ctx, repoPath, 1, 10000).
+			GetCommits(ctx, repoPath, HeadRef, 1, 10000).
 			Return([]*registryv1.Commit{
 				{Id: "commit-1", Message: "First commit"},
 				{Id: "commit-2", Message: "Second commit"},
@@ -2205,7 +4256,7 @@ func TestService_ProcessSdkTrigger(t *testing.T) {
 			}, nil)
 
 		mockRepo.EXPECT().
-			GetCommits(ctx, repoPath, 1, 10000).
+			GetCommits(ctx, repoPath, HeadRef, 1, 10000).
 			Return([]*registryv1.Commit{}, 0, nil)
 
 		err := svc.ProcessSdkTrigger(ctx, repoID, repoPath)
@@ -2285,3 +4336,184 @@ func TestSdkGenerationJobDTO(t *testing.T) {
 		assert.Equal(t, now, dto.CreatedAt)
 	})
 }
+
+func TestService_GetRepositorySize(t *testing.T) {
+	const (
+		repoID = "repo-123"
+		orgID  = "org-123"
+		userID = "user-123"
+	)
+
+	setup := func(t *testing.T, repoPath, role string, roleErr error) (*service, context.Context) {
+		ctrl := gomock.NewController(t)
+		mockRepo := NewMockRepository(ctrl)
+		mockOrgRepo := authorization.NewMockMemberRoleChecker(ctrl)
+		ctx := testAuthInterceptor(userID)
+
+		mockRepo.EXPECT().
+			GetRepositoryById(ctx, repoID).
+			Return(&RepositoryDTO{Id: repoID, OrganizationId: orgID, Path: repoPath}, nil).
+			AnyTimes()
+		mockOrgRepo.EXPECT().
+			GetMemberRole(ctx, orgID, userID).
+			Return(role, roleErr).
+			AnyTimes()
+
+		return &service{repository: mockRepo, orgRepo: mockOrgRepo}, ctx
+	}
+
+	initBare := func(t *testing.T) string {
+		repoPath := filepath.Join(t.TempDir(), "repo.git")
+		out, err := exec.Command("git", "init", "--bare", repoPath).CombinedOutput()
+		require.NoError(t, err, string(out))
+		return repoPath
+	}
+
+	writeBlob := func(t *testing.T, repoPath string, size int) {
+		blob := make([]byte, size)
+		_, err := rand.Read(blob)
+		require.NoError(t, err)
+
+		cmd := exec.Command("git", "hash-object", "-w", "--stdin")
+		cmd.Dir = repoPath
+		cmd.Stdin = strings.NewReader(string(blob))
+		out, err := cmd.CombinedOutput()
+		require.NoError(t, err, string(out))
+	}
+
+	t.Run("empty repository reports a small baseline", func(t *testing.T) {
+		svc, ctx := setup(t, initBare(t), authorization.MemberRoleReader, nil)
+
+		size, err := svc.GetRepositorySize(ctx, repoID)
+
+		require.NoError(t, err)
+		assert.Positive(t, size)
+		assert.Less(t, size, int64(1<<20))
+	})
+
+	t.Run("objects count towards the size", func(t *testing.T) {
+		repoPath := initBare(t)
+		baseline, err := repositoryDiskSize(repoPath)
+		require.NoError(t, err)
+
+		writeBlob(t, repoPath, 256<<10)
+
+		svc, ctx := setup(t, repoPath, authorization.MemberRoleReader, nil)
+		size, err := svc.GetRepositorySize(ctx, repoID)
+
+		require.NoError(t, err)
+		assert.Greater(t, size, baseline+200<<10)
+	})
+
+	t.Run("result is cached", func(t *testing.T) {
+		repoPath := initBare(t)
+		svc, ctx := setup(t, repoPath, authorization.MemberRoleReader, nil)
+
+		first, err := svc.GetRepositorySize(ctx, repoID)
+		require.NoError(t, err)
+
+		writeBlob(t, repoPath, 64<<10)
+
+		second, err := svc.GetRepositorySize(ctx, repoID)
+		require.NoError(t, err)
+		assert.Equal(t, first, second)
+	})
+
+	t.Run("non-member is rejected", func(t *testing.T) {
+		svc, ctx := setup(t, initBare(t), "", connect.NewError(connect.CodeNotFound, errors.New("member not found")))
+
+		_, err := svc.GetRepositorySize(ctx, repoID)
+
+		assert.Error(t, err)
+	})
+}
+
+func TestService_RegenerateDocs(t *testing.T) {
+	const (
+		repoID   = "repo-123"
+		orgID    = "org-123"
+		userID   = "user-123"
+		repoPath = "/repos/repo-123"
+		headHash = "0123456789abcdef0123456789abcdef01234567"
+	)
+
+	setup := func(t *testing.T, role string) (*service, *MockRepository, *MockSdkGenerationQueue, context.Context) {
+		ctrl := gomock.NewController(t)
+		mockRepo := NewMockRepository(ctrl)
+		mockOrgRepo := authorization.NewMockMemberRoleChecker(ctrl)
+		mockQueue := NewMockSdkGenerationQueue(ctrl)
+		ctx := testAuthInterceptor(userID)
+
+		mockRepo.EXPECT().
+			GetRepositoryById(ctx, repoID).
+			Return(&RepositoryDTO{Id: repoID, OrganizationId: orgID, Path: repoPath}, nil).
+			AnyTimes()
+		mockOrgRepo.EXPECT().
+			GetMemberRole(ctx, orgID, userID).
+			Return(role, nil).
+			AnyTimes()
+
+		return &service{repository: mockRepo, orgRepo: mockOrgRepo, sdkQueue: mockQueue}, mockRepo, mockQueue, ctx
+	}
+
+	t.Run("enqueues a build for the current commit", func(t *testing.T) {
+		svc, mockRepo, mockQueue, ctx := setup(t, authorization.MemberRoleAuthor)
+
+		mockRepo.EXPECT().
+			GetRecentCommit(ctx, repoPath).
+			Return(&registryv1.Commit{Id: headHash}, nil)
+
+		var enqueued *DocsGenerationJobDTO
+		mockQueue.EXPECT().
+			EnqueueDocsGenerationJob(ctx, gomock.Any()).
+			DoAndReturn(func(_ context.Context, job *DocsGenerationJobDTO) (bool, error) {
+				enqueued = job
+				return true, nil
+			})
+
+		err := svc.RegenerateDocs(ctx, repoID)
+
+		require.NoError(t, err)
+		require.NotNil(t, enqueued)
+		assert.Equal(t, repoID, enqueued.RepositoryId)
+		assert.Equal(t, headHash, enqueued.CommitHash)
+		assert.Equal(t, SdkGenerationJobStatusPending, enqueued.Status)
+		assert.NotEmpty(t, enqueued.Id)
+	})
+
+	t.Run("second call while a build is pending is a no-op", func(t *testing.T) {
+		svc, mockRepo, mockQueue, ctx := setup(t, authorization.MemberRoleOwner)
+
+		mockRepo.EXPECT().
+			GetRecentCommit(ctx, repoPath).
+			Return(&registryv1.Commit{Id: headHash}, nil).
+			Times(2)
+		gomock.InOrder(
+			mockQueue.EXPECT().EnqueueDocsGenerationJob(ctx, gomock.Any()).Return(true, nil),
+			mockQueue.EXPECT().EnqueueDocsGenerationJob(ctx, gomock.Any()).Return(false, nil),
+		)
+
+		require.NoError(t, svc.RegenerateDocs(ctx, repoID))
+		require.NoError(t, svc.RegenerateDocs(ctx, repoID))
+	})
+
+	t.Run("readers cannot regenerate", func(t *testing.T) {
+		svc, _, _, ctx := setup(t, authorization.MemberRoleReader)
+
+		err := svc.RegenerateDocs(ctx, repoID)
+
+		assert.Equal(t, connect.CodePermissionDenied, connect.CodeOf(err))
+	})
+
+	t.Run("empty repository has nothing to document", func(t *testing.T) {
+		svc, mockRepo, _, ctx := setup(t, authorization.MemberRoleOwner)
+
+		mockRepo.EXPECT().
+			GetRecentCommit(ctx, repoPath).
+			Return(nil, ErrRepositoryEmpty)
+
+		err := svc.RegenerateDocs(ctx, repoID)
+
+		assert.ErrorIs(t, err, ErrRepositoryEmpty)
+	})
+}
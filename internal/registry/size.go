@@ -0,0 +1,85 @@
+package registry
+
+import (
+	"errors"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+// repositorySizeTTL is how long a computed repository size is reused. Sizes
+// only change on push, and walking a large pack directory on every page load
+// is wasted work.
+const repositorySizeTTL = time.Minute
+
+type repositorySizeEntry struct {
+	size      int64
+	expiresAt time.Time
+}
+
+// repositorySizeCache is safe for concurrent use; its zero value is ready.
+type repositorySizeCache struct {
+	mu      sync.Mutex
+	entries map[string]repositorySizeEntry
+}
+
+func (c *repositorySizeCache) get(repoPath string, now time.Time) (int64, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	entry, ok := c.entries[repoPath]
+	if !ok || !now.Before(entry.expiresAt) {
+		return 0, false
+	}
+
+	return entry.size, true
+}
+
+func (c *repositorySizeCache) set(repoPath string, size int64, now time.Time) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.entries == nil {
+		c.entries = make(map[string]repositorySizeEntry)
+	}
+	for path, entry := range c.entries {
+		if !now.Before(entry.expiresAt) {
+			delete(c.entries, path)
+		}
+	}
+
+	c.entries[repoPath] = repositorySizeEntry{size: size, expiresAt: now.Add(repositorySizeTTL)}
+}
+
+// repositoryDiskSize sums the regular files of a bare repository: loose
+// objects, packs, refs and the metadata git keeps next to them.
+func repositoryDiskSize(repoPath string) (int64, error) {
+	var size int64
+	err := filepath.WalkDir(repoPath, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if !d.Type().IsRegular() {
+			return nil
+		}
+
+		info, err := d.Info()
+		if errors.Is(err, os.ErrNotExist) {
+			// Removed by a concurrent gc or repack since the directory was read.
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+
+		size += info.Size()
+		return nil
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	return size, nil
+}
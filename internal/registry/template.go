@@ -0,0 +1,187 @@
+package registry
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/filemode"
+	"github.com/go-git/go-git/v5/plumbing/object"
+	"github.com/go-git/go-git/v5/plumbing/storer"
+)
+
+const templateCommitMessage = "Initial commit"
+
+func (s *service) templateAuthor() object.Signature {
+	email := "noreply@hasir.local"
+	if s.cfg != nil && s.cfg.Smtp.From != "" {
+		email = s.cfg.Smtp.From
+	}
+
+	return object.Signature{
+		Name:  "Hasir",
+		Email: email,
+		When:  time.Now().UTC(),
+	}
+}
+
+// commitTemplate writes the files under templateDir into the bare repository
+// as a single commit on the branch HEAD points to. Bare repositories have no
+// worktree, so the blobs, trees and commit are stored directly.
+func commitTemplate(repo *git.Repository, templateDir string, author object.Signature) error {
+	info, err := os.Stat(templateDir)
+	if err != nil {
+		return fmt.Errorf("failed to read template directory: %w", err)
+	}
+	if !info.IsDir() {
+		return fmt.Errorf("template path %q is not a directory", templateDir)
+	}
+
+	treeHash, empty, err := writeTemplateTree(repo.Storer, templateDir)
+	if err != nil {
+		return err
+	}
+	if empty {
+		return fmt.Errorf("template directory %q has no files", templateDir)
+	}
+
+	commit := &object.Commit{
+		Author:    author,
+		Committer: author,
+		Message:   templateCommitMessage,
+		TreeHash:  treeHash,
+	}
+	commitHash, err := storeObject(repo.Storer, commit)
+	if err != nil {
+		return fmt.Errorf("failed to store commit: %w", err)
+	}
+
+	head, err := repo.Storer.Reference(plumbing.HEAD)
+	if err != nil {
+		return fmt.Errorf("failed to read HEAD: %w", err)
+	}
+
+	branch := head.Target()
+	if head.Type() != plumbing.SymbolicReference {
+		branch = plumbing.Master
+	}
+
+	return repo.Storer.SetReference(plumbing.NewHashReference(branch, commitHash))
+}
+
+// writeTemplateTree stores dir as a tree object. Empty directories are skipped
+// because git cannot represent them.
+func writeTemplateTree(s storer.EncodedObjectStorer, dir string) (plumbing.Hash, bool, error) {
+	dirEntries, err := os.ReadDir(dir)
+	if err != nil {
+		return plumbing.ZeroHash, false, fmt.Errorf("failed to read %s: %w", dir, err)
+	}
+
+	var entries []object.TreeEntry
+	for _, dirEntry := range dirEntries {
+		if dirEntry.Name() == ".git" {
+			continue
+		}
+
+		entryPath := filepath.Join(dir, dirEntry.Name())
+		switch {
+		case dirEntry.IsDir():
+			hash, empty, err := writeTemplateTree(s, entryPath)
+			if err != nil {
+				return plumbing.ZeroHash, false, err
+			}
+			if empty {
+				continue
+			}
+			entries = append(entries, object.TreeEntry{Name: dirEntry.Name(), Mode: filemode.Dir, Hash: hash})
+		case dirEntry.Type().IsRegular():
+			entry, err := writeTemplateBlob(s, entryPath, dirEntry.Name())
+			if err != nil {
+				return plumbing.ZeroHash, false, err
+			}
+			entries = append(entries, entry)
+		}
+	}
+
+	if len(entries) == 0 {
+		return plumbing.ZeroHash, true, nil
+	}
+
+	// Git orders tree entries as if directory names had a trailing slash.
+	sort.Slice(entries, func(i, j int) bool {
+		return treeEntrySortKey(entries[i]) < treeEntrySortKey(entries[j])
+	})
+
+	hash, err := storeObject(s, &object.Tree{Entries: entries})
+	if err != nil {
+		return plumbing.ZeroHash, false, fmt.Errorf("failed to store tree: %w", err)
+	}
+
+	return hash, false, nil
+}
+
+func writeTemplateBlob(s storer.EncodedObjectStorer, path, name string) (object.TreeEntry, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return object.TreeEntry{}, fmt.Errorf("failed to stat %s: %w", path, err)
+	}
+
+	// #nosec G304 -- path comes from walking the configured template directory
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return object.TreeEntry{}, fmt.Errorf("failed to read %s: %w", path, err)
+	}
+
+	blob := s.NewEncodedObject()
+	blob.SetType(plumbing.BlobObject)
+	blob.SetSize(int64(len(content)))
+	writer, err := blob.Writer()
+	if err != nil {
+		return object.TreeEntry{}, err
+	}
+	if _, err := bytes.NewReader(content).WriteTo(writer); err != nil {
+		_ = writer.Close()
+		return object.TreeEntry{}, err
+	}
+	if err := writer.Close(); err != nil {
+		return object.TreeEntry{}, err
+	}
+
+	hash, err := s.SetEncodedObject(blob)
+	if err != nil {
+		return object.TreeEntry{}, fmt.Errorf("failed to store blob: %w", err)
+	}
+
+	mode := filemode.Regular
+	if info.Mode()&0o111 != 0 {
+		mode = filemode.Executable
+	}
+
+	return object.TreeEntry{Name: name, Mode: mode, Hash: hash}, nil
+}
+
+func treeEntrySortKey(entry object.TreeEntry) string {
+	if entry.Mode == filemode.Dir {
+		return entry.Name + "/"
+	}
+
+	return entry.Name
+}
+
+type encodableObject interface {
+	Encode(plumbing.EncodedObject) error
+}
+
+func storeObject(s storer.EncodedObjectStorer, obj encodableObject) (plumbing.Hash, error) {
+	encoded := s.NewEncodedObject()
+	if err := obj.Encode(encoded); err != nil {
+		return plumbing.ZeroHash, err
+	}
+
+	return s.SetEncodedObject(encoded)
+}
@@ -3,7 +3,6 @@ package user
 import (
 	"context"
 	"errors"
-	"math"
 	"net/http"
 	"strings"
 
@@ -16,6 +15,7 @@ import (
 	"google.golang.org/protobuf/types/known/emptypb"
 
 	"hasir-api/pkg/authentication"
+	"hasir-api/pkg/pagination"
 )
 
 type handler struct {
@@ -194,25 +194,15 @@ func (h *handler) GetApiKeys(
 		})
 	}
 
-	totalPages := (totalCount + pageSize - 1) / pageSize
-	if totalPages == 0 {
-		totalPages = 1
-	}
-	if totalPages > math.MaxInt32 {
-		return nil, connect.NewError(connect.CodeInternal, errors.New("total pages exceeds maximum value"))
-	}
-	nextPage := int32(0)
-	if page < totalPages {
-		if page+1 > math.MaxInt32 {
-			return nil, connect.NewError(connect.CodeInternal, errors.New("page number exceeds maximum value"))
-		}
-		nextPage = int32(page + 1) // #nosec G115 -- bounds checked above
+	nextPage, totalPages, err := pagination.PaginateInt32(totalCount, page, pageSize)
+	if err != nil {
+		return nil, err
 	}
 
 	return connect.NewResponse(&userv1.KeyResponse{
 		Keys:      keys,
 		NextPage:  nextPage,
-		TotalPage: int32(totalPages), // #nosec G115 -- bounds checked above
+		TotalPage: totalPages,
 	}), nil
 }
 
@@ -286,25 +276,15 @@ func (h *handler) GetSshKeys(
 		})
 	}
 
-	totalPages := (totalCount + pageSize - 1) / pageSize
-	if totalPages == 0 {
-		totalPages = 1
-	}
-	if totalPages > math.MaxInt32 {
-		return nil, connect.NewError(connect.CodeInternal, errors.New("total pages exceeds maximum value"))
-	}
-	nextPage := int32(0)
-	if page < totalPages {
-		if page+1 > math.MaxInt32 {
-			return nil, connect.NewError(connect.CodeInternal, errors.New("page number exceeds maximum value"))
-		}
-		nextPage = int32(page + 1) // #nosec G115 -- bounds checked above
+	nextPage, totalPages, err := pagination.PaginateInt32(totalCount, page, pageSize)
+	if err != nil {
+		return nil, err
 	}
 
 	return connect.NewResponse(&userv1.KeyResponse{
 		Keys:      keys,
 		NextPage:  nextPage,
-		TotalPage: int32(totalPages), // #nosec G115 -- bounds checked above
+		TotalPage: totalPages,
 	}), nil
 }
 
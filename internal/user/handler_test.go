@@ -834,6 +834,36 @@ func TestHandler_CreateSshKey(t *testing.T) {
 		assert.Nil(t, resp)
 	})
 
+	t.Run("key registered elsewhere under another comment is rejected", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		mockUserService := NewMockService(ctrl)
+		mockUserRepository := NewMockRepository(ctrl)
+
+		testUserID := "test-user-id"
+
+		publicKey := "ssh-ed25519 AAAAC3NzaC1lZDI1NTE5AAAAIBhVLF+dcZbEWbWr1A+8YYLBxDGgmBdwk6IB/+W5v/Wh laptop"
+		expectedNormalizedKey := "ssh-ed25519 AAAAC3NzaC1lZDI1NTE5AAAAIBhVLF+dcZbEWbWr1A+8YYLBxDGgmBdwk6IB/+W5v/Wh"
+
+		mockUserRepository.
+			EXPECT().
+			CreateSshKey(gomock.Any(), testUserID, gomock.Any(), expectedNormalizedKey).
+			Return(connect.NewError(connect.CodeAlreadyExists, errors.New("ssh key already exists for another user"))).
+			Times(1)
+
+		allInterceptors := append(interceptors, testAuthInterceptor(testUserID))
+		h := NewHandler(mockUserService, mockUserRepository, allInterceptors...)
+		server := setupTestServer(t, h)
+		defer server.Close()
+
+		client := userv1connect.NewUserServiceClient(http.DefaultClient, server.URL)
+		_, err := client.CreateSshKey(context.Background(), connect.NewRequest(&userv1.CreateSshKeyRequest{
+			Name:      "test-ssh-key",
+			PublicKey: publicKey,
+		}))
+
+		assert.Equal(t, connect.CodeAlreadyExists, connect.CodeOf(err))
+	})
+
 	t.Run("repository error", func(t *testing.T) {
 		ctrl := gomock.NewController(t)
 		mockUserService := NewMockService(ctrl)
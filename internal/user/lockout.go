@@ -0,0 +1,51 @@
+package user
+
+import (
+	"time"
+
+	"hasir-api/pkg/config"
+)
+
+type loginLockout struct {
+	maxFailedAttempts int
+	baseDuration      time.Duration
+	maxDuration       time.Duration
+}
+
+func newLoginLockout(cfg *config.Config) loginLockout {
+	var lockoutConfig config.LoginLockoutConfig
+	if cfg != nil {
+		lockoutConfig = cfg.LoginLockout
+	}
+
+	lockout := loginLockout{
+		maxFailedAttempts: lockoutConfig.GetMaxFailedAttempts(),
+		baseDuration:      time.Minute,
+		maxDuration:       time.Hour,
+	}
+	// Unparsable values are already rejected by config.Validate at startup.
+	if d, err := lockoutConfig.GetBaseDuration(); err == nil && d > 0 {
+		lockout.baseDuration = d
+	}
+	if d, err := lockoutConfig.GetMaxDuration(); err == nil && d > 0 {
+		lockout.maxDuration = d
+	}
+
+	return lockout
+}
+
+// duration returns how long an account stays locked after failedCount
+// consecutive failures: nothing below the threshold, then the base duration
+// doubling with every further failure up to the maximum.
+func (l loginLockout) duration(failedCount int) time.Duration {
+	if failedCount < l.maxFailedAttempts {
+		return 0
+	}
+
+	d := l.baseDuration
+	for i := l.maxFailedAttempts; i < failedCount && d < l.maxDuration; i++ {
+		d *= 2
+	}
+
+	return min(d, l.maxDuration)
+}
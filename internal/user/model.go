@@ -28,12 +28,13 @@ type ApiKeyDTO struct {
 }
 
 type SshKeyDTO struct {
-	Id        string     `db:"id"`
-	UserId    string     `db:"user_id"`
-	Name      string     `db:"name"`
-	PublicKey string     `db:"public_key"`
-	CreatedAt time.Time  `db:"created_at"`
-	DeletedAt *time.Time `db:"deleted_at"`
+	Id         string     `db:"id"`
+	UserId     string     `db:"user_id"`
+	Name       string     `db:"name"`
+	PublicKey  string     `db:"public_key"`
+	CreatedAt  time.Time  `db:"created_at"`
+	LastUsedAt *time.Time `db:"last_used_at"`
+	DeletedAt  *time.Time `db:"deleted_at"`
 }
 
 type PasswordResetTokenDTO struct {
@@ -44,3 +45,43 @@ type PasswordResetTokenDTO struct {
 	CreatedAt time.Time  `db:"created_at"`
 	UsedAt    *time.Time `db:"used_at"`
 }
+
+type OrganizationMembershipDTO struct {
+	OrganizationId string    `db:"organization_id"`
+	Slug           string    `db:"slug"`
+	DisplayName    string    `db:"display_name"`
+	Role           string    `db:"role"`
+	JoinedAt       time.Time `db:"joined_at"`
+}
+
+// CurrentUser is the profile of the authenticated caller together with the
+// organizations they belong to.
+type CurrentUser struct {
+	Id            string
+	Username      string
+	Email         string
+	CreatedAt     time.Time
+	Organizations []OrganizationMembershipDTO
+}
+
+type TotpSettingsDTO struct {
+	UserId       string     `db:"user_id"`
+	Secret       string     `db:"secret"`
+	EnabledAt    *time.Time `db:"enabled_at"`
+	LastUsedStep *int64     `db:"last_used_step"`
+	CreatedAt    time.Time  `db:"created_at"`
+}
+
+// TotpEnrollment is what a user needs to add the account to an authenticator
+// app. The secret is only ever returned here.
+type TotpEnrollment struct {
+	Secret string `json:"secret"`
+	Uri    string `json:"uri"`
+}
+
+type LoginAttemptsDTO struct {
+	UserId       string     `db:"user_id"`
+	FailedCount  int        `db:"failed_count"`
+	LastFailedAt time.Time  `db:"last_failed_at"`
+	LockedUntil  *time.Time `db:"locked_until"`
+}
@@ -10,6 +10,7 @@ type Repository interface {
 	GetUserByEmail(ctx context.Context, email string) (*UserDTO, error)
 	GetUsersByEmails(ctx context.Context, emails []string) (map[string]*UserDTO, error)
 	GetUserById(ctx context.Context, id string) (*UserDTO, error)
+	GetOrganizationMemberships(ctx context.Context, userId string) ([]OrganizationMembershipDTO, error)
 	CreateRefreshToken(ctx context.Context, id, token string, expiresAt time.Time) error
 	GetRefreshTokenByTokenId(ctx context.Context, token string) (*RefreshTokensDTO, error)
 	DeleteRefreshToken(ctx context.Context, userId, token string) error
@@ -23,9 +24,18 @@ type Repository interface {
 	GetSshKeys(ctx context.Context, userId string, page, pageSize int) (*[]SshKeyDTO, error)
 	GetSshKeysCount(ctx context.Context, userId string) (int, error)
 	RevokeSshKey(ctx context.Context, userId, keyId string) error
+	MarkSshKeyUsed(ctx context.Context, publicKey string, usedAt time.Time) error
 	GetUserBySshPublicKey(ctx context.Context, publicKey string) (*UserDTO, error)
 	GetUserByApiKey(ctx context.Context, apiKey string) (*UserDTO, error)
 	CreatePasswordResetToken(ctx context.Context, userId, token string, expiresAt time.Time) error
 	GetPasswordResetToken(ctx context.Context, token string) (*PasswordResetTokenDTO, error)
 	MarkPasswordResetTokenAsUsed(ctx context.Context, token string) error
+	SetTotpSecret(ctx context.Context, userId, secret string) error
+	GetTotpSettings(ctx context.Context, userId string) (*TotpSettingsDTO, error)
+	EnableTotp(ctx context.Context, userId string, enabledAt time.Time) error
+	UseTotpStep(ctx context.Context, userId string, step int64) (bool, error)
+	GetLoginAttempts(ctx context.Context, userId string) (*LoginAttemptsDTO, error)
+	RecordFailedLogin(ctx context.Context, userId string, failedAt time.Time) (int, error)
+	LockAccount(ctx context.Context, userId string, lockedUntil time.Time) error
+	ResetLoginAttempts(ctx context.Context, userId string) error
 }
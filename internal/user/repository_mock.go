@@ -139,6 +139,20 @@ func (mr *MockRepositoryMockRecorder) DeleteUser(ctx, userId any) *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteUser", reflect.TypeOf((*MockRepository)(nil).DeleteUser), ctx, userId)
 }
 
+// EnableTotp mocks base method.
+func (m *MockRepository) EnableTotp(ctx context.Context, userId string, enabledAt time.Time) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "EnableTotp", ctx, userId, enabledAt)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// EnableTotp indicates an expected call of EnableTotp.
+func (mr *MockRepositoryMockRecorder) EnableTotp(ctx, userId, enabledAt any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "EnableTotp", reflect.TypeOf((*MockRepository)(nil).EnableTotp), ctx, userId, enabledAt)
+}
+
 // GetApiKeys mocks base method.
 func (m *MockRepository) GetApiKeys(ctx context.Context, userId string, page, pageSize int) (*[]ApiKeyDTO, error) {
 	m.ctrl.T.Helper()
@@ -169,6 +183,36 @@ func (mr *MockRepositoryMockRecorder) GetApiKeysCount(ctx, userId any) *gomock.C
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetApiKeysCount", reflect.TypeOf((*MockRepository)(nil).GetApiKeysCount), ctx, userId)
 }
 
+// GetLoginAttempts mocks base method.
+func (m *MockRepository) GetLoginAttempts(ctx context.Context, userId string) (*LoginAttemptsDTO, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetLoginAttempts", ctx, userId)
+	ret0, _ := ret[0].(*LoginAttemptsDTO)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetLoginAttempts indicates an expected call of GetLoginAttempts.
+func (mr *MockRepositoryMockRecorder) GetLoginAttempts(ctx, userId any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetLoginAttempts", reflect.TypeOf((*MockRepository)(nil).GetLoginAttempts), ctx, userId)
+}
+
+// GetOrganizationMemberships mocks base method.
+func (m *MockRepository) GetOrganizationMemberships(ctx context.Context, userId string) ([]OrganizationMembershipDTO, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetOrganizationMemberships", ctx, userId)
+	ret0, _ := ret[0].([]OrganizationMembershipDTO)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetOrganizationMemberships indicates an expected call of GetOrganizationMemberships.
+func (mr *MockRepositoryMockRecorder) GetOrganizationMemberships(ctx, userId any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetOrganizationMemberships", reflect.TypeOf((*MockRepository)(nil).GetOrganizationMemberships), ctx, userId)
+}
+
 // GetPasswordResetToken mocks base method.
 func (m *MockRepository) GetPasswordResetToken(ctx context.Context, token string) (*PasswordResetTokenDTO, error) {
 	m.ctrl.T.Helper()
@@ -229,6 +273,21 @@ func (mr *MockRepositoryMockRecorder) GetSshKeysCount(ctx, userId any) *gomock.C
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetSshKeysCount", reflect.TypeOf((*MockRepository)(nil).GetSshKeysCount), ctx, userId)
 }
 
+// GetTotpSettings mocks base method.
+func (m *MockRepository) GetTotpSettings(ctx context.Context, userId string) (*TotpSettingsDTO, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetTotpSettings", ctx, userId)
+	ret0, _ := ret[0].(*TotpSettingsDTO)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetTotpSettings indicates an expected call of GetTotpSettings.
+func (mr *MockRepositoryMockRecorder) GetTotpSettings(ctx, userId any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetTotpSettings", reflect.TypeOf((*MockRepository)(nil).GetTotpSettings), ctx, userId)
+}
+
 // GetUserByApiKey mocks base method.
 func (m *MockRepository) GetUserByApiKey(ctx context.Context, apiKey string) (*UserDTO, error) {
 	m.ctrl.T.Helper()
@@ -304,6 +363,20 @@ func (mr *MockRepositoryMockRecorder) GetUsersByEmails(ctx, emails any) *gomock.
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetUsersByEmails", reflect.TypeOf((*MockRepository)(nil).GetUsersByEmails), ctx, emails)
 }
 
+// LockAccount mocks base method.
+func (m *MockRepository) LockAccount(ctx context.Context, userId string, lockedUntil time.Time) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "LockAccount", ctx, userId, lockedUntil)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// LockAccount indicates an expected call of LockAccount.
+func (mr *MockRepositoryMockRecorder) LockAccount(ctx, userId, lockedUntil any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "LockAccount", reflect.TypeOf((*MockRepository)(nil).LockAccount), ctx, userId, lockedUntil)
+}
+
 // MarkPasswordResetTokenAsUsed mocks base method.
 func (m *MockRepository) MarkPasswordResetTokenAsUsed(ctx context.Context, token string) error {
 	m.ctrl.T.Helper()
@@ -318,6 +391,49 @@ func (mr *MockRepositoryMockRecorder) MarkPasswordResetTokenAsUsed(ctx, token an
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "MarkPasswordResetTokenAsUsed", reflect.TypeOf((*MockRepository)(nil).MarkPasswordResetTokenAsUsed), ctx, token)
 }
 
+// MarkSshKeyUsed mocks base method.
+func (m *MockRepository) MarkSshKeyUsed(ctx context.Context, publicKey string, usedAt time.Time) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "MarkSshKeyUsed", ctx, publicKey, usedAt)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// MarkSshKeyUsed indicates an expected call of MarkSshKeyUsed.
+func (mr *MockRepositoryMockRecorder) MarkSshKeyUsed(ctx, publicKey, usedAt any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "MarkSshKeyUsed", reflect.TypeOf((*MockRepository)(nil).MarkSshKeyUsed), ctx, publicKey, usedAt)
+}
+
+// RecordFailedLogin mocks base method.
+func (m *MockRepository) RecordFailedLogin(ctx context.Context, userId string, failedAt time.Time) (int, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RecordFailedLogin", ctx, userId, failedAt)
+	ret0, _ := ret[0].(int)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// RecordFailedLogin indicates an expected call of RecordFailedLogin.
+func (mr *MockRepositoryMockRecorder) RecordFailedLogin(ctx, userId, failedAt any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RecordFailedLogin", reflect.TypeOf((*MockRepository)(nil).RecordFailedLogin), ctx, userId, failedAt)
+}
+
+// ResetLoginAttempts mocks base method.
+func (m *MockRepository) ResetLoginAttempts(ctx context.Context, userId string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ResetLoginAttempts", ctx, userId)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// ResetLoginAttempts indicates an expected call of ResetLoginAttempts.
+func (mr *MockRepositoryMockRecorder) ResetLoginAttempts(ctx, userId any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ResetLoginAttempts", reflect.TypeOf((*MockRepository)(nil).ResetLoginAttempts), ctx, userId)
+}
+
 // RevokeApiKey mocks base method.
 func (m *MockRepository) RevokeApiKey(ctx context.Context, userId, keyId string) error {
 	m.ctrl.T.Helper()
@@ -346,6 +462,20 @@ func (mr *MockRepositoryMockRecorder) RevokeSshKey(ctx, userId, keyId any) *gomo
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RevokeSshKey", reflect.TypeOf((*MockRepository)(nil).RevokeSshKey), ctx, userId, keyId)
 }
 
+// SetTotpSecret mocks base method.
+func (m *MockRepository) SetTotpSecret(ctx context.Context, userId, secret string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SetTotpSecret", ctx, userId, secret)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SetTotpSecret indicates an expected call of SetTotpSecret.
+func (mr *MockRepositoryMockRecorder) SetTotpSecret(ctx, userId, secret any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetTotpSecret", reflect.TypeOf((*MockRepository)(nil).SetTotpSecret), ctx, userId, secret)
+}
+
 // UpdateUserById mocks base method.
 func (m *MockRepository) UpdateUserById(ctx context.Context, id string, user *UserDTO) error {
 	m.ctrl.T.Helper()
@@ -359,3 +489,18 @@ func (mr *MockRepositoryMockRecorder) UpdateUserById(ctx, id, user any) *gomock.
 	mr.mock.ctrl.T.Helper()
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateUserById", reflect.TypeOf((*MockRepository)(nil).UpdateUserById), ctx, id, user)
 }
+
+// UseTotpStep mocks base method.
+func (m *MockRepository) UseTotpStep(ctx context.Context, userId string, step int64) (bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UseTotpStep", ctx, userId, step)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// UseTotpStep indicates an expected call of UseTotpStep.
+func (mr *MockRepositoryMockRecorder) UseTotpStep(ctx, userId, step any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UseTotpStep", reflect.TypeOf((*MockRepository)(nil).UseTotpStep), ctx, userId, step)
+}
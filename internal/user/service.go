@@ -3,12 +3,16 @@ package user
 import (
 	"context"
 	"errors"
+	"fmt"
+	"math"
+	"strconv"
 	"time"
 
 	userv1 "buf.build/gen/go/hasir/hasir/protocolbuffers/go/user/v1"
 	"connectrpc.com/connect"
 	"github.com/golang-jwt/jwt/v5"
 	"github.com/google/uuid"
+	"go.uber.org/zap"
 	"golang.org/x/crypto/bcrypt"
 
 	"hasir-api/pkg/authentication"
@@ -24,12 +28,18 @@ type Service interface {
 	RenewTokens(ctx context.Context, req *userv1.RenewTokensRequest) (*userv1.RenewTokensResponse, error)
 	ForgotPassword(ctx context.Context, req *userv1.ForgotPasswordRequest) error
 	ResetPassword(ctx context.Context, req *userv1.ResetPasswordRequest) error
+	GetCurrentUser(ctx context.Context) (*CurrentUser, error)
+	EnrollTotp(ctx context.Context) (*TotpEnrollment, error)
+	VerifyTotp(ctx context.Context, code string) (*userv1.TokenEnvelope, error)
+	IsTotpEnabled(ctx context.Context, userId string) (bool, error)
 }
 
 type service struct {
-	config         *config.Config
-	userRepository Repository
-	emailService   EmailService
+	config           *config.Config
+	userRepository   Repository
+	emailService     EmailService
+	passwordHashCost int
+	loginLockout     loginLockout
 }
 
 type EmailService interface {
@@ -37,10 +47,17 @@ type EmailService interface {
 }
 
 func NewService(config *config.Config, userRepository Repository, emailService EmailService) *service {
+	passwordHashCost := bcrypt.DefaultCost
+	if config != nil {
+		passwordHashCost = config.GetPasswordHashCost()
+	}
+
 	return &service{
-		config:         config,
-		userRepository: userRepository,
-		emailService:   emailService,
+		config:           config,
+		userRepository:   userRepository,
+		emailService:     emailService,
+		passwordHashCost: passwordHashCost,
+		loginLockout:     newLoginLockout(config),
 	}
 }
 
@@ -58,7 +75,7 @@ func (s *service) Register(ctx context.Context, req *userv1.RegisterRequest) err
 	}
 
 	var hashedPassword []byte
-	hashedPassword, err = bcrypt.GenerateFromPassword([]byte(req.Password), bcrypt.DefaultCost)
+	hashedPassword, err = bcrypt.GenerateFromPassword([]byte(req.Password), s.passwordHashCost)
 	if err != nil {
 		return ErrInternalServer
 	}
@@ -85,11 +102,29 @@ func (s *service) Login(ctx context.Context, req *userv1.LoginRequest) (*userv1.
 		return nil, err
 	}
 
+	now := time.Now().UTC()
+	attempts, err := s.checkLoginLock(ctx, user.Id, now)
+	if err != nil {
+		return nil, err
+	}
+
 	if err = bcrypt.CompareHashAndPassword([]byte(user.Password), []byte(req.Password)); err != nil {
+		if err := s.recordFailedLogin(ctx, user.Id, now); err != nil {
+			return nil, err
+		}
+
 		return nil, connect.NewError(connect.CodeUnauthenticated, errors.New("invalid credentials"))
 	}
 
-	tokens, refreshTokenID, err := s.generateTokens(user)
+	if attempts != nil {
+		if err = s.userRepository.ResetLoginAttempts(ctx, user.Id); err != nil {
+			return nil, err
+		}
+	}
+
+	s.rehashPasswordIfNeeded(ctx, user, req.Password)
+
+	tokens, refreshTokenID, err := s.generateTokens(user, false)
 	if err != nil {
 		return nil, err
 	}
@@ -107,6 +142,73 @@ func (s *service) Login(ctx context.Context, req *userv1.LoginRequest) (*userv1.
 	return tokens, nil
 }
 
+// checkLoginLock returns the user's failed attempts, nil when there are none,
+// or an error while the account is locked.
+func (s *service) checkLoginLock(ctx context.Context, userId string, now time.Time) (*LoginAttemptsDTO, error) {
+	attempts, err := s.userRepository.GetLoginAttempts(ctx, userId)
+	if err != nil {
+		var connectErr *connect.Error
+		if !errors.As(err, &connectErr) || connectErr.Code() != connect.CodeNotFound {
+			return nil, err
+		}
+	}
+
+	if attempts != nil && attempts.LockedUntil != nil && now.Before(*attempts.LockedUntil) {
+		return nil, accountLockedError(attempts.LockedUntil.Sub(now))
+	}
+
+	return attempts, nil
+}
+
+func (s *service) recordFailedLogin(ctx context.Context, userId string, failedAt time.Time) error {
+	failedCount, err := s.userRepository.RecordFailedLogin(ctx, userId, failedAt)
+	if err != nil {
+		return err
+	}
+
+	if lockFor := s.loginLockout.duration(failedCount); lockFor > 0 {
+		return s.userRepository.LockAccount(ctx, userId, failedAt.Add(lockFor))
+	}
+
+	return nil
+}
+
+// accountLockedError tells the client when to try again, both in the message
+// and as a Retry-After header in seconds.
+func accountLockedError(retryAfter time.Duration) error {
+	seconds := int(math.Ceil(retryAfter.Seconds()))
+	err := connect.NewError(
+		connect.CodeResourceExhausted,
+		fmt.Errorf("account is temporarily locked after repeated failed logins, retry in %d seconds", seconds),
+	)
+	err.Meta().Set("Retry-After", strconv.Itoa(seconds))
+
+	return err
+}
+
+// rehashPasswordIfNeeded upgrades a hash made with a lower cost than the
+// configured one. The plain password is only available at login, so this is
+// the one place it can happen; failures are logged and the login goes on.
+func (s *service) rehashPasswordIfNeeded(ctx context.Context, user *UserDTO, password string) {
+	cost, err := bcrypt.Cost([]byte(user.Password))
+	if err != nil || cost >= s.passwordHashCost {
+		return
+	}
+
+	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(password), s.passwordHashCost)
+	if err != nil {
+		zap.L().Warn("failed to rehash password", zap.String("userId", user.Id), zap.Error(err))
+		return
+	}
+
+	if err := s.userRepository.UpdateUserById(ctx, user.Id, &UserDTO{Password: string(hashedPassword)}); err != nil {
+		zap.L().Warn("failed to store rehashed password", zap.String("userId", user.Id), zap.Error(err))
+		return
+	}
+
+	user.Password = string(hashedPassword)
+}
+
 func (s *service) UpdateUser(ctx context.Context, req *userv1.UpdateUserRequest) (*userv1.TokenEnvelope, error) {
 	userID, err := authentication.MustGetUserID(ctx)
 	if err != nil {
@@ -131,7 +233,7 @@ func (s *service) UpdateUser(ctx context.Context, req *userv1.UpdateUserRequest)
 
 	if req.GetNewPassword() != "" {
 		var hashedNewPassword []byte
-		hashedNewPassword, err = bcrypt.GenerateFromPassword([]byte(req.GetNewPassword()), bcrypt.DefaultCost)
+		hashedNewPassword, err = bcrypt.GenerateFromPassword([]byte(req.GetNewPassword()), s.passwordHashCost)
 		if err != nil {
 			return nil, ErrInternalServer
 		}
@@ -142,7 +244,7 @@ func (s *service) UpdateUser(ctx context.Context, req *userv1.UpdateUserRequest)
 		return nil, err
 	}
 
-	tokens, refreshTokenID, err := s.generateTokens(updatedUser)
+	tokens, refreshTokenID, err := s.generateTokens(updatedUser, false)
 	if err != nil {
 		return nil, err
 	}
@@ -220,7 +322,7 @@ func (s *service) RenewTokens(ctx context.Context, req *userv1.RenewTokensReques
 		return nil, err
 	}
 
-	tokens, _, err := s.generateTokens(user)
+	tokens, _, err := s.generateTokens(user, false)
 	if err != nil {
 		return nil, err
 	}
@@ -230,12 +332,15 @@ func (s *service) RenewTokens(ctx context.Context, req *userv1.RenewTokensReques
 	}, nil
 }
 
-func (s *service) generateTokens(user *UserDTO) (*userv1.TokenEnvelope, string, error) {
+// generateTokens issues an access and refresh token pair. Only the access
+// token carries totpVerified, so renewing it drops back to an unverified token.
+func (s *service) generateTokens(user *UserDTO, totpVerified bool) (*userv1.TokenEnvelope, string, error) {
 	now := time.Now().UTC()
 	accessTokenExpiresAt := now.Add(2 * time.Hour)
 	accessTokenClaims := authentication.JwtClaims{
-		Email:    user.Email,
-		Username: user.Username,
+		Email:        user.Email,
+		Username:     user.Username,
+		TotpVerified: totpVerified,
 		RegisteredClaims: jwt.RegisteredClaims{
 			ID:        uuid.NewString(),
 			Issuer:    s.config.Server.PublicUrl,
@@ -320,7 +425,7 @@ func (s *service) ResetPassword(ctx context.Context, req *userv1.ResetPasswordRe
 		return connect.NewError(connect.CodeInvalidArgument, errors.New("reset token has expired"))
 	}
 
-	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(req.NewPassword), bcrypt.DefaultCost)
+	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(req.NewPassword), s.passwordHashCost)
 	if err != nil {
 		return ErrInternalServer
 	}
@@ -347,3 +452,176 @@ func (s *service) ResetPassword(ctx context.Context, req *userv1.ResetPasswordRe
 
 	return nil
 }
+
+// GetCurrentUser resolves the caller of a request to their profile, so clients
+// holding only a token do not need to decode it. A token outliving its account
+// is treated like no token at all.
+func (s *service) GetCurrentUser(ctx context.Context) (*CurrentUser, error) {
+	userID, err := authentication.MustGetUserID(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	user, err := s.userRepository.GetUserById(ctx, userID)
+	if err != nil {
+		var connectErr *connect.Error
+		if errors.As(err, &connectErr) && connectErr.Code() == connect.CodeNotFound {
+			return nil, connect.NewError(connect.CodeUnauthenticated, errors.New("user no longer exists"))
+		}
+
+		return nil, err
+	}
+
+	if user.DeletedAt != nil {
+		return nil, connect.NewError(connect.CodeUnauthenticated, errors.New("user no longer exists"))
+	}
+
+	memberships, err := s.userRepository.GetOrganizationMemberships(ctx, user.Id)
+	if err != nil {
+		return nil, err
+	}
+
+	return &CurrentUser{
+		Id:            user.Id,
+		Username:      user.Username,
+		Email:         user.Email,
+		CreatedAt:     user.CreatedAt,
+		Organizations: memberships,
+	}, nil
+}
+
+// EnrollTotp generates a new TOTP secret for the caller. The second factor is
+// only enforced once VerifyTotp has accepted a code for it.
+func (s *service) EnrollTotp(ctx context.Context) (*TotpEnrollment, error) {
+	userID, err := authentication.MustGetUserID(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	user, err := s.userRepository.GetUserById(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	enabled, err := s.IsTotpEnabled(ctx, user.Id)
+	if err != nil {
+		return nil, err
+	}
+	if enabled {
+		return nil, connect.NewError(connect.CodeFailedPrecondition, errors.New("two-factor authentication is already enabled"))
+	}
+
+	secret, err := authentication.GenerateTotpSecret()
+	if err != nil {
+		return nil, ErrInternalServer
+	}
+
+	sealedSecret, err := authentication.SealTotpSecret(s.config.JwtSecret, secret)
+	if err != nil {
+		return nil, ErrInternalServer
+	}
+
+	if err = s.userRepository.SetTotpSecret(ctx, user.Id, sealedSecret); err != nil {
+		return nil, err
+	}
+
+	return &TotpEnrollment{
+		Secret: secret,
+		Uri:    authentication.TotpUri(s.config.Totp.GetIssuer(), user.Email, secret),
+	}, nil
+}
+
+// VerifyTotp checks a code against the caller's secret, completing enrollment
+// on first use, and returns tokens whose access token passes the TOTP gate.
+// Wrong codes count towards the same lockout as wrong passwords, and each
+// code is accepted only once.
+func (s *service) VerifyTotp(ctx context.Context, code string) (*userv1.TokenEnvelope, error) {
+	userID, err := authentication.MustGetUserID(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	user, err := s.userRepository.GetUserById(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	settings, err := s.userRepository.GetTotpSettings(ctx, user.Id)
+	if err != nil {
+		var connectErr *connect.Error
+		if errors.As(err, &connectErr) && connectErr.Code() == connect.CodeNotFound {
+			return nil, connect.NewError(connect.CodeFailedPrecondition, errors.New("two-factor authentication is not enrolled"))
+		}
+
+		return nil, err
+	}
+
+	secret, err := authentication.OpenTotpSecret(s.config.JwtSecret, settings.Secret)
+	if err != nil {
+		return nil, ErrInternalServer
+	}
+
+	now := time.Now().UTC()
+	attempts, err := s.checkLoginLock(ctx, user.Id, now)
+	if err != nil {
+		return nil, err
+	}
+
+	step, ok := authentication.ValidateTotpCode(secret, code, now)
+	if !ok {
+		if err := s.recordFailedLogin(ctx, user.Id, now); err != nil {
+			return nil, err
+		}
+
+		return nil, connect.NewError(connect.CodeUnauthenticated, errors.New("invalid two-factor code"))
+	}
+
+	fresh, err := s.userRepository.UseTotpStep(ctx, user.Id, step)
+	if err != nil {
+		return nil, err
+	}
+	if !fresh {
+		if err := s.recordFailedLogin(ctx, user.Id, now); err != nil {
+			return nil, err
+		}
+
+		return nil, connect.NewError(connect.CodeUnauthenticated, errors.New("two-factor code has already been used"))
+	}
+
+	if attempts != nil {
+		if err = s.userRepository.ResetLoginAttempts(ctx, user.Id); err != nil {
+			return nil, err
+		}
+	}
+
+	if settings.EnabledAt == nil {
+		if err = s.userRepository.EnableTotp(ctx, user.Id, now); err != nil {
+			return nil, err
+		}
+	}
+
+	tokens, refreshTokenID, err := s.generateTokens(user, true)
+	if err != nil {
+		return nil, err
+	}
+
+	if err = s.userRepository.CreateRefreshToken(ctx, user.Id, refreshTokenID, now.AddDate(0, 0, 14)); err != nil {
+		return nil, err
+	}
+
+	return tokens, nil
+}
+
+func (s *service) IsTotpEnabled(ctx context.Context, userId string) (bool, error) {
+	settings, err := s.userRepository.GetTotpSettings(ctx, userId)
+	if err != nil {
+		var connectErr *connect.Error
+		if errors.As(err, &connectErr) && connectErr.Code() == connect.CodeNotFound {
+			return false, nil
+		}
+
+		return false, err
+	}
+
+	return settings.EnabledAt != nil, nil
+}
@@ -41,6 +41,21 @@ func (m *MockService) EXPECT() *MockServiceMockRecorder {
 	return m.recorder
 }
 
+// EnrollTotp mocks base method.
+func (m *MockService) EnrollTotp(ctx context.Context) (*TotpEnrollment, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "EnrollTotp", ctx)
+	ret0, _ := ret[0].(*TotpEnrollment)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// EnrollTotp indicates an expected call of EnrollTotp.
+func (mr *MockServiceMockRecorder) EnrollTotp(ctx any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "EnrollTotp", reflect.TypeOf((*MockService)(nil).EnrollTotp), ctx)
+}
+
 // ForgotPassword mocks base method.
 func (m *MockService) ForgotPassword(ctx context.Context, req *userv1.ForgotPasswordRequest) error {
 	m.ctrl.T.Helper()
@@ -55,6 +70,36 @@ func (mr *MockServiceMockRecorder) ForgotPassword(ctx, req any) *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ForgotPassword", reflect.TypeOf((*MockService)(nil).ForgotPassword), ctx, req)
 }
 
+// GetCurrentUser mocks base method.
+func (m *MockService) GetCurrentUser(ctx context.Context) (*CurrentUser, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetCurrentUser", ctx)
+	ret0, _ := ret[0].(*CurrentUser)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetCurrentUser indicates an expected call of GetCurrentUser.
+func (mr *MockServiceMockRecorder) GetCurrentUser(ctx any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetCurrentUser", reflect.TypeOf((*MockService)(nil).GetCurrentUser), ctx)
+}
+
+// IsTotpEnabled mocks base method.
+func (m *MockService) IsTotpEnabled(ctx context.Context, userId string) (bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "IsTotpEnabled", ctx, userId)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// IsTotpEnabled indicates an expected call of IsTotpEnabled.
+func (mr *MockServiceMockRecorder) IsTotpEnabled(ctx, userId any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "IsTotpEnabled", reflect.TypeOf((*MockService)(nil).IsTotpEnabled), ctx, userId)
+}
+
 // Login mocks base method.
 func (m *MockService) Login(ctx context.Context, req *userv1.LoginRequest) (*userv1.TokenEnvelope, error) {
 	m.ctrl.T.Helper()
@@ -127,3 +172,18 @@ func (mr *MockServiceMockRecorder) UpdateUser(ctx, req any) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateUser", reflect.TypeOf((*MockService)(nil).UpdateUser), ctx, req)
 }
+
+// VerifyTotp mocks base method.
+func (m *MockService) VerifyTotp(ctx context.Context, code string) (*userv1.TokenEnvelope, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "VerifyTotp", ctx, code)
+	ret0, _ := ret[0].(*userv1.TokenEnvelope)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// VerifyTotp indicates an expected call of VerifyTotp.
+func (mr *MockServiceMockRecorder) VerifyTotp(ctx, code any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "VerifyTotp", reflect.TypeOf((*MockService)(nil).VerifyTotp), ctx, code)
+}
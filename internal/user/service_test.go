@@ -20,7 +20,10 @@ import (
 	"hasir-api/pkg/email"
 )
 
-var ErrNoRows = connect.NewError(connect.CodeNotFound, errors.New("user not found"))
+var (
+	ErrNoRows          = connect.NewError(connect.CodeNotFound, errors.New("user not found"))
+	errNoLoginAttempts = connect.NewError(connect.CodeNotFound, errors.New("no failed login attempts"))
+)
 
 func TestNewService(t *testing.T) {
 	s := NewService(nil, nil, nil)
@@ -148,6 +151,11 @@ func TestService_Login(t *testing.T) {
 				CreatedAt: time.Now().UTC(),
 			}, nil).
 			Times(1)
+		mockUserRepository.
+			EXPECT().
+			GetLoginAttempts(gomock.Any(), gomock.Any()).
+			Return(nil, errNoLoginAttempts).
+			Times(1)
 		mockUserRepository.
 			EXPECT().
 			CreateRefreshToken(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).
@@ -181,6 +189,16 @@ func TestService_Login(t *testing.T) {
 				CreatedAt: time.Now().UTC(),
 			}, nil).
 			Times(1)
+		mockUserRepository.
+			EXPECT().
+			GetLoginAttempts(gomock.Any(), gomock.Any()).
+			Return(nil, errNoLoginAttempts).
+			Times(1)
+		mockUserRepository.
+			EXPECT().
+			RecordFailedLogin(gomock.Any(), gomock.Any(), gomock.Any()).
+			Return(1, nil).
+			Times(1)
 
 		s := NewService(cfg, mockUserRepository, nil)
 		tokens, err := s.Login(t.Context(), &userv1.LoginRequest{
@@ -208,6 +226,11 @@ func TestService_Login(t *testing.T) {
 				CreatedAt: time.Now().UTC(),
 			}, nil).
 			Times(1)
+		mockUserRepository.
+			EXPECT().
+			GetLoginAttempts(gomock.Any(), gomock.Any()).
+			Return(nil, errNoLoginAttempts).
+			Times(1)
 		mockUserRepository.
 			EXPECT().
 			CreateRefreshToken(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).
@@ -223,6 +246,94 @@ func TestService_Login(t *testing.T) {
 		assert.Errorf(t, err, "something went wrong")
 		assert.Nil(t, tokens)
 	})
+
+	t.Run("under-cost hash is rehashed with the configured cost", func(t *testing.T) {
+		hashedPwd, err := bcrypt.GenerateFromPassword([]byte("Asdfg12345_"), bcrypt.MinCost)
+		require.NoError(t, err)
+
+		userId := uuid.NewString()
+		var rehashed string
+		mockUserRepository := NewMockRepository(mockController)
+		mockUserRepository.
+			EXPECT().
+			GetUserByEmail(gomock.Any(), gomock.Any()).
+			Return(&UserDTO{
+				Id:       userId,
+				Email:    "test@mail.com",
+				Password: string(hashedPwd),
+			}, nil).
+			Times(1)
+		mockUserRepository.
+			EXPECT().
+			GetLoginAttempts(gomock.Any(), gomock.Any()).
+			Return(nil, errNoLoginAttempts).
+			Times(1)
+		mockUserRepository.
+			EXPECT().
+			UpdateUserById(gomock.Any(), userId, gomock.Any()).
+			DoAndReturn(func(_ context.Context, _ string, user *UserDTO) error {
+				rehashed = user.Password
+				return nil
+			}).
+			Times(1)
+		mockUserRepository.
+			EXPECT().
+			CreateRefreshToken(gomock.Any(), userId, gomock.Any(), gomock.Any()).
+			Return(nil).
+			Times(1)
+
+		costlierCfg := *cfg
+		costlierCfg.PasswordHashCost = bcrypt.MinCost + 1
+		s := NewService(&costlierCfg, mockUserRepository, nil)
+		_, err = s.Login(t.Context(), &userv1.LoginRequest{
+			Email:    "test@mail.com",
+			Password: "Asdfg12345_",
+		})
+		require.NoError(t, err)
+
+		cost, err := bcrypt.Cost([]byte(rehashed))
+		require.NoError(t, err)
+		assert.Equal(t, bcrypt.MinCost+1, cost)
+		assert.NoError(t, bcrypt.CompareHashAndPassword([]byte(rehashed), []byte("Asdfg12345_")))
+	})
+
+	t.Run("failing rehash does not fail the login", func(t *testing.T) {
+		hashedPwd, err := bcrypt.GenerateFromPassword([]byte("Asdfg12345_"), bcrypt.MinCost)
+		require.NoError(t, err)
+
+		mockUserRepository := NewMockRepository(mockController)
+		mockUserRepository.
+			EXPECT().
+			GetUserByEmail(gomock.Any(), gomock.Any()).
+			Return(&UserDTO{Id: uuid.NewString(), Password: string(hashedPwd)}, nil).
+			Times(1)
+		mockUserRepository.
+			EXPECT().
+			GetLoginAttempts(gomock.Any(), gomock.Any()).
+			Return(nil, errNoLoginAttempts).
+			Times(1)
+		mockUserRepository.
+			EXPECT().
+			UpdateUserById(gomock.Any(), gomock.Any(), gomock.Any()).
+			Return(errors.New("something went wrong")).
+			Times(1)
+		mockUserRepository.
+			EXPECT().
+			CreateRefreshToken(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).
+			Return(nil).
+			Times(1)
+
+		costlierCfg := *cfg
+		costlierCfg.PasswordHashCost = bcrypt.MinCost + 1
+		s := NewService(&costlierCfg, mockUserRepository, nil)
+		tokens, err := s.Login(t.Context(), &userv1.LoginRequest{
+			Email:    "test@mail.com",
+			Password: "Asdfg12345_",
+		})
+
+		require.NoError(t, err)
+		assert.NotEmpty(t, tokens.AccessToken)
+	})
 }
 
 func TestService_UpdateUser(t *testing.T) {
@@ -857,3 +968,480 @@ func TestService_ResetPassword(t *testing.T) {
 		assert.Error(t, err)
 	})
 }
+
+func TestService_GetCurrentUser(t *testing.T) {
+	mockController := gomock.NewController(t)
+	defer mockController.Finish()
+
+	userId := uuid.NewString()
+	createdAt := time.Now().UTC()
+
+	t.Run("happy path", func(t *testing.T) {
+		memberships := []OrganizationMembershipDTO{
+			{OrganizationId: "org-1", Slug: "acme", DisplayName: "Acme", Role: "owner", JoinedAt: createdAt},
+		}
+
+		mockUserRepository := NewMockRepository(mockController)
+		mockUserRepository.
+			EXPECT().
+			GetUserById(gomock.Any(), userId).
+			Return(&UserDTO{
+				Id:        userId,
+				Username:  "test-user",
+				Email:     "test@mail.com",
+				Password:  "hashed",
+				CreatedAt: createdAt,
+			}, nil).
+			Times(1)
+		mockUserRepository.
+			EXPECT().
+			GetOrganizationMemberships(gomock.Any(), userId).
+			Return(memberships, nil).
+			Times(1)
+
+		s := NewService(nil, mockUserRepository, nil)
+		ctx := context.WithValue(t.Context(), authentication.UserIDKey, userId)
+		currentUser, err := s.GetCurrentUser(ctx)
+
+		require.NoError(t, err)
+		assert.Equal(t, userId, currentUser.Id)
+		assert.Equal(t, "test-user", currentUser.Username)
+		assert.Equal(t, "test@mail.com", currentUser.Email)
+		assert.Equal(t, createdAt, currentUser.CreatedAt)
+		assert.Equal(t, memberships, currentUser.Organizations)
+	})
+
+	t.Run("unauthenticated", func(t *testing.T) {
+		mockUserRepository := NewMockRepository(mockController)
+
+		s := NewService(nil, mockUserRepository, nil)
+		currentUser, err := s.GetCurrentUser(t.Context())
+
+		assert.Nil(t, currentUser)
+		assert.Equal(t, connect.CodeUnauthenticated, connect.CodeOf(err))
+	})
+
+	t.Run("deleted user", func(t *testing.T) {
+		deletedAt := time.Now().UTC()
+		mockUserRepository := NewMockRepository(mockController)
+		mockUserRepository.
+			EXPECT().
+			GetUserById(gomock.Any(), userId).
+			Return(&UserDTO{Id: userId, DeletedAt: &deletedAt}, nil).
+			Times(1)
+
+		s := NewService(nil, mockUserRepository, nil)
+		ctx := context.WithValue(t.Context(), authentication.UserIDKey, userId)
+		_, err := s.GetCurrentUser(ctx)
+
+		assert.Equal(t, connect.CodeUnauthenticated, connect.CodeOf(err))
+	})
+
+	t.Run("user not found", func(t *testing.T) {
+		mockUserRepository := NewMockRepository(mockController)
+		mockUserRepository.
+			EXPECT().
+			GetUserById(gomock.Any(), userId).
+			Return(nil, ErrNoRows).
+			Times(1)
+
+		s := NewService(nil, mockUserRepository, nil)
+		ctx := context.WithValue(t.Context(), authentication.UserIDKey, userId)
+		_, err := s.GetCurrentUser(ctx)
+
+		assert.Equal(t, connect.CodeUnauthenticated, connect.CodeOf(err))
+	})
+}
+
+func TestService_EnrollTotp(t *testing.T) {
+	mockController := gomock.NewController(t)
+	defer mockController.Finish()
+
+	cfg := &config.Config{JwtSecret: []byte("jwt-secret")}
+	userId := uuid.NewString()
+	ctx := context.WithValue(t.Context(), authentication.UserIDKey, userId)
+
+	t.Run("happy path", func(t *testing.T) {
+		mockUserRepository := NewMockRepository(mockController)
+		mockUserRepository.
+			EXPECT().
+			GetUserById(gomock.Any(), userId).
+			Return(&UserDTO{Id: userId, Email: "test@mail.com"}, nil).
+			Times(1)
+		mockUserRepository.
+			EXPECT().
+			GetTotpSettings(gomock.Any(), userId).
+			Return(nil, connect.NewError(connect.CodeNotFound, errors.New("totp is not enrolled"))).
+			Times(1)
+
+		var storedSecret string
+		mockUserRepository.
+			EXPECT().
+			SetTotpSecret(gomock.Any(), userId, gomock.Any()).
+			DoAndReturn(func(_ context.Context, _ string, secret string) error {
+				storedSecret = secret
+				return nil
+			}).
+			Times(1)
+
+		s := NewService(cfg, mockUserRepository, nil)
+		enrollment, err := s.EnrollTotp(ctx)
+
+		require.NoError(t, err)
+		assert.NotEmpty(t, enrollment.Secret)
+		assert.Contains(t, enrollment.Uri, "otpauth://totp/Hasir:test@mail.com?")
+		assert.NotEqual(t, enrollment.Secret, storedSecret, "secret must not be stored in plain text")
+
+		opened, err := authentication.OpenTotpSecret(cfg.JwtSecret, storedSecret)
+		require.NoError(t, err)
+		assert.Equal(t, enrollment.Secret, opened)
+	})
+
+	t.Run("already enabled", func(t *testing.T) {
+		enabledAt := time.Now().UTC()
+		mockUserRepository := NewMockRepository(mockController)
+		mockUserRepository.
+			EXPECT().
+			GetUserById(gomock.Any(), userId).
+			Return(&UserDTO{Id: userId}, nil).
+			Times(1)
+		mockUserRepository.
+			EXPECT().
+			GetTotpSettings(gomock.Any(), userId).
+			Return(&TotpSettingsDTO{UserId: userId, EnabledAt: &enabledAt}, nil).
+			Times(1)
+
+		s := NewService(cfg, mockUserRepository, nil)
+		_, err := s.EnrollTotp(ctx)
+
+		assert.Equal(t, connect.CodeFailedPrecondition, connect.CodeOf(err))
+	})
+
+	t.Run("unauthenticated", func(t *testing.T) {
+		s := NewService(cfg, NewMockRepository(mockController), nil)
+		_, err := s.EnrollTotp(t.Context())
+
+		assert.Equal(t, connect.CodeUnauthenticated, connect.CodeOf(err))
+	})
+}
+
+func TestService_VerifyTotp(t *testing.T) {
+	mockController := gomock.NewController(t)
+	defer mockController.Finish()
+
+	cfg := &config.Config{JwtSecret: []byte("jwt-secret")}
+	userId := uuid.NewString()
+	ctx := context.WithValue(t.Context(), authentication.UserIDKey, userId)
+
+	secret, err := authentication.GenerateTotpSecret()
+	require.NoError(t, err)
+	sealedSecret, err := authentication.SealTotpSecret(cfg.JwtSecret, secret)
+	require.NoError(t, err)
+
+	t.Run("first valid code enables totp and returns a verified token", func(t *testing.T) {
+		mockUserRepository := NewMockRepository(mockController)
+		mockUserRepository.
+			EXPECT().
+			GetUserById(gomock.Any(), userId).
+			Return(&UserDTO{Id: userId, Email: "test@mail.com"}, nil).
+			Times(1)
+		mockUserRepository.
+			EXPECT().
+			GetTotpSettings(gomock.Any(), userId).
+			Return(&TotpSettingsDTO{UserId: userId, Secret: sealedSecret}, nil).
+			Times(1)
+		mockUserRepository.
+			EXPECT().
+			GetLoginAttempts(gomock.Any(), userId).
+			Return(nil, errNoLoginAttempts).
+			Times(1)
+		mockUserRepository.
+			EXPECT().
+			UseTotpStep(gomock.Any(), userId, gomock.Any()).
+			Return(true, nil).
+			Times(1)
+		mockUserRepository.
+			EXPECT().
+			EnableTotp(gomock.Any(), userId, gomock.Any()).
+			Return(nil).
+			Times(1)
+		mockUserRepository.
+			EXPECT().
+			CreateRefreshToken(gomock.Any(), userId, gomock.Any(), gomock.Any()).
+			Return(nil).
+			Times(1)
+
+		code, err := authentication.GenerateTotpCode(secret, time.Now())
+		require.NoError(t, err)
+
+		s := NewService(cfg, mockUserRepository, nil)
+		tokens, err := s.VerifyTotp(ctx, code)
+		require.NoError(t, err)
+
+		claims := &authentication.JwtClaims{}
+		_, err = jwt.ParseWithClaims(tokens.AccessToken, claims, func(token *jwt.Token) (any, error) {
+			return cfg.JwtSecret, nil
+		})
+		require.NoError(t, err)
+		assert.True(t, claims.TotpVerified)
+	})
+
+	t.Run("invalid code", func(t *testing.T) {
+		mockUserRepository := NewMockRepository(mockController)
+		mockUserRepository.
+			EXPECT().
+			GetUserById(gomock.Any(), userId).
+			Return(&UserDTO{Id: userId}, nil).
+			Times(1)
+		mockUserRepository.
+			EXPECT().
+			GetTotpSettings(gomock.Any(), userId).
+			Return(&TotpSettingsDTO{UserId: userId, Secret: sealedSecret}, nil).
+			Times(1)
+		mockUserRepository.
+			EXPECT().
+			GetLoginAttempts(gomock.Any(), userId).
+			Return(nil, errNoLoginAttempts).
+			Times(1)
+		mockUserRepository.
+			EXPECT().
+			RecordFailedLogin(gomock.Any(), userId, gomock.Any()).
+			Return(1, nil).
+			Times(1)
+
+		code, err := authentication.GenerateTotpCode(secret, time.Now().Add(-time.Hour))
+		require.NoError(t, err)
+
+		s := NewService(cfg, mockUserRepository, nil)
+		_, err = s.VerifyTotp(ctx, code)
+
+		assert.Equal(t, connect.CodeUnauthenticated, connect.CodeOf(err))
+	})
+
+	t.Run("reused code is rejected", func(t *testing.T) {
+		mockUserRepository := NewMockRepository(mockController)
+		mockUserRepository.
+			EXPECT().
+			GetUserById(gomock.Any(), userId).
+			Return(&UserDTO{Id: userId}, nil).
+			Times(1)
+		mockUserRepository.
+			EXPECT().
+			GetTotpSettings(gomock.Any(), userId).
+			Return(&TotpSettingsDTO{UserId: userId, Secret: sealedSecret}, nil).
+			Times(1)
+		mockUserRepository.
+			EXPECT().
+			GetLoginAttempts(gomock.Any(), userId).
+			Return(nil, errNoLoginAttempts).
+			Times(1)
+		mockUserRepository.
+			EXPECT().
+			UseTotpStep(gomock.Any(), userId, gomock.Any()).
+			Return(false, nil).
+			Times(1)
+		mockUserRepository.
+			EXPECT().
+			RecordFailedLogin(gomock.Any(), userId, gomock.Any()).
+			Return(1, nil).
+			Times(1)
+
+		code, err := authentication.GenerateTotpCode(secret, time.Now())
+		require.NoError(t, err)
+
+		s := NewService(cfg, mockUserRepository, nil)
+		_, err = s.VerifyTotp(ctx, code)
+
+		assert.Equal(t, connect.CodeUnauthenticated, connect.CodeOf(err))
+	})
+
+	t.Run("locked account is rejected before the code is checked", func(t *testing.T) {
+		lockedUntil := time.Now().UTC().Add(time.Minute)
+		mockUserRepository := NewMockRepository(mockController)
+		mockUserRepository.
+			EXPECT().
+			GetUserById(gomock.Any(), userId).
+			Return(&UserDTO{Id: userId}, nil).
+			Times(1)
+		mockUserRepository.
+			EXPECT().
+			GetTotpSettings(gomock.Any(), userId).
+			Return(&TotpSettingsDTO{UserId: userId, Secret: sealedSecret}, nil).
+			Times(1)
+		mockUserRepository.
+			EXPECT().
+			GetLoginAttempts(gomock.Any(), userId).
+			Return(&LoginAttemptsDTO{UserId: userId, FailedCount: 5, LockedUntil: &lockedUntil}, nil).
+			Times(1)
+
+		code, err := authentication.GenerateTotpCode(secret, time.Now())
+		require.NoError(t, err)
+
+		s := NewService(cfg, mockUserRepository, nil)
+		_, err = s.VerifyTotp(ctx, code)
+
+		assert.Equal(t, connect.CodeResourceExhausted, connect.CodeOf(err))
+	})
+
+	t.Run("not enrolled", func(t *testing.T) {
+		mockUserRepository := NewMockRepository(mockController)
+		mockUserRepository.
+			EXPECT().
+			GetUserById(gomock.Any(), userId).
+			Return(&UserDTO{Id: userId}, nil).
+			Times(1)
+		mockUserRepository.
+			EXPECT().
+			GetTotpSettings(gomock.Any(), userId).
+			Return(nil, connect.NewError(connect.CodeNotFound, errors.New("totp is not enrolled"))).
+			Times(1)
+
+		s := NewService(cfg, mockUserRepository, nil)
+		_, err := s.VerifyTotp(ctx, "123456")
+
+		assert.Equal(t, connect.CodeFailedPrecondition, connect.CodeOf(err))
+	})
+}
+
+func TestService_LoginLockout(t *testing.T) {
+	mockController := gomock.NewController(t)
+	defer mockController.Finish()
+
+	cfg := &config.Config{
+		JwtSecret:        []byte("jwt-secret"),
+		PasswordHashCost: bcrypt.MinCost,
+		LoginLockout:     config.LoginLockoutConfig{MaxFailedAttempts: 3, BaseDuration: "1m", MaxDuration: "1h"},
+	}
+
+	userId := uuid.NewString()
+	hashedPwd, err := bcrypt.GenerateFromPassword([]byte("Asdfg12345_"), bcrypt.MinCost)
+	require.NoError(t, err)
+	user := &UserDTO{Id: userId, Email: "test@mail.com", Password: string(hashedPwd)}
+
+	t.Run("reaching the threshold locks the account", func(t *testing.T) {
+		failedCount := 0
+		var lockedUntil time.Time
+
+		mockUserRepository := NewMockRepository(mockController)
+		mockUserRepository.
+			EXPECT().
+			GetUserByEmail(gomock.Any(), "test@mail.com").
+			Return(user, nil).
+			Times(4)
+		mockUserRepository.
+			EXPECT().
+			GetLoginAttempts(gomock.Any(), userId).
+			DoAndReturn(func(context.Context, string) (*LoginAttemptsDTO, error) {
+				if failedCount == 0 {
+					return nil, errNoLoginAttempts
+				}
+				attempts := &LoginAttemptsDTO{UserId: userId, FailedCount: failedCount}
+				if !lockedUntil.IsZero() {
+					attempts.LockedUntil = &lockedUntil
+				}
+				return attempts, nil
+			}).
+			Times(4)
+		mockUserRepository.
+			EXPECT().
+			RecordFailedLogin(gomock.Any(), userId, gomock.Any()).
+			DoAndReturn(func(context.Context, string, time.Time) (int, error) {
+				failedCount++
+				return failedCount, nil
+			}).
+			Times(3)
+		mockUserRepository.
+			EXPECT().
+			LockAccount(gomock.Any(), userId, gomock.Any()).
+			DoAndReturn(func(_ context.Context, _ string, until time.Time) error {
+				lockedUntil = until
+				return nil
+			}).
+			Times(1)
+
+		s := NewService(cfg, mockUserRepository, nil)
+		for range 3 {
+			_, err := s.Login(t.Context(), &userv1.LoginRequest{Email: "test@mail.com", Password: "wrong-password"})
+			assert.Equal(t, connect.CodeUnauthenticated, connect.CodeOf(err))
+		}
+		assert.WithinDuration(t, time.Now().Add(time.Minute), lockedUntil, 5*time.Second)
+
+		_, err := s.Login(t.Context(), &userv1.LoginRequest{Email: "test@mail.com", Password: "Asdfg12345_"})
+		require.Equal(t, connect.CodeResourceExhausted, connect.CodeOf(err))
+
+		var connectErr *connect.Error
+		require.ErrorAs(t, err, &connectErr)
+		assert.NotEmpty(t, connectErr.Meta().Get("Retry-After"))
+	})
+
+	t.Run("successful login clears the counter", func(t *testing.T) {
+		mockUserRepository := NewMockRepository(mockController)
+		mockUserRepository.
+			EXPECT().
+			GetUserByEmail(gomock.Any(), "test@mail.com").
+			Return(user, nil).
+			Times(1)
+		mockUserRepository.
+			EXPECT().
+			GetLoginAttempts(gomock.Any(), userId).
+			Return(&LoginAttemptsDTO{UserId: userId, FailedCount: 2}, nil).
+			Times(1)
+		mockUserRepository.
+			EXPECT().
+			ResetLoginAttempts(gomock.Any(), userId).
+			Return(nil).
+			Times(1)
+		mockUserRepository.
+			EXPECT().
+			CreateRefreshToken(gomock.Any(), userId, gomock.Any(), gomock.Any()).
+			Return(nil).
+			Times(1)
+
+		s := NewService(cfg, mockUserRepository, nil)
+		tokens, err := s.Login(t.Context(), &userv1.LoginRequest{Email: "test@mail.com", Password: "Asdfg12345_"})
+
+		require.NoError(t, err)
+		assert.NotEmpty(t, tokens.AccessToken)
+	})
+
+	t.Run("expired lock lets the login through", func(t *testing.T) {
+		expired := time.Now().UTC().Add(-time.Second)
+		mockUserRepository := NewMockRepository(mockController)
+		mockUserRepository.
+			EXPECT().
+			GetUserByEmail(gomock.Any(), "test@mail.com").
+			Return(user, nil).
+			Times(1)
+		mockUserRepository.
+			EXPECT().
+			GetLoginAttempts(gomock.Any(), userId).
+			Return(&LoginAttemptsDTO{UserId: userId, FailedCount: 3, LockedUntil: &expired}, nil).
+			Times(1)
+		mockUserRepository.
+			EXPECT().
+			ResetLoginAttempts(gomock.Any(), userId).
+			Return(nil).
+			Times(1)
+		mockUserRepository.
+			EXPECT().
+			CreateRefreshToken(gomock.Any(), userId, gomock.Any(), gomock.Any()).
+			Return(nil).
+			Times(1)
+
+		s := NewService(cfg, mockUserRepository, nil)
+		_, err := s.Login(t.Context(), &userv1.LoginRequest{Email: "test@mail.com", Password: "Asdfg12345_"})
+
+		require.NoError(t, err)
+	})
+}
+
+func TestLoginLockout_Duration(t *testing.T) {
+	lockout := loginLockout{maxFailedAttempts: 3, baseDuration: time.Minute, maxDuration: 5 * time.Minute}
+
+	assert.Zero(t, lockout.duration(2))
+	assert.Equal(t, time.Minute, lockout.duration(3))
+	assert.Equal(t, 2*time.Minute, lockout.duration(4))
+	assert.Equal(t, 4*time.Minute, lockout.duration(5))
+	assert.Equal(t, 5*time.Minute, lockout.duration(6))
+	assert.Equal(t, 5*time.Minute, lockout.duration(100))
+}
@@ -0,0 +1,167 @@
+package user
+
+import (
+	"context"
+	"crypto/rsa"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/gliderlabs/ssh"
+	"go.uber.org/zap"
+	gossh "golang.org/x/crypto/ssh"
+)
+
+const (
+	SshUserIdContextKey         = "userId"
+	SshKeyFingerprintContextKey = "sshKeyFingerprint"
+)
+
+// sshPublicKeyContextKey holds the authorized_keys form of the key a
+// connection authenticated with, until the session handler records its use.
+const sshPublicKeyContextKey = "sshPublicKey"
+
+// SshKeyPolicy restricts which public keys may authenticate. An empty
+// AllowedTypes accepts every algorithm and a zero MinRsaBits any RSA size.
+type SshKeyPolicy struct {
+	AllowedTypes []string
+	MinRsaBits   int
+}
+
+// Check returns why the key is not acceptable, or nil.
+func (p SshKeyPolicy) Check(key gossh.PublicKey) error {
+	if len(p.AllowedTypes) > 0 && !slices.Contains(p.AllowedTypes, key.Type()) {
+		return fmt.Errorf("key type %s is not allowed", key.Type())
+	}
+
+	if p.MinRsaBits > 0 && key.Type() == gossh.KeyAlgoRSA {
+		cryptoKey, ok := key.(gossh.CryptoPublicKey)
+		if !ok {
+			return fmt.Errorf("cannot determine size of %s key", key.Type())
+		}
+		rsaKey, ok := cryptoKey.CryptoPublicKey().(*rsa.PublicKey)
+		if !ok {
+			return fmt.Errorf("cannot determine size of %s key", key.Type())
+		}
+		if bits := rsaKey.N.BitLen(); bits < p.MinRsaBits {
+			return fmt.Errorf("RSA key is %d bits, at least %d are required", bits, p.MinRsaBits)
+		}
+	}
+
+	return nil
+}
+
+type SshAuthenticator struct {
+	userRepository  Repository
+	allowApiKeyAuth bool
+	keyPolicy       SshKeyPolicy
+}
+
+func NewSshAuthenticator(userRepository Repository, allowApiKeyAuth bool, keyPolicy SshKeyPolicy) *SshAuthenticator {
+	return &SshAuthenticator{
+		userRepository:  userRepository,
+		allowApiKeyAuth: allowApiKeyAuth,
+		keyPolicy:       keyPolicy,
+	}
+}
+
+// Apply installs the public key handler and, when API key auth is enabled,
+// password and keyboard-interactive handlers that treat the secret as an API key.
+// It also wraps the server's session handler to record key usage, so it must
+// run after the handler is set.
+func (a *SshAuthenticator) Apply(server *ssh.Server) {
+	server.PublicKeyHandler = a.PublicKeyHandler
+	if a.allowApiKeyAuth {
+		server.PasswordHandler = a.PasswordHandler
+		server.KeyboardInteractiveHandler = a.KeyboardInteractiveHandler
+	}
+
+	if next := server.Handler; next != nil {
+		server.Handler = func(session ssh.Session) {
+			a.markKeyUsed(session.Context())
+			next(session)
+		}
+	}
+}
+
+func (a *SshAuthenticator) PublicKeyHandler(ctx ssh.Context, key ssh.PublicKey) bool {
+	// Weak keys are turned away before the lookup, so they cannot be used to
+	// probe which keys are registered.
+	if err := a.keyPolicy.Check(key); err != nil {
+		zap.L().Info("SSH public key rejected",
+			zap.String("fingerprint", gossh.FingerprintSHA256(key)),
+			zap.String("reason", err.Error()),
+		)
+		return false
+	}
+
+	publicKeyStr := strings.TrimSpace(string(gossh.MarshalAuthorizedKey(key)))
+	userDTO, err := a.userRepository.GetUserBySshPublicKey(context.Background(), publicKeyStr)
+	if err != nil {
+		zap.L().Debug("SSH auth failed", zap.Error(err))
+		return false
+	}
+
+	// This also runs for clients that only ask whether a key would be
+	// accepted, before they prove they hold it, so usage is recorded once the
+	// session starts rather than here.
+	fingerprint := gossh.FingerprintSHA256(key)
+	ctx.SetValue(SshUserIdContextKey, userDTO.Id)
+	ctx.SetValue(SshKeyFingerprintContextKey, fingerprint)
+	ctx.SetValue(sshPublicKeyContextKey, publicKeyStr)
+
+	zap.L().Info("SSH auth success", zap.String("userId", userDTO.Id), zap.String("fingerprint", fingerprint))
+	return true
+}
+
+// markKeyUsed records the use of the key an authenticated session signed in
+// with. It is best-effort and never fails the session.
+func (a *SshAuthenticator) markKeyUsed(ctx ssh.Context) {
+	publicKeyStr, _ := ctx.Value(sshPublicKeyContextKey).(string)
+	if publicKeyStr == "" {
+		return
+	}
+
+	if err := a.userRepository.MarkSshKeyUsed(context.Background(), publicKeyStr, time.Now().UTC()); err != nil {
+		userId, _ := ctx.Value(SshUserIdContextKey).(string)
+		fingerprint, _ := ctx.Value(SshKeyFingerprintContextKey).(string)
+		zap.L().Warn("failed to update SSH key last used time",
+			zap.String("userId", userId),
+			zap.String("fingerprint", fingerprint),
+			zap.Error(err),
+		)
+	}
+}
+
+func (a *SshAuthenticator) PasswordHandler(ctx ssh.Context, password string) bool {
+	return a.authenticateApiKey(ctx, password, "password")
+}
+
+func (a *SshAuthenticator) KeyboardInteractiveHandler(ctx ssh.Context, challenger gossh.KeyboardInteractiveChallenge) bool {
+	answers, err := challenger("", "", []string{"API key: "}, []bool{false})
+	if err != nil || len(answers) != 1 {
+		return false
+	}
+
+	return a.authenticateApiKey(ctx, answers[0], "keyboard-interactive")
+}
+
+func (a *SshAuthenticator) authenticateApiKey(ctx ssh.Context, apiKey, method string) bool {
+	if !a.allowApiKeyAuth || apiKey == "" {
+		return false
+	}
+
+	userDTO, err := a.userRepository.GetUserByApiKey(context.Background(), apiKey)
+	if err != nil {
+		zap.L().Debug("SSH API key auth failed", zap.String("method", method), zap.Error(err))
+		return false
+	}
+
+	// A key offered earlier on this connection did not authenticate it.
+	ctx.SetValue(SshUserIdContextKey, userDTO.Id)
+	ctx.SetValue(SshKeyFingerprintContextKey, "")
+	ctx.SetValue(sshPublicKeyContextKey, "")
+	zap.L().Info("SSH auth success", zap.String("userId", userDTO.Id), zap.String("method", method))
+	return true
+}
@@ -0,0 +1,307 @@
+package user
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/rsa"
+	"errors"
+	"io"
+	"net"
+	"strings"
+	"testing"
+	"time"
+
+	"connectrpc.com/connect"
+	"github.com/gliderlabs/ssh"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+	gossh "golang.org/x/crypto/ssh"
+)
+
+func startTestSshServer(t *testing.T, authenticator *SshAuthenticator) string {
+	t.Helper()
+
+	server := &ssh.Server{
+		Handler: func(session ssh.Session) {
+			userId, _ := session.Context().Value(SshUserIdContextKey).(string)
+			_, _ = session.Write([]byte(userId))
+		},
+	}
+	authenticator.Apply(server)
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+
+	go func() {
+		_ = server.Serve(listener)
+	}()
+	t.Cleanup(func() {
+		_ = server.Close()
+	})
+
+	return listener.Addr().String()
+}
+
+func runTestSshSession(addr string, auth gossh.AuthMethod) (string, error) {
+	client, err := gossh.Dial("tcp", addr, &gossh.ClientConfig{
+		User:            "git",
+		Auth:            []gossh.AuthMethod{auth},
+		HostKeyCallback: gossh.InsecureIgnoreHostKey(), // #nosec G106 -- test server
+		Timeout:         2 * time.Second,
+	})
+	if err != nil {
+		return "", err
+	}
+	defer func() {
+		_ = client.Close()
+	}()
+
+	session, err := client.NewSession()
+	if err != nil {
+		return "", err
+	}
+	defer func() {
+		_ = session.Close()
+	}()
+
+	output, err := session.Output("")
+	return string(output), err
+}
+
+// unprovenSigner offers a public key but cannot sign with it, like a client
+// that only knows someone else's public key.
+type unprovenSigner struct {
+	gossh.Signer
+}
+
+func (unprovenSigner) Sign(io.Reader, []byte) (*gossh.Signature, error) {
+	return nil, errors.New("private key not available")
+}
+
+func keyboardInteractiveAnswer(answer string) gossh.AuthMethod {
+	return gossh.KeyboardInteractive(func(_, _ string, questions []string, _ []bool) ([]string, error) {
+		answers := make([]string, len(questions))
+		for i := range questions {
+			answers[i] = answer
+		}
+		return answers, nil
+	})
+}
+
+func TestSshAuthenticator(t *testing.T) {
+	errInvalidApiKey := connect.NewError(connect.CodeNotFound, errors.New("api key not found"))
+
+	t.Run("password auth with valid api key", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		mockUserRepository := NewMockRepository(ctrl)
+		mockUserRepository.EXPECT().
+			GetUserByApiKey(gomock.Any(), "valid-api-key").
+			Return(&UserDTO{Id: "user-123"}, nil)
+
+		addr := startTestSshServer(t, NewSshAuthenticator(mockUserRepository, true, SshKeyPolicy{}))
+
+		output, err := runTestSshSession(addr, gossh.Password("valid-api-key"))
+		require.NoError(t, err)
+		assert.Equal(t, "user-123", output)
+	})
+
+	t.Run("keyboard-interactive auth with valid api key", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		mockUserRepository := NewMockRepository(ctrl)
+		mockUserRepository.EXPECT().
+			GetUserByApiKey(gomock.Any(), "valid-api-key").
+			Return(&UserDTO{Id: "user-123"}, nil)
+
+		addr := startTestSshServer(t, NewSshAuthenticator(mockUserRepository, true, SshKeyPolicy{}))
+
+		output, err := runTestSshSession(addr, keyboardInteractiveAnswer("valid-api-key"))
+		require.NoError(t, err)
+		assert.Equal(t, "user-123", output)
+	})
+
+	t.Run("invalid api key is rejected", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		mockUserRepository := NewMockRepository(ctrl)
+		mockUserRepository.EXPECT().
+			GetUserByApiKey(gomock.Any(), "invalid-api-key").
+			Return(nil, errInvalidApiKey).
+			AnyTimes()
+
+		addr := startTestSshServer(t, NewSshAuthenticator(mockUserRepository, true, SshKeyPolicy{}))
+
+		_, err := runTestSshSession(addr, gossh.Password("invalid-api-key"))
+		require.Error(t, err)
+	})
+
+	t.Run("api key auth disabled by default", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		mockUserRepository := NewMockRepository(ctrl)
+
+		addr := startTestSshServer(t, NewSshAuthenticator(mockUserRepository, false, SshKeyPolicy{}))
+
+		_, err := runTestSshSession(addr, gossh.Password("valid-api-key"))
+		require.Error(t, err)
+	})
+
+	t.Run("public key auth records key usage", func(t *testing.T) {
+		signer, publicKey := generateTestSigner(t)
+
+		ctrl := gomock.NewController(t)
+		mockUserRepository := NewMockRepository(ctrl)
+		mockUserRepository.EXPECT().
+			GetUserBySshPublicKey(gomock.Any(), publicKey).
+			Return(&UserDTO{Id: "user-456"}, nil).
+			MinTimes(1)
+		mockUserRepository.EXPECT().
+			MarkSshKeyUsed(gomock.Any(), publicKey, gomock.Any()).
+			Return(nil).
+			MinTimes(1)
+
+		addr := startTestSshServer(t, NewSshAuthenticator(mockUserRepository, false, SshKeyPolicy{}))
+
+		output, err := runTestSshSession(addr, gossh.PublicKeys(signer))
+		require.NoError(t, err)
+		assert.Equal(t, "user-456", output)
+	})
+
+	t.Run("public key auth succeeds when usage update fails", func(t *testing.T) {
+		signer, publicKey := generateTestSigner(t)
+
+		ctrl := gomock.NewController(t)
+		mockUserRepository := NewMockRepository(ctrl)
+		mockUserRepository.EXPECT().
+			GetUserBySshPublicKey(gomock.Any(), publicKey).
+			Return(&UserDTO{Id: "user-456"}, nil).
+			MinTimes(1)
+		mockUserRepository.EXPECT().
+			MarkSshKeyUsed(gomock.Any(), publicKey, gomock.Any()).
+			Return(ErrInternalServer).
+			MinTimes(1)
+
+		addr := startTestSshServer(t, NewSshAuthenticator(mockUserRepository, false, SshKeyPolicy{}))
+
+		output, err := runTestSshSession(addr, gossh.PublicKeys(signer))
+		require.NoError(t, err)
+		assert.Equal(t, "user-456", output)
+	})
+
+	t.Run("key that is never proven is not recorded as used", func(t *testing.T) {
+		signer, publicKey := generateTestSigner(t)
+
+		ctrl := gomock.NewController(t)
+		mockUserRepository := NewMockRepository(ctrl)
+		mockUserRepository.EXPECT().
+			GetUserBySshPublicKey(gomock.Any(), publicKey).
+			Return(&UserDTO{Id: "user-456"}, nil).
+			AnyTimes()
+		mockUserRepository.EXPECT().
+			MarkSshKeyUsed(gomock.Any(), gomock.Any(), gomock.Any()).
+			Times(0)
+
+		addr := startTestSshServer(t, NewSshAuthenticator(mockUserRepository, false, SshKeyPolicy{}))
+
+		_, err := runTestSshSession(addr, gossh.PublicKeys(unprovenSigner{signer}))
+		require.Error(t, err)
+	})
+
+	t.Run("public key auth stores key fingerprint in context", func(t *testing.T) {
+		signer, publicKey := generateTestSigner(t)
+
+		ctrl := gomock.NewController(t)
+		mockUserRepository := NewMockRepository(ctrl)
+		mockUserRepository.EXPECT().
+			GetUserBySshPublicKey(gomock.Any(), publicKey).
+			Return(&UserDTO{Id: "user-456"}, nil).
+			MinTimes(1)
+		mockUserRepository.EXPECT().
+			MarkSshKeyUsed(gomock.Any(), publicKey, gomock.Any()).
+			Return(nil).
+			MinTimes(1)
+
+		server := &ssh.Server{
+			Handler: func(session ssh.Session) {
+				fingerprint, _ := session.Context().Value(SshKeyFingerprintContextKey).(string)
+				_, _ = session.Write([]byte(fingerprint))
+			},
+		}
+		NewSshAuthenticator(mockUserRepository, false, SshKeyPolicy{}).Apply(server)
+
+		listener, err := net.Listen("tcp", "127.0.0.1:0")
+		require.NoError(t, err)
+		go func() {
+			_ = server.Serve(listener)
+		}()
+		t.Cleanup(func() {
+			_ = server.Close()
+		})
+
+		output, err := runTestSshSession(listener.Addr().String(), gossh.PublicKeys(signer))
+		require.NoError(t, err)
+		assert.Equal(t, gossh.FingerprintSHA256(signer.PublicKey()), output)
+	})
+}
+
+func TestSshAuthenticator_KeyPolicy(t *testing.T) {
+	policy := SshKeyPolicy{
+		AllowedTypes: []string{gossh.KeyAlgoED25519, gossh.KeyAlgoRSA},
+		MinRsaBits:   2048,
+	}
+
+	t.Run("ed25519 key is accepted", func(t *testing.T) {
+		signer, publicKey := generateTestSigner(t)
+
+		ctrl := gomock.NewController(t)
+		mockUserRepository := NewMockRepository(ctrl)
+		mockUserRepository.EXPECT().
+			GetUserBySshPublicKey(gomock.Any(), publicKey).
+			Return(&UserDTO{Id: "user-456"}, nil).
+			MinTimes(1)
+		mockUserRepository.EXPECT().
+			MarkSshKeyUsed(gomock.Any(), publicKey, gomock.Any()).
+			Return(nil).
+			MinTimes(1)
+
+		addr := startTestSshServer(t, NewSshAuthenticator(mockUserRepository, false, policy))
+
+		output, err := runTestSshSession(addr, gossh.PublicKeys(signer))
+		require.NoError(t, err)
+		assert.Equal(t, "user-456", output)
+	})
+
+	t.Run("undersized rsa key is rejected before lookup", func(t *testing.T) {
+		// #nosec G403 -- deliberately weak key to exercise the size check
+		privateKey, err := rsa.GenerateKey(rand.Reader, 1024)
+		require.NoError(t, err)
+		signer, err := gossh.NewSignerFromKey(privateKey)
+		require.NoError(t, err)
+
+		ctrl := gomock.NewController(t)
+		mockUserRepository := NewMockRepository(ctrl)
+
+		addr := startTestSshServer(t, NewSshAuthenticator(mockUserRepository, false, policy))
+
+		_, err = runTestSshSession(addr, gossh.PublicKeys(signer))
+		require.Error(t, err)
+	})
+
+	t.Run("disallowed key type", func(t *testing.T) {
+		_, publicKey := generateTestSigner(t)
+		key, _, _, _, err := gossh.ParseAuthorizedKey([]byte(publicKey))
+		require.NoError(t, err)
+
+		err = SshKeyPolicy{AllowedTypes: []string{gossh.KeyAlgoECDSA256}}.Check(key)
+		assert.EqualError(t, err, "key type ssh-ed25519 is not allowed")
+	})
+}
+
+func generateTestSigner(t *testing.T) (gossh.Signer, string) {
+	t.Helper()
+
+	_, privateKey, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+	signer, err := gossh.NewSignerFromKey(privateKey)
+	require.NoError(t, err)
+
+	return signer, strings.TrimSpace(string(gossh.MarshalAuthorizedKey(signer.PublicKey())))
+}
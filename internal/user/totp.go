@@ -0,0 +1,90 @@
+package user
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"hasir-api/pkg/authentication"
+	"hasir-api/pkg/httpjson"
+)
+
+// maxTotpRequestSize bounds the verify body, which only carries a short code.
+const maxTotpRequestSize = 1 << 10
+
+type totpHandler struct {
+	service       Service
+	authenticator *authentication.AuthInterceptor
+}
+
+// NewTotpHandler serves enrollment and verification of the second factor.
+// UserService has no RPCs for them, so they are plain JSON endpoints that take
+// the same bearer token.
+func NewTotpHandler(service Service, authenticator *authentication.AuthInterceptor) *totpHandler {
+	return &totpHandler{
+		service:       service,
+		authenticator: authenticator,
+	}
+}
+
+func (h *totpHandler) RegisterRoutes() (string, http.Handler) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("POST /totp/enroll", h.requireUser(h.EnrollTotp))
+	mux.HandleFunc("POST /totp/verify", h.requireUser(h.VerifyTotp))
+
+	return "/totp/", mux
+}
+
+func (h *totpHandler) requireUser(next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		ctx, err := h.authenticator.Authenticate(r.Context(), r.Header.Get("Authorization"))
+		if err != nil {
+			w.Header().Set("WWW-Authenticate", `Bearer realm="Hasir"`)
+			http.Error(w, "Authentication required", http.StatusUnauthorized)
+			return
+		}
+
+		next(w, r.WithContext(ctx))
+	}
+}
+
+// EnrollTotp responds with the new secret and its otpauth URI. The secret is
+// shown only here; the second factor turns on once VerifyTotp accepts a code.
+func (h *totpHandler) EnrollTotp(w http.ResponseWriter, r *http.Request) {
+	enrollment, err := h.service.EnrollTotp(r.Context())
+	if err != nil {
+		httpjson.WriteError(w, "failed to enroll totp", err)
+		return
+	}
+
+	httpjson.Write(w, "totp enrollment", enrollment)
+}
+
+type verifyTotpRequest struct {
+	Code string `json:"code"`
+}
+
+type tokenResponse struct {
+	AccessToken  string `json:"accessToken"`
+	RefreshToken string `json:"refreshToken"`
+}
+
+// VerifyTotp takes {"code": "123456"} and responds with tokens whose access
+// token passes the TOTP gate on sensitive procedures.
+func (h *totpHandler) VerifyTotp(w http.ResponseWriter, r *http.Request) {
+	var req verifyTotpRequest
+	if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, maxTotpRequestSize)).Decode(&req); err != nil || req.Code == "" {
+		http.Error(w, "Request body must be a JSON object with a code", http.StatusBadRequest)
+		return
+	}
+
+	tokens, err := h.service.VerifyTotp(r.Context(), req.Code)
+	if err != nil {
+		httpjson.WriteError(w, "failed to verify totp", err)
+		return
+	}
+
+	httpjson.Write(w, "tokens", tokenResponse{
+		AccessToken:  tokens.GetAccessToken(),
+		RefreshToken: tokens.GetRefreshToken(),
+	})
+}
@@ -0,0 +1,186 @@
+package user
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"buf.build/gen/go/hasir/hasir/connectrpc/go/user/v1/userv1connect"
+	"buf.build/gen/go/hasir/hasir/protocolbuffers/go/shared"
+	"connectrpc.com/connect"
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+
+	"hasir-api/internal"
+	"hasir-api/pkg/authentication"
+	"hasir-api/pkg/config"
+)
+
+func TestTotpHandler(t *testing.T) {
+	cfg := &config.Config{JwtSecret: []byte("jwt-secret")}
+	userId := uuid.NewString()
+
+	secret, err := authentication.GenerateTotpSecret()
+	require.NoError(t, err)
+	sealedSecret, err := authentication.SealTotpSecret(cfg.JwtSecret, secret)
+	require.NoError(t, err)
+
+	newServer := func(t *testing.T, repository Repository) *httptest.Server {
+		t.Helper()
+
+		svc := NewService(cfg, repository, nil)
+		authInterceptor := authentication.NewAuthInterceptor(cfg.JwtSecret)
+		totpInterceptor := authentication.NewTotpInterceptor(svc, []string{userv1connect.UserServiceGetSshKeysProcedure})
+
+		mux := http.NewServeMux()
+		for _, h := range []internal.GlobalHandler{
+			NewHandler(svc, repository, authInterceptor, totpInterceptor),
+			NewTotpHandler(svc, authInterceptor),
+		} {
+			path, handler := h.RegisterRoutes()
+			mux.Handle(path, handler)
+		}
+
+		server := httptest.NewServer(mux)
+		t.Cleanup(server.Close)
+
+		return server
+	}
+
+	accessToken := func(t *testing.T) string {
+		t.Helper()
+
+		claims := &authentication.JwtClaims{
+			RegisteredClaims: jwt.RegisteredClaims{
+				Subject:   userId,
+				ExpiresAt: jwt.NewNumericDate(time.Now().Add(time.Hour)),
+			},
+		}
+		signed, err := jwt.NewWithClaims(jwt.SigningMethodHS256, claims).SignedString(cfg.JwtSecret)
+		require.NoError(t, err)
+
+		return signed
+	}
+
+	getSshKeys := func(t *testing.T, server *httptest.Server, token string) error {
+		t.Helper()
+
+		client := userv1connect.NewUserServiceClient(http.DefaultClient, server.URL)
+		req := connect.NewRequest(&shared.Pagination{})
+		req.Header().Set("Authorization", "Bearer "+token)
+		_, err := client.GetSshKeys(context.Background(), req)
+
+		return err
+	}
+
+	t.Run("enrolled user passes the gate only after verifying", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		mockRepository := NewMockRepository(ctrl)
+		enabledAt := time.Now().Add(-24 * time.Hour)
+
+		mockRepository.EXPECT().
+			GetTotpSettings(gomock.Any(), userId).
+			Return(&TotpSettingsDTO{UserId: userId, Secret: sealedSecret, EnabledAt: &enabledAt}, nil).
+			Times(2)
+		mockRepository.EXPECT().
+			GetUserById(gomock.Any(), userId).
+			Return(&UserDTO{Id: userId, Email: "test@mail.com"}, nil)
+		mockRepository.EXPECT().
+			GetLoginAttempts(gomock.Any(), userId).
+			Return(nil, errNoLoginAttempts)
+		mockRepository.EXPECT().
+			UseTotpStep(gomock.Any(), userId, gomock.Any()).
+			Return(true, nil)
+		mockRepository.EXPECT().
+			CreateRefreshToken(gomock.Any(), userId, gomock.Any(), gomock.Any()).
+			Return(nil)
+		mockRepository.EXPECT().
+			GetSshKeysCount(gomock.Any(), userId).
+			Return(0, nil)
+		mockRepository.EXPECT().
+			GetSshKeys(gomock.Any(), userId, 1, 10).
+			Return(&[]SshKeyDTO{}, nil)
+
+		server := newServer(t, mockRepository)
+		token := accessToken(t)
+
+		err := getSshKeys(t, server, token)
+		require.Error(t, err)
+		assert.Equal(t, connect.CodePermissionDenied, connect.CodeOf(err))
+
+		code, err := authentication.GenerateTotpCode(secret, time.Now())
+		require.NoError(t, err)
+
+		req, err := http.NewRequest(http.MethodPost, server.URL+"/totp/verify", strings.NewReader(`{"code":"`+code+`"}`))
+		require.NoError(t, err)
+		req.Header.Set("Authorization", "Bearer "+token)
+		resp, err := http.DefaultClient.Do(req)
+		require.NoError(t, err)
+		defer resp.Body.Close()
+		require.Equal(t, http.StatusOK, resp.StatusCode)
+
+		var tokens tokenResponse
+		require.NoError(t, json.NewDecoder(resp.Body).Decode(&tokens))
+		require.NotEmpty(t, tokens.AccessToken)
+
+		require.NoError(t, getSshKeys(t, server, tokens.AccessToken))
+	})
+
+	t.Run("enroll returns the secret", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		mockRepository := NewMockRepository(ctrl)
+
+		mockRepository.EXPECT().
+			GetUserById(gomock.Any(), userId).
+			Return(&UserDTO{Id: userId, Email: "test@mail.com"}, nil)
+		mockRepository.EXPECT().
+			GetTotpSettings(gomock.Any(), userId).
+			Return(nil, connect.NewError(connect.CodeNotFound, nil))
+		mockRepository.EXPECT().
+			SetTotpSecret(gomock.Any(), userId, gomock.Any()).
+			Return(nil)
+
+		server := newServer(t, mockRepository)
+
+		req, err := http.NewRequest(http.MethodPost, server.URL+"/totp/enroll", nil)
+		require.NoError(t, err)
+		req.Header.Set("Authorization", "Bearer "+accessToken(t))
+		resp, err := http.DefaultClient.Do(req)
+		require.NoError(t, err)
+		defer resp.Body.Close()
+		require.Equal(t, http.StatusOK, resp.StatusCode)
+
+		var enrollment TotpEnrollment
+		require.NoError(t, json.NewDecoder(resp.Body).Decode(&enrollment))
+		assert.NotEmpty(t, enrollment.Secret)
+		assert.Contains(t, enrollment.Uri, "otpauth://totp/")
+	})
+
+	t.Run("requires a bearer token", func(t *testing.T) {
+		server := newServer(t, NewMockRepository(gomock.NewController(t)))
+
+		resp, err := http.Post(server.URL+"/totp/enroll", "application/json", nil)
+		require.NoError(t, err)
+		defer resp.Body.Close()
+		assert.Equal(t, http.StatusUnauthorized, resp.StatusCode)
+	})
+
+	t.Run("rejects a body without a code", func(t *testing.T) {
+		server := newServer(t, NewMockRepository(gomock.NewController(t)))
+
+		req, err := http.NewRequest(http.MethodPost, server.URL+"/totp/verify", strings.NewReader(`{}`))
+		require.NoError(t, err)
+		req.Header.Set("Authorization", "Bearer "+accessToken(t))
+		resp, err := http.DefaultClient.Do(req)
+		require.NoError(t, err)
+		defer resp.Body.Close()
+		assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+	})
+}
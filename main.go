@@ -8,9 +8,11 @@ import (
 	"encoding/pem"
 	"errors"
 	"fmt"
+	"net"
 	"net/http"
 	"os"
 	"os/signal"
+	"slices"
 	"strings"
 	"syscall"
 	"time"
@@ -23,16 +25,12 @@ import (
 	_ "github.com/golang-migrate/migrate/v4/database/postgres"
 	_ "github.com/golang-migrate/migrate/v4/source/file"
 	"github.com/rs/cors"
-	"go.opentelemetry.io/otel"
-	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracegrpc"
-	"go.opentelemetry.io/otel/propagation"
-	"go.opentelemetry.io/otel/sdk/resource"
 	sdktrace "go.opentelemetry.io/otel/sdk/trace"
-	semconv "go.opentelemetry.io/otel/semconv/v1.37.0"
 	"go.uber.org/zap"
 	gossh "golang.org/x/crypto/ssh"
 
 	"hasir-api/internal"
+	"hasir-api/internal/admin"
 	internalOrganization "hasir-api/internal/organization"
 	"hasir-api/internal/registry"
 	"hasir-api/internal/user"
@@ -40,39 +38,69 @@ import (
 	"hasir-api/pkg/authorization"
 	"hasir-api/pkg/config"
 	"hasir-api/pkg/email"
-	_ "hasir-api/pkg/log"
+	"hasir-api/pkg/health"
+	"hasir-api/pkg/log"
+	"hasir-api/pkg/middleware"
+	"hasir-api/pkg/postgres"
+	postgresAdmin "hasir-api/pkg/postgres/admin"
 	postgresOrganization "hasir-api/pkg/postgres/organization"
 	postgresRegistry "hasir-api/pkg/postgres/registry"
 	postgresUser "hasir-api/pkg/postgres/user"
+	"hasir-api/pkg/sdkgenerator"
+	"hasir-api/pkg/sshserver"
 )
 
 func main() {
+	// Push lint hooks re-run this binary from inside the bare repository;
+	// that invocation needs neither config nor a database.
+	if slices.Equal(os.Args[1:], registry.PreReceiveHookCommand) {
+		linter := registry.NewBufLinter(sdkgenerator.NewDefaultCommandRunner())
+		os.Exit(registry.RunPreReceiveHook(context.Background(), linter, ".", os.Stdin, os.Stderr))
+	}
+
 	cfgReader := config.NewConfigReader()
 	cfg := cfgReader.Read()
 
+	if err := cfg.Validate(); err != nil {
+		zap.L().Fatal("invalid configuration:\n" + err.Error())
+	}
+
+	if err := log.Configure(cfg.Log); err != nil {
+		zap.L().Fatal("failed to configure logger", zap.Error(err))
+	}
+
+	if err := cfg.CheckStoragePaths(); err != nil {
+		zap.L().Fatal("storage paths are not usable", zap.Error(err))
+	}
+
 	zap.L().Info("Server starting...")
 
-	m, err := migrate.New(
-		"file://migrations",
-		fmt.Sprintf(
-			"postgres://%s:%s@%s:%s/%s?sslmode=disable",
-			cfg.PostgresConfig.Username,
-			cfg.PostgresConfig.Password,
-			cfg.PostgresConfig.Host,
-			cfg.PostgresConfig.Port,
-			cfg.PostgresConfig.Database,
-		),
+	migrationUrl := fmt.Sprintf(
+		"postgres://%s:%s@%s:%s/%s?sslmode=disable",
+		cfg.PostgresConfig.Username,
+		cfg.PostgresConfig.Password,
+		cfg.PostgresConfig.Host,
+		cfg.PostgresConfig.Port,
+		cfg.PostgresConfig.Database,
 	)
+	if schema := cfg.PostgresConfig.Schema; schema != "" {
+		if err := postgres.ValidateSchema(schema); err != nil {
+			zap.L().Fatal("invalid database schema", zap.Error(err))
+		}
+		migrationUrl += "&search_path=" + schema + ",public"
+	}
+
+	m, err := migrate.New("file://migrations", migrationUrl)
 	if err != nil {
 		zap.L().Fatal("failed to create migration client", zap.Error(err))
 	}
-	if err = m.Up(); err != nil && !errors.Is(err, migrate.ErrNoChange) {
+	if err = runMigrations(m, cfg.Migration); err != nil {
 		zap.L().Fatal("failed to apply migrations", zap.Error(err))
 	}
 
-	var traceProvider *sdktrace.TracerProvider
-	if cfg.Otel.Enabled {
-		traceProvider = initTracer(cfg)
+	traceProvider, err := setupTracing(context.Background(), cfg.Otel)
+	if err != nil {
+		zap.L().Fatal("failed to initialize tracing", zap.Error(err))
 	}
 
 	userPgRepository := postgresUser.NewPgRepository(cfg, traceProvider)
@@ -90,22 +118,28 @@ func main() {
 
 	orgRepoAdapter := authorization.NewOrgRepositoryAdapter(organizationPgRepository)
 
+	sdkLeaseTimeout, err := cfg.SdkGeneration.GetLeaseTimeout()
+	if err != nil {
+		zap.L().Fatal("invalid SDK generation lease timeout", zap.Error(err))
+	}
+
 	sdkGenerationQueue := postgresRegistry.NewSdkGenerationJobQueue(
 		repositoryPgRepository.GetConnectionPool(),
 		repositoryPgRepository.GetTracer(),
+		sdkLeaseTimeout,
 	)
 
 	registryService := registry.NewService(repositoryPgRepository, orgRepoAdapter, sdkGenerationQueue, cfg)
 
-	pollInterval, err := time.ParseDuration(cfg.SdkGeneration.PollInterval)
+	pollInterval, err := cfg.SdkGeneration.GetPollInterval()
 	if err != nil {
 		zap.L().Fatal("invalid SDK generation poll interval", zap.Error(err))
 	}
 
-	sdkGenerationQueue.Start(ctx, registryService, registryService, cfg.SdkGeneration.WorkerCount, pollInterval)
+	sdkGenerationQueue.Start(ctx, registryService, registryService, registryService, cfg.SdkGeneration.GetWorkerCount(), pollInterval)
 	zap.L().Info(
 		"SDK generation queue listening",
-		zap.Int("workerCount", cfg.SdkGeneration.WorkerCount),
+		zap.Int("workerCount", cfg.SdkGeneration.GetWorkerCount()),
 		zap.Duration("pollInterval", pollInterval),
 	)
 
@@ -116,12 +150,15 @@ func main() {
 		registryService,
 		emailService,
 		userPgRepository,
+		cfg,
 	)
 
 	authInterceptor := authentication.NewAuthInterceptor(cfg.JwtSecret)
 
-	interceptors := []connect.Interceptor{validate.NewInterceptor(), authInterceptor}
-	if cfg.Otel.Enabled {
+	totpInterceptor := authentication.NewTotpInterceptor(userService, cfg.Totp.GetSensitiveProcedures())
+
+	interceptors := []connect.Interceptor{validate.NewInterceptor(), authInterceptor, totpInterceptor}
+	if traceProvider != nil {
 		otelInterceptor, err := otelconnect.NewInterceptor(
 			otelconnect.WithTracerProvider(traceProvider),
 		)
@@ -130,38 +167,104 @@ func main() {
 		}
 		interceptors = append(interceptors, otelInterceptor)
 	}
+	requestTimeout, err := cfg.Server.GetRequestTimeout()
+	if err != nil {
+		zap.L().Fatal("invalid request timeout", zap.Error(err))
+	}
+	procedureTimeouts, err := cfg.Server.GetProcedureTimeouts()
+	if err != nil {
+		zap.L().Fatal("invalid procedure timeouts", zap.Error(err))
+	}
+	interceptors = append(interceptors,
+		middleware.NewRequestIDInterceptor(),
+		middleware.NewReadRoutingInterceptor(),
+		middleware.NewTimeoutInterceptor(requestTimeout, procedureTimeouts),
+	)
+
+	queryTimeout, err := cfg.PostgresConfig.GetQueryTimeout()
+	if err != nil {
+		zap.L().Fatal("invalid database query timeout", zap.Error(err))
+	}
+
+	adminPgRepository := postgresAdmin.NewPgRepository(
+		organizationPgRepository.GetConnectionPool(),
+		organizationPgRepository.GetTracer(),
+		queryTimeout,
+	)
+	adminService := admin.NewService(adminPgRepository, registryService, cfg.AdminUserIds)
 
 	userHandler := user.NewHandler(userService, userPgRepository, interceptors...)
 	registryHandler := registry.NewHandler(registryService, repositoryPgRepository, interceptors...)
 	organizationHandler := internalOrganization.NewHandler(organizationService, organizationPgRepository, repositoryPgRepository, interceptors...)
+	totpHandler := user.NewTotpHandler(userService, authInterceptor)
+	deployTokenHandler := registry.NewDeployTokenHandler(registryService, authInterceptor)
+	adminHandler := admin.NewHandler(adminService, authInterceptor)
 	handlers := []internal.GlobalHandler{
 		userHandler,
+		totpHandler,
 		registryHandler,
+		deployTokenHandler,
 		organizationHandler,
+		adminHandler,
+	}
+
+	clientIPResolver, err := middleware.NewClientIPResolver(cfg.Server.TrustedProxies)
+	if err != nil {
+		zap.L().Fatal("invalid trusted proxies", zap.Error(err))
+	}
+
+	requestQueueTimeout, err := cfg.Server.GetRequestQueueTimeout()
+	if err != nil {
+		zap.L().Fatal("invalid request queue timeout", zap.Error(err))
 	}
 
 	mux := http.NewServeMux()
-	handler := cors.AllowAll().Handler(mux)
+	securityHeaders := middleware.SecurityHeaders(!cfg.Server.DisableHsts, "/git/")
+	concurrencyLimit := middleware.ConcurrencyLimit(
+		cfg.Server.MaxConcurrentRequests,
+		cfg.Server.MaxQueuedRequests,
+		requestQueueTimeout,
+		"/git/", "/sdk/",
+	)
+	gitConcurrencyLimit := middleware.ConcurrencyLimit(cfg.Server.MaxConcurrentGitRequests, 0, 0)
+	handler := middleware.ClientIP(clientIPResolver)(middleware.RequestID()(concurrencyLimit(securityHeaders(cors.AllowAll().Handler(mux)))))
 	for _, handler := range handlers {
 		path, h := handler.RegisterRoutes()
 		mux.Handle(path, h)
 	}
 
-	gitHttpHandler := registry.NewGitHttpHandler(registryService, userPgRepository, registry.DefaultReposPath)
-	mux.Handle("/git/", gitHttpHandler)
+	gitHttpHandler := registry.NewGitHttpHandler(registryService, userPgRepository, cfg.Repository.GetPath())
+	mux.Handle("/git/", gitConcurrencyLimit(gitHttpHandler))
 
-	sdkHttpHandler := registry.NewSdkHttpHandler(cfg.SdkGeneration.OutputPath)
-	mux.Handle("/sdk/", sdkHttpHandler)
+	sdkHttpHandler := registry.NewSdkHttpHandler(cfg.SdkGeneration.GetOutputPath())
+	mux.Handle("/sdk/", gitConcurrencyLimit(sdkHttpHandler))
 
-	sdkPath := cfg.SdkGeneration.GetOutputPath()
 	docHttpHandler := registry.NewDocumentationHttpHandler(
 		registryService,
 		repositoryPgRepository,
 		cfg.JwtSecret,
-		sdkPath,
+		cfg.SdkGeneration.GetDocsPath(),
 	)
 	mux.Handle("/docs/", docHttpHandler)
 
+	retentionPeriod, err := cfg.Retention.GetPeriod()
+	if err != nil {
+		zap.L().Fatal("invalid retention period", zap.Error(err))
+	}
+	retentionInterval, err := cfg.Retention.GetInterval()
+	if err != nil {
+		zap.L().Fatal("invalid retention interval", zap.Error(err))
+	}
+	reaper := admin.NewReaper(adminPgRepository, registryService, retentionPeriod, cfg.Retention.GetBatchSize())
+	reaper.Start(ctx, retentionInterval)
+
+	readiness := health.NewReadiness(5 * time.Second)
+	readiness.Add("database", organizationPgRepository.GetConnectionPool().Ping)
+	if cfg.Ssh.Enabled {
+		readiness.Add("ssh", health.SshBannerCheck(net.JoinHostPort("127.0.0.1", cfg.Ssh.Port)))
+	}
+	mux.Handle("GET /readyz", readiness)
+
 	protocols := new(http.Protocols)
 	protocols.SetHTTP1(true)
 	protocols.SetUnencryptedHTTP2(true)
@@ -181,104 +284,39 @@ func main() {
 
 	var sshServer *ssh.Server
 	if cfg.Ssh.Enabled {
-		gitSshHandler := registry.NewGitSshHandler(registryService, registry.DefaultReposPath)
-		sdkSshHandler := registry.NewSdkSshHandler(cfg.SdkGeneration.OutputPath)
+		gitSshHandler := registry.NewGitSshHandler(registryService, cfg.Repository.GetPath())
+		sdkSshHandler := registry.NewSdkSshHandler(cfg.SdkGeneration.GetOutputPath())
 		sshServer = startSshServer(cfg, userPgRepository, gitSshHandler, sdkSshHandler)
 	}
 
-	gracefulShutdown(server, sshServer, traceProvider, emailJobQueue, sdkGenerationQueue)
+	timeouts, err := shutdownTimeoutsFromConfig(cfg.Shutdown)
+	if err != nil {
+		zap.L().Fatal("invalid shutdown timeouts", zap.Error(err))
+	}
+
+	gracefulShutdown(timeouts, server, sshServer, traceProvider, emailJobQueue, sdkGenerationQueue, reaper)
 }
 
-func gracefulShutdown(server *http.Server, sshServer *ssh.Server, traceProvider *sdktrace.TracerProvider, emailJobQueue internalOrganization.Queue, sdkGenerationQueue registry.SdkGenerationQueue) {
+func gracefulShutdown(timeouts shutdownTimeouts, server *http.Server, sshServer *ssh.Server, traceProvider *sdktrace.TracerProvider, emailJobQueue internalOrganization.Queue, sdkGenerationQueue registry.SdkGenerationQueue, reaper *admin.Reaper) {
 	sigChan := make(chan os.Signal, 1)
 	signal.Notify(sigChan, os.Interrupt, syscall.SIGTERM)
 
 	<-sigChan
 	zap.L().Info("Shutting down server...")
 
-	shutdownCtx, shutdownRelease := context.WithTimeout(context.Background(), 10*time.Second)
-	defer shutdownRelease()
-
-	emailJobQueue.Stop()
-	sdkGenerationQueue.Stop()
-
-	if err := server.Shutdown(shutdownCtx); err != nil {
-		zap.L().Error("HTTP shutdown error", zap.Error(err))
-	}
-
-	if sshServer != nil {
-		if err := sshServer.Shutdown(shutdownCtx); err != nil {
-			zap.L().Error("SSH shutdown error", zap.Error(err))
-		}
-	}
-
-	if traceProvider != nil {
-		if err := traceProvider.Shutdown(shutdownCtx); err != nil {
-			zap.L().Error("TracerProvider shutdown error", zap.Error(err))
-		}
-	}
-
-	zap.L().Info("Server gracefully stopped")
-}
-
-func initTracer(cfg *config.Config) *sdktrace.TracerProvider {
-	ctx := context.Background()
-	res, err := resource.New(ctx,
-		resource.WithAttributes(
-			semconv.ServiceNameKey.String("hasir-api"),
-			semconv.ServiceVersionKey.String("1.0.0"),
-		),
-	)
-	if err != nil {
-		zap.L().Fatal("failed to create resource", zap.Error(err))
-	}
-
-	traceExporter, err := otlptracegrpc.New(ctx,
-		otlptracegrpc.WithEndpoint(cfg.Otel.TraceEndpoint),
-		otlptracegrpc.WithInsecure(),
-	)
-	if err != nil {
-		zap.L().Fatal("failed to create trace exporter", zap.Error(err))
-	}
-
-	tracerProvider := sdktrace.NewTracerProvider(
-		sdktrace.WithSampler(sdktrace.AlwaysSample()),
-		sdktrace.WithResource(res),
-		sdktrace.WithBatcher(traceExporter),
-	)
-	otel.SetTracerProvider(tracerProvider)
-	otel.SetTextMapPropagator(
-		propagation.NewCompositeTextMapPropagator(
-			propagation.TraceContext{},
-			propagation.Baggage{},
-		),
-	)
-
-	zap.L().Info("OpenTelemetry tracing enabled", zap.String("endpoint", cfg.Otel.TraceEndpoint))
-	return tracerProvider
+	shutdown(timeouts, server, sshServer, []stopper{emailJobQueue, sdkGenerationQueue, reaper}, traceProvider)
 }
 
 func startSshServer(cfg *config.Config, userRepo user.Repository, gitSshHandler *registry.GitSshHandler, sdkSshHandler *registry.SdkSshHandler) *ssh.Server {
-	hostKey, err := loadOrGenerateHostKey(cfg.Ssh.HostKeyPath)
+	hostKey, err := loadOrGenerateHostKey(cfg.Ssh.GetHostKeyPath())
 	if err != nil {
 		zap.L().Fatal("failed to load SSH host key", zap.Error(err))
 	}
 
 	sshServer := &ssh.Server{
 		Addr: ":" + cfg.Ssh.Port,
-		PublicKeyHandler: func(ctx ssh.Context, key ssh.PublicKey) bool {
-			publicKeyStr := strings.TrimSpace(string(gossh.MarshalAuthorizedKey(key)))
-			userDTO, err := userRepo.GetUserBySshPublicKey(context.Background(), publicKeyStr)
-			if err != nil {
-				zap.L().Debug("SSH auth failed", zap.Error(err))
-				return false
-			}
-			ctx.SetValue("userId", userDTO.Id)
-			zap.L().Info("SSH auth success", zap.String("userId", userDTO.Id))
-			return true
-		},
 		Handler: func(session ssh.Session) {
-			userId, ok := session.Context().Value("userId").(string)
+			userId, ok := session.Context().Value(user.SshUserIdContextKey).(string)
 			if !ok || userId == "" {
 				_, _ = fmt.Fprintln(session.Stderr(), "Authentication required")
 				_ = session.Exit(1)
@@ -313,6 +351,17 @@ func startSshServer(cfg *config.Config, userRepo user.Repository, gitSshHandler
 		},
 	}
 	sshServer.AddHostKey(hostKey)
+	sshKeyPolicy := user.SshKeyPolicy{
+		AllowedTypes: cfg.Ssh.GetAllowedKeyTypes(),
+		MinRsaBits:   cfg.Ssh.GetMinRsaKeyBits(),
+	}
+	user.NewSshAuthenticator(userRepo, cfg.Ssh.AllowApiKeyAuth, sshKeyPolicy).Apply(sshServer)
+
+	limits, err := sshserver.LimitsFromConfig(cfg.Ssh)
+	if err != nil {
+		zap.L().Fatal("invalid SSH server limits", zap.Error(err))
+	}
+	sshserver.Apply(sshServer, limits)
 
 	go func() {
 		zap.L().Info("SSH server starting", zap.String("port", cfg.Ssh.Port))
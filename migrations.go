@@ -0,0 +1,81 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/golang-migrate/migrate/v4"
+	"go.uber.org/zap"
+
+	"hasir-api/pkg/config"
+)
+
+var errDirtyDatabase = errors.New("database migrations are in a dirty state")
+
+// runMigrations applies pending migrations. A database left dirty by an
+// interrupted migration is refused with instructions, unless the config opts
+// into forcing it back to a known version first.
+func runMigrations(m *migrate.Migrate, cfg config.MigrationConfig) error {
+	from, dirty, err := migrationVersion(m)
+	if err != nil {
+		return fmt.Errorf("failed to read migration version: %w", err)
+	}
+
+	if dirty {
+		if cfg.GetDirtyPolicy() != config.MigrationDirtyForce {
+			return dirtyDatabaseError(from)
+		}
+
+		zap.L().Warn("forcing dirty migration version",
+			zap.Uint("dirtyVersion", from),
+			zap.Int("forceVersion", cfg.ForceVersion),
+		)
+		if err := m.Force(cfg.ForceVersion); err != nil {
+			return fmt.Errorf("failed to force migration version %d: %w", cfg.ForceVersion, err)
+		}
+		from = uint(cfg.ForceVersion) // #nosec G115 -- validated positive by config.Validate
+	}
+
+	if err := m.Up(); err != nil {
+		if errors.Is(err, migrate.ErrNoChange) {
+			zap.L().Info("database schema is up to date", zap.Uint("version", from))
+			return nil
+		}
+
+		// The failing migration may itself have left the database dirty.
+		if version, dirty, versionErr := migrationVersion(m); versionErr == nil && dirty {
+			return fmt.Errorf("%w (cause: %v)", dirtyDatabaseError(version), err)
+		}
+
+		return fmt.Errorf("failed to apply migrations: %w", err)
+	}
+
+	to, _, err := migrationVersion(m)
+	if err != nil {
+		return fmt.Errorf("failed to read migration version: %w", err)
+	}
+
+	zap.L().Info("applied migrations", zap.Uint("fromVersion", from), zap.Uint("toVersion", to))
+
+	return nil
+}
+
+// migrationVersion reports version 0 for a database no migration has touched.
+func migrationVersion(m *migrate.Migrate) (uint, bool, error) {
+	version, dirty, err := m.Version()
+	if errors.Is(err, migrate.ErrNilVersion) {
+		return 0, false, nil
+	}
+
+	return version, dirty, err
+}
+
+func dirtyDatabaseError(version uint) error {
+	return fmt.Errorf(
+		"%w: migration %d stopped partway. Check which of its statements were applied and "+
+			"finish or revert them by hand, then either run `migrate force <last good version>` "+
+			"or restart with migration.dirtyPolicy=force and migration.forceVersion set to that version",
+		errDirtyDatabase,
+		version,
+	)
+}
@@ -0,0 +1 @@
+ALTER TABLE ssh_keys DROP COLUMN IF EXISTS last_used_at;
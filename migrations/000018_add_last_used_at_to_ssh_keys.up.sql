@@ -0,0 +1 @@
+ALTER TABLE ssh_keys ADD COLUMN IF NOT EXISTS last_used_at TIMESTAMP;
@@ -0,0 +1,37 @@
+-- Display names may have drifted from slugs; the slug is what URLs used.
+UPDATE organizations SET display_name = slug;
+
+DROP MATERIALIZED VIEW IF EXISTS search_items;
+
+DROP INDEX IF EXISTS idx_organizations_slug;
+
+ALTER TABLE organizations DROP COLUMN IF EXISTS slug;
+ALTER TABLE organizations RENAME COLUMN display_name TO name;
+
+CREATE INDEX IF NOT EXISTS idx_organizations_name ON organizations(name);
+
+CREATE MATERIALIZED VIEW search_items AS
+SELECT
+    o.id,
+    o.name,
+    'organization' AS item_type,
+    NULL::VARCHAR(36) AS organization_id,
+    o.created_at,
+    o.deleted_at
+FROM organizations o
+UNION ALL
+SELECT
+    r.id,
+    r.name,
+    'repository' AS item_type,
+    r.organization_id,
+    r.created_at,
+    r.deleted_at
+FROM repositories r;
+
+CREATE INDEX IF NOT EXISTS idx_search_items_name_trgm ON search_items USING gin (name gin_trgm_ops);
+CREATE INDEX IF NOT EXISTS idx_search_items_item_type ON search_items (item_type);
+CREATE INDEX IF NOT EXISTS idx_search_items_organization_id ON search_items (organization_id);
+CREATE INDEX IF NOT EXISTS idx_search_items_deleted_at ON search_items (deleted_at);
+CREATE INDEX IF NOT EXISTS idx_search_items_created_at ON search_items (created_at DESC);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_search_items_id_type ON search_items (id, item_type);
@@ -0,0 +1,40 @@
+-- Split the organization name into an immutable slug, used in URLs, clone
+-- paths and the uniqueness check, and a display name owners can change.
+ALTER TABLE organizations RENAME COLUMN name TO display_name;
+ALTER TABLE organizations ADD COLUMN IF NOT EXISTS slug VARCHAR(255);
+
+UPDATE organizations SET slug = display_name;
+
+ALTER TABLE organizations ALTER COLUMN slug SET NOT NULL;
+
+DROP INDEX IF EXISTS idx_organizations_name;
+CREATE UNIQUE INDEX IF NOT EXISTS idx_organizations_slug ON organizations(slug) WHERE deleted_at IS NULL;
+
+-- Search results link to organizations, so index the slug rather than the label.
+DROP MATERIALIZED VIEW IF EXISTS search_items;
+
+CREATE MATERIALIZED VIEW search_items AS
+SELECT
+    o.id,
+    o.slug AS name,
+    'organization' AS item_type,
+    NULL::VARCHAR(36) AS organization_id,
+    o.created_at,
+    o.deleted_at
+FROM organizations o
+UNION ALL
+SELECT
+    r.id,
+    r.name,
+    'repository' AS item_type,
+    r.organization_id,
+    r.created_at,
+    r.deleted_at
+FROM repositories r;
+
+CREATE INDEX IF NOT EXISTS idx_search_items_name_trgm ON search_items USING gin (name gin_trgm_ops);
+CREATE INDEX IF NOT EXISTS idx_search_items_item_type ON search_items (item_type);
+CREATE INDEX IF NOT EXISTS idx_search_items_organization_id ON search_items (organization_id);
+CREATE INDEX IF NOT EXISTS idx_search_items_deleted_at ON search_items (deleted_at);
+CREATE INDEX IF NOT EXISTS idx_search_items_created_at ON search_items (created_at DESC);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_search_items_id_type ON search_items (id, item_type);
@@ -0,0 +1,2 @@
+DROP INDEX IF EXISTS idx_repository_pushes_repository_id_pushed_at;
+DROP TABLE IF EXISTS repository_pushes;
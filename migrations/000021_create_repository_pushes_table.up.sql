@@ -0,0 +1,8 @@
+CREATE TABLE IF NOT EXISTS repository_pushes (
+    id VARCHAR(36) PRIMARY KEY,
+    repository_id VARCHAR(36) NOT NULL REFERENCES repositories(id) ON DELETE CASCADE,
+    commit_hash VARCHAR(40) NOT NULL,
+    pushed_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX idx_repository_pushes_repository_id_pushed_at ON repository_pushes(repository_id, pushed_at DESC);
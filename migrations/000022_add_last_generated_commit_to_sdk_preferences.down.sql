@@ -0,0 +1 @@
+ALTER TABLE sdk_preferences DROP COLUMN IF EXISTS last_generated_commit;
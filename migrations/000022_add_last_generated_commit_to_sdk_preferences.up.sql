@@ -0,0 +1 @@
+ALTER TABLE sdk_preferences ADD COLUMN IF NOT EXISTS last_generated_commit VARCHAR(40);
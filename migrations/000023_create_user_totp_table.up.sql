@@ -0,0 +1,8 @@
+-- TOTP second factor. The secret is stored encrypted because verifying a code
+-- needs the secret itself; enabled_at stays NULL until the first code checks out.
+CREATE TABLE IF NOT EXISTS user_totp (
+    user_id VARCHAR(36) PRIMARY KEY REFERENCES users(id) ON DELETE CASCADE,
+    secret TEXT NOT NULL,
+    enabled_at TIMESTAMP WITH TIME ZONE,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW()
+);
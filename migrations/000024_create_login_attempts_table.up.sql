@@ -0,0 +1,8 @@
+-- Consecutive failed logins per account, kept in the database so every
+-- instance enforces the same lockout.
+CREATE TABLE IF NOT EXISTS login_attempts (
+    user_id VARCHAR(36) PRIMARY KEY REFERENCES users(id) ON DELETE CASCADE,
+    failed_count INTEGER NOT NULL DEFAULT 0,
+    last_failed_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    locked_until TIMESTAMP WITH TIME ZONE
+);
@@ -0,0 +1,4 @@
+DROP INDEX IF EXISTS idx_docs_generation_jobs_created_at;
+DROP INDEX IF EXISTS idx_docs_generation_jobs_status;
+DROP INDEX IF EXISTS idx_docs_generation_jobs_in_flight;
+DROP TABLE IF EXISTS docs_generation_jobs;
@@ -0,0 +1,20 @@
+CREATE TABLE IF NOT EXISTS docs_generation_jobs (
+    id VARCHAR(36) PRIMARY KEY,
+    repository_id VARCHAR(36) NOT NULL REFERENCES repositories(id) ON DELETE CASCADE,
+    commit_hash VARCHAR(40) NOT NULL,
+    status VARCHAR(20) NOT NULL DEFAULT 'pending',
+    attempts INTEGER NOT NULL DEFAULT 0,
+    max_attempts INTEGER NOT NULL DEFAULT 3,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    processed_at TIMESTAMP WITH TIME ZONE,
+    completed_at TIMESTAMP WITH TIME ZONE,
+    error_message TEXT,
+    CONSTRAINT docs_generation_jobs_status_check CHECK (status IN ('pending', 'processing', 'completed', 'failed'))
+);
+
+-- A repository has at most one docs build in flight; enqueueing another is a
+-- no-op until it finishes.
+CREATE UNIQUE INDEX idx_docs_generation_jobs_in_flight ON docs_generation_jobs(repository_id)
+    WHERE status IN ('pending', 'processing');
+CREATE INDEX idx_docs_generation_jobs_status ON docs_generation_jobs(status);
+CREATE INDEX idx_docs_generation_jobs_created_at ON docs_generation_jobs(created_at);
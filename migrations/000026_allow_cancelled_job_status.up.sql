@@ -0,0 +1,16 @@
+-- Deleting an organization cancels the work still queued for it.
+ALTER TABLE email_jobs DROP CONSTRAINT chk_email_job_status;
+ALTER TABLE email_jobs ADD CONSTRAINT chk_email_job_status
+    CHECK (status IN ('pending', 'processing', 'completed', 'failed', 'cancelled'));
+
+ALTER TABLE sdk_generation_jobs DROP CONSTRAINT sdk_generation_jobs_status_check;
+ALTER TABLE sdk_generation_jobs ADD CONSTRAINT sdk_generation_jobs_status_check
+    CHECK (status IN ('pending', 'processing', 'completed', 'failed', 'cancelled'));
+
+ALTER TABLE sdk_trigger_jobs DROP CONSTRAINT sdk_trigger_jobs_status_check;
+ALTER TABLE sdk_trigger_jobs ADD CONSTRAINT sdk_trigger_jobs_status_check
+    CHECK (status IN ('pending', 'processing', 'completed', 'failed', 'cancelled'));
+
+ALTER TABLE docs_generation_jobs DROP CONSTRAINT docs_generation_jobs_status_check;
+ALTER TABLE docs_generation_jobs ADD CONSTRAINT docs_generation_jobs_status_check
+    CHECK (status IN ('pending', 'processing', 'completed', 'failed', 'cancelled'));
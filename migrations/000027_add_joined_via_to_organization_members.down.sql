@@ -0,0 +1,17 @@
+DROP VIEW IF EXISTS organization_members_view;
+
+CREATE VIEW organization_members_view AS
+SELECT
+    om.id,
+    om.organization_id,
+    om.user_id,
+    om.role,
+    om.joined_at,
+    u.username,
+    u.email
+FROM organization_members om
+INNER JOIN users u ON om.user_id = u.id
+WHERE u.deleted_at IS NULL;
+
+ALTER TABLE organization_members DROP CONSTRAINT IF EXISTS chk_member_joined_via;
+ALTER TABLE organization_members DROP COLUMN IF EXISTS joined_via;
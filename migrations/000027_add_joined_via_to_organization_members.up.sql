@@ -0,0 +1,27 @@
+-- Record how each member joined. Existing members are assumed to have been
+-- added directly unless an accepted invite for their email exists.
+ALTER TABLE organization_members ADD COLUMN IF NOT EXISTS joined_via VARCHAR(20) NOT NULL DEFAULT 'direct';
+ALTER TABLE organization_members ADD CONSTRAINT chk_member_joined_via
+    CHECK (joined_via IN ('invite', 'direct', 'transfer'));
+
+UPDATE organization_members om
+SET joined_via = 'invite'
+FROM organization_invites oi
+INNER JOIN users u ON u.email = oi.email
+WHERE oi.organization_id = om.organization_id
+    AND u.id = om.user_id
+    AND oi.status = 'accepted';
+
+CREATE OR REPLACE VIEW organization_members_view AS
+SELECT
+    om.id,
+    om.organization_id,
+    om.user_id,
+    om.role,
+    om.joined_at,
+    u.username,
+    u.email,
+    om.joined_via
+FROM organization_members om
+INNER JOIN users u ON om.user_id = u.id
+WHERE u.deleted_at IS NULL;
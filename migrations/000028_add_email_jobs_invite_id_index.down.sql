@@ -0,0 +1 @@
+DROP INDEX IF EXISTS idx_email_jobs_invite_id;
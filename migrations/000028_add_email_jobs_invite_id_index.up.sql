@@ -0,0 +1 @@
+CREATE INDEX IF NOT EXISTS idx_email_jobs_invite_id ON email_jobs(invite_id, created_at DESC);
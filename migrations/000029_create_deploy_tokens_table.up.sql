@@ -0,0 +1,11 @@
+CREATE TABLE IF NOT EXISTS deploy_tokens (
+    id VARCHAR(36) PRIMARY KEY,
+    repository_id VARCHAR(36) NOT NULL REFERENCES repositories(id) ON DELETE CASCADE,
+    token VARCHAR(255) NOT NULL UNIQUE,
+    read_only BOOLEAN NOT NULL DEFAULT TRUE,
+    created_by VARCHAR(36) NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    deleted_at TIMESTAMP WITH TIME ZONE
+);
+
+CREATE INDEX idx_deploy_tokens_repository_id ON deploy_tokens(repository_id);
@@ -0,0 +1,2 @@
+DROP INDEX IF EXISTS idx_organizations_slug_lower;
+CREATE UNIQUE INDEX IF NOT EXISTS idx_organizations_slug ON organizations(slug) WHERE deleted_at IS NULL;
@@ -0,0 +1,28 @@
+-- Organization slugs are matched without regard to case. New slugs are stored
+-- lower case; existing ones keep their spelling but must not collide once
+-- folded. Slugs appear in URLs and clone paths, so colliding ones are not
+-- renamed here: the migration stops and names them so an operator can pick
+-- which organization keeps its slug.
+DO $$
+DECLARE
+    conflicts TEXT;
+BEGIN
+    SELECT string_agg(format('%s (ids: %s)', folded, ids), '; ')
+    INTO conflicts
+    FROM (
+        SELECT LOWER(slug) AS folded, string_agg(id || '=' || slug, ', ' ORDER BY created_at) AS ids
+        FROM organizations
+        WHERE deleted_at IS NULL
+        GROUP BY LOWER(slug)
+        HAVING COUNT(*) > 1
+    ) duplicates;
+
+    IF conflicts IS NOT NULL THEN
+        RAISE EXCEPTION 'organization slugs collide when compared case-insensitively: %', conflicts
+            USING HINT = 'Rename or delete all but one organization per slug, then rerun the migration.';
+    END IF;
+END
+$$;
+
+DROP INDEX IF EXISTS idx_organizations_slug;
+CREATE UNIQUE INDEX IF NOT EXISTS idx_organizations_slug_lower ON organizations(LOWER(slug)) WHERE deleted_at IS NULL;
@@ -0,0 +1,6 @@
+CREATE TABLE IF NOT EXISTS organization_sdk_defaults (
+    organization_id VARCHAR(36) NOT NULL REFERENCES organizations(id) ON DELETE CASCADE,
+    sdk sdk_type NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (organization_id, sdk)
+);
@@ -0,0 +1,3 @@
+DROP INDEX IF EXISTS idx_repositories_forked_from;
+
+ALTER TABLE repositories DROP COLUMN IF EXISTS forked_from;
@@ -0,0 +1,3 @@
+ALTER TABLE repositories ADD COLUMN IF NOT EXISTS forked_from VARCHAR(36) REFERENCES repositories(id) ON DELETE SET NULL;
+
+CREATE INDEX IF NOT EXISTS idx_repositories_forked_from ON repositories(forked_from);
@@ -0,0 +1 @@
+ALTER TABLE user_totp DROP COLUMN IF EXISTS last_used_step;
@@ -0,0 +1,4 @@
+-- The time step of the last accepted code. A code is valid for its whole
+-- period and the neighbouring ones, so it is only single-use if later codes
+-- must come from a newer step.
+ALTER TABLE user_totp ADD COLUMN IF NOT EXISTS last_used_step BIGINT;
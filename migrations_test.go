@@ -12,6 +12,8 @@ import (
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 	"github.com/testcontainers/testcontainers-go/modules/postgres"
+
+	"hasir-api/pkg/config"
 )
 
 const (
@@ -105,6 +107,11 @@ func TestMigrations(t *testing.T) {
 			"sdk_preferences",
 			"password_reset_tokens",
 			"sdk_generation_jobs",
+			"repository_pushes",
+			"user_totp",
+			"login_attempts",
+			"docs_generation_jobs",
+			"organization_sdk_defaults",
 		}
 
 		for _, tableName := range expectedTables {
@@ -739,6 +746,11 @@ func TestMigrations(t *testing.T) {
 			"sdk_preferences",
 			"password_reset_tokens",
 			"sdk_generation_jobs",
+			"repository_pushes",
+			"user_totp",
+			"login_attempts",
+			"docs_generation_jobs",
+			"organization_sdk_defaults",
 		}
 
 		for _, tableName := range expectedTables {
@@ -1278,8 +1290,8 @@ func TestMigrations(t *testing.T) {
 		require.NoError(t, err)
 
 		_, err = conn.Exec(context.Background(),
-			`INSERT INTO organizations (id, name, visibility, created_by, created_at)
-			VALUES ('test-org-id', 'test-org', 'private', 'user-id', NOW())`)
+			`INSERT INTO organizations (id, slug, display_name, visibility, created_by, created_at)
+			VALUES ('test-org-id', 'test-org', 'Test Org', 'private', 'user-id', NOW())`)
 		require.NoError(t, err)
 
 		_, err = conn.Exec(context.Background(), "REFRESH MATERIALIZED VIEW search_items")
@@ -1326,8 +1338,8 @@ func TestMigrations(t *testing.T) {
 		require.NoError(t, err)
 
 		_, err = conn.Exec(context.Background(),
-			`INSERT INTO organizations (id, name, visibility, created_by, created_at)
-			VALUES ('test-org-id', 'test-org', 'private', 'user-id', NOW())`)
+			`INSERT INTO organizations (id, slug, display_name, visibility, created_by, created_at)
+			VALUES ('test-org-id', 'test-org', 'Test Org', 'private', 'user-id', NOW())`)
 		require.NoError(t, err)
 
 		_, err = conn.Exec(context.Background(),
@@ -1380,10 +1392,10 @@ func TestMigrations(t *testing.T) {
 		require.NoError(t, err)
 
 		_, err = conn.Exec(context.Background(),
-			`INSERT INTO organizations (id, name, visibility, created_by, created_at)
-			VALUES ('org-1', 'testing-org', 'private', 'user-id', NOW()),
-			       ('org-2', 'test', 'private', 'user-id', NOW()),
-			       ('org-3', 'production', 'private', 'user-id', NOW())`)
+			`INSERT INTO organizations (id, slug, display_name, visibility, created_by, created_at)
+			VALUES ('org-1', 'testing-org', 'Testing', 'private', 'user-id', NOW()),
+			       ('org-2', 'test', 'Test', 'private', 'user-id', NOW()),
+			       ('org-3', 'production', 'Production', 'private', 'user-id', NOW())`)
 		require.NoError(t, err)
 
 		_, err = conn.Exec(context.Background(), "REFRESH MATERIALIZED VIEW search_items")
@@ -1442,7 +1454,7 @@ func TestMigrations(t *testing.T) {
 		require.NoError(t, err)
 		assert.True(t, exists, "search_items view should exist before rollback")
 
-		err = m.Steps(-4)
+		err = m.Migrate(13)
 		require.NoError(t, err)
 
 		err = conn.QueryRow(context.Background(),
@@ -1463,6 +1475,97 @@ func TestMigrations(t *testing.T) {
 		require.NoError(t, err)
 		assert.False(t, exists, "pg_trgm extension should not exist after rollback")
 	})
+
+	t.Run("verify organization slugs are backfilled from names", func(t *testing.T) {
+		container := setupPostgresContainer(t)
+		defer func() {
+			err := container.Terminate(context.Background())
+			require.NoError(t, err)
+		}()
+
+		connString, err := container.ConnectionString(context.Background())
+		require.NoError(t, err)
+
+		m := setupMigration(t, connString)
+		defer func() {
+			_, _ = m.Close()
+		}()
+
+		err = m.Migrate(19)
+		require.NoError(t, err)
+
+		conn, err := pgx.Connect(context.Background(), connString)
+		require.NoError(t, err)
+		defer func() {
+			_ = conn.Close(context.Background())
+		}()
+
+		_, err = conn.Exec(context.Background(),
+			`INSERT INTO users (id, username, email, password, created_at)
+			VALUES ('user-id', 'testuser', 'test@example.com', 'password', NOW())`)
+		require.NoError(t, err)
+
+		_, err = conn.Exec(context.Background(),
+			`INSERT INTO organizations (id, name, visibility, created_by, created_at)
+			VALUES ('test-org-id', 'test-org', 'private', 'user-id', NOW())`)
+		require.NoError(t, err)
+
+		err = m.Steps(1)
+		require.NoError(t, err)
+
+		var slug, displayName string
+		err = conn.QueryRow(context.Background(),
+			"SELECT slug, display_name FROM organizations WHERE id = 'test-org-id'").Scan(&slug, &displayName)
+		require.NoError(t, err)
+		assert.Equal(t, "test-org", slug)
+		assert.Equal(t, "test-org", displayName)
+
+		_, err = conn.Exec(context.Background(),
+			`INSERT INTO organizations (id, slug, display_name, visibility, created_by, created_at)
+			VALUES ('other-org-id', 'test-org', 'Other', 'private', 'user-id', NOW())`)
+		require.Error(t, err, "slug should be unique among live organizations")
+	})
+
+	t.Run("verify case-colliding organization slugs stop the migration", func(t *testing.T) {
+		container := setupPostgresContainer(t)
+		defer func() {
+			err := container.Terminate(context.Background())
+			require.NoError(t, err)
+		}()
+
+		connString, err := container.ConnectionString(context.Background())
+		require.NoError(t, err)
+
+		m := setupMigration(t, connString)
+		defer func() {
+			_, _ = m.Close()
+		}()
+
+		err = m.Migrate(29)
+		require.NoError(t, err)
+
+		conn, err := pgx.Connect(context.Background(), connString)
+		require.NoError(t, err)
+		defer func() {
+			_ = conn.Close(context.Background())
+		}()
+
+		_, err = conn.Exec(context.Background(),
+			`INSERT INTO users (id, username, email, password, created_at)
+			VALUES ('user-id', 'testuser', 'test@example.com', 'password', NOW())`)
+		require.NoError(t, err)
+
+		_, err = conn.Exec(context.Background(),
+			`INSERT INTO organizations (id, slug, display_name, visibility, created_by, created_at)
+			VALUES ('upper-org-id', 'Acme', 'Acme', 'private', 'user-id', NOW()),
+				('lower-org-id', 'acme', 'acme', 'private', 'user-id', NOW())`)
+		require.NoError(t, err)
+
+		err = m.Steps(1)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "upper-org-id=Acme")
+		assert.Contains(t, err.Error(), "lower-org-id=acme")
+	})
 }
 
 func setupPostgresContainer(t *testing.T) *postgres.PostgresContainer {
@@ -1500,3 +1603,98 @@ func setupMigration(t *testing.T, connString string) *migrate.Migrate {
 
 	return m
 }
+
+func TestRunMigrations_DirtyState(t *testing.T) {
+	markDirty := func(t *testing.T, connString string, version uint) {
+		t.Helper()
+
+		m := setupMigration(t, connString)
+		defer func() {
+			_, _ = m.Close()
+		}()
+		require.NoError(t, m.Migrate(version))
+
+		conn, err := pgx.Connect(context.Background(), connString)
+		require.NoError(t, err)
+		defer func() {
+			_ = conn.Close(context.Background())
+		}()
+
+		_, err = conn.Exec(context.Background(), "UPDATE schema_migrations SET dirty = true")
+		require.NoError(t, err)
+	}
+
+	t.Run("fails with remediation message by default", func(t *testing.T) {
+		container := setupPostgresContainer(t)
+		defer func() {
+			err := container.Terminate(context.Background())
+			require.NoError(t, err)
+		}()
+
+		connString, err := container.ConnectionString(context.Background())
+		require.NoError(t, err)
+		markDirty(t, connString, 5)
+
+		m := setupMigration(t, connString)
+		defer func() {
+			_, _ = m.Close()
+		}()
+
+		err = runMigrations(m, config.MigrationConfig{})
+		require.Error(t, err)
+		assert.True(t, errors.Is(err, errDirtyDatabase))
+		assert.Contains(t, err.Error(), "migration 5 stopped partway")
+		assert.Contains(t, err.Error(), "migration.dirtyPolicy=force")
+
+		version, dirty, err := m.Version()
+		require.NoError(t, err)
+		assert.True(t, dirty, "failing fast must not touch the dirty flag")
+		assert.Equal(t, uint(5), version)
+	})
+
+	t.Run("forces the configured version and applies the rest", func(t *testing.T) {
+		container := setupPostgresContainer(t)
+		defer func() {
+			err := container.Terminate(context.Background())
+			require.NoError(t, err)
+		}()
+
+		connString, err := container.ConnectionString(context.Background())
+		require.NoError(t, err)
+		markDirty(t, connString, 5)
+
+		m := setupMigration(t, connString)
+		defer func() {
+			_, _ = m.Close()
+		}()
+
+		err = runMigrations(m, config.MigrationConfig{
+			DirtyPolicy:  config.MigrationDirtyForce,
+			ForceVersion: 5,
+		})
+		require.NoError(t, err)
+
+		_, dirty, err := m.Version()
+		require.NoError(t, err)
+		assert.False(t, dirty)
+	})
+
+	t.Run("clean database migrates normally", func(t *testing.T) {
+		container := setupPostgresContainer(t)
+		defer func() {
+			err := container.Terminate(context.Background())
+			require.NoError(t, err)
+		}()
+
+		connString, err := container.ConnectionString(context.Background())
+		require.NoError(t, err)
+
+		m := setupMigration(t, connString)
+		defer func() {
+			_, _ = m.Close()
+		}()
+
+		require.NoError(t, runMigrations(m, config.MigrationConfig{}))
+		require.NoError(t, runMigrations(m, config.MigrationConfig{}))
+	})
+}
@@ -13,8 +13,9 @@ import (
 type contextKey string
 
 const (
-	UserIDKey    contextKey = "user_id"
-	UserEmailKey contextKey = "user_email"
+	UserIDKey       contextKey = "user_id"
+	UserEmailKey    contextKey = "user_email"
+	TotpVerifiedKey contextKey = "totp_verified"
 )
 
 var (
@@ -48,50 +49,11 @@ func (a *AuthInterceptor) WrapUnary(next connect.UnaryFunc) connect.UnaryFunc {
 			return next(ctx, req)
 		}
 
-		authHeader := req.Header().Get("Authorization")
-		if authHeader == "" {
-			return nil, ErrMissingToken
-		}
-
-		tokenString := strings.TrimPrefix(authHeader, "Bearer ")
-		if tokenString == authHeader {
-			return nil, ErrInvalidToken
-		}
-
-		token, err := jwt.ParseWithClaims(tokenString, &JwtClaims{}, func(token *jwt.Token) (any, error) {
-			if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
-				return nil, errors.New("unexpected signing method")
-			}
-
-			return a.jwtSecret, nil
-		})
-
+		ctx, err := a.Authenticate(ctx, req.Header().Get("Authorization"))
 		if err != nil {
-			if errors.Is(err, jwt.ErrTokenExpired) {
-				return nil, ErrTokenExpired
-			}
-			return nil, ErrInvalidToken
-		}
-
-		if !token.Valid {
-			return nil, ErrInvalidToken
-		}
-
-		claims, ok := token.Claims.(*JwtClaims)
-		if !ok {
-			return nil, ErrInvalidClaims
+			return nil, err
 		}
 
-		userID, err := claims.GetSubject()
-		if err != nil {
-			return nil, ErrInvalidClaims
-		}
-
-		ctx = context.WithValue(ctx, UserIDKey, userID)
-
-		email := claims.Email
-		ctx = context.WithValue(ctx, UserEmailKey, email)
-
 		return next(ctx, req)
 	}
 }
@@ -106,53 +68,64 @@ func (a *AuthInterceptor) WrapStreamingHandler(next connect.StreamingHandlerFunc
 			return next(ctx, conn)
 		}
 
-		authHeader := conn.RequestHeader().Get("Authorization")
-		if authHeader == "" {
-			return ErrMissingToken
-		}
-
-		tokenString := strings.TrimPrefix(authHeader, "Bearer ")
-		if tokenString == authHeader {
-			return ErrInvalidToken
+		ctx, err := a.Authenticate(ctx, conn.RequestHeader().Get("Authorization"))
+		if err != nil {
+			return err
 		}
 
-		token, err := jwt.ParseWithClaims(tokenString, &JwtClaims{}, func(token *jwt.Token) (interface{}, error) {
-			if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
-				return nil, errors.New("unexpected signing method")
-			}
-
-			return a.jwtSecret, nil
-		})
+		return next(ctx, conn)
+	}
+}
 
-		if err != nil {
-			if errors.Is(err, jwt.ErrTokenExpired) {
-				return ErrTokenExpired
-			}
+// Authenticate verifies the bearer token in authHeader and returns ctx
+// carrying the caller's identity. Plain HTTP handlers use it to accept the
+// same tokens as the RPC services.
+func (a *AuthInterceptor) Authenticate(ctx context.Context, authHeader string) (context.Context, error) {
+	if authHeader == "" {
+		return nil, ErrMissingToken
+	}
 
-			return ErrInvalidToken
-		}
+	tokenString := strings.TrimPrefix(authHeader, "Bearer ")
+	if tokenString == authHeader {
+		return nil, ErrInvalidToken
+	}
 
-		if !token.Valid {
-			return ErrInvalidToken
+	token, err := jwt.ParseWithClaims(tokenString, &JwtClaims{}, func(token *jwt.Token) (any, error) {
+		if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
+			return nil, errors.New("unexpected signing method")
 		}
 
-		claims, ok := token.Claims.(*JwtClaims)
-		if !ok {
-			return ErrInvalidClaims
-		}
+		return a.jwtSecret, nil
+	})
 
-		userID, err := claims.GetSubject()
-		if err != nil {
-			return ErrInvalidClaims
+	if err != nil {
+		if errors.Is(err, jwt.ErrTokenExpired) {
+			return nil, ErrTokenExpired
 		}
+		return nil, ErrInvalidToken
+	}
 
-		ctx = context.WithValue(ctx, UserIDKey, userID)
+	if !token.Valid {
+		return nil, ErrInvalidToken
+	}
 
-		email := claims.Email
-		ctx = context.WithValue(ctx, UserEmailKey, email)
+	claims, ok := token.Claims.(*JwtClaims)
+	if !ok {
+		return nil, ErrInvalidClaims
+	}
 
-		return next(ctx, conn)
+	userID, err := claims.GetSubject()
+	if err != nil {
+		return nil, ErrInvalidClaims
 	}
+
+	ctx = context.WithValue(ctx, UserIDKey, userID)
+
+	email := claims.Email
+	ctx = context.WithValue(ctx, UserEmailKey, email)
+	ctx = context.WithValue(ctx, TotpVerifiedKey, claims.TotpVerified)
+
+	return ctx, nil
 }
 
 func GetUserID(ctx context.Context) (string, bool) {
@@ -165,6 +138,13 @@ func GetUserEmail(ctx context.Context) (string, bool) {
 	return email, ok
 }
 
+// IsTotpVerified reports whether the request carries a token issued after a
+// successful TOTP check.
+func IsTotpVerified(ctx context.Context) bool {
+	verified, _ := ctx.Value(TotpVerifiedKey).(bool)
+	return verified
+}
+
 func MustGetUserID(ctx context.Context) (string, error) {
 	userID, ok := GetUserID(ctx)
 	if !ok {
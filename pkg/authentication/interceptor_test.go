@@ -560,3 +560,31 @@ func (m *mockStreamingHandlerConn) ResponseHeader() http.Header {
 func (m *mockStreamingHandlerConn) ResponseTrailer() http.Header {
 	return make(http.Header)
 }
+
+func TestAuthInterceptor_Authenticate(t *testing.T) {
+	interceptor := NewAuthInterceptor(testSecret)
+
+	t.Run("valid token carries the caller", func(t *testing.T) {
+		token := generateTestToken(t, "user-123", "test@example.com", time.Now().Add(time.Hour))
+
+		ctx, err := interceptor.Authenticate(context.Background(), "Bearer "+token)
+		require.NoError(t, err)
+
+		userID, _ := GetUserID(ctx)
+		email, _ := GetUserEmail(ctx)
+		assert.Equal(t, "user-123", userID)
+		assert.Equal(t, "test@example.com", email)
+	})
+
+	t.Run("missing header", func(t *testing.T) {
+		_, err := interceptor.Authenticate(context.Background(), "")
+		assert.ErrorIs(t, err, ErrMissingToken)
+	})
+
+	t.Run("expired token", func(t *testing.T) {
+		token := generateTestToken(t, "user-123", "test@example.com", time.Now().Add(-time.Hour))
+
+		_, err := interceptor.Authenticate(context.Background(), "Bearer "+token)
+		assert.ErrorIs(t, err, ErrTokenExpired)
+	})
+}
@@ -5,5 +5,8 @@ import "github.com/golang-jwt/jwt/v5"
 type JwtClaims struct {
 	Email    string `json:"email"`
 	Username string `json:"username"`
+	// TotpVerified marks an access token issued right after a second-factor
+	// check, which sensitive procedures require from users with TOTP enabled.
+	TotpVerified bool `json:"totp_verified,omitempty"`
 	jwt.RegisteredClaims
 }
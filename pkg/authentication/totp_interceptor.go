@@ -0,0 +1,83 @@
+package authentication
+
+import (
+	"context"
+	"errors"
+
+	"connectrpc.com/connect"
+)
+
+var ErrTotpRequired = connect.NewError(
+	connect.CodePermissionDenied,
+	errors.New("this operation requires a token verified with two-factor authentication"),
+)
+
+// TotpStatusChecker reports whether a user has finished TOTP enrollment.
+type TotpStatusChecker interface {
+	IsTotpEnabled(ctx context.Context, userId string) (bool, error)
+}
+
+// TotpInterceptor rejects the configured procedures for users with TOTP
+// enabled unless their token carries the verified claim. It must run after
+// AuthInterceptor, which puts the user and the claim into the context.
+type TotpInterceptor struct {
+	checker    TotpStatusChecker
+	procedures map[string]bool
+}
+
+func NewTotpInterceptor(checker TotpStatusChecker, procedures []string) *TotpInterceptor {
+	set := make(map[string]bool, len(procedures))
+	for _, procedure := range procedures {
+		set[procedure] = true
+	}
+
+	return &TotpInterceptor{
+		checker:    checker,
+		procedures: set,
+	}
+}
+
+func (i *TotpInterceptor) WrapUnary(next connect.UnaryFunc) connect.UnaryFunc {
+	return func(ctx context.Context, req connect.AnyRequest) (connect.AnyResponse, error) {
+		if err := i.check(ctx, req.Spec().Procedure); err != nil {
+			return nil, err
+		}
+
+		return next(ctx, req)
+	}
+}
+
+func (i *TotpInterceptor) WrapStreamingClient(next connect.StreamingClientFunc) connect.StreamingClientFunc {
+	return next
+}
+
+func (i *TotpInterceptor) WrapStreamingHandler(next connect.StreamingHandlerFunc) connect.StreamingHandlerFunc {
+	return func(ctx context.Context, conn connect.StreamingHandlerConn) error {
+		if err := i.check(ctx, conn.Spec().Procedure); err != nil {
+			return err
+		}
+
+		return next(ctx, conn)
+	}
+}
+
+func (i *TotpInterceptor) check(ctx context.Context, procedure string) error {
+	if !i.procedures[procedure] || IsTotpVerified(ctx) {
+		return nil
+	}
+
+	userID, ok := GetUserID(ctx)
+	if !ok {
+		return nil
+	}
+
+	enabled, err := i.checker.IsTotpEnabled(ctx, userID)
+	if err != nil {
+		return err
+	}
+	if enabled {
+		return ErrTotpRequired
+	}
+
+	return nil
+}
@@ -0,0 +1,194 @@
+package authentication
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"connectrpc.com/connect"
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/types/known/emptypb"
+)
+
+// rfc6238Secret is the SHA1 key of the RFC 6238 test vectors in base32.
+const rfc6238Secret = "GEZDGNBVGY3TQOJQGEZDGNBVGY3TQOJQ"
+
+func TestGenerateTotpCode(t *testing.T) {
+	for _, tc := range []struct {
+		unix     int64
+		expected string
+	}{
+		{59, "287082"},
+		{1111111109, "081804"},
+		{1234567890, "005924"},
+		{2000000000, "279037"},
+	} {
+		code, err := GenerateTotpCode(rfc6238Secret, time.Unix(tc.unix, 0))
+		require.NoError(t, err)
+		assert.Equal(t, tc.expected, code, "at %d", tc.unix)
+	}
+}
+
+func TestValidateTotpCode(t *testing.T) {
+	secret, err := GenerateTotpSecret()
+	require.NoError(t, err)
+
+	now := time.Now()
+	code, err := GenerateTotpCode(secret, now)
+	require.NoError(t, err)
+
+	step := now.Unix() / int64(totpPeriod/time.Second)
+
+	matched, ok := ValidateTotpCode(secret, code, now)
+	assert.True(t, ok)
+	assert.Equal(t, step, matched)
+
+	matched, ok = ValidateTotpCode(secret, code, now.Add(totpPeriod))
+	assert.True(t, ok, "one period of drift is accepted")
+	assert.Equal(t, step, matched, "the step is the code's own, not the current one")
+
+	_, ok = ValidateTotpCode(secret, code, now.Add(3*totpPeriod))
+	assert.False(t, ok)
+	_, ok = ValidateTotpCode(secret, "12345", now)
+	assert.False(t, ok)
+	_, ok = ValidateTotpCode("not base32!", code, now)
+	assert.False(t, ok)
+}
+
+func TestTotpUri(t *testing.T) {
+	uri := TotpUri("Hasir", "user@example.com", rfc6238Secret)
+
+	assert.Equal(t,
+		"otpauth://totp/Hasir:user@example.com?algorithm=SHA1&digits=6&issuer=Hasir&period=30&secret="+rfc6238Secret,
+		uri)
+}
+
+func TestSealTotpSecret(t *testing.T) {
+	sealed, err := SealTotpSecret(testSecret, rfc6238Secret)
+	require.NoError(t, err)
+	assert.NotContains(t, sealed, rfc6238Secret)
+
+	opened, err := OpenTotpSecret(testSecret, sealed)
+	require.NoError(t, err)
+	assert.Equal(t, rfc6238Secret, opened)
+
+	_, err = OpenTotpSecret([]byte("another-secret"), sealed)
+	assert.Error(t, err)
+}
+
+type fakeTotpStatusChecker struct {
+	enabled map[string]bool
+	err     error
+}
+
+func (f *fakeTotpStatusChecker) IsTotpEnabled(_ context.Context, userId string) (bool, error) {
+	return f.enabled[userId], f.err
+}
+
+func TestTotpInterceptor_WrapUnary(t *testing.T) {
+	const sensitiveProcedure = "/organization.v1.OrganizationService/DeleteOrganization"
+
+	checker := &fakeTotpStatusChecker{enabled: map[string]bool{"user-2fa": true}}
+	interceptor := NewTotpInterceptor(checker, []string{sensitiveProcedure})
+
+	call := func(ctx context.Context, procedure string) (bool, error) {
+		called := false
+		next := func(ctx context.Context, req connect.AnyRequest) (connect.AnyResponse, error) {
+			called = true
+			return connect.NewResponse(new(emptypb.Empty)), nil
+		}
+
+		req := &testRequest{
+			Request:           connect.NewRequest(new(emptypb.Empty)),
+			procedureOverride: procedure,
+		}
+		_, err := interceptor.WrapUnary(next)(ctx, req)
+
+		return called, err
+	}
+
+	t.Run("sensitive procedure without verified token is rejected", func(t *testing.T) {
+		ctx := context.WithValue(t.Context(), UserIDKey, "user-2fa")
+
+		called, err := call(ctx, sensitiveProcedure)
+
+		assert.False(t, called)
+		assert.Equal(t, connect.CodePermissionDenied, connect.CodeOf(err))
+	})
+
+	t.Run("sensitive procedure with verified token passes", func(t *testing.T) {
+		ctx := context.WithValue(t.Context(), UserIDKey, "user-2fa")
+		ctx = context.WithValue(ctx, TotpVerifiedKey, true)
+
+		called, err := call(ctx, sensitiveProcedure)
+
+		require.NoError(t, err)
+		assert.True(t, called)
+	})
+
+	t.Run("user without totp passes", func(t *testing.T) {
+		ctx := context.WithValue(t.Context(), UserIDKey, "user-plain")
+
+		called, err := call(ctx, sensitiveProcedure)
+
+		require.NoError(t, err)
+		assert.True(t, called)
+	})
+
+	t.Run("other procedures are not gated", func(t *testing.T) {
+		ctx := context.WithValue(t.Context(), UserIDKey, "user-2fa")
+
+		called, err := call(ctx, "/organization.v1.OrganizationService/GetOrganization")
+
+		require.NoError(t, err)
+		assert.True(t, called)
+	})
+
+	t.Run("status lookup failure is returned", func(t *testing.T) {
+		failing := NewTotpInterceptor(&fakeTotpStatusChecker{err: errors.New("db down")}, []string{sensitiveProcedure})
+		next := func(ctx context.Context, req connect.AnyRequest) (connect.AnyResponse, error) {
+			return connect.NewResponse(new(emptypb.Empty)), nil
+		}
+		req := &testRequest{
+			Request:           connect.NewRequest(new(emptypb.Empty)),
+			procedureOverride: sensitiveProcedure,
+		}
+
+		_, err := failing.WrapUnary(next)(context.WithValue(t.Context(), UserIDKey, "user-2fa"), req)
+
+		assert.EqualError(t, err, "db down")
+	})
+}
+
+func TestAuthInterceptor_TotpVerifiedClaim(t *testing.T) {
+	interceptor := NewAuthInterceptor(testSecret)
+
+	for _, verified := range []bool{true, false} {
+		claims := &JwtClaims{
+			TotpVerified: verified,
+			RegisteredClaims: jwt.RegisteredClaims{
+				Subject:   "user-123",
+				ExpiresAt: jwt.NewNumericDate(time.Now().Add(time.Hour)),
+			},
+		}
+		token, err := jwt.NewWithClaims(jwt.SigningMethodHS256, claims).SignedString(testSecret)
+		require.NoError(t, err)
+
+		var captured bool
+		next := func(ctx context.Context, req connect.AnyRequest) (connect.AnyResponse, error) {
+			captured = IsTotpVerified(ctx)
+			return connect.NewResponse(new(emptypb.Empty)), nil
+		}
+
+		realReq := connect.NewRequest(new(emptypb.Empty))
+		realReq.Header().Set("Authorization", "Bearer "+token)
+		req := &testRequest{Request: realReq, procedureOverride: "/organization.v1.OrganizationService/DeleteOrganization"}
+
+		_, err = interceptor.WrapUnary(next)(t.Context(), req)
+		require.NoError(t, err)
+		assert.Equal(t, verified, captured)
+	}
+}
@@ -37,6 +37,24 @@ func IsUserOwner(ctx context.Context, checker MemberRoleChecker, organizationId,
 	return nil
 }
 
+// IsUserAuthor allows members who can push to the organization's
+// repositories, which includes owners.
+func IsUserAuthor(ctx context.Context, checker MemberRoleChecker, organizationId, userId string) error {
+	role, err := checker.GetMemberRole(ctx, organizationId, userId)
+	if err != nil {
+		if errors.Is(err, ErrMemberNotFound) {
+			return connect.NewError(connect.CodePermissionDenied, errors.New("you are not a member of this organization"))
+		}
+		return err
+	}
+
+	if role != MemberRoleOwner && role != MemberRoleAuthor {
+		return connect.NewError(connect.CodePermissionDenied, errors.New("only organization owners and authors can perform this operation"))
+	}
+
+	return nil
+}
+
 func IsUserMember(ctx context.Context, checker MemberRoleChecker, organizationId, userId string) error {
 	_, err := checker.GetMemberRole(ctx, organizationId, userId)
 	if err != nil {
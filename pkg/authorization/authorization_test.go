@@ -110,6 +110,48 @@ func TestIsUserOwner(t *testing.T) {
 	}
 }
 
+func TestIsUserAuthor(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+
+	tests := []struct {
+		name        string
+		role        string
+		roleErr     error
+		wantErrCode connect.Code
+	}{
+		{name: "owner is allowed", role: MemberRoleOwner},
+		{name: "author is allowed", role: MemberRoleAuthor},
+		{name: "reader is denied", role: MemberRoleReader, wantErrCode: connect.CodePermissionDenied},
+		{name: "non-member is denied", roleErr: ErrMemberNotFound, wantErrCode: connect.CodePermissionDenied},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			ctrl := gomock.NewController(t)
+			mockChecker := NewMockMemberRoleChecker(ctrl)
+			mockChecker.EXPECT().
+				GetMemberRole(ctx, "org-1", "user-1").
+				Return(tt.role, tt.roleErr)
+
+			err := IsUserAuthor(ctx, mockChecker, "org-1", "user-1")
+			if tt.wantErrCode == 0 {
+				if err != nil {
+					t.Fatalf("expected no error, got %v", err)
+				}
+				return
+			}
+
+			if connect.CodeOf(err) != tt.wantErrCode {
+				t.Fatalf("expected code %v, got %v", tt.wantErrCode, err)
+			}
+		})
+	}
+}
+
 func TestIsUserMember(t *testing.T) {
 	t.Parallel()
 
@@ -6,20 +6,78 @@ import (
 	"path/filepath"
 	"runtime"
 	"strings"
+	"time"
 
 	"github.com/knadh/koanf/parsers/json"
 	"github.com/knadh/koanf/providers/env"
 	"github.com/knadh/koanf/providers/file"
 	"github.com/knadh/koanf/v2"
+	"golang.org/x/crypto/bcrypt"
+	gossh "golang.org/x/crypto/ssh"
+
+	"hasir-api/pkg/naming"
 )
 
 type PostgresConfig struct {
-	ConnectionString string `koanf:"connectionString"`
-	Host             string `koanf:"host"`
-	Port             string `koanf:"port"`
-	Username         string `koanf:"username"`
-	Password         string `koanf:"password"`
-	Database         string `koanf:"database"`
+	ConnectionString   string `koanf:"connectionString"`
+	Host               string `koanf:"host"`
+	Port               string `koanf:"port"`
+	Username           string `koanf:"username"`
+	Password           string `koanf:"password"`
+	Database           string `koanf:"database"`
+	Schema             string `koanf:"schema"`
+	QueryTimeout       string `koanf:"queryTimeout"`
+	SearchQueryTimeout string `koanf:"searchQueryTimeout"`
+	// ReplicaConnectionString points list, search and count queries at a read
+	// replica. Writes and consistency-sensitive reads stay on the primary.
+	ReplicaConnectionString string `koanf:"replicaConnectionString"`
+	// SearchSimilarityThresholds sets how similar a name must be to a search
+	// query, by query length. Longer queries share more trigrams with
+	// unrelated names, so they need a stricter threshold to keep out noise.
+	SearchSimilarityThresholds SearchSimilarityThresholds `koanf:"searchSimilarityThresholds"`
+}
+
+// SearchSimilarityThresholdConfig is the trigram similarity a search result
+// needs once the query is at least MinQueryLength characters long.
+type SearchSimilarityThresholdConfig struct {
+	MinQueryLength int     `koanf:"minQueryLength"`
+	Threshold      float64 `koanf:"threshold"`
+}
+
+type SearchSimilarityThresholds []SearchSimilarityThresholdConfig
+
+var DefaultSearchSimilarityThresholds = SearchSimilarityThresholds{
+	{MinQueryLength: 0, Threshold: 0.1},
+	{MinQueryLength: 8, Threshold: 0.2},
+	{MinQueryLength: 16, Threshold: 0.3},
+}
+
+// For returns the threshold of the entry with the longest MinQueryLength the
+// query reaches. Without any entries the defaults apply, and a query shorter
+// than every entry gets the loosest default.
+func (thresholds SearchSimilarityThresholds) For(queryLength int) float64 {
+	if len(thresholds) == 0 {
+		thresholds = DefaultSearchSimilarityThresholds
+	}
+
+	threshold := DefaultSearchSimilarityThresholds[0].Threshold
+	longest := -1
+	for _, entry := range thresholds {
+		if entry.MinQueryLength <= queryLength && entry.MinQueryLength > longest {
+			threshold = entry.Threshold
+			longest = entry.MinQueryLength
+		}
+	}
+
+	return threshold
+}
+
+func (pgc *PostgresConfig) GetQueryTimeout() (time.Duration, error) {
+	return parseDurationOrDefault(pgc.QueryTimeout, 5*time.Second)
+}
+
+func (pgc *PostgresConfig) GetSearchQueryTimeout() (time.Duration, error) {
+	return parseDurationOrDefault(pgc.SearchQueryTimeout, 15*time.Second)
 }
 
 func (pgc *PostgresConfig) GetPostgresDsn() string {
@@ -38,10 +96,80 @@ func (pgc *PostgresConfig) GetPostgresDsn() string {
 }
 
 type ServerConfig struct {
-	PublicUrl string `koanf:"publicUrl"`
-	SshHost   string `koanf:"sshHost"`
-	Ip        string `koanf:"ip"`
-	Port      string `koanf:"port"`
+	PublicUrl      string   `koanf:"publicUrl"`
+	SshHost        string   `koanf:"sshHost"`
+	SshPort        string   `koanf:"sshPort"`
+	Ip             string   `koanf:"ip"`
+	Port           string   `koanf:"port"`
+	TrustedProxies []string `koanf:"trustedProxies"`
+	// RequestTimeout bounds each RPC. ProcedureTimeouts overrides it for
+	// single procedures, such as searches that legitimately run longer.
+	RequestTimeout    string                   `koanf:"requestTimeout"`
+	ProcedureTimeouts []ProcedureTimeoutConfig `koanf:"procedureTimeouts"`
+	// DisableHsts drops Strict-Transport-Security from responses, for
+	// development servers that are not behind TLS.
+	DisableHsts bool `koanf:"disableHsts"`
+	// MaxConcurrentRequests caps the HTTP requests served at once, git smart
+	// HTTP aside; zero means no limit. Up to MaxQueuedRequests more wait up to
+	// RequestQueueTimeout for a slot and the rest are turned away with 503.
+	MaxConcurrentRequests int    `koanf:"maxConcurrentRequests"`
+	MaxQueuedRequests     int    `koanf:"maxQueuedRequests"`
+	RequestQueueTimeout   string `koanf:"requestQueueTimeout"`
+	// MaxConcurrentGitRequests is the separate budget of the long-lived git
+	// and SDK clone streams, which would otherwise hold general slots for
+	// minutes; zero means no limit.
+	MaxConcurrentGitRequests int `koanf:"maxConcurrentGitRequests"`
+}
+
+// DefaultRequestQueueTimeout keeps queued requests from piling up behind a
+// slow burst; past it they are better off retrying.
+const DefaultRequestQueueTimeout = time.Second
+
+// ProcedureTimeoutConfig names a procedure the way Connect does, e.g.
+// "/registry.v1.RegistryService/GetFileTree". It is a list entry rather than
+// a map key because config keys are split on dots and lowercased.
+type ProcedureTimeoutConfig struct {
+	Procedure string `koanf:"procedure"`
+	Timeout   string `koanf:"timeout"`
+}
+
+func (srvc *ServerConfig) GetRequestTimeout() (time.Duration, error) {
+	return parseDurationOrDefault(srvc.RequestTimeout, 30*time.Second)
+}
+
+func (srvc *ServerConfig) GetRequestQueueTimeout() (time.Duration, error) {
+	return parseDurationOrDefault(srvc.RequestQueueTimeout, DefaultRequestQueueTimeout)
+}
+
+func (srvc *ServerConfig) GetProcedureTimeouts() (map[string]time.Duration, error) {
+	timeouts := make(map[string]time.Duration, len(srvc.ProcedureTimeouts))
+	for _, entry := range srvc.ProcedureTimeouts {
+		if !strings.HasPrefix(entry.Procedure, "/") {
+			return nil, fmt.Errorf("invalid procedure %q: must be a full procedure name", entry.Procedure)
+		}
+
+		timeout, err := parseDurationOrDefault(entry.Timeout, 0)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", entry.Procedure, err)
+		}
+		timeouts[entry.Procedure] = timeout
+	}
+
+	return timeouts, nil
+}
+
+// GetPublicSshPort returns the SSH port advertised to clients, which can differ
+// from the listen port when the server sits behind port forwarding.
+func (c *Config) GetPublicSshPort() string {
+	if c.Server.SshPort != "" {
+		return c.Server.SshPort
+	}
+
+	if c.Ssh.Port != "" {
+		return c.Ssh.Port
+	}
+
+	return "22"
 }
 
 func (srvc *ServerConfig) GetServerAddress() string {
@@ -55,6 +183,9 @@ func (srvc *ServerConfig) GetServerAddress() string {
 type OtelConfig struct {
 	Enabled       bool   `koanf:"enabled"`
 	TraceEndpoint string `koanf:"traceEndpoint"`
+	// Required makes an unreachable collector fatal at startup. By default
+	// the server starts without tracing instead.
+	Required bool `koanf:"required"`
 }
 
 type SmtpConfig struct {
@@ -64,19 +195,177 @@ type SmtpConfig struct {
 	Password string `koanf:"password"`
 	From     string `koanf:"from"`
 	UseTLS   bool   `koanf:"useTLS"`
+	// Security selects how the connection is protected: "starttls" upgrades
+	// a plain connection and fails when the server cannot, "tls" connects
+	// over TLS from the start and "none" never encrypts. Unset picks
+	// "starttls" for port 587, "tls" for port 465 or when UseTLS is set, and
+	// "starttls" otherwise.
+	Security string `koanf:"security"`
+	// InsecureSkipVerify accepts any server certificate. Only meant for
+	// development servers with self-signed certificates.
+	InsecureSkipVerify bool `koanf:"insecureSkipVerify"`
+}
+
+const (
+	SmtpSecurityStartTls = "starttls"
+	SmtpSecurityTls      = "tls"
+	SmtpSecurityNone     = "none"
+)
+
+func (smtp SmtpConfig) GetSecurity() string {
+	if smtp.Security != "" {
+		return smtp.Security
+	}
+
+	if smtp.Port != 587 && (smtp.Port == 465 || smtp.UseTLS) {
+		return SmtpSecurityTls
+	}
+
+	return SmtpSecurityStartTls
 }
 
 type SshConfig struct {
-	Enabled     bool   `koanf:"enabled"`
-	Port        string `koanf:"port"`
-	HostKeyPath string `koanf:"hostKeyPath"`
+	Enabled          bool   `koanf:"enabled"`
+	Port             string `koanf:"port"`
+	HostKeyPath      string `koanf:"hostKeyPath"`
+	IdleTimeout      string `koanf:"idleTimeout"`
+	MaxTimeout       string `koanf:"maxTimeout"`
+	HandshakeTimeout string `koanf:"handshakeTimeout"`
+	MaxSessions      int    `koanf:"maxSessions"`
+	AllowApiKeyAuth  bool   `koanf:"allowApiKeyAuth"`
+	// AllowedKeyTypes lists the public key algorithms accepted for
+	// authentication, e.g. "ssh-ed25519". Empty selects DefaultSshKeyTypes.
+	AllowedKeyTypes []string `koanf:"allowedKeyTypes"`
+	MinRsaKeyBits   int      `koanf:"minRsaKeyBits"`
+}
+
+// DefaultSshKeyTypes leaves out DSA, which OpenSSH itself no longer accepts.
+var DefaultSshKeyTypes = []string{
+	gossh.KeyAlgoED25519,
+	gossh.KeyAlgoSKED25519,
+	gossh.KeyAlgoECDSA256,
+	gossh.KeyAlgoECDSA384,
+	gossh.KeyAlgoECDSA521,
+	gossh.KeyAlgoSKECDSA256,
+	gossh.KeyAlgoRSA,
+}
+
+// supportedSshKeyTypes additionally allows opting back into DSA.
+var supportedSshKeyTypes = append([]string{"ssh-dss"}, DefaultSshKeyTypes...)
+
+const defaultMinRsaKeyBits = 2048
+
+func (ssh SshConfig) GetIdleTimeout() (time.Duration, error) {
+	return parseDurationOrDefault(ssh.IdleTimeout, 10*time.Minute)
+}
+
+func (ssh SshConfig) GetMaxTimeout() (time.Duration, error) {
+	return parseDurationOrDefault(ssh.MaxTimeout, 0)
+}
+
+func (ssh SshConfig) GetHandshakeTimeout() (time.Duration, error) {
+	return parseDurationOrDefault(ssh.HandshakeTimeout, 30*time.Second)
+}
+
+func (ssh SshConfig) GetHostKeyPath() string {
+	if ssh.HostKeyPath != "" {
+		return ssh.HostKeyPath
+	}
+
+	return "./ssh_host_key"
+}
+
+func (ssh SshConfig) GetMaxSessions() int {
+	if ssh.MaxSessions > 0 {
+		return ssh.MaxSessions
+	}
+
+	return 100
+}
+
+func (ssh SshConfig) GetAllowedKeyTypes() []string {
+	if len(ssh.AllowedKeyTypes) > 0 {
+		return ssh.AllowedKeyTypes
+	}
+
+	return DefaultSshKeyTypes
+}
+
+func (ssh SshConfig) GetMinRsaKeyBits() int {
+	if ssh.MinRsaKeyBits > 0 {
+		return ssh.MinRsaKeyBits
+	}
+
+	return defaultMinRsaKeyBits
+}
+
+func parseDurationOrDefault(value string, defaultValue time.Duration) (time.Duration, error) {
+	if value == "" {
+		return defaultValue, nil
+	}
+
+	duration, err := time.ParseDuration(value)
+	if err != nil {
+		return 0, fmt.Errorf("invalid duration %q: %w", value, err)
+	}
+
+	if duration < 0 {
+		return 0, fmt.Errorf("invalid duration %q: must not be negative", value)
+	}
+
+	return duration, nil
 }
 
 type SdkGenerationConfig struct {
 	WorkerCount    int    `koanf:"workerCount"`
 	PollInterval   string `koanf:"pollInterval"`
+	LeaseTimeout   string `koanf:"leaseTimeout"`
 	OutputPath     string `koanf:"outputPath"`
+	DocsPath       string `koanf:"docsPath"`
 	ModuleBasePath string `koanf:"moduleBasePath"`
+	// AllowedSdks lists the SDKs this deployment has toolchains for, by
+	// name (e.g. GO_PROTOBUF). Empty allows every SDK.
+	AllowedSdks []string `koanf:"allowedSdks"`
+}
+
+func (sdk SdkGenerationConfig) GetPollInterval() (time.Duration, error) {
+	return parseDurationOrDefault(sdk.PollInterval, 10*time.Second)
+}
+
+func (sdk SdkGenerationConfig) GetWorkerCount() int {
+	if sdk.WorkerCount > 0 {
+		return sdk.WorkerCount
+	}
+
+	return 5
+}
+
+func (sdk SdkGenerationConfig) GetLeaseTimeout() (time.Duration, error) {
+	return parseDurationOrDefault(sdk.LeaseTimeout, 15*time.Minute)
+}
+
+// SupportedSdks are the names allowedSdks accepts.
+var SupportedSdks = []string{
+	"GO_PROTOBUF",
+	"GO_CONNECTRPC",
+	"GO_GRPC",
+	"JS_BUFBUILD_ES",
+	"JS_PROTOBUF",
+	"JS_CONNECTRPC",
+}
+
+func (sdk SdkGenerationConfig) IsSdkAllowed(name string) bool {
+	if len(sdk.AllowedSdks) == 0 {
+		return true
+	}
+
+	for _, allowed := range sdk.AllowedSdks {
+		if strings.EqualFold(allowed, name) {
+			return true
+		}
+	}
+
+	return false
 }
 
 func (sdk SdkGenerationConfig) GetModuleBasePath() string {
@@ -95,6 +384,279 @@ func (sdk SdkGenerationConfig) GetOutputPath() string {
 	return "./sdk"
 }
 
+// GetDocsPath returns where generated documentation is written and served
+// from; it shares the SDK output tree unless configured separately.
+func (sdk SdkGenerationConfig) GetDocsPath() string {
+	if sdk.DocsPath != "" {
+		return sdk.DocsPath
+	}
+
+	return sdk.GetOutputPath()
+}
+
+type LogSamplingConfig struct {
+	Initial    int `koanf:"initial"`
+	Thereafter int `koanf:"thereafter"`
+}
+
+type LogConfig struct {
+	Format   string            `koanf:"format"`
+	Level    string            `koanf:"level"`
+	Sampling LogSamplingConfig `koanf:"sampling"`
+}
+
+func (l LogConfig) GetFormat() string {
+	if l.Format != "" {
+		return l.Format
+	}
+
+	return "json"
+}
+
+func (l LogConfig) GetLevel() string {
+	if l.Level != "" {
+		return l.Level
+	}
+
+	return "info"
+}
+
+const DefaultMaxPreviewSize int64 = 1 << 20
+
+// DefaultMaxFileTreeDepth is deep enough for the usual
+// <org>/<package>/<version> proto layouts.
+const DefaultMaxFileTreeDepth = 8
+
+// DefaultPushLockTimeout covers a typical push, so a second push to the same
+// repository usually waits its turn rather than failing.
+const DefaultPushLockTimeout = 10 * time.Second
+
+type RepositoryConfig struct {
+	Path string `koanf:"path"`
+	// TemplatePath is a directory whose files are committed into new
+	// repositories that ask for a template.
+	TemplatePath string `koanf:"templatePath"`
+	// MaxPreviewSize is the number of bytes GetFilePreview returns before
+	// truncating the content.
+	MaxPreviewSize int64 `koanf:"maxPreviewSize"`
+	// MaxFileTreeDepth is how many levels a recursive GetFileTree listing
+	// descends; directories at the last level are returned without children.
+	MaxFileTreeDepth int `koanf:"maxFileTreeDepth"`
+	// PushLockTimeout is how long a push waits for another push to the same
+	// repository to finish before it is rejected; "0s" rejects it at once.
+	PushLockTimeout string `koanf:"pushLockTimeout"`
+	// MaxConcurrentClonesPerUser caps the fetches and clones a single user
+	// can run at once over SSH and HTTP; zero means no limit.
+	MaxConcurrentClonesPerUser int `koanf:"maxConcurrentClonesPerUser"`
+}
+
+func (r RepositoryConfig) GetPath() string {
+	if r.Path != "" {
+		return r.Path
+	}
+
+	return "./repos"
+}
+
+func (r RepositoryConfig) GetMaxPreviewSize() int64 {
+	if r.MaxPreviewSize > 0 {
+		return r.MaxPreviewSize
+	}
+
+	return DefaultMaxPreviewSize
+}
+
+func (r RepositoryConfig) GetMaxFileTreeDepth() int {
+	if r.MaxFileTreeDepth > 0 {
+		return r.MaxFileTreeDepth
+	}
+
+	return DefaultMaxFileTreeDepth
+}
+
+func (r RepositoryConfig) GetPushLockTimeout() (time.Duration, error) {
+	return parseDurationOrDefault(r.PushLockTimeout, DefaultPushLockTimeout)
+}
+
+type OrganizationConfig struct {
+	// MaxMembers caps the members of a single organization; zero means no limit.
+	MaxMembers int `koanf:"maxMembers"`
+	// MaxRepositories caps the repositories of a single organization, not
+	// counting deleted ones; zero means no limit.
+	MaxRepositories int `koanf:"maxRepositories"`
+	// ForbidPublicReposInPrivateOrgs rejects making a repository public when
+	// its organization is private.
+	ForbidPublicReposInPrivateOrgs bool `koanf:"forbidPublicReposInPrivateOrgs"`
+	// InviteTTL is how long an invite link stays valid.
+	InviteTTL string `koanf:"inviteTtl"`
+	// DefaultInviteRole is given to invites that do not name a role; reader
+	// or author.
+	DefaultInviteRole string `koanf:"defaultInviteRole"`
+	// MaxInvitesPerRequest caps the invites a single request can send.
+	MaxInvitesPerRequest int `koanf:"maxInvitesPerRequest"`
+}
+
+const (
+	DefaultInviteTTL            = 7 * 24 * time.Hour
+	DefaultMaxInvitesPerRequest = 100
+)
+
+func (o OrganizationConfig) GetInviteTTL() (time.Duration, error) {
+	return parseDurationOrDefault(o.InviteTTL, DefaultInviteTTL)
+}
+
+func (o OrganizationConfig) GetMaxInvitesPerRequest() int {
+	if o.MaxInvitesPerRequest > 0 {
+		return o.MaxInvitesPerRequest
+	}
+
+	return DefaultMaxInvitesPerRequest
+}
+
+func (o OrganizationConfig) GetDefaultInviteRole() string {
+	if o.DefaultInviteRole != "" {
+		return o.DefaultInviteRole
+	}
+
+	return "author"
+}
+
+// DefaultTotpProcedures are the destructive procedures that need a
+// TOTP-verified token from users who enabled two-factor authentication.
+var DefaultTotpProcedures = []string{
+	"/organization.v1.OrganizationService/DeleteOrganization",
+	"/organization.v1.OrganizationService/UpdateMemberRole",
+	"/registry.v1.RegistryService/DeleteRepository",
+}
+
+type TotpConfig struct {
+	// Issuer is the account label authenticator apps show.
+	Issuer string `koanf:"issuer"`
+	// SensitiveProcedures overrides DefaultTotpProcedures.
+	SensitiveProcedures []string `koanf:"sensitiveProcedures"`
+}
+
+func (t TotpConfig) GetIssuer() string {
+	if t.Issuer != "" {
+		return t.Issuer
+	}
+
+	return "Hasir"
+}
+
+func (t TotpConfig) GetSensitiveProcedures() []string {
+	if len(t.SensitiveProcedures) > 0 {
+		return t.SensitiveProcedures
+	}
+
+	return DefaultTotpProcedures
+}
+
+// ShutdownConfig bounds each shutdown phase. The servers drain first, so the
+// queues stop only after every request that could enqueue a job has finished.
+type ShutdownConfig struct {
+	HttpTimeout  string `koanf:"httpTimeout"`
+	SshTimeout   string `koanf:"sshTimeout"`
+	QueueTimeout string `koanf:"queueTimeout"`
+	TraceTimeout string `koanf:"traceTimeout"`
+}
+
+func (s ShutdownConfig) GetHttpTimeout() (time.Duration, error) {
+	return parseDurationOrDefault(s.HttpTimeout, 10*time.Second)
+}
+
+func (s ShutdownConfig) GetSshTimeout() (time.Duration, error) {
+	return parseDurationOrDefault(s.SshTimeout, 10*time.Second)
+}
+
+func (s ShutdownConfig) GetQueueTimeout() (time.Duration, error) {
+	return parseDurationOrDefault(s.QueueTimeout, 30*time.Second)
+}
+
+func (s ShutdownConfig) GetTraceTimeout() (time.Duration, error) {
+	return parseDurationOrDefault(s.TraceTimeout, 5*time.Second)
+}
+
+const (
+	DefaultRetentionPeriod    = 30 * 24 * time.Hour
+	DefaultRetentionInterval  = time.Hour
+	DefaultRetentionBatchSize = 100
+)
+
+// RetentionConfig controls the reaper that permanently removes soft-deleted
+// users, organizations and repositories.
+type RetentionConfig struct {
+	// Period is how long a soft-deleted row is kept; "0s" turns the reaper
+	// off.
+	Period string `koanf:"period"`
+	// Interval is the time between two reaper passes.
+	Interval string `koanf:"interval"`
+	// BatchSize caps the rows removed by one statement.
+	BatchSize int `koanf:"batchSize"`
+}
+
+func (r RetentionConfig) GetPeriod() (time.Duration, error) {
+	return parseDurationOrDefault(r.Period, DefaultRetentionPeriod)
+}
+
+func (r RetentionConfig) GetInterval() (time.Duration, error) {
+	return parseDurationOrDefault(r.Interval, DefaultRetentionInterval)
+}
+
+func (r RetentionConfig) GetBatchSize() int {
+	if r.BatchSize > 0 {
+		return r.BatchSize
+	}
+
+	return DefaultRetentionBatchSize
+}
+
+type LoginLockoutConfig struct {
+	// MaxFailedAttempts is how many wrong passwords in a row lock an account.
+	MaxFailedAttempts int `koanf:"maxFailedAttempts"`
+	// BaseDuration is the first lock; every further failure doubles it up to
+	// MaxDuration.
+	BaseDuration string `koanf:"baseDuration"`
+	MaxDuration  string `koanf:"maxDuration"`
+}
+
+func (l LoginLockoutConfig) GetMaxFailedAttempts() int {
+	if l.MaxFailedAttempts > 0 {
+		return l.MaxFailedAttempts
+	}
+
+	return 5
+}
+
+func (l LoginLockoutConfig) GetBaseDuration() (time.Duration, error) {
+	return parseDurationOrDefault(l.BaseDuration, time.Minute)
+}
+
+func (l LoginLockoutConfig) GetMaxDuration() (time.Duration, error) {
+	return parseDurationOrDefault(l.MaxDuration, time.Hour)
+}
+
+const (
+	MigrationDirtyFail  = "fail"
+	MigrationDirtyForce = "force"
+)
+
+type MigrationConfig struct {
+	// DirtyPolicy decides what startup does when a previous migration stopped
+	// partway: "fail" (the default) refuses to start, "force" resets the
+	// recorded version to ForceVersion and applies migrations again.
+	DirtyPolicy  string `koanf:"dirtyPolicy"`
+	ForceVersion int    `koanf:"forceVersion"`
+}
+
+func (m MigrationConfig) GetDirtyPolicy() string {
+	if m.DirtyPolicy != "" {
+		return m.DirtyPolicy
+	}
+
+	return MigrationDirtyFail
+}
+
 type Config struct {
 	Server         ServerConfig        `koanf:"server"`
 	Otel           OtelConfig          `koanf:"otel"`
@@ -102,8 +664,41 @@ type Config struct {
 	Smtp           SmtpConfig          `koanf:"smtp"`
 	Ssh            SshConfig           `koanf:"ssh"`
 	SdkGeneration  SdkGenerationConfig `koanf:"sdkGeneration"`
+	Repository     RepositoryConfig    `koanf:"repository"`
+	Organization   OrganizationConfig  `koanf:"organization"`
+	Migration      MigrationConfig     `koanf:"migration"`
+	Totp           TotpConfig          `koanf:"totp"`
+	LoginLockout   LoginLockoutConfig  `koanf:"loginLockout"`
+	Shutdown       ShutdownConfig      `koanf:"shutdown"`
+	Retention      RetentionConfig     `koanf:"retention"`
+	Log            LogConfig           `koanf:"log"`
 	JwtSecret      []byte              `koanf:"jwtSecret"`
-	DashboardUrl   string              `koanf:"dashboardUrl"`
+	// PasswordHashCost is the bcrypt cost for new password hashes. Logins
+	// rehash passwords stored with a lower cost.
+	PasswordHashCost int      `koanf:"passwordHashCost"`
+	DashboardUrl     string   `koanf:"dashboardUrl"`
+	AdminUserIds     []string `koanf:"adminUserIds"`
+	// ReservedNamesFile lists organization and repository names to reserve
+	// on top of naming.DefaultReservedNames, one per line.
+	ReservedNamesFile string `koanf:"reservedNamesFile"`
+}
+
+// GetReservedNames reads ReservedNamesFile; without one there are no extra
+// reserved names.
+func (c *Config) GetReservedNames() ([]string, error) {
+	if c.ReservedNamesFile == "" {
+		return nil, nil
+	}
+
+	return naming.ReadReservedNamesFile(c.ReservedNamesFile)
+}
+
+func (c *Config) GetPasswordHashCost() int {
+	if c.PasswordHashCost > 0 {
+		return c.PasswordHashCost
+	}
+
+	return bcrypt.DefaultCost
 }
 
 type ConfigReader interface {
@@ -115,7 +710,7 @@ func NewConfigReader() ConfigReader {
 	if mode == "development" {
 		return &JsonConfig{}
 	}
-	return &EnvConfig{}
+	return &EnvConfig{FilePath: os.Getenv(ConfigFileEnv)}
 }
 
 func getCwd() string {
@@ -149,12 +744,30 @@ func (c *JsonConfig) Read() *Config {
 	return &config
 }
 
-type EnvConfig struct{}
+// ConfigFileEnv optionally names a JSON or YAML file that EnvConfig loads
+// before the environment.
+const ConfigFileEnv = "HASIR_CONFIG_FILE"
+
+// EnvConfig reads HASIR_* environment variables. When FilePath is set, the file
+// is loaded first and the environment overrides it key by key.
+type EnvConfig struct {
+	FilePath string
+}
 
 func (c *EnvConfig) Read() *Config {
 	koanfInstance := koanf.New(".")
 
+	if c.FilePath != "" {
+		if err := loadConfigFile(koanfInstance, c.FilePath); err != nil {
+			panic(fmt.Sprintf("error occurred while reading config file: %s", err))
+		}
+	}
+
 	err := koanfInstance.Load(env.Provider("HASIR_", ".", func(s string) string {
+		if s == ConfigFileEnv {
+			return ""
+		}
+
 		return strings.ReplaceAll(
 			strings.ToLower(strings.TrimPrefix(s, "HASIR_")),
 			"_",
@@ -172,3 +785,31 @@ func (c *EnvConfig) Read() *Config {
 
 	return &config
 }
+
+// loadConfigFile merges the file into k with lower-cased keys. Environment keys
+// are lower-cased too, so this is what lets them replace file values instead of
+// sitting next to them under a differently cased key.
+func loadConfigFile(k *koanf.Koanf, path string) error {
+	var parser koanf.Parser
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".json":
+		parser = json.Parser()
+	case ".yaml", ".yml":
+		parser = yamlParser{}
+	default:
+		return fmt.Errorf("unsupported config file type %q: use .json, .yaml or .yml", path)
+	}
+
+	fileKoanf := koanf.New(".")
+	if err := fileKoanf.Load(file.Provider(path), parser); err != nil {
+		return err
+	}
+
+	for key, value := range fileKoanf.All() {
+		if err := k.Set(strings.ToLower(key), value); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
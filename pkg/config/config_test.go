@@ -4,6 +4,7 @@ import (
 	"os"
 	"path/filepath"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -171,6 +172,94 @@ func TestEnvConfig_Read(t *testing.T) {
 	})
 }
 
+func TestEnvConfig_Read_WithFile(t *testing.T) {
+	writeFile := func(t *testing.T, name, content string) string {
+		path := filepath.Join(t.TempDir(), name)
+		require.NoError(t, os.WriteFile(path, []byte(content), 0644))
+		return path
+	}
+
+	t.Run("loads json file", func(t *testing.T) {
+		path := writeFile(t, "config.json", `{
+			"server": {"publicUrl": "https://file.example.com", "port": "8080"},
+			"sdkGeneration": {"workerCount": 3, "outputPath": "/var/sdk"},
+			"adminUserIds": ["admin-1"]
+		}`)
+
+		config := (&EnvConfig{FilePath: path}).Read()
+
+		assert.Equal(t, "https://file.example.com", config.Server.PublicUrl)
+		assert.Equal(t, "8080", config.Server.Port)
+		assert.Equal(t, 3, config.SdkGeneration.WorkerCount)
+		assert.Equal(t, "/var/sdk", config.SdkGeneration.OutputPath)
+		assert.Equal(t, []string{"admin-1"}, config.AdminUserIds)
+	})
+
+	t.Run("loads yaml file", func(t *testing.T) {
+		path := writeFile(t, "config.yaml", `
+server:
+  publicUrl: https://file.example.com
+  port: "8080"
+postgresql:
+  queryTimeout: 3s
+smtp:
+  port: 2525
+  useTLS: true
+`)
+
+		config := (&EnvConfig{FilePath: path}).Read()
+
+		assert.Equal(t, "https://file.example.com", config.Server.PublicUrl)
+		assert.Equal(t, "8080", config.Server.Port)
+		assert.Equal(t, "3s", config.PostgresConfig.QueryTimeout)
+		assert.Equal(t, 2525, config.Smtp.Port)
+		assert.True(t, config.Smtp.UseTLS)
+	})
+
+	t.Run("environment overrides file values", func(t *testing.T) {
+		path := writeFile(t, "config.json", `{
+			"server": {"publicUrl": "https://file.example.com", "port": "8080"},
+			"sdkGeneration": {"workerCount": 3, "pollInterval": "30s"}
+		}`)
+		t.Setenv("HASIR_SERVER_PORT", "9090")
+		t.Setenv("HASIR_SDKGENERATION_WORKERCOUNT", "7")
+
+		config := (&EnvConfig{FilePath: path}).Read()
+
+		assert.Equal(t, "9090", config.Server.Port)
+		assert.Equal(t, 7, config.SdkGeneration.WorkerCount)
+		assert.Equal(t, "https://file.example.com", config.Server.PublicUrl)
+		assert.Equal(t, "30s", config.SdkGeneration.PollInterval)
+	})
+
+	t.Run("config file variable is not read as a setting", func(t *testing.T) {
+		path := writeFile(t, "config.json", `{"server": {"port": "8080"}}`)
+		t.Setenv("MODE", "")
+		t.Setenv(ConfigFileEnv, path)
+
+		reader, ok := NewConfigReader().(*EnvConfig)
+		require.True(t, ok)
+		assert.Equal(t, path, reader.FilePath)
+
+		config := reader.Read()
+		assert.Equal(t, "8080", config.Server.Port)
+	})
+
+	t.Run("panics on unsupported file type", func(t *testing.T) {
+		path := writeFile(t, "config.toml", `port = 8080`)
+
+		assert.Panics(t, func() {
+			(&EnvConfig{FilePath: path}).Read()
+		})
+	})
+
+	t.Run("panics when file does not exist", func(t *testing.T) {
+		assert.Panics(t, func() {
+			(&EnvConfig{FilePath: filepath.Join(t.TempDir(), "missing.yaml")}).Read()
+		})
+	})
+}
+
 func TestJsonConfig_Read(t *testing.T) {
 	t.Run("reads config from json file", func(t *testing.T) {
 		tmpDir := t.TempDir()
@@ -306,3 +395,108 @@ func TestSdkGenerationConfig(t *testing.T) {
 		assert.Empty(t, config.SdkGeneration.OutputPath)
 	})
 }
+
+func TestSdkGenerationConfig_GetLeaseTimeout(t *testing.T) {
+	t.Run("defaults to 15 minutes", func(t *testing.T) {
+		leaseTimeout, err := SdkGenerationConfig{}.GetLeaseTimeout()
+		require.NoError(t, err)
+		assert.Equal(t, 15*time.Minute, leaseTimeout)
+	})
+
+	t.Run("parses configured value", func(t *testing.T) {
+		leaseTimeout, err := SdkGenerationConfig{LeaseTimeout: "45m"}.GetLeaseTimeout()
+		require.NoError(t, err)
+		assert.Equal(t, 45*time.Minute, leaseTimeout)
+	})
+
+	t.Run("rejects invalid value", func(t *testing.T) {
+		_, err := SdkGenerationConfig{LeaseTimeout: "forever"}.GetLeaseTimeout()
+		require.Error(t, err)
+	})
+}
+
+func TestPostgresConfig_GetQueryTimeouts(t *testing.T) {
+	t.Run("defaults", func(t *testing.T) {
+		pgc := &PostgresConfig{}
+
+		queryTimeout, err := pgc.GetQueryTimeout()
+		require.NoError(t, err)
+		assert.Equal(t, 5*time.Second, queryTimeout)
+
+		searchQueryTimeout, err := pgc.GetSearchQueryTimeout()
+		require.NoError(t, err)
+		assert.Equal(t, 15*time.Second, searchQueryTimeout)
+	})
+
+	t.Run("parses configured values", func(t *testing.T) {
+		pgc := &PostgresConfig{QueryTimeout: "2s", SearchQueryTimeout: "30s"}
+
+		queryTimeout, err := pgc.GetQueryTimeout()
+		require.NoError(t, err)
+		assert.Equal(t, 2*time.Second, queryTimeout)
+
+		searchQueryTimeout, err := pgc.GetSearchQueryTimeout()
+		require.NoError(t, err)
+		assert.Equal(t, 30*time.Second, searchQueryTimeout)
+	})
+
+	t.Run("rejects invalid value", func(t *testing.T) {
+		_, err := (&PostgresConfig{QueryTimeout: "soon"}).GetQueryTimeout()
+		require.Error(t, err)
+	})
+}
+
+func TestSearchSimilarityThresholds_For(t *testing.T) {
+	t.Run("defaults", func(t *testing.T) {
+		var thresholds SearchSimilarityThresholds
+
+		assert.InDelta(t, 0.1, thresholds.For(3), 1e-9)
+		assert.InDelta(t, 0.2, thresholds.For(8), 1e-9)
+		assert.InDelta(t, 0.3, thresholds.For(40), 1e-9)
+	})
+
+	t.Run("picks the longest reached entry regardless of order", func(t *testing.T) {
+		thresholds := SearchSimilarityThresholds{
+			{MinQueryLength: 12, Threshold: 0.5},
+			{MinQueryLength: 4, Threshold: 0.25},
+		}
+
+		assert.InDelta(t, 0.1, thresholds.For(3), 1e-9)
+		assert.InDelta(t, 0.25, thresholds.For(11), 1e-9)
+		assert.InDelta(t, 0.5, thresholds.For(12), 1e-9)
+	})
+}
+
+func TestOrganizationConfig_GetInviteTTL(t *testing.T) {
+	ttl, err := OrganizationConfig{}.GetInviteTTL()
+	require.NoError(t, err)
+	assert.Equal(t, 7*24*time.Hour, ttl)
+
+	ttl, err = OrganizationConfig{InviteTTL: "48h"}.GetInviteTTL()
+	require.NoError(t, err)
+	assert.Equal(t, 48*time.Hour, ttl)
+}
+
+func TestSdkGenerationConfig_WorkerDefaults(t *testing.T) {
+	sdk := SdkGenerationConfig{}
+
+	pollInterval, err := sdk.GetPollInterval()
+	require.NoError(t, err)
+	assert.Equal(t, 10*time.Second, pollInterval)
+	assert.Equal(t, 5, sdk.GetWorkerCount())
+
+	sdk = SdkGenerationConfig{PollInterval: "30s", WorkerCount: 2}
+
+	pollInterval, err = sdk.GetPollInterval()
+	require.NoError(t, err)
+	assert.Equal(t, 30*time.Second, pollInterval)
+	assert.Equal(t, 2, sdk.GetWorkerCount())
+}
+
+func TestSmtpConfig_GetSecurity(t *testing.T) {
+	assert.Equal(t, SmtpSecurityStartTls, SmtpConfig{Port: 587, UseTLS: true}.GetSecurity())
+	assert.Equal(t, SmtpSecurityTls, SmtpConfig{Port: 465}.GetSecurity())
+	assert.Equal(t, SmtpSecurityTls, SmtpConfig{Port: 2465, UseTLS: true}.GetSecurity())
+	assert.Equal(t, SmtpSecurityStartTls, SmtpConfig{Port: 25}.GetSecurity())
+	assert.Equal(t, SmtpSecurityNone, SmtpConfig{Port: 465, Security: SmtpSecurityNone}.GetSecurity())
+}
@@ -0,0 +1,63 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+type storagePath struct {
+	name string
+	path string
+}
+
+// CheckStoragePaths makes sure every directory the server writes to exists and
+// is writable, so a bad storage layout fails at startup instead of on the
+// first push or SDK build.
+func (c *Config) CheckStoragePaths() error {
+	dirs := []storagePath{
+		{name: "repository.path", path: c.Repository.GetPath()},
+		{name: "sdkGeneration.outputPath", path: c.SdkGeneration.GetOutputPath()},
+		{name: "sdkGeneration.docsPath", path: c.SdkGeneration.GetDocsPath()},
+	}
+	if c.Ssh.Enabled {
+		dirs = append(dirs, storagePath{name: "ssh.hostKeyPath", path: filepath.Dir(c.Ssh.GetHostKeyPath())})
+	}
+
+	var errs []error
+	for _, dir := range dirs {
+		if err := ensureWritableDir(dir.path); err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", dir.name, err))
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+func ensureWritableDir(path string) error {
+	if err := os.MkdirAll(path, 0o750); err != nil {
+		return fmt.Errorf("cannot create directory %q: %w", path, err)
+	}
+
+	info, err := os.Stat(path)
+	if err != nil {
+		return fmt.Errorf("cannot stat %q: %w", path, err)
+	}
+	if !info.IsDir() {
+		return fmt.Errorf("%q is not a directory", path)
+	}
+
+	probe, err := os.CreateTemp(path, ".hasir-write-check-*")
+	if err != nil {
+		return fmt.Errorf("directory %q is not writable: %w", path, err)
+	}
+
+	probePath := probe.Name()
+	_ = probe.Close()
+	if err := os.Remove(probePath); err != nil {
+		return fmt.Errorf("cannot clean up write check in %q: %w", path, err)
+	}
+
+	return nil
+}
@@ -0,0 +1,106 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestStoragePathDefaults(t *testing.T) {
+	cfg := &Config{}
+
+	assert.Equal(t, "./repos", cfg.Repository.GetPath())
+	assert.Equal(t, "./sdk", cfg.SdkGeneration.GetOutputPath())
+	assert.Equal(t, "./sdk", cfg.SdkGeneration.GetDocsPath())
+	assert.Equal(t, "./ssh_host_key", cfg.Ssh.GetHostKeyPath())
+
+	cfg.SdkGeneration.OutputPath = "/data/sdk"
+	assert.Equal(t, "/data/sdk", cfg.SdkGeneration.GetDocsPath())
+
+	cfg.SdkGeneration.DocsPath = "/data/docs"
+	assert.Equal(t, "/data/docs", cfg.SdkGeneration.GetDocsPath())
+}
+
+func TestConfig_CheckStoragePaths(t *testing.T) {
+	newConfig := func(root string) *Config {
+		return &Config{
+			Repository:    RepositoryConfig{Path: filepath.Join(root, "repos")},
+			SdkGeneration: SdkGenerationConfig{OutputPath: filepath.Join(root, "sdk")},
+			Ssh:           SshConfig{Enabled: true, HostKeyPath: filepath.Join(root, "keys", "ssh_host_key")},
+		}
+	}
+
+	t.Run("creates missing directories", func(t *testing.T) {
+		root := t.TempDir()
+
+		require.NoError(t, newConfig(root).CheckStoragePaths())
+
+		assert.DirExists(t, filepath.Join(root, "repos"))
+		assert.DirExists(t, filepath.Join(root, "sdk"))
+		assert.DirExists(t, filepath.Join(root, "keys"))
+
+		entries, err := os.ReadDir(filepath.Join(root, "repos"))
+		require.NoError(t, err)
+		assert.Empty(t, entries, "write check must not leave files behind")
+	})
+
+	t.Run("fails when path is a file", func(t *testing.T) {
+		root := t.TempDir()
+		cfg := newConfig(root)
+		require.NoError(t, os.WriteFile(cfg.Repository.Path, []byte("not a dir"), 0o600))
+
+		err := cfg.CheckStoragePaths()
+
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "repository.path")
+	})
+
+	t.Run("fails when parent is a file", func(t *testing.T) {
+		root := t.TempDir()
+		blocker := filepath.Join(root, "blocker")
+		require.NoError(t, os.WriteFile(blocker, nil, 0o600))
+
+		cfg := newConfig(root)
+		cfg.SdkGeneration.DocsPath = filepath.Join(blocker, "docs")
+
+		err := cfg.CheckStoragePaths()
+
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "sdkGeneration.docsPath")
+		assert.NotContains(t, err.Error(), "repository.path")
+	})
+
+	t.Run("fails when directory is not writable", func(t *testing.T) {
+		if os.Geteuid() == 0 {
+			t.Skip("permission bits are not enforced for root")
+		}
+
+		root := t.TempDir()
+		cfg := newConfig(root)
+		require.NoError(t, os.MkdirAll(cfg.SdkGeneration.OutputPath, 0o500))
+		t.Cleanup(func() {
+			_ = os.Chmod(cfg.SdkGeneration.OutputPath, 0o700)
+		})
+
+		err := cfg.CheckStoragePaths()
+
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "sdkGeneration.outputPath")
+		assert.Contains(t, err.Error(), "not writable")
+	})
+
+	t.Run("skips host key directory when ssh is disabled", func(t *testing.T) {
+		root := t.TempDir()
+		blocker := filepath.Join(root, "blocker")
+		require.NoError(t, os.WriteFile(blocker, nil, 0o600))
+
+		cfg := newConfig(root)
+		cfg.Ssh.Enabled = false
+		cfg.Ssh.HostKeyPath = filepath.Join(blocker, "ssh_host_key")
+
+		require.NoError(t, cfg.CheckStoragePaths())
+	})
+}
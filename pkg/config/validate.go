@@ -0,0 +1,219 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"go.uber.org/zap/zapcore"
+	"golang.org/x/crypto/bcrypt"
+)
+
+// Validate checks the whole configuration and reports every problem at once,
+// each prefixed with its config key, so a broken deployment can be fixed in a
+// single pass instead of failing on first use.
+func (c *Config) Validate() error {
+	var problems []error
+	add := func(key, format string, args ...any) {
+		problems = append(problems, fmt.Errorf("%s: %s", key, fmt.Sprintf(format, args...)))
+	}
+	checkDuration := func(key string, parse func() (time.Duration, error)) {
+		if _, err := parse(); err != nil {
+			add(key, "%v", err)
+		}
+	}
+	checkPort := func(key, value string, required bool) {
+		if value == "" {
+			if required {
+				add(key, "is required")
+			}
+			return
+		}
+		if err := validatePort(value); err != nil {
+			add(key, "%v", err)
+		}
+	}
+
+	if len(c.JwtSecret) == 0 {
+		add("jwtSecret", "is required")
+	}
+
+	if c.PasswordHashCost != 0 && (c.PasswordHashCost < bcrypt.MinCost || c.PasswordHashCost > bcrypt.MaxCost) {
+		add("passwordHashCost", "must be between %d and %d, got %d", bcrypt.MinCost, bcrypt.MaxCost, c.PasswordHashCost)
+	}
+
+	checkPort("server.port", c.Server.Port, true)
+	checkPort("server.sshPort", c.Server.SshPort, false)
+	if c.Server.PublicUrl != "" {
+		if u, err := url.Parse(c.Server.PublicUrl); err != nil || u.Scheme == "" || u.Host == "" {
+			add("server.publicUrl", "must be an absolute URL, got %q", c.Server.PublicUrl)
+		}
+	}
+
+	checkDuration("server.requestTimeout", c.Server.GetRequestTimeout)
+	checkDuration("server.requestQueueTimeout", c.Server.GetRequestQueueTimeout)
+	if c.Server.MaxConcurrentRequests < 0 {
+		add("server.maxConcurrentRequests", "must not be negative")
+	}
+	if c.Server.MaxQueuedRequests < 0 {
+		add("server.maxQueuedRequests", "must not be negative")
+	}
+	if c.Server.MaxConcurrentGitRequests < 0 {
+		add("server.maxConcurrentGitRequests", "must not be negative")
+	}
+	if _, err := c.Server.GetProcedureTimeouts(); err != nil {
+		add("server.procedureTimeouts", "%v", err)
+	}
+
+	if c.PostgresConfig.ConnectionString == "" {
+		if c.PostgresConfig.Host == "" {
+			add("postgresql.host", "is required when connectionString is not set")
+		}
+		checkPort("postgresql.port", c.PostgresConfig.Port, true)
+		if c.PostgresConfig.Username == "" {
+			add("postgresql.username", "is required when connectionString is not set")
+		}
+		if c.PostgresConfig.Database == "" {
+			add("postgresql.database", "is required when connectionString is not set")
+		}
+	}
+	checkDuration("postgresql.queryTimeout", c.PostgresConfig.GetQueryTimeout)
+	checkDuration("postgresql.searchQueryTimeout", c.PostgresConfig.GetSearchQueryTimeout)
+	for _, entry := range c.PostgresConfig.SearchSimilarityThresholds {
+		if entry.MinQueryLength < 0 {
+			add("postgresql.searchSimilarityThresholds", "minQueryLength must not be negative, got %d", entry.MinQueryLength)
+		}
+		if entry.Threshold < 0 || entry.Threshold > 1 {
+			add("postgresql.searchSimilarityThresholds", "threshold must be between 0 and 1, got %g", entry.Threshold)
+		}
+	}
+
+	if c.Smtp.Host != "" && (c.Smtp.Port < 1 || c.Smtp.Port > 65535) {
+		add("smtp.port", "must be between 1 and 65535, got %d", c.Smtp.Port)
+	}
+	switch c.Smtp.GetSecurity() {
+	case SmtpSecurityStartTls, SmtpSecurityTls, SmtpSecurityNone:
+	default:
+		add("smtp.security", "must be starttls, tls or none, got %q", c.Smtp.Security)
+	}
+
+	if c.Ssh.Enabled {
+		checkPort("ssh.port", c.Ssh.Port, true)
+	}
+	checkDuration("ssh.idleTimeout", c.Ssh.GetIdleTimeout)
+	checkDuration("ssh.maxTimeout", c.Ssh.GetMaxTimeout)
+	checkDuration("ssh.handshakeTimeout", c.Ssh.GetHandshakeTimeout)
+	if c.Ssh.MaxSessions < 0 {
+		add("ssh.maxSessions", "must not be negative")
+	}
+	for _, keyType := range c.Ssh.AllowedKeyTypes {
+		if !slices.Contains(supportedSshKeyTypes, keyType) {
+			add("ssh.allowedKeyTypes", "unsupported key type %q", keyType)
+		}
+	}
+	if c.Ssh.MinRsaKeyBits < 0 {
+		add("ssh.minRsaKeyBits", "must not be negative")
+	}
+
+	checkDuration("sdkGeneration.pollInterval", c.SdkGeneration.GetPollInterval)
+	checkDuration("sdkGeneration.leaseTimeout", c.SdkGeneration.GetLeaseTimeout)
+	if c.SdkGeneration.WorkerCount < 0 {
+		add("sdkGeneration.workerCount", "must not be negative")
+	}
+	for _, name := range c.SdkGeneration.AllowedSdks {
+		if !slices.ContainsFunc(SupportedSdks, func(sdk string) bool { return strings.EqualFold(sdk, name) }) {
+			add("sdkGeneration.allowedSdks", "unknown SDK %q", name)
+		}
+	}
+
+	if c.Repository.MaxPreviewSize < 0 {
+		add("repository.maxPreviewSize", "must not be negative")
+	}
+	if c.Repository.MaxFileTreeDepth < 0 {
+		add("repository.maxFileTreeDepth", "must not be negative")
+	}
+	checkDuration("repository.pushLockTimeout", c.Repository.GetPushLockTimeout)
+	if c.Repository.MaxConcurrentClonesPerUser < 0 {
+		add("repository.maxConcurrentClonesPerUser", "must not be negative")
+	}
+	if c.Organization.MaxMembers < 0 {
+		add("organization.maxMembers", "must not be negative")
+	}
+	if c.Organization.MaxRepositories < 0 {
+		add("organization.maxRepositories", "must not be negative")
+	}
+	if c.Organization.MaxInvitesPerRequest < 0 {
+		add("organization.maxInvitesPerRequest", "must not be negative")
+	}
+	if ttl, err := c.Organization.GetInviteTTL(); err != nil {
+		add("organization.inviteTtl", "%v", err)
+	} else if ttl == 0 {
+		add("organization.inviteTtl", "must be positive")
+	}
+	if role := c.Organization.GetDefaultInviteRole(); role != "reader" && role != "author" {
+		add("organization.defaultInviteRole", "must be reader or author, got %q", role)
+	}
+
+	switch c.Migration.GetDirtyPolicy() {
+	case MigrationDirtyFail:
+	case MigrationDirtyForce:
+		if c.Migration.ForceVersion < 1 {
+			add("migration.forceVersion", "must be a positive migration version when dirtyPolicy is force")
+		}
+	default:
+		add("migration.dirtyPolicy", "must be fail or force, got %q", c.Migration.DirtyPolicy)
+	}
+
+	checkDuration("loginLockout.baseDuration", c.LoginLockout.GetBaseDuration)
+	checkDuration("loginLockout.maxDuration", c.LoginLockout.GetMaxDuration)
+
+	checkDuration("shutdown.httpTimeout", c.Shutdown.GetHttpTimeout)
+	checkDuration("shutdown.sshTimeout", c.Shutdown.GetSshTimeout)
+	checkDuration("shutdown.queueTimeout", c.Shutdown.GetQueueTimeout)
+	checkDuration("shutdown.traceTimeout", c.Shutdown.GetTraceTimeout)
+
+	checkDuration("retention.period", c.Retention.GetPeriod)
+	if interval, err := c.Retention.GetInterval(); err != nil {
+		add("retention.interval", "%v", err)
+	} else if interval == 0 {
+		add("retention.interval", "must be positive")
+	}
+	if c.Retention.BatchSize < 0 {
+		add("retention.batchSize", "must not be negative")
+	}
+
+	if _, err := c.GetReservedNames(); err != nil {
+		add("reservedNamesFile", "%v", err)
+	}
+
+	for _, procedure := range c.Totp.SensitiveProcedures {
+		if !strings.HasPrefix(procedure, "/") {
+			add("totp.sensitiveProcedures", "invalid procedure %q: must be a full procedure name", procedure)
+		}
+	}
+
+	if format := c.Log.GetFormat(); format != "json" && format != "console" {
+		add("log.format", "must be json or console, got %q", c.Log.Format)
+	}
+	if _, err := zapcore.ParseLevel(c.Log.GetLevel()); err != nil {
+		add("log.level", "unknown level %q", c.Log.Level)
+	}
+
+	return errors.Join(problems...)
+}
+
+func validatePort(value string) error {
+	port, err := strconv.Atoi(value)
+	if err != nil {
+		return fmt.Errorf("must be a number, got %q", value)
+	}
+	if port < 1 || port > 65535 {
+		return fmt.Errorf("must be between 1 and 65535, got %d", port)
+	}
+
+	return nil
+}
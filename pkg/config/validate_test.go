@@ -0,0 +1,271 @@
+package config
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func validConfig() *Config {
+	return &Config{
+		Server: ServerConfig{
+			PublicUrl: "https://hasir.example.com",
+			Port:      "8080",
+		},
+		PostgresConfig: PostgresConfig{
+			Host:     "localhost",
+			Port:     "5432",
+			Username: "postgres",
+			Database: "hasir",
+		},
+		Ssh: SshConfig{
+			Enabled: true,
+			Port:    "2222",
+		},
+		JwtSecret: []byte("secret"),
+	}
+}
+
+func TestConfig_Validate(t *testing.T) {
+	t.Run("valid config", func(t *testing.T) {
+		require.NoError(t, validConfig().Validate())
+	})
+
+	t.Run("connection string replaces discrete postgres settings", func(t *testing.T) {
+		cfg := validConfig()
+		cfg.PostgresConfig = PostgresConfig{ConnectionString: "postgres://localhost/hasir"}
+
+		require.NoError(t, cfg.Validate())
+	})
+
+	t.Run("example config is valid", func(t *testing.T) {
+		cfg := (&JsonConfig{ConfigPath: filepath.Join(getCwd(), "config.example.json")}).Read()
+
+		require.NoError(t, cfg.Validate())
+	})
+
+	tests := []struct {
+		name     string
+		mutate   func(cfg *Config)
+		expected []string
+	}{
+		{
+			name:     "missing jwt secret",
+			mutate:   func(cfg *Config) { cfg.JwtSecret = nil },
+			expected: []string{"jwtSecret: is required"},
+		},
+		{
+			name:     "password hash cost out of range",
+			mutate:   func(cfg *Config) { cfg.PasswordHashCost = 40 },
+			expected: []string{"passwordHashCost: must be between 4 and 31, got 40"},
+		},
+		{
+			name: "missing database settings",
+			mutate: func(cfg *Config) {
+				cfg.PostgresConfig.Host = ""
+				cfg.PostgresConfig.Database = ""
+			},
+			expected: []string{
+				"postgresql.host: is required when connectionString is not set",
+				"postgresql.database: is required when connectionString is not set",
+			},
+		},
+		{
+			name: "ports out of range",
+			mutate: func(cfg *Config) {
+				cfg.Server.Port = "70000"
+				cfg.Ssh.Port = "ssh"
+				cfg.Smtp = SmtpConfig{Host: "smtp.example.com", Port: 0}
+			},
+			expected: []string{
+				"server.port: must be between 1 and 65535, got 70000",
+				`ssh.port: must be a number, got "ssh"`,
+				"smtp.port: must be between 1 and 65535, got 0",
+			},
+		},
+		{
+			name: "invalid durations",
+			mutate: func(cfg *Config) {
+				cfg.Ssh.IdleTimeout = "forever"
+				cfg.SdkGeneration.PollInterval = "-1s"
+				cfg.PostgresConfig.QueryTimeout = "5"
+				cfg.Shutdown.QueueTimeout = "later"
+				cfg.Repository.PushLockTimeout = "soon"
+				cfg.Server.RequestQueueTimeout = "-1s"
+			},
+			expected: []string{
+				`ssh.idleTimeout: invalid duration "forever"`,
+				`sdkGeneration.pollInterval: invalid duration "-1s": must not be negative`,
+				`postgresql.queryTimeout: invalid duration "5"`,
+				`shutdown.queueTimeout: invalid duration "later"`,
+				`repository.pushLockTimeout: invalid duration "soon"`,
+				`server.requestQueueTimeout: invalid duration "-1s": must not be negative`,
+			},
+		},
+		{
+			name: "invalid request timeouts",
+			mutate: func(cfg *Config) {
+				cfg.Server.RequestTimeout = "soon"
+				cfg.Server.ProcedureTimeouts = []ProcedureTimeoutConfig{{Procedure: "GetFileTree", Timeout: "1m"}}
+			},
+			expected: []string{
+				`server.requestTimeout: invalid duration "soon"`,
+				`server.procedureTimeouts: invalid procedure "GetFileTree": must be a full procedure name`,
+			},
+		},
+		{
+			name:     "unknown allowed sdk",
+			mutate:   func(cfg *Config) { cfg.SdkGeneration.AllowedSdks = []string{"go_protobuf", "PYTHON_PROTOBUF"} },
+			expected: []string{`sdkGeneration.allowedSdks: unknown SDK "PYTHON_PROTOBUF"`},
+		},
+		{
+			name:     "owner as default invite role",
+			mutate:   func(cfg *Config) { cfg.Organization.DefaultInviteRole = "owner" },
+			expected: []string{`organization.defaultInviteRole: must be reader or author, got "owner"`},
+		},
+		{
+			name:     "unknown smtp security mode",
+			mutate:   func(cfg *Config) { cfg.Smtp.Security = "ssl" },
+			expected: []string{`smtp.security: must be starttls, tls or none, got "ssl"`},
+		},
+		{
+			name:     "missing reserved names file",
+			mutate:   func(cfg *Config) { cfg.ReservedNamesFile = "/nonexistent/reserved-names.txt" },
+			expected: []string{"reservedNamesFile: failed to open reserved names file"},
+		},
+		{
+			name: "invalid ssh key restrictions",
+			mutate: func(cfg *Config) {
+				cfg.Ssh.AllowedKeyTypes = []string{"ssh-ed25519", "ssh-rsa1"}
+				cfg.Ssh.MinRsaKeyBits = -1
+			},
+			expected: []string{
+				`ssh.allowedKeyTypes: unsupported key type "ssh-rsa1"`,
+				"ssh.minRsaKeyBits: must not be negative",
+			},
+		},
+		{
+			name: "invalid search similarity thresholds",
+			mutate: func(cfg *Config) {
+				cfg.PostgresConfig.SearchSimilarityThresholds = SearchSimilarityThresholds{
+					{MinQueryLength: -1, Threshold: 0.2},
+					{MinQueryLength: 10, Threshold: 1.5},
+				}
+			},
+			expected: []string{
+				"postgresql.searchSimilarityThresholds: minQueryLength must not be negative, got -1",
+				"postgresql.searchSimilarityThresholds: threshold must be between 0 and 1, got 1.5",
+			},
+		},
+		{
+			name: "invalid totp procedures",
+			mutate: func(cfg *Config) {
+				cfg.Totp.SensitiveProcedures = []string{"DeleteOrganization"}
+			},
+			expected: []string{
+				`totp.sensitiveProcedures: invalid procedure "DeleteOrganization": must be a full procedure name`,
+			},
+		},
+		{
+			name: "invalid log settings and public url",
+			mutate: func(cfg *Config) {
+				cfg.Log = LogConfig{Format: "xml", Level: "loud"}
+				cfg.Server.PublicUrl = "hasir.example.com"
+			},
+			expected: []string{
+				`log.format: must be json or console, got "xml"`,
+				`log.level: unknown level "loud"`,
+				`server.publicUrl: must be an absolute URL, got "hasir.example.com"`,
+			},
+		},
+		{
+			name: "negative limits",
+			mutate: func(cfg *Config) {
+				cfg.Organization.MaxMembers = -1
+				cfg.Organization.MaxRepositories = -1
+				cfg.Repository.MaxPreviewSize = -1
+				cfg.Repository.MaxFileTreeDepth = -1
+				cfg.Repository.MaxConcurrentClonesPerUser = -1
+				cfg.Server.MaxConcurrentRequests = -1
+				cfg.Server.MaxQueuedRequests = -1
+				cfg.Server.MaxConcurrentGitRequests = -1
+			},
+			expected: []string{
+				"organization.maxMembers: must not be negative",
+				"organization.maxRepositories: must not be negative",
+				"repository.maxPreviewSize: must not be negative",
+				"repository.maxFileTreeDepth: must not be negative",
+				"repository.maxConcurrentClonesPerUser: must not be negative",
+				"server.maxConcurrentRequests: must not be negative",
+				"server.maxQueuedRequests: must not be negative",
+				"server.maxConcurrentGitRequests: must not be negative",
+			},
+		},
+		{
+			name: "zero invite ttl",
+			mutate: func(cfg *Config) {
+				cfg.Organization.InviteTTL = "0s"
+			},
+			expected: []string{
+				"organization.inviteTtl: must be positive",
+			},
+		},
+		{
+			name: "invalid retention",
+			mutate: func(cfg *Config) {
+				cfg.Retention.Period = "a month"
+				cfg.Retention.Interval = "0s"
+				cfg.Retention.BatchSize = -1
+			},
+			expected: []string{
+				`retention.period: invalid duration "a month": time: invalid duration "a month"`,
+				"retention.interval: must be positive",
+				"retention.batchSize: must not be negative",
+			},
+		},
+		{
+			name: "unknown migration dirty policy",
+			mutate: func(cfg *Config) {
+				cfg.Migration.DirtyPolicy = "ignore"
+			},
+			expected: []string{
+				`migration.dirtyPolicy: must be fail or force, got "ignore"`,
+			},
+		},
+		{
+			name: "force policy without a version",
+			mutate: func(cfg *Config) {
+				cfg.Migration.DirtyPolicy = MigrationDirtyForce
+			},
+			expected: []string{
+				"migration.forceVersion: must be a positive migration version when dirtyPolicy is force",
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := validConfig()
+			tt.mutate(cfg)
+
+			err := cfg.Validate()
+			require.Error(t, err)
+
+			lines := strings.Split(err.Error(), "\n")
+			assert.Len(t, lines, len(tt.expected), err.Error())
+			for _, expected := range tt.expected {
+				assert.Contains(t, err.Error(), expected)
+			}
+		})
+	}
+
+	t.Run("ssh port is optional when ssh is disabled", func(t *testing.T) {
+		cfg := validConfig()
+		cfg.Ssh = SshConfig{}
+
+		require.NoError(t, cfg.Validate())
+	})
+}
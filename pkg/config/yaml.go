@@ -0,0 +1,19 @@
+package config
+
+import "gopkg.in/yaml.v3"
+
+// yamlParser implements koanf.Parser on top of yaml.v3.
+type yamlParser struct{}
+
+func (yamlParser) Unmarshal(b []byte) (map[string]any, error) {
+	out := map[string]any{}
+	if err := yaml.Unmarshal(b, &out); err != nil {
+		return nil, err
+	}
+
+	return out, nil
+}
+
+func (yamlParser) Marshal(o map[string]any) ([]byte, error) {
+	return yaml.Marshal(o)
+}
@@ -1,9 +1,20 @@
 package email
 
 import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
 	"html/template"
+	"math/big"
+	"net"
+	"net/textproto"
 	"strings"
+	"sync"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -237,7 +248,7 @@ func TestSendEmail_PortConfigurations(t *testing.T) {
 			useTLS: true,
 		},
 		{
-			name:   "default port uses SendMail",
+			name:   "default port uses STARTTLS",
 			port:   25,
 			useTLS: false,
 		},
@@ -355,3 +366,210 @@ func TestSendEmail_MessageFormat(t *testing.T) {
 
 	assert.Error(t, err)
 }
+
+// fakeSmtpServer accepts a single connection and records the commands it
+// receives, upgrading to TLS when the client asks for STARTTLS.
+type fakeSmtpServer struct {
+	listener      net.Listener
+	offerStartTls bool
+	tlsConfig     *tls.Config
+
+	mu       sync.Mutex
+	commands []string
+	mailOver string
+	data     string
+	done     chan struct{}
+}
+
+func newFakeSmtpServer(t *testing.T, offerStartTls bool) *fakeSmtpServer {
+	t.Helper()
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	t.Cleanup(func() {
+		_ = listener.Close()
+	})
+
+	server := &fakeSmtpServer{
+		listener:      listener,
+		offerStartTls: offerStartTls,
+		tlsConfig:     &tls.Config{Certificates: []tls.Certificate{selfSignedCertificate(t)}, MinVersion: tls.VersionTLS12},
+		done:          make(chan struct{}),
+	}
+	go server.serve()
+
+	return server
+}
+
+func (f *fakeSmtpServer) port() int {
+	return f.listener.Addr().(*net.TCPAddr).Port
+}
+
+func (f *fakeSmtpServer) serve() {
+	defer close(f.done)
+
+	conn, err := f.listener.Accept()
+	if err != nil {
+		return
+	}
+	defer func() {
+		_ = conn.Close()
+	}()
+
+	text := textproto.NewConn(conn)
+	_ = text.PrintfLine("220 fake ESMTP")
+
+	encrypted := false
+	for {
+		line, err := text.ReadLine()
+		if err != nil {
+			return
+		}
+
+		verb := strings.ToUpper(strings.Fields(line + " ")[0])
+		f.mu.Lock()
+		f.commands = append(f.commands, verb)
+		f.mu.Unlock()
+
+		switch verb {
+		case "EHLO":
+			if f.offerStartTls && !encrypted {
+				_ = text.PrintfLine("250-fake")
+				_ = text.PrintfLine("250 STARTTLS")
+			} else {
+				_ = text.PrintfLine("250 fake")
+			}
+		case "STARTTLS":
+			_ = text.PrintfLine("220 ready")
+			tlsConn := tls.Server(conn, f.tlsConfig)
+			if err := tlsConn.Handshake(); err != nil {
+				return
+			}
+			conn = tlsConn
+			text = textproto.NewConn(tlsConn)
+			encrypted = true
+		case "MAIL":
+			f.mu.Lock()
+			f.mailOver = "plaintext"
+			if encrypted {
+				f.mailOver = "tls"
+			}
+			f.mu.Unlock()
+			_ = text.PrintfLine("250 ok")
+		case "RCPT":
+			_ = text.PrintfLine("250 ok")
+		case "DATA":
+			_ = text.PrintfLine("354 go ahead")
+			data, err := text.ReadDotBytes()
+			if err != nil {
+				return
+			}
+			f.mu.Lock()
+			f.data = string(data)
+			f.mu.Unlock()
+			_ = text.PrintfLine("250 ok")
+		case "QUIT":
+			_ = text.PrintfLine("221 bye")
+			return
+		default:
+			_ = text.PrintfLine("502 not implemented")
+		}
+	}
+}
+
+func (f *fakeSmtpServer) result(t *testing.T) (commands []string, mailOver, data string) {
+	t.Helper()
+
+	select {
+	case <-f.done:
+	case <-time.After(5 * time.Second):
+		t.Fatal("fake SMTP server did not finish")
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.commands, f.mailOver, f.data
+}
+
+func selfSignedCertificate(t *testing.T) tls.Certificate {
+	t.Helper()
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+
+	certTemplate := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: "127.0.0.1"},
+		IPAddresses:  []net.IP{net.ParseIP("127.0.0.1")},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		KeyUsage:     x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+	}
+	der, err := x509.CreateCertificate(rand.Reader, certTemplate, certTemplate, &key.PublicKey, key)
+	require.NoError(t, err)
+
+	return tls.Certificate{Certificate: [][]byte{der}, PrivateKey: key}
+}
+
+func TestSendEmail_SecurityModes(t *testing.T) {
+	newSmtpService := func(server *fakeSmtpServer, security string, insecureSkipVerify bool) *smtpService {
+		return NewService(&config.Config{
+			Smtp: config.SmtpConfig{
+				Host:               "127.0.0.1",
+				Port:               server.port(),
+				From:               "no-reply@example.com",
+				Security:           security,
+				InsecureSkipVerify: insecureSkipVerify,
+			},
+		}).(*smtpService)
+	}
+
+	t.Run("starttls upgrades before sending", func(t *testing.T) {
+		server := newFakeSmtpServer(t, true)
+		svc := newSmtpService(server, config.SmtpSecurityStartTls, true)
+
+		require.NoError(t, svc.sendEmail("test@example.com", "Subject", "hello", false))
+
+		commands, mailOver, data := server.result(t)
+		assert.Equal(t, []string{"EHLO", "STARTTLS", "EHLO", "MAIL", "RCPT", "DATA", "QUIT"}, commands)
+		assert.Equal(t, "tls", mailOver)
+		assert.Contains(t, data, "hello")
+	})
+
+	t.Run("starttls refuses a server that cannot upgrade", func(t *testing.T) {
+		server := newFakeSmtpServer(t, false)
+		svc := newSmtpService(server, config.SmtpSecurityStartTls, true)
+
+		err := svc.sendEmail("test@example.com", "Subject", "hello", false)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "does not support STARTTLS")
+
+		commands, _, _ := server.result(t)
+		assert.NotContains(t, commands, "MAIL")
+	})
+
+	t.Run("starttls verifies the certificate by default", func(t *testing.T) {
+		server := newFakeSmtpServer(t, true)
+		svc := newSmtpService(server, config.SmtpSecurityStartTls, false)
+
+		err := svc.sendEmail("test@example.com", "Subject", "hello", false)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "failed to start TLS")
+
+		commands, _, _ := server.result(t)
+		assert.NotContains(t, commands, "MAIL")
+	})
+
+	t.Run("none never attempts a handshake", func(t *testing.T) {
+		server := newFakeSmtpServer(t, true)
+		svc := newSmtpService(server, config.SmtpSecurityNone, false)
+
+		require.NoError(t, svc.sendEmail("test@example.com", "Subject", "hello", false))
+
+		commands, mailOver, data := server.result(t)
+		assert.NotContains(t, commands, "STARTTLS")
+		assert.Equal(t, "plaintext", mailOver)
+		assert.Contains(t, data, "hello")
+	})
+}
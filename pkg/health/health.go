@@ -0,0 +1,115 @@
+package health
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// Check reports why a subsystem cannot serve traffic, or nil.
+type Check func(ctx context.Context) error
+
+type namedCheck struct {
+	name  string
+	check Check
+}
+
+// Readiness serves /readyz: 200 when every registered check passes, 503
+// otherwise, with the result of each check in the body.
+type Readiness struct {
+	timeout time.Duration
+	checks  []namedCheck
+}
+
+type ReadinessResponse struct {
+	Ready  bool              `json:"ready"`
+	Checks map[string]string `json:"checks"`
+}
+
+func NewReadiness(timeout time.Duration) *Readiness {
+	return &Readiness{timeout: timeout}
+}
+
+// Add registers a check; it must be called before the handler serves.
+func (r *Readiness) Add(name string, check Check) {
+	r.checks = append(r.checks, namedCheck{name: name, check: check})
+}
+
+func (r *Readiness) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	ctx, cancel := context.WithTimeout(req.Context(), r.timeout)
+	defer cancel()
+
+	response := ReadinessResponse{
+		Ready:  true,
+		Checks: make(map[string]string, len(r.checks)),
+	}
+
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	for _, c := range r.checks {
+		wg.Go(func() {
+			err := c.check(ctx)
+
+			mu.Lock()
+			defer mu.Unlock()
+			if err != nil {
+				response.Ready = false
+				response.Checks[c.name] = err.Error()
+				return
+			}
+			response.Checks[c.name] = "ok"
+		})
+	}
+	wg.Wait()
+
+	status := http.StatusOK
+	if !response.Ready {
+		status = http.StatusServiceUnavailable
+		zap.L().Warn("readiness check failed", zap.Any("checks", response.Checks))
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	if err := json.NewEncoder(w).Encode(response); err != nil {
+		zap.L().Error("failed to encode readiness response", zap.Error(err))
+	}
+}
+
+// SshBannerCheck dials addr and expects an SSH version banner, so a port
+// held by something other than our SSH server does not count as ready.
+func SshBannerCheck(addr string) Check {
+	return func(ctx context.Context) error {
+		var dialer net.Dialer
+		conn, err := dialer.DialContext(ctx, "tcp", addr)
+		if err != nil {
+			return fmt.Errorf("ssh server is not accepting connections: %w", err)
+		}
+		defer func() {
+			_ = conn.Close()
+		}()
+
+		if deadline, ok := ctx.Deadline(); ok {
+			if err := conn.SetReadDeadline(deadline); err != nil {
+				return err
+			}
+		}
+
+		banner, err := bufio.NewReader(conn).ReadString('\n')
+		if err != nil {
+			return fmt.Errorf("failed to read ssh banner: %w", err)
+		}
+		if !strings.HasPrefix(banner, "SSH-") {
+			return fmt.Errorf("unexpected ssh banner %q", strings.TrimSpace(banner))
+		}
+
+		return nil
+	}
+}
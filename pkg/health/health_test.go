@@ -0,0 +1,111 @@
+package health
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// closedAddr returns an address nothing is listening on.
+func closedAddr(t *testing.T) string {
+	t.Helper()
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	addr := listener.Addr().String()
+	require.NoError(t, listener.Close())
+
+	return addr
+}
+
+func serveBanner(t *testing.T, banner string) string {
+	t.Helper()
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	t.Cleanup(func() {
+		_ = listener.Close()
+	})
+
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			_, _ = conn.Write([]byte(banner))
+			_ = conn.Close()
+		}
+	}()
+
+	return listener.Addr().String()
+}
+
+func getReadiness(t *testing.T, readiness *Readiness) (int, ReadinessResponse) {
+	t.Helper()
+
+	w := httptest.NewRecorder()
+	readiness.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/readyz", nil))
+
+	var resp ReadinessResponse
+	require.NoError(t, json.NewDecoder(w.Body).Decode(&resp))
+
+	return w.Code, resp
+}
+
+func TestReadiness(t *testing.T) {
+	passing := func(context.Context) error { return nil }
+
+	t.Run("ready when every check passes", func(t *testing.T) {
+		readiness := NewReadiness(time.Second)
+		readiness.Add("database", passing)
+		readiness.Add("ssh", SshBannerCheck(serveBanner(t, "SSH-2.0-Go\r\n")))
+
+		status, resp := getReadiness(t, readiness)
+
+		assert.Equal(t, http.StatusOK, status)
+		assert.True(t, resp.Ready)
+		assert.Equal(t, map[string]string{"database": "ok", "ssh": "ok"}, resp.Checks)
+	})
+
+	t.Run("ssh enabled but not listening", func(t *testing.T) {
+		readiness := NewReadiness(time.Second)
+		readiness.Add("database", passing)
+		readiness.Add("ssh", SshBannerCheck(closedAddr(t)))
+
+		status, resp := getReadiness(t, readiness)
+
+		assert.Equal(t, http.StatusServiceUnavailable, status)
+		assert.False(t, resp.Ready)
+		assert.Equal(t, "ok", resp.Checks["database"])
+		assert.Contains(t, resp.Checks["ssh"], "ssh server is not accepting connections")
+	})
+
+	t.Run("port held by something else", func(t *testing.T) {
+		readiness := NewReadiness(time.Second)
+		readiness.Add("ssh", SshBannerCheck(serveBanner(t, "HTTP/1.1 400 Bad Request\r\n")))
+
+		status, resp := getReadiness(t, readiness)
+
+		assert.Equal(t, http.StatusServiceUnavailable, status)
+		assert.Contains(t, resp.Checks["ssh"], "unexpected ssh banner")
+	})
+
+	t.Run("failing database check", func(t *testing.T) {
+		readiness := NewReadiness(time.Second)
+		readiness.Add("database", func(context.Context) error { return errors.New("connection refused") })
+
+		status, resp := getReadiness(t, readiness)
+
+		assert.Equal(t, http.StatusServiceUnavailable, status)
+		assert.Equal(t, "connection refused", resp.Checks["database"])
+	})
+}
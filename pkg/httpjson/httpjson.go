@@ -0,0 +1,55 @@
+// Package httpjson writes the responses of the plain HTTP endpoints that sit
+// next to the Connect services, so their errors match what the same service
+// call would return over RPC.
+package httpjson
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"connectrpc.com/connect"
+	"go.uber.org/zap"
+)
+
+// WriteError maps a service error to its HTTP status. Client errors carry the
+// service's message and any Retry-After it set; anything else is logged with
+// logMessage and hidden behind a generic 500.
+func WriteError(w http.ResponseWriter, logMessage string, err error) {
+	var status int
+	switch connect.CodeOf(err) {
+	case connect.CodeUnauthenticated:
+		status = http.StatusUnauthorized
+	case connect.CodePermissionDenied:
+		status = http.StatusForbidden
+	case connect.CodeNotFound:
+		status = http.StatusNotFound
+	case connect.CodeInvalidArgument, connect.CodeFailedPrecondition:
+		status = http.StatusBadRequest
+	case connect.CodeResourceExhausted:
+		status = http.StatusTooManyRequests
+	default:
+		zap.L().Error(logMessage, zap.Error(err))
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		return
+	}
+
+	message := err.Error()
+	var connectErr *connect.Error
+	if errors.As(err, &connectErr) {
+		message = connectErr.Message()
+		if retryAfter := connectErr.Meta().Get("Retry-After"); retryAfter != "" {
+			w.Header().Set("Retry-After", retryAfter)
+		}
+	}
+	http.Error(w, message, status)
+}
+
+// Write encodes value as the JSON response body; what names it in the log if
+// encoding fails.
+func Write(w http.ResponseWriter, what string, value any) {
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(value); err != nil {
+		zap.L().Error("failed to encode "+what, zap.Error(err))
+	}
+}
@@ -0,0 +1,68 @@
+package httpjson
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"connectrpc.com/connect"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWriteError(t *testing.T) {
+	tests := []struct {
+		name   string
+		err    error
+		status int
+		body   string
+	}{
+		{
+			name:   "client error keeps the message",
+			err:    connect.NewError(connect.CodeFailedPrecondition, errors.New("not enrolled")),
+			status: http.StatusBadRequest,
+			body:   "not enrolled\n",
+		},
+		{
+			name:   "permission denied",
+			err:    connect.NewError(connect.CodePermissionDenied, errors.New("not an owner")),
+			status: http.StatusForbidden,
+			body:   "not an owner\n",
+		},
+		{
+			name:   "internal error is hidden",
+			err:    errors.New("connection refused"),
+			status: http.StatusInternalServerError,
+			body:   "Internal server error\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			WriteError(w, "failed", tt.err)
+
+			assert.Equal(t, tt.status, w.Code)
+			assert.Equal(t, tt.body, w.Body.String())
+		})
+	}
+
+	t.Run("resource exhausted forwards Retry-After", func(t *testing.T) {
+		err := connect.NewError(connect.CodeResourceExhausted, errors.New("locked"))
+		err.Meta().Set("Retry-After", "30")
+
+		w := httptest.NewRecorder()
+		WriteError(w, "failed", err)
+
+		assert.Equal(t, http.StatusTooManyRequests, w.Code)
+		assert.Equal(t, "30", w.Header().Get("Retry-After"))
+	})
+}
+
+func TestWrite(t *testing.T) {
+	w := httptest.NewRecorder()
+	Write(w, "value", map[string]int{"count": 2})
+
+	assert.Equal(t, "application/json", w.Header().Get("Content-Type"))
+	assert.JSONEq(t, `{"count":2}`, w.Body.String())
+}
@@ -1,26 +1,83 @@
 package log
 
 import (
+	"fmt"
 	"os"
 
 	"go.uber.org/zap"
 	"go.uber.org/zap/zapcore"
+
+	"hasir-api/pkg/config"
 )
 
 var logger *zap.Logger
 
 func init() {
+	logger = zap.Must(buildConfig(zapcore.InfoLevel, "json", nil).Build())
+
+	zap.ReplaceGlobals(logger)
+}
+
+// Configure replaces the global logger with one built from cfg. Until it is
+// called, the logger installed by init writes JSON at info level.
+func Configure(cfg config.LogConfig) error {
+	configured, err := NewLogger(cfg)
+	if err != nil {
+		return err
+	}
+
+	logger = configured
+	zap.ReplaceGlobals(logger)
+
+	return nil
+}
+
+func NewLogger(cfg config.LogConfig) (*zap.Logger, error) {
+	zapConfig, err := newZapConfig(cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	return zapConfig.Build()
+}
+
+func newZapConfig(cfg config.LogConfig) (zap.Config, error) {
+	level, err := zapcore.ParseLevel(cfg.GetLevel())
+	if err != nil {
+		return zap.Config{}, fmt.Errorf("invalid log level %q: %w", cfg.Level, err)
+	}
+
+	format := cfg.GetFormat()
+	if format != "json" && format != "console" {
+		return zap.Config{}, fmt.Errorf("invalid log format %q: must be json or console", cfg.Format)
+	}
+
+	var sampling *zap.SamplingConfig
+	if cfg.Sampling.Initial > 0 {
+		sampling = &zap.SamplingConfig{
+			Initial:    cfg.Sampling.Initial,
+			Thereafter: cfg.Sampling.Thereafter,
+		}
+	}
+
+	return buildConfig(level, format, sampling), nil
+}
+
+func buildConfig(level zapcore.Level, encoding string, sampling *zap.SamplingConfig) zap.Config {
 	encoderCfg := zap.NewProductionEncoderConfig()
 	encoderCfg.TimeKey = "timestamp"
 	encoderCfg.EncodeTime = zapcore.ISO8601TimeEncoder
+	if encoding == "console" {
+		encoderCfg.EncodeLevel = zapcore.CapitalLevelEncoder
+	}
 
-	config := zap.Config{
-		Level:             zap.NewAtomicLevelAt(zap.InfoLevel),
+	return zap.Config{
+		Level:             zap.NewAtomicLevelAt(level),
 		Development:       false,
 		DisableCaller:     false,
 		DisableStacktrace: false,
-		Sampling:          nil,
-		Encoding:          "json",
+		Sampling:          sampling,
+		Encoding:          encoding,
 		EncoderConfig:     encoderCfg,
 		OutputPaths: []string{
 			"stderr",
@@ -32,8 +89,4 @@ func init() {
 			"pid": os.Getpid(),
 		},
 	}
-
-	logger = zap.Must(config.Build())
-
-	zap.ReplaceGlobals(logger)
 }
@@ -0,0 +1,66 @@
+package log
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap/zapcore"
+
+	"hasir-api/pkg/config"
+)
+
+func TestNewZapConfig(t *testing.T) {
+	t.Run("defaults to json at info without sampling", func(t *testing.T) {
+		zapConfig, err := newZapConfig(config.LogConfig{})
+		require.NoError(t, err)
+
+		assert.Equal(t, "json", zapConfig.Encoding)
+		assert.Equal(t, zapcore.InfoLevel, zapConfig.Level.Level())
+		assert.Nil(t, zapConfig.Sampling)
+	})
+
+	t.Run("uses configured format and level", func(t *testing.T) {
+		zapConfig, err := newZapConfig(config.LogConfig{Format: "console", Level: "debug"})
+		require.NoError(t, err)
+
+		assert.Equal(t, "console", zapConfig.Encoding)
+		assert.Equal(t, zapcore.DebugLevel, zapConfig.Level.Level())
+	})
+
+	t.Run("enables sampling when initial is set", func(t *testing.T) {
+		zapConfig, err := newZapConfig(config.LogConfig{
+			Sampling: config.LogSamplingConfig{Initial: 100, Thereafter: 50},
+		})
+		require.NoError(t, err)
+
+		require.NotNil(t, zapConfig.Sampling)
+		assert.Equal(t, 100, zapConfig.Sampling.Initial)
+		assert.Equal(t, 50, zapConfig.Sampling.Thereafter)
+	})
+
+	t.Run("rejects unknown format", func(t *testing.T) {
+		_, err := newZapConfig(config.LogConfig{Format: "xml"})
+		require.Error(t, err)
+	})
+
+	t.Run("rejects unknown level", func(t *testing.T) {
+		_, err := newZapConfig(config.LogConfig{Level: "verbose"})
+		require.Error(t, err)
+	})
+}
+
+func TestNewLogger(t *testing.T) {
+	t.Run("honors configured level", func(t *testing.T) {
+		logger, err := NewLogger(config.LogConfig{Level: "warn"})
+		require.NoError(t, err)
+
+		assert.False(t, logger.Core().Enabled(zapcore.InfoLevel))
+		assert.True(t, logger.Core().Enabled(zapcore.WarnLevel))
+	})
+
+	t.Run("returns error for invalid config", func(t *testing.T) {
+		_, err := NewLogger(config.LogConfig{Format: "xml"})
+		require.Error(t, err)
+	})
+}
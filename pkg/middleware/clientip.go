@@ -0,0 +1,141 @@
+package middleware
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/http"
+	"strings"
+)
+
+type clientIPKey struct{}
+
+type ClientIPResolver struct {
+	trustedProxies []*net.IPNet
+}
+
+func NewClientIPResolver(trustedProxies []string) (*ClientIPResolver, error) {
+	resolver := &ClientIPResolver{}
+	for _, proxy := range trustedProxies {
+		proxy = strings.TrimSpace(proxy)
+		if proxy == "" {
+			continue
+		}
+
+		if !strings.Contains(proxy, "/") {
+			ip := net.ParseIP(proxy)
+			if ip == nil {
+				return nil, fmt.Errorf("invalid trusted proxy %q", proxy)
+			}
+			bits := 128
+			if ip.To4() != nil {
+				bits = 32
+			}
+			proxy = fmt.Sprintf("%s/%d", proxy, bits)
+		}
+
+		_, network, err := net.ParseCIDR(proxy)
+		if err != nil {
+			return nil, fmt.Errorf("invalid trusted proxy %q: %w", proxy, err)
+		}
+		resolver.trustedProxies = append(resolver.trustedProxies, network)
+	}
+
+	return resolver, nil
+}
+
+// Resolve returns the client IP for the request. Forwarding headers are only
+// honoured when the immediate peer is a trusted proxy; the chain is then walked
+// from the nearest hop outwards and the first untrusted address is the client.
+func (c *ClientIPResolver) Resolve(r *http.Request) string {
+	peer := parseIP(r.RemoteAddr)
+	if peer == nil {
+		return r.RemoteAddr
+	}
+
+	if !c.isTrusted(peer) {
+		return peer.String()
+	}
+
+	chain := forwardedChain(r.Header)
+	if len(chain) == 0 {
+		return peer.String()
+	}
+
+	for i := len(chain) - 1; i >= 0; i-- {
+		if !c.isTrusted(chain[i]) {
+			return chain[i].String()
+		}
+	}
+
+	return chain[0].String()
+}
+
+func (c *ClientIPResolver) isTrusted(ip net.IP) bool {
+	for _, network := range c.trustedProxies {
+		if network.Contains(ip) {
+			return true
+		}
+	}
+
+	return false
+}
+
+func ClientIP(resolver *ClientIPResolver) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			ctx := context.WithValue(r.Context(), clientIPKey{}, resolver.Resolve(r))
+			next.ServeHTTP(w, r.WithContext(ctx))
+		})
+	}
+}
+
+func ClientIPFromContext(ctx context.Context) string {
+	clientIP, _ := ctx.Value(clientIPKey{}).(string)
+	return clientIP
+}
+
+func forwardedChain(header http.Header) []net.IP {
+	var chain []net.IP
+
+	if forwarded := header.Values("Forwarded"); len(forwarded) > 0 {
+		for _, value := range forwarded {
+			for _, element := range strings.Split(value, ",") {
+				for _, pair := range strings.Split(element, ";") {
+					key, val, ok := strings.Cut(strings.TrimSpace(pair), "=")
+					if !ok || !strings.EqualFold(key, "for") {
+						continue
+					}
+					ip := parseIP(strings.Trim(val, `"`))
+					if ip == nil {
+						return nil
+					}
+					chain = append(chain, ip)
+				}
+			}
+		}
+
+		return chain
+	}
+
+	for _, value := range header.Values("X-Forwarded-For") {
+		for _, hop := range strings.Split(value, ",") {
+			ip := parseIP(strings.TrimSpace(hop))
+			if ip == nil {
+				return nil
+			}
+			chain = append(chain, ip)
+		}
+	}
+
+	return chain
+}
+
+func parseIP(value string) net.IP {
+	if host, _, err := net.SplitHostPort(value); err == nil {
+		value = host
+	}
+	value = strings.TrimSuffix(strings.TrimPrefix(value, "["), "]")
+
+	return net.ParseIP(value)
+}
@@ -0,0 +1,113 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestRequest(remoteAddr string, headers map[string]string) *http.Request {
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	req.RemoteAddr = remoteAddr
+	for key, value := range headers {
+		req.Header.Set(key, value)
+	}
+	return req
+}
+
+func TestNewClientIPResolver(t *testing.T) {
+	t.Run("accepts CIDRs and plain addresses", func(t *testing.T) {
+		_, err := NewClientIPResolver([]string{"10.0.0.0/8", "192.168.1.10", "::1"})
+		require.NoError(t, err)
+	})
+
+	t.Run("rejects invalid entries", func(t *testing.T) {
+		_, err := NewClientIPResolver([]string{"not-an-ip"})
+		require.Error(t, err)
+
+		_, err = NewClientIPResolver([]string{"10.0.0.0/99"})
+		require.Error(t, err)
+	})
+}
+
+func TestClientIPResolver_Resolve(t *testing.T) {
+	resolver, err := NewClientIPResolver([]string{"10.0.0.0/8"})
+	require.NoError(t, err)
+
+	t.Run("uses peer address without forwarding headers", func(t *testing.T) {
+		req := newTestRequest("203.0.113.5:4321", nil)
+		assert.Equal(t, "203.0.113.5", resolver.Resolve(req))
+	})
+
+	t.Run("trusted proxy X-Forwarded-For", func(t *testing.T) {
+		req := newTestRequest("10.0.0.1:4321", map[string]string{
+			"X-Forwarded-For": "198.51.100.7",
+		})
+		assert.Equal(t, "198.51.100.7", resolver.Resolve(req))
+	})
+
+	t.Run("trusted proxy chain skips trusted hops", func(t *testing.T) {
+		req := newTestRequest("10.0.0.1:4321", map[string]string{
+			"X-Forwarded-For": "1.2.3.4, 198.51.100.7, 10.0.0.2",
+		})
+		assert.Equal(t, "198.51.100.7", resolver.Resolve(req))
+	})
+
+	t.Run("trusted proxy Forwarded header", func(t *testing.T) {
+		req := newTestRequest("10.0.0.1:4321", map[string]string{
+			"Forwarded": `for=198.51.100.7;proto=https, for="[2001:db8::1]:8080"`,
+		})
+		assert.Equal(t, "2001:db8::1", resolver.Resolve(req))
+	})
+
+	t.Run("untrusted peer cannot spoof X-Forwarded-For", func(t *testing.T) {
+		req := newTestRequest("203.0.113.5:4321", map[string]string{
+			"X-Forwarded-For": "198.51.100.7",
+		})
+		assert.Equal(t, "203.0.113.5", resolver.Resolve(req))
+	})
+
+	t.Run("untrusted peer cannot spoof Forwarded", func(t *testing.T) {
+		req := newTestRequest("203.0.113.5:4321", map[string]string{
+			"Forwarded": "for=198.51.100.7",
+		})
+		assert.Equal(t, "203.0.113.5", resolver.Resolve(req))
+	})
+
+	t.Run("malformed header falls back to peer", func(t *testing.T) {
+		req := newTestRequest("10.0.0.1:4321", map[string]string{
+			"X-Forwarded-For": "garbage",
+		})
+		assert.Equal(t, "10.0.0.1", resolver.Resolve(req))
+	})
+
+	t.Run("no trusted proxies ignores headers", func(t *testing.T) {
+		emptyResolver, err := NewClientIPResolver(nil)
+		require.NoError(t, err)
+
+		req := newTestRequest("10.0.0.1:4321", map[string]string{
+			"X-Forwarded-For": "198.51.100.7",
+		})
+		assert.Equal(t, "10.0.0.1", emptyResolver.Resolve(req))
+	})
+}
+
+func TestClientIP(t *testing.T) {
+	resolver, err := NewClientIPResolver([]string{"10.0.0.0/8"})
+	require.NoError(t, err)
+
+	var clientIP string
+	handler := ClientIP(resolver)(http.HandlerFunc(func(_ http.ResponseWriter, r *http.Request) {
+		clientIP = ClientIPFromContext(r.Context())
+	}))
+
+	req := newTestRequest("10.0.0.1:4321", map[string]string{
+		"X-Forwarded-For": "198.51.100.7",
+	})
+	handler.ServeHTTP(httptest.NewRecorder(), req)
+
+	assert.Equal(t, "198.51.100.7", clientIP)
+}
@@ -0,0 +1,72 @@
+package middleware
+
+import (
+	"net/http"
+	"strings"
+	"time"
+)
+
+// concurrencyRetryAfter is the Retry-After, in seconds, sent with requests
+// turned away by ConcurrencyLimit. Slots free up as fast as requests finish,
+// so clients should come back soon rather than back off for long.
+const concurrencyRetryAfter = "1"
+
+// ConcurrencyLimit serves at most maxInFlight requests at once. Up to
+// maxQueued more wait up to queueTimeout for one of them to finish; anything
+// beyond that, or still waiting when the timeout runs out, gets 503 with a
+// Retry-After. Requests under skipPrefixes bypass the limit, which is meant
+// for long-lived streams such as git clones that get their own budget. A
+// maxInFlight of zero or less disables the limit.
+func ConcurrencyLimit(maxInFlight, maxQueued int, queueTimeout time.Duration, skipPrefixes ...string) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		if maxInFlight <= 0 {
+			return next
+		}
+
+		slots := make(chan struct{}, maxInFlight)
+		// admitted counts the requests either holding or waiting for a slot,
+		// which caps the queue without a separate counter.
+		admitted := make(chan struct{}, maxInFlight+max(maxQueued, 0))
+
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			for _, prefix := range skipPrefixes {
+				if strings.HasPrefix(r.URL.Path, prefix) {
+					next.ServeHTTP(w, r)
+					return
+				}
+			}
+
+			select {
+			case admitted <- struct{}{}:
+			default:
+				rejectOverloaded(w)
+				return
+			}
+			defer func() { <-admitted }()
+
+			select {
+			case slots <- struct{}{}:
+			default:
+				timer := time.NewTimer(queueTimeout)
+				defer timer.Stop()
+
+				select {
+				case slots <- struct{}{}:
+				case <-timer.C:
+					rejectOverloaded(w)
+					return
+				case <-r.Context().Done():
+					return
+				}
+			}
+			defer func() { <-slots }()
+
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+func rejectOverloaded(w http.ResponseWriter) {
+	w.Header().Set("Retry-After", concurrencyRetryAfter)
+	http.Error(w, "server is busy, try again shortly", http.StatusServiceUnavailable)
+}
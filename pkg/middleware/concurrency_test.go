@@ -0,0 +1,161 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// blockingHandler holds every request until release is closed and signals
+// started as each one begins.
+type blockingHandler struct {
+	started chan struct{}
+	release chan struct{}
+}
+
+func newBlockingHandler() *blockingHandler {
+	return &blockingHandler{
+		started: make(chan struct{}, 16),
+		release: make(chan struct{}),
+	}
+}
+
+func (h *blockingHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	h.started <- struct{}{}
+	<-h.release
+	w.WriteHeader(http.StatusOK)
+}
+
+func (h *blockingHandler) waitStarted(t *testing.T, n int) {
+	t.Helper()
+
+	for range n {
+		select {
+		case <-h.started:
+		case <-time.After(time.Second):
+			t.Fatal("request did not reach the handler")
+		}
+	}
+}
+
+func TestConcurrencyLimit(t *testing.T) {
+	serve := func(handler http.Handler, path string) *httptest.ResponseRecorder {
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, path, nil))
+		return rec
+	}
+
+	// serveAsync starts n requests and returns a function that waits for
+	// their status codes.
+	serveAsync := func(handler http.Handler, path string, n int) func() []int {
+		codes := make([]int, n)
+		var wg sync.WaitGroup
+		for i := range n {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				codes[i] = serve(handler, path).Code
+			}()
+		}
+		return func() []int {
+			wg.Wait()
+			return codes
+		}
+	}
+
+	t.Run("excess requests get 503 when saturated", func(t *testing.T) {
+		backend := newBlockingHandler()
+		handler := ConcurrencyLimit(2, 0, 0)(backend)
+
+		wait := serveAsync(handler, "/api", 2)
+		backend.waitStarted(t, 2)
+
+		for range 3 {
+			rec := serve(handler, "/api")
+			assert.Equal(t, http.StatusServiceUnavailable, rec.Code)
+			assert.Equal(t, concurrencyRetryAfter, rec.Header().Get("Retry-After"))
+		}
+
+		close(backend.release)
+		assert.Equal(t, []int{http.StatusOK, http.StatusOK}, wait())
+
+		assert.Equal(t, http.StatusOK, serve(handler, "/api").Code)
+	})
+
+	t.Run("queued request is served once a slot frees up", func(t *testing.T) {
+		backend := newBlockingHandler()
+		handler := ConcurrencyLimit(1, 1, time.Minute)(backend)
+
+		wait := serveAsync(handler, "/api", 1)
+		backend.waitStarted(t, 1)
+
+		// Of two more requests one takes the queue slot and the other is
+		// turned away, which is the only way either can finish before the
+		// backend is released.
+		codes := make(chan int, 2)
+		for range 2 {
+			go func() {
+				codes <- serve(handler, "/api").Code
+			}()
+		}
+		select {
+		case code := <-codes:
+			assert.Equal(t, http.StatusServiceUnavailable, code)
+		case <-time.After(time.Second):
+			t.Fatal("request over the queue was not rejected")
+		}
+
+		close(backend.release)
+		assert.Equal(t, []int{http.StatusOK}, wait())
+		assert.Equal(t, http.StatusOK, <-codes)
+	})
+
+	t.Run("queued request gives up after the queue timeout", func(t *testing.T) {
+		backend := newBlockingHandler()
+		handler := ConcurrencyLimit(1, 1, 20*time.Millisecond)(backend)
+
+		wait := serveAsync(handler, "/api", 1)
+		backend.waitStarted(t, 1)
+
+		rec := serve(handler, "/api")
+		assert.Equal(t, http.StatusServiceUnavailable, rec.Code)
+		assert.Equal(t, concurrencyRetryAfter, rec.Header().Get("Retry-After"))
+
+		close(backend.release)
+		assert.Equal(t, []int{http.StatusOK}, wait())
+	})
+
+	t.Run("skipped prefixes are not limited", func(t *testing.T) {
+		backend := newBlockingHandler()
+		handler := ConcurrencyLimit(1, 0, 0, "/git/")(backend)
+
+		waitApi := serveAsync(handler, "/api", 1)
+		backend.waitStarted(t, 1)
+
+		waitGit := serveAsync(handler, "/git/repo.git/info/refs", 3)
+		backend.waitStarted(t, 3)
+
+		assert.Equal(t, http.StatusServiceUnavailable, serve(handler, "/api").Code)
+
+		close(backend.release)
+		assert.Equal(t, []int{http.StatusOK}, waitApi())
+		assert.Equal(t, []int{http.StatusOK, http.StatusOK, http.StatusOK}, waitGit())
+	})
+
+	t.Run("zero disables the limit", func(t *testing.T) {
+		backend := newBlockingHandler()
+		handler := ConcurrencyLimit(0, 0, 0)(backend)
+
+		wait := serveAsync(handler, "/api", 5)
+		backend.waitStarted(t, 5)
+
+		close(backend.release)
+		for _, code := range wait() {
+			assert.Equal(t, http.StatusOK, code)
+		}
+	})
+}
@@ -0,0 +1,57 @@
+package middleware
+
+import (
+	"context"
+	"path"
+	"strings"
+
+	"connectrpc.com/connect"
+
+	"hasir-api/pkg/postgres"
+)
+
+// readOnlyProcedurePrefixes name the RPCs whose reads may be served by a
+// replica. Every other procedure can write, so its reads go to the primary and
+// see the rows it is about to change.
+var readOnlyProcedurePrefixes = []string{"Get", "List", "Search", "Count"}
+
+type ReadRoutingInterceptor struct{}
+
+func NewReadRoutingInterceptor() *ReadRoutingInterceptor {
+	return &ReadRoutingInterceptor{}
+}
+
+func (i *ReadRoutingInterceptor) WrapUnary(next connect.UnaryFunc) connect.UnaryFunc {
+	return func(ctx context.Context, req connect.AnyRequest) (connect.AnyResponse, error) {
+		if !req.Spec().IsClient && !isReadOnlyProcedure(req.Spec().Procedure) {
+			ctx = postgres.WithPrimary(ctx)
+		}
+
+		return next(ctx, req)
+	}
+}
+
+func (i *ReadRoutingInterceptor) WrapStreamingClient(next connect.StreamingClientFunc) connect.StreamingClientFunc {
+	return next
+}
+
+func (i *ReadRoutingInterceptor) WrapStreamingHandler(next connect.StreamingHandlerFunc) connect.StreamingHandlerFunc {
+	return func(ctx context.Context, conn connect.StreamingHandlerConn) error {
+		if !isReadOnlyProcedure(conn.Spec().Procedure) {
+			ctx = postgres.WithPrimary(ctx)
+		}
+
+		return next(ctx, conn)
+	}
+}
+
+func isReadOnlyProcedure(procedure string) bool {
+	method := path.Base(procedure)
+	for _, prefix := range readOnlyProcedurePrefixes {
+		if strings.HasPrefix(method, prefix) {
+			return true
+		}
+	}
+
+	return false
+}
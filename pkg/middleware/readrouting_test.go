@@ -0,0 +1,55 @@
+package middleware
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"connectrpc.com/connect"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/types/known/emptypb"
+
+	"hasir-api/pkg/postgres"
+)
+
+func TestReadRoutingInterceptor(t *testing.T) {
+	primary := &pgxpool.Pool{}
+	replica := &pgxpool.Pool{}
+
+	tests := []struct {
+		procedure string
+		expected  *pgxpool.Pool
+	}{
+		{procedure: "/registry.v1.RegistryService/GetRepository", expected: replica},
+		{procedure: "/registry.v1.RegistryService/GetRepositories", expected: replica},
+		{procedure: "/organization.v1.OrganizationService/SearchItems", expected: replica},
+		{procedure: "/registry.v1.RegistryService/UpdateRepository", expected: primary},
+		{procedure: "/organization.v1.OrganizationService/RespondToInvitation", expected: primary},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.procedure, func(t *testing.T) {
+			var selected *pgxpool.Pool
+			mux := http.NewServeMux()
+			mux.Handle(tt.procedure, connect.NewUnaryHandler(
+				tt.procedure,
+				func(ctx context.Context, _ *connect.Request[emptypb.Empty]) (*connect.Response[emptypb.Empty], error) {
+					selected = postgres.ReadPool(ctx, primary, replica)
+					return connect.NewResponse(&emptypb.Empty{}), nil
+				},
+				connect.WithInterceptors(NewReadRoutingInterceptor()),
+			))
+			server := httptest.NewServer(mux)
+			defer server.Close()
+
+			client := connect.NewClient[emptypb.Empty, emptypb.Empty](server.Client(), server.URL+tt.procedure)
+			_, err := client.CallUnary(context.Background(), connect.NewRequest(&emptypb.Empty{}))
+			require.NoError(t, err)
+
+			assert.Same(t, tt.expected, selected)
+		})
+	}
+}
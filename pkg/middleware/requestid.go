@@ -0,0 +1,184 @@
+package middleware
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"time"
+
+	"connectrpc.com/connect"
+	"github.com/google/uuid"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+	"go.uber.org/zap"
+)
+
+const RequestIDHeader = "X-Request-Id"
+
+const maxRequestIDLength = 128
+
+type requestIDKey struct{}
+
+// RequestID reuses a well-formed incoming X-Request-Id or generates one, echoes
+// it on the response and logs one line per request tagged with it.
+func RequestID() func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			requestID := resolveRequestID(r.Header.Get(RequestIDHeader))
+			ctx := WithRequestID(r.Context(), requestID)
+			trace.SpanFromContext(ctx).SetAttributes(attribute.String("request.id", requestID))
+			w.Header().Set(RequestIDHeader, requestID)
+
+			recorder := &statusRecorder{ResponseWriter: w, status: http.StatusOK}
+			start := time.Now()
+			next.ServeHTTP(recorder, r.WithContext(ctx))
+
+			Logger(ctx).Info("http request",
+				zap.String("method", r.Method),
+				zap.String("path", r.URL.Path),
+				zap.Int("status", recorder.status),
+				zap.Duration("duration", time.Since(start)),
+				zap.String("clientIp", ClientIPFromContext(ctx)),
+			)
+		})
+	}
+}
+
+func WithRequestID(ctx context.Context, requestID string) context.Context {
+	return context.WithValue(ctx, requestIDKey{}, requestID)
+}
+
+func RequestIDFromContext(ctx context.Context) string {
+	requestID, _ := ctx.Value(requestIDKey{}).(string)
+	return requestID
+}
+
+// Logger returns the global logger tagged with the request id from ctx, if any.
+func Logger(ctx context.Context) *zap.Logger {
+	if requestID := RequestIDFromContext(ctx); requestID != "" {
+		return zap.L().With(zap.String("requestId", requestID))
+	}
+
+	return zap.L()
+}
+
+type RequestIDInterceptor struct{}
+
+func NewRequestIDInterceptor() *RequestIDInterceptor {
+	return &RequestIDInterceptor{}
+}
+
+func (i *RequestIDInterceptor) WrapUnary(next connect.UnaryFunc) connect.UnaryFunc {
+	return func(ctx context.Context, req connect.AnyRequest) (connect.AnyResponse, error) {
+		if req.Spec().IsClient {
+			if requestID := RequestIDFromContext(ctx); requestID != "" {
+				req.Header().Set(RequestIDHeader, requestID)
+			}
+			return next(ctx, req)
+		}
+
+		ctx, requestID, generated := requestIDForHandler(ctx, req.Header())
+
+		response, err := next(ctx, req)
+		if generated {
+			if err != nil {
+				var connectErr *connect.Error
+				if errors.As(err, &connectErr) {
+					connectErr.Meta().Set(RequestIDHeader, requestID)
+				}
+			} else if response != nil {
+				response.Header().Set(RequestIDHeader, requestID)
+			}
+		}
+
+		return response, err
+	}
+}
+
+func (i *RequestIDInterceptor) WrapStreamingClient(next connect.StreamingClientFunc) connect.StreamingClientFunc {
+	return func(ctx context.Context, spec connect.Spec) connect.StreamingClientConn {
+		conn := next(ctx, spec)
+		if requestID := RequestIDFromContext(ctx); requestID != "" {
+			conn.RequestHeader().Set(RequestIDHeader, requestID)
+		}
+		return conn
+	}
+}
+
+func (i *RequestIDInterceptor) WrapStreamingHandler(next connect.StreamingHandlerFunc) connect.StreamingHandlerFunc {
+	return func(ctx context.Context, conn connect.StreamingHandlerConn) error {
+		ctx, requestID, generated := requestIDForHandler(ctx, conn.RequestHeader())
+		if generated {
+			conn.ResponseHeader().Set(RequestIDHeader, requestID)
+		}
+
+		return next(ctx, conn)
+	}
+}
+
+// requestIDForHandler prefers the id set by the RequestID middleware so both
+// layers agree. generated reports whether the middleware did not run, in which
+// case the interceptor has to echo the id itself.
+func requestIDForHandler(ctx context.Context, header http.Header) (context.Context, string, bool) {
+	requestID := RequestIDFromContext(ctx)
+	generated := requestID == ""
+	if generated {
+		requestID = resolveRequestID(header.Get(RequestIDHeader))
+		ctx = WithRequestID(ctx, requestID)
+	}
+
+	trace.SpanFromContext(ctx).SetAttributes(attribute.String("request.id", requestID))
+
+	return ctx, requestID, generated
+}
+
+func resolveRequestID(incoming string) string {
+	if isValidRequestID(incoming) {
+		return incoming
+	}
+
+	return uuid.NewString()
+}
+
+func isValidRequestID(requestID string) bool {
+	if requestID == "" || len(requestID) > maxRequestIDLength {
+		return false
+	}
+
+	for _, c := range requestID {
+		if c < 0x21 || c > 0x7e {
+			return false
+		}
+	}
+
+	return true
+}
+
+type statusRecorder struct {
+	http.ResponseWriter
+	status      int
+	wroteHeader bool
+}
+
+func (s *statusRecorder) WriteHeader(status int) {
+	if !s.wroteHeader {
+		s.status = status
+		s.wroteHeader = true
+	}
+	s.ResponseWriter.WriteHeader(status)
+}
+
+func (s *statusRecorder) Write(b []byte) (int, error) {
+	s.wroteHeader = true
+	return s.ResponseWriter.Write(b)
+}
+
+func (s *statusRecorder) Flush() {
+	if flusher, ok := s.ResponseWriter.(http.Flusher); ok {
+		flusher.Flush()
+	}
+}
+
+func (s *statusRecorder) Unwrap() http.ResponseWriter {
+	return s.ResponseWriter
+}
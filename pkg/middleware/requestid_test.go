@@ -0,0 +1,130 @@
+package middleware
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"connectrpc.com/connect"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"go.uber.org/zap/zaptest/observer"
+)
+
+func observeLogs(t *testing.T) *observer.ObservedLogs {
+	t.Helper()
+
+	core, logs := observer.New(zapcore.InfoLevel)
+	restore := zap.ReplaceGlobals(zap.New(core))
+	t.Cleanup(restore)
+
+	return logs
+}
+
+func TestRequestID(t *testing.T) {
+	t.Run("echoes incoming id and logs it", func(t *testing.T) {
+		logs := observeLogs(t)
+
+		var seen string
+		handler := RequestID()(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			seen = RequestIDFromContext(r.Context())
+			w.WriteHeader(http.StatusTeapot)
+		}))
+
+		req := httptest.NewRequest(http.MethodGet, "/some/path", nil)
+		req.Header.Set(RequestIDHeader, "abc-123")
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, req)
+
+		assert.Equal(t, "abc-123", seen)
+		assert.Equal(t, "abc-123", rec.Header().Get(RequestIDHeader))
+
+		entries := logs.FilterMessage("http request").All()
+		require.Len(t, entries, 1)
+		fields := entries[0].ContextMap()
+		assert.Equal(t, "abc-123", fields["requestId"])
+		assert.Equal(t, "/some/path", fields["path"])
+		assert.EqualValues(t, http.StatusTeapot, fields["status"])
+	})
+
+	t.Run("generates id when missing", func(t *testing.T) {
+		observeLogs(t)
+
+		var seen string
+		handler := RequestID()(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			seen = RequestIDFromContext(r.Context())
+		}))
+
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/", nil))
+
+		require.NotEmpty(t, seen)
+		assert.Equal(t, seen, rec.Header().Get(RequestIDHeader))
+	})
+
+	t.Run("replaces malformed id", func(t *testing.T) {
+		observeLogs(t)
+
+		for _, incoming := range []string{"has space", strings.Repeat("a", maxRequestIDLength+1)} {
+			req := httptest.NewRequest(http.MethodGet, "/", nil)
+			req.Header.Set(RequestIDHeader, incoming)
+			rec := httptest.NewRecorder()
+			RequestID()(http.HandlerFunc(func(http.ResponseWriter, *http.Request) {})).ServeHTTP(rec, req)
+
+			echoed := rec.Header().Get(RequestIDHeader)
+			assert.NotEmpty(t, echoed)
+			assert.NotEqual(t, incoming, echoed)
+		}
+	})
+}
+
+func TestLogger(t *testing.T) {
+	logs := observeLogs(t)
+
+	Logger(WithRequestID(context.Background(), "req-1")).Info("tagged")
+	Logger(context.Background()).Info("untagged")
+
+	entries := logs.All()
+	require.Len(t, entries, 2)
+	assert.Equal(t, "req-1", entries[0].ContextMap()["requestId"])
+	assert.NotContains(t, entries[1].ContextMap(), "requestId")
+}
+
+func TestRequestIDInterceptor(t *testing.T) {
+	interceptor := NewRequestIDInterceptor()
+
+	t.Run("keeps id set by middleware", func(t *testing.T) {
+		var seen string
+		next := interceptor.WrapUnary(func(ctx context.Context, req connect.AnyRequest) (connect.AnyResponse, error) {
+			seen = RequestIDFromContext(ctx)
+			return connect.NewResponse(&struct{}{}), nil
+		})
+
+		ctx := WithRequestID(context.Background(), "from-middleware")
+		response, err := next(ctx, connect.NewRequest(&struct{}{}))
+		require.NoError(t, err)
+
+		assert.Equal(t, "from-middleware", seen)
+		assert.Empty(t, response.Header().Get(RequestIDHeader))
+	})
+
+	t.Run("reads header and echoes it without middleware", func(t *testing.T) {
+		var seen string
+		next := interceptor.WrapUnary(func(ctx context.Context, req connect.AnyRequest) (connect.AnyResponse, error) {
+			seen = RequestIDFromContext(ctx)
+			return connect.NewResponse(&struct{}{}), nil
+		})
+
+		req := connect.NewRequest(&struct{}{})
+		req.Header().Set(RequestIDHeader, "from-header")
+		response, err := next(context.Background(), req)
+		require.NoError(t, err)
+
+		assert.Equal(t, "from-header", seen)
+		assert.Equal(t, "from-header", response.Header().Get(RequestIDHeader))
+	})
+}
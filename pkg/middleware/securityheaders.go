@@ -0,0 +1,36 @@
+package middleware
+
+import (
+	"net/http"
+	"strings"
+)
+
+// hstsMaxAge is one year, the minimum browsers accept for preload lists.
+const hstsMaxAge = "max-age=31536000; includeSubDomains"
+
+// SecurityHeaders sets the usual hardening headers on every response except
+// those under skipPrefixes, which is meant for the git smart HTTP endpoints
+// whose clients expect the responses untouched. hsts should be off when the
+// server is not reached over TLS, since browsers would otherwise refuse plain
+// HTTP to the host for a year.
+func SecurityHeaders(hsts bool, skipPrefixes ...string) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			for _, prefix := range skipPrefixes {
+				if strings.HasPrefix(r.URL.Path, prefix) {
+					next.ServeHTTP(w, r)
+					return
+				}
+			}
+
+			header := w.Header()
+			if hsts {
+				header.Set("Strict-Transport-Security", hstsMaxAge)
+			}
+			header.Set("X-Content-Type-Options", "nosniff")
+			header.Set("Referrer-Policy", "no-referrer")
+
+			next.ServeHTTP(w, r)
+		})
+	}
+}
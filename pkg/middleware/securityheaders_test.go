@@ -0,0 +1,49 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSecurityHeaders(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/docs/", func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte("<html></html>"))
+	})
+	mux.HandleFunc("/git/", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/x-git-upload-pack-advertisement")
+	})
+
+	serve := func(handler http.Handler, path string) http.Header {
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, path, nil))
+		return rec.Header()
+	}
+
+	t.Run("docs responses carry the headers", func(t *testing.T) {
+		header := serve(SecurityHeaders(true, "/git/")(mux), "/docs/org/repo/index.html")
+
+		assert.Equal(t, hstsMaxAge, header.Get("Strict-Transport-Security"))
+		assert.Equal(t, "nosniff", header.Get("X-Content-Type-Options"))
+		assert.Equal(t, "no-referrer", header.Get("Referrer-Policy"))
+	})
+
+	t.Run("git responses are untouched", func(t *testing.T) {
+		header := serve(SecurityHeaders(true, "/git/")(mux), "/git/repo.git/info/refs")
+
+		assert.Empty(t, header.Get("Strict-Transport-Security"))
+		assert.Empty(t, header.Get("X-Content-Type-Options"))
+		assert.Empty(t, header.Get("Referrer-Policy"))
+		assert.Equal(t, "application/x-git-upload-pack-advertisement", header.Get("Content-Type"))
+	})
+
+	t.Run("hsts can be disabled", func(t *testing.T) {
+		header := serve(SecurityHeaders(false, "/git/")(mux), "/docs/org/repo/index.html")
+
+		assert.Empty(t, header.Get("Strict-Transport-Security"))
+		assert.Equal(t, "nosniff", header.Get("X-Content-Type-Options"))
+	})
+}
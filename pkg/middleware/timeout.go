@@ -0,0 +1,81 @@
+package middleware
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"connectrpc.com/connect"
+)
+
+// TimeoutInterceptor bounds each handled RPC by the timeout configured for
+// its procedure, falling back to the default. A zero timeout leaves the call
+// unbounded.
+type TimeoutInterceptor struct {
+	defaultTimeout time.Duration
+	timeouts       map[string]time.Duration
+}
+
+func NewTimeoutInterceptor(defaultTimeout time.Duration, timeouts map[string]time.Duration) *TimeoutInterceptor {
+	return &TimeoutInterceptor{
+		defaultTimeout: defaultTimeout,
+		timeouts:       timeouts,
+	}
+}
+
+func (i *TimeoutInterceptor) WrapUnary(next connect.UnaryFunc) connect.UnaryFunc {
+	return func(ctx context.Context, req connect.AnyRequest) (connect.AnyResponse, error) {
+		if req.Spec().IsClient {
+			return next(ctx, req)
+		}
+
+		timeout := i.timeoutFor(req.Spec().Procedure)
+		if timeout == 0 {
+			return next(ctx, req)
+		}
+
+		ctx, cancel := context.WithTimeout(ctx, timeout)
+		defer cancel()
+
+		res, err := next(ctx, req)
+		return res, deadlineError(ctx, err, req.Spec().Procedure, timeout)
+	}
+}
+
+func (i *TimeoutInterceptor) WrapStreamingClient(next connect.StreamingClientFunc) connect.StreamingClientFunc {
+	return next
+}
+
+func (i *TimeoutInterceptor) WrapStreamingHandler(next connect.StreamingHandlerFunc) connect.StreamingHandlerFunc {
+	return func(ctx context.Context, conn connect.StreamingHandlerConn) error {
+		timeout := i.timeoutFor(conn.Spec().Procedure)
+		if timeout == 0 {
+			return next(ctx, conn)
+		}
+
+		ctx, cancel := context.WithTimeout(ctx, timeout)
+		defer cancel()
+
+		return deadlineError(ctx, next(ctx, conn), conn.Spec().Procedure, timeout)
+	}
+}
+
+func (i *TimeoutInterceptor) timeoutFor(procedure string) time.Duration {
+	if timeout, ok := i.timeouts[procedure]; ok {
+		return timeout
+	}
+
+	return i.defaultTimeout
+}
+
+// deadlineError reports a failure caused by our deadline as
+// CodeDeadlineExceeded, whatever code the handler wrapped it in. A handler
+// that finished despite the deadline keeps its result.
+func deadlineError(ctx context.Context, err error, procedure string, timeout time.Duration) error {
+	if err == nil || !errors.Is(ctx.Err(), context.DeadlineExceeded) {
+		return err
+	}
+
+	return connect.NewError(connect.CodeDeadlineExceeded, fmt.Errorf("%s did not finish within %s", procedure, timeout))
+}
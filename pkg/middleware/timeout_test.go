@@ -0,0 +1,73 @@
+package middleware
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"connectrpc.com/connect"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/types/known/emptypb"
+)
+
+func TestTimeoutInterceptor(t *testing.T) {
+	const (
+		slowSearch   = "/organization.v1.OrganizationService/Search"
+		slowFileTree = "/registry.v1.RegistryService/GetFileTree"
+		quick        = "/registry.v1.RegistryService/GetRepository"
+	)
+
+	interceptor := NewTimeoutInterceptor(time.Second, map[string]time.Duration{
+		slowSearch:   20 * time.Millisecond,
+		slowFileTree: 5 * time.Second,
+	})
+
+	deadlines := map[string]time.Time{}
+	mux := http.NewServeMux()
+	for _, procedure := range []string{slowSearch, slowFileTree, quick} {
+		mux.Handle(procedure, connect.NewUnaryHandler(
+			procedure,
+			func(ctx context.Context, _ *connect.Request[emptypb.Empty]) (*connect.Response[emptypb.Empty], error) {
+				deadlines[procedure], _ = ctx.Deadline()
+				if procedure == quick {
+					return connect.NewResponse(&emptypb.Empty{}), nil
+				}
+
+				select {
+				case <-ctx.Done():
+					return nil, connect.NewError(connect.CodeInternal, ctx.Err())
+				case <-time.After(100 * time.Millisecond):
+					return connect.NewResponse(&emptypb.Empty{}), nil
+				}
+			},
+			connect.WithInterceptors(interceptor),
+		))
+	}
+	server := httptest.NewServer(mux)
+	defer server.Close()
+
+	call := func(procedure string) error {
+		client := connect.NewClient[emptypb.Empty, emptypb.Empty](server.Client(), server.URL+procedure)
+		_, err := client.CallUnary(context.Background(), connect.NewRequest(&emptypb.Empty{}))
+		return err
+	}
+
+	t.Run("short configured timeout expires", func(t *testing.T) {
+		err := call(slowSearch)
+		require.Error(t, err)
+		assert.Equal(t, connect.CodeDeadlineExceeded, connect.CodeOf(err))
+	})
+
+	t.Run("long configured timeout lets the call complete", func(t *testing.T) {
+		require.NoError(t, call(slowFileTree))
+		assert.WithinDuration(t, time.Now().Add(5*time.Second), deadlines[slowFileTree], time.Second)
+	})
+
+	t.Run("unlisted procedure uses the default", func(t *testing.T) {
+		require.NoError(t, call(quick))
+		assert.WithinDuration(t, time.Now().Add(time.Second), deadlines[quick], time.Second)
+	})
+}
@@ -0,0 +1,105 @@
+package naming
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"strings"
+
+	"connectrpc.com/connect"
+)
+
+// DefaultReservedNames are names that collide with dashboard routes or could
+// pass for the service itself, so no organization or repository may take them.
+var DefaultReservedNames = []string{
+	"admin",
+	"administrator",
+	"api",
+	"docs",
+	"explore",
+	"hasir",
+	"login",
+	"logout",
+	"new",
+	"organizations",
+	"repositories",
+	"root",
+	"sdk",
+	"search",
+	"settings",
+	"signup",
+	"support",
+	"system",
+}
+
+// ReservedNames is the set of names ValidateName rejects. A nil set holds
+// only DefaultReservedNames.
+type ReservedNames struct {
+	names map[string]struct{}
+}
+
+// NewReservedNames merges extra with DefaultReservedNames.
+func NewReservedNames(extra []string) *ReservedNames {
+	names := make(map[string]struct{}, len(DefaultReservedNames)+len(extra))
+	for _, name := range DefaultReservedNames {
+		names[name] = struct{}{}
+	}
+	for _, name := range extra {
+		names[strings.ToLower(name)] = struct{}{}
+	}
+
+	return &ReservedNames{names: names}
+}
+
+var defaultReservedNames = NewReservedNames(nil)
+
+// ValidateName rejects reserved names regardless of case.
+func (r *ReservedNames) ValidateName(name string) error {
+	if r == nil {
+		r = defaultReservedNames
+	}
+
+	if _, ok := r.names[strings.ToLower(name)]; ok {
+		return connect.NewError(connect.CodeInvalidArgument, fmt.Errorf("name %q is reserved", name))
+	}
+
+	return nil
+}
+
+// ReadReservedNamesFile reads one name per line. Blank lines and lines
+// starting with # are skipped; an entry containing whitespace is an error.
+func ReadReservedNamesFile(path string) ([]string, error) {
+	// #nosec G304 -- the path comes from the operator's configuration
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open reserved names file: %w", err)
+	}
+	defer func() {
+		_ = file.Close()
+	}()
+
+	var names []string
+	scanner := bufio.NewScanner(file)
+	for lineNumber := 1; scanner.Scan(); lineNumber++ {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		if strings.ContainsFunc(line, isSpace) {
+			return nil, fmt.Errorf("%s:%d: invalid reserved name %q", path, lineNumber, line)
+		}
+
+		names = append(names, line)
+	}
+
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read reserved names file: %w", err)
+	}
+
+	return names, nil
+}
+
+func isSpace(r rune) bool {
+	return r == ' ' || r == '\t'
+}
@@ -0,0 +1,69 @@
+package naming
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"connectrpc.com/connect"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func writeReservedNamesFile(t *testing.T, content string) string {
+	t.Helper()
+
+	path := filepath.Join(t.TempDir(), "reserved-names.txt")
+	require.NoError(t, os.WriteFile(path, []byte(content), 0o600))
+
+	return path
+}
+
+func TestReservedNames_ValidateName(t *testing.T) {
+	t.Run("built-in names are reserved in any case", func(t *testing.T) {
+		err := NewReservedNames(nil).ValidateName("Admin")
+
+		require.Error(t, err)
+		assert.Equal(t, connect.CodeInvalidArgument, connect.CodeOf(err))
+	})
+
+	t.Run("nil set still rejects built-in names", func(t *testing.T) {
+		var reserved *ReservedNames
+
+		require.Error(t, reserved.ValidateName("settings"))
+		require.NoError(t, reserved.ValidateName("acme"))
+	})
+
+	t.Run("extra names are merged with the defaults", func(t *testing.T) {
+		reserved := NewReservedNames([]string{"Acme"})
+
+		require.Error(t, reserved.ValidateName("acme"))
+		require.Error(t, reserved.ValidateName("api"))
+		require.NoError(t, reserved.ValidateName("payments"))
+	})
+}
+
+func TestReadReservedNamesFile(t *testing.T) {
+	t.Run("skips comments and blank lines", func(t *testing.T) {
+		path := writeReservedNamesFile(t, "# brands\nacme\n\n  internal-tools  \n")
+
+		names, err := ReadReservedNamesFile(path)
+		require.NoError(t, err)
+
+		assert.Equal(t, []string{"acme", "internal-tools"}, names)
+	})
+
+	t.Run("rejects entries with whitespace", func(t *testing.T) {
+		path := writeReservedNamesFile(t, "acme\nacme corp\n")
+
+		_, err := ReadReservedNamesFile(path)
+
+		require.ErrorContains(t, err, `:2: invalid reserved name "acme corp"`)
+	})
+
+	t.Run("missing file", func(t *testing.T) {
+		_, err := ReadReservedNamesFile(filepath.Join(t.TempDir(), "missing.txt"))
+
+		require.ErrorContains(t, err, "failed to open reserved names file")
+	})
+}
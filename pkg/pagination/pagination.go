@@ -0,0 +1,36 @@
+package pagination
+
+import (
+	"errors"
+	"math"
+
+	"connectrpc.com/connect"
+)
+
+// Paginate works out the paging fields of a list response. totalPages is at
+// least 1, so an empty list is a single empty page, and nextPage is 0 when
+// page is the last page or beyond it. A page below 1 counts as the first.
+func Paginate(totalCount, page, pageSize int) (nextPage, totalPages int) {
+	if pageSize < 1 {
+		return 0, 1
+	}
+
+	totalPages = max((totalCount+pageSize-1)/pageSize, 1)
+	page = max(page, 1)
+	if page < totalPages {
+		nextPage = page + 1
+	}
+
+	return nextPage, totalPages
+}
+
+// PaginateInt32 is Paginate for the int32 fields of the proto responses.
+func PaginateInt32(totalCount, page, pageSize int) (nextPage, totalPages int32, err error) {
+	next, total := Paginate(totalCount, page, pageSize)
+	if total > math.MaxInt32 {
+		return 0, 0, connect.NewError(connect.CodeInternal, errors.New("total pages exceeds maximum value"))
+	}
+
+	// next is at most total, so it fits as well.
+	return int32(next), int32(total), nil // #nosec G115 -- bounds checked above
+}
@@ -0,0 +1,59 @@
+package pagination
+
+import (
+	"math"
+	"testing"
+
+	"connectrpc.com/connect"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPaginate(t *testing.T) {
+	tests := []struct {
+		name       string
+		totalCount int
+		page       int
+		pageSize   int
+		nextPage   int
+		totalPages int
+	}{
+		{name: "empty list is one page", totalCount: 0, page: 1, pageSize: 10, nextPage: 0, totalPages: 1},
+		{name: "fewer items than a page", totalCount: 3, page: 1, pageSize: 10, nextPage: 0, totalPages: 1},
+		{name: "exactly one page", totalCount: 10, page: 1, pageSize: 10, nextPage: 0, totalPages: 1},
+		{name: "one item over a page", totalCount: 11, page: 1, pageSize: 10, nextPage: 2, totalPages: 2},
+		{name: "exact multiple, first page", totalCount: 30, page: 1, pageSize: 10, nextPage: 2, totalPages: 3},
+		{name: "exact multiple, last page", totalCount: 30, page: 3, pageSize: 10, nextPage: 0, totalPages: 3},
+		{name: "partial last page", totalCount: 25, page: 3, pageSize: 10, nextPage: 0, totalPages: 3},
+		{name: "page before partial last page", totalCount: 25, page: 2, pageSize: 10, nextPage: 3, totalPages: 3},
+		{name: "page beyond the end", totalCount: 25, page: 7, pageSize: 10, nextPage: 0, totalPages: 3},
+		{name: "page below one counts as first", totalCount: 25, page: 0, pageSize: 10, nextPage: 2, totalPages: 3},
+		{name: "page size of one", totalCount: 2, page: 1, pageSize: 1, nextPage: 2, totalPages: 2},
+		{name: "invalid page size", totalCount: 25, page: 1, pageSize: 0, nextPage: 0, totalPages: 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			nextPage, totalPages := Paginate(tt.totalCount, tt.page, tt.pageSize)
+
+			assert.Equal(t, tt.nextPage, nextPage)
+			assert.Equal(t, tt.totalPages, totalPages)
+		})
+	}
+}
+
+func TestPaginateInt32(t *testing.T) {
+	t.Run("converts", func(t *testing.T) {
+		nextPage, totalPages, err := PaginateInt32(25, 2, 10)
+
+		require.NoError(t, err)
+		assert.Equal(t, int32(3), nextPage)
+		assert.Equal(t, int32(3), totalPages)
+	})
+
+	t.Run("too many pages", func(t *testing.T) {
+		_, _, err := PaginateInt32(math.MaxInt32+1, 1, 1)
+
+		assert.Equal(t, connect.CodeInternal, connect.CodeOf(err))
+	})
+}
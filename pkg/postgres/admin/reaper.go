@@ -0,0 +1,172 @@
+package admin
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"connectrpc.com/connect"
+	"github.com/jackc/pgx/v5"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+
+	"hasir-api/internal/admin"
+	"hasir-api/pkg/postgres"
+)
+
+func (r *PgRepository) GetExpiredRepositories(ctx context.Context, deletedBefore time.Time, limit int) ([]admin.ExpiredRepositoryDTO, error) {
+	var span trace.Span
+	ctx, span = r.tracer.Start(ctx, "GetExpiredRepositories", trace.WithAttributes(
+		attribute.KeyValue{
+			Key:   "limit",
+			Value: attribute.IntValue(limit),
+		},
+	))
+	defer span.End()
+
+	ctx, cancel := postgres.WithQueryTimeout(ctx, r.queryTimeout)
+	defer cancel()
+
+	connection, err := r.connectionPool.Acquire(ctx)
+	if err != nil {
+		return nil, postgres.QueryError(ctx, ErrFailedAcquireConnection)
+	}
+	defer connection.Release()
+
+	sql := `SELECT id, organization_id, path
+		FROM repositories
+		WHERE deleted_at < $1
+		ORDER BY deleted_at, id
+		LIMIT $2`
+
+	rows, err := connection.Query(ctx, sql, deletedBefore, limit)
+	if err != nil {
+		span.RecordError(err)
+		return nil, postgres.QueryError(ctx, connect.NewError(connect.CodeInternal, errors.New("failed to query expired repositories")))
+	}
+
+	repositories, err := pgx.CollectRows(rows, pgx.RowToStructByName[admin.ExpiredRepositoryDTO])
+	if err != nil {
+		span.RecordError(err)
+		return nil, postgres.QueryError(ctx, connect.NewError(connect.CodeInternal, errors.New("failed to collect expired repository rows")))
+	}
+
+	return repositories, nil
+}
+
+// HardDeleteRepositories re-checks deleted_at, so a repository restored since
+// it was listed is kept. Its SDK preferences, jobs, pushes and deploy tokens
+// go with it through ON DELETE CASCADE.
+func (r *PgRepository) HardDeleteRepositories(ctx context.Context, ids []string, deletedBefore time.Time) (int64, error) {
+	var span trace.Span
+	ctx, span = r.tracer.Start(ctx, "HardDeleteRepositories", trace.WithAttributes(
+		attribute.KeyValue{
+			Key:   "count",
+			Value: attribute.IntValue(len(ids)),
+		},
+	))
+	defer span.End()
+
+	ctx, cancel := postgres.WithQueryTimeout(ctx, r.queryTimeout)
+	defer cancel()
+
+	connection, err := r.connectionPool.Acquire(ctx)
+	if err != nil {
+		return 0, postgres.QueryError(ctx, ErrFailedAcquireConnection)
+	}
+	defer connection.Release()
+
+	sql := "DELETE FROM repositories WHERE id = ANY($1) AND deleted_at < $2"
+	result, err := connection.Exec(ctx, sql, ids, deletedBefore)
+	if err != nil {
+		span.RecordError(err)
+		return 0, postgres.QueryError(ctx, connect.NewError(connect.CodeInternal, errors.New("failed to delete expired repositories")))
+	}
+
+	return result.RowsAffected(), nil
+}
+
+// HardDeleteOrganizations skips organizations that still have any repository
+// row, deleted or not: those are removed first, together with their files,
+// rather than through the cascade.
+func (r *PgRepository) HardDeleteOrganizations(ctx context.Context, deletedBefore time.Time, limit int) (int64, error) {
+	var span trace.Span
+	ctx, span = r.tracer.Start(ctx, "HardDeleteOrganizations", trace.WithAttributes(
+		attribute.KeyValue{
+			Key:   "limit",
+			Value: attribute.IntValue(limit),
+		},
+	))
+	defer span.End()
+
+	ctx, cancel := postgres.WithQueryTimeout(ctx, r.queryTimeout)
+	defer cancel()
+
+	connection, err := r.connectionPool.Acquire(ctx)
+	if err != nil {
+		return 0, postgres.QueryError(ctx, ErrFailedAcquireConnection)
+	}
+	defer connection.Release()
+
+	sql := `DELETE FROM organizations
+		WHERE id IN (
+			SELECT o.id FROM organizations o
+			WHERE o.deleted_at < $1
+				AND NOT EXISTS (SELECT 1 FROM repositories r WHERE r.organization_id = o.id)
+			ORDER BY o.deleted_at, o.id
+			LIMIT $2
+			FOR UPDATE SKIP LOCKED
+		)`
+
+	result, err := connection.Exec(ctx, sql, deletedBefore, limit)
+	if err != nil {
+		span.RecordError(err)
+		return 0, postgres.QueryError(ctx, connect.NewError(connect.CodeInternal, errors.New("failed to delete expired organizations")))
+	}
+
+	return result.RowsAffected(), nil
+}
+
+// HardDeleteUsers skips users still referenced by something the cascade would
+// otherwise take with them: an organization or repository they created, a
+// live deploy token they issued or an invite of theirs still pending.
+func (r *PgRepository) HardDeleteUsers(ctx context.Context, deletedBefore time.Time, limit int) (int64, error) {
+	var span trace.Span
+	ctx, span = r.tracer.Start(ctx, "HardDeleteUsers", trace.WithAttributes(
+		attribute.KeyValue{
+			Key:   "limit",
+			Value: attribute.IntValue(limit),
+		},
+	))
+	defer span.End()
+
+	ctx, cancel := postgres.WithQueryTimeout(ctx, r.queryTimeout)
+	defer cancel()
+
+	connection, err := r.connectionPool.Acquire(ctx)
+	if err != nil {
+		return 0, postgres.QueryError(ctx, ErrFailedAcquireConnection)
+	}
+	defer connection.Release()
+
+	sql := `DELETE FROM users
+		WHERE id IN (
+			SELECT u.id FROM users u
+			WHERE u.deleted_at < $1
+				AND NOT EXISTS (SELECT 1 FROM organizations o WHERE o.created_by = u.id)
+				AND NOT EXISTS (SELECT 1 FROM repositories r WHERE r.created_by = u.id)
+				AND NOT EXISTS (SELECT 1 FROM deploy_tokens t WHERE t.created_by = u.id AND t.deleted_at IS NULL)
+				AND NOT EXISTS (SELECT 1 FROM organization_invites i WHERE i.invited_by = u.id AND i.status = 'pending')
+			ORDER BY u.deleted_at, u.id
+			LIMIT $2
+			FOR UPDATE SKIP LOCKED
+		)`
+
+	result, err := connection.Exec(ctx, sql, deletedBefore, limit)
+	if err != nil {
+		span.RecordError(err)
+		return 0, postgres.QueryError(ctx, connect.NewError(connect.CodeInternal, errors.New("failed to delete expired users")))
+	}
+
+	return result.RowsAffected(), nil
+}
@@ -0,0 +1,147 @@
+package admin
+
+import (
+	"testing"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// setupReaperTables adds the tables the reaper deletes from, with the
+// foreign keys of the real schema, on top of setupTestRepository's.
+func setupReaperTables(t *testing.T, pool *pgxpool.Pool) {
+	t.Helper()
+
+	_, err := pool.Exec(t.Context(), `
+		CREATE TABLE users (
+			id VARCHAR(36) PRIMARY KEY,
+			deleted_at TIMESTAMP WITH TIME ZONE
+		);
+		ALTER TABLE organizations
+			ADD CONSTRAINT organizations_created_by_fkey FOREIGN KEY (created_by) REFERENCES users(id) ON DELETE CASCADE;
+		CREATE TABLE repositories (
+			id VARCHAR(36) PRIMARY KEY,
+			path VARCHAR(255) NOT NULL,
+			created_by VARCHAR(36) NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+			organization_id VARCHAR(36) NOT NULL REFERENCES organizations(id) ON DELETE CASCADE,
+			deleted_at TIMESTAMP WITH TIME ZONE
+		);
+		CREATE TABLE repository_pushes (
+			id VARCHAR(36) PRIMARY KEY,
+			repository_id VARCHAR(36) NOT NULL REFERENCES repositories(id) ON DELETE CASCADE
+		);
+		CREATE TABLE deploy_tokens (
+			id VARCHAR(36) PRIMARY KEY,
+			repository_id VARCHAR(36) NOT NULL REFERENCES repositories(id) ON DELETE CASCADE,
+			created_by VARCHAR(36) NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+			deleted_at TIMESTAMP WITH TIME ZONE
+		);
+		CREATE TABLE organization_invites (
+			id VARCHAR(36) PRIMARY KEY,
+			organization_id VARCHAR(36) NOT NULL REFERENCES organizations(id) ON DELETE CASCADE,
+			invited_by VARCHAR(36) NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+			status VARCHAR(20) NOT NULL DEFAULT 'pending'
+		)`)
+	require.NoError(t, err)
+}
+
+func TestPgRepository_Reaper(t *testing.T) {
+	repo, pool := setupTestRepository(t)
+	setupReaperTables(t, pool)
+
+	now := time.Now().UTC()
+	cutoff := now.Add(-30 * 24 * time.Hour)
+	expired := cutoff.Add(-time.Hour)
+	recent := cutoff.Add(time.Hour)
+
+	exec := func(sql string, args ...any) {
+		t.Helper()
+		_, err := pool.Exec(t.Context(), sql, args...)
+		require.NoError(t, err)
+	}
+	exists := func(table, id string) bool {
+		t.Helper()
+		var found bool
+		require.NoError(t, pool.QueryRow(t.Context(), `SELECT EXISTS (SELECT 1 FROM `+table+` WHERE id = $1)`, id).Scan(&found))
+		return found
+	}
+
+	exec(`INSERT INTO users (id, deleted_at) VALUES
+		('live-user', NULL),
+		('expired-user', $1),
+		('recent-user', $2),
+		('expired-org-creator', $1),
+		('expired-inviter', $1)`, expired, recent)
+
+	exec(`INSERT INTO organizations (id, slug, display_name, created_by, deleted_at) VALUES
+		('live-org', 'live', 'live', 'expired-org-creator', NULL),
+		('expired-org', 'expired', 'expired', 'live-user', $1),
+		('expired-org-recent-repo', 'recent-repo', 'recent-repo', 'live-user', $1),
+		('recent-org', 'recent', 'recent', 'live-user', $2)`, expired, recent)
+
+	exec(`INSERT INTO repositories (id, path, created_by, organization_id, deleted_at) VALUES
+		('live-repo', '/repos/live-repo', 'live-user', 'live-org', NULL),
+		('expired-repo', '/repos/expired-repo', 'live-user', 'expired-org', $1),
+		('recent-repo', '/repos/recent-repo', 'live-user', 'expired-org-recent-repo', $2)`, expired, recent)
+	exec(`INSERT INTO repository_pushes (id, repository_id) VALUES ('push', 'expired-repo')`)
+	exec(`INSERT INTO organization_invites (id, organization_id, invited_by, status) VALUES
+		('pending-invite', 'live-org', 'expired-inviter', 'pending')`)
+
+	t.Run("lists only repositories past the retention period", func(t *testing.T) {
+		repositories, err := repo.GetExpiredRepositories(t.Context(), cutoff, 10)
+		require.NoError(t, err)
+		require.Len(t, repositories, 1)
+		assert.Equal(t, "expired-repo", repositories[0].Id)
+		assert.Equal(t, "expired-org", repositories[0].OrganizationId)
+		assert.Equal(t, "/repos/expired-repo", repositories[0].Path)
+	})
+
+	t.Run("hard-deletes expired repositories with their dependents", func(t *testing.T) {
+		deleted, err := repo.HardDeleteRepositories(t.Context(), []string{"expired-repo", "recent-repo", "live-repo"}, cutoff)
+		require.NoError(t, err)
+		assert.Equal(t, int64(1), deleted)
+
+		assert.False(t, exists("repositories", "expired-repo"))
+		assert.False(t, exists("repository_pushes", "push"))
+		assert.True(t, exists("repositories", "recent-repo"))
+		assert.True(t, exists("repositories", "live-repo"))
+	})
+
+	t.Run("hard-deletes expired organizations without repositories", func(t *testing.T) {
+		deleted, err := repo.HardDeleteOrganizations(t.Context(), cutoff, 10)
+		require.NoError(t, err)
+		assert.Equal(t, int64(1), deleted)
+
+		assert.False(t, exists("organizations", "expired-org"))
+		assert.True(t, exists("organizations", "expired-org-recent-repo"), "a recently deleted repository keeps its organization")
+		assert.True(t, exists("organizations", "recent-org"))
+		assert.True(t, exists("organizations", "live-org"))
+	})
+
+	t.Run("hard-deletes expired users nothing live points at", func(t *testing.T) {
+		deleted, err := repo.HardDeleteUsers(t.Context(), cutoff, 10)
+		require.NoError(t, err)
+		assert.Equal(t, int64(1), deleted)
+
+		assert.False(t, exists("users", "expired-user"))
+		assert.True(t, exists("users", "expired-org-creator"), "the creator of a live organization is kept")
+		assert.True(t, exists("users", "expired-inviter"), "the sender of a pending invite is kept")
+		assert.True(t, exists("users", "recent-user"))
+		assert.True(t, exists("users", "live-user"))
+		assert.True(t, exists("organizations", "live-org"))
+	})
+
+	t.Run("deletes in batches", func(t *testing.T) {
+		exec(`INSERT INTO users (id, deleted_at) VALUES ('batch-1', $1), ('batch-2', $1), ('batch-3', $1)`, expired)
+
+		deleted, err := repo.HardDeleteUsers(t.Context(), cutoff, 2)
+		require.NoError(t, err)
+		assert.Equal(t, int64(2), deleted)
+
+		deleted, err = repo.HardDeleteUsers(t.Context(), cutoff, 2)
+		require.NoError(t, err)
+		assert.Equal(t, int64(1), deleted)
+	})
+}
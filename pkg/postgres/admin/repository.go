@@ -0,0 +1,189 @@
+package admin
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"connectrpc.com/connect"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+
+	"hasir-api/internal/admin"
+	"hasir-api/pkg/postgres"
+)
+
+var (
+	ErrFailedAcquireConnection = connect.NewError(connect.CodeInternal, errors.New("failed to acquire connection"))
+	ErrEmailJobNotFound        = connect.NewError(connect.CodeNotFound, errors.New("email job not found"))
+)
+
+type PgRepository struct {
+	connectionPool *pgxpool.Pool
+	tracer         trace.Tracer
+	queryTimeout   time.Duration
+}
+
+func NewPgRepository(connectionPool *pgxpool.Pool, tracer trace.Tracer, queryTimeout time.Duration) *PgRepository {
+	return &PgRepository{
+		connectionPool: connectionPool,
+		tracer:         tracer,
+		queryTimeout:   queryTimeout,
+	}
+}
+
+func (r *PgRepository) GetEmailJobStats(ctx context.Context) (*admin.QueueStatsDTO, error) {
+	return r.getJobStats(ctx, "GetEmailJobStats", "email_jobs")
+}
+
+func (r *PgRepository) GetSdkGenerationJobStats(ctx context.Context) (*admin.QueueStatsDTO, error) {
+	return r.getJobStats(ctx, "GetSdkGenerationJobStats", "sdk_generation_jobs")
+}
+
+// getJobStats is only called with hardcoded table names, so building the query
+// with Sprintf does not expose it to injection.
+func (r *PgRepository) getJobStats(ctx context.Context, spanName, table string) (*admin.QueueStatsDTO, error) {
+	var span trace.Span
+	ctx, span = r.tracer.Start(ctx, spanName, trace.WithAttributes(
+		attribute.KeyValue{
+			Key:   "table",
+			Value: attribute.StringValue(table),
+		},
+	))
+	defer span.End()
+
+	ctx, cancel := postgres.WithQueryTimeout(ctx, r.queryTimeout)
+	defer cancel()
+
+	connection, err := r.connectionPool.Acquire(ctx)
+	if err != nil {
+		return nil, postgres.QueryError(ctx, ErrFailedAcquireConnection)
+	}
+	defer connection.Release()
+
+	sql := fmt.Sprintf(`SELECT
+			COUNT(*) FILTER (WHERE status = 'pending') AS pending,
+			COUNT(*) FILTER (WHERE status = 'processing') AS processing,
+			COUNT(*) FILTER (WHERE status = 'completed') AS completed,
+			COUNT(*) FILTER (WHERE status = 'failed') AS failed,
+			COUNT(*) FILTER (WHERE status = 'cancelled') AS cancelled,
+			MIN(created_at) FILTER (WHERE status = 'pending') AS oldest_pending_at
+		FROM %s`, table)
+
+	rows, err := connection.Query(ctx, sql)
+	if err != nil {
+		span.RecordError(err)
+		return nil, postgres.QueryError(ctx, connect.NewError(connect.CodeInternal, fmt.Errorf("failed to query %s stats", table)))
+	}
+
+	stats, err := pgx.CollectExactlyOneRow(rows, pgx.RowToAddrOfStructByName[admin.QueueStatsDTO])
+	if err != nil {
+		span.RecordError(err)
+		return nil, postgres.QueryError(ctx, connect.NewError(connect.CodeInternal, fmt.Errorf("failed to collect %s stats", table)))
+	}
+
+	return stats, nil
+}
+
+func (r *PgRepository) GetOrganizations(ctx context.Context, includeDeleted bool, page, pageSize int) ([]admin.OrganizationDTO, int, error) {
+	var span trace.Span
+	ctx, span = r.tracer.Start(ctx, "GetOrganizations", trace.WithAttributes(
+		attribute.KeyValue{
+			Key:   "includeDeleted",
+			Value: attribute.BoolValue(includeDeleted),
+		},
+		attribute.KeyValue{
+			Key:   "page",
+			Value: attribute.IntValue(page),
+		},
+		attribute.KeyValue{
+			Key:   "pageSize",
+			Value: attribute.IntValue(pageSize),
+		},
+	))
+	defer span.End()
+
+	ctx, cancel := postgres.WithQueryTimeout(ctx, r.queryTimeout)
+	defer cancel()
+
+	connection, err := r.connectionPool.Acquire(ctx)
+	if err != nil {
+		return nil, 0, postgres.QueryError(ctx, ErrFailedAcquireConnection)
+	}
+	defer connection.Release()
+
+	var totalCount int
+	countSql := "SELECT COUNT(*) FROM organizations WHERE $1 OR deleted_at IS NULL"
+	if err := connection.QueryRow(ctx, countSql, includeDeleted).Scan(&totalCount); err != nil {
+		span.RecordError(err)
+		return nil, 0, postgres.QueryError(ctx, connect.NewError(connect.CodeInternal, errors.New("failed to count organizations")))
+	}
+
+	offset := (page - 1) * pageSize
+	sql := `SELECT id, slug, display_name, visibility::TEXT AS visibility, created_by, created_at, deleted_at
+		FROM organizations
+		WHERE $1 OR deleted_at IS NULL
+		ORDER BY created_at DESC, id
+		LIMIT $2 OFFSET $3`
+
+	rows, err := connection.Query(ctx, sql, includeDeleted, pageSize, offset)
+	if err != nil {
+		span.RecordError(err)
+		return nil, 0, postgres.QueryError(ctx, connect.NewError(connect.CodeInternal, errors.New("failed to query organizations")))
+	}
+
+	organizations, err := pgx.CollectRows(rows, pgx.RowToStructByName[admin.OrganizationDTO])
+	if err != nil {
+		span.RecordError(err)
+		return nil, 0, postgres.QueryError(ctx, connect.NewError(connect.CodeInternal, errors.New("failed to collect organization rows")))
+	}
+
+	return organizations, totalCount, nil
+}
+
+func (r *PgRepository) GetEmailJobByInviteId(ctx context.Context, inviteId string) (*admin.EmailJobDTO, error) {
+	var span trace.Span
+	ctx, span = r.tracer.Start(ctx, "GetEmailJobByInviteId", trace.WithAttributes(
+		attribute.KeyValue{
+			Key:   "inviteId",
+			Value: attribute.StringValue(inviteId),
+		},
+	))
+	defer span.End()
+
+	ctx, cancel := postgres.WithQueryTimeout(ctx, r.queryTimeout)
+	defer cancel()
+
+	connection, err := r.connectionPool.Acquire(ctx)
+	if err != nil {
+		return nil, postgres.QueryError(ctx, ErrFailedAcquireConnection)
+	}
+	defer connection.Release()
+
+	sql := `SELECT id, invite_id, organization_id, email, status, attempts, max_attempts,
+			created_at, processed_at, completed_at, error_message
+		FROM email_jobs
+		WHERE invite_id = $1
+		ORDER BY created_at DESC, id
+		LIMIT 1`
+
+	rows, err := connection.Query(ctx, sql, inviteId)
+	if err != nil {
+		span.RecordError(err)
+		return nil, postgres.QueryError(ctx, connect.NewError(connect.CodeInternal, errors.New("failed to query email job")))
+	}
+
+	job, err := pgx.CollectExactlyOneRow(rows, pgx.RowToAddrOfStructByName[admin.EmailJobDTO])
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, ErrEmailJobNotFound
+		}
+		span.RecordError(err)
+		return nil, postgres.QueryError(ctx, connect.NewError(connect.CodeInternal, errors.New("failed to collect email job")))
+	}
+
+	return job, nil
+}
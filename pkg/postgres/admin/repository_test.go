@@ -0,0 +1,232 @@
+package admin
+
+import (
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/testcontainers/testcontainers-go/modules/postgres"
+	"go.opentelemetry.io/otel/trace/noop"
+)
+
+func setupTestRepository(t *testing.T) (*PgRepository, *pgxpool.Pool) {
+	t.Helper()
+
+	container, err := postgres.Run(t.Context(),
+		"postgres:16-alpine",
+		postgres.WithDatabase("test"),
+		postgres.WithUsername("test"),
+		postgres.WithPassword("test"),
+		postgres.BasicWaitStrategies(),
+		postgres.WithSQLDriver("pgx"),
+	)
+	require.NoError(t, err)
+	t.Cleanup(func() {
+		if err := container.Terminate(t.Context()); err != nil {
+			t.Logf("failed to terminate postgres container: %v", err)
+		}
+	})
+
+	connString, err := container.ConnectionString(t.Context())
+	require.NoError(t, err)
+
+	conn, err := pgx.Connect(t.Context(), connString)
+	require.NoError(t, err)
+	defer func() {
+		require.NoError(t, conn.Close(t.Context()))
+	}()
+
+	_, err = conn.Exec(t.Context(), `CREATE TABLE email_jobs (
+		id VARCHAR(36) PRIMARY KEY,
+		invite_id VARCHAR(36) NOT NULL DEFAULT '',
+		organization_id VARCHAR(36) NOT NULL DEFAULT '',
+		email VARCHAR(255) NOT NULL DEFAULT '',
+		status VARCHAR(20) NOT NULL DEFAULT 'pending',
+		attempts INT NOT NULL DEFAULT 0,
+		max_attempts INT NOT NULL DEFAULT 3,
+		created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+		processed_at TIMESTAMP WITH TIME ZONE,
+		completed_at TIMESTAMP WITH TIME ZONE,
+		error_message TEXT
+	)`)
+	require.NoError(t, err)
+
+	_, err = conn.Exec(t.Context(), `CREATE TABLE sdk_generation_jobs (
+		id VARCHAR(36) PRIMARY KEY,
+		status VARCHAR(20) NOT NULL DEFAULT 'pending',
+		created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW()
+	)`)
+	require.NoError(t, err)
+
+	_, err = conn.Exec(t.Context(), `
+		CREATE TYPE visibility AS ENUM ('private', 'public');
+		CREATE TABLE organizations (
+			id VARCHAR(36) PRIMARY KEY,
+			slug VARCHAR(255) NOT NULL,
+			display_name VARCHAR(255) NOT NULL,
+			visibility visibility NOT NULL DEFAULT 'private',
+			created_by VARCHAR(36) NOT NULL,
+			created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+			deleted_at TIMESTAMP WITH TIME ZONE
+		)`)
+	require.NoError(t, err)
+
+	pool, err := pgxpool.New(t.Context(), connString)
+	require.NoError(t, err)
+	t.Cleanup(pool.Close)
+
+	return NewPgRepository(pool, noop.NewTracerProvider().Tracer("test"), 5*time.Second), pool
+}
+
+func insertJob(t *testing.T, pool *pgxpool.Pool, table, status string, createdAt time.Time) {
+	t.Helper()
+
+	_, err := pool.Exec(t.Context(),
+		`INSERT INTO `+table+` (id, status, created_at) VALUES ($1, $2, $3)`,
+		uuid.NewString(), status, createdAt,
+	)
+	require.NoError(t, err)
+}
+
+func TestPgRepository_GetJobStats(t *testing.T) {
+	repo, pool := setupTestRepository(t)
+
+	now := time.Now().UTC().Truncate(time.Microsecond)
+	oldestEmail := now.Add(-2 * time.Hour)
+	oldestSdk := now.Add(-30 * time.Minute)
+
+	insertJob(t, pool, "email_jobs", "pending", oldestEmail)
+	insertJob(t, pool, "email_jobs", "pending", now.Add(-time.Minute))
+	insertJob(t, pool, "email_jobs", "processing", now.Add(-3*time.Hour))
+	insertJob(t, pool, "email_jobs", "completed", now.Add(-4*time.Hour))
+	insertJob(t, pool, "email_jobs", "failed", now)
+	insertJob(t, pool, "email_jobs", "failed", now)
+	insertJob(t, pool, "email_jobs", "cancelled", now)
+
+	insertJob(t, pool, "sdk_generation_jobs", "pending", oldestSdk)
+	insertJob(t, pool, "sdk_generation_jobs", "completed", now.Add(-5*time.Hour))
+
+	t.Run("email jobs", func(t *testing.T) {
+		stats, err := repo.GetEmailJobStats(t.Context())
+		require.NoError(t, err)
+
+		assert.Equal(t, 2, stats.Pending)
+		assert.Equal(t, 1, stats.Processing)
+		assert.Equal(t, 1, stats.Completed)
+		assert.Equal(t, 2, stats.Failed)
+		assert.Equal(t, 1, stats.Cancelled)
+		require.NotNil(t, stats.OldestPendingAt)
+		assert.True(t, oldestEmail.Equal(*stats.OldestPendingAt))
+	})
+
+	t.Run("sdk generation jobs", func(t *testing.T) {
+		stats, err := repo.GetSdkGenerationJobStats(t.Context())
+		require.NoError(t, err)
+
+		assert.Equal(t, 1, stats.Pending)
+		assert.Equal(t, 0, stats.Processing)
+		assert.Equal(t, 1, stats.Completed)
+		assert.Equal(t, 0, stats.Failed)
+		require.NotNil(t, stats.OldestPendingAt)
+		assert.True(t, oldestSdk.Equal(*stats.OldestPendingAt))
+	})
+
+	t.Run("no pending jobs has no oldest age", func(t *testing.T) {
+		_, err := pool.Exec(t.Context(), `UPDATE sdk_generation_jobs SET status = 'completed'`)
+		require.NoError(t, err)
+
+		stats, err := repo.GetSdkGenerationJobStats(t.Context())
+		require.NoError(t, err)
+
+		assert.Equal(t, 0, stats.Pending)
+		assert.Equal(t, 2, stats.Completed)
+		assert.Nil(t, stats.OldestPendingAt)
+	})
+}
+
+func insertOrganization(t *testing.T, pool *pgxpool.Pool, slug string, createdAt time.Time, deletedAt *time.Time) string {
+	t.Helper()
+
+	id := uuid.NewString()
+	_, err := pool.Exec(t.Context(),
+		`INSERT INTO organizations (id, slug, display_name, created_by, created_at, deleted_at) VALUES ($1, $2, $2, $3, $4, $5)`,
+		id, slug, uuid.NewString(), createdAt, deletedAt,
+	)
+	require.NoError(t, err)
+
+	return id
+}
+
+func TestPgRepository_GetOrganizations(t *testing.T) {
+	repo, pool := setupTestRepository(t)
+
+	now := time.Now().UTC().Truncate(time.Microsecond)
+	deletedAt := now.Add(-time.Minute)
+
+	liveId := insertOrganization(t, pool, "live", now.Add(-time.Hour), nil)
+	deletedId := insertOrganization(t, pool, "gone", now, &deletedAt)
+
+	t.Run("excludes deleted organizations by default", func(t *testing.T) {
+		organizations, totalCount, err := repo.GetOrganizations(t.Context(), false, 1, 10)
+		require.NoError(t, err)
+
+		assert.Equal(t, 1, totalCount)
+		require.Len(t, organizations, 1)
+		assert.Equal(t, liveId, organizations[0].Id)
+		assert.Equal(t, "private", organizations[0].Visibility)
+		assert.Nil(t, organizations[0].DeletedAt)
+	})
+
+	t.Run("includes deleted organizations with their deletion time", func(t *testing.T) {
+		organizations, totalCount, err := repo.GetOrganizations(t.Context(), true, 1, 10)
+		require.NoError(t, err)
+
+		assert.Equal(t, 2, totalCount)
+		require.Len(t, organizations, 2)
+		assert.Equal(t, deletedId, organizations[0].Id)
+		require.NotNil(t, organizations[0].DeletedAt)
+		assert.True(t, deletedAt.Equal(*organizations[0].DeletedAt))
+		assert.Equal(t, liveId, organizations[1].Id)
+	})
+
+	t.Run("pages the results", func(t *testing.T) {
+		organizations, totalCount, err := repo.GetOrganizations(t.Context(), true, 2, 1)
+		require.NoError(t, err)
+
+		assert.Equal(t, 2, totalCount)
+		require.Len(t, organizations, 1)
+		assert.Equal(t, liveId, organizations[0].Id)
+	})
+}
+
+func TestPgRepository_GetEmailJobByInviteId(t *testing.T) {
+	repo, pool := setupTestRepository(t)
+
+	now := time.Now().UTC().Truncate(time.Microsecond)
+	inviteId := uuid.NewString()
+
+	_, err := pool.Exec(t.Context(), `INSERT INTO email_jobs (id, invite_id, email, status, attempts, created_at, error_message)
+		VALUES ($1, $2, 'friend@example.com', 'failed', 3, $3, 'smtp: connection refused'),
+			($4, $2, 'friend@example.com', 'completed', 1, $5, NULL)`,
+		"job-old", inviteId, now.Add(-time.Hour), "job-new", now)
+	require.NoError(t, err)
+
+	t.Run("returns the latest job for the invite", func(t *testing.T) {
+		job, err := repo.GetEmailJobByInviteId(t.Context(), inviteId)
+		require.NoError(t, err)
+
+		assert.Equal(t, "job-new", job.Id)
+		assert.Equal(t, "completed", job.Status)
+		assert.Equal(t, 1, job.Attempts)
+		assert.Nil(t, job.ErrorMessage)
+	})
+
+	t.Run("not found", func(t *testing.T) {
+		_, err := repo.GetEmailJobByInviteId(t.Context(), uuid.NewString())
+		require.ErrorIs(t, err, ErrEmailJobNotFound)
+	})
+}
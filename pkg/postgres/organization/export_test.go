@@ -0,0 +1,118 @@
+package organization
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"hasir-api/internal/organization"
+	"hasir-api/pkg/proto"
+)
+
+func TestPgRepository_GetPendingInvites(t *testing.T) {
+	container := setupPgContainer(t)
+	t.Cleanup(func() {
+		require.NoError(t, container.Terminate(context.Background()))
+	})
+
+	connString, err := container.ConnectionString(t.Context())
+	require.NoError(t, err)
+
+	createOrganizationsTable(t, connString)
+	createOrganizationInvitesTable(t, connString)
+	createUsersTable(t, connString)
+
+	repo, pool := setupTestRepository(t, connString)
+	t.Cleanup(pool.Close)
+
+	inviter := createTestUser(t, "inviter", "inviter@example.com")
+	insertTestUser(t, connString, inviter)
+
+	org := createTestOrganization(t, "export-org", proto.VisibilityPrivate)
+	require.NoError(t, repo.CreateOrganization(t.Context(), org))
+	other := createTestOrganization(t, "other-org", proto.VisibilityPrivate)
+	require.NoError(t, repo.CreateOrganization(t.Context(), other))
+
+	invite := func(orgId, email string) *organization.OrganizationInviteDTO {
+		return createTestInvite(t, orgId, email, uuid.NewString(), inviter.Id, organization.MemberRoleReader)
+	}
+
+	pending := invite(org.Id, "pending@example.com")
+	expired := invite(org.Id, "expired@example.com")
+	expired.ExpiresAt = time.Now().UTC().Add(-time.Hour)
+	accepted := invite(org.Id, "accepted@example.com")
+	elsewhere := invite(other.Id, "elsewhere@example.com")
+	require.NoError(t, repo.CreateInvites(t.Context(), []*organization.OrganizationInviteDTO{pending, expired, accepted, elsewhere}))
+
+	acceptedAt := time.Now().UTC()
+	require.NoError(t, repo.UpdateInviteStatus(t.Context(), accepted.Id, organization.InviteStatusAccepted, &acceptedAt))
+
+	invites, err := repo.GetPendingInvites(t.Context(), org.Id)
+	require.NoError(t, err)
+	require.Len(t, invites, 1)
+	assert.Equal(t, pending.Id, invites[0].Id)
+	assert.Equal(t, "inviter", invites[0].InviterUsername)
+	assert.Equal(t, "export-org", invites[0].OrganizationName)
+}
+
+func TestPgRepository_GetOrganizationRepositories(t *testing.T) {
+	container := setupPgContainer(t)
+	t.Cleanup(func() {
+		require.NoError(t, container.Terminate(context.Background()))
+	})
+
+	connString, err := container.ConnectionString(t.Context())
+	require.NoError(t, err)
+
+	createOrganizationsTable(t, connString)
+	createRepositoriesTable(t, connString)
+
+	conn, err := pgx.Connect(t.Context(), connString)
+	require.NoError(t, err)
+	t.Cleanup(func() {
+		_ = conn.Close(context.Background())
+	})
+
+	_, err = conn.Exec(t.Context(), `ALTER TABLE repositories ADD COLUMN archived BOOLEAN NOT NULL DEFAULT FALSE`)
+	require.NoError(t, err)
+
+	repo, pool := setupTestRepository(t, connString)
+	t.Cleanup(pool.Close)
+
+	org := createTestOrganization(t, "export-org", proto.VisibilityPrivate)
+	require.NoError(t, repo.CreateOrganization(t.Context(), org))
+
+	insert := func(orgId, name string, visibility proto.Visibility, archived bool, deleted bool) {
+		var deletedAt *time.Time
+		if deleted {
+			now := time.Now().UTC()
+			deletedAt = &now
+		}
+		_, err := conn.Exec(t.Context(),
+			`INSERT INTO repositories (id, name, visibility, organization_id, created_by, created_at, deleted_at, archived)
+			VALUES ($1, $2, $3, $4, $5, $6, $7, $8)`,
+			uuid.NewString(), name, visibility, orgId, uuid.NewString(), time.Now().UTC(), deletedAt, archived)
+		require.NoError(t, err)
+	}
+
+	insert(org.Id, "web", proto.VisibilityPublic, false, false)
+	insert(org.Id, "api", proto.VisibilityPrivate, false, false)
+	insert(org.Id, "legacy", proto.VisibilityPrivate, true, false)
+	insert(org.Id, "gone", proto.VisibilityPrivate, false, true)
+	insert(uuid.NewString(), "foreign", proto.VisibilityPrivate, false, false)
+
+	repositories, err := repo.GetOrganizationRepositories(t.Context(), org.Id)
+	require.NoError(t, err)
+	require.Len(t, repositories, 3)
+
+	assert.Equal(t, "api", repositories[0].Name)
+	assert.Equal(t, "legacy", repositories[1].Name)
+	assert.True(t, repositories[1].Archived)
+	assert.Equal(t, "web", repositories[2].Name)
+	assert.Equal(t, proto.VisibilityPublic, repositories[2].Visibility)
+}
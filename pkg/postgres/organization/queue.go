@@ -16,6 +16,7 @@ import (
 
 	"hasir-api/internal/organization"
 	"hasir-api/pkg/email"
+	"hasir-api/pkg/postgres"
 )
 
 type EmailJobQueue struct {
@@ -34,6 +35,16 @@ func NewEmailJobQueue(connectionPool *pgxpool.Pool, tracer trace.Tracer) *EmailJ
 	}
 }
 
+func (q *EmailJobQueue) withTx(ctx context.Context, fn func(pgx.Tx) error) error {
+	connection, err := q.connectionPool.Acquire(ctx)
+	if err != nil {
+		return connect.NewError(connect.CodeInternal, errors.New("failed to acquire connection"))
+	}
+	defer connection.Release()
+
+	return postgres.WithTx(ctx, connection, fn)
+}
+
 func (q *EmailJobQueue) Start(ctx context.Context, emailService email.Service, batchSize int, pollInterval time.Duration) {
 	q.processorWg.Add(1)
 	go func() {
@@ -70,61 +81,46 @@ func (q *EmailJobQueue) EnqueueEmailJobs(ctx context.Context, jobs []*organizati
 		return nil
 	}
 
-	connection, err := q.connectionPool.Acquire(ctx)
-	if err != nil {
-		return connect.NewError(connect.CodeInternal, errors.New("failed to acquire connection"))
-	}
-	defer connection.Release()
-
-	tx, err := connection.Begin(ctx)
-	if err != nil {
-		return connect.NewError(connect.CodeInternal, errors.New("failed to begin transaction"))
-	}
-	committed := false
-	defer func() {
-		if !committed {
-			_ = tx.Rollback(ctx)
-		}
-	}()
-
-	jobSQL := `INSERT INTO email_jobs (id, invite_id, organization_id, email, organization_name, invite_token, attempts, max_attempts, status, created_at)
+	err := q.withTx(ctx, func(tx pgx.Tx) error {
+		jobSQL := `INSERT INTO email_jobs (id, invite_id, organization_id, email, organization_name, invite_token, attempts, max_attempts, status, created_at)
 			VALUES (@Id, @InviteId, @OrganizationId, @Email, @OrganizationName, @InviteToken, @Attempts, @MaxAttempts, @Status, @CreatedAt)`
 
-	jobBatch := &pgx.Batch{}
-	for _, job := range jobs {
-		jobArgs := pgx.NamedArgs{
-			"Id":               job.Id,
-			"InviteId":         job.InviteId,
-			"OrganizationId":   job.OrganizationId,
-			"Email":            job.Email,
-			"OrganizationName": job.OrganizationName,
-			"InviteToken":      job.InviteToken,
-			"Attempts":         job.Attempts,
-			"MaxAttempts":      job.MaxAttempts,
-			"Status":           job.Status,
-			"CreatedAt":        job.CreatedAt,
+		jobBatch := &pgx.Batch{}
+		for _, job := range jobs {
+			jobArgs := pgx.NamedArgs{
+				"Id":               job.Id,
+				"InviteId":         job.InviteId,
+				"OrganizationId":   job.OrganizationId,
+				"Email":            job.Email,
+				"OrganizationName": job.OrganizationName,
+				"InviteToken":      job.InviteToken,
+				"Attempts":         job.Attempts,
+				"MaxAttempts":      job.MaxAttempts,
+				"Status":           job.Status,
+				"CreatedAt":        job.CreatedAt,
+			}
+			jobBatch.Queue(jobSQL, jobArgs)
 		}
-		jobBatch.Queue(jobSQL, jobArgs)
-	}
 
-	jobResults := tx.SendBatch(ctx, jobBatch)
+		jobResults := tx.SendBatch(ctx, jobBatch)
 
-	for i := 0; i < len(jobs); i++ {
-		_, err := jobResults.Exec()
-		if err != nil {
-			_ = jobResults.Close()
-			return connect.NewError(connect.CodeInternal, fmt.Errorf("failed to enqueue job %d: %w", i, err))
+		for i := 0; i < len(jobs); i++ {
+			_, err := jobResults.Exec()
+			if err != nil {
+				_ = jobResults.Close()
+				return connect.NewError(connect.CodeInternal, fmt.Errorf("failed to enqueue job %d: %w", i, err))
+			}
 		}
-	}
 
-	if err := jobResults.Close(); err != nil {
-		return connect.NewError(connect.CodeInternal, fmt.Errorf("failed to close job batch results: %w", err))
-	}
+		if err := jobResults.Close(); err != nil {
+			return connect.NewError(connect.CodeInternal, fmt.Errorf("failed to close job batch results: %w", err))
+		}
 
-	if err := tx.Commit(ctx); err != nil {
-		return connect.NewError(connect.CodeInternal, errors.New("failed to commit transaction"))
+		return nil
+	})
+	if err != nil {
+		return err
 	}
-	committed = true
 
 	zap.L().Info("email jobs enqueued successfully", zap.Int("count", len(jobs)))
 
@@ -188,25 +184,9 @@ func (q *EmailJobQueue) GetPendingEmailJobs(ctx context.Context, limit int) ([]*
 	))
 	defer span.End()
 
-	connection, err := q.connectionPool.Acquire(ctx)
-	if err != nil {
-		return nil, connect.NewError(connect.CodeInternal, errors.New("failed to acquire connection"))
-	}
-	defer connection.Release()
-
-	tx, err := connection.Begin(ctx)
-	if err != nil {
-		span.RecordError(err)
-		return nil, connect.NewError(connect.CodeInternal, errors.New("failed to begin transaction"))
-	}
-	committed := false
-	defer func() {
-		if !committed {
-			_ = tx.Rollback(ctx)
-		}
-	}()
-
-	sql := `UPDATE email_jobs
+	var jobs []organization.EmailJobDTO
+	err := q.withTx(ctx, func(tx pgx.Tx) error {
+		sql := `UPDATE email_jobs
 			SET status = 'processing', processed_at = NOW(), attempts = attempts + 1
 			WHERE id IN (
 				SELECT id FROM email_jobs
@@ -217,24 +197,24 @@ func (q *EmailJobQueue) GetPendingEmailJobs(ctx context.Context, limit int) ([]*
 			)
 			RETURNING id, invite_id, organization_id, email, organization_name, invite_token, status, attempts, max_attempts, created_at, processed_at, completed_at, error_message`
 
-	rows, err := tx.Query(ctx, sql, limit)
-	if err != nil {
-		span.RecordError(err)
-		return nil, connect.NewError(connect.CodeInternal, errors.New("failed to query and update pending email jobs"))
-	}
-	defer rows.Close()
+		rows, err := tx.Query(ctx, sql, limit)
+		if err != nil {
+			span.RecordError(err)
+			return connect.NewError(connect.CodeInternal, errors.New("failed to query and update pending email jobs"))
+		}
+		defer rows.Close()
 
-	jobs, err := pgx.CollectRows(rows, pgx.RowToStructByName[organization.EmailJobDTO])
-	if err != nil {
-		span.RecordError(err)
-		return nil, connect.NewError(connect.CodeInternal, errors.New("failed to collect email job rows"))
-	}
+		jobs, err = pgx.CollectRows(rows, pgx.RowToStructByName[organization.EmailJobDTO])
+		if err != nil {
+			span.RecordError(err)
+			return connect.NewError(connect.CodeInternal, errors.New("failed to collect email job rows"))
+		}
 
-	if err := tx.Commit(ctx); err != nil {
-		span.RecordError(err)
-		return nil, connect.NewError(connect.CodeInternal, errors.New("failed to commit transaction"))
+		return nil
+	})
+	if err != nil {
+		return nil, err
 	}
-	committed = true
 
 	result := make([]*organization.EmailJobDTO, len(jobs))
 	for i := range jobs {
@@ -4,7 +4,11 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"slices"
+	"strconv"
+	"strings"
 	"time"
+	"unicode/utf8"
 
 	"connectrpc.com/connect"
 	"github.com/exaring/otelpgx"
@@ -19,6 +23,7 @@ import (
 
 	"hasir-api/internal/organization"
 	"hasir-api/pkg/config"
+	"hasir-api/pkg/postgres"
 )
 
 var (
@@ -27,13 +32,20 @@ var (
 	ErrOrganizationNotFound      = connect.NewError(connect.CodeNotFound, errors.New("organization not found"))
 	ErrMemberAlreadyExists       = connect.NewError(connect.CodeAlreadyExists, errors.New("member already exists"))
 	ErrMemberNotFound            = connect.NewError(connect.CodeNotFound, errors.New("member not found"))
+	ErrNoOwnerLeft               = connect.NewError(connect.CodeFailedPrecondition, errors.New("organization must keep at least one owner"))
+	ErrInvalidInviteTransition   = connect.NewError(connect.CodeFailedPrecondition, errors.New("invite is no longer pending"))
 	ErrFailedAcquireConnection   = connect.NewError(connect.CodeInternal, errors.New("failed to acquire connection"))
 	ErrUniqueViolationCode       = "23505"
 )
 
 type OrganizationRepository struct {
-	connectionPool *pgxpool.Pool
-	tracer         trace.Tracer
+	connectionPool     *pgxpool.Pool
+	replicaPool        *pgxpool.Pool
+	tracer             trace.Tracer
+	queryTimeout       time.Duration
+	searchQueryTimeout time.Duration
+	// searchSimilarityThresholds falls back to the config defaults when empty.
+	searchSimilarityThresholds config.SearchSimilarityThresholds
 }
 
 func NewOrganizationRepository(
@@ -46,6 +58,10 @@ func NewOrganizationRepository(
 		zap.L().Fatal("failed to parse database config", zap.Error(err))
 	}
 
+	if err := postgres.ApplySchema(pgConfig, cfg.PostgresConfig.Schema); err != nil {
+		zap.L().Fatal("failed to apply database schema", zap.Error(err))
+	}
+
 	if traceProvider != nil {
 		pgConfig.ConnConfig.Tracer = otelpgx.NewTracer(
 			otelpgx.WithTracerProvider(traceProvider),
@@ -84,9 +100,29 @@ func NewOrganizationRepository(
 		tracer = noop.NewTracerProvider().Tracer("OrganizationPostgreSQLRepository")
 	}
 
+	replicaPool, err := postgres.NewReplicaPool(cfg.PostgresConfig, traceProvider)
+	if err != nil {
+		zap.L().Fatal("failed to connect read replica", zap.Error(err))
+	}
+
+	queryTimeout, err := cfg.PostgresConfig.GetQueryTimeout()
+	if err != nil {
+		zap.L().Fatal("failed to parse database query timeout", zap.Error(err))
+	}
+
+	searchQueryTimeout, err := cfg.PostgresConfig.GetSearchQueryTimeout()
+	if err != nil {
+		zap.L().Fatal("failed to parse database search query timeout", zap.Error(err))
+	}
+
 	return &OrganizationRepository{
-		connectionPool: pgConnectionPool,
-		tracer:         tracer,
+		connectionPool:     pgConnectionPool,
+		replicaPool:        replicaPool,
+		tracer:             tracer,
+		queryTimeout:       queryTimeout,
+		searchQueryTimeout: searchQueryTimeout,
+
+		searchSimilarityThresholds: cfg.PostgresConfig.SearchSimilarityThresholds,
 	}
 }
 
@@ -94,6 +130,20 @@ func (r *OrganizationRepository) GetConnectionPool() *pgxpool.Pool {
 	return r.connectionPool
 }
 
+func (r *OrganizationRepository) readPool(ctx context.Context) *pgxpool.Pool {
+	return postgres.ReadPool(ctx, r.connectionPool, r.replicaPool)
+}
+
+func (r *OrganizationRepository) withTx(ctx context.Context, fn func(pgx.Tx) error) error {
+	connection, err := r.connectionPool.Acquire(ctx)
+	if err != nil {
+		return postgres.QueryError(ctx, ErrFailedAcquireConnection)
+	}
+	defer connection.Release()
+
+	return postgres.WithTx(ctx, connection, fn)
+}
+
 func (r *OrganizationRepository) GetTracer() trace.Tracer {
 	return r.tracer
 }
@@ -102,7 +152,7 @@ func querySingleRow[T any](ctx context.Context, conn *pgxpool.Conn, span trace.S
 	rows, err := conn.Query(ctx, sql, args...)
 	if err != nil {
 		span.RecordError(err)
-		return nil, connect.NewError(connect.CodeInternal, errors.New("failed to execute query"))
+		return nil, postgres.QueryError(ctx, connect.NewError(connect.CodeInternal, errors.New("failed to execute query")))
 	}
 	defer rows.Close()
 
@@ -112,12 +162,18 @@ func querySingleRow[T any](ctx context.Context, conn *pgxpool.Conn, span trace.S
 			return nil, notFoundErr
 		}
 		span.RecordError(err)
-		return nil, connect.NewError(connect.CodeInternal, errors.New("failed to collect row"))
+		return nil, postgres.QueryError(ctx, connect.NewError(connect.CodeInternal, errors.New("failed to collect row")))
 	}
 
 	return &result, nil
 }
 
+// execer is what the insert helpers need from a pooled connection or a
+// transaction, so the same statements run either way.
+type execer interface {
+	Exec(ctx context.Context, sql string, arguments ...any) (pgconn.CommandTag, error)
+}
+
 func (r *OrganizationRepository) CreateOrganization(ctx context.Context, org *organization.OrganizationDTO) error {
 	var span trace.Span
 	ctx, span = r.tracer.Start(ctx, "CreateOrganization", trace.WithAttributes(attribute.KeyValue{
@@ -126,23 +182,63 @@ func (r *OrganizationRepository) CreateOrganization(ctx context.Context, org *or
 	}))
 	defer span.End()
 
+	ctx, cancel := postgres.WithQueryTimeout(ctx, r.queryTimeout)
+	defer cancel()
+
 	connection, err := r.connectionPool.Acquire(ctx)
 	if err != nil {
-		return ErrFailedAcquireConnection
+		return postgres.QueryError(ctx, ErrFailedAcquireConnection)
 	}
 	defer connection.Release()
 
-	sql := `INSERT INTO organizations (id, name, visibility, created_by, created_at)
-			VALUES (@Id, @Name, @Visibility, @CreatedBy, @CreatedAt)`
+	return insertOrganization(ctx, connection, span, org)
+}
+
+// CreateOrganizationWithOwner inserts the organization and its first owner in
+// one transaction; if either insert fails neither row is kept.
+func (r *OrganizationRepository) CreateOrganizationWithOwner(
+	ctx context.Context,
+	org *organization.OrganizationDTO,
+	owner *organization.OrganizationMemberDTO,
+) error {
+	var span trace.Span
+	ctx, span = r.tracer.Start(ctx, "CreateOrganizationWithOwner", trace.WithAttributes(
+		attribute.KeyValue{
+			Key:   "newOrganization",
+			Value: attribute.StringValue(fmt.Sprintf("%+v", org)),
+		},
+		attribute.KeyValue{
+			Key:   "ownerId",
+			Value: attribute.StringValue(owner.UserId),
+		},
+	))
+	defer span.End()
+
+	ctx, cancel := postgres.WithQueryTimeout(ctx, r.queryTimeout)
+	defer cancel()
+
+	return r.withTx(ctx, func(tx pgx.Tx) error {
+		if err := insertOrganization(ctx, tx, span, org); err != nil {
+			return err
+		}
+
+		return insertMember(ctx, tx, span, owner)
+	})
+}
+
+func insertOrganization(ctx context.Context, db execer, span trace.Span, org *organization.OrganizationDTO) error {
+	sql := `INSERT INTO organizations (id, slug, display_name, visibility, created_by, created_at)
+			VALUES (@Id, @Slug, @DisplayName, @Visibility, @CreatedBy, @CreatedAt)`
 	sqlArgs := pgx.NamedArgs{
-		"Id":         org.Id,
-		"Name":       org.Name,
-		"Visibility": org.Visibility,
-		"CreatedBy":  org.CreatedBy,
-		"CreatedAt":  time.Now().UTC(),
+		"Id":          org.Id,
+		"Slug":        org.Slug,
+		"DisplayName": org.DisplayName,
+		"Visibility":  org.Visibility,
+		"CreatedBy":   org.CreatedBy,
+		"CreatedAt":   time.Now().UTC(),
 	}
 
-	if _, err = connection.Exec(ctx, sql, sqlArgs); err != nil {
+	if _, err := db.Exec(ctx, sql, sqlArgs); err != nil {
 		span.RecordError(err)
 
 		var pgErr *pgconn.PgError
@@ -153,7 +249,7 @@ func (r *OrganizationRepository) CreateOrganization(ctx context.Context, org *or
 			return err
 		}
 
-		return connect.NewError(connect.CodeInternal, errors.New("failed to execute insert organization query"))
+		return postgres.QueryError(ctx, connect.NewError(connect.CodeInternal, errors.New("failed to execute insert organization query")))
 	}
 
 	return nil
@@ -173,9 +269,12 @@ func (r *OrganizationRepository) GetOrganizations(ctx context.Context, page, pag
 	))
 	defer span.End()
 
-	connection, err := r.connectionPool.Acquire(ctx)
+	ctx, cancel := postgres.WithQueryTimeout(ctx, r.queryTimeout)
+	defer cancel()
+
+	connection, err := r.readPool(ctx).Acquire(ctx)
 	if err != nil {
-		return nil, ErrFailedAcquireConnection
+		return nil, postgres.QueryError(ctx, ErrFailedAcquireConnection)
 	}
 	defer connection.Release()
 
@@ -186,14 +285,14 @@ func (r *OrganizationRepository) GetOrganizations(ctx context.Context, page, pag
 	rows, err = connection.Query(ctx, sql, pageSize, offset)
 	if err != nil {
 		span.RecordError(err)
-		return nil, connect.NewError(connect.CodeInternal, errors.New("failed to query organizations"))
+		return nil, postgres.QueryError(ctx, connect.NewError(connect.CodeInternal, errors.New("failed to query organizations")))
 	}
 	defer rows.Close()
 
 	orgs, err := pgx.CollectRows[organization.OrganizationDTO](rows, pgx.RowToStructByName)
 	if err != nil {
 		span.RecordError(err)
-		return nil, connect.NewError(connect.CodeInternal, errors.New("failed to collect rows"))
+		return nil, postgres.QueryError(ctx, connect.NewError(connect.CodeInternal, errors.New("failed to collect rows")))
 	}
 
 	return &orgs, nil
@@ -217,9 +316,12 @@ func (r *OrganizationRepository) GetUserOrganizations(ctx context.Context, userI
 	))
 	defer span.End()
 
-	connection, err := r.connectionPool.Acquire(ctx)
+	ctx, cancel := postgres.WithQueryTimeout(ctx, r.queryTimeout)
+	defer cancel()
+
+	connection, err := r.readPool(ctx).Acquire(ctx)
 	if err != nil {
-		return nil, ErrFailedAcquireConnection
+		return nil, postgres.QueryError(ctx, ErrFailedAcquireConnection)
 	}
 	defer connection.Release()
 
@@ -235,14 +337,14 @@ func (r *OrganizationRepository) GetUserOrganizations(ctx context.Context, userI
 	rows, err := connection.Query(ctx, sql, userId, pageSize, offset)
 	if err != nil {
 		span.RecordError(err)
-		return nil, connect.NewError(connect.CodeInternal, errors.New("failed to query user organizations"))
+		return nil, postgres.QueryError(ctx, connect.NewError(connect.CodeInternal, errors.New("failed to query user organizations")))
 	}
 	defer rows.Close()
 
 	orgs, err := pgx.CollectRows[organization.OrganizationDTO](rows, pgx.RowToStructByName)
 	if err != nil {
 		span.RecordError(err)
-		return nil, connect.NewError(connect.CodeInternal, errors.New("failed to collect user organization rows"))
+		return nil, postgres.QueryError(ctx, connect.NewError(connect.CodeInternal, errors.New("failed to collect user organization rows")))
 	}
 
 	return &orgs, nil
@@ -253,9 +355,12 @@ func (r *OrganizationRepository) GetOrganizationsCount(ctx context.Context) (int
 	ctx, span = r.tracer.Start(ctx, "GetOrganizationsCount")
 	defer span.End()
 
-	connection, err := r.connectionPool.Acquire(ctx)
+	ctx, cancel := postgres.WithQueryTimeout(ctx, r.queryTimeout)
+	defer cancel()
+
+	connection, err := r.readPool(ctx).Acquire(ctx)
 	if err != nil {
-		return 0, ErrFailedAcquireConnection
+		return 0, postgres.QueryError(ctx, ErrFailedAcquireConnection)
 	}
 	defer connection.Release()
 
@@ -265,7 +370,7 @@ func (r *OrganizationRepository) GetOrganizationsCount(ctx context.Context) (int
 	err = connection.QueryRow(ctx, sql).Scan(&count)
 	if err != nil {
 		span.RecordError(err)
-		return 0, connect.NewError(connect.CodeInternal, errors.New("failed to count organizations"))
+		return 0, postgres.QueryError(ctx, connect.NewError(connect.CodeInternal, errors.New("failed to count organizations")))
 	}
 
 	return count, nil
@@ -281,9 +386,12 @@ func (r *OrganizationRepository) GetUserOrganizationsCount(ctx context.Context,
 	))
 	defer span.End()
 
-	connection, err := r.connectionPool.Acquire(ctx)
+	ctx, cancel := postgres.WithQueryTimeout(ctx, r.queryTimeout)
+	defer cancel()
+
+	connection, err := r.readPool(ctx).Acquire(ctx)
 	if err != nil {
-		return 0, ErrFailedAcquireConnection
+		return 0, postgres.QueryError(ctx, ErrFailedAcquireConnection)
 	}
 	defer connection.Release()
 
@@ -297,7 +405,7 @@ func (r *OrganizationRepository) GetUserOrganizationsCount(ctx context.Context,
 	err = connection.QueryRow(ctx, sql, userId).Scan(&count)
 	if err != nil {
 		span.RecordError(err)
-		return 0, connect.NewError(connect.CodeInternal, errors.New("failed to count user organizations"))
+		return 0, postgres.QueryError(ctx, connect.NewError(connect.CodeInternal, errors.New("failed to count user organizations")))
 	}
 
 	return count, nil
@@ -313,16 +421,49 @@ func (r *OrganizationRepository) GetOrganizationByName(ctx context.Context, name
 	))
 	defer span.End()
 
-	connection, err := r.connectionPool.Acquire(ctx)
+	ctx, cancel := postgres.WithQueryTimeout(ctx, r.queryTimeout)
+	defer cancel()
+
+	connection, err := r.readPool(ctx).Acquire(ctx)
 	if err != nil {
-		return nil, ErrFailedAcquireConnection
+		return nil, postgres.QueryError(ctx, ErrFailedAcquireConnection)
 	}
 	defer connection.Release()
 
-	sql := "SELECT * FROM organizations WHERE name = $1 AND deleted_at IS NULL"
+	sql := "SELECT * FROM organizations WHERE LOWER(slug) = LOWER($1) AND deleted_at IS NULL"
 	return querySingleRow[organization.OrganizationDTO](ctx, connection, span, sql, []any{name}, ErrOrganizationNotFound)
 }
 
+func (r *OrganizationRepository) GetOrganizationByNameWithRole(ctx context.Context, name, userId string) (*organization.OrganizationWithRoleDTO, error) {
+	var span trace.Span
+	ctx, span = r.tracer.Start(ctx, "GetOrganizationByNameWithRole", trace.WithAttributes(
+		attribute.KeyValue{
+			Key:   "name",
+			Value: attribute.StringValue(name),
+		},
+		attribute.KeyValue{
+			Key:   "userId",
+			Value: attribute.StringValue(userId),
+		},
+	))
+	defer span.End()
+
+	ctx, cancel := postgres.WithQueryTimeout(ctx, r.queryTimeout)
+	defer cancel()
+
+	connection, err := r.readPool(ctx).Acquire(ctx)
+	if err != nil {
+		return nil, postgres.QueryError(ctx, ErrFailedAcquireConnection)
+	}
+	defer connection.Release()
+
+	sql := `SELECT o.id, o.slug, o.display_name, o.visibility, o.created_by, o.created_at, o.deleted_at, om.role
+			FROM organizations o
+			LEFT JOIN organization_members om ON om.organization_id = o.id AND om.user_id = $2
+			WHERE LOWER(o.slug) = LOWER($1) AND o.deleted_at IS NULL`
+	return querySingleRow[organization.OrganizationWithRoleDTO](ctx, connection, span, sql, []any{name, userId}, ErrOrganizationNotFound)
+}
+
 func (r *OrganizationRepository) GetOrganizationById(ctx context.Context, id string) (*organization.OrganizationDTO, error) {
 	var span trace.Span
 	ctx, span = r.tracer.Start(ctx, "GetOrganizationById", trace.WithAttributes(
@@ -333,9 +474,12 @@ func (r *OrganizationRepository) GetOrganizationById(ctx context.Context, id str
 	))
 	defer span.End()
 
-	connection, err := r.connectionPool.Acquire(ctx)
+	ctx, cancel := postgres.WithQueryTimeout(ctx, r.queryTimeout)
+	defer cancel()
+
+	connection, err := r.readPool(ctx).Acquire(ctx)
 	if err != nil {
-		return nil, ErrFailedAcquireConnection
+		return nil, postgres.QueryError(ctx, ErrFailedAcquireConnection)
 	}
 	defer connection.Release()
 
@@ -353,20 +497,23 @@ func (r *OrganizationRepository) UpdateOrganization(ctx context.Context, org *or
 	))
 	defer span.End()
 
+	ctx, cancel := postgres.WithQueryTimeout(ctx, r.queryTimeout)
+	defer cancel()
+
 	connection, err := r.connectionPool.Acquire(ctx)
 	if err != nil {
-		return ErrFailedAcquireConnection
+		return postgres.QueryError(ctx, ErrFailedAcquireConnection)
 	}
 	defer connection.Release()
 
 	sql := `UPDATE organizations
-			SET name = @Name,
+			SET display_name = @DisplayName,
 				visibility = @Visibility
 			WHERE id = @Id AND deleted_at IS NULL`
 	sqlArgs := pgx.NamedArgs{
-		"Id":         org.Id,
-		"Name":       org.Name,
-		"Visibility": org.Visibility,
+		"Id":          org.Id,
+		"DisplayName": org.DisplayName,
+		"Visibility":  org.Visibility,
 	}
 
 	result, err := connection.Exec(ctx, sql, sqlArgs)
@@ -381,7 +528,7 @@ func (r *OrganizationRepository) UpdateOrganization(ctx context.Context, org *or
 			return err
 		}
 
-		return connect.NewError(connect.CodeInternal, errors.New("failed to update organization"))
+		return postgres.QueryError(ctx, connect.NewError(connect.CodeInternal, errors.New("failed to update organization")))
 	}
 
 	if result.RowsAffected() == 0 {
@@ -401,30 +548,60 @@ func (r *OrganizationRepository) DeleteOrganization(ctx context.Context, id stri
 	))
 	defer span.End()
 
-	connection, err := r.connectionPool.Acquire(ctx)
-	if err != nil {
-		return ErrFailedAcquireConnection
-	}
-	defer connection.Release()
+	ctx, cancel := postgres.WithQueryTimeout(ctx, r.queryTimeout)
+	defer cancel()
 
-	now := time.Now().UTC()
-	sql := `UPDATE organizations SET deleted_at = @DeletedAt WHERE id = @Id AND deleted_at IS NULL`
-	sqlArgs := pgx.NamedArgs{
-		"Id":        id,
-		"DeletedAt": now,
-	}
+	return r.withTx(ctx, func(tx pgx.Tx) error {
+		now := time.Now().UTC()
+		sql := `UPDATE organizations SET deleted_at = @DeletedAt WHERE id = @Id AND deleted_at IS NULL`
+		sqlArgs := pgx.NamedArgs{
+			"Id":        id,
+			"DeletedAt": now,
+		}
 
-	result, err := connection.Exec(ctx, sql, sqlArgs)
-	if err != nil {
-		span.RecordError(err)
-		return connect.NewError(connect.CodeInternal, errors.New("failed to delete organization"))
-	}
+		result, err := tx.Exec(ctx, sql, sqlArgs)
+		if err != nil {
+			span.RecordError(err)
+			return postgres.QueryError(ctx, connect.NewError(connect.CodeInternal, errors.New("failed to delete organization")))
+		}
 
-	if result.RowsAffected() == 0 {
-		return ErrOrganizationNotFound
-	}
+		if result.RowsAffected() == 0 {
+			return ErrOrganizationNotFound
+		}
 
-	return nil
+		// Cascaded repositories share the organization's deleted_at, which is what
+		// distinguishes them from repositories that were deleted on their own.
+		sql = `UPDATE repositories SET deleted_at = @DeletedAt WHERE organization_id = @Id AND deleted_at IS NULL`
+		if _, err = tx.Exec(ctx, sql, sqlArgs); err != nil {
+			span.RecordError(err)
+			return postgres.QueryError(ctx, connect.NewError(connect.CodeInternal, errors.New("failed to delete organization repositories")))
+		}
+
+		// Work still queued for the organization would only fail later or, for
+		// invite emails, invite people into an organization that is gone. Jobs a
+		// worker already claimed are left to finish.
+		cancelSqls := []struct {
+			sql    string
+			target string
+		}{
+			{`UPDATE organization_invites SET status = 'cancelled' WHERE organization_id = @Id AND status = 'pending'`, "invites"},
+			{`UPDATE email_jobs SET status = 'cancelled' WHERE organization_id = @Id AND status = 'pending'`, "email jobs"},
+			{`UPDATE sdk_trigger_jobs SET status = 'cancelled'
+				WHERE status = 'pending' AND repository_id IN (SELECT id FROM repositories WHERE organization_id = @Id)`, "sdk trigger jobs"},
+			{`UPDATE sdk_generation_jobs SET status = 'cancelled'
+				WHERE status = 'pending' AND repository_id IN (SELECT id FROM repositories WHERE organization_id = @Id)`, "sdk generation jobs"},
+			{`UPDATE docs_generation_jobs SET status = 'cancelled'
+				WHERE status = 'pending' AND repository_id IN (SELECT id FROM repositories WHERE organization_id = @Id)`, "docs generation jobs"},
+		}
+		for _, cancelSql := range cancelSqls {
+			if _, err = tx.Exec(ctx, cancelSql.sql, sqlArgs); err != nil {
+				span.RecordError(err)
+				return postgres.QueryError(ctx, connect.NewError(connect.CodeInternal, fmt.Errorf("failed to cancel organization %s", cancelSql.target)))
+			}
+		}
+
+		return nil
+	})
 }
 
 func (r *OrganizationRepository) CreateInvites(ctx context.Context, invites []*organization.OrganizationInviteDTO) error {
@@ -441,12 +618,6 @@ func (r *OrganizationRepository) CreateInvites(ctx context.Context, invites []*o
 		return nil
 	}
 
-	connection, err := r.connectionPool.Acquire(ctx)
-	if err != nil {
-		return ErrFailedAcquireConnection
-	}
-	defer connection.Release()
-
 	orgID := invites[0].OrganizationId
 
 	emails := make([]string, 0, len(invites))
@@ -454,108 +625,94 @@ func (r *OrganizationRepository) CreateInvites(ctx context.Context, invites []*o
 		emails = append(emails, invite.Email)
 	}
 
-	existingEmails := make(map[string]struct{}, len(emails))
-	rows, err := connection.Query(
-		ctx,
-		`SELECT email FROM organization_invites WHERE organization_id = $1 AND status = 'pending' AND email = ANY($2)`,
-		orgID,
-		emails,
-	)
-	if err != nil {
-		span.RecordError(err)
-		return connect.NewError(connect.CodeInternal, errors.New("failed to check existing pending invites"))
-	}
-	defer rows.Close()
+	ctx, cancel := postgres.WithQueryTimeout(ctx, r.queryTimeout)
+	defer cancel()
 
-	for rows.Next() {
-		var email string
-		if err := rows.Scan(&email); err != nil {
+	return r.withTx(ctx, func(tx pgx.Tx) error {
+		existingEmails := make(map[string]struct{}, len(emails))
+		rows, err := tx.Query(
+			ctx,
+			`SELECT email FROM organization_invites WHERE organization_id = $1 AND status = 'pending' AND email = ANY($2)`,
+			orgID,
+			emails,
+		)
+		if err != nil {
 			span.RecordError(err)
-			return connect.NewError(connect.CodeInternal, errors.New("failed to scan existing pending invites"))
+			return postgres.QueryError(ctx, connect.NewError(connect.CodeInternal, errors.New("failed to check existing pending invites")))
 		}
-		existingEmails[email] = struct{}{}
-	}
-
-	if err := rows.Err(); err != nil {
-		span.RecordError(err)
-		return connect.NewError(connect.CodeInternal, errors.New("failed to iterate existing pending invites"))
-	}
+		defer rows.Close()
 
-	filteredInvites := make([]*organization.OrganizationInviteDTO, 0, len(invites))
-	for _, invite := range invites {
-		if _, found := existingEmails[invite.Email]; found {
-			continue
+		for rows.Next() {
+			var email string
+			if err := rows.Scan(&email); err != nil {
+				span.RecordError(err)
+				return postgres.QueryError(ctx, connect.NewError(connect.CodeInternal, errors.New("failed to scan existing pending invites")))
+			}
+			existingEmails[email] = struct{}{}
 		}
 
-		filteredInvites = append(filteredInvites, invite)
-	}
+		if err := rows.Err(); err != nil {
+			span.RecordError(err)
+			return postgres.QueryError(ctx, connect.NewError(connect.CodeInternal, errors.New("failed to iterate existing pending invites")))
+		}
+		rows.Close()
 
-	invites = filteredInvites
+		filteredInvites := make([]*organization.OrganizationInviteDTO, 0, len(invites))
+		for _, invite := range invites {
+			if _, found := existingEmails[invite.Email]; found {
+				continue
+			}
 
-	if len(invites) == 0 {
-		return nil
-	}
+			filteredInvites = append(filteredInvites, invite)
+		}
 
-	tx, err := connection.Begin(ctx)
-	if err != nil {
-		span.RecordError(err)
-		return connect.NewError(connect.CodeInternal, errors.New("failed to begin transaction"))
-	}
-	committed := false
-	defer func() {
-		if !committed {
-			_ = tx.Rollback(ctx)
+		if len(filteredInvites) == 0 {
+			return nil
 		}
-	}()
 
-	inviteSQL := `INSERT INTO organization_invites (id, organization_id, email, token, invited_by, role, status, created_at, expires_at)
+		inviteSQL := `INSERT INTO organization_invites (id, organization_id, email, token, invited_by, role, status, created_at, expires_at)
 			VALUES (@Id, @OrganizationId, @Email, @Token, @InvitedBy, @Role, @Status, @CreatedAt, @ExpiresAt)`
 
-	inviteBatch := &pgx.Batch{}
-	for _, invite := range invites {
-		sqlArgs := pgx.NamedArgs{
-			"Id":             invite.Id,
-			"OrganizationId": invite.OrganizationId,
-			"Email":          invite.Email,
-			"Token":          invite.Token,
-			"InvitedBy":      invite.InvitedBy,
-			"Role":           invite.Role,
-			"Status":         invite.Status,
-			"CreatedAt":      invite.CreatedAt,
-			"ExpiresAt":      invite.ExpiresAt,
+		inviteBatch := &pgx.Batch{}
+		for _, invite := range filteredInvites {
+			sqlArgs := pgx.NamedArgs{
+				"Id":             invite.Id,
+				"OrganizationId": invite.OrganizationId,
+				"Email":          invite.Email,
+				"Token":          invite.Token,
+				"InvitedBy":      invite.InvitedBy,
+				"Role":           invite.Role,
+				"Status":         invite.Status,
+				"CreatedAt":      invite.CreatedAt,
+				"ExpiresAt":      invite.ExpiresAt,
+			}
+			inviteBatch.Queue(inviteSQL, sqlArgs)
 		}
-		inviteBatch.Queue(inviteSQL, sqlArgs)
-	}
-
-	inviteResults := tx.SendBatch(ctx, inviteBatch)
 
-	for i := 0; i < len(invites); i++ {
-		_, err := inviteResults.Exec()
-		if err != nil {
-			span.RecordError(err)
-			_ = inviteResults.Close()
-			var pgErr *pgconn.PgError
-			if errors.As(err, &pgErr) {
-				if pgErr.Code == ErrUniqueViolationCode {
-					return connect.NewError(connect.CodeAlreadyExists, fmt.Errorf("invite already exists for email: %s", invites[i].Email))
+		inviteResults := tx.SendBatch(ctx, inviteBatch)
+
+		for i := 0; i < len(filteredInvites); i++ {
+			_, err := inviteResults.Exec()
+			if err != nil {
+				span.RecordError(err)
+				_ = inviteResults.Close()
+				var pgErr *pgconn.PgError
+				if errors.As(err, &pgErr) {
+					if pgErr.Code == ErrUniqueViolationCode {
+						return connect.NewError(connect.CodeAlreadyExists, fmt.Errorf("invite already exists for email: %s", filteredInvites[i].Email))
+					}
 				}
+				return postgres.QueryError(ctx, connect.NewError(connect.CodeInternal, fmt.Errorf("failed to create invite %d: %w", i, err)))
 			}
-			return connect.NewError(connect.CodeInternal, fmt.Errorf("failed to create invite %d: %w", i, err))
 		}
-	}
 
-	if err := inviteResults.Close(); err != nil {
-		span.RecordError(err)
-		return connect.NewError(connect.CodeInternal, fmt.Errorf("failed to close invite batch results: %w", err))
-	}
-
-	if err := tx.Commit(ctx); err != nil {
-		span.RecordError(err)
-		return connect.NewError(connect.CodeInternal, errors.New("failed to commit transaction"))
-	}
-	committed = true
+		if err := inviteResults.Close(); err != nil {
+			span.RecordError(err)
+			return postgres.QueryError(ctx, connect.NewError(connect.CodeInternal, fmt.Errorf("failed to close invite batch results: %w", err)))
+		}
 
-	return nil
+		return nil
+	})
 }
 
 func (r *OrganizationRepository) GetInviteByToken(ctx context.Context, token string) (*organization.OrganizationInviteDTO, error) {
@@ -568,9 +725,12 @@ func (r *OrganizationRepository) GetInviteByToken(ctx context.Context, token str
 	))
 	defer span.End()
 
+	ctx, cancel := postgres.WithQueryTimeout(ctx, r.queryTimeout)
+	defer cancel()
+
 	connection, err := r.connectionPool.Acquire(ctx)
 	if err != nil {
-		return nil, ErrFailedAcquireConnection
+		return nil, postgres.QueryError(ctx, ErrFailedAcquireConnection)
 	}
 	defer connection.Release()
 
@@ -578,6 +738,131 @@ func (r *OrganizationRepository) GetInviteByToken(ctx context.Context, token str
 	return querySingleRow[organization.OrganizationInviteDTO](ctx, connection, span, sql, []any{token}, ErrInviteNotFound)
 }
 
+func (r *OrganizationRepository) GetInviteDetailsByToken(ctx context.Context, token string) (*organization.OrganizationInviteDetailsDTO, error) {
+	var span trace.Span
+	ctx, span = r.tracer.Start(ctx, "GetInviteDetailsByToken", trace.WithAttributes(
+		attribute.KeyValue{
+			Key:   "token",
+			Value: attribute.StringValue(token),
+		},
+	))
+	defer span.End()
+
+	ctx, cancel := postgres.WithQueryTimeout(ctx, r.queryTimeout)
+	defer cancel()
+
+	connection, err := r.connectionPool.Acquire(ctx)
+	if err != nil {
+		return nil, postgres.QueryError(ctx, ErrFailedAcquireConnection)
+	}
+	defer connection.Release()
+
+	sql := `SELECT i.id, i.organization_id, i.email, i.token, i.invited_by, i.role, i.status,
+				i.created_at, i.expires_at, i.accepted_at,
+				o.display_name AS organization_name,
+				COALESCE(u.username, $2) AS inviter_username
+			FROM organization_invites i
+			INNER JOIN organizations o ON o.id = i.organization_id AND o.deleted_at IS NULL
+			LEFT JOIN users u ON u.id = i.invited_by AND u.deleted_at IS NULL
+			WHERE i.token = $1`
+	return querySingleRow[organization.OrganizationInviteDetailsDTO](
+		ctx, connection, span, sql, []any{token, organization.DeletedInviterUsername}, ErrInviteNotFound,
+	)
+}
+
+// GetInvitesForEmail returns the pending invites for exactly email that have
+// not expired, newest first. Invites to deleted organizations are left out.
+// The token is the credential that accepts an invite, so it is not returned.
+func (r *OrganizationRepository) GetInvitesForEmail(ctx context.Context, email string) ([]organization.OrganizationInviteDetailsDTO, error) {
+	var span trace.Span
+	ctx, span = r.tracer.Start(ctx, "GetInvitesForEmail", trace.WithAttributes(
+		attribute.KeyValue{
+			Key:   "email",
+			Value: attribute.StringValue(email),
+		},
+	))
+	defer span.End()
+
+	ctx, cancel := postgres.WithQueryTimeout(ctx, r.queryTimeout)
+	defer cancel()
+
+	connection, err := r.connectionPool.Acquire(ctx)
+	if err != nil {
+		return nil, postgres.QueryError(ctx, ErrFailedAcquireConnection)
+	}
+	defer connection.Release()
+
+	sql := `SELECT i.id, i.organization_id, i.email, '' AS token, i.invited_by, i.role, i.status,
+				i.created_at, i.expires_at, i.accepted_at,
+				o.display_name AS organization_name,
+				COALESCE(u.username, $2) AS inviter_username
+			FROM organization_invites i
+			INNER JOIN organizations o ON o.id = i.organization_id AND o.deleted_at IS NULL
+			LEFT JOIN users u ON u.id = i.invited_by AND u.deleted_at IS NULL
+			WHERE i.email = $1 AND i.status = 'pending' AND i.expires_at > NOW()
+			ORDER BY i.created_at DESC, i.id`
+
+	rows, err := connection.Query(ctx, sql, email, organization.DeletedInviterUsername)
+	if err != nil {
+		span.RecordError(err)
+		return nil, postgres.QueryError(ctx, connect.NewError(connect.CodeInternal, errors.New("failed to query invites")))
+	}
+
+	invites, err := pgx.CollectRows(rows, pgx.RowToStructByName[organization.OrganizationInviteDetailsDTO])
+	if err != nil {
+		span.RecordError(err)
+		return nil, postgres.QueryError(ctx, connect.NewError(connect.CodeInternal, errors.New("failed to collect invites")))
+	}
+
+	return invites, nil
+}
+
+// GetPendingInvites lists the organization's invites that can still be
+// accepted, oldest first.
+func (r *OrganizationRepository) GetPendingInvites(ctx context.Context, organizationId string) ([]organization.OrganizationInviteDetailsDTO, error) {
+	var span trace.Span
+	ctx, span = r.tracer.Start(ctx, "GetPendingInvites", trace.WithAttributes(
+		attribute.KeyValue{
+			Key:   "organizationId",
+			Value: attribute.StringValue(organizationId),
+		},
+	))
+	defer span.End()
+
+	ctx, cancel := postgres.WithQueryTimeout(ctx, r.queryTimeout)
+	defer cancel()
+
+	connection, err := r.readPool(ctx).Acquire(ctx)
+	if err != nil {
+		return nil, postgres.QueryError(ctx, ErrFailedAcquireConnection)
+	}
+	defer connection.Release()
+
+	sql := `SELECT i.id, i.organization_id, i.email, i.token, i.invited_by, i.role, i.status,
+				i.created_at, i.expires_at, i.accepted_at,
+				o.display_name AS organization_name,
+				COALESCE(u.username, $2) AS inviter_username
+			FROM organization_invites i
+			INNER JOIN organizations o ON o.id = i.organization_id
+			LEFT JOIN users u ON u.id = i.invited_by AND u.deleted_at IS NULL
+			WHERE i.organization_id = $1 AND i.status = 'pending' AND i.expires_at > NOW()
+			ORDER BY i.created_at ASC, i.id`
+
+	rows, err := connection.Query(ctx, sql, organizationId, organization.DeletedInviterUsername)
+	if err != nil {
+		span.RecordError(err)
+		return nil, postgres.QueryError(ctx, connect.NewError(connect.CodeInternal, errors.New("failed to query pending invites")))
+	}
+
+	invites, err := pgx.CollectRows(rows, pgx.RowToStructByName[organization.OrganizationInviteDetailsDTO])
+	if err != nil {
+		span.RecordError(err)
+		return nil, postgres.QueryError(ctx, connect.NewError(connect.CodeInternal, errors.New("failed to collect pending invites")))
+	}
+
+	return invites, nil
+}
+
 func (r *OrganizationRepository) UpdateInviteStatus(ctx context.Context, id string, status organization.InviteStatus, acceptedAt *time.Time) error {
 	var span trace.Span
 	ctx, span = r.tracer.Start(ctx, "UpdateInviteStatus", trace.WithAttributes(
@@ -592,30 +877,50 @@ func (r *OrganizationRepository) UpdateInviteStatus(ctx context.Context, id stri
 	))
 	defer span.End()
 
+	ctx, cancel := postgres.WithQueryTimeout(ctx, r.queryTimeout)
+	defer cancel()
+
 	connection, err := r.connectionPool.Acquire(ctx)
 	if err != nil {
-		return ErrFailedAcquireConnection
+		return postgres.QueryError(ctx, ErrFailedAcquireConnection)
 	}
 	defer connection.Release()
 
-	sql := `UPDATE organization_invites SET status = @Status, accepted_at = @AcceptedAt WHERE id = @Id`
-	sqlArgs := pgx.NamedArgs{
-		"Id":         id,
-		"Status":     status,
-		"AcceptedAt": acceptedAt,
-	}
+	return postgres.WithTx(ctx, connection, func(tx pgx.Tx) error {
+		var current organization.InviteStatus
+		err := tx.QueryRow(ctx, `SELECT status FROM organization_invites WHERE id = $1 FOR UPDATE`, id).Scan(&current)
+		if errors.Is(err, pgx.ErrNoRows) {
+			return ErrInviteNotFound
+		}
+		if err != nil {
+			span.RecordError(err)
+			return postgres.QueryError(ctx, connect.NewError(connect.CodeInternal, errors.New("failed to get invite status")))
+		}
 
-	result, err := connection.Exec(ctx, sql, sqlArgs)
-	if err != nil {
-		span.RecordError(err)
-		return connect.NewError(connect.CodeInternal, errors.New("failed to update invite status"))
-	}
+		// A retried or raced update that already landed succeeds without
+		// touching the row, so accepted_at keeps the first acceptance time.
+		if current == status && current != organization.InviteStatusPending {
+			return nil
+		}
 
-	if result.RowsAffected() == 0 {
-		return ErrInviteNotFound
-	}
+		if !current.CanTransitionTo(status) {
+			return ErrInvalidInviteTransition
+		}
 
-	return nil
+		sql := `UPDATE organization_invites SET status = @Status, accepted_at = @AcceptedAt WHERE id = @Id`
+		sqlArgs := pgx.NamedArgs{
+			"Id":         id,
+			"Status":     status,
+			"AcceptedAt": acceptedAt,
+		}
+
+		if _, err := tx.Exec(ctx, sql, sqlArgs); err != nil {
+			span.RecordError(err)
+			return postgres.QueryError(ctx, connect.NewError(connect.CodeInternal, errors.New("failed to update invite status")))
+		}
+
+		return nil
+	})
 }
 
 func (r *OrganizationRepository) AddMember(ctx context.Context, member *organization.OrganizationMemberDTO) error {
@@ -626,23 +931,36 @@ func (r *OrganizationRepository) AddMember(ctx context.Context, member *organiza
 	}))
 	defer span.End()
 
+	ctx, cancel := postgres.WithQueryTimeout(ctx, r.queryTimeout)
+	defer cancel()
+
 	connection, err := r.connectionPool.Acquire(ctx)
 	if err != nil {
-		return ErrFailedAcquireConnection
+		return postgres.QueryError(ctx, ErrFailedAcquireConnection)
 	}
 	defer connection.Release()
 
-	sql := `INSERT INTO organization_members (id, organization_id, user_id, role, joined_at)
-			VALUES (@Id, @OrganizationId, @UserId, @Role, @JoinedAt)`
+	return insertMember(ctx, connection, span, member)
+}
+
+func insertMember(ctx context.Context, db execer, span trace.Span, member *organization.OrganizationMemberDTO) error {
+	joinedVia := member.JoinedVia
+	if joinedVia == "" {
+		joinedVia = organization.JoinedViaDirect
+	}
+
+	sql := `INSERT INTO organization_members (id, organization_id, user_id, role, joined_at, joined_via)
+			VALUES (@Id, @OrganizationId, @UserId, @Role, @JoinedAt, @JoinedVia)`
 	sqlArgs := pgx.NamedArgs{
 		"Id":             member.Id,
 		"OrganizationId": member.OrganizationId,
 		"UserId":         member.UserId,
 		"Role":           member.Role,
 		"JoinedAt":       member.JoinedAt,
+		"JoinedVia":      joinedVia,
 	}
 
-	if _, err = connection.Exec(ctx, sql, sqlArgs); err != nil {
+	if _, err := db.Exec(ctx, sql, sqlArgs); err != nil {
 		span.RecordError(err)
 
 		var pgErr *pgconn.PgError
@@ -653,7 +971,7 @@ func (r *OrganizationRepository) AddMember(ctx context.Context, member *organiza
 			return err
 		}
 
-		return connect.NewError(connect.CodeInternal, errors.New("failed to add member"))
+		return postgres.QueryError(ctx, connect.NewError(connect.CodeInternal, errors.New("failed to add member")))
 	}
 
 	return nil
@@ -675,28 +993,31 @@ func (r *OrganizationRepository) GetMembers(ctx context.Context, organizationId
 	))
 	defer span.End()
 
-	connection, err := r.connectionPool.Acquire(ctx)
+	ctx, cancel := postgres.WithQueryTimeout(ctx, r.queryTimeout)
+	defer cancel()
+
+	connection, err := r.readPool(ctx).Acquire(ctx)
 	if err != nil {
-		return nil, nil, nil, ErrFailedAcquireConnection
+		return nil, nil, nil, postgres.QueryError(ctx, ErrFailedAcquireConnection)
 	}
 	defer connection.Release()
 
-	sql := `SELECT id, organization_id, user_id, role, joined_at, username, email
+	sql := `SELECT id, organization_id, user_id, role, joined_at, joined_via, username, email
 			FROM organization_members_view
 			WHERE organization_id = $1
-			ORDER BY joined_at ASC`
+			ORDER BY joined_at ASC, id ASC`
 
 	rows, err := connection.Query(ctx, sql, organizationId)
 	if err != nil {
 		span.RecordError(err)
-		return nil, nil, nil, connect.NewError(connect.CodeInternal, errors.New("failed to query members"))
+		return nil, nil, nil, postgres.QueryError(ctx, connect.NewError(connect.CodeInternal, errors.New("failed to query members")))
 	}
 	defer rows.Close()
 
 	memberRows, err := pgx.CollectRows[memberRow](rows, pgx.RowToStructByName)
 	if err != nil {
 		span.RecordError(err)
-		return nil, nil, nil, connect.NewError(connect.CodeInternal, errors.New("failed to collect member rows"))
+		return nil, nil, nil, postgres.QueryError(ctx, connect.NewError(connect.CodeInternal, errors.New("failed to collect member rows")))
 	}
 
 	members := make([]*organization.OrganizationMemberDTO, len(memberRows))
@@ -726,9 +1047,12 @@ func (r *OrganizationRepository) GetMemberRole(ctx context.Context, organization
 	))
 	defer span.End()
 
+	ctx, cancel := postgres.WithQueryTimeout(ctx, r.queryTimeout)
+	defer cancel()
+
 	connection, err := r.connectionPool.Acquire(ctx)
 	if err != nil {
-		return "", ErrFailedAcquireConnection
+		return "", postgres.QueryError(ctx, ErrFailedAcquireConnection)
 	}
 	defer connection.Release()
 
@@ -741,7 +1065,7 @@ func (r *OrganizationRepository) GetMemberRole(ctx context.Context, organization
 			return "", ErrMemberNotFound
 		}
 		span.RecordError(err)
-		return "", connect.NewError(connect.CodeInternal, errors.New("failed to query member role"))
+		return "", postgres.QueryError(ctx, connect.NewError(connect.CodeInternal, errors.New("failed to query member role")))
 	}
 
 	return role, nil
@@ -752,6 +1076,45 @@ func (r *OrganizationRepository) GetMemberRoleString(ctx context.Context, organi
 	return string(role), err
 }
 
+func (r *OrganizationRepository) GetOrganizationRepositories(ctx context.Context, organizationId string) ([]organization.OrganizationRepositoryDTO, error) {
+	var span trace.Span
+	ctx, span = r.tracer.Start(ctx, "GetOrganizationRepositories", trace.WithAttributes(
+		attribute.KeyValue{
+			Key:   "organizationId",
+			Value: attribute.StringValue(organizationId),
+		},
+	))
+	defer span.End()
+
+	ctx, cancel := postgres.WithQueryTimeout(ctx, r.queryTimeout)
+	defer cancel()
+
+	connection, err := r.readPool(ctx).Acquire(ctx)
+	if err != nil {
+		return nil, postgres.QueryError(ctx, ErrFailedAcquireConnection)
+	}
+	defer connection.Release()
+
+	sql := `SELECT id, name, visibility, archived, created_at
+			FROM repositories
+			WHERE organization_id = $1 AND deleted_at IS NULL
+			ORDER BY name ASC, id ASC`
+
+	rows, err := connection.Query(ctx, sql, organizationId)
+	if err != nil {
+		span.RecordError(err)
+		return nil, postgres.QueryError(ctx, connect.NewError(connect.CodeInternal, errors.New("failed to query organization repositories")))
+	}
+
+	repositories, err := pgx.CollectRows(rows, pgx.RowToStructByName[organization.OrganizationRepositoryDTO])
+	if err != nil {
+		span.RecordError(err)
+		return nil, postgres.QueryError(ctx, connect.NewError(connect.CodeInternal, errors.New("failed to collect organization repositories")))
+	}
+
+	return repositories, nil
+}
+
 func (r *OrganizationRepository) GetOwnerCount(ctx context.Context, organizationId string) (int, error) {
 	var span trace.Span
 	ctx, span = r.tracer.Start(ctx, "GetOwnerCount", trace.WithAttributes(
@@ -762,9 +1125,12 @@ func (r *OrganizationRepository) GetOwnerCount(ctx context.Context, organization
 	))
 	defer span.End()
 
+	ctx, cancel := postgres.WithQueryTimeout(ctx, r.queryTimeout)
+	defer cancel()
+
 	connection, err := r.connectionPool.Acquire(ctx)
 	if err != nil {
-		return 0, ErrFailedAcquireConnection
+		return 0, postgres.QueryError(ctx, ErrFailedAcquireConnection)
 	}
 	defer connection.Release()
 
@@ -774,7 +1140,38 @@ func (r *OrganizationRepository) GetOwnerCount(ctx context.Context, organization
 	err = connection.QueryRow(ctx, sql, organizationId, organization.MemberRoleOwner).Scan(&count)
 	if err != nil {
 		span.RecordError(err)
-		return 0, connect.NewError(connect.CodeInternal, errors.New("failed to query owner count"))
+		return 0, postgres.QueryError(ctx, connect.NewError(connect.CodeInternal, errors.New("failed to query owner count")))
+	}
+
+	return count, nil
+}
+
+func (r *OrganizationRepository) GetMembersCount(ctx context.Context, organizationId string) (int, error) {
+	var span trace.Span
+	ctx, span = r.tracer.Start(ctx, "GetMembersCount", trace.WithAttributes(
+		attribute.KeyValue{
+			Key:   "organizationId",
+			Value: attribute.StringValue(organizationId),
+		},
+	))
+	defer span.End()
+
+	ctx, cancel := postgres.WithQueryTimeout(ctx, r.queryTimeout)
+	defer cancel()
+
+	connection, err := r.connectionPool.Acquire(ctx)
+	if err != nil {
+		return 0, postgres.QueryError(ctx, ErrFailedAcquireConnection)
+	}
+	defer connection.Release()
+
+	sql := `SELECT COUNT(*) FROM organization_members_view WHERE organization_id = $1`
+
+	var count int
+	err = connection.QueryRow(ctx, sql, organizationId).Scan(&count)
+	if err != nil {
+		span.RecordError(err)
+		return 0, postgres.QueryError(ctx, connect.NewError(connect.CodeInternal, errors.New("failed to query members count")))
 	}
 
 	return count, nil
@@ -798,9 +1195,12 @@ func (r *OrganizationRepository) UpdateMemberRole(ctx context.Context, organizat
 	))
 	defer span.End()
 
+	ctx, cancel := postgres.WithQueryTimeout(ctx, r.queryTimeout)
+	defer cancel()
+
 	connection, err := r.connectionPool.Acquire(ctx)
 	if err != nil {
-		return ErrFailedAcquireConnection
+		return postgres.QueryError(ctx, ErrFailedAcquireConnection)
 	}
 	defer connection.Release()
 
@@ -814,7 +1214,7 @@ func (r *OrganizationRepository) UpdateMemberRole(ctx context.Context, organizat
 	result, err := connection.Exec(ctx, sql, sqlArgs)
 	if err != nil {
 		span.RecordError(err)
-		return connect.NewError(connect.CodeInternal, errors.New("failed to update member role"))
+		return postgres.QueryError(ctx, connect.NewError(connect.CodeInternal, errors.New("failed to update member role")))
 	}
 
 	if result.RowsAffected() == 0 {
@@ -824,6 +1224,69 @@ func (r *OrganizationRepository) UpdateMemberRole(ctx context.Context, organizat
 	return nil
 }
 
+// UpdateMemberRoles applies all changes in one transaction and rolls them
+// back when no owner would remain. The membership rows are locked first so a
+// concurrent change cannot pass the owner check alongside this one.
+func (r *OrganizationRepository) UpdateMemberRoles(ctx context.Context, organizationId string, changes map[string]organization.MemberRole) error {
+	var span trace.Span
+	ctx, span = r.tracer.Start(ctx, "UpdateMemberRoles", trace.WithAttributes(
+		attribute.KeyValue{
+			Key:   "organizationId",
+			Value: attribute.StringValue(organizationId),
+		},
+		attribute.KeyValue{
+			Key:   "changeCount",
+			Value: attribute.IntValue(len(changes)),
+		},
+	))
+	defer span.End()
+
+	ctx, cancel := postgres.WithQueryTimeout(ctx, r.queryTimeout)
+	defer cancel()
+
+	userIds := make([]string, 0, len(changes))
+	for userId := range changes {
+		userIds = append(userIds, userId)
+	}
+	slices.Sort(userIds)
+
+	return r.withTx(ctx, func(tx pgx.Tx) error {
+		lockSQL := `SELECT id FROM organization_members WHERE organization_id = $1 ORDER BY id FOR UPDATE`
+		if _, err := tx.Exec(ctx, lockSQL, organizationId); err != nil {
+			span.RecordError(err)
+			return postgres.QueryError(ctx, connect.NewError(connect.CodeInternal, errors.New("failed to lock members")))
+		}
+
+		sql := `UPDATE organization_members SET role = @Role WHERE organization_id = @OrganizationId AND user_id = @UserId`
+		for _, userId := range userIds {
+			result, err := tx.Exec(ctx, sql, pgx.NamedArgs{
+				"OrganizationId": organizationId,
+				"UserId":         userId,
+				"Role":           changes[userId],
+			})
+			if err != nil {
+				span.RecordError(err)
+				return postgres.QueryError(ctx, connect.NewError(connect.CodeInternal, errors.New("failed to update member role")))
+			}
+			if result.RowsAffected() == 0 {
+				return ErrMemberNotFound
+			}
+		}
+
+		var ownerCount int
+		countSQL := `SELECT COUNT(*) FROM organization_members WHERE organization_id = $1 AND role = 'owner'`
+		if err := tx.QueryRow(ctx, countSQL, organizationId).Scan(&ownerCount); err != nil {
+			span.RecordError(err)
+			return postgres.QueryError(ctx, connect.NewError(connect.CodeInternal, errors.New("failed to count owners")))
+		}
+		if ownerCount == 0 {
+			return ErrNoOwnerLeft
+		}
+
+		return nil
+	})
+}
+
 func (r *OrganizationRepository) DeleteMember(ctx context.Context, organizationId, userId string) error {
 	var span trace.Span
 	ctx, span = r.tracer.Start(ctx, "DeleteMember", trace.WithAttributes(
@@ -838,9 +1301,12 @@ func (r *OrganizationRepository) DeleteMember(ctx context.Context, organizationI
 	))
 	defer span.End()
 
+	ctx, cancel := postgres.WithQueryTimeout(ctx, r.queryTimeout)
+	defer cancel()
+
 	connection, err := r.connectionPool.Acquire(ctx)
 	if err != nil {
-		return ErrFailedAcquireConnection
+		return postgres.QueryError(ctx, ErrFailedAcquireConnection)
 	}
 	defer connection.Release()
 
@@ -853,7 +1319,7 @@ func (r *OrganizationRepository) DeleteMember(ctx context.Context, organizationI
 	result, err := connection.Exec(ctx, sql, sqlArgs)
 	if err != nil {
 		span.RecordError(err)
-		return connect.NewError(connect.CodeInternal, errors.New("failed to delete member"))
+		return postgres.QueryError(ctx, connect.NewError(connect.CodeInternal, errors.New("failed to delete member")))
 	}
 
 	if result.RowsAffected() == 0 {
@@ -889,33 +1355,128 @@ func (r *OrganizationRepository) SearchItems(
 	))
 	defer span.End()
 
-	connection, err := r.connectionPool.Acquire(ctx)
+	ctx, cancel := postgres.WithQueryTimeout(ctx, r.searchQueryTimeout)
+	defer cancel()
+
+	connection, err := r.readPool(ctx).Acquire(ctx)
 	if err != nil {
-		return nil, 0, ErrFailedAcquireConnection
+		return nil, 0, postgres.QueryError(ctx, ErrFailedAcquireConnection)
 	}
 	defer connection.Release()
 
 	offset := (page - 1) * pageSize
+	threshold := r.searchSimilarityThresholds.For(utf8.RuneCountInString(query))
+	span.SetAttributes(attribute.Float64("similarityThreshold", threshold))
 
-	countSql := `
-		SELECT COUNT(DISTINCT si.id)
-		FROM search_items si
-		LEFT JOIN organization_members om ON
-			(si.item_type = 'organization' AND si.id = om.organization_id) OR
-			(si.item_type = 'repository' AND si.organization_id = om.organization_id)
-		WHERE om.user_id = $1
-		  AND si.deleted_at IS NULL
-		  AND similarity(si.name, $2) > 0.1`
-
+	var items []organization.SearchItemDTO
 	var totalCount int
-	err = connection.QueryRow(ctx, countSql, userId, query).Scan(&totalCount)
+	err = postgres.WithTx(ctx, connection, func(tx pgx.Tx) error {
+		// SET LOCAL takes no bind parameters; the threshold is a float from
+		// config, so formatting it into the statement is safe. It is reset
+		// when the transaction ends, before the connection returns to the pool.
+		setSql := "SET LOCAL pg_trgm.similarity_threshold = " + strconv.FormatFloat(threshold, 'f', -1, 64)
+		if _, err := tx.Exec(ctx, setSql); err != nil {
+			span.RecordError(err)
+			return postgres.QueryError(ctx, connect.NewError(connect.CodeInternal, errors.New("failed to set search similarity threshold")))
+		}
+
+		countSql := `
+			SELECT COUNT(DISTINCT si.id)
+			FROM search_items si
+			LEFT JOIN organization_members om ON
+				(si.item_type = 'organization' AND si.id = om.organization_id) OR
+				(si.item_type = 'repository' AND si.organization_id = om.organization_id)
+			WHERE om.user_id = $1
+			  AND si.deleted_at IS NULL
+			  AND si.name % $2`
+
+		if err := tx.QueryRow(ctx, countSql, userId, query).Scan(&totalCount); err != nil {
+			span.RecordError(err)
+			return postgres.QueryError(ctx, connect.NewError(connect.CodeInternal, errors.New("failed to count search items")))
+		}
+
+		sql := `
+			SELECT DISTINCT
+				si.id,
+				si.name,
+				si.item_type,
+				si.organization_id,
+				si.created_at,
+				si.deleted_at,
+				similarity(si.name, $2) AS score
+			FROM search_items si
+			LEFT JOIN organization_members om ON
+				(si.item_type = 'organization' AND si.id = om.organization_id) OR
+				(si.item_type = 'repository' AND si.organization_id = om.organization_id)
+			WHERE om.user_id = $1
+			  AND si.deleted_at IS NULL
+			  AND si.name % $2
+			ORDER BY score DESC, si.created_at DESC
+			LIMIT $3 OFFSET $4`
+
+		rows, err := tx.Query(ctx, sql, userId, query, pageSize, offset)
+		if err != nil {
+			span.RecordError(err)
+			return postgres.QueryError(ctx, connect.NewError(connect.CodeInternal, errors.New("failed to search items")))
+		}
+		defer rows.Close()
+
+		items, err = pgx.CollectRows[organization.SearchItemDTO](rows, pgx.RowToStructByName)
+		if err != nil {
+			span.RecordError(err)
+			return postgres.QueryError(ctx, connect.NewError(connect.CodeInternal, errors.New("failed to collect search item rows")))
+		}
+
+		return nil
+	})
 	if err != nil {
-		span.RecordError(err)
-		return nil, 0, connect.NewError(connect.CodeInternal, errors.New("failed to count search items"))
+		return nil, 0, err
 	}
 
+	return &items, totalCount, nil
+}
+
+// likePrefixReplacer escapes the LIKE wildcards in user input, so a prefix
+// containing % or _ only matches itself.
+var likePrefixReplacer = strings.NewReplacer(`\`, `\\`, "%", `\%`, "_", `\_`)
+
+// SearchSuggestions returns the names starting with prefix in the user's
+// organizations. The trigram index serves the prefix match, and results are
+// ranked with exact matches first, then by similarity, then shortest name.
+// Unlike SearchItems it runs a single query with no total count.
+func (r *OrganizationRepository) SearchSuggestions(
+	ctx context.Context,
+	userId, prefix string,
+	limit int,
+) ([]organization.SearchItemDTO, error) {
+	var span trace.Span
+	ctx, span = r.tracer.Start(ctx, "SearchSuggestions", trace.WithAttributes(
+		attribute.KeyValue{
+			Key:   "userId",
+			Value: attribute.StringValue(userId),
+		},
+		attribute.KeyValue{
+			Key:   "prefix",
+			Value: attribute.StringValue(prefix),
+		},
+		attribute.KeyValue{
+			Key:   "limit",
+			Value: attribute.IntValue(limit),
+		},
+	))
+	defer span.End()
+
+	ctx, cancel := postgres.WithQueryTimeout(ctx, r.queryTimeout)
+	defer cancel()
+
+	connection, err := r.readPool(ctx).Acquire(ctx)
+	if err != nil {
+		return nil, postgres.QueryError(ctx, ErrFailedAcquireConnection)
+	}
+	defer connection.Release()
+
 	sql := `
-		SELECT DISTINCT
+		SELECT
 			si.id,
 			si.name,
 			si.item_type,
@@ -924,27 +1485,29 @@ func (r *OrganizationRepository) SearchItems(
 			si.deleted_at,
 			similarity(si.name, $2) AS score
 		FROM search_items si
-		LEFT JOIN organization_members om ON
-			(si.item_type = 'organization' AND si.id = om.organization_id) OR
-			(si.item_type = 'repository' AND si.organization_id = om.organization_id)
-		WHERE om.user_id = $1
+		WHERE si.name ILIKE $3 ESCAPE '\'
 		  AND si.deleted_at IS NULL
-		  AND similarity(si.name, $2) > 0.1
-		ORDER BY score DESC, si.created_at DESC
-		LIMIT $3 OFFSET $4`
-
-	rows, err := connection.Query(ctx, sql, userId, query, pageSize, offset)
+		  AND EXISTS (
+			SELECT 1 FROM organization_members om
+			WHERE om.user_id = $1
+			  AND om.organization_id = COALESCE(si.organization_id, si.id)
+		  )
+		ORDER BY lower(si.name) = lower($2) DESC, score DESC, length(si.name), si.name
+		LIMIT $4`
+
+	pattern := likePrefixReplacer.Replace(prefix) + "%"
+	rows, err := connection.Query(ctx, sql, userId, prefix, pattern, limit)
 	if err != nil {
 		span.RecordError(err)
-		return nil, 0, connect.NewError(connect.CodeInternal, errors.New("failed to search items"))
+		return nil, postgres.QueryError(ctx, connect.NewError(connect.CodeInternal, errors.New("failed to query search suggestions")))
 	}
 	defer rows.Close()
 
 	items, err := pgx.CollectRows[organization.SearchItemDTO](rows, pgx.RowToStructByName)
 	if err != nil {
 		span.RecordError(err)
-		return nil, 0, connect.NewError(connect.CodeInternal, errors.New("failed to collect search item rows"))
+		return nil, postgres.QueryError(ctx, connect.NewError(connect.CodeInternal, errors.New("failed to collect search suggestion rows")))
 	}
 
-	return &items, totalCount, nil
+	return items, nil
 }
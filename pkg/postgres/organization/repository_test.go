@@ -1,7 +1,9 @@
 package organization
 
 import (
+	"context"
 	"fmt"
+	"strings"
 	"testing"
 	"time"
 
@@ -15,6 +17,7 @@ import (
 	"go.opentelemetry.io/otel/trace/noop"
 
 	"hasir-api/internal/organization"
+	"hasir-api/pkg/config"
 	"hasir-api/pkg/proto"
 )
 
@@ -78,7 +81,8 @@ func createOrganizationsTable(t *testing.T, connString string) {
 
 	sql := `CREATE TABLE organizations (
 		id VARCHAR PRIMARY KEY,
-		name VARCHAR NOT NULL UNIQUE,
+		slug VARCHAR NOT NULL UNIQUE,
+		display_name VARCHAR NOT NULL,
 		visibility visibility NOT NULL DEFAULT 'private',
 		created_by VARCHAR NOT NULL,
 		created_at TIMESTAMP NOT NULL,
@@ -87,6 +91,9 @@ func createOrganizationsTable(t *testing.T, connString string) {
 
 	_, err = conn.Exec(t.Context(), sql)
 	require.NoError(t, err)
+
+	_, err = conn.Exec(t.Context(), `CREATE UNIQUE INDEX idx_organizations_slug_lower ON organizations(LOWER(slug)) WHERE deleted_at IS NULL`)
+	require.NoError(t, err)
 }
 
 func createOrganizationsAndMembersTables(t *testing.T, connString string) {
@@ -108,6 +115,7 @@ func createOrganizationsAndMembersTables(t *testing.T, connString string) {
 		user_id VARCHAR NOT NULL,
 		role VARCHAR NOT NULL,
 		joined_at TIMESTAMP NOT NULL,
+		joined_via VARCHAR NOT NULL DEFAULT 'direct',
 		FOREIGN KEY (organization_id) REFERENCES organizations(id) ON DELETE CASCADE,
 		FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE,
 		CONSTRAINT uq_organization_member UNIQUE (organization_id, user_id)
@@ -121,11 +129,12 @@ func createTestOrganization(t *testing.T, name string, visibility proto.Visibili
 	t.Helper()
 	now := time.Now().UTC()
 	return &organization.OrganizationDTO{
-		Id:         uuid.NewString(),
-		Name:       name,
-		Visibility: visibility,
-		CreatedBy:  uuid.NewString(),
-		CreatedAt:  now,
+		Id:          uuid.NewString(),
+		Slug:        name,
+		DisplayName: name,
+		Visibility:  visibility,
+		CreatedBy:   uuid.NewString(),
+		CreatedAt:   now,
 	}
 }
 
@@ -158,12 +167,12 @@ func TestPgRepository_CreateOrganization(t *testing.T) {
 
 		var dbId, dbName, dbVisibility, dbCreatedBy string
 		err = conn.QueryRow(t.Context(),
-			"SELECT id, name, visibility, created_by FROM organizations WHERE id = $1", testOrg.Id).
+			"SELECT id, slug, visibility, created_by FROM organizations WHERE id = $1", testOrg.Id).
 			Scan(&dbId, &dbName, &dbVisibility, &dbCreatedBy)
 		require.NoError(t, err)
 
 		assert.Equal(t, testOrg.Id, dbId)
-		assert.Equal(t, testOrg.Name, dbName)
+		assert.Equal(t, testOrg.Slug, dbName)
 		assert.Equal(t, "private", dbVisibility)
 		assert.Equal(t, testOrg.CreatedBy, dbCreatedBy)
 	})
@@ -263,7 +272,7 @@ func TestPgRepository_CreateOrganization(t *testing.T) {
 		require.NoError(t, err)
 
 		assert.Equal(t, testOrg.Id, dbId)
-		assert.Equal(t, testOrg.Name, dbName)
+		assert.Equal(t, testOrg.Slug, dbName)
 		assert.Equal(t, "public", dbVisibility)
 		assert.Equal(t, testOrg.CreatedBy, dbCreatedBy)
 		assert.WithinDuration(t, time.Now().UTC(), dbCreatedAt, 5*time.Second)
@@ -291,14 +300,42 @@ func TestPgRepository_GetOrganizationByName(t *testing.T) {
 		err = repo.CreateOrganization(t.Context(), testOrg)
 		require.NoError(t, err)
 
-		found, err := repo.GetOrganizationByName(t.Context(), testOrg.Name)
+		found, err := repo.GetOrganizationByName(t.Context(), testOrg.Slug)
 		require.NoError(t, err)
 		require.NotNil(t, found)
 		assert.Equal(t, testOrg.Id, found.Id)
-		assert.Equal(t, testOrg.Name, found.Name)
+		assert.Equal(t, testOrg.Slug, found.Slug)
 		assert.Equal(t, proto.VisibilityPrivate, found.Visibility)
 	})
 
+	t.Run("matches regardless of case", func(t *testing.T) {
+		container := setupPgContainer(t)
+		defer func() {
+			err := container.Terminate(t.Context())
+			require.NoError(t, err)
+		}()
+
+		connString, err := container.ConnectionString(t.Context())
+		require.NoError(t, err)
+
+		createOrganizationsTable(t, connString)
+
+		repo, pool := setupTestRepository(t, connString)
+		defer pool.Close()
+
+		testOrg := createTestOrganization(t, "myorg-"+uuid.NewString(), proto.VisibilityPrivate)
+		require.NoError(t, repo.CreateOrganization(t.Context(), testOrg))
+
+		found, err := repo.GetOrganizationByName(t.Context(), strings.ToUpper(testOrg.Slug))
+		require.NoError(t, err)
+		assert.Equal(t, testOrg.Id, found.Id)
+
+		duplicate := createTestOrganization(t, strings.ToUpper(testOrg.Slug), proto.VisibilityPrivate)
+		err = repo.CreateOrganization(t.Context(), duplicate)
+		require.Error(t, err)
+		assert.Equal(t, connect.CodeAlreadyExists, connect.CodeOf(err))
+	})
+
 	t.Run("not found", func(t *testing.T) {
 		container := setupPgContainer(t)
 		defer func() {
@@ -346,7 +383,7 @@ func TestPgRepository_GetOrganizationByName(t *testing.T) {
 			_ = conn.Close(t.Context())
 		}()
 
-		_, err = repo.GetOrganizationByName(t.Context(), testOrg.Name)
+		_, err = repo.GetOrganizationByName(t.Context(), testOrg.Slug)
 		require.ErrorIs(t, err, ErrOrganizationNotFound)
 	})
 
@@ -370,18 +407,97 @@ func TestPgRepository_GetOrganizationByName(t *testing.T) {
 		err = repo.CreateOrganization(t.Context(), testOrg)
 		require.NoError(t, err)
 
-		found, err := repo.GetOrganizationByName(t.Context(), testOrg.Name)
+		found, err := repo.GetOrganizationByName(t.Context(), testOrg.Slug)
 		require.NoError(t, err)
 		require.NotNil(t, found)
 
 		assert.Equal(t, testOrg.Id, found.Id)
-		assert.Equal(t, testOrg.Name, found.Name)
+		assert.Equal(t, testOrg.Slug, found.Slug)
 		assert.Equal(t, proto.VisibilityPublic, found.Visibility)
 		assert.Equal(t, testOrg.CreatedBy, found.CreatedBy)
 		assert.WithinDuration(t, time.Now().UTC(), found.CreatedAt, 5*time.Second)
 	})
 }
 
+func TestPgRepository_GetOrganizationByNameWithRole(t *testing.T) {
+	t.Run("member gets role", func(t *testing.T) {
+		container := setupPgContainer(t)
+		defer func() {
+			err := container.Terminate(t.Context())
+			require.NoError(t, err)
+		}()
+
+		connString, err := container.ConnectionString(t.Context())
+		require.NoError(t, err)
+
+		createOrganizationsAndMembersTables(t, connString)
+
+		repo, pool := setupTestRepository(t, connString)
+		defer pool.Close()
+
+		testOrg := createTestOrganization(t, "with-role-"+uuid.NewString(), proto.VisibilityPrivate)
+		err = repo.CreateOrganization(t.Context(), testOrg)
+		require.NoError(t, err)
+
+		testUser := createTestUser(t, "member-"+uuid.NewString(), "member-"+uuid.NewString()+"@example.com")
+		insertTestUser(t, connString, testUser)
+		insertTestMember(t, connString, createTestMember(t, testOrg.Id, testUser.Id, organization.MemberRoleAuthor))
+
+		found, err := repo.GetOrganizationByNameWithRole(t.Context(), testOrg.Slug, testUser.Id)
+		require.NoError(t, err)
+		require.NotNil(t, found)
+		assert.Equal(t, testOrg.Id, found.Id)
+		require.NotNil(t, found.Role)
+		assert.Equal(t, organization.MemberRoleAuthor, *found.Role)
+	})
+
+	t.Run("non-member of public organization gets nil role", func(t *testing.T) {
+		container := setupPgContainer(t)
+		defer func() {
+			err := container.Terminate(t.Context())
+			require.NoError(t, err)
+		}()
+
+		connString, err := container.ConnectionString(t.Context())
+		require.NoError(t, err)
+
+		createOrganizationsAndMembersTables(t, connString)
+
+		repo, pool := setupTestRepository(t, connString)
+		defer pool.Close()
+
+		testOrg := createTestOrganization(t, "public-"+uuid.NewString(), proto.VisibilityPublic)
+		err = repo.CreateOrganization(t.Context(), testOrg)
+		require.NoError(t, err)
+
+		found, err := repo.GetOrganizationByNameWithRole(t.Context(), testOrg.Slug, uuid.NewString())
+		require.NoError(t, err)
+		require.NotNil(t, found)
+		assert.Equal(t, testOrg.Id, found.Id)
+		assert.Equal(t, proto.VisibilityPublic, found.Visibility)
+		assert.Nil(t, found.Role)
+	})
+
+	t.Run("not found", func(t *testing.T) {
+		container := setupPgContainer(t)
+		defer func() {
+			err := container.Terminate(t.Context())
+			require.NoError(t, err)
+		}()
+
+		connString, err := container.ConnectionString(t.Context())
+		require.NoError(t, err)
+
+		createOrganizationsAndMembersTables(t, connString)
+
+		repo, pool := setupTestRepository(t, connString)
+		defer pool.Close()
+
+		_, err = repo.GetOrganizationByNameWithRole(t.Context(), "nonexistent-org-"+uuid.NewString(), uuid.NewString())
+		require.ErrorIs(t, err, ErrOrganizationNotFound)
+	})
+}
+
 func TestPgRepository_GetOrganizations(t *testing.T) {
 	t.Run("success with multiple organizations", func(t *testing.T) {
 		container := setupPgContainer(t)
@@ -417,12 +533,12 @@ func TestPgRepository_GetOrganizations(t *testing.T) {
 		for _, o := range *orgs {
 			if o.Id == testOrg1.Id {
 				foundOrg1 = true
-				assert.Equal(t, testOrg1.Name, o.Name)
+				assert.Equal(t, testOrg1.Slug, o.Slug)
 				assert.Equal(t, proto.VisibilityPrivate, o.Visibility)
 			}
 			if o.Id == testOrg2.Id {
 				foundOrg2 = true
-				assert.Equal(t, testOrg2.Name, o.Name)
+				assert.Equal(t, testOrg2.Slug, o.Slug)
 				assert.Equal(t, proto.VisibilityPublic, o.Visibility)
 			}
 		}
@@ -552,7 +668,7 @@ func TestPgRepository_GetOrganizations(t *testing.T) {
 
 		found := (*orgs)[0]
 		assert.Equal(t, testOrg.Id, found.Id)
-		assert.Equal(t, testOrg.Name, found.Name)
+		assert.Equal(t, testOrg.Slug, found.Slug)
 		assert.Equal(t, proto.VisibilityPublic, found.Visibility)
 		assert.Equal(t, testOrg.CreatedBy, found.CreatedBy)
 		assert.WithinDuration(t, time.Now().UTC(), found.CreatedAt, 5*time.Second)
@@ -641,13 +757,51 @@ func createEmailJobsTable(t *testing.T, connString string) {
 		processed_at TIMESTAMP WITH TIME ZONE,
 		completed_at TIMESTAMP WITH TIME ZONE,
 		error_message TEXT,
-		CONSTRAINT chk_email_job_status CHECK (status IN ('pending', 'processing', 'completed', 'failed'))
+		CONSTRAINT chk_email_job_status CHECK (status IN ('pending', 'processing', 'completed', 'failed', 'cancelled'))
 	)`
 
 	_, err = conn.Exec(t.Context(), sql)
 	require.NoError(t, err)
 }
 
+// createOrganizationJobTables creates the queues DeleteOrganization cancels
+// pending work in, unless a test already created fuller versions of them.
+func createOrganizationJobTables(t *testing.T, connString string) {
+	t.Helper()
+
+	createEmailJobsTable(t, connString)
+
+	conn, err := pgx.Connect(t.Context(), connString)
+	require.NoError(t, err)
+	defer func() {
+		err = conn.Close(t.Context())
+		require.NoError(t, err)
+	}()
+
+	_, err = conn.Exec(t.Context(), `CREATE TABLE IF NOT EXISTS organization_invites (
+		id VARCHAR(36) PRIMARY KEY,
+		organization_id VARCHAR(36) NOT NULL,
+		email VARCHAR(255) NOT NULL,
+		token VARCHAR(64) NOT NULL UNIQUE,
+		invited_by VARCHAR(36) NOT NULL,
+		role VARCHAR(20) NOT NULL DEFAULT 'author',
+		status VARCHAR(20) NOT NULL DEFAULT 'pending',
+		created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+		expires_at TIMESTAMP WITH TIME ZONE NOT NULL,
+		accepted_at TIMESTAMP WITH TIME ZONE
+	)`)
+	require.NoError(t, err)
+
+	for _, table := range []string{"sdk_trigger_jobs", "sdk_generation_jobs", "docs_generation_jobs"} {
+		_, err = conn.Exec(t.Context(), `CREATE TABLE IF NOT EXISTS `+table+` (
+			id VARCHAR(36) PRIMARY KEY,
+			repository_id VARCHAR(36) NOT NULL,
+			status VARCHAR(20) NOT NULL DEFAULT 'pending'
+		)`)
+		require.NoError(t, err)
+	}
+}
+
 func createOrganizationInvitesTable(t *testing.T, connString string) {
 	t.Helper()
 
@@ -914,6 +1068,8 @@ func TestPgRepository_DeleteOrganization(t *testing.T) {
 		require.NoError(t, err)
 
 		createOrganizationsTable(t, connString)
+		createRepositoriesTable(t, connString)
+		createOrganizationJobTables(t, connString)
 
 		repo, pool := setupTestRepository(t, connString)
 		defer pool.Close()
@@ -957,6 +1113,8 @@ func TestPgRepository_DeleteOrganization(t *testing.T) {
 		require.NoError(t, err)
 
 		createOrganizationsTable(t, connString)
+		createRepositoriesTable(t, connString)
+		createOrganizationJobTables(t, connString)
 
 		repo, pool := setupTestRepository(t, connString)
 		defer pool.Close()
@@ -978,6 +1136,8 @@ func TestPgRepository_DeleteOrganization(t *testing.T) {
 		require.NoError(t, err)
 
 		createOrganizationsTable(t, connString)
+		createRepositoriesTable(t, connString)
+		createOrganizationJobTables(t, connString)
 
 		repo, pool := setupTestRepository(t, connString)
 		defer pool.Close()
@@ -1005,6 +1165,8 @@ func TestPgRepository_DeleteOrganization(t *testing.T) {
 		require.NoError(t, err)
 
 		createOrganizationsTable(t, connString)
+		createRepositoriesTable(t, connString)
+		createOrganizationJobTables(t, connString)
 
 		repo, pool := setupTestRepository(t, connString)
 		defer pool.Close()
@@ -1039,6 +1201,8 @@ func TestPgRepository_DeleteOrganization(t *testing.T) {
 		require.NoError(t, err)
 
 		createOrganizationsTable(t, connString)
+		createRepositoriesTable(t, connString)
+		createOrganizationJobTables(t, connString)
 
 		repo, pool := setupTestRepository(t, connString)
 		defer pool.Close()
@@ -1063,6 +1227,192 @@ func TestPgRepository_DeleteOrganization(t *testing.T) {
 		require.NoError(t, err)
 		require.Equal(t, 1, count)
 	})
+
+	t.Run("success - cascades soft delete to repositories", func(t *testing.T) {
+		container := setupPgContainer(t)
+		defer func() {
+			err := container.Terminate(t.Context())
+			require.NoError(t, err)
+		}()
+
+		connString, err := container.ConnectionString(t.Context())
+		require.NoError(t, err)
+
+		createOrganizationsTable(t, connString)
+		createRepositoriesTable(t, connString)
+		createOrganizationJobTables(t, connString)
+
+		repo, pool := setupTestRepository(t, connString)
+		defer pool.Close()
+
+		org := createTestOrganization(t, "test-org-"+uuid.NewString(), proto.VisibilityPrivate)
+		err = repo.CreateOrganization(t.Context(), org)
+		require.NoError(t, err)
+
+		otherOrg := createTestOrganization(t, "other-org-"+uuid.NewString(), proto.VisibilityPrivate)
+		err = repo.CreateOrganization(t.Context(), otherOrg)
+		require.NoError(t, err)
+
+		activeRepo := createTestRepository(t, "active-repo", org.Id, org.CreatedBy, proto.VisibilityPrivate)
+		insertTestRepository(t, connString, activeRepo)
+		previouslyDeletedRepo := createTestRepository(t, "previously-deleted-repo", org.Id, org.CreatedBy, proto.VisibilityPrivate)
+		insertTestRepository(t, connString, previouslyDeletedRepo)
+		otherOrgRepo := createTestRepository(t, "other-org-repo", otherOrg.Id, otherOrg.CreatedBy, proto.VisibilityPrivate)
+		insertTestRepository(t, connString, otherOrgRepo)
+
+		conn, err := pgx.Connect(t.Context(), connString)
+		require.NoError(t, err)
+		defer func() {
+			_ = conn.Close(t.Context())
+		}()
+
+		earlierDeletedAt := time.Now().UTC().Add(-24 * time.Hour).Truncate(time.Microsecond)
+		_, err = conn.Exec(t.Context(), "UPDATE repositories SET deleted_at = $1 WHERE id = $2", earlierDeletedAt, previouslyDeletedRepo.Id)
+		require.NoError(t, err)
+
+		err = repo.DeleteOrganization(t.Context(), org.Id)
+		require.NoError(t, err)
+
+		var orgDeletedAt time.Time
+		err = conn.QueryRow(t.Context(), "SELECT deleted_at FROM organizations WHERE id = $1", org.Id).Scan(&orgDeletedAt)
+		require.NoError(t, err)
+
+		var activeRepoDeletedAt *time.Time
+		err = conn.QueryRow(t.Context(), "SELECT deleted_at FROM repositories WHERE id = $1", activeRepo.Id).Scan(&activeRepoDeletedAt)
+		require.NoError(t, err)
+		require.NotNil(t, activeRepoDeletedAt)
+		assert.True(t, orgDeletedAt.Equal(*activeRepoDeletedAt))
+
+		var previouslyDeletedAt time.Time
+		err = conn.QueryRow(t.Context(), "SELECT deleted_at FROM repositories WHERE id = $1", previouslyDeletedRepo.Id).Scan(&previouslyDeletedAt)
+		require.NoError(t, err)
+		assert.True(t, earlierDeletedAt.Equal(previouslyDeletedAt))
+
+		var otherOrgRepoDeletedAt *time.Time
+		err = conn.QueryRow(t.Context(), "SELECT deleted_at FROM repositories WHERE id = $1", otherOrgRepo.Id).Scan(&otherOrgRepoDeletedAt)
+		require.NoError(t, err)
+		assert.Nil(t, otherOrgRepoDeletedAt)
+	})
+
+	t.Run("success - cascaded repositories excluded from search", func(t *testing.T) {
+		container := setupPgContainer(t)
+		defer func() {
+			err := container.Terminate(t.Context())
+			require.NoError(t, err)
+		}()
+
+		connString, err := container.ConnectionString(t.Context())
+		require.NoError(t, err)
+
+		setupTestDatabase(t, connString)
+
+		repo, pool := setupTestRepository(t, connString)
+		defer pool.Close()
+
+		org := createTestOrganization(t, "search-org", proto.VisibilityPublic)
+		err = repo.CreateOrganization(t.Context(), org)
+		require.NoError(t, err)
+
+		user := createTestUser(t, "testuser", "test@example.com")
+		insertTestUser(t, connString, user)
+
+		member := createTestMember(t, org.Id, user.Id, organization.MemberRoleOwner)
+		insertTestMember(t, connString, member)
+
+		repository := createTestRepository(t, "search-repo", org.Id, user.Id, proto.VisibilityPublic)
+		insertTestRepository(t, connString, repository)
+
+		refreshSearchItemsView(t, connString)
+
+		items, totalCount, err := repo.SearchItems(t.Context(), user.Id, "search", 1, 10)
+		require.NoError(t, err)
+		require.Equal(t, 2, totalCount)
+		require.Len(t, *items, 2)
+
+		err = repo.DeleteOrganization(t.Context(), org.Id)
+		require.NoError(t, err)
+
+		refreshSearchItemsView(t, connString)
+
+		items, totalCount, err = repo.SearchItems(t.Context(), user.Id, "search", 1, 10)
+		require.NoError(t, err)
+		assert.Equal(t, 0, totalCount)
+		assert.Empty(t, *items)
+	})
+}
+
+func TestPgRepository_DeleteOrganization_CancelsPendingWork(t *testing.T) {
+	container := setupPgContainer(t)
+	defer func() {
+		err := container.Terminate(t.Context())
+		require.NoError(t, err)
+	}()
+
+	connString, err := container.ConnectionString(t.Context())
+	require.NoError(t, err)
+
+	createOrganizationsTable(t, connString)
+	createOrganizationInvitesTable(t, connString)
+	createRepositoriesTable(t, connString)
+	createOrganizationJobTables(t, connString)
+
+	repo, pool := setupTestRepository(t, connString)
+	defer pool.Close()
+
+	user := createTestUser(t, "inviter", "inviter@example.com")
+	insertTestUser(t, connString, user)
+
+	org := createTestOrganization(t, "doomed-org", proto.VisibilityPrivate)
+	require.NoError(t, repo.CreateOrganization(t.Context(), org))
+	otherOrg := createTestOrganization(t, "surviving-org", proto.VisibilityPrivate)
+	require.NoError(t, repo.CreateOrganization(t.Context(), otherOrg))
+
+	invite := createTestInvite(t, org.Id, "invitee@example.com", uuid.NewString(), user.Id, organization.MemberRoleReader)
+	otherInvite := createTestInvite(t, otherOrg.Id, "invitee@example.com", uuid.NewString(), user.Id, organization.MemberRoleReader)
+	require.NoError(t, repo.CreateInvites(t.Context(), []*organization.OrganizationInviteDTO{invite}))
+	require.NoError(t, repo.CreateInvites(t.Context(), []*organization.OrganizationInviteDTO{otherInvite}))
+
+	queue := NewEmailJobQueue(pool, noop.NewTracerProvider().Tracer("test"))
+	var emailJobs []*organization.EmailJobDTO
+	for _, i := range []*organization.OrganizationInviteDTO{invite, otherInvite} {
+		emailJobs = append(emailJobs, &organization.EmailJobDTO{
+			Id:               uuid.NewString(),
+			InviteId:         i.Id,
+			OrganizationId:   i.OrganizationId,
+			Email:            i.Email,
+			OrganizationName: "org",
+			InviteToken:      i.Token,
+			Status:           organization.EmailJobStatusPending,
+			MaxAttempts:      3,
+			CreatedAt:        time.Now().UTC(),
+		})
+	}
+	require.NoError(t, queue.EnqueueEmailJobs(t.Context(), emailJobs))
+
+	repository := createTestRepository(t, "doomed-repo", org.Id, user.Id, proto.VisibilityPrivate)
+	insertTestRepository(t, connString, repository)
+	sdkJobId := uuid.NewString()
+	_, err = pool.Exec(t.Context(), "INSERT INTO sdk_generation_jobs (id, repository_id, status) VALUES ($1, $2, 'pending')", sdkJobId, repository.Id)
+	require.NoError(t, err)
+
+	require.NoError(t, repo.DeleteOrganization(t.Context(), org.Id))
+
+	var inviteStatus string
+	require.NoError(t, pool.QueryRow(t.Context(), "SELECT status FROM organization_invites WHERE id = $1", invite.Id).Scan(&inviteStatus))
+	assert.Equal(t, string(organization.InviteStatusCancelled), inviteStatus)
+
+	var emailJobStatus string
+	require.NoError(t, pool.QueryRow(t.Context(), "SELECT status FROM email_jobs WHERE invite_id = $1", invite.Id).Scan(&emailJobStatus))
+	assert.Equal(t, string(organization.EmailJobStatusCancelled), emailJobStatus)
+
+	var sdkJobStatus string
+	require.NoError(t, pool.QueryRow(t.Context(), "SELECT status FROM sdk_generation_jobs WHERE id = $1", sdkJobId).Scan(&sdkJobStatus))
+	assert.Equal(t, "cancelled", sdkJobStatus)
+
+	pending, err := queue.GetPendingEmailJobs(t.Context(), 10)
+	require.NoError(t, err)
+	require.Len(t, pending, 1, "only the surviving organization's email is sent")
+	assert.Equal(t, otherInvite.Id, pending[0].InviteId)
 }
 
 func createUsersTable(t *testing.T, connString string) {
@@ -1120,7 +1470,9 @@ func createOrganizationMembersTable(t *testing.T, connString string) {
 		user_id VARCHAR(36) NOT NULL,
 		role VARCHAR(20) NOT NULL DEFAULT 'reader',
 		joined_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+		joined_via VARCHAR(20) NOT NULL DEFAULT 'direct',
 		CONSTRAINT chk_member_role CHECK (role IN ('owner', 'author', 'reader')),
+		CONSTRAINT chk_member_joined_via CHECK (joined_via IN ('invite', 'direct', 'transfer')),
 		FOREIGN KEY (organization_id) REFERENCES organizations(id) ON DELETE CASCADE,
 		FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE,
 		CONSTRAINT uq_organization_member UNIQUE (organization_id, user_id)
@@ -1148,7 +1500,8 @@ func createOrganizationMembersView(t *testing.T, connString string) {
 			om.role,
 			om.joined_at,
 			u.username,
-			u.email
+			u.email,
+			om.joined_via
 		FROM organization_members om
 		INNER JOIN users u ON om.user_id = u.id
 		WHERE u.deleted_at IS NULL`
@@ -1292,6 +1645,55 @@ func TestPgRepository_GetMembers(t *testing.T) {
 		assert.Equal(t, organization.MemberRoleReader, members[2].Role)
 	})
 
+	t.Run("members with equal joined_at are ordered by id", func(t *testing.T) {
+		container := setupPgContainer(t)
+		defer func() {
+			err := container.Terminate(t.Context())
+			require.NoError(t, err)
+		}()
+
+		connString, err := container.ConnectionString(t.Context())
+		require.NoError(t, err)
+
+		createOrganizationsTable(t, connString)
+		createUsersTable(t, connString)
+		createOrganizationMembersTable(t, connString)
+		createOrganizationMembersView(t, connString)
+
+		repo, pool := setupTestRepository(t, connString)
+		defer pool.Close()
+
+		org := createTestOrganization(t, "tie-org-"+uuid.NewString(), proto.VisibilityPrivate)
+		err = repo.CreateOrganization(t.Context(), org)
+		require.NoError(t, err)
+
+		joinedAt := time.Now().UTC().Truncate(time.Microsecond)
+		var expectedIds []string
+		// Insert in reverse id order so insertion order cannot pass for id order.
+		for _, id := range []string{"member-c", "member-b", "member-a"} {
+			u := createTestUser(t, id, id+"@example.com")
+			insertTestUser(t, connString, u)
+
+			member := createTestMember(t, org.Id, u.Id, organization.MemberRoleReader)
+			member.Id = id
+			member.JoinedAt = joinedAt
+			insertTestMember(t, connString, member)
+
+			expectedIds = append([]string{id}, expectedIds...)
+		}
+
+		for range 5 {
+			members, _, _, err := repo.GetMembers(t.Context(), org.Id)
+			require.NoError(t, err)
+
+			var ids []string
+			for _, member := range members {
+				ids = append(ids, member.Id)
+			}
+			assert.Equal(t, expectedIds, ids)
+		}
+	})
+
 	t.Run("success with empty result", func(t *testing.T) {
 		container := setupPgContainer(t)
 		defer func() {
@@ -1929,20 +2331,92 @@ func TestPgRepository_UpdateMemberRole(t *testing.T) {
 	})
 }
 
-func TestPgRepository_DeleteMember(t *testing.T) {
-	t.Run("success - delete reader member", func(t *testing.T) {
-		container := setupPgContainer(t)
-		defer func() {
-			err := container.Terminate(t.Context())
-			require.NoError(t, err)
-		}()
-
-		connString, err := container.ConnectionString(t.Context())
+func TestPgRepository_UpdateMemberRoles(t *testing.T) {
+	container := setupPgContainer(t)
+	defer func() {
+		err := container.Terminate(t.Context())
 		require.NoError(t, err)
+	}()
 
-		createOrganizationsTable(t, connString)
-		createUsersTable(t, connString)
-		createOrganizationMembersTable(t, connString)
+	connString, err := container.ConnectionString(t.Context())
+	require.NoError(t, err)
+
+	createOrganizationsTable(t, connString)
+	createUsersTable(t, connString)
+	createOrganizationMembersTable(t, connString)
+	createOrganizationMembersView(t, connString)
+
+	repo, pool := setupTestRepository(t, connString)
+	defer pool.Close()
+
+	org := createTestOrganization(t, "bulk-roles-org-"+uuid.NewString(), proto.VisibilityPrivate)
+	require.NoError(t, repo.CreateOrganization(t.Context(), org))
+
+	owner := createTestUser(t, "owner", "owner@example.com")
+	author := createTestUser(t, "author", "author@example.com")
+	reader := createTestUser(t, "reader", "reader@example.com")
+	insertTestUser(t, connString, owner)
+	insertTestUser(t, connString, author)
+	insertTestUser(t, connString, reader)
+	insertTestMember(t, connString, createTestMember(t, org.Id, owner.Id, organization.MemberRoleOwner))
+	insertTestMember(t, connString, createTestMember(t, org.Id, author.Id, organization.MemberRoleAuthor))
+	insertTestMember(t, connString, createTestMember(t, org.Id, reader.Id, organization.MemberRoleReader))
+
+	roleOf := func(userId string) organization.MemberRole {
+		role, err := repo.GetMemberRole(t.Context(), org.Id, userId)
+		require.NoError(t, err)
+		return role
+	}
+
+	t.Run("applies every change", func(t *testing.T) {
+		err := repo.UpdateMemberRoles(t.Context(), org.Id, map[string]organization.MemberRole{
+			author.Id: organization.MemberRoleOwner,
+			reader.Id: organization.MemberRoleAuthor,
+		})
+		require.NoError(t, err)
+
+		assert.Equal(t, organization.MemberRoleOwner, roleOf(author.Id))
+		assert.Equal(t, organization.MemberRoleAuthor, roleOf(reader.Id))
+	})
+
+	t.Run("demoting every owner rolls the whole batch back", func(t *testing.T) {
+		err := repo.UpdateMemberRoles(t.Context(), org.Id, map[string]organization.MemberRole{
+			owner.Id:  organization.MemberRoleReader,
+			author.Id: organization.MemberRoleReader,
+			reader.Id: organization.MemberRoleReader,
+		})
+		assert.ErrorIs(t, err, ErrNoOwnerLeft)
+
+		assert.Equal(t, organization.MemberRoleOwner, roleOf(owner.Id))
+		assert.Equal(t, organization.MemberRoleOwner, roleOf(author.Id))
+		assert.Equal(t, organization.MemberRoleAuthor, roleOf(reader.Id))
+	})
+
+	t.Run("unknown member rolls the whole batch back", func(t *testing.T) {
+		err := repo.UpdateMemberRoles(t.Context(), org.Id, map[string]organization.MemberRole{
+			reader.Id:        organization.MemberRoleOwner,
+			uuid.NewString(): organization.MemberRoleReader,
+		})
+		assert.ErrorIs(t, err, ErrMemberNotFound)
+
+		assert.Equal(t, organization.MemberRoleAuthor, roleOf(reader.Id))
+	})
+}
+
+func TestPgRepository_DeleteMember(t *testing.T) {
+	t.Run("success - delete reader member", func(t *testing.T) {
+		container := setupPgContainer(t)
+		defer func() {
+			err := container.Terminate(t.Context())
+			require.NoError(t, err)
+		}()
+
+		connString, err := container.ConnectionString(t.Context())
+		require.NoError(t, err)
+
+		createOrganizationsTable(t, connString)
+		createUsersTable(t, connString)
+		createOrganizationMembersTable(t, connString)
 		createOrganizationMembersView(t, connString)
 
 		repo, pool := setupTestRepository(t, connString)
@@ -2311,7 +2785,7 @@ func createSearchItemsView(t *testing.T, connString string) {
 	sql := `CREATE MATERIALIZED VIEW search_items AS
 		SELECT
 			o.id,
-			o.name,
+			o.slug AS name,
 			'organization' AS item_type,
 			NULL::VARCHAR(36) AS organization_id,
 			o.created_at,
@@ -2404,6 +2878,7 @@ func setupTestDatabase(t *testing.T, connString string) {
 	createUsersTable(t, connString)
 	createOrganizationsTable(t, connString)
 	createRepositoriesTable(t, connString)
+	createOrganizationJobTables(t, connString)
 	createOrganizationMembersTable(t, connString)
 
 	createSearchItemsView(t, connString)
@@ -2422,6 +2897,124 @@ func refreshSearchItemsView(t *testing.T, connString string) {
 	require.NoError(t, err)
 }
 
+func TestPgRepository_SearchSuggestions(t *testing.T) {
+	container := setupPgContainer(t)
+	defer func() {
+		err := container.Terminate(t.Context())
+		require.NoError(t, err)
+	}()
+
+	connString, err := container.ConnectionString(t.Context())
+	require.NoError(t, err)
+
+	setupTestDatabase(t, connString)
+
+	repo, pool := setupTestRepository(t, connString)
+	defer pool.Close()
+
+	user := createTestUser(t, "testuser", "test@example.com")
+	insertTestUser(t, connString, user)
+
+	org := createTestOrganization(t, "proto", proto.VisibilityPrivate)
+	require.NoError(t, repo.CreateOrganization(t.Context(), org))
+	insertTestMember(t, connString, createTestMember(t, org.Id, user.Id, organization.MemberRoleOwner))
+
+	otherOrg := createTestOrganization(t, "proto-other", proto.VisibilityPrivate)
+	require.NoError(t, repo.CreateOrganization(t.Context(), otherOrg))
+
+	for _, name := range []string{"protocol-buffers", "protos", "my-protos", "pro_x"} {
+		insertTestRepository(t, connString, createTestRepository(t, name, org.Id, user.Id, proto.VisibilityPrivate))
+	}
+	insertTestRepository(t, connString, createTestRepository(t, "protos-secret", otherOrg.Id, user.Id, proto.VisibilityPrivate))
+
+	refreshSearchItemsView(t, connString)
+
+	names := func(items []organization.SearchItemDTO) []string {
+		result := make([]string, len(items))
+		for i, item := range items {
+			result[i] = item.Name
+		}
+		return result
+	}
+
+	t.Run("ranks prefix matches within the user's organizations", func(t *testing.T) {
+		items, err := repo.SearchSuggestions(t.Context(), user.Id, "proto", 10)
+		require.NoError(t, err)
+
+		assert.Equal(t, []string{"proto", "protos", "protocol-buffers"}, names(items))
+	})
+
+	t.Run("respects the limit", func(t *testing.T) {
+		items, err := repo.SearchSuggestions(t.Context(), user.Id, "proto", 2)
+		require.NoError(t, err)
+
+		assert.Equal(t, []string{"proto", "protos"}, names(items))
+	})
+
+	t.Run("treats LIKE wildcards literally", func(t *testing.T) {
+		items, err := repo.SearchSuggestions(t.Context(), user.Id, "pro_", 10)
+		require.NoError(t, err)
+
+		assert.Equal(t, []string{"pro_x"}, names(items))
+	})
+
+	t.Run("returns nothing to non-members", func(t *testing.T) {
+		items, err := repo.SearchSuggestions(t.Context(), uuid.NewString(), "proto", 10)
+		require.NoError(t, err)
+
+		assert.Empty(t, items)
+	})
+}
+
+func TestPgRepository_SearchItems_SimilarityThreshold(t *testing.T) {
+	container := setupPgContainer(t)
+	defer func() {
+		err := container.Terminate(t.Context())
+		require.NoError(t, err)
+	}()
+
+	connString, err := container.ConnectionString(t.Context())
+	require.NoError(t, err)
+
+	setupTestDatabase(t, connString)
+
+	repo, pool := setupTestRepository(t, connString)
+	defer pool.Close()
+	repo.searchSimilarityThresholds = config.SearchSimilarityThresholds{
+		{MinQueryLength: 0, Threshold: 0.1},
+		{MinQueryLength: 10, Threshold: 0.5},
+	}
+
+	user := createTestUser(t, "testuser", "test@example.com")
+	insertTestUser(t, connString, user)
+
+	org := createTestOrganization(t, "acme", proto.VisibilityPrivate)
+	require.NoError(t, repo.CreateOrganization(t.Context(), org))
+	insertTestMember(t, connString, createTestMember(t, org.Id, user.Id, organization.MemberRoleOwner))
+
+	insertTestRepository(t, connString, createTestRepository(t, "billing-events", org.Id, user.Id, proto.VisibilityPrivate))
+	insertTestRepository(t, connString, createTestRepository(t, "billing-ledger", org.Id, user.Id, proto.VisibilityPrivate))
+
+	refreshSearchItemsView(t, connString)
+
+	t.Run("short query keeps loose matches", func(t *testing.T) {
+		items, totalCount, err := repo.SearchItems(t.Context(), user.Id, "billing", 1, 10)
+		require.NoError(t, err)
+
+		assert.Equal(t, 2, totalCount)
+		assert.Len(t, *items, 2)
+	})
+
+	t.Run("long query filters out weak matches", func(t *testing.T) {
+		items, totalCount, err := repo.SearchItems(t.Context(), user.Id, "billing-events", 1, 10)
+		require.NoError(t, err)
+
+		assert.Equal(t, 1, totalCount)
+		require.Len(t, *items, 1)
+		assert.Equal(t, "billing-events", (*items)[0].Name)
+	})
+}
+
 func TestPgRepository_SearchItems(t *testing.T) {
 	t.Run("success with mixed organization and repository results", func(t *testing.T) {
 		container := setupPgContainer(t)
@@ -2465,7 +3058,7 @@ func TestPgRepository_SearchItems(t *testing.T) {
 			if item.ItemType == organization.SearchItemTypeOrganization {
 				foundOrg = true
 				assert.Equal(t, org.Id, item.Id)
-				assert.Equal(t, org.Name, item.Name)
+				assert.Equal(t, org.Slug, item.Name)
 			}
 			if item.ItemType == organization.SearchItemTypeRepository {
 				foundRepo = true
@@ -2881,7 +3474,8 @@ func TestPgRepository_UpdateOrganization(t *testing.T) {
 		err = repo.CreateOrganization(t.Context(), org)
 		require.NoError(t, err)
 
-		org.Name = "updated-name"
+		org.Slug = "updated-name"
+		org.DisplayName = "Updated Name"
 		org.Visibility = proto.VisibilityPublic
 
 		err = repo.UpdateOrganization(t.Context(), org)
@@ -2889,7 +3483,8 @@ func TestPgRepository_UpdateOrganization(t *testing.T) {
 
 		updated, err := repo.GetOrganizationById(t.Context(), org.Id)
 		require.NoError(t, err)
-		assert.Equal(t, "updated-name", updated.Name)
+		assert.Equal(t, "original-name", updated.Slug, "slug must not change on update")
+		assert.Equal(t, "Updated Name", updated.DisplayName)
 		assert.Equal(t, proto.VisibilityPublic, updated.Visibility)
 	})
 
@@ -2974,6 +3569,158 @@ func TestPgRepository_GetInviteByToken(t *testing.T) {
 	})
 }
 
+func TestPgRepository_GetInviteDetailsByToken(t *testing.T) {
+	setup := func(t *testing.T) (*OrganizationRepository, string) {
+		t.Helper()
+
+		container := setupPgContainer(t)
+		t.Cleanup(func() {
+			require.NoError(t, container.Terminate(context.Background()))
+		})
+
+		connString, err := container.ConnectionString(t.Context())
+		require.NoError(t, err)
+
+		createOrganizationsTable(t, connString)
+		createOrganizationInvitesTable(t, connString)
+		createUsersTable(t, connString)
+		createRepositoriesTable(t, connString)
+		createOrganizationJobTables(t, connString)
+
+		repo, pool := setupTestRepository(t, connString)
+		t.Cleanup(pool.Close)
+
+		return repo, connString
+	}
+
+	t.Run("includes organization name and inviter username", func(t *testing.T) {
+		repo, connString := setup(t)
+
+		user := createTestUser(t, "inviter", "inviter@example.com")
+		insertTestUser(t, connString, user)
+
+		org := createTestOrganization(t, "acme", proto.VisibilityPrivate)
+		require.NoError(t, repo.CreateOrganization(t.Context(), org))
+
+		invite := createTestInvite(t, org.Id, "test@example.com", uuid.NewString(), user.Id, organization.MemberRoleAuthor)
+		require.NoError(t, repo.CreateInvites(t.Context(), []*organization.OrganizationInviteDTO{invite}))
+
+		found, err := repo.GetInviteDetailsByToken(t.Context(), invite.Token)
+		require.NoError(t, err)
+		assert.Equal(t, invite.Id, found.Id)
+		assert.Equal(t, invite.Email, found.Email)
+		assert.Equal(t, organization.MemberRoleAuthor, found.Role)
+		assert.Equal(t, "acme", found.OrganizationName)
+		assert.Equal(t, "inviter", found.InviterUsername)
+	})
+
+	t.Run("deleted inviter falls back to placeholder", func(t *testing.T) {
+		repo, connString := setup(t)
+
+		user := createTestUser(t, "inviter", "inviter@example.com")
+		insertTestUser(t, connString, user)
+
+		org := createTestOrganization(t, "acme", proto.VisibilityPrivate)
+		require.NoError(t, repo.CreateOrganization(t.Context(), org))
+
+		invite := createTestInvite(t, org.Id, "test@example.com", uuid.NewString(), user.Id, organization.MemberRoleReader)
+		require.NoError(t, repo.CreateInvites(t.Context(), []*organization.OrganizationInviteDTO{invite}))
+
+		conn, err := pgx.Connect(t.Context(), connString)
+		require.NoError(t, err)
+		defer func() {
+			_ = conn.Close(t.Context())
+		}()
+		_, err = conn.Exec(t.Context(), "UPDATE users SET deleted_at = NOW() WHERE id = $1", user.Id)
+		require.NoError(t, err)
+
+		found, err := repo.GetInviteDetailsByToken(t.Context(), invite.Token)
+		require.NoError(t, err)
+		assert.Equal(t, "acme", found.OrganizationName)
+		assert.Equal(t, organization.DeletedInviterUsername, found.InviterUsername)
+	})
+
+	t.Run("invite of deleted organization is not found", func(t *testing.T) {
+		repo, connString := setup(t)
+
+		user := createTestUser(t, "inviter", "inviter@example.com")
+		insertTestUser(t, connString, user)
+
+		org := createTestOrganization(t, "acme", proto.VisibilityPrivate)
+		require.NoError(t, repo.CreateOrganization(t.Context(), org))
+
+		invite := createTestInvite(t, org.Id, "test@example.com", uuid.NewString(), user.Id, organization.MemberRoleReader)
+		require.NoError(t, repo.CreateInvites(t.Context(), []*organization.OrganizationInviteDTO{invite}))
+		require.NoError(t, repo.DeleteOrganization(t.Context(), org.Id))
+
+		_, err := repo.GetInviteDetailsByToken(t.Context(), invite.Token)
+		require.ErrorIs(t, err, ErrInviteNotFound)
+	})
+}
+
+func TestPgRepository_GetInvitesForEmail(t *testing.T) {
+	container := setupPgContainer(t)
+	t.Cleanup(func() {
+		require.NoError(t, container.Terminate(context.Background()))
+	})
+
+	connString, err := container.ConnectionString(t.Context())
+	require.NoError(t, err)
+
+	createOrganizationsTable(t, connString)
+	createOrganizationInvitesTable(t, connString)
+	createUsersTable(t, connString)
+
+	repo, pool := setupTestRepository(t, connString)
+	t.Cleanup(pool.Close)
+
+	inviter := createTestUser(t, "inviter", "inviter@example.com")
+	insertTestUser(t, connString, inviter)
+
+	createOrg := func(name string) *organization.OrganizationDTO {
+		org := createTestOrganization(t, name, proto.VisibilityPrivate)
+		require.NoError(t, repo.CreateOrganization(t.Context(), org))
+		return org
+	}
+	invite := func(orgId, email string, role organization.MemberRole) *organization.OrganizationInviteDTO {
+		return createTestInvite(t, orgId, email, uuid.NewString(), inviter.Id, role)
+	}
+
+	pending := invite(createOrg("pending-org").Id, "friend@example.com", organization.MemberRoleReader)
+	otherCase := invite(createOrg("other-case-org").Id, "Friend@Example.com", organization.MemberRoleAuthor)
+	expired := invite(createOrg("expired-org").Id, "friend@example.com", organization.MemberRoleReader)
+	expired.ExpiresAt = time.Now().UTC().Add(-time.Hour)
+	accepted := invite(createOrg("accepted-org").Id, "friend@example.com", organization.MemberRoleReader)
+	someoneElse := invite(createOrg("someone-else-org").Id, "stranger@example.com", organization.MemberRoleReader)
+	require.NoError(t, repo.CreateInvites(t.Context(), []*organization.OrganizationInviteDTO{pending}))
+	require.NoError(t, repo.CreateInvites(t.Context(), []*organization.OrganizationInviteDTO{otherCase}))
+	require.NoError(t, repo.CreateInvites(t.Context(), []*organization.OrganizationInviteDTO{expired}))
+	require.NoError(t, repo.CreateInvites(t.Context(), []*organization.OrganizationInviteDTO{accepted}))
+	require.NoError(t, repo.CreateInvites(t.Context(), []*organization.OrganizationInviteDTO{someoneElse}))
+
+	acceptedAt := time.Now().UTC()
+	require.NoError(t, repo.UpdateInviteStatus(t.Context(), accepted.Id, organization.InviteStatusAccepted, &acceptedAt))
+
+	invites, err := repo.GetInvitesForEmail(t.Context(), "friend@example.com")
+	require.NoError(t, err)
+
+	ids := make([]string, 0, len(invites))
+	for _, found := range invites {
+		ids = append(ids, found.Id)
+		assert.Equal(t, organization.InviteStatusPending, found.Status)
+		assert.Equal(t, "inviter", found.InviterUsername)
+		assert.Empty(t, found.Token)
+	}
+	assert.ElementsMatch(t, []string{pending.Id}, ids)
+
+	for _, found := range invites {
+		if found.Id == pending.Id {
+			assert.Equal(t, "pending-org", found.OrganizationName)
+			assert.Equal(t, organization.MemberRoleReader, found.Role)
+		}
+	}
+}
+
 func TestPgRepository_UpdateInviteStatus(t *testing.T) {
 	t.Run("success", func(t *testing.T) {
 		container := setupPgContainer(t)
@@ -3036,6 +3783,84 @@ func TestPgRepository_UpdateInviteStatus(t *testing.T) {
 		err = repo.UpdateInviteStatus(t.Context(), uuid.NewString(), organization.InviteStatusAccepted, &now)
 		require.ErrorIs(t, err, ErrInviteNotFound)
 	})
+
+	t.Run("transitions", func(t *testing.T) {
+		container := setupPgContainer(t)
+		defer func() {
+			err := container.Terminate(t.Context())
+			require.NoError(t, err)
+		}()
+
+		connString, err := container.ConnectionString(t.Context())
+		require.NoError(t, err)
+
+		createOrganizationsTable(t, connString)
+		createOrganizationInvitesTable(t, connString)
+		createUsersTable(t, connString)
+
+		repo, pool := setupTestRepository(t, connString)
+		defer pool.Close()
+
+		user := createTestUser(t, "inviter", "inviter@example.com")
+		insertTestUser(t, connString, user)
+
+		org := createTestOrganization(t, "test-org", proto.VisibilityPrivate)
+		require.NoError(t, repo.CreateOrganization(t.Context(), org))
+
+		newInvite := func(t *testing.T) *organization.OrganizationInviteDTO {
+			t.Helper()
+
+			invite := createTestInvite(t, org.Id, uuid.NewString()+"@example.com", uuid.NewString(), user.Id, organization.MemberRoleAuthor)
+			require.NoError(t, repo.CreateInvites(t.Context(), []*organization.OrganizationInviteDTO{invite}))
+			return invite
+		}
+
+		for _, status := range []organization.InviteStatus{
+			organization.InviteStatusAccepted,
+			organization.InviteStatusExpired,
+			organization.InviteStatusCancelled,
+		} {
+			t.Run("pending to "+string(status), func(t *testing.T) {
+				invite := newInvite(t)
+
+				require.NoError(t, repo.UpdateInviteStatus(t.Context(), invite.Id, status, nil))
+
+				stored, err := repo.GetInviteByToken(t.Context(), invite.Token)
+				require.NoError(t, err)
+				assert.Equal(t, status, stored.Status)
+			})
+		}
+
+		t.Run("rejects leaving a final status", func(t *testing.T) {
+			invite := newInvite(t)
+			require.NoError(t, repo.UpdateInviteStatus(t.Context(), invite.Id, organization.InviteStatusCancelled, nil))
+
+			now := time.Now().UTC()
+			err := repo.UpdateInviteStatus(t.Context(), invite.Id, organization.InviteStatusAccepted, &now)
+			require.ErrorIs(t, err, ErrInvalidInviteTransition)
+
+			stored, err := repo.GetInviteByToken(t.Context(), invite.Token)
+			require.NoError(t, err)
+			assert.Equal(t, organization.InviteStatusCancelled, stored.Status)
+			assert.Nil(t, stored.AcceptedAt)
+		})
+
+		t.Run("re-applying a final status is a no-op", func(t *testing.T) {
+			invite := newInvite(t)
+
+			acceptedAt := time.Now().UTC().Add(-time.Hour).Truncate(time.Microsecond)
+			require.NoError(t, repo.UpdateInviteStatus(t.Context(), invite.Id, organization.InviteStatusAccepted, &acceptedAt))
+
+			retriedAt := time.Now().UTC()
+			require.NoError(t, repo.UpdateInviteStatus(t.Context(), invite.Id, organization.InviteStatusAccepted, &retriedAt))
+
+			stored, err := repo.GetInviteByToken(t.Context(), invite.Token)
+			require.NoError(t, err)
+			assert.Equal(t, organization.InviteStatusAccepted, stored.Status)
+			require.NotNil(t, stored.AcceptedAt)
+			assert.True(t, acceptedAt.Equal(*stored.AcceptedAt))
+		})
+	})
 }
 
 func TestPgRepository_AddMember(t *testing.T) {
@@ -3072,6 +3897,117 @@ func TestPgRepository_AddMember(t *testing.T) {
 	})
 }
 
+func TestPgRepository_AddMember_JoinedVia(t *testing.T) {
+	container := setupPgContainer(t)
+	defer func() {
+		err := container.Terminate(t.Context())
+		require.NoError(t, err)
+	}()
+
+	connString, err := container.ConnectionString(t.Context())
+	require.NoError(t, err)
+
+	createOrganizationsTable(t, connString)
+	createUsersTable(t, connString)
+	createOrganizationMembersTable(t, connString)
+	createOrganizationMembersView(t, connString)
+
+	repo, pool := setupTestRepository(t, connString)
+	defer pool.Close()
+
+	org := createTestOrganization(t, "test-org-"+uuid.NewString(), proto.VisibilityPrivate)
+	require.NoError(t, repo.CreateOrganization(t.Context(), org))
+
+	invited := createTestUser(t, "invited", "invited@example.com")
+	added := createTestUser(t, "added", "added@example.com")
+	insertTestUser(t, connString, invited)
+	insertTestUser(t, connString, added)
+
+	invitedMember := createTestMember(t, org.Id, invited.Id, organization.MemberRoleReader)
+	invitedMember.JoinedVia = organization.JoinedViaInvite
+	require.NoError(t, repo.AddMember(t.Context(), invitedMember))
+
+	addedMember := createTestMember(t, org.Id, added.Id, organization.MemberRoleOwner)
+	addedMember.JoinedVia = organization.JoinedViaDirect
+	addedMember.JoinedAt = invitedMember.JoinedAt.Add(time.Second)
+	require.NoError(t, repo.AddMember(t.Context(), addedMember))
+
+	members, _, _, err := repo.GetMembers(t.Context(), org.Id)
+	require.NoError(t, err)
+	require.Len(t, members, 2)
+
+	assert.Equal(t, invited.Id, members[0].UserId)
+	assert.Equal(t, organization.JoinedViaInvite, members[0].JoinedVia)
+	assert.Equal(t, added.Id, members[1].UserId)
+	assert.Equal(t, organization.JoinedViaDirect, members[1].JoinedVia)
+}
+
+func TestPgRepository_CreateOrganizationWithOwner(t *testing.T) {
+	container := setupPgContainer(t)
+	defer func() {
+		err := container.Terminate(t.Context())
+		require.NoError(t, err)
+	}()
+
+	connString, err := container.ConnectionString(t.Context())
+	require.NoError(t, err)
+
+	createOrganizationsTable(t, connString)
+	createUsersTable(t, connString)
+	createOrganizationMembersTable(t, connString)
+	createOrganizationMembersView(t, connString)
+
+	repo, pool := setupTestRepository(t, connString)
+	defer pool.Close()
+
+	t.Run("creator is the only owner", func(t *testing.T) {
+		creator := createTestUser(t, "creator", "creator@example.com")
+		insertTestUser(t, connString, creator)
+
+		org := createTestOrganization(t, "test-org-"+uuid.NewString(), proto.VisibilityPrivate)
+		org.CreatedBy = creator.Id
+		owner := createTestMember(t, org.Id, creator.Id, organization.MemberRoleOwner)
+
+		require.NoError(t, repo.CreateOrganizationWithOwner(t.Context(), org, owner))
+
+		members, _, _, err := repo.GetMembers(t.Context(), org.Id)
+		require.NoError(t, err)
+		require.Len(t, members, 1)
+		assert.Equal(t, creator.Id, members[0].UserId)
+		assert.Equal(t, organization.MemberRoleOwner, members[0].Role)
+		assert.Equal(t, organization.JoinedViaDirect, members[0].JoinedVia)
+
+		ownerCount, err := repo.GetOwnerCount(t.Context(), org.Id)
+		require.NoError(t, err)
+		assert.Equal(t, 1, ownerCount)
+	})
+
+	t.Run("rolls back the organization when the owner insert fails", func(t *testing.T) {
+		org := createTestOrganization(t, "test-org-"+uuid.NewString(), proto.VisibilityPrivate)
+		owner := createTestMember(t, org.Id, uuid.NewString(), organization.MemberRoleOwner)
+
+		err := repo.CreateOrganizationWithOwner(t.Context(), org, owner)
+		require.Error(t, err)
+
+		_, err = repo.GetOrganizationByName(t.Context(), org.Slug)
+		require.ErrorIs(t, err, ErrOrganizationNotFound)
+	})
+
+	t.Run("duplicate organization", func(t *testing.T) {
+		creator := createTestUser(t, "dup-creator", "dup-creator@example.com")
+		insertTestUser(t, connString, creator)
+
+		org := createTestOrganization(t, "test-org-"+uuid.NewString(), proto.VisibilityPrivate)
+		require.NoError(t, repo.CreateOrganization(t.Context(), org))
+
+		duplicate := createTestOrganization(t, org.Slug, proto.VisibilityPrivate)
+		owner := createTestMember(t, duplicate.Id, creator.Id, organization.MemberRoleOwner)
+
+		err := repo.CreateOrganizationWithOwner(t.Context(), duplicate, owner)
+		require.ErrorIs(t, err, ErrOrganizationAlreadyExists)
+	})
+}
+
 func TestPgRepository_GetMemberRole(t *testing.T) {
 	t.Run("success", func(t *testing.T) {
 		container := setupPgContainer(t)
@@ -3195,3 +4131,69 @@ func TestPgRepository_GetOwnerCount(t *testing.T) {
 		assert.Equal(t, 2, count)
 	})
 }
+
+func TestPgRepository_GetMembersCount(t *testing.T) {
+	t.Run("counts members of active users", func(t *testing.T) {
+		container := setupPgContainer(t)
+		defer func() {
+			err := container.Terminate(t.Context())
+			require.NoError(t, err)
+		}()
+
+		connString, err := container.ConnectionString(t.Context())
+		require.NoError(t, err)
+
+		createOrganizationsAndMembersTables(t, connString)
+		createOrganizationMembersView(t, connString)
+
+		repo, pool := setupTestRepository(t, connString)
+		defer pool.Close()
+
+		user1 := createTestUser(t, "user1", "user1@example.com")
+		user2 := createTestUser(t, "user2", "user2@example.com")
+		user3 := createTestUser(t, "user3", "user3@example.com")
+		insertTestUser(t, connString, user1)
+		insertTestUser(t, connString, user2)
+		insertTestUser(t, connString, user3)
+
+		org := createTestOrganization(t, "test-org", proto.VisibilityPrivate)
+		err = repo.CreateOrganization(t.Context(), org)
+		require.NoError(t, err)
+		otherOrg := createTestOrganization(t, "other-org", proto.VisibilityPrivate)
+		err = repo.CreateOrganization(t.Context(), otherOrg)
+		require.NoError(t, err)
+
+		insertTestMember(t, connString, createTestMember(t, org.Id, user1.Id, organization.MemberRoleOwner))
+		insertTestMember(t, connString, createTestMember(t, org.Id, user2.Id, organization.MemberRoleAuthor))
+		insertTestMember(t, connString, createTestMember(t, org.Id, user3.Id, organization.MemberRoleReader))
+		insertTestMember(t, connString, createTestMember(t, otherOrg.Id, user1.Id, organization.MemberRoleOwner))
+
+		_, err = pool.Exec(t.Context(), `UPDATE users SET deleted_at = NOW() WHERE id = $1`, user3.Id)
+		require.NoError(t, err)
+
+		count, err := repo.GetMembersCount(t.Context(), org.Id)
+		require.NoError(t, err)
+		assert.Equal(t, 2, count)
+	})
+
+	t.Run("returns zero for organization without members", func(t *testing.T) {
+		container := setupPgContainer(t)
+		defer func() {
+			err := container.Terminate(t.Context())
+			require.NoError(t, err)
+		}()
+
+		connString, err := container.ConnectionString(t.Context())
+		require.NoError(t, err)
+
+		createOrganizationsAndMembersTables(t, connString)
+		createOrganizationMembersView(t, connString)
+
+		repo, pool := setupTestRepository(t, connString)
+		defer pool.Close()
+
+		count, err := repo.GetMembersCount(t.Context(), uuid.NewString())
+		require.NoError(t, err)
+		assert.Equal(t, 0, count)
+	})
+}
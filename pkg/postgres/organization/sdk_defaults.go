@@ -0,0 +1,90 @@
+package organization
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"connectrpc.com/connect"
+	"github.com/jackc/pgx/v5"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+
+	"hasir-api/internal/registry"
+	"hasir-api/pkg/postgres"
+)
+
+func (r *OrganizationRepository) GetDefaultSdks(ctx context.Context, organizationId string) ([]registry.SDK, error) {
+	var span trace.Span
+	ctx, span = r.tracer.Start(ctx, "GetDefaultSdks", trace.WithAttributes(
+		attribute.KeyValue{
+			Key:   "organizationId",
+			Value: attribute.StringValue(organizationId),
+		},
+	))
+	defer span.End()
+
+	ctx, cancel := postgres.WithQueryTimeout(ctx, r.queryTimeout)
+	defer cancel()
+
+	connection, err := r.readPool(ctx).Acquire(ctx)
+	if err != nil {
+		return nil, postgres.QueryError(ctx, ErrFailedAcquireConnection)
+	}
+	defer connection.Release()
+
+	sql := `SELECT sdk FROM organization_sdk_defaults WHERE organization_id = $1 ORDER BY sdk`
+
+	rows, err := connection.Query(ctx, sql, organizationId)
+	if err != nil {
+		span.RecordError(err)
+		return nil, postgres.QueryError(ctx, connect.NewError(connect.CodeInternal, errors.New("failed to query default sdks")))
+	}
+
+	sdks, err := pgx.CollectRows(rows, pgx.RowTo[registry.SDK])
+	if err != nil {
+		span.RecordError(err)
+		return nil, postgres.QueryError(ctx, connect.NewError(connect.CodeInternal, errors.New("failed to collect default sdks")))
+	}
+
+	return sdks, nil
+}
+
+// SetDefaultSdks replaces the organization's default SDKs with sdks; an empty
+// list clears them.
+func (r *OrganizationRepository) SetDefaultSdks(ctx context.Context, organizationId string, sdks []registry.SDK) error {
+	var span trace.Span
+	ctx, span = r.tracer.Start(ctx, "SetDefaultSdks", trace.WithAttributes(
+		attribute.KeyValue{
+			Key:   "organizationId",
+			Value: attribute.StringValue(organizationId),
+		},
+		attribute.KeyValue{
+			Key:   "sdks",
+			Value: attribute.StringValue(fmt.Sprintf("%v", sdks)),
+		},
+	))
+	defer span.End()
+
+	ctx, cancel := postgres.WithQueryTimeout(ctx, r.queryTimeout)
+	defer cancel()
+
+	return r.withTx(ctx, func(tx pgx.Tx) error {
+		if _, err := tx.Exec(ctx, `DELETE FROM organization_sdk_defaults WHERE organization_id = $1`, organizationId); err != nil {
+			span.RecordError(err)
+			return postgres.QueryError(ctx, connect.NewError(connect.CodeInternal, errors.New("failed to clear default sdks")))
+		}
+
+		for _, sdk := range sdks {
+			if _, err := tx.Exec(ctx,
+				`INSERT INTO organization_sdk_defaults (organization_id, sdk) VALUES ($1, $2) ON CONFLICT DO NOTHING`,
+				organizationId, string(sdk),
+			); err != nil {
+				span.RecordError(err)
+				return postgres.QueryError(ctx, connect.NewError(connect.CodeInternal, errors.New("failed to save default sdk")))
+			}
+		}
+
+		return nil
+	})
+}
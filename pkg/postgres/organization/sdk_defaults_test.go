@@ -0,0 +1,91 @@
+package organization
+
+import (
+	"testing"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"hasir-api/internal/registry"
+	"hasir-api/pkg/proto"
+)
+
+func createOrganizationSdkDefaultsTable(t *testing.T, connString string) {
+	t.Helper()
+
+	conn, err := pgx.Connect(t.Context(), connString)
+	require.NoError(t, err)
+	defer func() {
+		_ = conn.Close(t.Context())
+	}()
+
+	_, err = conn.Exec(t.Context(), `DO $$ BEGIN
+		CREATE TYPE sdk_type AS ENUM (
+			'GO_PROTOBUF',
+			'GO_CONNECTRPC',
+			'GO_GRPC',
+			'JS_BUFBUILD_ES',
+			'JS_PROTOBUF',
+			'JS_CONNECTRPC'
+		);
+	EXCEPTION
+		WHEN duplicate_object THEN null;
+	END $$`)
+	require.NoError(t, err)
+
+	_, err = conn.Exec(t.Context(), `CREATE TABLE organization_sdk_defaults (
+		organization_id VARCHAR(36) NOT NULL REFERENCES organizations(id) ON DELETE CASCADE,
+		sdk sdk_type NOT NULL,
+		created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+		PRIMARY KEY (organization_id, sdk)
+	)`)
+	require.NoError(t, err)
+}
+
+func TestPgRepository_DefaultSdks(t *testing.T) {
+	container := setupPgContainer(t)
+	defer func() {
+		err := container.Terminate(t.Context())
+		require.NoError(t, err)
+	}()
+
+	connString, err := container.ConnectionString(t.Context())
+	require.NoError(t, err)
+
+	setupTestDatabase(t, connString)
+	createOrganizationSdkDefaultsTable(t, connString)
+
+	repo, pool := setupTestRepository(t, connString)
+	defer pool.Close()
+
+	org := createTestOrganization(t, "defaults", proto.VisibilityPrivate)
+	require.NoError(t, repo.CreateOrganization(t.Context(), org))
+	other := createTestOrganization(t, "other", proto.VisibilityPrivate)
+	require.NoError(t, repo.CreateOrganization(t.Context(), other))
+
+	sdks, err := repo.GetDefaultSdks(t.Context(), org.Id)
+	require.NoError(t, err)
+	assert.Empty(t, sdks)
+
+	require.NoError(t, repo.SetDefaultSdks(t.Context(), org.Id, []registry.SDK{registry.SdkGoProtobuf, registry.SdkJsBufbuildEs}))
+	require.NoError(t, repo.SetDefaultSdks(t.Context(), other.Id, []registry.SDK{registry.SdkGoGrpc}))
+
+	sdks, err = repo.GetDefaultSdks(t.Context(), org.Id)
+	require.NoError(t, err)
+	assert.Equal(t, []registry.SDK{registry.SdkGoProtobuf, registry.SdkJsBufbuildEs}, sdks)
+
+	require.NoError(t, repo.SetDefaultSdks(t.Context(), org.Id, []registry.SDK{registry.SdkGoConnectRpc}))
+	sdks, err = repo.GetDefaultSdks(t.Context(), org.Id)
+	require.NoError(t, err)
+	assert.Equal(t, []registry.SDK{registry.SdkGoConnectRpc}, sdks)
+
+	require.NoError(t, repo.SetDefaultSdks(t.Context(), org.Id, nil))
+	sdks, err = repo.GetDefaultSdks(t.Context(), org.Id)
+	require.NoError(t, err)
+	assert.Empty(t, sdks)
+
+	sdks, err = repo.GetDefaultSdks(t.Context(), other.Id)
+	require.NoError(t, err)
+	assert.Equal(t, []registry.SDK{registry.SdkGoGrpc}, sdks)
+}
@@ -0,0 +1,122 @@
+package registry
+
+import (
+	"context"
+	"errors"
+
+	"connectrpc.com/connect"
+	"github.com/jackc/pgx/v5"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+
+	"hasir-api/internal/registry"
+	"hasir-api/pkg/postgres"
+)
+
+// activityEventsSQL lists the pushes and finished SDK generations of every
+// repository. Feeds join it to the repositories they cover and page the result.
+const activityEventsSQL = `
+	SELECT p.id, 'push' AS type, p.repository_id, p.commit_hash,
+		NULL::TEXT AS sdk, NULL::TEXT AS status, p.pushed_at AS occurred_at
+	FROM repository_pushes p
+	UNION ALL
+	SELECT g.id, 'sdk_generation' AS type, g.repository_id, g.commit_hash,
+		g.sdk::TEXT, g.status::TEXT, COALESCE(g.completed_at, g.processed_at, g.created_at) AS occurred_at
+	FROM sdk_generation_jobs g
+	WHERE g.status IN ('completed', 'failed')`
+
+func (r *PgRepository) RecordPush(ctx context.Context, push *registry.PushDTO) error {
+	var span trace.Span
+	ctx, span = r.tracer.Start(ctx, "RecordPush", trace.WithAttributes(
+		attribute.KeyValue{
+			Key:   "repositoryId",
+			Value: attribute.StringValue(push.RepositoryId),
+		},
+		attribute.KeyValue{
+			Key:   "commitHash",
+			Value: attribute.StringValue(push.CommitHash),
+		},
+	))
+	defer span.End()
+
+	ctx, cancel := postgres.WithQueryTimeout(ctx, r.queryTimeout)
+	defer cancel()
+
+	connection, err := r.connectionPool.Acquire(ctx)
+	if err != nil {
+		return postgres.QueryError(ctx, ErrFailedAcquireConnection)
+	}
+	defer connection.Release()
+
+	sql := `INSERT INTO repository_pushes (id, repository_id, commit_hash, pushed_at)
+			VALUES (@Id, @RepositoryId, @CommitHash, @PushedAt)`
+	sqlArgs := pgx.NamedArgs{
+		"Id":           push.Id,
+		"RepositoryId": push.RepositoryId,
+		"CommitHash":   push.CommitHash,
+		"PushedAt":     push.PushedAt,
+	}
+
+	if _, err := connection.Exec(ctx, sql, sqlArgs); err != nil {
+		span.RecordError(err)
+		return postgres.QueryError(ctx, connect.NewError(connect.CodeInternal, errors.New("failed to record push")))
+	}
+
+	return nil
+}
+
+// GetUserActivityFeed merges the activity of every live repository in the
+// organizations userId belongs to, newest first.
+func (r *PgRepository) GetUserActivityFeed(ctx context.Context, userId string, page, pageSize int) ([]registry.ActivityDTO, error) {
+	var span trace.Span
+	ctx, span = r.tracer.Start(ctx, "GetUserActivityFeed", trace.WithAttributes(
+		attribute.KeyValue{
+			Key:   "userId",
+			Value: attribute.StringValue(userId),
+		},
+		attribute.KeyValue{
+			Key:   "page",
+			Value: attribute.IntValue(page),
+		},
+		attribute.KeyValue{
+			Key:   "pageSize",
+			Value: attribute.IntValue(pageSize),
+		},
+	))
+	defer span.End()
+
+	ctx, cancel := postgres.WithQueryTimeout(ctx, r.queryTimeout)
+	defer cancel()
+
+	connection, err := r.readPool(ctx).Acquire(ctx)
+	if err != nil {
+		return nil, postgres.QueryError(ctx, ErrFailedAcquireConnection)
+	}
+	defer connection.Release()
+
+	offset := (page - 1) * pageSize
+	sql := `
+		SELECT a.id, a.type, a.repository_id, r.name AS repository_name, r.organization_id,
+			a.commit_hash, a.sdk, a.status, a.occurred_at
+		FROM (` + activityEventsSQL + `) a
+		INNER JOIN repositories r ON r.id = a.repository_id AND r.deleted_at IS NULL
+		WHERE r.organization_id IN (
+			SELECT organization_id FROM organization_members WHERE user_id = $1
+		)
+		ORDER BY a.occurred_at DESC, a.id
+		LIMIT $2 OFFSET $3`
+
+	rows, err := connection.Query(ctx, sql, userId, pageSize, offset)
+	if err != nil {
+		span.RecordError(err)
+		return nil, postgres.QueryError(ctx, connect.NewError(connect.CodeInternal, errors.New("failed to query activity feed")))
+	}
+
+	activity, err := pgx.CollectRows(rows, pgx.RowToStructByName[registry.ActivityDTO])
+	if err != nil {
+		span.RecordError(err)
+		return nil, postgres.QueryError(ctx, connect.NewError(connect.CodeInternal, errors.New("failed to collect activity feed rows")))
+	}
+
+	return activity, nil
+}
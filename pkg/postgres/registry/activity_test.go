@@ -0,0 +1,126 @@
+package registry
+
+import (
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"hasir-api/internal/registry"
+)
+
+func createRepositoryPushesTable(t *testing.T, pool *pgxpool.Pool) {
+	t.Helper()
+
+	_, err := pool.Exec(t.Context(), `CREATE TABLE repository_pushes (
+		id VARCHAR(36) PRIMARY KEY,
+		repository_id VARCHAR(36) NOT NULL,
+		commit_hash VARCHAR(40) NOT NULL,
+		pushed_at TIMESTAMP NOT NULL DEFAULT NOW()
+	)`)
+	require.NoError(t, err)
+}
+
+func TestPgRepository_GetUserActivityFeed(t *testing.T) {
+	container := setupPgContainer(t)
+	defer func() {
+		err := container.Terminate(t.Context())
+		require.NoError(t, err)
+	}()
+
+	connString, err := container.ConnectionString(t.Context())
+	require.NoError(t, err)
+
+	createRepositoriesAndMembersTables(t, connString)
+	createSdkGenerationJobsTable(t, connString)
+
+	repo, pool := setupTestRepository(t, connString)
+	defer pool.Close()
+
+	createRepositoryPushesTable(t, pool)
+
+	const userID = "user-123"
+	memberOrgs := []string{"org-a", "org-b"}
+	for _, orgID := range memberOrgs {
+		_, err = pool.Exec(t.Context(),
+			"INSERT INTO organization_members (id, organization_id, user_id, role, joined_at) VALUES ($1, $2, $3, 'reader', NOW())",
+			uuid.NewString(), orgID, userID)
+		require.NoError(t, err)
+	}
+	_, err = pool.Exec(t.Context(),
+		"INSERT INTO organization_members (id, organization_id, user_id, role, joined_at) VALUES ($1, 'org-c', 'someone-else', 'owner', NOW())",
+		uuid.NewString())
+	require.NoError(t, err)
+
+	repoIDs := map[string]string{}
+	for _, orgID := range []string{"org-a", "org-b", "org-c"} {
+		r := createTestRepository(t, "repo-"+orgID)
+		r.OrganizationId = orgID
+		require.NoError(t, repo.CreateRepository(t.Context(), r))
+		repoIDs[orgID] = r.Id
+	}
+
+	base := time.Now().UTC().Add(-time.Hour)
+	push := func(orgID, commitHash string, at time.Time) {
+		require.NoError(t, repo.RecordPush(t.Context(), &registry.PushDTO{
+			Id:           uuid.NewString(),
+			RepositoryId: repoIDs[orgID],
+			CommitHash:   commitHash,
+			PushedAt:     at,
+		}))
+	}
+	generation := func(orgID, commitHash, status string, completedAt *time.Time) {
+		_, err := pool.Exec(t.Context(),
+			`INSERT INTO sdk_generation_jobs (id, repository_id, commit_hash, sdk, status, created_at, completed_at)
+			VALUES ($1, $2, $3, 'GO_PROTOBUF', $4, $5, $6)`,
+			uuid.NewString(), repoIDs[orgID], commitHash, status, base, completedAt)
+		require.NoError(t, err)
+	}
+
+	push("org-a", "aaaa", base.Add(time.Minute))
+	push("org-b", "bbbb", base.Add(2*time.Minute))
+	push("org-c", "cccc", base.Add(3*time.Minute))
+	completedAt := base.Add(4 * time.Minute)
+	generation("org-b", "bbbb", "completed", &completedAt)
+	generation("org-c", "cccc", "completed", &completedAt)
+	generation("org-a", "aaaa", "pending", nil)
+
+	t.Run("only member organizations appear, newest first", func(t *testing.T) {
+		feed, err := repo.GetUserActivityFeed(t.Context(), userID, 1, 10)
+		require.NoError(t, err)
+		require.Len(t, feed, 3)
+
+		assert.Equal(t, registry.ActivityTypeSdkGeneration, feed[0].Type)
+		assert.Equal(t, "org-b", feed[0].OrganizationId)
+		require.NotNil(t, feed[0].Sdk)
+		assert.Equal(t, registry.SdkGoProtobuf, *feed[0].Sdk)
+
+		assert.Equal(t, registry.ActivityTypePush, feed[1].Type)
+		assert.Equal(t, "org-b", feed[1].OrganizationId)
+		assert.Equal(t, "repo-org-b", feed[1].RepositoryName)
+
+		assert.Equal(t, registry.ActivityTypePush, feed[2].Type)
+		assert.Equal(t, "org-a", feed[2].OrganizationId)
+		assert.Equal(t, "aaaa", feed[2].CommitHash)
+
+		for _, entry := range feed {
+			assert.NotEqual(t, "org-c", entry.OrganizationId)
+		}
+	})
+
+	t.Run("pages the merged feed", func(t *testing.T) {
+		feed, err := repo.GetUserActivityFeed(t.Context(), userID, 2, 2)
+		require.NoError(t, err)
+		require.Len(t, feed, 1)
+		assert.Equal(t, "org-a", feed[0].OrganizationId)
+	})
+
+	t.Run("user without organizations has an empty feed", func(t *testing.T) {
+		feed, err := repo.GetUserActivityFeed(t.Context(), "nobody", 1, 10)
+		require.NoError(t, err)
+		assert.Empty(t, feed)
+	})
+}
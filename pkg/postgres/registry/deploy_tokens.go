@@ -0,0 +1,139 @@
+package registry
+
+import (
+	"context"
+	"errors"
+
+	"connectrpc.com/connect"
+	"github.com/jackc/pgx/v5"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+
+	"hasir-api/internal/registry"
+	"hasir-api/pkg/postgres"
+)
+
+var ErrDeployTokenNotFound = connect.NewError(connect.CodeNotFound, errors.New("deploy token not found"))
+
+func (r *PgRepository) CreateDeployToken(ctx context.Context, token *registry.DeployTokenDTO) error {
+	var span trace.Span
+	ctx, span = r.tracer.Start(ctx, "CreateDeployToken", trace.WithAttributes(
+		attribute.KeyValue{
+			Key:   "repositoryId",
+			Value: attribute.StringValue(token.RepositoryId),
+		},
+		attribute.KeyValue{
+			Key:   "readOnly",
+			Value: attribute.BoolValue(token.ReadOnly),
+		},
+	))
+	defer span.End()
+
+	ctx, cancel := postgres.WithQueryTimeout(ctx, r.queryTimeout)
+	defer cancel()
+
+	connection, err := r.connectionPool.Acquire(ctx)
+	if err != nil {
+		return postgres.QueryError(ctx, ErrFailedAcquireConnection)
+	}
+	defer connection.Release()
+
+	sql := `INSERT INTO deploy_tokens (id, repository_id, token, read_only, created_by, created_at)
+			VALUES (@Id, @RepositoryId, @Token, @ReadOnly, @CreatedBy, @CreatedAt)`
+	sqlArgs := pgx.NamedArgs{
+		"Id":           token.Id,
+		"RepositoryId": token.RepositoryId,
+		"Token":        token.Token,
+		"ReadOnly":     token.ReadOnly,
+		"CreatedBy":    token.CreatedBy,
+		"CreatedAt":    token.CreatedAt,
+	}
+
+	if _, err := connection.Exec(ctx, sql, sqlArgs); err != nil {
+		span.RecordError(err)
+		return postgres.QueryError(ctx, connect.NewError(connect.CodeInternal, errors.New("failed to create deploy token")))
+	}
+
+	return nil
+}
+
+// GetDeployToken reads from the primary so a token works as soon as it has
+// been created. Tokens of deleted repositories are not found.
+func (r *PgRepository) GetDeployToken(ctx context.Context, token string) (*registry.DeployTokenDTO, error) {
+	var span trace.Span
+	ctx, span = r.tracer.Start(ctx, "GetDeployToken")
+	defer span.End()
+
+	ctx, cancel := postgres.WithQueryTimeout(ctx, r.queryTimeout)
+	defer cancel()
+
+	connection, err := r.connectionPool.Acquire(ctx)
+	if err != nil {
+		return nil, postgres.QueryError(ctx, ErrFailedAcquireConnection)
+	}
+	defer connection.Release()
+
+	sql := `SELECT dt.id, dt.repository_id, dt.token, dt.read_only, dt.created_by, dt.created_at, dt.deleted_at
+		FROM deploy_tokens dt
+		INNER JOIN repositories r ON r.id = dt.repository_id
+		WHERE dt.token = $1 AND dt.deleted_at IS NULL AND r.deleted_at IS NULL`
+
+	rows, err := connection.Query(ctx, sql, token)
+	if err != nil {
+		span.RecordError(err)
+		return nil, postgres.QueryError(ctx, connect.NewError(connect.CodeInternal, errors.New("failed to query deploy token")))
+	}
+	defer rows.Close()
+
+	deployToken, err := pgx.CollectOneRow(rows, pgx.RowToStructByName[registry.DeployTokenDTO])
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, ErrDeployTokenNotFound
+		}
+
+		return nil, postgres.QueryError(ctx, connect.NewError(connect.CodeInternal, errors.New("failed to collect row")))
+	}
+
+	return &deployToken, nil
+}
+
+// RevokeDeployToken marks the token deleted. A token that does not belong to
+// repositoryId, or was already revoked, is not found.
+func (r *PgRepository) RevokeDeployToken(ctx context.Context, repositoryId, tokenId string) error {
+	var span trace.Span
+	ctx, span = r.tracer.Start(ctx, "RevokeDeployToken", trace.WithAttributes(
+		attribute.KeyValue{
+			Key:   "repositoryId",
+			Value: attribute.StringValue(repositoryId),
+		},
+		attribute.KeyValue{
+			Key:   "tokenId",
+			Value: attribute.StringValue(tokenId),
+		},
+	))
+	defer span.End()
+
+	ctx, cancel := postgres.WithQueryTimeout(ctx, r.queryTimeout)
+	defer cancel()
+
+	connection, err := r.connectionPool.Acquire(ctx)
+	if err != nil {
+		return postgres.QueryError(ctx, ErrFailedAcquireConnection)
+	}
+	defer connection.Release()
+
+	sql := `UPDATE deploy_tokens SET deleted_at = NOW()
+		WHERE id = $1 AND repository_id = $2 AND deleted_at IS NULL`
+
+	result, err := connection.Exec(ctx, sql, tokenId, repositoryId)
+	if err != nil {
+		span.RecordError(err)
+		return postgres.QueryError(ctx, connect.NewError(connect.CodeInternal, errors.New("failed to revoke deploy token")))
+	}
+
+	if result.RowsAffected() == 0 {
+		return ErrDeployTokenNotFound
+	}
+
+	return nil
+}
@@ -0,0 +1,97 @@
+package registry
+
+import (
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"hasir-api/internal/registry"
+)
+
+func createDeployTokensTable(t *testing.T, pool *pgxpool.Pool) {
+	t.Helper()
+
+	_, err := pool.Exec(t.Context(), `CREATE TABLE deploy_tokens (
+		id VARCHAR(36) PRIMARY KEY,
+		repository_id VARCHAR(36) NOT NULL,
+		token VARCHAR(255) NOT NULL UNIQUE,
+		read_only BOOLEAN NOT NULL DEFAULT TRUE,
+		created_by VARCHAR(36) NOT NULL,
+		created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+		deleted_at TIMESTAMP WITH TIME ZONE
+	)`)
+	require.NoError(t, err)
+}
+
+func TestPgRepository_DeployTokens(t *testing.T) {
+	container := setupPgContainer(t)
+	defer func() {
+		err := container.Terminate(t.Context())
+		require.NoError(t, err)
+	}()
+
+	connString, err := container.ConnectionString(t.Context())
+	require.NoError(t, err)
+
+	createRepositoriesTable(t, connString)
+
+	repo, pool := setupTestRepository(t, connString)
+	defer pool.Close()
+
+	createDeployTokensTable(t, pool)
+
+	testRepo := createTestRepository(t, "deploy-"+uuid.NewString())
+	require.NoError(t, repo.CreateRepository(t.Context(), testRepo))
+
+	token := &registry.DeployTokenDTO{
+		Id:           uuid.NewString(),
+		RepositoryId: testRepo.Id,
+		Token:        registry.DeployTokenPrefix + uuid.NewString(),
+		ReadOnly:     true,
+		CreatedBy:    testRepo.CreatedBy,
+		CreatedAt:    time.Now().UTC(),
+	}
+	require.NoError(t, repo.CreateDeployToken(t.Context(), token))
+
+	t.Run("found by token", func(t *testing.T) {
+		found, err := repo.GetDeployToken(t.Context(), token.Token)
+		require.NoError(t, err)
+		assert.Equal(t, token.Id, found.Id)
+		assert.Equal(t, testRepo.Id, found.RepositoryId)
+		assert.True(t, found.ReadOnly)
+	})
+
+	t.Run("unknown token", func(t *testing.T) {
+		_, err := repo.GetDeployToken(t.Context(), registry.DeployTokenPrefix+"unknown")
+		require.ErrorIs(t, err, ErrDeployTokenNotFound)
+	})
+
+	t.Run("revoked token", func(t *testing.T) {
+		revoked := &registry.DeployTokenDTO{
+			Id:           uuid.NewString(),
+			RepositoryId: testRepo.Id,
+			Token:        registry.DeployTokenPrefix + uuid.NewString(),
+			CreatedBy:    testRepo.CreatedBy,
+			CreatedAt:    time.Now().UTC(),
+		}
+		require.NoError(t, repo.CreateDeployToken(t.Context(), revoked))
+
+		require.ErrorIs(t, repo.RevokeDeployToken(t.Context(), uuid.NewString(), revoked.Id), ErrDeployTokenNotFound)
+		require.NoError(t, repo.RevokeDeployToken(t.Context(), testRepo.Id, revoked.Id))
+		require.ErrorIs(t, repo.RevokeDeployToken(t.Context(), testRepo.Id, revoked.Id), ErrDeployTokenNotFound)
+
+		_, err := repo.GetDeployToken(t.Context(), revoked.Token)
+		require.ErrorIs(t, err, ErrDeployTokenNotFound)
+	})
+
+	t.Run("token of a deleted repository", func(t *testing.T) {
+		require.NoError(t, repo.DeleteRepository(t.Context(), testRepo.Id))
+
+		_, err := repo.GetDeployToken(t.Context(), token.Token)
+		require.ErrorIs(t, err, ErrDeployTokenNotFound)
+	})
+}
@@ -0,0 +1,298 @@
+package registry
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"connectrpc.com/connect"
+	"github.com/jackc/pgx/v5"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+	"go.uber.org/zap"
+
+	"hasir-api/internal/registry"
+)
+
+// EnqueueDocsGenerationJob relies on the partial unique index over in-flight
+// jobs, so two concurrent requests for the same repository enqueue one build.
+func (q *SdkGenerationJobQueue) EnqueueDocsGenerationJob(ctx context.Context, job *registry.DocsGenerationJobDTO) (bool, error) {
+	var span trace.Span
+	ctx, span = q.tracer.Start(ctx, "EnqueueDocsGenerationJob", trace.WithAttributes(
+		attribute.KeyValue{
+			Key:   "repositoryId",
+			Value: attribute.StringValue(job.RepositoryId),
+		},
+		attribute.KeyValue{
+			Key:   "commitHash",
+			Value: attribute.StringValue(job.CommitHash),
+		},
+	))
+	defer span.End()
+
+	connection, err := q.connectionPool.Acquire(ctx)
+	if err != nil {
+		return false, connect.NewError(connect.CodeInternal, errors.New("failed to acquire connection"))
+	}
+	defer connection.Release()
+
+	sql := `INSERT INTO docs_generation_jobs (id, repository_id, commit_hash, status, attempts, max_attempts, created_at)
+			VALUES ($1, $2, $3, $4, $5, $6, $7)
+			ON CONFLICT (repository_id) WHERE status IN ('pending', 'processing') DO NOTHING`
+
+	result, err := connection.Exec(ctx, sql,
+		job.Id,
+		job.RepositoryId,
+		job.CommitHash,
+		job.Status,
+		job.Attempts,
+		job.MaxAttempts,
+		job.CreatedAt,
+	)
+	if err != nil {
+		span.RecordError(err)
+		return false, connect.NewError(connect.CodeInternal, fmt.Errorf("failed to enqueue docs generation job: %w", err))
+	}
+
+	if result.RowsAffected() == 0 {
+		return false, nil
+	}
+
+	zap.L().Info("docs generation job enqueued successfully",
+		zap.String("jobId", job.Id),
+		zap.String("repositoryId", job.RepositoryId),
+		zap.String("commitHash", job.CommitHash))
+
+	return true, nil
+}
+
+func (q *SdkGenerationJobQueue) GetPendingDocsGenerationJobs(ctx context.Context, limit int) ([]*registry.DocsGenerationJobDTO, error) {
+	var span trace.Span
+	ctx, span = q.tracer.Start(ctx, "GetPendingDocsGenerationJobs", trace.WithAttributes(
+		attribute.KeyValue{
+			Key:   "limit",
+			Value: attribute.IntValue(limit),
+		},
+	))
+	defer span.End()
+
+	var jobs []registry.DocsGenerationJobDTO
+	err := q.withTx(ctx, func(tx pgx.Tx) error {
+		sql := `UPDATE docs_generation_jobs
+			SET status = 'processing', processed_at = NOW(), attempts = attempts + 1
+			WHERE id IN (
+				SELECT id FROM docs_generation_jobs
+				WHERE status = 'pending'
+				ORDER BY created_at ASC
+				LIMIT $1
+				FOR UPDATE SKIP LOCKED
+			)
+			RETURNING id, repository_id, commit_hash, status, attempts, max_attempts, created_at, processed_at, completed_at, error_message`
+
+		rows, err := tx.Query(ctx, sql, limit)
+		if err != nil {
+			span.RecordError(err)
+			return connect.NewError(connect.CodeInternal, errors.New("failed to query and update pending docs generation jobs"))
+		}
+		defer rows.Close()
+
+		jobs, err = pgx.CollectRows(rows, pgx.RowToStructByName[registry.DocsGenerationJobDTO])
+		if err != nil {
+			span.RecordError(err)
+			return connect.NewError(connect.CodeInternal, errors.New("failed to collect docs generation job rows"))
+		}
+
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]*registry.DocsGenerationJobDTO, len(jobs))
+	for i := range jobs {
+		result[i] = &jobs[i]
+	}
+
+	return result, nil
+}
+
+func (q *SdkGenerationJobQueue) UpdateDocsGenerationJobStatus(ctx context.Context, jobId string, status registry.SdkGenerationJobStatus, errorMsg *string) error {
+	var span trace.Span
+	ctx, span = q.tracer.Start(ctx, "UpdateDocsGenerationJobStatus", trace.WithAttributes(
+		attribute.KeyValue{
+			Key:   "jobId",
+			Value: attribute.StringValue(jobId),
+		},
+		attribute.KeyValue{
+			Key:   "status",
+			Value: attribute.StringValue(string(status)),
+		},
+	))
+	defer span.End()
+
+	connection, err := q.connectionPool.Acquire(ctx)
+	if err != nil {
+		return connect.NewError(connect.CodeInternal, errors.New("failed to acquire connection"))
+	}
+	defer connection.Release()
+
+	var sql string
+	var sqlArgs pgx.NamedArgs
+
+	switch status {
+	case registry.SdkGenerationJobStatusCompleted:
+		sql = `UPDATE docs_generation_jobs SET status = @Status, completed_at = @CompletedAt WHERE id = @Id AND status = 'processing'`
+		sqlArgs = pgx.NamedArgs{
+			"Id":          jobId,
+			"Status":      status,
+			"CompletedAt": time.Now().UTC(),
+		}
+	case registry.SdkGenerationJobStatusFailed:
+		sql = `UPDATE docs_generation_jobs SET status = @Status, error_message = @ErrorMessage WHERE id = @Id AND status = 'processing'`
+		sqlArgs = pgx.NamedArgs{
+			"Id":           jobId,
+			"Status":       status,
+			"ErrorMessage": errorMsg,
+		}
+	case registry.SdkGenerationJobStatusPending:
+		sql = `UPDATE docs_generation_jobs SET status = @Status WHERE id = @Id AND status = 'processing'`
+		sqlArgs = pgx.NamedArgs{
+			"Id":     jobId,
+			"Status": status,
+		}
+	default:
+		return connect.NewError(connect.CodeInvalidArgument, fmt.Errorf("unsupported docs generation job status %q", status))
+	}
+
+	result, err := connection.Exec(ctx, sql, sqlArgs)
+	if err != nil {
+		span.RecordError(err)
+		return connect.NewError(connect.CodeInternal, errors.New("failed to update docs generation job status"))
+	}
+
+	if result.RowsAffected() == 0 {
+		return connect.NewError(connect.CodeNotFound, errors.New("docs generation job not found or status mismatch"))
+	}
+
+	return nil
+}
+
+// processDocsGenerationJobs claims one job per iteration and renews its lease
+// while it builds, like processSdkGenerationJobs, so no claimed job waits
+// without a lease.
+func (q *SdkGenerationJobQueue) processDocsGenerationJobs(ctx context.Context, docsGenerator registry.DocsGenerator, batchSize int) {
+	for range batchSize {
+		jobs, err := q.GetPendingDocsGenerationJobs(ctx, 1)
+		if err != nil {
+			zap.L().Error("failed to get pending docs generation jobs", zap.Error(err))
+			return
+		}
+
+		if len(jobs) == 0 {
+			return
+		}
+
+		q.processDocsGenerationJob(ctx, docsGenerator, jobs[0])
+	}
+}
+
+func (q *SdkGenerationJobQueue) processDocsGenerationJob(ctx context.Context, docsGenerator registry.DocsGenerator, job *registry.DocsGenerationJobDTO) {
+	stopRenewingLease := q.keepLeaseAlive(ctx, job.Id, q.RenewDocsLease)
+	err := docsGenerator.TriggerDocumentationGeneration(ctx, job.RepositoryId, job.CommitHash)
+	stopRenewingLease()
+	if err != nil {
+		zap.L().Error("failed to generate documentation",
+			zap.Error(err),
+			zap.String("jobId", job.Id),
+			zap.String("repositoryId", job.RepositoryId),
+			zap.String("commitHash", job.CommitHash))
+
+		if job.Attempts < job.MaxAttempts {
+			if updateErr := q.UpdateDocsGenerationJobStatus(ctx, job.Id, registry.SdkGenerationJobStatusPending, nil); updateErr != nil {
+				zap.L().Error("failed to reset docs job to pending", zap.Error(updateErr))
+			}
+		} else {
+			errorMsg := err.Error()
+			if updateErr := q.UpdateDocsGenerationJobStatus(ctx, job.Id, registry.SdkGenerationJobStatusFailed, &errorMsg); updateErr != nil {
+				zap.L().Error("failed to mark docs job as failed", zap.Error(updateErr))
+			}
+		}
+		return
+	}
+
+	if err := q.UpdateDocsGenerationJobStatus(ctx, job.Id, registry.SdkGenerationJobStatusCompleted, nil); err != nil {
+		zap.L().Error("failed to update docs job status to completed",
+			zap.Error(err),
+			zap.String("jobId", job.Id))
+		return
+	}
+
+	zap.L().Info("docs generation job completed successfully",
+		zap.String("jobId", job.Id),
+		zap.String("repositoryId", job.RepositoryId),
+		zap.String("commitHash", job.CommitHash))
+}
+
+// RenewDocsLease is the docs counterpart of RenewLease.
+func (q *SdkGenerationJobQueue) RenewDocsLease(ctx context.Context, jobId string) error {
+	var span trace.Span
+	ctx, span = q.tracer.Start(ctx, "RenewDocsLease", trace.WithAttributes(
+		attribute.KeyValue{
+			Key:   "jobId",
+			Value: attribute.StringValue(jobId),
+		},
+	))
+	defer span.End()
+
+	connection, err := q.connectionPool.Acquire(ctx)
+	if err != nil {
+		return connect.NewError(connect.CodeInternal, errors.New("failed to acquire connection"))
+	}
+	defer connection.Release()
+
+	sql := `UPDATE docs_generation_jobs SET processed_at = $1 WHERE id = $2 AND status = 'processing'`
+	result, err := connection.Exec(ctx, sql, time.Now().UTC(), jobId)
+	if err != nil {
+		span.RecordError(err)
+		return connect.NewError(connect.CodeInternal, errors.New("failed to renew docs generation job lease"))
+	}
+
+	if result.RowsAffected() == 0 {
+		return connect.NewError(connect.CodeNotFound, errors.New("docs generation job not found or not processing"))
+	}
+
+	return nil
+}
+
+// requeueStaleDocsGenerationJobs releases builds whose worker died mid-run:
+// a build whose lease has not been renewed within the lease timeout is
+// treated as abandoned; otherwise it would block every later regeneration of
+// the repository.
+func (q *SdkGenerationJobQueue) requeueStaleDocsGenerationJobs(ctx context.Context) {
+	if q.leaseTimeout <= 0 {
+		return
+	}
+
+	connection, err := q.connectionPool.Acquire(ctx)
+	if err != nil {
+		zap.L().Error("failed to acquire connection", zap.Error(err))
+		return
+	}
+	defer connection.Release()
+
+	sql := `UPDATE docs_generation_jobs
+		SET status = CASE WHEN attempts >= max_attempts THEN 'failed' ELSE 'pending' END
+		WHERE status = 'processing' AND processed_at < $1`
+	result, err := connection.Exec(ctx, sql, time.Now().UTC().Add(-q.leaseTimeout))
+	if err != nil {
+		zap.L().Error("failed to requeue stale docs generation jobs", zap.Error(err))
+		return
+	}
+
+	if result.RowsAffected() > 0 {
+		zap.L().Warn("released stale docs generation jobs",
+			zap.Int64("count", result.RowsAffected()),
+			zap.Duration("leaseTimeout", q.leaseTimeout))
+	}
+}
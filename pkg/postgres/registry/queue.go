@@ -15,28 +15,42 @@ import (
 	"go.uber.org/zap"
 
 	"hasir-api/internal/registry"
+	"hasir-api/pkg/postgres"
 )
 
 type SdkGenerationJobQueue struct {
 	connectionPool *pgxpool.Pool
 	tracer         trace.Tracer
+	leaseTimeout   time.Duration
 	stopChan       chan struct{}
 	stopOnce       sync.Once
 	processorWg    sync.WaitGroup
 }
 
-func NewSdkGenerationJobQueue(connectionPool *pgxpool.Pool, tracer trace.Tracer) *SdkGenerationJobQueue {
+func NewSdkGenerationJobQueue(connectionPool *pgxpool.Pool, tracer trace.Tracer, leaseTimeout time.Duration) *SdkGenerationJobQueue {
 	return &SdkGenerationJobQueue{
 		connectionPool: connectionPool,
 		tracer:         tracer,
+		leaseTimeout:   leaseTimeout,
 		stopChan:       make(chan struct{}),
 	}
 }
 
+func (q *SdkGenerationJobQueue) withTx(ctx context.Context, fn func(pgx.Tx) error) error {
+	connection, err := q.connectionPool.Acquire(ctx)
+	if err != nil {
+		return connect.NewError(connect.CodeInternal, errors.New("failed to acquire connection"))
+	}
+	defer connection.Release()
+
+	return postgres.WithTx(ctx, connection, fn)
+}
+
 func (q *SdkGenerationJobQueue) Start(
 	ctx context.Context,
 	sdkGenerator registry.SdkGenerator,
 	triggerProcessor registry.SdkTriggerProcessor,
+	docsGenerator registry.DocsGenerator,
 	batchSize int,
 	pollInterval time.Duration,
 ) {
@@ -48,7 +62,8 @@ func (q *SdkGenerationJobQueue) Start(
 
 		zap.L().Info("sdk generation job processor started",
 			zap.Int("batchSize", batchSize),
-			zap.Duration("pollInterval", pollInterval))
+			zap.Duration("pollInterval", pollInterval),
+			zap.Duration("leaseTimeout", q.leaseTimeout))
 
 		for {
 			select {
@@ -56,8 +71,11 @@ func (q *SdkGenerationJobQueue) Start(
 				zap.L().Info("sdk generation job processor stopping")
 				return
 			case <-ticker.C:
+				q.recoverStaleSdkGenerationJobs(ctx)
 				q.processSdkTriggerJobs(ctx, triggerProcessor, batchSize)
 				q.processSdkGenerationJobs(ctx, sdkGenerator, batchSize)
+				q.requeueStaleDocsGenerationJobs(ctx)
+				q.processDocsGenerationJobs(ctx, docsGenerator, batchSize)
 			}
 		}
 	}()
@@ -76,59 +94,44 @@ func (q *SdkGenerationJobQueue) EnqueueSdkGenerationJobs(ctx context.Context, jo
 		return nil
 	}
 
-	connection, err := q.connectionPool.Acquire(ctx)
-	if err != nil {
-		return connect.NewError(connect.CodeInternal, errors.New("failed to acquire connection"))
-	}
-	defer connection.Release()
-
-	tx, err := connection.Begin(ctx)
-	if err != nil {
-		return connect.NewError(connect.CodeInternal, errors.New("failed to begin transaction"))
-	}
-	committed := false
-	defer func() {
-		if !committed {
-			_ = tx.Rollback(ctx)
-		}
-	}()
-
-	jobSQL := `INSERT INTO sdk_generation_jobs (id, repository_id, commit_hash, sdk, status, attempts, max_attempts, created_at)
+	err := q.withTx(ctx, func(tx pgx.Tx) error {
+		jobSQL := `INSERT INTO sdk_generation_jobs (id, repository_id, commit_hash, sdk, status, attempts, max_attempts, created_at)
 			VALUES (@Id, @RepositoryId, @CommitHash, @Sdk, @Status, @Attempts, @MaxAttempts, @CreatedAt)`
 
-	jobBatch := &pgx.Batch{}
-	for _, job := range jobs {
-		jobArgs := pgx.NamedArgs{
-			"Id":           job.Id,
-			"RepositoryId": job.RepositoryId,
-			"CommitHash":   job.CommitHash,
-			"Sdk":          job.Sdk,
-			"Status":       job.Status,
-			"Attempts":     job.Attempts,
-			"MaxAttempts":  job.MaxAttempts,
-			"CreatedAt":    job.CreatedAt,
+		jobBatch := &pgx.Batch{}
+		for _, job := range jobs {
+			jobArgs := pgx.NamedArgs{
+				"Id":           job.Id,
+				"RepositoryId": job.RepositoryId,
+				"CommitHash":   job.CommitHash,
+				"Sdk":          job.Sdk,
+				"Status":       job.Status,
+				"Attempts":     job.Attempts,
+				"MaxAttempts":  job.MaxAttempts,
+				"CreatedAt":    job.CreatedAt,
+			}
+			jobBatch.Queue(jobSQL, jobArgs)
 		}
-		jobBatch.Queue(jobSQL, jobArgs)
-	}
 
-	jobResults := tx.SendBatch(ctx, jobBatch)
+		jobResults := tx.SendBatch(ctx, jobBatch)
 
-	for i := 0; i < len(jobs); i++ {
-		_, err := jobResults.Exec()
-		if err != nil {
-			_ = jobResults.Close()
-			return connect.NewError(connect.CodeInternal, fmt.Errorf("failed to enqueue job %d: %w", i, err))
+		for i := 0; i < len(jobs); i++ {
+			_, err := jobResults.Exec()
+			if err != nil {
+				_ = jobResults.Close()
+				return connect.NewError(connect.CodeInternal, fmt.Errorf("failed to enqueue job %d: %w", i, err))
+			}
 		}
-	}
 
-	if err := jobResults.Close(); err != nil {
-		return connect.NewError(connect.CodeInternal, fmt.Errorf("failed to close job batch results: %w", err))
-	}
+		if err := jobResults.Close(); err != nil {
+			return connect.NewError(connect.CodeInternal, fmt.Errorf("failed to close job batch results: %w", err))
+		}
 
-	if err := tx.Commit(ctx); err != nil {
-		return connect.NewError(connect.CodeInternal, errors.New("failed to commit transaction"))
+		return nil
+	})
+	if err != nil {
+		return err
 	}
-	committed = true
 
 	zap.L().Info("sdk generation jobs enqueued successfully", zap.Int("count", len(jobs)))
 
@@ -175,25 +178,9 @@ func (q *SdkGenerationJobQueue) GetPendingSdkTriggerJobs(ctx context.Context, li
 	))
 	defer span.End()
 
-	connection, err := q.connectionPool.Acquire(ctx)
-	if err != nil {
-		return nil, connect.NewError(connect.CodeInternal, errors.New("failed to acquire connection"))
-	}
-	defer connection.Release()
-
-	tx, err := connection.Begin(ctx)
-	if err != nil {
-		span.RecordError(err)
-		return nil, connect.NewError(connect.CodeInternal, errors.New("failed to begin transaction"))
-	}
-	committed := false
-	defer func() {
-		if !committed {
-			_ = tx.Rollback(ctx)
-		}
-	}()
-
-	sql := `UPDATE sdk_trigger_jobs
+	var jobs []registry.SdkTriggerJobDTO
+	err := q.withTx(ctx, func(tx pgx.Tx) error {
+		sql := `UPDATE sdk_trigger_jobs
 			SET status = 'processing', processed_at = NOW(), attempts = attempts + 1
 			WHERE id IN (
 				SELECT id FROM sdk_trigger_jobs
@@ -204,24 +191,24 @@ func (q *SdkGenerationJobQueue) GetPendingSdkTriggerJobs(ctx context.Context, li
 			)
 			RETURNING id, repository_id, repo_path, status, attempts, max_attempts, created_at, processed_at, completed_at, error_message`
 
-	rows, err := tx.Query(ctx, sql, limit)
-	if err != nil {
-		span.RecordError(err)
-		return nil, connect.NewError(connect.CodeInternal, errors.New("failed to query and update pending sdk trigger jobs"))
-	}
-	defer rows.Close()
+		rows, err := tx.Query(ctx, sql, limit)
+		if err != nil {
+			span.RecordError(err)
+			return connect.NewError(connect.CodeInternal, errors.New("failed to query and update pending sdk trigger jobs"))
+		}
+		defer rows.Close()
 
-	jobs, err := pgx.CollectRows(rows, pgx.RowToStructByName[registry.SdkTriggerJobDTO])
-	if err != nil {
-		span.RecordError(err)
-		return nil, connect.NewError(connect.CodeInternal, errors.New("failed to collect sdk trigger job rows"))
-	}
+		jobs, err = pgx.CollectRows(rows, pgx.RowToStructByName[registry.SdkTriggerJobDTO])
+		if err != nil {
+			span.RecordError(err)
+			return connect.NewError(connect.CodeInternal, errors.New("failed to collect sdk trigger job rows"))
+		}
 
-	if err := tx.Commit(ctx); err != nil {
-		span.RecordError(err)
-		return nil, connect.NewError(connect.CodeInternal, errors.New("failed to commit transaction"))
+		return nil
+	})
+	if err != nil {
+		return nil, err
 	}
-	committed = true
 
 	result := make([]*registry.SdkTriggerJobDTO, len(jobs))
 	for i := range jobs {
@@ -351,86 +338,257 @@ func (q *SdkGenerationJobQueue) processSdkTriggerJobs(ctx context.Context, trigg
 	}
 }
 
+// processSdkGenerationJobs runs up to batchSize jobs, claiming each one only
+// when it is about to run. A job claimed with the rest of a batch would sit
+// in processing without a renewed lease while the ones before it ran, and be
+// requeued under the worker once the lease ran out.
 func (q *SdkGenerationJobQueue) processSdkGenerationJobs(ctx context.Context, sdkGenerator registry.SdkGenerator, batchSize int) {
-	jobs, err := q.GetPendingSdkGenerationJobs(ctx, batchSize)
+	for range batchSize {
+		jobs, err := q.GetPendingSdkGenerationJobs(ctx, 1)
+		if err != nil {
+			zap.L().Error("failed to get pending sdk generation jobs", zap.Error(err))
+			return
+		}
+
+		if len(jobs) == 0 {
+			return
+		}
+
+		q.processSdkGenerationJob(ctx, sdkGenerator, jobs[0])
+	}
+}
+
+func (q *SdkGenerationJobQueue) processSdkGenerationJob(ctx context.Context, sdkGenerator registry.SdkGenerator, job *registry.SdkGenerationJobDTO) {
+	stopRenewingLease := q.keepLeaseAlive(ctx, job.Id, q.RenewLease)
+	err := sdkGenerator.GenerateSDK(ctx, job.RepositoryId, job.CommitHash, job.Sdk)
+	stopRenewingLease()
 	if err != nil {
-		zap.L().Error("failed to get pending sdk generation jobs", zap.Error(err))
+		zap.L().Error("failed to generate SDK",
+			zap.Error(err),
+			zap.String("jobId", job.Id),
+			zap.String("repositoryId", job.RepositoryId),
+			zap.String("commitHash", job.CommitHash),
+			zap.String("sdk", string(job.Sdk)))
+
+		if job.Attempts < job.MaxAttempts {
+			if updateErr := q.UpdateSdkGenerationJobStatus(ctx, job.Id, registry.SdkGenerationJobStatusPending, nil); updateErr != nil {
+				zap.L().Error("failed to reset job to pending", zap.Error(updateErr))
+			}
+		} else {
+			errorMsg := err.Error()
+			if updateErr := q.UpdateSdkGenerationJobStatus(ctx, job.Id, registry.SdkGenerationJobStatusFailed, &errorMsg); updateErr != nil {
+				zap.L().Error("failed to mark job as failed", zap.Error(updateErr))
+			}
+		}
 		return
 	}
 
-	if len(jobs) == 0 {
+	if err := q.UpdateSdkGenerationJobStatus(ctx, job.Id, registry.SdkGenerationJobStatusCompleted, nil); err != nil {
+		zap.L().Error("failed to update job status to completed",
+			zap.Error(err),
+			zap.String("jobId", job.Id))
 		return
 	}
 
-	zap.L().Info("processing sdk generation jobs", zap.Int("count", len(jobs)))
+	zap.L().Info("sdk generation job completed successfully",
+		zap.String("jobId", job.Id),
+		zap.String("repositoryId", job.RepositoryId),
+		zap.String("commitHash", job.CommitHash),
+		zap.String("sdk", string(job.Sdk)))
+}
 
-	for _, job := range jobs {
-		err := sdkGenerator.GenerateSDK(ctx, job.RepositoryId, job.CommitHash, job.Sdk)
-		if err != nil {
-			zap.L().Error("failed to generate SDK",
-				zap.Error(err),
-				zap.String("jobId", job.Id),
-				zap.String("repositoryId", job.RepositoryId),
-				zap.String("commitHash", job.CommitHash),
-				zap.String("sdk", string(job.Sdk)))
+func (q *SdkGenerationJobQueue) recoverStaleSdkGenerationJobs(ctx context.Context) {
+	if q.leaseTimeout <= 0 {
+		return
+	}
 
-			if job.Attempts < job.MaxAttempts {
-				if updateErr := q.UpdateSdkGenerationJobStatus(ctx, job.Id, registry.SdkGenerationJobStatusPending, nil); updateErr != nil {
-					zap.L().Error("failed to reset job to pending", zap.Error(updateErr))
-				}
-			} else {
-				errorMsg := err.Error()
-				if updateErr := q.UpdateSdkGenerationJobStatus(ctx, job.Id, registry.SdkGenerationJobStatusFailed, &errorMsg); updateErr != nil {
-					zap.L().Error("failed to mark job as failed", zap.Error(updateErr))
+	requeued, err := q.RequeueStaleSdkGenerationJobs(ctx, q.leaseTimeout)
+	if err != nil {
+		zap.L().Error("failed to requeue stale sdk generation jobs", zap.Error(err))
+		return
+	}
+
+	if requeued > 0 {
+		zap.L().Warn("requeued stale sdk generation jobs",
+			zap.Int("count", requeued),
+			zap.Duration("leaseTimeout", q.leaseTimeout))
+	}
+}
+
+// keepLeaseAlive renews the job's lease with renew in the background until the
+// returned stop function is called, so long but healthy runs are not requeued.
+func (q *SdkGenerationJobQueue) keepLeaseAlive(ctx context.Context, jobId string, renew func(context.Context, string) error) func() {
+	if q.leaseTimeout <= 0 {
+		return func() {}
+	}
+
+	done := make(chan struct{})
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		ticker := time.NewTicker(q.leaseTimeout / 3)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-done:
+				return
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				if err := renew(ctx, jobId); err != nil {
+					zap.L().Warn("failed to renew job lease",
+						zap.String("jobId", jobId),
+						zap.Error(err))
 				}
 			}
-			continue
 		}
+	}()
 
-		if err := q.UpdateSdkGenerationJobStatus(ctx, job.Id, registry.SdkGenerationJobStatusCompleted, nil); err != nil {
-			zap.L().Error("failed to update job status to completed",
-				zap.Error(err),
-				zap.String("jobId", job.Id))
-			continue
+	return func() {
+		close(done)
+		wg.Wait()
+	}
+}
+
+func (q *SdkGenerationJobQueue) RenewLease(ctx context.Context, jobId string) error {
+	var span trace.Span
+	ctx, span = q.tracer.Start(ctx, "RenewLease", trace.WithAttributes(
+		attribute.KeyValue{
+			Key:   "jobId",
+			Value: attribute.StringValue(jobId),
+		},
+	))
+	defer span.End()
+
+	connection, err := q.connectionPool.Acquire(ctx)
+	if err != nil {
+		return connect.NewError(connect.CodeInternal, errors.New("failed to acquire connection"))
+	}
+	defer connection.Release()
+
+	sql := `UPDATE sdk_generation_jobs SET processed_at = $1 WHERE id = $2 AND status = 'processing'`
+	result, err := connection.Exec(ctx, sql, time.Now().UTC(), jobId)
+	if err != nil {
+		span.RecordError(err)
+		return connect.NewError(connect.CodeInternal, errors.New("failed to renew sdk generation job lease"))
+	}
+
+	if result.RowsAffected() == 0 {
+		return connect.NewError(connect.CodeNotFound, errors.New("sdk generation job not found or not processing"))
+	}
+
+	return nil
+}
+
+// RequeueStaleSdkGenerationJobs resets jobs whose lease has not been renewed
+// within leaseTimeout back to pending. The interrupted run was already counted
+// when the job was claimed, so jobs that have used up their attempts are failed
+// instead.
+func (q *SdkGenerationJobQueue) RequeueStaleSdkGenerationJobs(ctx context.Context, leaseTimeout time.Duration) (int, error) {
+	var span trace.Span
+	ctx, span = q.tracer.Start(ctx, "RequeueStaleSdkGenerationJobs", trace.WithAttributes(
+		attribute.KeyValue{
+			Key:   "leaseTimeout",
+			Value: attribute.StringValue(leaseTimeout.String()),
+		},
+	))
+	defer span.End()
+
+	expiredBefore := time.Now().UTC().Add(-leaseTimeout)
+
+	var requeued int64
+	err := q.withTx(ctx, func(tx pgx.Tx) error {
+		failSql := `UPDATE sdk_generation_jobs
+			SET status = 'failed', error_message = 'lease expired after final attempt'
+			WHERE status = 'processing' AND processed_at < $1 AND attempts >= max_attempts`
+		if _, err := tx.Exec(ctx, failSql, expiredBefore); err != nil {
+			span.RecordError(err)
+			return connect.NewError(connect.CodeInternal, errors.New("failed to fail exhausted stale sdk generation jobs"))
 		}
 
-		zap.L().Info("sdk generation job completed successfully",
-			zap.String("jobId", job.Id),
-			zap.String("repositoryId", job.RepositoryId),
-			zap.String("commitHash", job.CommitHash),
-			zap.String("sdk", string(job.Sdk)))
+		requeueSql := `UPDATE sdk_generation_jobs
+			SET status = 'pending'
+			WHERE status = 'processing' AND processed_at < $1 AND attempts < max_attempts`
+		result, err := tx.Exec(ctx, requeueSql, expiredBefore)
+		if err != nil {
+			span.RecordError(err)
+			return connect.NewError(connect.CodeInternal, errors.New("failed to requeue stale sdk generation jobs"))
+		}
+		requeued = result.RowsAffected()
+
+		return nil
+	})
+	if err != nil {
+		return 0, err
 	}
+
+	return int(requeued), nil
 }
 
-func (q *SdkGenerationJobQueue) GetPendingSdkGenerationJobs(ctx context.Context, limit int) ([]*registry.SdkGenerationJobDTO, error) {
+// GetLatestSdkJobStatuses picks the newest job per SDK; jobs cancelled along
+// with their organization say nothing about the SDK's health and are skipped.
+func (q *SdkGenerationJobQueue) GetLatestSdkJobStatuses(ctx context.Context, repositoryId string, sdks []registry.SDK) ([]registry.SdkJobStatusDTO, error) {
 	var span trace.Span
-	ctx, span = q.tracer.Start(ctx, "GetPendingSdkGenerationJobs", trace.WithAttributes(
+	ctx, span = q.tracer.Start(ctx, "GetLatestSdkJobStatuses", trace.WithAttributes(
 		attribute.KeyValue{
-			Key:   "limit",
-			Value: attribute.IntValue(limit),
+			Key:   "repositoryId",
+			Value: attribute.StringValue(repositoryId),
 		},
 	))
 	defer span.End()
 
+	if len(sdks) == 0 {
+		return nil, nil
+	}
+
 	connection, err := q.connectionPool.Acquire(ctx)
 	if err != nil {
 		return nil, connect.NewError(connect.CodeInternal, errors.New("failed to acquire connection"))
 	}
 	defer connection.Release()
 
-	tx, err := connection.Begin(ctx)
+	sdkNames := make([]string, len(sdks))
+	for i, sdk := range sdks {
+		sdkNames[i] = string(sdk)
+	}
+
+	sql := `SELECT DISTINCT ON (sdk) sdk, status
+		FROM sdk_generation_jobs
+		WHERE repository_id = $1
+			AND sdk = ANY($2::sdk_type[])
+			AND status IN ('pending', 'processing', 'completed', 'failed')
+		ORDER BY sdk, created_at DESC, id DESC`
+
+	rows, err := connection.Query(ctx, sql, repositoryId, sdkNames)
 	if err != nil {
 		span.RecordError(err)
-		return nil, connect.NewError(connect.CodeInternal, errors.New("failed to begin transaction"))
+		return nil, connect.NewError(connect.CodeInternal, errors.New("failed to query latest sdk generation jobs"))
 	}
-	committed := false
-	defer func() {
-		if !committed {
-			_ = tx.Rollback(ctx)
-		}
-	}()
 
-	sql := `UPDATE sdk_generation_jobs
+	statuses, err := pgx.CollectRows(rows, pgx.RowToStructByName[registry.SdkJobStatusDTO])
+	if err != nil {
+		span.RecordError(err)
+		return nil, connect.NewError(connect.CodeInternal, errors.New("failed to collect latest sdk generation jobs"))
+	}
+
+	return statuses, nil
+}
+
+func (q *SdkGenerationJobQueue) GetPendingSdkGenerationJobs(ctx context.Context, limit int) ([]*registry.SdkGenerationJobDTO, error) {
+	var span trace.Span
+	ctx, span = q.tracer.Start(ctx, "GetPendingSdkGenerationJobs", trace.WithAttributes(
+		attribute.KeyValue{
+			Key:   "limit",
+			Value: attribute.IntValue(limit),
+		},
+	))
+	defer span.End()
+
+	var jobs []registry.SdkGenerationJobDTO
+	err := q.withTx(ctx, func(tx pgx.Tx) error {
+		sql := `UPDATE sdk_generation_jobs
 			SET status = 'processing', processed_at = NOW(), attempts = attempts + 1
 			WHERE id IN (
 				SELECT id FROM sdk_generation_jobs
@@ -441,24 +599,24 @@ func (q *SdkGenerationJobQueue) GetPendingSdkGenerationJobs(ctx context.Context,
 			)
 			RETURNING id, repository_id, commit_hash, sdk, status, attempts, max_attempts, created_at, processed_at, completed_at, error_message`
 
-	rows, err := tx.Query(ctx, sql, limit)
-	if err != nil {
-		span.RecordError(err)
-		return nil, connect.NewError(connect.CodeInternal, errors.New("failed to query and update pending sdk generation jobs"))
-	}
-	defer rows.Close()
+		rows, err := tx.Query(ctx, sql, limit)
+		if err != nil {
+			span.RecordError(err)
+			return connect.NewError(connect.CodeInternal, errors.New("failed to query and update pending sdk generation jobs"))
+		}
+		defer rows.Close()
 
-	jobs, err := pgx.CollectRows(rows, pgx.RowToStructByName[registry.SdkGenerationJobDTO])
-	if err != nil {
-		span.RecordError(err)
-		return nil, connect.NewError(connect.CodeInternal, errors.New("failed to collect sdk generation job rows"))
-	}
+		jobs, err = pgx.CollectRows(rows, pgx.RowToStructByName[registry.SdkGenerationJobDTO])
+		if err != nil {
+			span.RecordError(err)
+			return connect.NewError(connect.CodeInternal, errors.New("failed to collect sdk generation job rows"))
+		}
 
-	if err := tx.Commit(ctx); err != nil {
-		span.RecordError(err)
-		return nil, connect.NewError(connect.CodeInternal, errors.New("failed to commit transaction"))
+		return nil
+	})
+	if err != nil {
+		return nil, err
 	}
-	committed = true
 
 	result := make([]*registry.SdkGenerationJobDTO, len(jobs))
 	for i := range jobs {
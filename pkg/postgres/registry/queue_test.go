@@ -25,11 +25,12 @@ func setupQueueTestEnvironment(t *testing.T) (*SdkGenerationJobQueue, *pgxpool.P
 	require.NoError(t, err)
 
 	createSdkGenerationJobsTable(t, connString)
+	createDocsGenerationJobsTable(t, connString)
 
 	pool, err := pgxpool.New(t.Context(), connString)
 	require.NoError(t, err)
 
-	queue := NewSdkGenerationJobQueue(pool, noop.NewTracerProvider().Tracer("test"))
+	queue := NewSdkGenerationJobQueue(pool, noop.NewTracerProvider().Tracer("test"), time.Minute)
 
 	cleanup := func() {
 		pool.Close()
@@ -96,6 +97,35 @@ func createSdkGenerationJobsTable(t *testing.T, connString string) {
 	require.NoError(t, err)
 }
 
+func createDocsGenerationJobsTable(t *testing.T, connString string) {
+	t.Helper()
+
+	conn, err := pgx.Connect(t.Context(), connString)
+	require.NoError(t, err)
+	defer func() {
+		err = conn.Close(t.Context())
+		require.NoError(t, err)
+	}()
+
+	_, err = conn.Exec(t.Context(), `CREATE TABLE IF NOT EXISTS docs_generation_jobs (
+		id VARCHAR(36) PRIMARY KEY,
+		repository_id VARCHAR(36) NOT NULL,
+		commit_hash VARCHAR(40) NOT NULL,
+		status VARCHAR(20) NOT NULL DEFAULT 'pending',
+		attempts INTEGER NOT NULL DEFAULT 0,
+		max_attempts INTEGER NOT NULL DEFAULT 3,
+		created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+		processed_at TIMESTAMP WITH TIME ZONE,
+		completed_at TIMESTAMP WITH TIME ZONE,
+		error_message TEXT
+	)`)
+	require.NoError(t, err)
+
+	_, err = conn.Exec(t.Context(), `CREATE UNIQUE INDEX idx_docs_generation_jobs_in_flight
+		ON docs_generation_jobs(repository_id) WHERE status IN ('pending', 'processing')`)
+	require.NoError(t, err)
+}
+
 func TestEnqueueSdkGenerationJobs(t *testing.T) {
 	queue, pool, cleanup := setupQueueTestEnvironment(t)
 	defer cleanup()
@@ -393,7 +423,7 @@ func TestSdkGenerationJobQueue_StartStop(t *testing.T) {
 	mockService.EXPECT().GenerateSDK(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Return(nil).AnyTimes()
 	mockService.EXPECT().ProcessSdkTrigger(gomock.Any(), gomock.Any(), gomock.Any()).Return(nil).AnyTimes()
 
-	queue.Start(ctx, mockService, mockService, 10, 100*time.Millisecond)
+	queue.Start(ctx, mockService, mockService, mockService, 10, 100*time.Millisecond)
 
 	time.Sleep(200 * time.Millisecond)
 
@@ -435,7 +465,7 @@ func TestProcessSdkGenerationJobs_Success(t *testing.T) {
 	err := queue.EnqueueSdkGenerationJobs(ctx, jobs)
 	require.NoError(t, err)
 
-	queue.Start(ctx, mockService, mockService, 10, 100*time.Millisecond)
+	queue.Start(ctx, mockService, mockService, mockService, 10, 100*time.Millisecond)
 	defer queue.Stop()
 
 	time.Sleep(500 * time.Millisecond)
@@ -483,7 +513,7 @@ func TestProcessSdkGenerationJobs_Retry(t *testing.T) {
 	err := queue.EnqueueSdkGenerationJobs(ctx, jobs)
 	require.NoError(t, err)
 
-	queue.Start(ctx, mockService, mockService, 10, 100*time.Millisecond)
+	queue.Start(ctx, mockService, mockService, mockService, 10, 100*time.Millisecond)
 	defer queue.Stop()
 
 	time.Sleep(1 * time.Second)
@@ -497,3 +527,285 @@ func TestProcessSdkGenerationJobs_Retry(t *testing.T) {
 	assert.NotNil(t, errorMsg)
 	assert.GreaterOrEqual(t, attemptCount, 3)
 }
+
+func TestRequeueStaleSdkGenerationJobs(t *testing.T) {
+	queue, pool, cleanup := setupQueueTestEnvironment(t)
+	defer cleanup()
+
+	ctx := t.Context()
+	now := time.Now().UTC()
+
+	insertProcessingJob := func(t *testing.T, processedAt time.Time, attempts, maxAttempts int) string {
+		t.Helper()
+
+		jobId := uuid.NewString()
+		_, err := pool.Exec(ctx, `INSERT INTO sdk_generation_jobs
+			(id, repository_id, commit_hash, sdk, status, attempts, max_attempts, created_at, processed_at)
+			VALUES ($1, $2, 'abc123', 'GO_PROTOBUF', 'processing', $3, $4, $5, $6)`,
+			jobId, uuid.NewString(), attempts, maxAttempts, processedAt, processedAt)
+		require.NoError(t, err)
+
+		return jobId
+	}
+
+	getJob := func(t *testing.T, jobId string) (registry.SdkGenerationJobStatus, int, *string) {
+		t.Helper()
+
+		var status registry.SdkGenerationJobStatus
+		var attempts int
+		var errorMsg *string
+		err := pool.QueryRow(ctx, "SELECT status, attempts, error_message FROM sdk_generation_jobs WHERE id = $1", jobId).
+			Scan(&status, &attempts, &errorMsg)
+		require.NoError(t, err)
+
+		return status, attempts, errorMsg
+	}
+
+	staleJobId := insertProcessingJob(t, now.Add(-time.Hour), 1, 5)
+	freshJobId := insertProcessingJob(t, now, 1, 5)
+	exhaustedJobId := insertProcessingJob(t, now.Add(-time.Hour), 5, 5)
+
+	requeued, err := queue.RequeueStaleSdkGenerationJobs(ctx, 10*time.Minute)
+	require.NoError(t, err)
+	assert.Equal(t, 1, requeued)
+
+	status, attempts, _ := getJob(t, staleJobId)
+	assert.Equal(t, registry.SdkGenerationJobStatusPending, status)
+	assert.Equal(t, 1, attempts)
+
+	status, _, _ = getJob(t, freshJobId)
+	assert.Equal(t, registry.SdkGenerationJobStatusProcessing, status)
+
+	status, _, errorMsg := getJob(t, exhaustedJobId)
+	assert.Equal(t, registry.SdkGenerationJobStatusFailed, status)
+	require.NotNil(t, errorMsg)
+
+	pending, err := queue.GetPendingSdkGenerationJobs(ctx, 10)
+	require.NoError(t, err)
+	require.Len(t, pending, 1)
+	assert.Equal(t, staleJobId, pending[0].Id)
+	assert.Equal(t, 2, pending[0].Attempts)
+}
+
+func TestRenewLease(t *testing.T) {
+	queue, pool, cleanup := setupQueueTestEnvironment(t)
+	defer cleanup()
+
+	ctx := t.Context()
+
+	insertProcessingJob := func(t *testing.T) string {
+		t.Helper()
+
+		jobId := uuid.NewString()
+		processedAt := time.Now().UTC()
+		_, err := pool.Exec(ctx, `INSERT INTO sdk_generation_jobs
+			(id, repository_id, commit_hash, sdk, status, attempts, max_attempts, created_at, processed_at)
+			VALUES ($1, $2, 'abc123', 'GO_PROTOBUF', 'processing', 1, 5, $3, $3)`,
+			jobId, uuid.NewString(), processedAt)
+		require.NoError(t, err)
+
+		return jobId
+	}
+
+	getStatus := func(t *testing.T, jobId string) registry.SdkGenerationJobStatus {
+		t.Helper()
+
+		var status registry.SdkGenerationJobStatus
+		err := pool.QueryRow(ctx, "SELECT status FROM sdk_generation_jobs WHERE id = $1", jobId).Scan(&status)
+		require.NoError(t, err)
+
+		return status
+	}
+
+	t.Run("renewing job is never requeued while silent job is", func(t *testing.T) {
+		const leaseTimeout = 300 * time.Millisecond
+
+		renewingJobId := insertProcessingJob(t)
+		silentJobId := insertProcessingJob(t)
+
+		stopRenewing := make(chan struct{})
+		renewErr := make(chan error, 1)
+		go func() {
+			ticker := time.NewTicker(leaseTimeout / 3)
+			defer ticker.Stop()
+			for {
+				select {
+				case <-stopRenewing:
+					renewErr <- nil
+					return
+				case <-ticker.C:
+					if err := queue.RenewLease(ctx, renewingJobId); err != nil {
+						renewErr <- err
+						return
+					}
+				}
+			}
+		}()
+
+		deadline := time.Now().Add(4 * leaseTimeout)
+		for time.Now().Before(deadline) {
+			_, err := queue.RequeueStaleSdkGenerationJobs(ctx, leaseTimeout)
+			require.NoError(t, err)
+			assert.Equal(t, registry.SdkGenerationJobStatusProcessing, getStatus(t, renewingJobId))
+			time.Sleep(leaseTimeout / 4)
+		}
+
+		close(stopRenewing)
+		require.NoError(t, <-renewErr)
+
+		assert.Equal(t, registry.SdkGenerationJobStatusProcessing, getStatus(t, renewingJobId))
+		assert.Equal(t, registry.SdkGenerationJobStatusPending, getStatus(t, silentJobId))
+	})
+
+	t.Run("worker keeps lease alive during generation", func(t *testing.T) {
+		queue.leaseTimeout = 300 * time.Millisecond
+		defer func() {
+			queue.leaseTimeout = time.Minute
+		}()
+
+		jobId := insertProcessingJob(t)
+
+		stop := queue.keepLeaseAlive(ctx, jobId, queue.RenewLease)
+		time.Sleep(3 * queue.leaseTimeout)
+
+		_, err := queue.RequeueStaleSdkGenerationJobs(ctx, queue.leaseTimeout)
+		stop()
+		require.NoError(t, err)
+
+		assert.Equal(t, registry.SdkGenerationJobStatusProcessing, getStatus(t, jobId))
+	})
+
+	t.Run("fails for job that is not processing", func(t *testing.T) {
+		err := queue.RenewLease(ctx, uuid.NewString())
+		require.Error(t, err)
+	})
+}
+
+func TestEnqueueDocsGenerationJob_Dedupe(t *testing.T) {
+	queue, _, cleanup := setupQueueTestEnvironment(t)
+	defer cleanup()
+
+	repositoryId := uuid.NewString()
+	newJob := func() *registry.DocsGenerationJobDTO {
+		return &registry.DocsGenerationJobDTO{
+			Id:           uuid.NewString(),
+			RepositoryId: repositoryId,
+			CommitHash:   "abc123",
+			Status:       registry.SdkGenerationJobStatusPending,
+			MaxAttempts:  3,
+			CreatedAt:    time.Now().UTC(),
+		}
+	}
+
+	enqueued, err := queue.EnqueueDocsGenerationJob(t.Context(), newJob())
+	require.NoError(t, err)
+	assert.True(t, enqueued)
+
+	enqueued, err = queue.EnqueueDocsGenerationJob(t.Context(), newJob())
+	require.NoError(t, err)
+	assert.False(t, enqueued, "pending build should absorb the second request")
+
+	jobs, err := queue.GetPendingDocsGenerationJobs(t.Context(), 10)
+	require.NoError(t, err)
+	require.Len(t, jobs, 1)
+
+	enqueued, err = queue.EnqueueDocsGenerationJob(t.Context(), newJob())
+	require.NoError(t, err)
+	assert.False(t, enqueued, "processing build should absorb the request too")
+
+	require.NoError(t, queue.UpdateDocsGenerationJobStatus(t.Context(), jobs[0].Id, registry.SdkGenerationJobStatusCompleted, nil))
+
+	enqueued, err = queue.EnqueueDocsGenerationJob(t.Context(), newJob())
+	require.NoError(t, err)
+	assert.True(t, enqueued, "finished build should not block a new one")
+}
+
+func TestRenewDocsLease(t *testing.T) {
+	queue, pool, cleanup := setupQueueTestEnvironment(t)
+	defer cleanup()
+
+	ctx := t.Context()
+	queue.leaseTimeout = 300 * time.Millisecond
+
+	insertProcessingJob := func(t *testing.T) string {
+		t.Helper()
+
+		jobId := uuid.NewString()
+		_, err := pool.Exec(ctx, `INSERT INTO docs_generation_jobs
+			(id, repository_id, commit_hash, status, attempts, max_attempts, created_at, processed_at)
+			VALUES ($1, $2, 'abc123', 'processing', 1, 3, $3, $3)`,
+			jobId, uuid.NewString(), time.Now().UTC())
+		require.NoError(t, err)
+
+		return jobId
+	}
+
+	getStatus := func(t *testing.T, jobId string) registry.SdkGenerationJobStatus {
+		t.Helper()
+
+		var status registry.SdkGenerationJobStatus
+		err := pool.QueryRow(ctx, "SELECT status FROM docs_generation_jobs WHERE id = $1", jobId).Scan(&status)
+		require.NoError(t, err)
+
+		return status
+	}
+
+	renewingJobId := insertProcessingJob(t)
+	silentJobId := insertProcessingJob(t)
+
+	stop := queue.keepLeaseAlive(ctx, renewingJobId, queue.RenewDocsLease)
+	time.Sleep(3 * queue.leaseTimeout)
+	queue.requeueStaleDocsGenerationJobs(ctx)
+	stop()
+
+	assert.Equal(t, registry.SdkGenerationJobStatusProcessing, getStatus(t, renewingJobId))
+	assert.Equal(t, registry.SdkGenerationJobStatusPending, getStatus(t, silentJobId))
+	require.Error(t, queue.RenewDocsLease(ctx, uuid.NewString()))
+}
+
+func TestGetLatestSdkJobStatuses(t *testing.T) {
+	queue, pool, cleanup := setupQueueTestEnvironment(t)
+	defer cleanup()
+
+	repositoryId := uuid.NewString()
+	otherRepositoryId := uuid.NewString()
+	base := time.Now().UTC().Add(-time.Hour)
+
+	seed := func(repoId string, sdk registry.SDK, status registry.SdkGenerationJobStatus, createdAt time.Time) {
+		_, err := pool.Exec(t.Context(),
+			`INSERT INTO sdk_generation_jobs (id, repository_id, commit_hash, sdk, status, created_at)
+			VALUES ($1, $2, 'abc123', $3, $4, $5)`,
+			uuid.NewString(), repoId, sdk, status, createdAt)
+		require.NoError(t, err)
+	}
+
+	// GO_PROTOBUF failed before and has since succeeded.
+	seed(repositoryId, registry.SdkGoProtobuf, registry.SdkGenerationJobStatusFailed, base)
+	seed(repositoryId, registry.SdkGoProtobuf, registry.SdkGenerationJobStatusCompleted, base.Add(time.Minute))
+	// GO_CONNECTRPC succeeded before and has since failed.
+	seed(repositoryId, registry.SdkGoConnectRpc, registry.SdkGenerationJobStatusCompleted, base)
+	seed(repositoryId, registry.SdkGoConnectRpc, registry.SdkGenerationJobStatusFailed, base.Add(time.Minute))
+	// JS_BUFBUILD_ES has a newer job still waiting.
+	seed(repositoryId, registry.SdkJsBufbuildEs, registry.SdkGenerationJobStatusFailed, base)
+	seed(repositoryId, registry.SdkJsBufbuildEs, registry.SdkGenerationJobStatusPending, base.Add(time.Minute))
+	// GO_GRPC is not asked for, and the other repository's jobs do not count.
+	seed(repositoryId, registry.SdkGoGrpc, registry.SdkGenerationJobStatusFailed, base)
+	seed(otherRepositoryId, registry.SdkGoProtobuf, registry.SdkGenerationJobStatusFailed, base.Add(time.Hour))
+
+	statuses, err := queue.GetLatestSdkJobStatuses(t.Context(), repositoryId, []registry.SDK{
+		registry.SdkGoProtobuf,
+		registry.SdkGoConnectRpc,
+		registry.SdkJsBufbuildEs,
+		registry.SdkJsConnectrpc,
+	})
+	require.NoError(t, err)
+	assert.ElementsMatch(t, []registry.SdkJobStatusDTO{
+		{Sdk: registry.SdkGoProtobuf, Status: registry.SdkGenerationJobStatusCompleted},
+		{Sdk: registry.SdkGoConnectRpc, Status: registry.SdkGenerationJobStatusFailed},
+		{Sdk: registry.SdkJsBufbuildEs, Status: registry.SdkGenerationJobStatusPending},
+	}, statuses)
+
+	statuses, err = queue.GetLatestSdkJobStatuses(t.Context(), repositoryId, nil)
+	require.NoError(t, err)
+	assert.Empty(t, statuses)
+}
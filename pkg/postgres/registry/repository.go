@@ -1,16 +1,27 @@
 package registry
 
 import (
+	"bytes"
 	"context"
 	"errors"
 	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
 	"time"
 
 	registryv1 "buf.build/gen/go/hasir/hasir/protocolbuffers/go/registry/v1"
 	"connectrpc.com/connect"
 	"github.com/exaring/otelpgx"
 	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing"
 	"github.com/go-git/go-git/v5/plumbing/object"
+	"github.com/go-git/go-git/v5/plumbing/storer"
 	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgconn"
 	"github.com/jackc/pgx/v5/pgxpool"
@@ -23,18 +34,26 @@ import (
 
 	"hasir-api/internal/registry"
 	"hasir-api/pkg/config"
+	"hasir-api/pkg/postgres"
+	"hasir-api/pkg/proto"
 )
 
 var (
 	ErrRepositoryAlreadyExists = connect.NewError(connect.CodeAlreadyExists, errors.New("repository already exists"))
 	ErrRepositoryNotFound      = connect.NewError(connect.CodeNotFound, errors.New("repository not found"))
+	ErrOrganizationNotFound    = connect.NewError(connect.CodeNotFound, errors.New("organization not found"))
 	ErrFailedAcquireConnection = connect.NewError(connect.CodeInternal, errors.New("failed to acquire connection"))
 	ErrUniqueViolationCode     = "23505"
+	ErrForeignKeyViolationCode = "23503"
 )
 
 type PgRepository struct {
-	connectionPool *pgxpool.Pool
-	tracer         trace.Tracer
+	connectionPool   *pgxpool.Pool
+	replicaPool      *pgxpool.Pool
+	tracer           trace.Tracer
+	queryTimeout     time.Duration
+	maxPreviewSize   int64
+	maxFileTreeDepth int
 }
 
 func NewPgRepository(
@@ -47,6 +66,10 @@ func NewPgRepository(
 		zap.L().Fatal("failed to parse database config", zap.Error(err))
 	}
 
+	if err := postgres.ApplySchema(pgConfig, cfg.PostgresConfig.Schema); err != nil {
+		zap.L().Fatal("failed to apply database schema", zap.Error(err))
+	}
+
 	if traceProvider != nil {
 		pgConfig.ConnConfig.Tracer = otelpgx.NewTracer(
 			otelpgx.WithTracerProvider(traceProvider),
@@ -85,9 +108,23 @@ func NewPgRepository(
 		tracer = noop.NewTracerProvider().Tracer("RepositoryPostgreSQLRepository")
 	}
 
+	replicaPool, err := postgres.NewReplicaPool(cfg.PostgresConfig, traceProvider)
+	if err != nil {
+		zap.L().Fatal("failed to connect read replica", zap.Error(err))
+	}
+
+	queryTimeout, err := cfg.PostgresConfig.GetQueryTimeout()
+	if err != nil {
+		zap.L().Fatal("failed to parse database query timeout", zap.Error(err))
+	}
+
 	return &PgRepository{
-		connectionPool: pgConnectionPool,
-		tracer:         tracer,
+		connectionPool:   pgConnectionPool,
+		replicaPool:      replicaPool,
+		tracer:           tracer,
+		queryTimeout:     queryTimeout,
+		maxPreviewSize:   cfg.Repository.GetMaxPreviewSize(),
+		maxFileTreeDepth: cfg.Repository.GetMaxFileTreeDepth(),
 	}
 }
 
@@ -95,10 +132,24 @@ func (r *PgRepository) GetConnectionPool() *pgxpool.Pool {
 	return r.connectionPool
 }
 
+func (r *PgRepository) readPool(ctx context.Context) *pgxpool.Pool {
+	return postgres.ReadPool(ctx, r.connectionPool, r.replicaPool)
+}
+
 func (r *PgRepository) GetTracer() trace.Tracer {
 	return r.tracer
 }
 
+func (r *PgRepository) withTx(ctx context.Context, fn func(pgx.Tx) error) error {
+	connection, err := r.connectionPool.Acquire(ctx)
+	if err != nil {
+		return postgres.QueryError(ctx, ErrFailedAcquireConnection)
+	}
+	defer connection.Release()
+
+	return postgres.WithTx(ctx, connection, fn)
+}
+
 func (r *PgRepository) CreateRepository(ctx context.Context, repo *registry.RepositoryDTO) error {
 	var span trace.Span
 	ctx, span = r.tracer.Start(ctx, "CreateRepository", trace.WithAttributes(attribute.KeyValue{
@@ -107,15 +158,18 @@ func (r *PgRepository) CreateRepository(ctx context.Context, repo *registry.Repo
 	}))
 	defer span.End()
 
+	ctx, cancel := postgres.WithQueryTimeout(ctx, r.queryTimeout)
+	defer cancel()
+
 	connection, err := r.connectionPool.Acquire(ctx)
 	if err != nil {
-		return ErrFailedAcquireConnection
+		return postgres.QueryError(ctx, ErrFailedAcquireConnection)
 	}
 	defer connection.Release()
 
 	now := time.Now().UTC()
-	sql := `INSERT INTO repositories (id, name, created_by, organization_id, path, visibility, created_at, updated_at)
-			VALUES ($1, $2, $3, $4, $5, $6, $7, $8)`
+	sql := `INSERT INTO repositories (id, name, created_by, organization_id, path, visibility, created_at, updated_at, forked_from)
+			VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)`
 	if _, err = connection.Exec(ctx, sql,
 		repo.Id,
 		repo.Name,
@@ -125,6 +179,7 @@ func (r *PgRepository) CreateRepository(ctx context.Context, repo *registry.Repo
 		repo.Visibility,
 		now,
 		&now,
+		repo.ForkedFrom,
 	); err != nil {
 		span.RecordError(err)
 
@@ -133,10 +188,10 @@ func (r *PgRepository) CreateRepository(ctx context.Context, repo *registry.Repo
 			return ErrRepositoryAlreadyExists
 		}
 
-		return connect.NewError(
+		return postgres.QueryError(ctx, connect.NewError(
 			connect.CodeInternal,
 			errors.New("failed to execute insert repository query"),
-		)
+		))
 	}
 
 	return nil
@@ -152,9 +207,12 @@ func (r *PgRepository) GetRepositoryByName(ctx context.Context, name string) (*r
 	))
 	defer span.End()
 
-	connection, err := r.connectionPool.Acquire(ctx)
+	ctx, cancel := postgres.WithQueryTimeout(ctx, r.queryTimeout)
+	defer cancel()
+
+	connection, err := r.readPool(ctx).Acquire(ctx)
 	if err != nil {
-		return nil, ErrFailedAcquireConnection
+		return nil, postgres.QueryError(ctx, ErrFailedAcquireConnection)
 	}
 	defer connection.Release()
 
@@ -164,7 +222,7 @@ func (r *PgRepository) GetRepositoryByName(ctx context.Context, name string) (*r
 	rows, err = connection.Query(ctx, sql, name)
 	if err != nil {
 		span.RecordError(err)
-		return nil, connect.NewError(connect.CodeInternal, errors.New("failed to query repository by name"))
+		return nil, postgres.QueryError(ctx, connect.NewError(connect.CodeInternal, errors.New("failed to query repository by name")))
 	}
 	defer rows.Close()
 
@@ -175,7 +233,7 @@ func (r *PgRepository) GetRepositoryByName(ctx context.Context, name string) (*r
 			return nil, ErrRepositoryNotFound
 		}
 
-		return nil, connect.NewError(connect.CodeInternal, errors.New("failed to collect row"))
+		return nil, postgres.QueryError(ctx, connect.NewError(connect.CodeInternal, errors.New("failed to collect row")))
 	}
 
 	return &repo, nil
@@ -195,9 +253,12 @@ func (r *PgRepository) GetRepositories(ctx context.Context, page, pageSize int)
 	))
 	defer span.End()
 
-	connection, err := r.connectionPool.Acquire(ctx)
+	ctx, cancel := postgres.WithQueryTimeout(ctx, r.queryTimeout)
+	defer cancel()
+
+	connection, err := r.readPool(ctx).Acquire(ctx)
 	if err != nil {
-		return nil, ErrFailedAcquireConnection
+		return nil, postgres.QueryError(ctx, ErrFailedAcquireConnection)
 	}
 	defer connection.Release()
 
@@ -208,14 +269,14 @@ func (r *PgRepository) GetRepositories(ctx context.Context, page, pageSize int)
 	rows, err = connection.Query(ctx, sql, pageSize, offset)
 	if err != nil {
 		span.RecordError(err)
-		return nil, connect.NewError(connect.CodeInternal, errors.New("failed to query repositories"))
+		return nil, postgres.QueryError(ctx, connect.NewError(connect.CodeInternal, errors.New("failed to query repositories")))
 	}
 	defer rows.Close()
 
 	repos, err := pgx.CollectRows[registry.RepositoryDTO](rows, pgx.RowToStructByName)
 	if err != nil {
 		span.RecordError(err)
-		return nil, connect.NewError(connect.CodeInternal, errors.New("failed to collect rows"))
+		return nil, postgres.QueryError(ctx, connect.NewError(connect.CodeInternal, errors.New("failed to collect rows")))
 	}
 
 	return &repos, nil
@@ -239,9 +300,12 @@ func (r *PgRepository) GetRepositoriesByUser(ctx context.Context, userId string,
 	))
 	defer span.End()
 
-	connection, err := r.connectionPool.Acquire(ctx)
+	ctx, cancel := postgres.WithQueryTimeout(ctx, r.queryTimeout)
+	defer cancel()
+
+	connection, err := r.readPool(ctx).Acquire(ctx)
 	if err != nil {
-		return nil, ErrFailedAcquireConnection
+		return nil, postgres.QueryError(ctx, ErrFailedAcquireConnection)
 	}
 	defer connection.Release()
 
@@ -257,14 +321,69 @@ func (r *PgRepository) GetRepositoriesByUser(ctx context.Context, userId string,
 	rows, err := connection.Query(ctx, sql, userId, pageSize, offset)
 	if err != nil {
 		span.RecordError(err)
-		return nil, connect.NewError(connect.CodeInternal, errors.New("failed to query repositories by user"))
+		return nil, postgres.QueryError(ctx, connect.NewError(connect.CodeInternal, errors.New("failed to query repositories by user")))
+	}
+	defer rows.Close()
+
+	repos, err := pgx.CollectRows[registry.RepositoryDTO](rows, pgx.RowToStructByName)
+	if err != nil {
+		span.RecordError(err)
+		return nil, postgres.QueryError(ctx, connect.NewError(connect.CodeInternal, errors.New("failed to collect repositories by user rows")))
+	}
+
+	return &repos, nil
+}
+
+// GetForks lists the live repositories forked from repoId that userId can
+// see: public ones, and private ones in organizations userId belongs to.
+func (r *PgRepository) GetForks(ctx context.Context, repoId, userId string) (*[]registry.RepositoryDTO, error) {
+	var span trace.Span
+	ctx, span = r.tracer.Start(ctx, "GetForks", trace.WithAttributes(
+		attribute.KeyValue{
+			Key:   "repoId",
+			Value: attribute.StringValue(repoId),
+		},
+		attribute.KeyValue{
+			Key:   "userId",
+			Value: attribute.StringValue(userId),
+		},
+	))
+	defer span.End()
+
+	ctx, cancel := postgres.WithQueryTimeout(ctx, r.queryTimeout)
+	defer cancel()
+
+	connection, err := r.readPool(ctx).Acquire(ctx)
+	if err != nil {
+		return nil, postgres.QueryError(ctx, ErrFailedAcquireConnection)
+	}
+	defer connection.Release()
+
+	sql := `
+		SELECT r.*
+		FROM repositories r
+		WHERE r.forked_from = $1
+			AND r.deleted_at IS NULL
+			AND (
+				r.visibility = 'public'
+				OR EXISTS (
+					SELECT 1 FROM organization_members m
+					WHERE m.organization_id = r.organization_id AND m.user_id = $2
+				)
+			)
+		ORDER BY r.created_at, r.id`
+
+	rows, err := connection.Query(ctx, sql, repoId, userId)
+	if err != nil {
+		span.RecordError(err)
+		return nil, postgres.QueryError(ctx, connect.NewError(connect.CodeInternal, errors.New("failed to query forks")))
 	}
 	defer rows.Close()
 
 	repos, err := pgx.CollectRows[registry.RepositoryDTO](rows, pgx.RowToStructByName)
 	if err != nil {
 		span.RecordError(err)
-		return nil, connect.NewError(connect.CodeInternal, errors.New("failed to collect repositories by user rows"))
+		return nil, postgres.QueryError(ctx, connect.NewError(connect.CodeInternal, errors.New("failed to collect fork rows")))
 	}
 
 	return &repos, nil
@@ -280,9 +399,12 @@ func (r *PgRepository) GetRepositoriesByOrganizationId(ctx context.Context, orga
 	))
 	defer span.End()
 
-	connection, err := r.connectionPool.Acquire(ctx)
+	ctx, cancel := postgres.WithQueryTimeout(ctx, r.queryTimeout)
+	defer cancel()
+
+	connection, err := r.readPool(ctx).Acquire(ctx)
 	if err != nil {
-		return nil, ErrFailedAcquireConnection
+		return nil, postgres.QueryError(ctx, ErrFailedAcquireConnection)
 	}
 	defer connection.Release()
 
@@ -291,14 +413,113 @@ func (r *PgRepository) GetRepositoriesByOrganizationId(ctx context.Context, orga
 	rows, err := connection.Query(ctx, sql, organizationId)
 	if err != nil {
 		span.RecordError(err)
-		return nil, connect.NewError(connect.CodeInternal, errors.New("failed to query repositories by organization id"))
+		return nil, postgres.QueryError(ctx, connect.NewError(connect.CodeInternal, errors.New("failed to query repositories by organization id")))
+	}
+	defer rows.Close()
+
+	repos, err := pgx.CollectRows[registry.RepositoryDTO](rows, pgx.RowToStructByName)
+	if err != nil {
+		span.RecordError(err)
+		return nil, postgres.QueryError(ctx, connect.NewError(connect.CodeInternal, errors.New("failed to collect rows")))
+	}
+
+	return &repos, nil
+}
+
+func (r *PgRepository) GetOrganizationRepositoriesCount(ctx context.Context, organizationId string, publicOnly bool) (int, error) {
+	var span trace.Span
+	ctx, span = r.tracer.Start(ctx, "GetOrganizationRepositoriesCount", trace.WithAttributes(
+		attribute.KeyValue{
+			Key:   "organizationId",
+			Value: attribute.StringValue(organizationId),
+		},
+		attribute.KeyValue{
+			Key:   "publicOnly",
+			Value: attribute.BoolValue(publicOnly),
+		},
+	))
+	defer span.End()
+
+	ctx, cancel := postgres.WithQueryTimeout(ctx, r.queryTimeout)
+	defer cancel()
+
+	connection, err := r.readPool(ctx).Acquire(ctx)
+	if err != nil {
+		return 0, postgres.QueryError(ctx, ErrFailedAcquireConnection)
+	}
+	defer connection.Release()
+
+	sql := `
+		SELECT COUNT(*)
+		FROM repositories
+		WHERE organization_id = $1
+		  AND deleted_at IS NULL
+		  AND (NOT $2 OR visibility = 'public')`
+
+	var count int
+	err = connection.QueryRow(ctx, sql, organizationId, publicOnly).Scan(&count)
+	if err != nil {
+		span.RecordError(err)
+		return 0, postgres.QueryError(ctx, connect.NewError(connect.CodeInternal, errors.New("failed to count organization repositories")))
+	}
+
+	return count, nil
+}
+
+// GetRecentOrganizationRepositories returns the organization's most recently
+// updated repositories, newest first.
+func (r *PgRepository) GetRecentOrganizationRepositories(
+	ctx context.Context,
+	organizationId string,
+	publicOnly bool,
+	limit int,
+) (*[]registry.RepositoryDTO, error) {
+	var span trace.Span
+	ctx, span = r.tracer.Start(ctx, "GetRecentOrganizationRepositories", trace.WithAttributes(
+		attribute.KeyValue{
+			Key:   "organizationId",
+			Value: attribute.StringValue(organizationId),
+		},
+		attribute.KeyValue{
+			Key:   "publicOnly",
+			Value: attribute.BoolValue(publicOnly),
+		},
+		attribute.KeyValue{
+			Key:   "limit",
+			Value: attribute.IntValue(limit),
+		},
+	))
+	defer span.End()
+
+	ctx, cancel := postgres.WithQueryTimeout(ctx, r.queryTimeout)
+	defer cancel()
+
+	connection, err := r.readPool(ctx).Acquire(ctx)
+	if err != nil {
+		return nil, postgres.QueryError(ctx, ErrFailedAcquireConnection)
+	}
+	defer connection.Release()
+
+	sql := `
+		SELECT *
+		FROM repositories
+		WHERE organization_id = $1
+		  AND deleted_at IS NULL
+		  AND (NOT $2 OR visibility = 'public')
+		ORDER BY updated_at DESC, id
+		LIMIT $3`
+
+	rows, err := connection.Query(ctx, sql, organizationId, publicOnly, limit)
+	if err != nil {
+		span.RecordError(err)
+		return nil, postgres.QueryError(ctx, connect.NewError(connect.CodeInternal, errors.New("failed to query recent organization repositories")))
 	}
 	defer rows.Close()
 
 	repos, err := pgx.CollectRows[registry.RepositoryDTO](rows, pgx.RowToStructByName)
 	if err != nil {
 		span.RecordError(err)
-		return nil, connect.NewError(connect.CodeInternal, errors.New("failed to collect rows"))
+		return nil, postgres.QueryError(ctx, connect.NewError(connect.CodeInternal, errors.New("failed to collect rows")))
 	}
 
 	return &repos, nil
@@ -309,9 +530,12 @@ func (r *PgRepository) GetRepositoriesCount(ctx context.Context) (int, error) {
 	ctx, span = r.tracer.Start(ctx, "GetRepositoriesCount")
 	defer span.End()
 
-	connection, err := r.connectionPool.Acquire(ctx)
+	ctx, cancel := postgres.WithQueryTimeout(ctx, r.queryTimeout)
+	defer cancel()
+
+	connection, err := r.readPool(ctx).Acquire(ctx)
 	if err != nil {
-		return 0, ErrFailedAcquireConnection
+		return 0, postgres.QueryError(ctx, ErrFailedAcquireConnection)
 	}
 	defer connection.Release()
 
@@ -321,7 +545,7 @@ func (r *PgRepository) GetRepositoriesCount(ctx context.Context) (int, error) {
 	err = connection.QueryRow(ctx, sql).Scan(&count)
 	if err != nil {
 		span.RecordError(err)
-		return 0, connect.NewError(connect.CodeInternal, errors.New("failed to count repositories"))
+		return 0, postgres.QueryError(ctx, connect.NewError(connect.CodeInternal, errors.New("failed to count repositories")))
 	}
 
 	return count, nil
@@ -337,9 +561,12 @@ func (r *PgRepository) GetRepositoriesByUserCount(ctx context.Context, userId st
 	))
 	defer span.End()
 
-	connection, err := r.connectionPool.Acquire(ctx)
+	ctx, cancel := postgres.WithQueryTimeout(ctx, r.queryTimeout)
+	defer cancel()
+
+	connection, err := r.readPool(ctx).Acquire(ctx)
 	if err != nil {
-		return 0, ErrFailedAcquireConnection
+		return 0, postgres.QueryError(ctx, ErrFailedAcquireConnection)
 	}
 	defer connection.Release()
 
@@ -353,7 +580,7 @@ func (r *PgRepository) GetRepositoriesByUserCount(ctx context.Context, userId st
 	err = connection.QueryRow(ctx, sql, userId).Scan(&count)
 	if err != nil {
 		span.RecordError(err)
-		return 0, connect.NewError(connect.CodeInternal, errors.New("failed to count repositories by user"))
+		return 0, postgres.QueryError(ctx, connect.NewError(connect.CodeInternal, errors.New("failed to count repositories by user")))
 	}
 
 	return count, nil
@@ -381,9 +608,12 @@ func (r *PgRepository) GetRepositoriesByUserAndOrganization(ctx context.Context,
 	))
 	defer span.End()
 
-	connection, err := r.connectionPool.Acquire(ctx)
+	ctx, cancel := postgres.WithQueryTimeout(ctx, r.queryTimeout)
+	defer cancel()
+
+	connection, err := r.readPool(ctx).Acquire(ctx)
 	if err != nil {
-		return nil, ErrFailedAcquireConnection
+		return nil, postgres.QueryError(ctx, ErrFailedAcquireConnection)
 	}
 	defer connection.Release()
 
@@ -399,14 +629,14 @@ func (r *PgRepository) GetRepositoriesByUserAndOrganization(ctx context.Context,
 	rows, err := connection.Query(ctx, sql, userId, organizationId, pageSize, offset)
 	if err != nil {
 		span.RecordError(err)
-		return nil, connect.NewError(connect.CodeInternal, errors.New("failed to query repositories by user and organization"))
+		return nil, postgres.QueryError(ctx, connect.NewError(connect.CodeInternal, errors.New("failed to query repositories by user and organization")))
 	}
 	defer rows.Close()
 
 	repos, err := pgx.CollectRows[registry.RepositoryDTO](rows, pgx.RowToStructByName)
 	if err != nil {
 		span.RecordError(err)
-		return nil, connect.NewError(connect.CodeInternal, errors.New("failed to collect repositories by user and organization rows"))
+		return nil, postgres.QueryError(ctx, connect.NewError(connect.CodeInternal, errors.New("failed to collect repositories by user and organization rows")))
 	}
 
 	return &repos, nil
@@ -426,9 +656,12 @@ func (r *PgRepository) GetRepositoriesByUserAndOrganizationCount(ctx context.Con
 	))
 	defer span.End()
 
-	connection, err := r.connectionPool.Acquire(ctx)
+	ctx, cancel := postgres.WithQueryTimeout(ctx, r.queryTimeout)
+	defer cancel()
+
+	connection, err := r.readPool(ctx).Acquire(ctx)
 	if err != nil {
-		return 0, ErrFailedAcquireConnection
+		return 0, postgres.QueryError(ctx, ErrFailedAcquireConnection)
 	}
 	defer connection.Release()
 
@@ -442,7 +675,7 @@ func (r *PgRepository) GetRepositoriesByUserAndOrganizationCount(ctx context.Con
 	err = connection.QueryRow(ctx, sql, userId, organizationId).Scan(&count)
 	if err != nil {
 		span.RecordError(err)
-		return 0, connect.NewError(connect.CodeInternal, errors.New("failed to count repositories by user and organization"))
+		return 0, postgres.QueryError(ctx, connect.NewError(connect.CodeInternal, errors.New("failed to count repositories by user and organization")))
 	}
 
 	return count, nil
@@ -458,9 +691,12 @@ func (r *PgRepository) GetRepositoryById(ctx context.Context, id string) (*regis
 	))
 	defer span.End()
 
-	connection, err := r.connectionPool.Acquire(ctx)
+	ctx, cancel := postgres.WithQueryTimeout(ctx, r.queryTimeout)
+	defer cancel()
+
+	connection, err := r.readPool(ctx).Acquire(ctx)
 	if err != nil {
-		return nil, ErrFailedAcquireConnection
+		return nil, postgres.QueryError(ctx, ErrFailedAcquireConnection)
 	}
 	defer connection.Release()
 
@@ -470,7 +706,7 @@ func (r *PgRepository) GetRepositoryById(ctx context.Context, id string) (*regis
 	rows, err = connection.Query(ctx, sql, id)
 	if err != nil {
 		span.RecordError(err)
-		return nil, connect.NewError(connect.CodeInternal, errors.New("failed to query repository by id"))
+		return nil, postgres.QueryError(ctx, connect.NewError(connect.CodeInternal, errors.New("failed to query repository by id")))
 	}
 	defer rows.Close()
 
@@ -481,56 +717,67 @@ func (r *PgRepository) GetRepositoryById(ctx context.Context, id string) (*regis
 			return nil, ErrRepositoryNotFound
 		}
 
-		return nil, connect.NewError(connect.CodeInternal, errors.New("failed to collect row"))
+		return nil, postgres.QueryError(ctx, connect.NewError(connect.CodeInternal, errors.New("failed to collect row")))
 	}
 
 	return &repo, nil
 }
 
-func (r *PgRepository) UpdateRepository(ctx context.Context, repo *registry.RepositoryDTO) error {
+// GetRepositoryByPath maps an on-disk repository path back to its record. The
+// path is cleaned first, so a trailing slash or "./" prefix still matches.
+func (r *PgRepository) GetRepositoryByPath(ctx context.Context, path string) (*registry.RepositoryDTO, error) {
+	path = filepath.Clean(path)
+
 	var span trace.Span
-	ctx, span = r.tracer.Start(ctx, "UpdateRepository", trace.WithAttributes(attribute.KeyValue{
-		Key:   "repository",
-		Value: attribute.StringValue(fmt.Sprintf("%+v", repo)),
-	}))
+	ctx, span = r.tracer.Start(ctx, "GetRepositoryByPath", trace.WithAttributes(
+		attribute.KeyValue{
+			Key:   "path",
+			Value: attribute.StringValue(path),
+		},
+	))
 	defer span.End()
 
-	connection, err := r.connectionPool.Acquire(ctx)
+	ctx, cancel := postgres.WithQueryTimeout(ctx, r.queryTimeout)
+	defer cancel()
+
+	connection, err := r.readPool(ctx).Acquire(ctx)
 	if err != nil {
-		return ErrFailedAcquireConnection
+		return nil, postgres.QueryError(ctx, ErrFailedAcquireConnection)
 	}
 	defer connection.Release()
 
-	now := time.Now().UTC()
-	sql := `UPDATE repositories
-			SET name = $1, updated_at = $2
-			WHERE id = $3 AND deleted_at IS NULL`
+	sql := "SELECT * FROM repositories WHERE path = $1 AND deleted_at IS NULL"
 
-	result, err := connection.Exec(ctx, sql, repo.Name, &now, repo.Id)
+	rows, err := connection.Query(ctx, sql, path)
 	if err != nil {
 		span.RecordError(err)
+		return nil, postgres.QueryError(ctx, connect.NewError(connect.CodeInternal, errors.New("failed to query repository by path")))
+	}
+	defer rows.Close()
 
-		var pgErr *pgconn.PgError
-		if errors.As(err, &pgErr) {
-			if pgErr.Code == ErrUniqueViolationCode {
-				return ErrRepositoryAlreadyExists
-			}
-			return connect.NewError(connect.CodeInternal, err)
+	repo, err := pgx.CollectOneRow(rows, pgx.RowToStructByName[registry.RepositoryDTO])
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, ErrRepositoryNotFound
 		}
 
-		return connect.NewError(connect.CodeInternal, errors.New("failed to execute update repository query"))
+		return nil, postgres.QueryError(ctx, connect.NewError(connect.CodeInternal, errors.New("failed to collect row")))
 	}
 
-	if result.RowsAffected() == 0 {
-		return ErrRepositoryNotFound
-	}
+	return &repo, nil
+}
 
-	return nil
+type repositoryWithSdkPreferenceRow struct {
+	registry.RepositoryDTO
+	Sdk       *registry.SDK `db:"sdk"`
+	SdkStatus *bool         `db:"sdk_status"`
 }
 
-func (r *PgRepository) DeleteRepository(ctx context.Context, id string) error {
+// GetRepositoryWithSdkPreferences joins the SDK preferences onto the
+// repository, giving one row per preference or a single row without one.
+func (r *PgRepository) GetRepositoryWithSdkPreferences(ctx context.Context, id string) (*registry.RepositoryDTO, []registry.SdkPreferencesDTO, error) {
 	var span trace.Span
-	ctx, span = r.tracer.Start(ctx, "DeleteRepository", trace.WithAttributes(
+	ctx, span = r.tracer.Start(ctx, "GetRepositoryWithSdkPreferences", trace.WithAttributes(
 		attribute.KeyValue{
 			Key:   "id",
 			Value: attribute.StringValue(id),
@@ -538,36 +785,57 @@ func (r *PgRepository) DeleteRepository(ctx context.Context, id string) error {
 	))
 	defer span.End()
 
-	connection, err := r.connectionPool.Acquire(ctx)
+	ctx, cancel := postgres.WithQueryTimeout(ctx, r.queryTimeout)
+	defer cancel()
+
+	connection, err := r.readPool(ctx).Acquire(ctx)
 	if err != nil {
-		return ErrFailedAcquireConnection
+		return nil, nil, postgres.QueryError(ctx, ErrFailedAcquireConnection)
 	}
 	defer connection.Release()
 
-	now := time.Now().UTC()
-	sql := `UPDATE repositories
-			SET deleted_at = $1
-			WHERE id = $2 AND deleted_at IS NULL`
+	sql := `SELECT r.id, r.name, r.created_by, r.organization_id, r.path, r.visibility, r.archived,
+			r.created_at, r.updated_at, r.deleted_at, r.forked_from, sp.sdk AS sdk, sp.status AS sdk_status
+		FROM repositories r
+		LEFT JOIN sdk_preferences sp ON sp.repository_id = r.id
+		WHERE r.id = $1 AND r.deleted_at IS NULL
+		ORDER BY sp.sdk`
 
-	result, err := connection.Exec(ctx, sql, &now, id)
+	rows, err := connection.Query(ctx, sql, id)
 	if err != nil {
 		span.RecordError(err)
-		return connect.NewError(
-			connect.CodeInternal,
-			errors.New("failed to execute delete repository query"),
-		)
+		return nil, nil, postgres.QueryError(ctx, connect.NewError(connect.CodeInternal, errors.New("failed to query repository with sdk preferences")))
 	}
 
-	if result.RowsAffected() == 0 {
-		return ErrRepositoryNotFound
+	collected, err := pgx.CollectRows(rows, pgx.RowToStructByName[repositoryWithSdkPreferenceRow])
+	if err != nil {
+		span.RecordError(err)
+		return nil, nil, postgres.QueryError(ctx, connect.NewError(connect.CodeInternal, errors.New("failed to collect rows")))
 	}
 
-	return nil
+	if len(collected) == 0 {
+		return nil, nil, ErrRepositoryNotFound
+	}
+
+	repo := collected[0].RepositoryDTO
+	var preferences []registry.SdkPreferencesDTO
+	for _, row := range collected {
+		if row.Sdk == nil {
+			continue
+		}
+		preferences = append(preferences, registry.SdkPreferencesDTO{
+			RepositoryId: repo.Id,
+			Sdk:          *row.Sdk,
+			Status:       *row.SdkStatus,
+		})
+	}
+
+	return &repo, preferences, nil
 }
 
-func (r *PgRepository) DeleteRepositoriesByOrganizationId(ctx context.Context, organizationId string) error {
+func (r *PgRepository) GetOrganizationVisibility(ctx context.Context, organizationId string) (proto.Visibility, error) {
 	var span trace.Span
-	ctx, span = r.tracer.Start(ctx, "DeleteRepositoriesByOrganizationId", trace.WithAttributes(
+	ctx, span = r.tracer.Start(ctx, "GetOrganizationVisibility", trace.WithAttributes(
 		attribute.KeyValue{
 			Key:   "organizationId",
 			Value: attribute.StringValue(organizationId),
@@ -575,208 +843,866 @@ func (r *PgRepository) DeleteRepositoriesByOrganizationId(ctx context.Context, o
 	))
 	defer span.End()
 
-	connection, err := r.connectionPool.Acquire(ctx)
+	ctx, cancel := postgres.WithQueryTimeout(ctx, r.queryTimeout)
+	defer cancel()
+
+	connection, err := r.readPool(ctx).Acquire(ctx)
 	if err != nil {
-		return ErrFailedAcquireConnection
+		return "", postgres.QueryError(ctx, ErrFailedAcquireConnection)
 	}
 	defer connection.Release()
 
-	now := time.Now().UTC()
-	sql := `UPDATE repositories
-			SET deleted_at = $1
-			WHERE organization_id = $2 AND deleted_at IS NULL`
+	sql := "SELECT visibility FROM organizations WHERE id = $1 AND deleted_at IS NULL"
 
-	_, err = connection.Exec(ctx, sql, &now, organizationId)
+	var visibility proto.Visibility
+	err = connection.QueryRow(ctx, sql, organizationId).Scan(&visibility)
 	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return "", ErrOrganizationNotFound
+		}
+
 		span.RecordError(err)
-		return connect.NewError(
-			connect.CodeInternal,
-			errors.New("failed to execute batch delete repositories query"),
-		)
+		return "", postgres.QueryError(ctx, connect.NewError(connect.CodeInternal, errors.New("failed to query organization visibility")))
 	}
 
-	return nil
+	return visibility, nil
 }
 
-func (r *PgRepository) UpdateSdkPreferences(
-	ctx context.Context,
-	repositoryId string,
-	preferences []registry.SdkPreferencesDTO,
-) error {
+func (r *PgRepository) GetOrganizationDefaultSdks(ctx context.Context, organizationId string) ([]registry.SDK, error) {
 	var span trace.Span
-	ctx, span = r.tracer.Start(ctx, "UpdateSdkPreferences", trace.WithAttributes(
+	ctx, span = r.tracer.Start(ctx, "GetOrganizationDefaultSdks", trace.WithAttributes(
 		attribute.KeyValue{
-			Key:   "repositoryId",
-			Value: attribute.StringValue(repositoryId),
-		},
-		attribute.KeyValue{
-			Key:   "preferences",
-			Value: attribute.StringValue(fmt.Sprintf("%+v", preferences)),
+			Key:   "organizationId",
+			Value: attribute.StringValue(organizationId),
 		},
 	))
 	defer span.End()
 
+	ctx, cancel := postgres.WithQueryTimeout(ctx, r.queryTimeout)
+	defer cancel()
+
 	connection, err := r.connectionPool.Acquire(ctx)
 	if err != nil {
-		return ErrFailedAcquireConnection
+		return nil, postgres.QueryError(ctx, ErrFailedAcquireConnection)
 	}
 	defer connection.Release()
 
-	tx, err := connection.Begin(ctx)
+	sql := `SELECT sdk FROM organization_sdk_defaults WHERE organization_id = $1 ORDER BY sdk`
+
+	rows, err := connection.Query(ctx, sql, organizationId)
 	if err != nil {
 		span.RecordError(err)
-		return connect.NewError(connect.CodeInternal, errors.New("failed to begin transaction"))
-	}
-	defer func() {
-		if err != nil {
-			_ = tx.Rollback(ctx)
-		}
-	}()
-
-	now := time.Now().UTC()
-	upsertSQL := `
-		INSERT INTO sdk_preferences (id, repository_id, sdk, status, created_at, updated_at)
-		VALUES ($1, $2, $3, $4, $5, $6)
-		ON CONFLICT (repository_id, sdk)
-		DO UPDATE SET
-			status = EXCLUDED.status,
-			updated_at = EXCLUDED.updated_at`
-
-	for _, pref := range preferences {
-		_, err = tx.Exec(ctx, upsertSQL,
-			pref.Id,
-			repositoryId,
-			string(pref.Sdk),
-			pref.Status,
-			now,
-			&now,
-		)
-		if err != nil {
-			span.RecordError(err)
-			return connect.NewError(
-				connect.CodeInternal,
-				errors.New("failed to upsert sdk preference"),
-			)
-		}
+		return nil, postgres.QueryError(ctx, connect.NewError(connect.CodeInternal, errors.New("failed to query organization default sdks")))
 	}
 
-	if err = tx.Commit(ctx); err != nil {
+	sdks, err := pgx.CollectRows(rows, pgx.RowTo[registry.SDK])
+	if err != nil {
 		span.RecordError(err)
-		return connect.NewError(connect.CodeInternal, errors.New("failed to commit transaction"))
+		return nil, postgres.QueryError(ctx, connect.NewError(connect.CodeInternal, errors.New("failed to collect organization default sdks")))
 	}
 
-	return nil
+	return sdks, nil
 }
 
-func (r *PgRepository) GetSdkPreferences(
-	ctx context.Context,
-	repositoryId string,
-) ([]registry.SdkPreferencesDTO, error) {
+func (r *PgRepository) GetUsersByEmails(ctx context.Context, emails []string) ([]registry.UserIdentity, error) {
 	var span trace.Span
-	ctx, span = r.tracer.Start(ctx, "GetSdkPreferences", trace.WithAttributes(
+	ctx, span = r.tracer.Start(ctx, "GetUsersByEmails", trace.WithAttributes(
 		attribute.KeyValue{
-			Key:   "repositoryId",
-			Value: attribute.StringValue(repositoryId),
+			Key:   "emailCount",
+			Value: attribute.IntValue(len(emails)),
 		},
 	))
 	defer span.End()
 
-	connection, err := r.connectionPool.Acquire(ctx)
+	if len(emails) == 0 {
+		return []registry.UserIdentity{}, nil
+	}
+
+	ctx, cancel := postgres.WithQueryTimeout(ctx, r.queryTimeout)
+	defer cancel()
+
+	connection, err := r.readPool(ctx).Acquire(ctx)
 	if err != nil {
-		return nil, ErrFailedAcquireConnection
+		return nil, postgres.QueryError(ctx, ErrFailedAcquireConnection)
 	}
 	defer connection.Release()
 
-	sql := "SELECT * FROM sdk_preferences WHERE repository_id = $1"
+	sql := "SELECT id, username, email FROM users WHERE LOWER(email) = ANY($1) AND deleted_at IS NULL"
 
-	rows, err := connection.Query(ctx, sql, repositoryId)
+	rows, err := connection.Query(ctx, sql, emails)
 	if err != nil {
 		span.RecordError(err)
-		return nil, connect.NewError(
-			connect.CodeInternal,
-			errors.New("failed to query sdk preferences"),
-		)
+		return nil, postgres.QueryError(ctx, connect.NewError(connect.CodeInternal, errors.New("failed to query users by email")))
 	}
-	defer rows.Close()
 
-	preferences, err := pgx.CollectRows[registry.SdkPreferencesDTO](rows, pgx.RowToStructByName)
+	users, err := pgx.CollectRows(rows, pgx.RowToStructByName[registry.UserIdentity])
 	if err != nil {
 		span.RecordError(err)
-		return nil, connect.NewError(
-			connect.CodeInternal,
-			errors.New("failed to collect sdk preferences rows"),
-		)
+		return nil, postgres.QueryError(ctx, connect.NewError(connect.CodeInternal, errors.New("failed to scan users")))
 	}
 
-	return preferences, nil
+	return users, nil
 }
 
-func (r *PgRepository) GetSdkPreferencesByRepositoryIds(
+func (r *PgRepository) UpdateRepository(ctx context.Context, repo *registry.RepositoryDTO) error {
+	var span trace.Span
+	ctx, span = r.tracer.Start(ctx, "UpdateRepository", trace.WithAttributes(attribute.KeyValue{
+		Key:   "repository",
+		Value: attribute.StringValue(fmt.Sprintf("%+v", repo)),
+	}))
+	defer span.End()
+
+	ctx, cancel := postgres.WithQueryTimeout(ctx, r.queryTimeout)
+	defer cancel()
+
+	connection, err := r.connectionPool.Acquire(ctx)
+	if err != nil {
+		return postgres.QueryError(ctx, ErrFailedAcquireConnection)
+	}
+	defer connection.Release()
+
+	now := time.Now().UTC()
+	sql := `UPDATE repositories
+			SET name = $1, updated_at = $2
+			WHERE id = $3 AND deleted_at IS NULL`
+
+	result, err := connection.Exec(ctx, sql, repo.Name, &now, repo.Id)
+	if err != nil {
+		span.RecordError(err)
+
+		var pgErr *pgconn.PgError
+		if errors.As(err, &pgErr) {
+			if pgErr.Code == ErrUniqueViolationCode {
+				return ErrRepositoryAlreadyExists
+			}
+			return postgres.QueryError(ctx, connect.NewError(connect.CodeInternal, err))
+		}
+
+		return postgres.QueryError(ctx, connect.NewError(connect.CodeInternal, errors.New("failed to execute update repository query")))
+	}
+
+	if result.RowsAffected() == 0 {
+		return ErrRepositoryNotFound
+	}
+
+	return nil
+}
+
+// TransferRepository moves a live repository to targetOrganizationId unless
+// the target already has a live repository of the same name. The search view
+// is refreshed by the trigger on repositories. Generated Go SDKs embed the
+// organization id in their module path, so the repository's SDK preferences
+// forget their last generated commit and the next push regenerates them.
+func (r *PgRepository) TransferRepository(ctx context.Context, id, targetOrganizationId string) error {
+	var span trace.Span
+	ctx, span = r.tracer.Start(ctx, "TransferRepository", trace.WithAttributes(
+		attribute.KeyValue{
+			Key:   "id",
+			Value: attribute.StringValue(id),
+		},
+		attribute.KeyValue{
+			Key:   "targetOrganizationId",
+			Value: attribute.StringValue(targetOrganizationId),
+		},
+	))
+	defer span.End()
+
+	ctx, cancel := postgres.WithQueryTimeout(ctx, r.queryTimeout)
+	defer cancel()
+
+	return r.withTx(ctx, func(tx pgx.Tx) error {
+		var name string
+		sql := `SELECT name FROM repositories WHERE id = $1 AND deleted_at IS NULL FOR UPDATE`
+		if err := tx.QueryRow(ctx, sql, id).Scan(&name); err != nil {
+			if errors.Is(err, pgx.ErrNoRows) {
+				return ErrRepositoryNotFound
+			}
+			span.RecordError(err)
+			return postgres.QueryError(ctx, connect.NewError(connect.CodeInternal, errors.New("failed to lock repository")))
+		}
+
+		var taken bool
+		sql = `SELECT EXISTS (
+			SELECT 1 FROM repositories
+			WHERE organization_id = $1 AND name = $2 AND id <> $3 AND deleted_at IS NULL
+		)`
+		if err := tx.QueryRow(ctx, sql, targetOrganizationId, name, id).Scan(&taken); err != nil {
+			span.RecordError(err)
+			return postgres.QueryError(ctx, connect.NewError(connect.CodeInternal, errors.New("failed to check repository name")))
+		}
+		if taken {
+			return ErrRepositoryAlreadyExists
+		}
+
+		sql = `UPDATE repositories SET organization_id = $1, updated_at = $2 WHERE id = $3`
+		if _, err := tx.Exec(ctx, sql, targetOrganizationId, time.Now().UTC(), id); err != nil {
+			span.RecordError(err)
+			var pgErr *pgconn.PgError
+			if errors.As(err, &pgErr) && pgErr.Code == ErrForeignKeyViolationCode {
+				return ErrOrganizationNotFound
+			}
+			return postgres.QueryError(ctx, connect.NewError(connect.CodeInternal, errors.New("failed to transfer repository")))
+		}
+
+		sql = `UPDATE sdk_preferences SET last_generated_commit = NULL WHERE repository_id = $1`
+		if _, err := tx.Exec(ctx, sql, id); err != nil {
+			span.RecordError(err)
+			return postgres.QueryError(ctx, connect.NewError(connect.CodeInternal, errors.New("failed to reset sdk preferences")))
+		}
+
+		return nil
+	})
+}
+
+func (r *PgRepository) SetRepositoryArchived(ctx context.Context, id string, archived bool) error {
+	var span trace.Span
+	ctx, span = r.tracer.Start(ctx, "SetRepositoryArchived", trace.WithAttributes(
+		attribute.KeyValue{
+			Key:   "id",
+			Value: attribute.StringValue(id),
+		},
+		attribute.KeyValue{
+			Key:   "archived",
+			Value: attribute.BoolValue(archived),
+		},
+	))
+	defer span.End()
+
+	ctx, cancel := postgres.WithQueryTimeout(ctx, r.queryTimeout)
+	defer cancel()
+
+	connection, err := r.connectionPool.Acquire(ctx)
+	if err != nil {
+		return postgres.QueryError(ctx, ErrFailedAcquireConnection)
+	}
+	defer connection.Release()
+
+	now := time.Now().UTC()
+	sql := `UPDATE repositories
+			SET archived = $1, updated_at = $2
+			WHERE id = $3 AND deleted_at IS NULL`
+
+	result, err := connection.Exec(ctx, sql, archived, &now, id)
+	if err != nil {
+		span.RecordError(err)
+		return postgres.QueryError(ctx, connect.NewError(
+			connect.CodeInternal,
+			errors.New("failed to execute set repository archived query"),
+		))
+	}
+
+	if result.RowsAffected() == 0 {
+		return ErrRepositoryNotFound
+	}
+
+	return nil
+}
+
+// SetRepositoriesVisibilityByOrganizationId updates the organization's
+// repositories in one statement, so the repositories_search_refresh trigger
+// refreshes the search index once for the whole batch.
+func (r *PgRepository) SetRepositoriesVisibilityByOrganizationId(
+	ctx context.Context,
+	organizationId string,
+	visibility proto.Visibility,
+) (int, error) {
+	var span trace.Span
+	ctx, span = r.tracer.Start(ctx, "SetRepositoriesVisibilityByOrganizationId", trace.WithAttributes(
+		attribute.KeyValue{
+			Key:   "organizationId",
+			Value: attribute.StringValue(organizationId),
+		},
+		attribute.KeyValue{
+			Key:   "visibility",
+			Value: attribute.StringValue(string(visibility)),
+		},
+	))
+	defer span.End()
+
+	ctx, cancel := postgres.WithQueryTimeout(ctx, r.queryTimeout)
+	defer cancel()
+
+	connection, err := r.connectionPool.Acquire(ctx)
+	if err != nil {
+		return 0, postgres.QueryError(ctx, ErrFailedAcquireConnection)
+	}
+	defer connection.Release()
+
+	now := time.Now().UTC()
+	sql := `UPDATE repositories
+			SET visibility = $1, updated_at = $2
+			WHERE organization_id = $3 AND deleted_at IS NULL AND visibility <> $1`
+
+	result, err := connection.Exec(ctx, sql, visibility, &now, organizationId)
+	if err != nil {
+		span.RecordError(err)
+		return 0, postgres.QueryError(ctx, connect.NewError(
+			connect.CodeInternal,
+			errors.New("failed to update organization repositories visibility"),
+		))
+	}
+
+	return int(result.RowsAffected()), nil
+}
+
+func (r *PgRepository) DeleteRepository(ctx context.Context, id string) error {
+	var span trace.Span
+	ctx, span = r.tracer.Start(ctx, "DeleteRepository", trace.WithAttributes(
+		attribute.KeyValue{
+			Key:   "id",
+			Value: attribute.StringValue(id),
+		},
+	))
+	defer span.End()
+
+	ctx, cancel := postgres.WithQueryTimeout(ctx, r.queryTimeout)
+	defer cancel()
+
+	connection, err := r.connectionPool.Acquire(ctx)
+	if err != nil {
+		return postgres.QueryError(ctx, ErrFailedAcquireConnection)
+	}
+	defer connection.Release()
+
+	now := time.Now().UTC()
+	sql := `UPDATE repositories
+			SET deleted_at = $1
+			WHERE id = $2 AND deleted_at IS NULL`
+
+	result, err := connection.Exec(ctx, sql, &now, id)
+	if err != nil {
+		span.RecordError(err)
+		return postgres.QueryError(ctx, connect.NewError(
+			connect.CodeInternal,
+			errors.New("failed to execute delete repository query"),
+		))
+	}
+
+	if result.RowsAffected() == 0 {
+		return ErrRepositoryNotFound
+	}
+
+	return nil
+}
+
+func (r *PgRepository) UpdateSdkPreferences(
+	ctx context.Context,
+	repositoryId string,
+	preferences []registry.SdkPreferencesDTO,
+) error {
+	var span trace.Span
+	ctx, span = r.tracer.Start(ctx, "UpdateSdkPreferences", trace.WithAttributes(
+		attribute.KeyValue{
+			Key:   "repositoryId",
+			Value: attribute.StringValue(repositoryId),
+		},
+		attribute.KeyValue{
+			Key:   "preferences",
+			Value: attribute.StringValue(fmt.Sprintf("%+v", preferences)),
+		},
+	))
+	defer span.End()
+
+	ctx, cancel := postgres.WithQueryTimeout(ctx, r.queryTimeout)
+	defer cancel()
+
+	return r.withTx(ctx, func(tx pgx.Tx) error {
+		now := time.Now().UTC()
+		upsertSQL := `
+		INSERT INTO sdk_preferences (id, repository_id, sdk, status, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6)
+		ON CONFLICT (repository_id, sdk)
+		DO UPDATE SET
+			status = EXCLUDED.status,
+			updated_at = EXCLUDED.updated_at`
+
+		for _, pref := range preferences {
+			_, err := tx.Exec(ctx, upsertSQL,
+				pref.Id,
+				repositoryId,
+				string(pref.Sdk),
+				pref.Status,
+				now,
+				&now,
+			)
+			if err != nil {
+				span.RecordError(err)
+				return postgres.QueryError(ctx, connect.NewError(
+					connect.CodeInternal,
+					errors.New("failed to upsert sdk preference"),
+				))
+			}
+		}
+
+		return nil
+	})
+}
+
+func (r *PgRepository) GetSdkPreferences(
+	ctx context.Context,
+	repositoryId string,
+) ([]registry.SdkPreferencesDTO, error) {
+	var span trace.Span
+	ctx, span = r.tracer.Start(ctx, "GetSdkPreferences", trace.WithAttributes(
+		attribute.KeyValue{
+			Key:   "repositoryId",
+			Value: attribute.StringValue(repositoryId),
+		},
+	))
+	defer span.End()
+
+	ctx, cancel := postgres.WithQueryTimeout(ctx, r.queryTimeout)
+	defer cancel()
+
+	connection, err := r.readPool(ctx).Acquire(ctx)
+	if err != nil {
+		return nil, postgres.QueryError(ctx, ErrFailedAcquireConnection)
+	}
+	defer connection.Release()
+
+	sql := "SELECT * FROM sdk_preferences WHERE repository_id = $1"
+
+	rows, err := connection.Query(ctx, sql, repositoryId)
+	if err != nil {
+		span.RecordError(err)
+		return nil, postgres.QueryError(ctx, connect.NewError(
+			connect.CodeInternal,
+			errors.New("failed to query sdk preferences"),
+		))
+	}
+	defer rows.Close()
+
+	preferences, err := pgx.CollectRows[registry.SdkPreferencesDTO](rows, pgx.RowToStructByName)
+	if err != nil {
+		span.RecordError(err)
+		return nil, postgres.QueryError(ctx, connect.NewError(
+			connect.CodeInternal,
+			errors.New("failed to collect sdk preferences rows"),
+		))
+	}
+
+	return preferences, nil
+}
+
+func (r *PgRepository) GetSdkPreferencesByRepositoryIds(
 	ctx context.Context,
 	repositoryIds []string,
 ) (map[string][]registry.SdkPreferencesDTO, error) {
 	var span trace.Span
-	ctx, span = r.tracer.Start(ctx, "GetSdkPreferencesByRepositoryIds", trace.WithAttributes(
+	ctx, span = r.tracer.Start(ctx, "GetSdkPreferencesByRepositoryIds", trace.WithAttributes(
+		attribute.KeyValue{
+			Key:   "repositoryCount",
+			Value: attribute.IntValue(len(repositoryIds)),
+		},
+	))
+	defer span.End()
+
+	if len(repositoryIds) == 0 {
+		return make(map[string][]registry.SdkPreferencesDTO), nil
+	}
+
+	ctx, cancel := postgres.WithQueryTimeout(ctx, r.queryTimeout)
+	defer cancel()
+
+	connection, err := r.readPool(ctx).Acquire(ctx)
+	if err != nil {
+		return nil, postgres.QueryError(ctx, ErrFailedAcquireConnection)
+	}
+	defer connection.Release()
+
+	sql := "SELECT * FROM sdk_preferences WHERE repository_id = ANY($1)"
+
+	rows, err := connection.Query(ctx, sql, repositoryIds)
+	if err != nil {
+		span.RecordError(err)
+		return nil, postgres.QueryError(ctx, connect.NewError(
+			connect.CodeInternal,
+			errors.New("failed to query sdk preferences"),
+		))
+	}
+	defer rows.Close()
+
+	preferences, err := pgx.CollectRows[registry.SdkPreferencesDTO](rows, pgx.RowToStructByName)
+	if err != nil {
+		span.RecordError(err)
+		return nil, postgres.QueryError(ctx, connect.NewError(
+			connect.CodeInternal,
+			errors.New("failed to collect sdk preferences rows"),
+		))
+	}
+
+	preferencesMap := make(map[string][]registry.SdkPreferencesDTO)
+	for _, pref := range preferences {
+		preferencesMap[pref.RepositoryId] = append(preferencesMap[pref.RepositoryId], pref)
+	}
+
+	return preferencesMap, nil
+}
+
+func (r *PgRepository) SetLastGeneratedCommit(ctx context.Context, repositoryId string, sdk registry.SDK, commitHash string) error {
+	var span trace.Span
+	ctx, span = r.tracer.Start(ctx, "SetLastGeneratedCommit", trace.WithAttributes(
+		attribute.KeyValue{
+			Key:   "repositoryId",
+			Value: attribute.StringValue(repositoryId),
+		},
+		attribute.KeyValue{
+			Key:   "sdk",
+			Value: attribute.StringValue(string(sdk)),
+		},
+		attribute.KeyValue{
+			Key:   "commitHash",
+			Value: attribute.StringValue(commitHash),
+		},
+	))
+	defer span.End()
+
+	ctx, cancel := postgres.WithQueryTimeout(ctx, r.queryTimeout)
+	defer cancel()
+
+	connection, err := r.connectionPool.Acquire(ctx)
+	if err != nil {
+		return postgres.QueryError(ctx, ErrFailedAcquireConnection)
+	}
+	defer connection.Release()
+
+	sql := `UPDATE sdk_preferences
+			SET last_generated_commit = $1
+			WHERE repository_id = $2 AND sdk = $3`
+
+	if _, err := connection.Exec(ctx, sql, commitHash, repositoryId, string(sdk)); err != nil {
+		span.RecordError(err)
+		return postgres.QueryError(ctx, connect.NewError(
+			connect.CodeInternal,
+			errors.New("failed to update last generated commit"),
+		))
+	}
+
+	return nil
+}
+
+// HasSdkInputChanges reports whether any file SDK generation reads differs
+// between the two commits. A commit that can no longer be found, for example
+// after a force push, counts as a change.
+func (r *PgRepository) HasSdkInputChanges(ctx context.Context, repoPath, fromCommit, toCommit string) (bool, error) {
+	var span trace.Span
+	ctx, span = r.tracer.Start(ctx, "HasSdkInputChanges", trace.WithAttributes(
+		attribute.KeyValue{
+			Key:   "repoPath",
+			Value: attribute.StringValue(repoPath),
+		},
+		attribute.KeyValue{
+			Key:   "fromCommit",
+			Value: attribute.StringValue(fromCommit),
+		},
+		attribute.KeyValue{
+			Key:   "toCommit",
+			Value: attribute.StringValue(toCommit),
+		},
+	))
+	defer span.End()
+
+	if fromCommit == toCommit {
+		return false, nil
+	}
+
+	repo, err := git.PlainOpen(repoPath)
+	if err != nil {
+		span.RecordError(err)
+		return false, connect.NewError(connect.CodeNotFound, errors.New("failed to open git repository"))
+	}
+
+	from, err := repo.CommitObject(plumbing.NewHash(fromCommit))
+	if errors.Is(err, plumbing.ErrObjectNotFound) {
+		return true, nil
+	}
+	if err != nil {
+		span.RecordError(err)
+		return false, connect.NewError(connect.CodeInternal, errors.New("failed to get commit object"))
+	}
+
+	to, err := repo.CommitObject(plumbing.NewHash(toCommit))
+	if err != nil {
+		span.RecordError(err)
+		return false, connect.NewError(connect.CodeNotFound, fmt.Errorf("commit %s not found", toCommit))
+	}
+
+	fromTree, err := from.Tree()
+	if err != nil {
+		span.RecordError(err)
+		return false, connect.NewError(connect.CodeInternal, errors.New("failed to get commit tree"))
+	}
+
+	toTree, err := to.Tree()
+	if err != nil {
+		span.RecordError(err)
+		return false, connect.NewError(connect.CodeInternal, errors.New("failed to get commit tree"))
+	}
+
+	changes, err := object.DiffTreeWithOptions(ctx, fromTree, toTree, nil)
+	if err != nil {
+		span.RecordError(err)
+		return false, connect.NewError(connect.CodeInternal, errors.New("failed to diff commits"))
+	}
+
+	for _, change := range changes {
+		if isSdkInput(change.From.Name) || isSdkInput(change.To.Name) {
+			return true, nil
+		}
+	}
+
+	return false, nil
+}
+
+// isSdkInput reports whether SDK generation reads the file: proto sources and
+// the buf configuration that decides how they are compiled.
+func isSdkInput(filePath string) bool {
+	if filePath == "" {
+		return false
+	}
+
+	switch path.Base(filePath) {
+	case "buf.yaml", "buf.gen.yaml", "buf.lock", "buf.work.yaml":
+		return true
+	}
+
+	return strings.HasSuffix(filePath, ".proto")
+}
+
+func (r *PgRepository) GetCommits(ctx context.Context, repoPath, ref string, page, pageSize int) ([]*registryv1.Commit, int, error) {
+	var span trace.Span
+	_, span = r.tracer.Start(ctx, "GetCommits", trace.WithAttributes(
+		attribute.KeyValue{
+			Key:   "repoPath",
+			Value: attribute.StringValue(repoPath),
+		},
+		attribute.KeyValue{
+			Key:   "ref",
+			Value: attribute.StringValue(ref),
+		},
+		attribute.KeyValue{
+			Key:   "page",
+			Value: attribute.IntValue(page),
+		},
+		attribute.KeyValue{
+			Key:   "pageSize",
+			Value: attribute.IntValue(pageSize),
+		},
+	))
+	defer span.End()
+
+	repo, err := git.PlainOpen(repoPath)
+	if err != nil {
+		span.RecordError(err)
+		return nil, 0, connect.NewError(connect.CodeNotFound, errors.New("failed to open git repository"))
+	}
+
+	head, err := resolveCommit(repo, ref)
+	if err != nil {
+		span.RecordError(err)
+		return nil, 0, err
+	}
+
+	commitIter, err := repo.Log(&git.LogOptions{From: head.Hash})
+	if err != nil {
+		span.RecordError(err)
+		return nil, 0, connect.NewError(connect.CodeInternal, errors.New("failed to get commit log"))
+	}
+	defer commitIter.Close()
+
+	totalCount := 0
+	err = commitIter.ForEach(func(c *object.Commit) error {
+		totalCount++
+		return nil
+	})
+	if err != nil {
+		span.RecordError(err)
+		return nil, 0, connect.NewError(connect.CodeInternal, errors.New("failed to count commits"))
+	}
+
+	commitIter, err = repo.Log(&git.LogOptions{From: head.Hash})
+	if err != nil {
+		span.RecordError(err)
+		return nil, 0, connect.NewError(connect.CodeInternal, errors.New("failed to get commit log"))
+	}
+	defer commitIter.Close()
+
+	offset := (page - 1) * pageSize
+	var commits []*registryv1.Commit
+	currentIndex := 0
+
+	var errStopIteration = errors.New("stop iteration")
+	err = commitIter.ForEach(func(c *object.Commit) error {
+		if currentIndex < offset {
+			currentIndex++
+			return nil
+		}
+		if len(commits) >= pageSize {
+			return errStopIteration
+		}
+
+		commit := &registryv1.Commit{
+			Id:      c.Hash.String(),
+			Message: c.Message,
+			User: &registryv1.Commit_User{
+				Id:       c.Author.Email,
+				Username: c.Author.Name,
+			},
+			CommitedAt: timestamppb.New(c.Author.When),
+		}
+		commits = append(commits, commit)
+		currentIndex++
+		return nil
+	})
+	if err != nil && err != errStopIteration {
+		span.RecordError(err)
+		return nil, 0, connect.NewError(connect.CodeInternal, errors.New("failed to iterate commits"))
+	}
+
+	return commits, totalCount, nil
+}
+
+func (r *PgRepository) GetRecentCommit(ctx context.Context, repoPath string) (*registryv1.Commit, error) {
+	var span trace.Span
+	_, span = r.tracer.Start(ctx, "GetRecentCommit", trace.WithAttributes(
 		attribute.KeyValue{
-			Key:   "repositoryCount",
-			Value: attribute.IntValue(len(repositoryIds)),
+			Key:   "repoPath",
+			Value: attribute.StringValue(repoPath),
 		},
 	))
 	defer span.End()
 
-	if len(repositoryIds) == 0 {
-		return make(map[string][]registry.SdkPreferencesDTO), nil
+	repo, err := git.PlainOpen(repoPath)
+	if err != nil {
+		span.RecordError(err)
+		return nil, connect.NewError(connect.CodeNotFound, errors.New("failed to open git repository"))
 	}
 
-	connection, err := r.connectionPool.Acquire(ctx)
+	commit, err := resolveCommit(repo, registry.HeadRef)
 	if err != nil {
-		return nil, ErrFailedAcquireConnection
+		span.RecordError(err)
+		return nil, err
 	}
-	defer connection.Release()
 
-	sql := "SELECT * FROM sdk_preferences WHERE repository_id = ANY($1)"
+	return &registryv1.Commit{
+		Id:      commit.Hash.String(),
+		Message: commit.Message,
+		User: &registryv1.Commit_User{
+			Id:       commit.Author.Email,
+			Username: commit.Author.Name,
+		},
+		CommitedAt: timestamppb.New(commit.Author.When),
+	}, nil
+}
 
-	rows, err := connection.Query(ctx, sql, repositoryIds)
+func (r *PgRepository) IsRepositoryEmpty(ctx context.Context, repoPath string) (bool, error) {
+	var span trace.Span
+	_, span = r.tracer.Start(ctx, "IsRepositoryEmpty", trace.WithAttributes(
+		attribute.KeyValue{
+			Key:   "repoPath",
+			Value: attribute.StringValue(repoPath),
+		},
+	))
+	defer span.End()
+
+	repo, err := git.PlainOpen(repoPath)
 	if err != nil {
 		span.RecordError(err)
-		return nil, connect.NewError(
-			connect.CodeInternal,
-			errors.New("failed to query sdk preferences"),
-		)
+		return false, connect.NewError(connect.CodeNotFound, errors.New("failed to open git repository"))
 	}
-	defer rows.Close()
 
-	preferences, err := pgx.CollectRows[registry.SdkPreferencesDTO](rows, pgx.RowToStructByName)
+	empty, err := hasNoRefs(repo)
 	if err != nil {
 		span.RecordError(err)
-		return nil, connect.NewError(
-			connect.CodeInternal,
-			errors.New("failed to collect sdk preferences rows"),
-		)
+		return false, connect.NewError(connect.CodeInternal, errors.New("failed to list refs"))
 	}
 
-	preferencesMap := make(map[string][]registry.SdkPreferencesDTO)
-	for _, pref := range preferences {
-		preferencesMap[pref.RepositoryId] = append(preferencesMap[pref.RepositoryId], pref)
+	return empty, nil
+}
+
+// hasNoRefs reports whether nothing has been pushed to repo. HEAD alone does
+// not count, since a fresh repository already has one pointing at an unborn
+// branch.
+func hasNoRefs(repo *git.Repository) (bool, error) {
+	refs, err := repo.References()
+	if err != nil {
+		return false, err
 	}
+	defer refs.Close()
 
-	return preferencesMap, nil
+	empty := true
+	err = refs.ForEach(func(ref *plumbing.Reference) error {
+		if ref.Name() == plumbing.HEAD {
+			return nil
+		}
+		empty = false
+		return storer.ErrStop
+	})
+
+	return empty, err
+}
+
+// resolveCommit returns the commit ref points at. An empty ref or HEAD
+// follows the repository's symbolic HEAD to its branch; any other ref names a
+// branch.
+func resolveCommit(repo *git.Repository, ref string) (*object.Commit, error) {
+	followHead := ref == "" || ref == registry.HeadRef
+
+	refName := plumbing.NewBranchReferenceName(ref)
+	if followHead {
+		head, err := repo.Storer.Reference(plumbing.HEAD)
+		if err != nil {
+			return nil, connect.NewError(connect.CodeNotFound, errors.New("failed to get repository HEAD"))
+		}
+
+		refName = head.Name()
+		if head.Type() == plumbing.SymbolicReference {
+			refName = head.Target()
+		}
+	}
+
+	resolved, err := repo.Reference(refName, true)
+	if errors.Is(err, plumbing.ErrReferenceNotFound) {
+		if followHead {
+			if empty, emptyErr := hasNoRefs(repo); emptyErr == nil && empty {
+				return nil, registry.ErrRepositoryEmpty
+			}
+			return nil, connect.NewError(connect.CodeFailedPrecondition, fmt.Errorf(
+				"HEAD points to branch %q, which has no commits yet", refName.Short(),
+			))
+		}
+		return nil, connect.NewError(connect.CodeNotFound, fmt.Errorf("branch %q not found", ref))
+	}
+	if err != nil {
+		return nil, connect.NewError(connect.CodeInternal, errors.New("failed to resolve ref"))
+	}
+
+	commit, err := repo.CommitObject(resolved.Hash())
+	if err != nil {
+		return nil, connect.NewError(connect.CodeInternal, errors.New("failed to get commit object"))
+	}
+
+	return commit, nil
 }
 
-func (r *PgRepository) GetCommits(ctx context.Context, repoPath string, page, pageSize int) ([]*registryv1.Commit, int, error) {
+func (r *PgRepository) GetFileTree(ctx context.Context, repoPath, ref string, subPath *string, page registry.FileTreePage) (*registry.FileTree, error) {
 	var span trace.Span
-	_, span = r.tracer.Start(ctx, "GetCommits", trace.WithAttributes(
+	_, span = r.tracer.Start(ctx, "GetFileTree", trace.WithAttributes(
 		attribute.KeyValue{
 			Key:   "repoPath",
 			Value: attribute.StringValue(repoPath),
 		},
 		attribute.KeyValue{
-			Key:   "page",
-			Value: attribute.IntValue(page),
+			Key:   "ref",
+			Value: attribute.StringValue(ref),
 		},
 		attribute.KeyValue{
-			Key:   "pageSize",
-			Value: attribute.IntValue(pageSize),
+			Key:   "offset",
+			Value: attribute.IntValue(page.Offset),
+		},
+		attribute.KeyValue{
+			Key:   "limit",
+			Value: attribute.IntValue(page.Limit),
 		},
 	))
 	defer span.End()
@@ -784,77 +1710,252 @@ func (r *PgRepository) GetCommits(ctx context.Context, repoPath string, page, pa
 	repo, err := git.PlainOpen(repoPath)
 	if err != nil {
 		span.RecordError(err)
-		return nil, 0, connect.NewError(connect.CodeNotFound, errors.New("failed to open git repository"))
+		return nil, connect.NewError(connect.CodeNotFound, errors.New("failed to open git repository"))
 	}
 
-	ref, err := repo.Head()
+	commit, err := resolveCommit(repo, ref)
 	if err != nil {
 		span.RecordError(err)
-		return nil, 0, connect.NewError(connect.CodeNotFound, errors.New("failed to get repository HEAD"))
+		return nil, err
 	}
 
-	commitIter, err := repo.Log(&git.LogOptions{From: ref.Hash()})
+	tree, err := commit.Tree()
 	if err != nil {
 		span.RecordError(err)
-		return nil, 0, connect.NewError(connect.CodeInternal, errors.New("failed to get commit log"))
+		return nil, connect.NewError(connect.CodeInternal, errors.New("failed to get tree object"))
 	}
-	defer commitIter.Close()
 
-	totalCount := 0
-	err = commitIter.ForEach(func(c *object.Commit) error {
-		totalCount++
-		return nil
+	var targetTree *object.Tree
+	targetPath := ""
+	if subPath != nil && *subPath != "" {
+		targetPath = *subPath
+		targetTree, err = tree.Tree(targetPath)
+		if err != nil {
+			span.RecordError(err)
+			return nil, connect.NewError(connect.CodeNotFound, errors.New("path not found in repository"))
+		}
+	} else {
+		targetTree = tree
+	}
+
+	entries := sortTreeEntries(targetTree.Entries)
+	totalCount := len(entries)
+
+	// A paginated listing stays one level deep so a page costs the same no
+	// matter how large the directories on it are; clients page into those
+	// by path.
+	paginated := page.Offset > 0 || page.Limit > 0
+	entries = entries[min(page.Offset, len(entries)):]
+	if page.Limit > 0 && page.Limit < len(entries) {
+		entries = entries[:page.Limit]
+	}
+
+	maxDepth := r.maxFileTreeDepth
+	if maxDepth <= 0 {
+		maxDepth = config.DefaultMaxFileTreeDepth
+	}
+
+	var truncatedPaths []string
+	var nodes []*registryv1.FileTreeNode
+	for _, entry := range entries {
+		nodePath := entry.Name
+		if targetPath != "" {
+			nodePath = targetPath + "/" + entry.Name
+		}
+
+		var nodeType registryv1.NodeType
+		var children []*registryv1.FileTreeNode
+
+		if entry.Mode.IsFile() {
+			nodeType = registryv1.NodeType_NODE_TYPE_FILE
+		} else {
+			nodeType = registryv1.NodeType_NODE_TYPE_DIRECTORY
+
+			if !paginated {
+				if maxDepth > 1 {
+					subTree, err := tree.Tree(nodePath)
+					if err == nil {
+						children = buildFileTreeNodes(tree, subTree, nodePath, maxDepth-1, &truncatedPaths)
+					}
+				} else {
+					truncatedPaths = append(truncatedPaths, nodePath)
+				}
+			}
+		}
+
+		node := &registryv1.FileTreeNode{
+			Name:     entry.Name,
+			Path:     nodePath,
+			Type:     nodeType,
+			Children: children,
+		}
+		nodes = append(nodes, node)
+	}
+
+	return &registry.FileTree{
+		GetFileTreeResponse: &registryv1.GetFileTreeResponse{
+			Nodes: nodes,
+		},
+		TotalCount:     totalCount,
+		TruncatedPaths: truncatedPaths,
+	}, nil
+}
+
+// sortTreeEntries orders directories before files, each group by name.
+func sortTreeEntries(entries []object.TreeEntry) []object.TreeEntry {
+	sorted := slices.Clone(entries)
+	slices.SortStableFunc(sorted, func(a, b object.TreeEntry) int {
+		if aIsDir, bIsDir := !a.Mode.IsFile(), !b.Mode.IsFile(); aIsDir != bIsDir {
+			if aIsDir {
+				return -1
+			}
+			return 1
+		}
+		return strings.Compare(a.Name, b.Name)
 	})
+
+	return sorted
+}
+
+// buildFileTreeNodes lists tree and descends into its directories until
+// depth levels have been listed. Directories whose children were cut off are
+// recorded in truncatedPaths.
+func buildFileTreeNodes(rootTree *object.Tree, tree *object.Tree, basePath string, depth int, truncatedPaths *[]string) []*registryv1.FileTreeNode {
+	var nodes []*registryv1.FileTreeNode
+
+	for _, entry := range sortTreeEntries(tree.Entries) {
+		nodePath := basePath + "/" + entry.Name
+
+		var nodeType registryv1.NodeType
+		var children []*registryv1.FileTreeNode
+
+		if entry.Mode.IsFile() {
+			nodeType = registryv1.NodeType_NODE_TYPE_FILE
+		} else {
+			nodeType = registryv1.NodeType_NODE_TYPE_DIRECTORY
+
+			if depth > 1 {
+				subTree, err := rootTree.Tree(nodePath)
+				if err == nil {
+					children = buildFileTreeNodes(rootTree, subTree, nodePath, depth-1, truncatedPaths)
+				}
+			} else {
+				*truncatedPaths = append(*truncatedPaths, nodePath)
+			}
+		}
+
+		node := &registryv1.FileTreeNode{
+			Name:     entry.Name,
+			Path:     nodePath,
+			Type:     nodeType,
+			Children: children,
+		}
+		nodes = append(nodes, node)
+	}
+
+	return nodes
+}
+
+func (r *PgRepository) GetFilePreview(ctx context.Context, repoPath, ref, filePath string) (*registry.FilePreview, error) {
+	var span trace.Span
+	_, span = r.tracer.Start(ctx, "GetFilePreview", trace.WithAttributes(
+		attribute.KeyValue{
+			Key:   "repoPath",
+			Value: attribute.StringValue(repoPath),
+		},
+		attribute.KeyValue{
+			Key:   "ref",
+			Value: attribute.StringValue(ref),
+		},
+		attribute.KeyValue{
+			Key:   "filePath",
+			Value: attribute.StringValue(filePath),
+		},
+	))
+	defer span.End()
+
+	repo, err := git.PlainOpen(repoPath)
 	if err != nil {
 		span.RecordError(err)
-		return nil, 0, connect.NewError(connect.CodeInternal, errors.New("failed to count commits"))
+		return nil, connect.NewError(connect.CodeNotFound, errors.New("failed to open git repository"))
 	}
 
-	commitIter, err = repo.Log(&git.LogOptions{From: ref.Hash()})
+	commit, err := resolveCommit(repo, ref)
 	if err != nil {
 		span.RecordError(err)
-		return nil, 0, connect.NewError(connect.CodeInternal, errors.New("failed to get commit log"))
+		return nil, err
 	}
-	defer commitIter.Close()
 
-	offset := (page - 1) * pageSize
-	var commits []*registryv1.Commit
-	currentIndex := 0
+	tree, err := commit.Tree()
+	if err != nil {
+		span.RecordError(err)
+		return nil, connect.NewError(connect.CodeInternal, errors.New("failed to get tree object"))
+	}
 
-	var errStopIteration = errors.New("stop iteration")
-	err = commitIter.ForEach(func(c *object.Commit) error {
-		if currentIndex < offset {
-			currentIndex++
-			return nil
-		}
-		if len(commits) >= pageSize {
-			return errStopIteration
-		}
+	file, err := tree.File(filePath)
+	if err != nil {
+		span.RecordError(err)
+		return nil, connect.NewError(connect.CodeNotFound, errors.New("file not found in repository"))
+	}
+
+	maxPreviewSize := r.maxPreviewSize
+	if maxPreviewSize <= 0 {
+		maxPreviewSize = config.DefaultMaxPreviewSize
+	}
+
+	reader, err := file.Reader()
+	if err != nil {
+		span.RecordError(err)
+		return nil, connect.NewError(connect.CodeInternal, errors.New("failed to read file content"))
+	}
+	defer func() {
+		_ = reader.Close()
+	}()
+
+	content, err := io.ReadAll(io.LimitReader(reader, maxPreviewSize))
+	if err != nil {
+		span.RecordError(err)
+		return nil, connect.NewError(connect.CodeInternal, errors.New("failed to read file content"))
+	}
+
+	preview := &registry.FilePreview{
+		GetFilePreviewResponse: &registryv1.GetFilePreviewResponse{
+			MimeType: detectMimeType(filePath, content),
+			Size:     file.Size,
+		},
+		Language: detectLanguage(filePath, content),
+	}
 
-		commit := &registryv1.Commit{
-			Id:      c.Hash.String(),
-			Message: c.Message,
-			User: &registryv1.Commit_User{
-				Id:       c.Author.Email,
-				Username: c.Author.Name,
-			},
-			CommitedAt: timestamppb.New(c.Author.When),
+	if isBinaryContent(content) {
+		preview.Binary = true
+		preview.Language = ""
+		if strings.HasPrefix(preview.MimeType, "text/") {
+			preview.MimeType = "application/octet-stream"
 		}
-		commits = append(commits, commit)
-		currentIndex++
-		return nil
-	})
-	if err != nil && err != errStopIteration {
-		span.RecordError(err)
-		return nil, 0, connect.NewError(connect.CodeInternal, errors.New("failed to iterate commits"))
+		return preview, nil
 	}
 
-	return commits, totalCount, nil
+	preview.Truncated = file.Size > int64(len(content))
+	preview.Content = strings.ToValidUTF8(string(content), "\uFFFD")
+
+	return preview, nil
 }
 
-func (r *PgRepository) GetRecentCommit(ctx context.Context, repoPath string) (*registryv1.Commit, error) {
+// binarySniffLength matches the prefix git inspects when deciding whether a
+// file is binary.
+const binarySniffLength = 8000
+
+func isBinaryContent(content []byte) bool {
+	if len(content) > binarySniffLength {
+		content = content[:binarySniffLength]
+	}
+
+	return bytes.IndexByte(content, 0) != -1
+}
+
+func (r *PgRepository) ListRefs(ctx context.Context, repoPath string) ([]registry.RefInfo, []registry.RefInfo, error) {
 	var span trace.Span
-	_, span = r.tracer.Start(ctx, "GetRecentCommit", trace.WithAttributes(
+	ctx, span = r.tracer.Start(ctx, "ListRefs", trace.WithAttributes(
 		attribute.KeyValue{
 			Key:   "repoPath",
 			Value: attribute.StringValue(repoPath),
@@ -862,38 +1963,46 @@ func (r *PgRepository) GetRecentCommit(ctx context.Context, repoPath string) (*r
 	))
 	defer span.End()
 
-	repo, err := git.PlainOpen(repoPath)
-	if err != nil {
+	if _, err := os.Stat(repoPath); err != nil {
 		span.RecordError(err)
-		return nil, connect.NewError(connect.CodeNotFound, errors.New("failed to open git repository"))
+		return nil, nil, connect.NewError(connect.CodeNotFound, errors.New("failed to open git repository"))
 	}
 
-	ref, err := repo.Head()
+	branches, err := forEachRef(ctx, repoPath, "refname", "refs/heads/")
 	if err != nil {
 		span.RecordError(err)
-		return nil, connect.NewError(connect.CodeNotFound, errors.New("failed to get repository HEAD"))
+		return nil, nil, connect.NewError(connect.CodeInternal, errors.New("failed to list branches"))
 	}
 
-	commit, err := repo.CommitObject(ref.Hash())
+	tags, err := forEachRef(ctx, repoPath, "-version:refname", "refs/tags/")
 	if err != nil {
 		span.RecordError(err)
-		return nil, connect.NewError(connect.CodeInternal, errors.New("failed to get commit object"))
+		return nil, nil, connect.NewError(connect.CodeInternal, errors.New("failed to list tags"))
+	}
+
+	// The branch HEAD points at is listed first so clients can treat it as the default.
+	headCmd := exec.CommandContext(ctx, "git", "symbolic-ref", "--quiet", "--short", "HEAD")
+	headCmd.Dir = repoPath
+	if out, err := headCmd.Output(); err == nil {
+		head := strings.TrimSpace(string(out))
+		for i, branch := range branches {
+			if branch.Name == head {
+				copy(branches[1:i+1], branches[:i])
+				branches[0] = branch
+				break
+			}
+		}
 	}
 
-	return &registryv1.Commit{
-		Id:      commit.Hash.String(),
-		Message: commit.Message,
-		User: &registryv1.Commit_User{
-			Id:       commit.Author.Email,
-			Username: commit.Author.Name,
-		},
-		CommitedAt: timestamppb.New(commit.Author.When),
-	}, nil
+	return branches, tags, nil
 }
 
-func (r *PgRepository) GetFileTree(ctx context.Context, repoPath string, subPath *string) (*registryv1.GetFileTreeResponse, error) {
+// GetBranchDivergence compares every branch with the one HEAD points at. A
+// repository without branches, or whose HEAD names a branch that does not
+// exist yet, has no default branch and an empty result.
+func (r *PgRepository) GetBranchDivergence(ctx context.Context, repoPath string) (string, []registry.BranchDivergence, error) {
 	var span trace.Span
-	_, span = r.tracer.Start(ctx, "GetFileTree", trace.WithAttributes(
+	ctx, span = r.tracer.Start(ctx, "GetBranchDivergence", trace.WithAttributes(
 		attribute.KeyValue{
 			Key:   "repoPath",
 			Value: attribute.StringValue(repoPath),
@@ -901,168 +2010,270 @@ func (r *PgRepository) GetFileTree(ctx context.Context, repoPath string, subPath
 	))
 	defer span.End()
 
-	repo, err := git.PlainOpen(repoPath)
-	if err != nil {
-		span.RecordError(err)
-		return nil, connect.NewError(connect.CodeNotFound, errors.New("failed to open git repository"))
-	}
-
-	ref, err := repo.Head()
-	if err != nil {
+	if _, err := os.Stat(repoPath); err != nil {
 		span.RecordError(err)
-		return nil, connect.NewError(connect.CodeNotFound, errors.New("failed to get repository HEAD"))
+		return "", nil, connect.NewError(connect.CodeNotFound, errors.New("failed to open git repository"))
 	}
 
-	commit, err := repo.CommitObject(ref.Hash())
+	branches, err := forEachRef(ctx, repoPath, "refname", "refs/heads/")
 	if err != nil {
 		span.RecordError(err)
-		return nil, connect.NewError(connect.CodeInternal, errors.New("failed to get commit object"))
+		return "", nil, connect.NewError(connect.CodeInternal, errors.New("failed to list branches"))
 	}
 
-	tree, err := commit.Tree()
+	headCmd := exec.CommandContext(ctx, "git", "symbolic-ref", "--quiet", "--short", "HEAD")
+	headCmd.Dir = repoPath
+	out, err := headCmd.Output()
 	if err != nil {
-		span.RecordError(err)
-		return nil, connect.NewError(connect.CodeInternal, errors.New("failed to get tree object"))
+		return "", []registry.BranchDivergence{}, nil
 	}
 
-	var targetTree *object.Tree
-	targetPath := ""
-	if subPath != nil && *subPath != "" {
-		targetPath = *subPath
-		targetTree, err = tree.Tree(targetPath)
-		if err != nil {
-			span.RecordError(err)
-			return nil, connect.NewError(connect.CodeNotFound, errors.New("path not found in repository"))
-		}
-	} else {
-		targetTree = tree
+	defaultBranch := strings.TrimSpace(string(out))
+	if !slices.ContainsFunc(branches, func(branch registry.RefInfo) bool { return branch.Name == defaultBranch }) {
+		return "", []registry.BranchDivergence{}, nil
 	}
 
-	var nodes []*registryv1.FileTreeNode
-	for _, entry := range targetTree.Entries {
-		nodePath := entry.Name
-		if targetPath != "" {
-			nodePath = targetPath + "/" + entry.Name
+	defaultRef := "refs/heads/" + defaultBranch
+	divergence := make([]registry.BranchDivergence, 0, len(branches))
+	for _, branch := range branches {
+		entry := registry.BranchDivergence{Name: branch.Name, Target: branch.Target}
+		if branch.Name != defaultBranch {
+			ahead, behind, related, err := compareRefs(ctx, repoPath, defaultRef, "refs/heads/"+branch.Name)
+			if err != nil {
+				span.RecordError(err)
+				return "", nil, connect.NewError(connect.CodeInternal, errors.New("failed to compare branches"))
+			}
+			entry.Ahead, entry.Behind, entry.Unrelated = ahead, behind, !related
 		}
 
-		var nodeType registryv1.NodeType
-		var children []*registryv1.FileTreeNode
+		divergence = append(divergence, entry)
+	}
 
-		if entry.Mode.IsFile() {
-			nodeType = registryv1.NodeType_NODE_TYPE_FILE
-		} else {
-			nodeType = registryv1.NodeType_NODE_TYPE_DIRECTORY
+	return defaultBranch, divergence, nil
+}
 
-			subTree, err := tree.Tree(nodePath)
-			if err == nil {
-				children = buildFileTreeNodes(tree, subTree, nodePath)
-			}
+// compareRefs counts the commits only head has (ahead) and only base has
+// (behind). related is false when the two have no common ancestor.
+func compareRefs(ctx context.Context, repoPath, base, head string) (ahead, behind int, related bool, err error) {
+	mergeBaseCmd := exec.CommandContext(ctx, "git", "merge-base", base, head)
+	mergeBaseCmd.Dir = repoPath
+	if err := mergeBaseCmd.Run(); err != nil {
+		var exitErr *exec.ExitError
+		if errors.As(err, &exitErr) && exitErr.ExitCode() == 1 {
+			return 0, 0, false, nil
 		}
+		return 0, 0, false, fmt.Errorf("git merge-base: %w", err)
+	}
 
-		node := &registryv1.FileTreeNode{
-			Name:     entry.Name,
-			Path:     nodePath,
-			Type:     nodeType,
-			Children: children,
-		}
-		nodes = append(nodes, node)
+	cmd := exec.CommandContext(ctx, "git", "rev-list", "--count", "--left-right", base+"..."+head)
+	cmd.Dir = repoPath
+	out, err := cmd.Output()
+	if err != nil {
+		return 0, 0, false, fmt.Errorf("git rev-list: %w", err)
 	}
 
-	return &registryv1.GetFileTreeResponse{
-		Nodes: nodes,
-	}, nil
-}
+	fields := strings.Fields(string(out))
+	if len(fields) != 2 {
+		return 0, 0, false, fmt.Errorf("unexpected rev-list output %q", out)
+	}
 
-func buildFileTreeNodes(rootTree *object.Tree, tree *object.Tree, basePath string) []*registryv1.FileTreeNode {
-	var nodes []*registryv1.FileTreeNode
+	if behind, err = strconv.Atoi(fields[0]); err != nil {
+		return 0, 0, false, fmt.Errorf("unexpected rev-list count %q: %w", fields[0], err)
+	}
+	if ahead, err = strconv.Atoi(fields[1]); err != nil {
+		return 0, 0, false, fmt.Errorf("unexpected rev-list count %q: %w", fields[1], err)
+	}
 
-	for _, entry := range tree.Entries {
-		nodePath := basePath + "/" + entry.Name
+	return ahead, behind, true, nil
+}
 
-		var nodeType registryv1.NodeType
-		var children []*registryv1.FileTreeNode
+// forEachRef lists refs under prefix with their target commit; annotated tags are
+// peeled so the target is always the commit rather than the tag object.
+func forEachRef(ctx context.Context, repoPath, sort, prefix string) ([]registry.RefInfo, error) {
+	cmd := exec.CommandContext(ctx, "git", "for-each-ref",
+		"--sort="+sort,
+		"--format=%(refname)%00%(objectname)%00%(*objectname)",
+		prefix,
+	)
+	cmd.Dir = repoPath
+
+	out, err := cmd.Output()
+	if err != nil {
+		return nil, fmt.Errorf("git for-each-ref %s: %w", prefix, err)
+	}
 
-		if entry.Mode.IsFile() {
-			nodeType = registryv1.NodeType_NODE_TYPE_FILE
-		} else {
-			nodeType = registryv1.NodeType_NODE_TYPE_DIRECTORY
+	refs := []registry.RefInfo{}
+	for _, line := range strings.Split(strings.TrimSpace(string(out)), "\n") {
+		if line == "" {
+			continue
+		}
 
-			subTree, err := rootTree.Tree(nodePath)
-			if err == nil {
-				children = buildFileTreeNodes(rootTree, subTree, nodePath)
-			}
+		fields := strings.Split(line, "\x00")
+		if len(fields) != 3 {
+			return nil, fmt.Errorf("unexpected for-each-ref output %q", line)
 		}
 
-		node := &registryv1.FileTreeNode{
-			Name:     entry.Name,
-			Path:     nodePath,
-			Type:     nodeType,
-			Children: children,
+		target := fields[1]
+		if fields[2] != "" {
+			target = fields[2]
 		}
-		nodes = append(nodes, node)
+
+		refs = append(refs, registry.RefInfo{
+			Name:   strings.TrimPrefix(fields[0], prefix),
+			Target: target,
+		})
 	}
 
-	return nodes
+	return refs, nil
 }
 
-func (r *PgRepository) GetFilePreview(ctx context.Context, repoPath, filePath string) (*registryv1.GetFilePreviewResponse, error) {
+func (r *PgRepository) GetContributors(ctx context.Context, repoPath string) ([]registry.Contributor, error) {
 	var span trace.Span
-	_, span = r.tracer.Start(ctx, "GetFilePreview", trace.WithAttributes(
+	ctx, span = r.tracer.Start(ctx, "GetContributors", trace.WithAttributes(
 		attribute.KeyValue{
 			Key:   "repoPath",
 			Value: attribute.StringValue(repoPath),
 		},
-		attribute.KeyValue{
-			Key:   "filePath",
-			Value: attribute.StringValue(filePath),
-		},
 	))
 	defer span.End()
 
-	repo, err := git.PlainOpen(repoPath)
-	if err != nil {
+	if _, err := os.Stat(repoPath); err != nil {
 		span.RecordError(err)
 		return nil, connect.NewError(connect.CodeNotFound, errors.New("failed to open git repository"))
 	}
 
-	ref, err := repo.Head()
-	if err != nil {
-		span.RecordError(err)
-		return nil, connect.NewError(connect.CodeNotFound, errors.New("failed to get repository HEAD"))
+	// An empty repository has no HEAD to summarize.
+	headCmd := exec.CommandContext(ctx, "git", "rev-parse", "--verify", "--quiet", "HEAD")
+	headCmd.Dir = repoPath
+	if err := headCmd.Run(); err != nil {
+		return []registry.Contributor{}, nil
 	}
 
-	commit, err := repo.CommitObject(ref.Hash())
+	// shortlog reads from stdin unless a revision is given, so HEAD is explicit.
+	cmd := exec.CommandContext(ctx, "git", "shortlog", "-sne", "HEAD")
+	cmd.Dir = repoPath
+
+	out, err := cmd.Output()
 	if err != nil {
 		span.RecordError(err)
-		return nil, connect.NewError(connect.CodeInternal, errors.New("failed to get commit object"))
+		return nil, connect.NewError(connect.CodeInternal, errors.New("failed to list contributors"))
 	}
 
-	tree, err := commit.Tree()
+	contributors, err := parseShortlog(string(out))
 	if err != nil {
 		span.RecordError(err)
-		return nil, connect.NewError(connect.CodeInternal, errors.New("failed to get tree object"))
+		return nil, connect.NewError(connect.CodeInternal, errors.New("failed to list contributors"))
 	}
 
-	file, err := tree.File(filePath)
-	if err != nil {
+	return contributors, nil
+}
+
+// GetCommitActivity counts the commits reachable from HEAD by committer date.
+// git filters coarsely with --since and --until; the exact [from, to) bounds
+// are applied while bucketing.
+func (r *PgRepository) GetCommitActivity(
+	ctx context.Context,
+	repoPath string,
+	bucket registry.CommitActivityBucket,
+	from, to time.Time,
+) ([]registry.CommitActivity, error) {
+	var span trace.Span
+	ctx, span = r.tracer.Start(ctx, "GetCommitActivity", trace.WithAttributes(
+		attribute.KeyValue{
+			Key:   "repoPath",
+			Value: attribute.StringValue(repoPath),
+		},
+		attribute.KeyValue{
+			Key:   "bucket",
+			Value: attribute.StringValue(string(bucket)),
+		},
+	))
+	defer span.End()
+
+	if _, err := os.Stat(repoPath); err != nil {
 		span.RecordError(err)
-		return nil, connect.NewError(connect.CodeNotFound, errors.New("file not found in repository"))
+		return nil, connect.NewError(connect.CodeNotFound, errors.New("failed to open git repository"))
+	}
+
+	activity := registry.NewCommitActivity(bucket, from, to)
+
+	headCmd := exec.CommandContext(ctx, "git", "rev-parse", "--verify", "--quiet", "HEAD")
+	headCmd.Dir = repoPath
+	if err := headCmd.Run(); err != nil {
+		return activity, nil
 	}
 
-	content, err := file.Contents()
+	cmd := exec.CommandContext(ctx, "git", "log",
+		"--since="+from.UTC().Format(time.RFC3339),
+		"--until="+to.UTC().Format(time.RFC3339),
+		"--format=%ct",
+		"HEAD")
+	cmd.Dir = repoPath
+
+	out, err := cmd.Output()
 	if err != nil {
 		span.RecordError(err)
-		return nil, connect.NewError(connect.CodeInternal, errors.New("failed to read file content"))
+		return nil, connect.NewError(connect.CodeInternal, errors.New("failed to read commit activity"))
 	}
 
-	mimeType := detectMimeType(filePath, []byte(content))
-	size := file.Size
+	for _, line := range strings.Fields(string(out)) {
+		seconds, err := strconv.ParseInt(line, 10, 64)
+		if err != nil {
+			span.RecordError(err)
+			return nil, connect.NewError(connect.CodeInternal, errors.New("failed to read commit activity"))
+		}
 
-	return &registryv1.GetFilePreviewResponse{
-		Content:  content,
-		MimeType: mimeType,
-		Size:     size,
-	}, nil
+		committedAt := time.Unix(seconds, 0).UTC()
+		if committedAt.Before(from) || !committedAt.Before(to) {
+			continue
+		}
+
+		start := bucket.Truncate(committedAt)
+		for i := range activity {
+			if activity[i].Start.Equal(start) {
+				activity[i].Count++
+				break
+			}
+		}
+	}
+
+	return activity, nil
+}
+
+// parseShortlog parses `git shortlog -sne` lines of the form
+// "   12\tName <email>".
+func parseShortlog(output string) ([]registry.Contributor, error) {
+	contributors := []registry.Contributor{}
+	for _, line := range strings.Split(output, "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" {
+			continue
+		}
+
+		count, ident, ok := strings.Cut(line, "\t")
+		if !ok {
+			return nil, fmt.Errorf("unexpected shortlog output %q", line)
+		}
+
+		commitCount, err := strconv.Atoi(strings.TrimSpace(count))
+		if err != nil {
+			return nil, fmt.Errorf("unexpected shortlog count %q: %w", count, err)
+		}
+
+		name, email := ident, ""
+		if start := strings.LastIndex(ident, "<"); start != -1 && strings.HasSuffix(ident, ">") {
+			name = strings.TrimSpace(ident[:start])
+			email = ident[start+1 : len(ident)-1]
+		}
+
+		contributors = append(contributors, registry.Contributor{
+			Name:        name,
+			Email:       email,
+			CommitCount: commitCount,
+		})
+	}
+
+	return contributors, nil
 }
 
 var mimeTypeMap = map[string]string{
@@ -1094,19 +2305,21 @@ var mimeTypeMap = map[string]string{
 	".Dockerfile": "text/x-dockerfile",
 }
 
-func detectMimeType(filePath string, content []byte) string {
-	ext := ""
+func fileExtension(filePath string) string {
 	for i := len(filePath) - 1; i >= 0; i-- {
 		if filePath[i] == '.' {
-			ext = filePath[i:]
-			break
+			return filePath[i:]
 		}
 		if filePath[i] == '/' {
 			break
 		}
 	}
 
-	if mimeType, ok := mimeTypeMap[ext]; ok {
+	return ""
+}
+
+func detectMimeType(filePath string, content []byte) string {
+	if mimeType, ok := mimeTypeMap[fileExtension(filePath)]; ok {
 		return mimeType
 	}
 
@@ -1115,3 +2328,221 @@ func detectMimeType(filePath string, content []byte) string {
 	}
 	return "text/plain"
 }
+
+var languageByExtension = map[string]string{
+	".proto":      "protobuf",
+	".go":         "go",
+	".py":         "python",
+	".js":         "javascript",
+	".mjs":        "javascript",
+	".cjs":        "javascript",
+	".jsx":        "jsx",
+	".ts":         "typescript",
+	".tsx":        "tsx",
+	".java":       "java",
+	".kt":         "kotlin",
+	".swift":      "swift",
+	".c":          "c",
+	".h":          "c",
+	".cpp":        "cpp",
+	".cc":         "cpp",
+	".cxx":        "cpp",
+	".hpp":        "cpp",
+	".cs":         "csharp",
+	".rs":         "rust",
+	".rb":         "ruby",
+	".php":        "php",
+	".sh":         "bash",
+	".bash":       "bash",
+	".zsh":        "bash",
+	".sql":        "sql",
+	".json":       "json",
+	".yaml":       "yaml",
+	".yml":        "yaml",
+	".toml":       "toml",
+	".xml":        "xml",
+	".html":       "html",
+	".htm":        "html",
+	".css":        "css",
+	".md":         "markdown",
+	".dockerfile": "dockerfile",
+	".mod":        "gomod",
+}
+
+var languageByFileName = map[string]string{
+	"Dockerfile":  "dockerfile",
+	"Makefile":    "makefile",
+	"GNUmakefile": "makefile",
+	"go.mod":      "gomod",
+}
+
+var languageByInterpreter = map[string]string{
+	"sh":      "bash",
+	"bash":    "bash",
+	"zsh":     "bash",
+	"python":  "python",
+	"python3": "python",
+	"node":    "javascript",
+	"ruby":    "ruby",
+	"perl":    "perl",
+	"php":     "php",
+}
+
+// detectLanguage returns a syntax highlighting hint for the editor, or an
+// empty string when the file type is unknown.
+func detectLanguage(filePath string, content []byte) string {
+	fileName := path.Base(filePath)
+	if language, ok := languageByFileName[fileName]; ok {
+		return language
+	}
+
+	if ext := fileExtension(filePath); ext != "" {
+		return languageByExtension[strings.ToLower(ext)]
+	}
+
+	return detectShebangLanguage(content)
+}
+
+func detectShebangLanguage(content []byte) string {
+	if !bytes.HasPrefix(content, []byte("#!")) {
+		return ""
+	}
+
+	line := content[2:]
+	if end := bytes.IndexByte(line, '\n'); end != -1 {
+		line = line[:end]
+	}
+
+	fields := strings.Fields(string(line))
+	if len(fields) == 0 {
+		return ""
+	}
+
+	interpreter := path.Base(fields[0])
+	if interpreter == "env" {
+		for _, field := range fields[1:] {
+			if !strings.HasPrefix(field, "-") {
+				interpreter = field
+				break
+			}
+		}
+	}
+
+	return languageByInterpreter[interpreter]
+}
+
+func (r *PgRepository) GetCommitRange(ctx context.Context, repoPath, baseRef, headRef string, limit int) (*registry.CommitRange, error) {
+	var span trace.Span
+	ctx, span = r.tracer.Start(ctx, "GetCommitRange", trace.WithAttributes(
+		attribute.KeyValue{
+			Key:   "repoPath",
+			Value: attribute.StringValue(repoPath),
+		},
+		attribute.KeyValue{
+			Key:   "baseRef",
+			Value: attribute.StringValue(baseRef),
+		},
+		attribute.KeyValue{
+			Key:   "headRef",
+			Value: attribute.StringValue(headRef),
+		},
+	))
+	defer span.End()
+
+	if _, err := os.Stat(repoPath); err != nil {
+		span.RecordError(err)
+		return nil, connect.NewError(connect.CodeNotFound, errors.New("failed to open git repository"))
+	}
+
+	base, err := resolveRevision(ctx, repoPath, baseRef)
+	if err != nil {
+		span.RecordError(err)
+		return nil, err
+	}
+	head, err := resolveRevision(ctx, repoPath, headRef)
+	if err != nil {
+		span.RecordError(err)
+		return nil, err
+	}
+
+	mergeBaseCmd := exec.CommandContext(ctx, "git", "merge-base", base, head)
+	mergeBaseCmd.Dir = repoPath
+	if err := mergeBaseCmd.Run(); err != nil {
+		var exitErr *exec.ExitError
+		if errors.As(err, &exitErr) && exitErr.ExitCode() == 1 {
+			return nil, registry.ErrUnrelatedRefs
+		}
+		span.RecordError(err)
+		return nil, connect.NewError(connect.CodeInternal, errors.New("failed to compare refs"))
+	}
+
+	// One commit past the limit tells a full range from a truncated one.
+	cmd := exec.CommandContext(ctx, "git", "log", "-z",
+		"--max-count="+strconv.Itoa(limit+1),
+		"--format=%H%n%an%n%ae%n%at%n%B",
+		base+".."+head)
+	cmd.Dir = repoPath
+
+	out, err := cmd.Output()
+	if err != nil {
+		span.RecordError(err)
+		return nil, connect.NewError(connect.CodeInternal, errors.New("failed to read commit range"))
+	}
+
+	commitRange := &registry.CommitRange{Commits: []*registryv1.Commit{}}
+	for record := range strings.SplitSeq(string(out), "\x00") {
+		if record == "" {
+			continue
+		}
+		if len(commitRange.Commits) == limit {
+			commitRange.Truncated = true
+			break
+		}
+
+		fields := strings.SplitN(record, "\n", 5)
+		if len(fields) < 4 {
+			return nil, connect.NewError(connect.CodeInternal, errors.New("failed to read commit range"))
+		}
+
+		seconds, err := strconv.ParseInt(fields[3], 10, 64)
+		if err != nil {
+			span.RecordError(err)
+			return nil, connect.NewError(connect.CodeInternal, errors.New("failed to read commit range"))
+		}
+
+		var message string
+		if len(fields) == 5 {
+			message = fields[4]
+		}
+
+		commitRange.Commits = append(commitRange.Commits, &registryv1.Commit{
+			Id:      fields[0],
+			Message: message,
+			User: &registryv1.Commit_User{
+				Id:       fields[2],
+				Username: fields[1],
+			},
+			CommitedAt: timestamppb.New(time.Unix(seconds, 0)),
+		})
+	}
+
+	return commitRange, nil
+}
+
+// resolveRevision returns the hash of the commit rev names. rev is passed
+// after --end-of-options so it is never read as a flag.
+func resolveRevision(ctx context.Context, repoPath, rev string) (string, error) {
+	cmd := exec.CommandContext(ctx, "git", "rev-parse", "--verify", "--quiet", "--end-of-options", rev+"^{commit}")
+	cmd.Dir = repoPath
+
+	out, err := cmd.Output()
+	if err != nil {
+		var exitErr *exec.ExitError
+		if errors.As(err, &exitErr) && exitErr.ExitCode() == 1 {
+			return "", connect.NewError(connect.CodeNotFound, fmt.Errorf("ref %q not found", rev))
+		}
+		return "", connect.NewError(connect.CodeInternal, errors.New("failed to resolve ref"))
+	}
+
+	return strings.TrimSpace(string(out)), nil
+}
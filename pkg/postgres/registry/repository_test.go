@@ -1,12 +1,17 @@
 package registry
 
 import (
+	"fmt"
 	"os"
+	"os/exec"
 	"path/filepath"
+	"strconv"
+	"strings"
 	"testing"
 	"time"
 
 	registryv1 "buf.build/gen/go/hasir/hasir/protocolbuffers/go/registry/v1"
+	"connectrpc.com/connect"
 	"github.com/go-git/go-git/v5"
 	"github.com/go-git/go-git/v5/plumbing/object"
 	"github.com/google/uuid"
@@ -18,6 +23,7 @@ import (
 	"go.opentelemetry.io/otel/trace/noop"
 
 	"hasir-api/internal/registry"
+	pgutil "hasir-api/pkg/postgres"
 	"hasir-api/pkg/proto"
 )
 
@@ -82,9 +88,11 @@ func createRepositoriesTable(t *testing.T, connString string) {
 		organization_id VARCHAR,
 		path VARCHAR NOT NULL,
 		visibility visibility NOT NULL DEFAULT 'private',
+		archived BOOLEAN NOT NULL DEFAULT FALSE,
 		created_at TIMESTAMP NOT NULL,
 		updated_at TIMESTAMP NOT NULL,
-		deleted_at TIMESTAMP
+		deleted_at TIMESTAMP,
+		forked_from VARCHAR
 	)`
 
 	_, err = conn.Exec(t.Context(), sql)
@@ -252,6 +260,38 @@ func TestPgRepository_CreateRepository(t *testing.T) {
 		assert.WithinDuration(t, time.Now().UTC(), dbCreatedAt, 5*time.Second)
 		assert.WithinDuration(t, time.Now().UTC(), dbUpdatedAt, 5*time.Second)
 	})
+
+	t.Run("fork keeps its parent", func(t *testing.T) {
+		container := setupPgContainer(t)
+		defer func() {
+			err := container.Terminate(t.Context())
+			require.NoError(t, err)
+		}()
+
+		connString, err := container.ConnectionString(t.Context())
+		require.NoError(t, err)
+
+		createRepositoriesTable(t, connString)
+
+		repo, pool := setupTestRepository(t, connString)
+		defer pool.Close()
+
+		parent := createTestRepository(t, "parent-repo-"+uuid.NewString())
+		require.NoError(t, repo.CreateRepository(t.Context(), parent))
+
+		fork := createTestRepository(t, "fork-repo-"+uuid.NewString())
+		fork.ForkedFrom = &parent.Id
+		require.NoError(t, repo.CreateRepository(t.Context(), fork))
+
+		found, err := repo.GetRepositoryById(t.Context(), fork.Id)
+		require.NoError(t, err)
+		require.NotNil(t, found.ForkedFrom)
+		assert.Equal(t, parent.Id, *found.ForkedFrom)
+
+		found, err = repo.GetRepositoryById(t.Context(), parent.Id)
+		require.NoError(t, err)
+		assert.Nil(t, found.ForkedFrom)
+	})
 }
 
 func TestPgRepository_GetRepositoryByName(t *testing.T) {
@@ -552,6 +592,80 @@ func TestPgRepository_GetRepositories(t *testing.T) {
 	})
 }
 
+func TestPgRepository_GetForks(t *testing.T) {
+	container := setupPgContainer(t)
+	defer func() {
+		err := container.Terminate(t.Context())
+		require.NoError(t, err)
+	}()
+
+	connString, err := container.ConnectionString(t.Context())
+	require.NoError(t, err)
+
+	createRepositoriesAndMembersTables(t, connString)
+
+	repo, pool := setupTestRepository(t, connString)
+	defer pool.Close()
+
+	userID := uuid.NewString()
+	memberOrgID := uuid.NewString()
+	otherOrgID := uuid.NewString()
+
+	parent := createTestRepository(t, "parent-"+uuid.NewString())
+	parent.Visibility = proto.VisibilityPublic
+	require.NoError(t, repo.CreateRepository(t.Context(), parent))
+
+	newFork := func(name, organizationId string, visibility proto.Visibility) *registry.RepositoryDTO {
+		fork := createTestRepository(t, name+"-"+uuid.NewString())
+		fork.OrganizationId = organizationId
+		fork.Visibility = visibility
+		fork.ForkedFrom = &parent.Id
+		require.NoError(t, repo.CreateRepository(t.Context(), fork))
+		return fork
+	}
+
+	publicFork := newFork("public-fork", otherOrgID, proto.VisibilityPublic)
+	memberFork := newFork("member-fork", memberOrgID, proto.VisibilityPrivate)
+	newFork("hidden-fork", otherOrgID, proto.VisibilityPrivate)
+	deletedFork := newFork("deleted-fork", memberOrgID, proto.VisibilityPublic)
+
+	unrelated := createTestRepository(t, "unrelated-"+uuid.NewString())
+	unrelated.Visibility = proto.VisibilityPublic
+	require.NoError(t, repo.CreateRepository(t.Context(), unrelated))
+
+	conn, err := pgx.Connect(t.Context(), connString)
+	require.NoError(t, err)
+	defer func() {
+		_ = conn.Close(t.Context())
+	}()
+
+	_, err = conn.Exec(t.Context(),
+		`INSERT INTO organization_members (id, organization_id, user_id, role, joined_at)
+		 VALUES ($1, $2, $3, 'reader', NOW())`,
+		uuid.NewString(), memberOrgID, userID,
+	)
+	require.NoError(t, err)
+
+	_, err = conn.Exec(t.Context(), "UPDATE repositories SET deleted_at = NOW() WHERE id = $1", deletedFork.Id)
+	require.NoError(t, err)
+
+	forks, err := repo.GetForks(t.Context(), parent.Id, userID)
+	require.NoError(t, err)
+
+	ids := make([]string, 0, len(*forks))
+	for _, fork := range *forks {
+		ids = append(ids, fork.Id)
+		require.NotNil(t, fork.ForkedFrom)
+		assert.Equal(t, parent.Id, *fork.ForkedFrom)
+	}
+	assert.ElementsMatch(t, []string{publicFork.Id, memberFork.Id}, ids)
+
+	forks, err = repo.GetForks(t.Context(), parent.Id, uuid.NewString())
+	require.NoError(t, err)
+	require.Len(t, *forks, 1)
+	assert.Equal(t, publicFork.Id, (*forks)[0].Id)
+}
+
 func TestPgRepository_GetRepositoriesByUser(t *testing.T) {
 	t.Run("returns repositories for a specific user via organization membership", func(t *testing.T) {
 		container := setupPgContainer(t)
@@ -609,6 +723,64 @@ func TestPgRepository_GetRepositoriesByUser(t *testing.T) {
 	})
 }
 
+func TestPgRepository_SetRepositoriesVisibilityByOrganizationId(t *testing.T) {
+	container := setupPgContainer(t)
+	defer func() {
+		err := container.Terminate(t.Context())
+		require.NoError(t, err)
+	}()
+
+	connString, err := container.ConnectionString(t.Context())
+	require.NoError(t, err)
+
+	createRepositoriesTable(t, connString)
+
+	repo, pool := setupTestRepository(t, connString)
+	defer pool.Close()
+
+	orgID := uuid.NewString()
+	var orgRepos []*registry.RepositoryDTO
+	for i, visibility := range []proto.Visibility{proto.VisibilityPrivate, proto.VisibilityPrivate, proto.VisibilityPublic} {
+		r := createTestRepository(t, "bulk-"+strconv.Itoa(i)+"-"+uuid.NewString())
+		r.OrganizationId = orgID
+		r.Visibility = visibility
+		require.NoError(t, repo.CreateRepository(t.Context(), r))
+		orgRepos = append(orgRepos, r)
+	}
+
+	deleted := createTestRepository(t, "bulk-deleted-"+uuid.NewString())
+	deleted.OrganizationId = orgID
+	require.NoError(t, repo.CreateRepository(t.Context(), deleted))
+	require.NoError(t, repo.DeleteRepository(t.Context(), deleted.Id))
+
+	other := createTestRepository(t, "bulk-other-"+uuid.NewString())
+	other.OrganizationId = uuid.NewString()
+	require.NoError(t, repo.CreateRepository(t.Context(), other))
+
+	updated, err := repo.SetRepositoriesVisibilityByOrganizationId(t.Context(), orgID, proto.VisibilityPublic)
+	require.NoError(t, err)
+	assert.Equal(t, 2, updated, "already public repositories are not counted")
+
+	for _, r := range orgRepos {
+		found, err := repo.GetRepositoryById(t.Context(), r.Id)
+		require.NoError(t, err)
+		assert.Equal(t, proto.VisibilityPublic, found.Visibility)
+	}
+
+	var deletedVisibility proto.Visibility
+	err = pool.QueryRow(t.Context(), "SELECT visibility FROM repositories WHERE id = $1", deleted.Id).Scan(&deletedVisibility)
+	require.NoError(t, err)
+	assert.Equal(t, proto.VisibilityPrivate, deletedVisibility)
+
+	found, err := repo.GetRepositoryById(t.Context(), other.Id)
+	require.NoError(t, err)
+	assert.Equal(t, proto.VisibilityPrivate, found.Visibility)
+
+	updated, err = repo.SetRepositoriesVisibilityByOrganizationId(t.Context(), orgID, proto.VisibilityPublic)
+	require.NoError(t, err)
+	assert.Zero(t, updated)
+}
+
 func TestPgRepository_DeleteRepository(t *testing.T) {
 	t.Run("successfully deletes repository by setting deleted_at", func(t *testing.T) {
 		container := setupPgContainer(t)
@@ -693,495 +865,343 @@ func TestPgRepository_DeleteRepository(t *testing.T) {
 	})
 }
 
-func TestPgRepository_DeleteRepositoriesByOrganizationId(t *testing.T) {
-	t.Run("successfully deletes all repositories for organization", func(t *testing.T) {
-		container := setupPgContainer(t)
-		defer func() {
-			err := container.Terminate(t.Context())
-			require.NoError(t, err)
-		}()
+func TestPgRepository_GetFilePreview(t *testing.T) {
+	t.Run("successfully retrieves file content", func(t *testing.T) {
+		repo, pool := setupTestRepository(t, "")
+		defer pool.Close()
 
-		connString, err := container.ConnectionString(t.Context())
+		content := "# Test Repository\n\nThis is a test file."
+		testRepoPath := setupTestGitRepository(t, map[string]string{
+			"README.md": content,
+		})
+
+		response, err := repo.GetFilePreview(t.Context(), testRepoPath, "", "README.md")
 		require.NoError(t, err)
+		require.NotNil(t, response)
+		assert.Equal(t, content, response.Content)
+		assert.Equal(t, "text/markdown", response.MimeType)
+		assert.Equal(t, int64(len(content)), response.Size)
+	})
 
-		createRepositoriesTable(t, connString)
-		repo, pool := setupTestRepository(t, connString)
+	t.Run("returns correct mime type for different file extensions", func(t *testing.T) {
+		repo, pool := setupTestRepository(t, "")
 		defer pool.Close()
 
-		orgID := uuid.NewString()
+		tests := []struct {
+			fileName     string
+			content      string
+			expectedMime string
+		}{
+			{"file.txt", "plain text", "text/plain"},
+			{"config.json", `{"key": "value"}`, "application/json"},
+			{"config.yaml", "key: value", "application/yaml"},
+			{"config.yml", "key: value", "application/yaml"},
+			{"style.css", "body { margin: 0; }", "text/css"},
+			{"script.js", "console.log('test');", "application/javascript"},
+			{"app.ts", "const x: string = 'test';", "application/typescript"},
+			{"main.go", "package main", "text/x-go"},
+			{"script.py", "print('hello')", "text/x-python"},
+			{"Main.java", "public class Main {}", "text/x-java"},
+			{"schema.proto", "syntax = \"proto3\";", "text/x-protobuf"},
+			{"query.sql", "SELECT * FROM users;", "application/sql"},
+			{"index.html", "<html></html>", "text/html"},
+		}
 
-		repo1 := createTestRepository(t, "repo1")
-		repo1.OrganizationId = orgID
-		err = repo.CreateRepository(t.Context(), repo1)
-		require.NoError(t, err)
+		for _, tt := range tests {
+			testRepoPath := setupTestGitRepository(t, map[string]string{
+				tt.fileName: tt.content,
+			})
 
-		repo2 := createTestRepository(t, "repo2")
-		repo2.OrganizationId = orgID
-		err = repo.CreateRepository(t.Context(), repo2)
-		require.NoError(t, err)
+			response, err := repo.GetFilePreview(t.Context(), testRepoPath, "", tt.fileName)
+			require.NoError(t, err, "failed for file: %s", tt.fileName)
+			assert.Equal(t, tt.expectedMime, response.MimeType, "incorrect mime type for: %s", tt.fileName)
+			assert.Equal(t, tt.content, response.Content, "incorrect content for: %s", tt.fileName)
+		}
+	})
 
-		repo3 := createTestRepository(t, "repo3")
-		repo3.OrganizationId = orgID
-		err = repo.CreateRepository(t.Context(), repo3)
-		require.NoError(t, err)
+	t.Run("returns correct size for file", func(t *testing.T) {
+		repo, pool := setupTestRepository(t, "")
+		defer pool.Close()
 
-		err = repo.DeleteRepositoriesByOrganizationId(t.Context(), orgID)
-		assert.NoError(t, err)
+		content := "This is a test file with specific length."
+		testRepoPath := setupTestGitRepository(t, map[string]string{
+			"test.txt": content,
+		})
 
-		conn, err := pgx.Connect(t.Context(), connString)
+		response, err := repo.GetFilePreview(t.Context(), testRepoPath, "", "test.txt")
 		require.NoError(t, err)
-		defer func() {
-			_ = conn.Close(t.Context())
-		}()
+		assert.Equal(t, int64(len(content)), response.Size)
+	})
 
-		var count int
-		err = conn.QueryRow(t.Context(),
-			"SELECT COUNT(*) FROM repositories WHERE organization_id = $1 AND deleted_at IS NOT NULL",
-			orgID,
-		).Scan(&count)
-		require.NoError(t, err)
-		assert.Equal(t, 3, count, "all 3 repositories should be marked as deleted")
+	t.Run("handles file in subdirectory", func(t *testing.T) {
+		repo, pool := setupTestRepository(t, "")
+		defer pool.Close()
 
-		err = conn.QueryRow(t.Context(),
-			"SELECT COUNT(*) FROM repositories WHERE organization_id = $1 AND deleted_at IS NULL",
-			orgID,
-		).Scan(&count)
+		testRepoPath := setupTestGitRepository(t, map[string]string{
+			"src/main.go": "package main\n\nfunc main() {}",
+		})
+
+		response, err := repo.GetFilePreview(t.Context(), testRepoPath, "", "src/main.go")
 		require.NoError(t, err)
-		assert.Equal(t, 0, count, "no repositories should remain active")
+		require.NotNil(t, response)
+		assert.Equal(t, "package main\n\nfunc main() {}", response.Content)
+		assert.Equal(t, "text/x-go", response.MimeType)
 	})
 
-	t.Run("does not delete repositories from other organizations", func(t *testing.T) {
-		container := setupPgContainer(t)
-		defer func() {
-			err := container.Terminate(t.Context())
-			require.NoError(t, err)
-		}()
+	t.Run("handles deeply nested file", func(t *testing.T) {
+		repo, pool := setupTestRepository(t, "")
+		defer pool.Close()
 
-		connString, err := container.ConnectionString(t.Context())
+		testRepoPath := setupTestGitRepository(t, map[string]string{
+			"src/internal/handlers/auth/login.go": "package auth",
+		})
+
+		response, err := repo.GetFilePreview(t.Context(), testRepoPath, "", "src/internal/handlers/auth/login.go")
 		require.NoError(t, err)
+		require.NotNil(t, response)
+		assert.Equal(t, "package auth", response.Content)
+		assert.Equal(t, "text/x-go", response.MimeType)
+	})
 
-		createRepositoriesTable(t, connString)
-		repo, pool := setupTestRepository(t, connString)
+	t.Run("returns error for non-existent file", func(t *testing.T) {
+		repo, pool := setupTestRepository(t, "")
 		defer pool.Close()
 
-		orgID1 := uuid.NewString()
-		orgID2 := uuid.NewString()
-
-		repo1 := createTestRepository(t, "org1-repo1")
-		repo1.OrganizationId = orgID1
-		err = repo.CreateRepository(t.Context(), repo1)
-		require.NoError(t, err)
+		testRepoPath := setupTestGitRepository(t, map[string]string{
+			"README.md": "# Test",
+		})
 
-		repo2 := createTestRepository(t, "org1-repo2")
-		repo2.OrganizationId = orgID1
-		err = repo.CreateRepository(t.Context(), repo2)
-		require.NoError(t, err)
+		_, err := repo.GetFilePreview(t.Context(), testRepoPath, "", "non-existent.txt")
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "file not found")
+	})
 
-		repo3 := createTestRepository(t, "org2-repo1")
-		repo3.OrganizationId = orgID2
-		err = repo.CreateRepository(t.Context(), repo3)
-		require.NoError(t, err)
+	t.Run("returns error for invalid repository path", func(t *testing.T) {
+		repo, pool := setupTestRepository(t, "")
+		defer pool.Close()
 
-		err = repo.DeleteRepositoriesByOrganizationId(t.Context(), orgID1)
-		assert.NoError(t, err)
+		_, err := repo.GetFilePreview(t.Context(), "/invalid/path/to/repo", "", "README.md")
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "failed to open git repository")
+	})
 
-		conn, err := pgx.Connect(t.Context(), connString)
-		require.NoError(t, err)
-		defer func() {
-			_ = conn.Close(t.Context())
-		}()
+	t.Run("handles empty file", func(t *testing.T) {
+		repo, pool := setupTestRepository(t, "")
+		defer pool.Close()
 
-		var deletedCount int
-		err = conn.QueryRow(t.Context(),
-			"SELECT COUNT(*) FROM repositories WHERE organization_id = $1 AND deleted_at IS NOT NULL",
-			orgID1,
-		).Scan(&deletedCount)
-		require.NoError(t, err)
-		assert.Equal(t, 2, deletedCount, "both org1 repositories should be deleted")
+		testRepoPath := setupTestGitRepository(t, map[string]string{
+			"empty.txt": "",
+		})
 
-		var activeCount int
-		err = conn.QueryRow(t.Context(),
-			"SELECT COUNT(*) FROM repositories WHERE organization_id = $1 AND deleted_at IS NULL",
-			orgID2,
-		).Scan(&activeCount)
+		response, err := repo.GetFilePreview(t.Context(), testRepoPath, "", "empty.txt")
 		require.NoError(t, err)
-		assert.Equal(t, 1, activeCount, "org2 repository should still be active")
+		require.NotNil(t, response)
+		assert.Equal(t, "", response.Content)
+		assert.Equal(t, int64(0), response.Size)
+		assert.Equal(t, "text/plain", response.MimeType)
 	})
 
-	t.Run("handles empty organization gracefully", func(t *testing.T) {
-		container := setupPgContainer(t)
-		defer func() {
-			err := container.Terminate(t.Context())
-			require.NoError(t, err)
-		}()
+	t.Run("handles file without extension", func(t *testing.T) {
+		repo, pool := setupTestRepository(t, "")
+		defer pool.Close()
 
-		connString, err := container.ConnectionString(t.Context())
+		testRepoPath := setupTestGitRepository(t, map[string]string{
+			"Makefile": "all:\n\tgo build",
+		})
+
+		response, err := repo.GetFilePreview(t.Context(), testRepoPath, "", "Makefile")
 		require.NoError(t, err)
+		require.NotNil(t, response)
+		assert.Equal(t, "all:\n\tgo build", response.Content)
+		assert.Equal(t, "text/plain", response.MimeType)
+	})
 
-		createRepositoriesTable(t, connString)
-		repo, pool := setupTestRepository(t, connString)
+	t.Run("handles dockerfile extension", func(t *testing.T) {
+		repo, pool := setupTestRepository(t, "")
 		defer pool.Close()
 
-		nonExistentOrgID := uuid.NewString()
-		err = repo.DeleteRepositoriesByOrganizationId(t.Context(), nonExistentOrgID)
-		assert.NoError(t, err, "deleting from empty organization should not error")
-	})
+		testRepoPath := setupTestGitRepository(t, map[string]string{
+			"app.dockerfile": "FROM node:18",
+		})
 
-	t.Run("does not re-delete already deleted repositories", func(t *testing.T) {
-		container := setupPgContainer(t)
-		defer func() {
-			err := container.Terminate(t.Context())
-			require.NoError(t, err)
-		}()
-
-		connString, err := container.ConnectionString(t.Context())
-		require.NoError(t, err)
-
-		createRepositoriesTable(t, connString)
-		repo, pool := setupTestRepository(t, connString)
-		defer pool.Close()
-
-		orgID := uuid.NewString()
-
-		repo1 := createTestRepository(t, "repo1")
-		repo1.OrganizationId = orgID
-		err = repo.CreateRepository(t.Context(), repo1)
-		require.NoError(t, err)
-
-		repo2 := createTestRepository(t, "repo2")
-		repo2.OrganizationId = orgID
-		err = repo.CreateRepository(t.Context(), repo2)
-		require.NoError(t, err)
-
-		err = repo.DeleteRepositoriesByOrganizationId(t.Context(), orgID)
-		require.NoError(t, err)
-
-		conn, err := pgx.Connect(t.Context(), connString)
-		require.NoError(t, err)
-		defer func() {
-			_ = conn.Close(t.Context())
-		}()
-
-		var firstDeletedAt time.Time
-		err = conn.QueryRow(t.Context(),
-			"SELECT deleted_at FROM repositories WHERE id = $1",
-			repo1.Id,
-		).Scan(&firstDeletedAt)
-		require.NoError(t, err)
-
-		time.Sleep(100 * time.Millisecond)
-
-		err = repo.DeleteRepositoriesByOrganizationId(t.Context(), orgID)
-		assert.NoError(t, err, "second delete should succeed but not change anything")
-
-		var secondDeletedAt time.Time
-		err = conn.QueryRow(t.Context(),
-			"SELECT deleted_at FROM repositories WHERE id = $1",
-			repo1.Id,
-		).Scan(&secondDeletedAt)
-		require.NoError(t, err)
-		assert.Equal(t, firstDeletedAt, secondDeletedAt, "deleted_at should not change on second delete")
-	})
-
-	t.Run("batch delete is atomic", func(t *testing.T) {
-		container := setupPgContainer(t)
-		defer func() {
-			err := container.Terminate(t.Context())
-			require.NoError(t, err)
-		}()
-
-		connString, err := container.ConnectionString(t.Context())
-		require.NoError(t, err)
-
-		createRepositoriesTable(t, connString)
-		repo, pool := setupTestRepository(t, connString)
-		defer pool.Close()
-
-		orgID := uuid.NewString()
-
-		repo1 := createTestRepository(t, "repo1")
-		repo1.OrganizationId = orgID
-		err = repo.CreateRepository(t.Context(), repo1)
-		require.NoError(t, err)
-
-		repo2 := createTestRepository(t, "repo2")
-		repo2.OrganizationId = orgID
-		err = repo.CreateRepository(t.Context(), repo2)
-		require.NoError(t, err)
-
-		err = repo.DeleteRepositoriesByOrganizationId(t.Context(), orgID)
-		assert.NoError(t, err)
-
-		conn, err := pgx.Connect(t.Context(), connString)
-		require.NoError(t, err)
-		defer func() {
-			_ = conn.Close(t.Context())
-		}()
-
-		var deletedAt1, deletedAt2 time.Time
-		err = conn.QueryRow(t.Context(),
-			"SELECT deleted_at FROM repositories WHERE id = $1",
-			repo1.Id,
-		).Scan(&deletedAt1)
-		require.NoError(t, err)
-
-		err = conn.QueryRow(t.Context(),
-			"SELECT deleted_at FROM repositories WHERE id = $1",
-			repo2.Id,
-		).Scan(&deletedAt2)
-		require.NoError(t, err)
-
-		timeDiff := deletedAt1.Sub(deletedAt2)
-		if timeDiff < 0 {
-			timeDiff = -timeDiff
-		}
-		assert.Less(t, timeDiff, 1*time.Second, "all repositories should be deleted with same timestamp (atomic operation)")
-	})
-}
-
-func TestPgRepository_GetFilePreview(t *testing.T) {
-	t.Run("successfully retrieves file content", func(t *testing.T) {
-		repo, pool := setupTestRepository(t, "")
-		defer pool.Close()
-
-		content := "# Test Repository\n\nThis is a test file."
-		testRepoPath := setupTestGitRepository(t, map[string]string{
-			"README.md": content,
-		})
-
-		response, err := repo.GetFilePreview(t.Context(), testRepoPath, "README.md")
+		response, err := repo.GetFilePreview(t.Context(), testRepoPath, "", "app.dockerfile")
 		require.NoError(t, err)
 		require.NotNil(t, response)
-		assert.Equal(t, content, response.Content)
-		assert.Equal(t, "text/markdown", response.MimeType)
-		assert.Equal(t, int64(len(content)), response.Size)
-	})
-
-	t.Run("returns correct mime type for different file extensions", func(t *testing.T) {
-		repo, pool := setupTestRepository(t, "")
-		defer pool.Close()
-
-		tests := []struct {
-			fileName     string
-			content      string
-			expectedMime string
-		}{
-			{"file.txt", "plain text", "text/plain"},
-			{"config.json", `{"key": "value"}`, "application/json"},
-			{"config.yaml", "key: value", "application/yaml"},
-			{"config.yml", "key: value", "application/yaml"},
-			{"style.css", "body { margin: 0; }", "text/css"},
-			{"script.js", "console.log('test');", "application/javascript"},
-			{"app.ts", "const x: string = 'test';", "application/typescript"},
-			{"main.go", "package main", "text/x-go"},
-			{"script.py", "print('hello')", "text/x-python"},
-			{"Main.java", "public class Main {}", "text/x-java"},
-			{"schema.proto", "syntax = \"proto3\";", "text/x-protobuf"},
-			{"query.sql", "SELECT * FROM users;", "application/sql"},
-			{"index.html", "<html></html>", "text/html"},
-		}
-
-		for _, tt := range tests {
-			testRepoPath := setupTestGitRepository(t, map[string]string{
-				tt.fileName: tt.content,
-			})
-
-			response, err := repo.GetFilePreview(t.Context(), testRepoPath, tt.fileName)
-			require.NoError(t, err, "failed for file: %s", tt.fileName)
-			assert.Equal(t, tt.expectedMime, response.MimeType, "incorrect mime type for: %s", tt.fileName)
-			assert.Equal(t, tt.content, response.Content, "incorrect content for: %s", tt.fileName)
-		}
-	})
-
-	t.Run("returns correct size for file", func(t *testing.T) {
-		repo, pool := setupTestRepository(t, "")
-		defer pool.Close()
-
-		content := "This is a test file with specific length."
-		testRepoPath := setupTestGitRepository(t, map[string]string{
-			"test.txt": content,
-		})
-
-		response, err := repo.GetFilePreview(t.Context(), testRepoPath, "test.txt")
-		require.NoError(t, err)
-		assert.Equal(t, int64(len(content)), response.Size)
+		assert.Equal(t, "text/x-dockerfile", response.MimeType)
+		assert.Equal(t, "FROM node:18", response.Content)
 	})
 
-	t.Run("handles file in subdirectory", func(t *testing.T) {
+	t.Run("defaults to text/plain for unknown extension", func(t *testing.T) {
 		repo, pool := setupTestRepository(t, "")
 		defer pool.Close()
 
 		testRepoPath := setupTestGitRepository(t, map[string]string{
-			"src/main.go": "package main\n\nfunc main() {}",
+			"file.unknown": "some content",
 		})
 
-		response, err := repo.GetFilePreview(t.Context(), testRepoPath, "src/main.go")
+		response, err := repo.GetFilePreview(t.Context(), testRepoPath, "", "file.unknown")
 		require.NoError(t, err)
 		require.NotNil(t, response)
-		assert.Equal(t, "package main\n\nfunc main() {}", response.Content)
-		assert.Equal(t, "text/x-go", response.MimeType)
+		assert.Equal(t, "text/plain", response.MimeType)
 	})
 
-	t.Run("handles deeply nested file", func(t *testing.T) {
+	t.Run("detects binary file", func(t *testing.T) {
 		repo, pool := setupTestRepository(t, "")
 		defer pool.Close()
 
+		binaryContent := string([]byte{0x00, 0x01, 0x02, 0x03})
 		testRepoPath := setupTestGitRepository(t, map[string]string{
-			"src/internal/handlers/auth/login.go": "package auth",
+			"binary.bin": binaryContent,
 		})
 
-		response, err := repo.GetFilePreview(t.Context(), testRepoPath, "src/internal/handlers/auth/login.go")
+		response, err := repo.GetFilePreview(t.Context(), testRepoPath, "", "binary.bin")
 		require.NoError(t, err)
 		require.NotNil(t, response)
-		assert.Equal(t, "package auth", response.Content)
-		assert.Equal(t, "text/x-go", response.MimeType)
-	})
-
-	t.Run("returns error for non-existent file", func(t *testing.T) {
-		repo, pool := setupTestRepository(t, "")
-		defer pool.Close()
-
-		testRepoPath := setupTestGitRepository(t, map[string]string{
-			"README.md": "# Test",
-		})
-
-		_, err := repo.GetFilePreview(t.Context(), testRepoPath, "non-existent.txt")
-		require.Error(t, err)
-		assert.Contains(t, err.Error(), "file not found")
+		assert.Equal(t, "application/octet-stream", response.MimeType)
 	})
 
-	t.Run("returns error for invalid repository path", func(t *testing.T) {
+	t.Run("handles large file content", func(t *testing.T) {
 		repo, pool := setupTestRepository(t, "")
 		defer pool.Close()
 
-		_, err := repo.GetFilePreview(t.Context(), "/invalid/path/to/repo", "README.md")
-		require.Error(t, err)
-		assert.Contains(t, err.Error(), "failed to open git repository")
-	})
-
-	t.Run("handles empty file", func(t *testing.T) {
-		repo, pool := setupTestRepository(t, "")
-		defer pool.Close()
+		largeContent := ""
+		for i := 0; i < 1000; i++ {
+			largeContent += "This is line " + strconv.Itoa(i) + "\n"
+		}
 
 		testRepoPath := setupTestGitRepository(t, map[string]string{
-			"empty.txt": "",
+			"large.txt": largeContent,
 		})
 
-		response, err := repo.GetFilePreview(t.Context(), testRepoPath, "empty.txt")
+		response, err := repo.GetFilePreview(t.Context(), testRepoPath, "", "large.txt")
 		require.NoError(t, err)
 		require.NotNil(t, response)
-		assert.Equal(t, "", response.Content)
-		assert.Equal(t, int64(0), response.Size)
-		assert.Equal(t, "text/plain", response.MimeType)
+		assert.Equal(t, largeContent, response.Content)
+		assert.Equal(t, int64(len(largeContent)), response.Size)
 	})
 
-	t.Run("handles file without extension", func(t *testing.T) {
+	t.Run("handles multiple files and retrieves correct one", func(t *testing.T) {
 		repo, pool := setupTestRepository(t, "")
 		defer pool.Close()
 
 		testRepoPath := setupTestGitRepository(t, map[string]string{
-			"Makefile": "all:\n\tgo build",
+			"file1.txt": "content1",
+			"file2.txt": "content2",
+			"file3.txt": "content3",
 		})
 
-		response, err := repo.GetFilePreview(t.Context(), testRepoPath, "Makefile")
+		response, err := repo.GetFilePreview(t.Context(), testRepoPath, "", "file2.txt")
 		require.NoError(t, err)
 		require.NotNil(t, response)
-		assert.Equal(t, "all:\n\tgo build", response.Content)
-		assert.Equal(t, "text/plain", response.MimeType)
+		assert.Equal(t, "content2", response.Content)
 	})
 
-	t.Run("handles dockerfile extension", func(t *testing.T) {
+	t.Run("returns error when file is actually a directory", func(t *testing.T) {
 		repo, pool := setupTestRepository(t, "")
 		defer pool.Close()
 
 		testRepoPath := setupTestGitRepository(t, map[string]string{
-			"app.dockerfile": "FROM node:18",
+			"src/main.go": "package main",
 		})
 
-		response, err := repo.GetFilePreview(t.Context(), testRepoPath, "app.dockerfile")
-		require.NoError(t, err)
-		require.NotNil(t, response)
-		assert.Equal(t, "text/x-dockerfile", response.MimeType)
-		assert.Equal(t, "FROM node:18", response.Content)
+		_, err := repo.GetFilePreview(t.Context(), testRepoPath, "", "src")
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "file not found")
 	})
 
-	t.Run("defaults to text/plain for unknown extension", func(t *testing.T) {
+	t.Run("truncates text file over preview size", func(t *testing.T) {
 		repo, pool := setupTestRepository(t, "")
 		defer pool.Close()
+		repo.maxPreviewSize = 16
 
+		content := strings.Repeat("abcdefgh", 8)
 		testRepoPath := setupTestGitRepository(t, map[string]string{
-			"file.unknown": "some content",
+			"big.txt": content,
 		})
 
-		response, err := repo.GetFilePreview(t.Context(), testRepoPath, "file.unknown")
+		response, err := repo.GetFilePreview(t.Context(), testRepoPath, "", "big.txt")
 		require.NoError(t, err)
-		require.NotNil(t, response)
-		assert.Equal(t, "text/plain", response.MimeType)
+		assert.True(t, response.Truncated)
+		assert.False(t, response.Binary)
+		assert.Equal(t, content[:16], response.Content)
+		assert.Equal(t, int64(len(content)), response.Size)
 	})
 
-	t.Run("detects binary file", func(t *testing.T) {
+	t.Run("flags binary file without content", func(t *testing.T) {
 		repo, pool := setupTestRepository(t, "")
 		defer pool.Close()
 
-		binaryContent := string([]byte{0x00, 0x01, 0x02, 0x03})
 		testRepoPath := setupTestGitRepository(t, map[string]string{
-			"binary.bin": binaryContent,
+			"data.txt": "header\x00\x01\x02trailer",
 		})
 
-		response, err := repo.GetFilePreview(t.Context(), testRepoPath, "binary.bin")
+		response, err := repo.GetFilePreview(t.Context(), testRepoPath, "", "data.txt")
 		require.NoError(t, err)
-		require.NotNil(t, response)
+		assert.True(t, response.Binary)
+		assert.False(t, response.Truncated)
+		assert.Empty(t, response.Content)
 		assert.Equal(t, "application/octet-stream", response.MimeType)
+		assert.Equal(t, int64(16), response.Size)
 	})
 
-	t.Run("handles large file content", func(t *testing.T) {
+	t.Run("returns small text file in full", func(t *testing.T) {
 		repo, pool := setupTestRepository(t, "")
 		defer pool.Close()
+		repo.maxPreviewSize = 1024
 
-		largeContent := ""
-		for i := 0; i < 1000; i++ {
-			largeContent += "This is line " + string(rune(i)) + "\n"
-		}
-
+		content := "syntax = \"proto3\";\n"
 		testRepoPath := setupTestGitRepository(t, map[string]string{
-			"large.txt": largeContent,
+			"service.proto": content,
 		})
 
-		response, err := repo.GetFilePreview(t.Context(), testRepoPath, "large.txt")
+		response, err := repo.GetFilePreview(t.Context(), testRepoPath, "", "service.proto")
 		require.NoError(t, err)
-		require.NotNil(t, response)
-		assert.Equal(t, largeContent, response.Content)
-		assert.Equal(t, int64(len(largeContent)), response.Size)
+		assert.False(t, response.Truncated)
+		assert.False(t, response.Binary)
+		assert.Equal(t, content, response.Content)
 	})
 
-	t.Run("handles multiple files and retrieves correct one", func(t *testing.T) {
+	t.Run("includes language hint", func(t *testing.T) {
 		repo, pool := setupTestRepository(t, "")
 		defer pool.Close()
 
 		testRepoPath := setupTestGitRepository(t, map[string]string{
-			"file1.txt": "content1",
-			"file2.txt": "content2",
-			"file3.txt": "content3",
+			"proto/v1/user.proto": "syntax = \"proto3\";\n",
 		})
 
-		response, err := repo.GetFilePreview(t.Context(), testRepoPath, "file2.txt")
+		response, err := repo.GetFilePreview(t.Context(), testRepoPath, "", "proto/v1/user.proto")
 		require.NoError(t, err)
-		require.NotNil(t, response)
-		assert.Equal(t, "content2", response.Content)
+		assert.Equal(t, "protobuf", response.Language)
 	})
+}
 
-	t.Run("returns error when file is actually a directory", func(t *testing.T) {
-		repo, pool := setupTestRepository(t, "")
-		defer pool.Close()
+func TestDetectLanguage(t *testing.T) {
+	tests := []struct {
+		name     string
+		filePath string
+		content  string
+		want     string
+	}{
+		{name: "proto", filePath: "api/v1/service.proto", want: "protobuf"},
+		{name: "go", filePath: "main.go", want: "go"},
+		{name: "typescript", filePath: "src/index.ts", want: "typescript"},
+		{name: "yaml", filePath: "buf.yaml", want: "yaml"},
+		{name: "upper case extension", filePath: "README.MD", want: "markdown"},
+		{name: "dockerfile by name", filePath: "build/Dockerfile", want: "dockerfile"},
+		{name: "makefile by name", filePath: "Makefile", want: "makefile"},
+		{name: "shebang with env", filePath: "scripts/generate", content: "#!/usr/bin/env python3\nprint('hi')\n", want: "python"},
+		{name: "shebang with path", filePath: "bin/setup", content: "#!/bin/bash -e\necho hi\n", want: "bash"},
+		{name: "extension wins over shebang", filePath: "run.rb", content: "#!/bin/sh\n", want: "ruby"},
+		{name: "unknown extension", filePath: "data.xyz", want: ""},
+		{name: "unknown shebang", filePath: "tool", content: "#!/usr/bin/awk -f\n", want: ""},
+		{name: "extensionless without shebang", filePath: "LICENSE", content: "MIT License\n", want: ""},
+	}
 
-		testRepoPath := setupTestGitRepository(t, map[string]string{
-			"src/main.go": "package main",
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, detectLanguage(tt.filePath, []byte(tt.content)))
 		})
-
-		_, err := repo.GetFilePreview(t.Context(), testRepoPath, "src")
-		require.Error(t, err)
-		assert.Contains(t, err.Error(), "file not found")
-	})
+	}
 }
 
 func TestPgRepository_GetRepositoriesByOrganizationId(t *testing.T) {
@@ -1245,15 +1265,79 @@ func TestPgRepository_GetRepositoriesByOrganizationId(t *testing.T) {
 	})
 }
 
-func TestPgRepository_GetRepositoriesCount(t *testing.T) {
-	t.Run("returns correct count", func(t *testing.T) {
-		container := setupPgContainer(t)
-		defer func() {
-			err := container.Terminate(t.Context())
-			require.NoError(t, err)
-		}()
-
-		connString, err := container.ConnectionString(t.Context())
+func TestPgRepository_GetOrganizationRepositorySummary(t *testing.T) {
+	container := setupPgContainer(t)
+	defer func() {
+		err := container.Terminate(t.Context())
+		require.NoError(t, err)
+	}()
+
+	connString, err := container.ConnectionString(t.Context())
+	require.NoError(t, err)
+
+	createRepositoriesTable(t, connString)
+
+	repo, pool := setupTestRepository(t, connString)
+	defer pool.Close()
+
+	orgID := uuid.NewString()
+	base := time.Now().UTC().Add(-time.Hour)
+	var created []*registry.RepositoryDTO
+	for i, visibility := range []proto.Visibility{proto.VisibilityPublic, proto.VisibilityPrivate, proto.VisibilityPublic} {
+		r := createTestRepository(t, "summary-"+strconv.Itoa(i)+"-"+uuid.NewString())
+		r.OrganizationId = orgID
+		r.Visibility = visibility
+		updatedAt := base.Add(time.Duration(i) * time.Minute)
+		r.UpdatedAt = &updatedAt
+		require.NoError(t, repo.CreateRepository(t.Context(), r))
+		created = append(created, r)
+	}
+
+	deleted := createTestRepository(t, "summary-deleted-"+uuid.NewString())
+	deleted.OrganizationId = orgID
+	require.NoError(t, repo.CreateRepository(t.Context(), deleted))
+	require.NoError(t, repo.DeleteRepository(t.Context(), deleted.Id))
+
+	other := createTestRepository(t, "summary-other-"+uuid.NewString())
+	other.OrganizationId = uuid.NewString()
+	require.NoError(t, repo.CreateRepository(t.Context(), other))
+
+	t.Run("counts live repositories", func(t *testing.T) {
+		count, err := repo.GetOrganizationRepositoriesCount(t.Context(), orgID, false)
+		require.NoError(t, err)
+		assert.Equal(t, 3, count)
+
+		count, err = repo.GetOrganizationRepositoriesCount(t.Context(), orgID, true)
+		require.NoError(t, err)
+		assert.Equal(t, 2, count)
+	})
+
+	t.Run("returns most recently updated first", func(t *testing.T) {
+		recent, err := repo.GetRecentOrganizationRepositories(t.Context(), orgID, false, 2)
+		require.NoError(t, err)
+		require.Len(t, *recent, 2)
+		assert.Equal(t, created[2].Id, (*recent)[0].Id)
+		assert.Equal(t, created[1].Id, (*recent)[1].Id)
+	})
+
+	t.Run("public only skips private repositories", func(t *testing.T) {
+		recent, err := repo.GetRecentOrganizationRepositories(t.Context(), orgID, true, 5)
+		require.NoError(t, err)
+		require.Len(t, *recent, 2)
+		assert.Equal(t, created[2].Id, (*recent)[0].Id)
+		assert.Equal(t, created[0].Id, (*recent)[1].Id)
+	})
+}
+
+func TestPgRepository_GetRepositoriesCount(t *testing.T) {
+	t.Run("returns correct count", func(t *testing.T) {
+		container := setupPgContainer(t)
+		defer func() {
+			err := container.Terminate(t.Context())
+			require.NoError(t, err)
+		}()
+
+		connString, err := container.ConnectionString(t.Context())
 		require.NoError(t, err)
 
 		createRepositoriesTable(t, connString)
@@ -1530,6 +1614,103 @@ func TestPgRepository_GetRepositoryById(t *testing.T) {
 	})
 }
 
+func TestPgRepository_GetRepositoryByPath(t *testing.T) {
+	container := setupPgContainer(t)
+	defer func() {
+		err := container.Terminate(t.Context())
+		require.NoError(t, err)
+	}()
+
+	connString, err := container.ConnectionString(t.Context())
+	require.NoError(t, err)
+
+	createRepositoriesTable(t, connString)
+
+	repo, pool := setupTestRepository(t, connString)
+	defer pool.Close()
+
+	testRepo := createTestRepository(t, "get-by-path-"+uuid.NewString())
+	require.NoError(t, repo.CreateRepository(t.Context(), testRepo))
+
+	t.Run("found", func(t *testing.T) {
+		found, err := repo.GetRepositoryByPath(t.Context(), testRepo.Path+"/")
+		require.NoError(t, err)
+		assert.Equal(t, testRepo.Id, found.Id)
+		assert.Equal(t, testRepo.Path, found.Path)
+	})
+
+	t.Run("unknown path", func(t *testing.T) {
+		_, err := repo.GetRepositoryByPath(t.Context(), "/test/path/"+uuid.NewString())
+		require.ErrorIs(t, err, ErrRepositoryNotFound)
+	})
+}
+
+func TestPgRepository_GetRepositoryWithSdkPreferences(t *testing.T) {
+	container := setupPgContainer(t)
+	defer func() {
+		err := container.Terminate(t.Context())
+		require.NoError(t, err)
+	}()
+
+	connString, err := container.ConnectionString(t.Context())
+	require.NoError(t, err)
+
+	createRepositoriesTable(t, connString)
+
+	repo, pool := setupTestRepository(t, connString)
+	defer pool.Close()
+
+	_, err = pool.Exec(t.Context(), `CREATE TYPE sdk_type AS ENUM (
+		'GO_PROTOBUF', 'GO_CONNECTRPC', 'GO_GRPC', 'JS_BUFBUILD_ES', 'JS_PROTOBUF', 'JS_CONNECTRPC'
+	)`)
+	require.NoError(t, err)
+	_, err = pool.Exec(t.Context(), `CREATE TABLE sdk_preferences (
+		id VARCHAR PRIMARY KEY,
+		repository_id VARCHAR NOT NULL,
+		sdk sdk_type NOT NULL,
+		status BOOLEAN NOT NULL DEFAULT false
+	)`)
+	require.NoError(t, err)
+
+	withPreferences := createTestRepository(t, "with-prefs-"+uuid.NewString())
+	require.NoError(t, repo.CreateRepository(t.Context(), withPreferences))
+	for sdk, status := range map[string]bool{"GO_PROTOBUF": true, "JS_PROTOBUF": false} {
+		_, err = pool.Exec(t.Context(),
+			"INSERT INTO sdk_preferences (id, repository_id, sdk, status) VALUES ($1, $2, $3, $4)",
+			uuid.NewString(), withPreferences.Id, sdk, status)
+		require.NoError(t, err)
+	}
+
+	withoutPreferences := createTestRepository(t, "without-prefs-"+uuid.NewString())
+	require.NoError(t, repo.CreateRepository(t.Context(), withoutPreferences))
+
+	t.Run("returns the repository with its preferences", func(t *testing.T) {
+		found, preferences, err := repo.GetRepositoryWithSdkPreferences(t.Context(), withPreferences.Id)
+		require.NoError(t, err)
+
+		assert.Equal(t, withPreferences.Id, found.Id)
+		assert.Equal(t, withPreferences.Name, found.Name)
+		require.Len(t, preferences, 2)
+		assert.Equal(t, registry.SdkGoProtobuf, preferences[0].Sdk)
+		assert.True(t, preferences[0].Status)
+		assert.Equal(t, registry.SdkJsProtobuf, preferences[1].Sdk)
+		assert.False(t, preferences[1].Status)
+	})
+
+	t.Run("repository without preferences", func(t *testing.T) {
+		found, preferences, err := repo.GetRepositoryWithSdkPreferences(t.Context(), withoutPreferences.Id)
+		require.NoError(t, err)
+
+		assert.Equal(t, withoutPreferences.Id, found.Id)
+		assert.Empty(t, preferences)
+	})
+
+	t.Run("not found", func(t *testing.T) {
+		_, _, err := repo.GetRepositoryWithSdkPreferences(t.Context(), uuid.NewString())
+		require.ErrorIs(t, err, ErrRepositoryNotFound)
+	})
+}
+
 func TestPgRepository_UpdateRepository(t *testing.T) {
 	t.Run("success", func(t *testing.T) {
 		container := setupPgContainer(t)
@@ -1581,6 +1762,136 @@ func TestPgRepository_UpdateRepository(t *testing.T) {
 	})
 }
 
+func TestPgRepository_TransferRepository(t *testing.T) {
+	container := setupPgContainer(t)
+	defer func() {
+		err := container.Terminate(t.Context())
+		require.NoError(t, err)
+	}()
+
+	connString, err := container.ConnectionString(t.Context())
+	require.NoError(t, err)
+
+	createRepositoriesTable(t, connString)
+
+	repo, pool := setupTestRepository(t, connString)
+	defer pool.Close()
+
+	_, err = pool.Exec(t.Context(), `CREATE TABLE sdk_preferences (
+		id VARCHAR PRIMARY KEY,
+		repository_id VARCHAR NOT NULL,
+		last_generated_commit VARCHAR
+	)`)
+	require.NoError(t, err)
+
+	moving := createTestRepository(t, "api")
+	moving.OrganizationId = "org-source"
+	require.NoError(t, repo.CreateRepository(t.Context(), moving))
+
+	_, err = pool.Exec(t.Context(),
+		"INSERT INTO sdk_preferences (id, repository_id, last_generated_commit) VALUES ($1, $2, 'abc123')",
+		uuid.NewString(), moving.Id)
+	require.NoError(t, err)
+
+	t.Run("name taken in the target organization", func(t *testing.T) {
+		clash := createTestRepository(t, "api")
+		clash.OrganizationId = "org-clash"
+		require.NoError(t, repo.CreateRepository(t.Context(), clash))
+
+		err := repo.TransferRepository(t.Context(), moving.Id, "org-clash")
+		assert.ErrorIs(t, err, ErrRepositoryAlreadyExists)
+
+		unchanged, err := repo.GetRepositoryById(t.Context(), moving.Id)
+		require.NoError(t, err)
+		assert.Equal(t, "org-source", unchanged.OrganizationId)
+	})
+
+	t.Run("moves the repository and resets generated commits", func(t *testing.T) {
+		err := repo.TransferRepository(t.Context(), moving.Id, "org-target")
+		require.NoError(t, err)
+
+		moved, err := repo.GetRepositoryById(t.Context(), moving.Id)
+		require.NoError(t, err)
+		assert.Equal(t, "org-target", moved.OrganizationId)
+		assert.Equal(t, moving.Path, moved.Path)
+
+		var lastGenerated *string
+		err = pool.QueryRow(t.Context(),
+			"SELECT last_generated_commit FROM sdk_preferences WHERE repository_id = $1", moving.Id).Scan(&lastGenerated)
+		require.NoError(t, err)
+		assert.Nil(t, lastGenerated)
+	})
+
+	t.Run("unknown repository", func(t *testing.T) {
+		err := repo.TransferRepository(t.Context(), uuid.NewString(), "org-target")
+		assert.ErrorIs(t, err, ErrRepositoryNotFound)
+	})
+}
+
+func TestPgRepository_SetRepositoryArchived(t *testing.T) {
+	t.Run("archives and unarchives repository", func(t *testing.T) {
+		container := setupPgContainer(t)
+		defer func() {
+			err := container.Terminate(t.Context())
+			require.NoError(t, err)
+		}()
+
+		connString, err := container.ConnectionString(t.Context())
+		require.NoError(t, err)
+
+		createRepositoriesTable(t, connString)
+
+		repo, pool := setupTestRepository(t, connString)
+		defer pool.Close()
+
+		testRepo := createTestRepository(t, "archivable")
+		err = repo.CreateRepository(t.Context(), testRepo)
+		require.NoError(t, err)
+
+		created, err := repo.GetRepositoryById(t.Context(), testRepo.Id)
+		require.NoError(t, err)
+		assert.False(t, created.Archived)
+
+		err = repo.SetRepositoryArchived(t.Context(), testRepo.Id, true)
+		require.NoError(t, err)
+
+		archived, err := repo.GetRepositoryById(t.Context(), testRepo.Id)
+		require.NoError(t, err)
+		assert.True(t, archived.Archived)
+
+		repos, err := repo.GetRepositories(t.Context(), 1, 10)
+		require.NoError(t, err)
+		require.Len(t, *repos, 1)
+		assert.True(t, (*repos)[0].Archived)
+
+		err = repo.SetRepositoryArchived(t.Context(), testRepo.Id, false)
+		require.NoError(t, err)
+
+		unarchived, err := repo.GetRepositoryById(t.Context(), testRepo.Id)
+		require.NoError(t, err)
+		assert.False(t, unarchived.Archived)
+	})
+
+	t.Run("not found", func(t *testing.T) {
+		container := setupPgContainer(t)
+		defer func() {
+			err := container.Terminate(t.Context())
+			require.NoError(t, err)
+		}()
+
+		connString, err := container.ConnectionString(t.Context())
+		require.NoError(t, err)
+
+		createRepositoriesTable(t, connString)
+
+		repo, pool := setupTestRepository(t, connString)
+		defer pool.Close()
+
+		err = repo.SetRepositoryArchived(t.Context(), uuid.NewString(), true)
+		require.ErrorIs(t, err, ErrRepositoryNotFound)
+	})
+}
+
 func TestPgRepository_GetFileTree(t *testing.T) {
 	t.Run("successfully retrieves file tree from root", func(t *testing.T) {
 		repo, pool := setupTestRepository(t, "")
@@ -1592,7 +1903,7 @@ func TestPgRepository_GetFileTree(t *testing.T) {
 			"config.yml": "key: value",
 		})
 
-		response, err := repo.GetFileTree(t.Context(), testRepoPath, nil)
+		response, err := repo.GetFileTree(t.Context(), testRepoPath, "", nil, registry.FileTreePage{})
 		require.NoError(t, err)
 		require.NotNil(t, response)
 		require.Len(t, response.Nodes, 3)
@@ -1619,7 +1930,7 @@ func TestPgRepository_GetFileTree(t *testing.T) {
 			"docs/guide.md": "# Guide",
 		})
 
-		response, err := repo.GetFileTree(t.Context(), testRepoPath, nil)
+		response, err := repo.GetFileTree(t.Context(), testRepoPath, "", nil, registry.FileTreePage{})
 		require.NoError(t, err)
 		require.NotNil(t, response)
 		require.Len(t, response.Nodes, 3)
@@ -1657,7 +1968,7 @@ func TestPgRepository_GetFileTree(t *testing.T) {
 		})
 
 		subPath := "src"
-		response, err := repo.GetFileTree(t.Context(), testRepoPath, &subPath)
+		response, err := repo.GetFileTree(t.Context(), testRepoPath, "", &subPath, registry.FileTreePage{})
 		require.NoError(t, err)
 		require.NotNil(t, response)
 		require.Len(t, response.Nodes, 2)
@@ -1684,7 +1995,7 @@ func TestPgRepository_GetFileTree(t *testing.T) {
 			"src/pkg/utils/helpers.go":               "package utils",
 		})
 
-		response, err := repo.GetFileTree(t.Context(), testRepoPath, nil)
+		response, err := repo.GetFileTree(t.Context(), testRepoPath, "", nil, registry.FileTreePage{})
 		require.NoError(t, err)
 		require.NotNil(t, response)
 		require.Len(t, response.Nodes, 1)
@@ -1719,7 +2030,7 @@ func TestPgRepository_GetFileTree(t *testing.T) {
 			"src/internal/auth/login.go": "package auth",
 		})
 
-		response, err := repo.GetFileTree(t.Context(), testRepoPath, nil)
+		response, err := repo.GetFileTree(t.Context(), testRepoPath, "", nil, registry.FileTreePage{})
 		require.NoError(t, err)
 		require.NotNil(t, response)
 
@@ -1745,7 +2056,7 @@ func TestPgRepository_GetFileTree(t *testing.T) {
 			".gitkeep": "",
 		})
 
-		response, err := repo.GetFileTree(t.Context(), testRepoPath, nil)
+		response, err := repo.GetFileTree(t.Context(), testRepoPath, "", nil, registry.FileTreePage{})
 		require.NoError(t, err)
 		require.NotNil(t, response)
 		require.Len(t, response.Nodes, 1)
@@ -1756,7 +2067,7 @@ func TestPgRepository_GetFileTree(t *testing.T) {
 		repo, pool := setupTestRepository(t, "")
 		defer pool.Close()
 
-		_, err := repo.GetFileTree(t.Context(), "/invalid/path/to/repo", nil)
+		_, err := repo.GetFileTree(t.Context(), "/invalid/path/to/repo", "", nil, registry.FileTreePage{})
 		require.Error(t, err)
 		assert.Contains(t, err.Error(), "failed to open git repository")
 	})
@@ -1770,7 +2081,7 @@ func TestPgRepository_GetFileTree(t *testing.T) {
 		})
 
 		subPath := "nonexistent/path"
-		_, err := repo.GetFileTree(t.Context(), testRepoPath, &subPath)
+		_, err := repo.GetFileTree(t.Context(), testRepoPath, "", &subPath, registry.FileTreePage{})
 		require.Error(t, err)
 		assert.Contains(t, err.Error(), "path not found")
 	})
@@ -1787,7 +2098,7 @@ func TestPgRepository_GetFileTree(t *testing.T) {
 			"config.yml":     "key: value",
 		})
 
-		response, err := repo.GetFileTree(t.Context(), testRepoPath, nil)
+		response, err := repo.GetFileTree(t.Context(), testRepoPath, "", nil, registry.FileTreePage{})
 		require.NoError(t, err)
 		require.NotNil(t, response)
 		require.Len(t, response.Nodes, 5)
@@ -1816,7 +2127,7 @@ func TestPgRepository_GetFileTree(t *testing.T) {
 			"main.go":   "package main",
 		})
 
-		response, err := repo.GetFileTree(t.Context(), testRepoPath, nil)
+		response, err := repo.GetFileTree(t.Context(), testRepoPath, "", nil, registry.FileTreePage{})
 		require.NoError(t, err)
 		require.NotNil(t, response)
 
@@ -1839,7 +2150,7 @@ func TestPgRepository_GetFileTree(t *testing.T) {
 		})
 
 		subPath := "src/internal"
-		response, err := repo.GetFileTree(t.Context(), testRepoPath, &subPath)
+		response, err := repo.GetFileTree(t.Context(), testRepoPath, "", &subPath, registry.FileTreePage{})
 		require.NoError(t, err)
 		require.NotNil(t, response)
 		require.Len(t, response.Nodes, 2)
@@ -1862,7 +2173,7 @@ func TestPgRepository_GetFileTree(t *testing.T) {
 		})
 
 		emptySubPath := ""
-		response, err := repo.GetFileTree(t.Context(), testRepoPath, &emptySubPath)
+		response, err := repo.GetFileTree(t.Context(), testRepoPath, "", &emptySubPath, registry.FileTreePage{})
 		require.NoError(t, err)
 		require.NotNil(t, response)
 		require.Len(t, response.Nodes, 2)
@@ -1876,7 +2187,7 @@ func TestPgRepository_GetFileTree(t *testing.T) {
 			"src/handlers/auth.go": "package handlers",
 		})
 
-		response, err := repo.GetFileTree(t.Context(), testRepoPath, nil)
+		response, err := repo.GetFileTree(t.Context(), testRepoPath, "", nil, registry.FileTreePage{})
 		require.NoError(t, err)
 
 		srcNode := response.Nodes[0]
@@ -1899,7 +2210,7 @@ func TestPgRepository_GetFileTree(t *testing.T) {
 			"tests/unit/.gitkeep":   "",
 		})
 
-		response, err := repo.GetFileTree(t.Context(), testRepoPath, nil)
+		response, err := repo.GetFileTree(t.Context(), testRepoPath, "", nil, registry.FileTreePage{})
 		require.NoError(t, err)
 		require.NotNil(t, response)
 		require.Len(t, response.Nodes, 3)
@@ -1920,7 +2231,7 @@ func TestPgRepository_GetFileTree(t *testing.T) {
 			"file.multiple.dots.yaml":  "key: value",
 		})
 
-		response, err := repo.GetFileTree(t.Context(), testRepoPath, nil)
+		response, err := repo.GetFileTree(t.Context(), testRepoPath, "", nil, registry.FileTreePage{})
 		require.NoError(t, err)
 		require.NotNil(t, response)
 		require.Len(t, response.Nodes, 3)
@@ -1946,7 +2257,7 @@ func TestPgRepository_GetFileTree(t *testing.T) {
 		})
 
 		subPath := "src/internal/handlers"
-		response, err := repo.GetFileTree(t.Context(), testRepoPath, &subPath)
+		response, err := repo.GetFileTree(t.Context(), testRepoPath, "", &subPath, registry.FileTreePage{})
 		require.NoError(t, err)
 		require.NotNil(t, response)
 		require.Len(t, response.Nodes, 2)
@@ -1957,4 +2268,879 @@ func TestPgRepository_GetFileTree(t *testing.T) {
 			assert.Contains(t, node.Path, "src/internal/handlers/")
 		}
 	})
+
+	t.Run("lists directories first, then files, by name", func(t *testing.T) {
+		repo, pool := setupTestRepository(t, "")
+		defer pool.Close()
+
+		testRepoPath := setupTestGitRepository(t, map[string]string{
+			"b.proto":       "syntax = \"proto3\";",
+			"A.md":          "# A",
+			"zeta/z.proto":  "syntax = \"proto3\";",
+			"alpha/a.proto": "syntax = \"proto3\";",
+			"alpha/b/c.txt": "c",
+			"alpha/0.proto": "syntax = \"proto3\";",
+		})
+
+		response, err := repo.GetFileTree(t.Context(), testRepoPath, "", nil, registry.FileTreePage{})
+		require.NoError(t, err)
+		assert.Equal(t, 4, response.TotalCount)
+
+		var names []string
+		for _, node := range response.Nodes {
+			names = append(names, node.Name)
+		}
+		assert.Equal(t, []string{"alpha", "zeta", "A.md", "b.proto"}, names)
+
+		var alphaChildren []string
+		for _, node := range response.Nodes[0].Children {
+			alphaChildren = append(alphaChildren, node.Name)
+		}
+		assert.Equal(t, []string{"b", "0.proto", "a.proto"}, alphaChildren)
+	})
+
+	t.Run("pages through a large directory", func(t *testing.T) {
+		repo, pool := setupTestRepository(t, "")
+		defer pool.Close()
+
+		files := make(map[string]string)
+		for i := range 250 {
+			files[fmt.Sprintf("protos/file-%03d.proto", i)] = "syntax = \"proto3\";"
+		}
+		for i := range 5 {
+			files[fmt.Sprintf("protos/dir-%d/nested.proto", i)] = "syntax = \"proto3\";"
+		}
+		testRepoPath := setupTestGitRepository(t, files)
+
+		subPath := "protos"
+		var seen []string
+		for offset := 0; ; offset += 100 {
+			response, err := repo.GetFileTree(t.Context(), testRepoPath, "", &subPath, registry.FileTreePage{Offset: offset, Limit: 100})
+			require.NoError(t, err)
+			assert.Equal(t, 255, response.TotalCount)
+
+			if len(response.Nodes) == 0 {
+				break
+			}
+			assert.LessOrEqual(t, len(response.Nodes), 100)
+
+			for _, node := range response.Nodes {
+				assert.Empty(t, node.Children, "paginated listings are one level deep")
+				seen = append(seen, node.Path)
+			}
+		}
+
+		require.Len(t, seen, 255)
+		assert.Equal(t, "protos/dir-0", seen[0])
+		assert.Equal(t, "protos/dir-4", seen[4])
+		assert.Equal(t, "protos/file-000.proto", seen[5])
+		assert.Equal(t, "protos/file-249.proto", seen[254])
+	})
+
+	t.Run("offset past the end returns no nodes", func(t *testing.T) {
+		repo, pool := setupTestRepository(t, "")
+		defer pool.Close()
+
+		testRepoPath := setupTestGitRepository(t, map[string]string{
+			"README.md": "# Test",
+		})
+
+		response, err := repo.GetFileTree(t.Context(), testRepoPath, "", nil, registry.FileTreePage{Offset: 10, Limit: 5})
+		require.NoError(t, err)
+		assert.Empty(t, response.Nodes)
+		assert.Equal(t, 1, response.TotalCount)
+	})
+}
+
+func setupTestBareRepositoryWithRefs(t *testing.T) (string, map[string]string) {
+	t.Helper()
+
+	workDir := t.TempDir()
+	bareDir := filepath.Join(t.TempDir(), "repo.git")
+
+	runGit := func(dir string, args ...string) string {
+		cmd := exec.Command("git", args...)
+		cmd.Dir = dir
+		out, err := cmd.CombinedOutput()
+		require.NoError(t, err, "git %v failed: %s", args, string(out))
+		return strings.TrimSpace(string(out))
+	}
+
+	runGit(workDir, "init", "--initial-branch=main")
+	runGit(workDir, "config", "user.email", "test@example.com")
+	runGit(workDir, "config", "user.name", "Test User")
+
+	commits := map[string]string{}
+	runGit(workDir, "commit", "--allow-empty", "-m", "first")
+	commits["first"] = runGit(workDir, "rev-parse", "HEAD")
+	runGit(workDir, "tag", "v1.0.0")
+	runGit(workDir, "tag", "-a", "v1.10.0", "-m", "annotated release")
+
+	runGit(workDir, "checkout", "-b", "feature/refs")
+	runGit(workDir, "commit", "--allow-empty", "-m", "second")
+	commits["second"] = runGit(workDir, "rev-parse", "HEAD")
+	runGit(workDir, "tag", "-a", "v1.2.0", "-m", "annotated release")
+
+	runGit(workDir, "checkout", "main")
+	runGit(workDir, "branch", "develop", commits["second"])
+	runGit(workDir, "branch", "alpha", commits["first"])
+
+	runGit(workDir, "clone", "--bare", "--quiet", workDir, bareDir)
+
+	return bareDir, commits
+}
+
+func TestPgRepository_ListRefs(t *testing.T) {
+	repo := &PgRepository{
+		tracer: noop.NewTracerProvider().Tracer("test"),
+	}
+
+	t.Run("branches and tags are categorized with their targets", func(t *testing.T) {
+		repoPath, commits := setupTestBareRepositoryWithRefs(t)
+
+		branches, tags, err := repo.ListRefs(t.Context(), repoPath)
+		require.NoError(t, err)
+
+		assert.Equal(t, []registry.RefInfo{
+			{Name: "main", Target: commits["first"]},
+			{Name: "alpha", Target: commits["first"]},
+			{Name: "develop", Target: commits["second"]},
+			{Name: "feature/refs", Target: commits["second"]},
+		}, branches)
+
+		assert.Equal(t, []registry.RefInfo{
+			{Name: "v1.10.0", Target: commits["first"]},
+			{Name: "v1.2.0", Target: commits["second"]},
+			{Name: "v1.0.0", Target: commits["first"]},
+		}, tags)
+	})
+
+	t.Run("empty repository has no refs", func(t *testing.T) {
+		repoPath := t.TempDir()
+		cmd := exec.Command("git", "init", "--bare", "--quiet", repoPath)
+		out, err := cmd.CombinedOutput()
+		require.NoError(t, err, string(out))
+
+		branches, tags, err := repo.ListRefs(t.Context(), repoPath)
+		require.NoError(t, err)
+		assert.Empty(t, branches)
+		assert.Empty(t, tags)
+	})
+
+	t.Run("missing repository", func(t *testing.T) {
+		_, _, err := repo.ListRefs(t.Context(), filepath.Join(t.TempDir(), "missing"))
+		require.Error(t, err)
+		assert.Equal(t, connect.CodeNotFound, connect.CodeOf(err))
+	})
+}
+
+func TestPgRepository_GetBranchDivergence(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not installed")
+	}
+
+	repo := &PgRepository{
+		tracer: noop.NewTracerProvider().Tracer("test"),
+	}
+
+	gitIn := func(t *testing.T, dir string, args ...string) {
+		t.Helper()
+
+		cmd := exec.Command("git", append([]string{"-c", "user.name=Test", "-c", "user.email=test@example.com"}, args...)...)
+		cmd.Dir = dir
+		out, err := cmd.CombinedOutput()
+		require.NoError(t, err, string(out))
+	}
+	commit := func(t *testing.T, dir, message string) {
+		t.Helper()
+
+		gitIn(t, dir, "commit", "--allow-empty", "--quiet", "-m", message)
+	}
+
+	t.Run("counts commits ahead and behind the default branch", func(t *testing.T) {
+		dir := t.TempDir()
+		gitIn(t, dir, "init", "--quiet", "--initial-branch=main")
+		commit(t, dir, "c1")
+		commit(t, dir, "c2")
+		gitIn(t, dir, "branch", "feature")
+		gitIn(t, dir, "branch", "stale")
+		commit(t, dir, "c3")
+
+		gitIn(t, dir, "checkout", "--quiet", "feature")
+		commit(t, dir, "f1")
+		commit(t, dir, "f2")
+
+		gitIn(t, dir, "checkout", "--quiet", "--orphan", "lonely")
+		commit(t, dir, "l1")
+		gitIn(t, dir, "checkout", "--quiet", "main")
+
+		defaultBranch, divergence, err := repo.GetBranchDivergence(t.Context(), dir)
+		require.NoError(t, err)
+		assert.Equal(t, "main", defaultBranch)
+
+		byName := map[string]registry.BranchDivergence{}
+		for _, branch := range divergence {
+			assert.NotEmpty(t, branch.Target)
+			branch.Target = ""
+			byName[branch.Name] = branch
+		}
+
+		assert.Equal(t, map[string]registry.BranchDivergence{
+			"main":    {Name: "main"},
+			"feature": {Name: "feature", Ahead: 2, Behind: 1},
+			"stale":   {Name: "stale", Behind: 1},
+			"lonely":  {Name: "lonely", Unrelated: true},
+		}, byName)
+	})
+
+	t.Run("empty repository has no branches", func(t *testing.T) {
+		repoPath := t.TempDir()
+		gitIn(t, repoPath, "init", "--bare", "--quiet")
+
+		defaultBranch, divergence, err := repo.GetBranchDivergence(t.Context(), repoPath)
+		require.NoError(t, err)
+		assert.Empty(t, defaultBranch)
+		assert.Empty(t, divergence)
+	})
+
+	t.Run("missing repository", func(t *testing.T) {
+		_, _, err := repo.GetBranchDivergence(t.Context(), filepath.Join(t.TempDir(), "missing"))
+		assert.Equal(t, connect.CodeNotFound, connect.CodeOf(err))
+	})
+}
+
+func TestPgRepository_RefResolution(t *testing.T) {
+	repo := &PgRepository{
+		tracer: noop.NewTracerProvider().Tracer("test"),
+	}
+
+	t.Run("HEAD and empty ref follow the symbolic HEAD", func(t *testing.T) {
+		repoPath, commits := setupTestBareRepositoryWithRefs(t)
+
+		for _, ref := range []string{registry.HeadRef, ""} {
+			log, total, err := repo.GetCommits(t.Context(), repoPath, ref, 1, 10)
+			require.NoError(t, err)
+			assert.Equal(t, 1, total)
+			assert.Equal(t, commits["first"], log[0].Id)
+		}
+	})
+
+	t.Run("branch name selects that branch", func(t *testing.T) {
+		repoPath, commits := setupTestBareRepositoryWithRefs(t)
+
+		log, total, err := repo.GetCommits(t.Context(), repoPath, "feature/refs", 1, 10)
+		require.NoError(t, err)
+		assert.Equal(t, 2, total)
+		assert.Equal(t, commits["second"], log[0].Id)
+	})
+
+	t.Run("file endpoints read the requested branch", func(t *testing.T) {
+		testRepoPath := setupTestGitRepository(t, map[string]string{
+			"README.md": "# main",
+		})
+
+		gitRepo, err := git.PlainOpen(testRepoPath)
+		require.NoError(t, err)
+		head, err := gitRepo.Head()
+		require.NoError(t, err)
+
+		cmd := exec.Command("git", "branch", "other")
+		cmd.Dir = testRepoPath
+		out, err := cmd.CombinedOutput()
+		require.NoError(t, err, string(out))
+
+		tree, err := repo.GetFileTree(t.Context(), testRepoPath, head.Name().Short(), nil, registry.FileTreePage{})
+		require.NoError(t, err)
+		require.Len(t, tree.Nodes, 1)
+
+		preview, err := repo.GetFilePreview(t.Context(), testRepoPath, "other", "README.md")
+		require.NoError(t, err)
+		assert.Equal(t, "# main", string(preview.Content))
+	})
+
+	t.Run("unknown branch is not found", func(t *testing.T) {
+		repoPath, _ := setupTestBareRepositoryWithRefs(t)
+
+		_, _, err := repo.GetCommits(t.Context(), repoPath, "missing", 1, 10)
+		require.Error(t, err)
+		assert.Equal(t, connect.CodeNotFound, connect.CodeOf(err))
+	})
+
+	t.Run("unborn HEAD in an empty repository", func(t *testing.T) {
+		repoPath := t.TempDir()
+		cmd := exec.Command("git", "init", "--bare", "--quiet", "--initial-branch=trunk", repoPath)
+		out, err := cmd.CombinedOutput()
+		require.NoError(t, err, string(out))
+
+		_, err = repo.GetFileTree(t.Context(), repoPath, registry.HeadRef, nil, registry.FileTreePage{})
+		require.ErrorIs(t, err, registry.ErrRepositoryEmpty)
+
+		_, _, err = repo.GetCommits(t.Context(), repoPath, "", 1, 10)
+		require.ErrorIs(t, err, registry.ErrRepositoryEmpty)
+
+		_, err = repo.GetFilePreview(t.Context(), repoPath, "", "README.md")
+		require.ErrorIs(t, err, registry.ErrRepositoryEmpty)
+	})
+
+	t.Run("unborn HEAD next to other branches", func(t *testing.T) {
+		repoPath, _ := setupTestBareRepositoryWithRefs(t)
+		cmd := exec.Command("git", "symbolic-ref", "HEAD", "refs/heads/trunk")
+		cmd.Dir = repoPath
+		out, err := cmd.CombinedOutput()
+		require.NoError(t, err, string(out))
+
+		_, err = repo.GetFileTree(t.Context(), repoPath, registry.HeadRef, nil, registry.FileTreePage{})
+		require.Error(t, err)
+		assert.NotErrorIs(t, err, registry.ErrRepositoryEmpty)
+		assert.Equal(t, connect.CodeFailedPrecondition, connect.CodeOf(err))
+		assert.Contains(t, err.Error(), `HEAD points to branch "trunk"`)
+	})
+}
+
+func TestPgRepository_IsRepositoryEmpty(t *testing.T) {
+	repo := &PgRepository{
+		tracer: noop.NewTracerProvider().Tracer("test"),
+	}
+
+	t.Run("freshly initialized bare repository", func(t *testing.T) {
+		repoPath := t.TempDir()
+		_, err := git.PlainInit(repoPath, true)
+		require.NoError(t, err)
+
+		empty, err := repo.IsRepositoryEmpty(t.Context(), repoPath)
+		require.NoError(t, err)
+		assert.True(t, empty)
+
+		_, err = repo.GetRecentCommit(t.Context(), repoPath)
+		require.ErrorIs(t, err, registry.ErrRepositoryEmpty)
+
+		_, _, err = repo.GetCommits(t.Context(), repoPath, registry.HeadRef, 1, 10)
+		require.ErrorIs(t, err, registry.ErrRepositoryEmpty)
+	})
+
+	t.Run("repository with commits", func(t *testing.T) {
+		repoPath, _ := setupTestBareRepositoryWithRefs(t)
+
+		empty, err := repo.IsRepositoryEmpty(t.Context(), repoPath)
+		require.NoError(t, err)
+		assert.False(t, empty)
+	})
+
+	t.Run("missing repository", func(t *testing.T) {
+		_, err := repo.IsRepositoryEmpty(t.Context(), filepath.Join(t.TempDir(), "missing"))
+		require.Error(t, err)
+		assert.Equal(t, connect.CodeNotFound, connect.CodeOf(err))
+	})
+}
+
+func TestPgRepository_HasSdkInputChanges(t *testing.T) {
+	repo := &PgRepository{
+		tracer: noop.NewTracerProvider().Tracer("test"),
+	}
+
+	repoPath := setupTestGitRepository(t, map[string]string{
+		"proto/v1/service.proto": "syntax = \"proto3\";\n",
+		"README.md":              "# service\n",
+	})
+
+	gitRepo, err := git.PlainOpen(repoPath)
+	require.NoError(t, err)
+	worktree, err := gitRepo.Worktree()
+	require.NoError(t, err)
+
+	head, err := gitRepo.Head()
+	require.NoError(t, err)
+	base := head.Hash().String()
+
+	commitFile := func(filePath, content string) string {
+		t.Helper()
+
+		fullPath := filepath.Join(repoPath, filePath)
+		require.NoError(t, os.MkdirAll(filepath.Dir(fullPath), 0755))
+		require.NoError(t, os.WriteFile(fullPath, []byte(content), 0644))
+		_, err := worktree.Add(filePath)
+		require.NoError(t, err)
+
+		hash, err := worktree.Commit("update "+filePath, &git.CommitOptions{
+			Author: &object.Signature{Name: "Test User", Email: "test@example.com", When: time.Now()},
+		})
+		require.NoError(t, err)
+		return hash.String()
+	}
+
+	docsOnly := commitFile("README.md", "# service\n\nMore docs.\n")
+	protoChange := commitFile("proto/v1/service.proto", "syntax = \"proto3\";\npackage v1;\n")
+	bufConfig := commitFile("buf.yaml", "version: v2\n")
+
+	t.Run("docs-only push has no SDK input changes", func(t *testing.T) {
+		changed, err := repo.HasSdkInputChanges(t.Context(), repoPath, base, docsOnly)
+		require.NoError(t, err)
+		assert.False(t, changed)
+	})
+
+	t.Run("proto change is detected", func(t *testing.T) {
+		changed, err := repo.HasSdkInputChanges(t.Context(), repoPath, docsOnly, protoChange)
+		require.NoError(t, err)
+		assert.True(t, changed)
+	})
+
+	t.Run("buf config change is detected", func(t *testing.T) {
+		changed, err := repo.HasSdkInputChanges(t.Context(), repoPath, protoChange, bufConfig)
+		require.NoError(t, err)
+		assert.True(t, changed)
+	})
+
+	t.Run("same commit is unchanged", func(t *testing.T) {
+		changed, err := repo.HasSdkInputChanges(t.Context(), repoPath, bufConfig, bufConfig)
+		require.NoError(t, err)
+		assert.False(t, changed)
+	})
+
+	t.Run("unknown base commit counts as changed", func(t *testing.T) {
+		changed, err := repo.HasSdkInputChanges(t.Context(), repoPath, strings.Repeat("1", 40), bufConfig)
+		require.NoError(t, err)
+		assert.True(t, changed)
+	})
+}
+
+func TestPgRepository_GetOrganizationVisibility(t *testing.T) {
+	container := setupPgContainer(t)
+	defer func() {
+		err := container.Terminate(t.Context())
+		require.NoError(t, err)
+	}()
+
+	connString, err := container.ConnectionString(t.Context())
+	require.NoError(t, err)
+
+	createRepositoriesTable(t, connString)
+
+	repo, pool := setupTestRepository(t, connString)
+	defer pool.Close()
+
+	_, err = pool.Exec(t.Context(), `CREATE TABLE organizations (
+		id VARCHAR PRIMARY KEY,
+		name VARCHAR NOT NULL,
+		visibility visibility NOT NULL DEFAULT 'private',
+		deleted_at TIMESTAMP
+	)`)
+	require.NoError(t, err)
+
+	_, err = pool.Exec(t.Context(), `INSERT INTO organizations (id, name, visibility, deleted_at) VALUES
+		('org-public', 'public-org', 'public', NULL),
+		('org-private', 'private-org', 'private', NULL),
+		('org-deleted', 'deleted-org', 'public', NOW())`)
+	require.NoError(t, err)
+
+	t.Run("returns organization visibility", func(t *testing.T) {
+		visibility, err := repo.GetOrganizationVisibility(t.Context(), "org-public")
+		require.NoError(t, err)
+		assert.Equal(t, proto.VisibilityPublic, visibility)
+
+		visibility, err = repo.GetOrganizationVisibility(t.Context(), "org-private")
+		require.NoError(t, err)
+		assert.Equal(t, proto.VisibilityPrivate, visibility)
+	})
+
+	t.Run("deleted or missing organization is not found", func(t *testing.T) {
+		for _, id := range []string{"org-deleted", "org-missing"} {
+			_, err := repo.GetOrganizationVisibility(t.Context(), id)
+			require.Error(t, err)
+			assert.Equal(t, connect.CodeNotFound, connect.CodeOf(err))
+		}
+	})
+}
+
+func TestPgRepository_GetContributors(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not installed")
+	}
+
+	repo := &PgRepository{tracer: noop.NewTracerProvider().Tracer("test")}
+
+	commitAs := func(t *testing.T, dir, name, email, file string) {
+		t.Helper()
+
+		require.NoError(t, os.WriteFile(filepath.Join(dir, file), []byte(file), 0o600))
+		for _, args := range [][]string{
+			{"add", file},
+			{"-c", "user.name=" + name, "-c", "user.email=" + email, "commit", "-m", "update " + file},
+		} {
+			cmd := exec.Command("git", args...)
+			cmd.Dir = dir
+			out, err := cmd.CombinedOutput()
+			require.NoError(t, err, string(out))
+		}
+	}
+
+	t.Run("aggregates commits per author", func(t *testing.T) {
+		dir := t.TempDir()
+		_, err := git.PlainInit(dir, false)
+		require.NoError(t, err)
+
+		commitAs(t, dir, "Alice", "alice@example.com", "a1.proto")
+		commitAs(t, dir, "Bob", "bob@example.com", "b1.proto")
+		commitAs(t, dir, "Alice", "alice@example.com", "a2.proto")
+		commitAs(t, dir, "Alice", "alice@example.com", "a3.proto")
+
+		contributors, err := repo.GetContributors(t.Context(), dir)
+		require.NoError(t, err)
+		require.Len(t, contributors, 2)
+
+		assert.Equal(t, registry.Contributor{Name: "Alice", Email: "alice@example.com", CommitCount: 3}, contributors[0])
+		assert.Equal(t, registry.Contributor{Name: "Bob", Email: "bob@example.com", CommitCount: 1}, contributors[1])
+	})
+
+	t.Run("empty repository has no contributors", func(t *testing.T) {
+		dir := t.TempDir()
+		_, err := git.PlainInit(dir, true)
+		require.NoError(t, err)
+
+		contributors, err := repo.GetContributors(t.Context(), dir)
+		require.NoError(t, err)
+		assert.Empty(t, contributors)
+	})
+
+	t.Run("missing repository", func(t *testing.T) {
+		_, err := repo.GetContributors(t.Context(), filepath.Join(t.TempDir(), "missing"))
+		require.Error(t, err)
+		assert.Equal(t, connect.CodeNotFound, connect.CodeOf(err))
+	})
+}
+
+func TestPgRepository_GetCommitActivity(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not installed")
+	}
+
+	repo := &PgRepository{tracer: noop.NewTracerProvider().Tracer("test")}
+
+	commitAt := func(t *testing.T, dir string, at time.Time) {
+		t.Helper()
+
+		file := at.Format("20060102150405") + ".proto"
+		require.NoError(t, os.WriteFile(filepath.Join(dir, file), []byte(file), 0o600))
+		for _, args := range [][]string{
+			{"add", file},
+			{"-c", "user.name=Alice", "-c", "user.email=alice@example.com", "commit", "-m", "add " + file},
+		} {
+			cmd := exec.Command("git", args...)
+			cmd.Dir = dir
+			cmd.Env = append(os.Environ(),
+				"GIT_AUTHOR_DATE="+at.Format(time.RFC3339),
+				"GIT_COMMITTER_DATE="+at.Format(time.RFC3339))
+			out, err := cmd.CombinedOutput()
+			require.NoError(t, err, string(out))
+		}
+	}
+
+	day := func(d int) time.Time {
+		return time.Date(2024, time.January, d, 0, 0, 0, 0, time.UTC)
+	}
+
+	dir := t.TempDir()
+	_, err := git.PlainInit(dir, false)
+	require.NoError(t, err)
+
+	commitAt(t, dir, time.Date(2023, time.December, 31, 12, 0, 0, 0, time.UTC))
+	commitAt(t, dir, day(1).Add(10*time.Hour))
+	commitAt(t, dir, day(1).Add(15*time.Hour))
+	commitAt(t, dir, day(3).Add(9*time.Hour))
+	commitAt(t, dir, day(9).Add(12*time.Hour))
+	commitAt(t, dir, day(10).Add(8*time.Hour))
+
+	t.Run("daily buckets", func(t *testing.T) {
+		activity, err := repo.GetCommitActivity(t.Context(), dir, registry.CommitActivityBucketDay, day(1), day(10))
+		require.NoError(t, err)
+		require.Len(t, activity, 9)
+
+		counts := map[int]int{1: 2, 3: 1, 9: 1}
+		for i, bucket := range activity {
+			assert.Equal(t, day(i+1), bucket.Start)
+			assert.Equal(t, counts[i+1], bucket.Count, bucket.Start)
+		}
+	})
+
+	t.Run("weekly buckets start on Monday", func(t *testing.T) {
+		activity, err := repo.GetCommitActivity(t.Context(), dir, registry.CommitActivityBucketWeek, day(1), day(10))
+		require.NoError(t, err)
+
+		assert.Equal(t, []registry.CommitActivity{
+			{Start: day(1), Count: 3},
+			{Start: day(8), Count: 1},
+		}, activity)
+	})
+
+	t.Run("empty repository has empty buckets", func(t *testing.T) {
+		emptyDir := t.TempDir()
+		_, err := git.PlainInit(emptyDir, true)
+		require.NoError(t, err)
+
+		activity, err := repo.GetCommitActivity(t.Context(), emptyDir, registry.CommitActivityBucketDay, day(1), day(3))
+		require.NoError(t, err)
+		assert.Equal(t, []registry.CommitActivity{{Start: day(1)}, {Start: day(2)}}, activity)
+	})
+}
+
+func TestPgRepository_GetUsersByEmails(t *testing.T) {
+	container := setupPgContainer(t)
+	defer func() {
+		err := container.Terminate(t.Context())
+		require.NoError(t, err)
+	}()
+
+	connString, err := container.ConnectionString(t.Context())
+	require.NoError(t, err)
+
+	repo, pool := setupTestRepository(t, connString)
+	defer pool.Close()
+
+	_, err = pool.Exec(t.Context(), `CREATE TABLE users (
+		id VARCHAR(36) PRIMARY KEY,
+		username VARCHAR(255) NOT NULL,
+		email VARCHAR(255) NOT NULL UNIQUE,
+		deleted_at TIMESTAMP WITH TIME ZONE
+	)`)
+	require.NoError(t, err)
+
+	_, err = pool.Exec(t.Context(), `INSERT INTO users (id, username, email, deleted_at) VALUES
+		('user-1', 'alice', 'Alice@Example.com', NULL),
+		('user-2', 'bob', 'bob@example.com', NULL),
+		('user-3', 'carol', 'carol@example.com', NOW())`)
+	require.NoError(t, err)
+
+	t.Run("matches emails case-insensitively and skips deleted users", func(t *testing.T) {
+		users, err := repo.GetUsersByEmails(t.Context(), []string{"alice@example.com", "carol@example.com", "dave@example.com"})
+		require.NoError(t, err)
+		require.Len(t, users, 1)
+		assert.Equal(t, "user-1", users[0].Id)
+		assert.Equal(t, "alice", users[0].Username)
+	})
+
+	t.Run("empty input", func(t *testing.T) {
+		users, err := repo.GetUsersByEmails(t.Context(), nil)
+		require.NoError(t, err)
+		assert.Empty(t, users)
+	})
+}
+
+func TestPgRepository_ReadReplicaRouting(t *testing.T) {
+	container := setupPgContainer(t)
+	defer func() {
+		err := container.Terminate(t.Context())
+		require.NoError(t, err)
+	}()
+
+	connString, err := container.ConnectionString(t.Context())
+	require.NoError(t, err)
+
+	// A second schema stands in for the replica; nothing replicates into it,
+	// so every row shows which pool served the query.
+	conn, err := pgx.Connect(t.Context(), connString)
+	require.NoError(t, err)
+	_, err = conn.Exec(t.Context(), "CREATE SCHEMA replica")
+	require.NoError(t, err)
+	require.NoError(t, conn.Close(t.Context()))
+
+	replicaConnString := connString + "search_path=replica"
+	createRepositoriesTable(t, connString)
+	createRepositoriesTable(t, replicaConnString)
+
+	repo, primaryPool := setupTestRepository(t, connString)
+	defer primaryPool.Close()
+
+	replicaPool, err := pgxpool.New(t.Context(), replicaConnString)
+	require.NoError(t, err)
+	defer replicaPool.Close()
+	repo.replicaPool = replicaPool
+
+	primaryRepo := createTestRepository(t, "primary-"+uuid.NewString())
+	require.NoError(t, repo.CreateRepository(t.Context(), primaryRepo))
+
+	replicaRepo := createTestRepository(t, "replica-"+uuid.NewString())
+	_, err = replicaPool.Exec(t.Context(),
+		`INSERT INTO repositories (id, name, created_by, path, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $5)`,
+		replicaRepo.Id, replicaRepo.Name, replicaRepo.CreatedBy, replicaRepo.Path, time.Now(),
+	)
+	require.NoError(t, err)
+
+	t.Run("reads use the replica", func(t *testing.T) {
+		found, err := repo.GetRepositoryById(t.Context(), replicaRepo.Id)
+		require.NoError(t, err)
+		assert.Equal(t, replicaRepo.Name, found.Name)
+
+		_, err = repo.GetRepositoryById(t.Context(), primaryRepo.Id)
+		require.ErrorIs(t, err, ErrRepositoryNotFound)
+
+		count, err := repo.GetRepositoriesCount(t.Context())
+		require.NoError(t, err)
+		assert.Equal(t, 1, count)
+	})
+
+	t.Run("writes use the primary", func(t *testing.T) {
+		var count int
+		err := primaryPool.QueryRow(t.Context(), "SELECT COUNT(*) FROM repositories WHERE id = $1", primaryRepo.Id).Scan(&count)
+		require.NoError(t, err)
+		assert.Equal(t, 1, count)
+
+		err = replicaPool.QueryRow(t.Context(), "SELECT COUNT(*) FROM repositories WHERE id = $1", primaryRepo.Id).Scan(&count)
+		require.NoError(t, err)
+		assert.Zero(t, count)
+	})
+
+	t.Run("reads marked for the primary skip the replica", func(t *testing.T) {
+		found, err := repo.GetRepositoryById(pgutil.WithPrimary(t.Context()), primaryRepo.Id)
+		require.NoError(t, err)
+		assert.Equal(t, primaryRepo.Name, found.Name)
+	})
+}
+
+func TestPgRepository_GetFileTree_MaxDepth(t *testing.T) {
+	testRepoPath := setupTestGitRepository(t, map[string]string{
+		"README.md":              "# Test",
+		"a/b/c/d/e/f/deep.proto": "syntax = \"proto3\";",
+		"a/b/c/shallow.proto":    "syntax = \"proto3\";",
+		"other/x/nested.proto":   "syntax = \"proto3\";",
+		"other/top.proto":        "syntax = \"proto3\";",
+	})
+
+	depthOf := func(nodes []*registryv1.FileTreeNode) int {
+		var walk func(nodes []*registryv1.FileTreeNode) int
+		walk = func(nodes []*registryv1.FileTreeNode) int {
+			depth := 0
+			for _, node := range nodes {
+				depth = max(depth, 1+walk(node.Children))
+			}
+			return depth
+		}
+		return walk(nodes)
+	}
+
+	t.Run("truncates directories at the configured depth", func(t *testing.T) {
+		repo := &PgRepository{tracer: noop.NewTracerProvider().Tracer("test"), maxFileTreeDepth: 3}
+
+		response, err := repo.GetFileTree(t.Context(), testRepoPath, "", nil, registry.FileTreePage{})
+		require.NoError(t, err)
+		assert.Equal(t, 3, depthOf(response.Nodes))
+		assert.Equal(t, []string{"a/b/c"}, response.TruncatedPaths)
+
+		a := response.Nodes[0]
+		require.Equal(t, "a", a.Name)
+		c := a.Children[0].Children[0]
+		assert.Equal(t, "a/b/c", c.Path)
+		assert.Empty(t, c.Children)
+	})
+
+	t.Run("depth is counted from the listed sub path", func(t *testing.T) {
+		repo := &PgRepository{tracer: noop.NewTracerProvider().Tracer("test"), maxFileTreeDepth: 2}
+
+		subPath := "a/b"
+		response, err := repo.GetFileTree(t.Context(), testRepoPath, "", &subPath, registry.FileTreePage{})
+		require.NoError(t, err)
+		assert.Equal(t, 2, depthOf(response.Nodes))
+		assert.Equal(t, []string{"a/b/c/d"}, response.TruncatedPaths)
+	})
+
+	t.Run("a depth of one lists only the top level", func(t *testing.T) {
+		repo := &PgRepository{tracer: noop.NewTracerProvider().Tracer("test"), maxFileTreeDepth: 1}
+
+		response, err := repo.GetFileTree(t.Context(), testRepoPath, "", nil, registry.FileTreePage{})
+		require.NoError(t, err)
+		assert.Equal(t, 1, depthOf(response.Nodes))
+		assert.Equal(t, []string{"a", "other"}, response.TruncatedPaths)
+	})
+
+	t.Run("nothing is truncated when the tree fits", func(t *testing.T) {
+		repo := &PgRepository{tracer: noop.NewTracerProvider().Tracer("test")}
+
+		response, err := repo.GetFileTree(t.Context(), testRepoPath, "", nil, registry.FileTreePage{})
+		require.NoError(t, err)
+		assert.Equal(t, 7, depthOf(response.Nodes))
+		assert.Empty(t, response.TruncatedPaths)
+	})
+}
+
+func TestPgRepository_GetCommitRange(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not installed")
+	}
+
+	repo := &PgRepository{
+		tracer: noop.NewTracerProvider().Tracer("test"),
+	}
+
+	gitIn := func(t *testing.T, dir string, args ...string) string {
+		t.Helper()
+
+		cmd := exec.Command("git", append([]string{"-c", "user.name=Test", "-c", "user.email=test@example.com"}, args...)...)
+		cmd.Dir = dir
+		out, err := cmd.CombinedOutput()
+		require.NoError(t, err, string(out))
+		return strings.TrimSpace(string(out))
+	}
+
+	dir := t.TempDir()
+	gitIn(t, dir, "init", "--quiet", "--initial-branch=main")
+	var hashes []string
+	for i := 1; i <= 5; i++ {
+		gitIn(t, dir, "commit", "--allow-empty", "--quiet", "-m", fmt.Sprintf("c%d", i), "-m", "body line")
+		hashes = append(hashes, gitIn(t, dir, "rev-parse", "HEAD"))
+		if i == 2 {
+			gitIn(t, dir, "tag", "v1.0.0")
+		}
+	}
+	gitIn(t, dir, "checkout", "--quiet", "--orphan", "lonely")
+	gitIn(t, dir, "commit", "--allow-empty", "--quiet", "-m", "l1")
+	gitIn(t, dir, "checkout", "--quiet", "main")
+
+	ids := func(commitRange *registry.CommitRange) []string {
+		var ids []string
+		for _, commit := range commitRange.Commits {
+			ids = append(ids, commit.Id)
+		}
+		return ids
+	}
+
+	t.Run("lists the commits between a tag and a branch newest first", func(t *testing.T) {
+		commitRange, err := repo.GetCommitRange(t.Context(), dir, "v1.0.0", "main", 100)
+		require.NoError(t, err)
+		assert.False(t, commitRange.Truncated)
+		assert.Equal(t, []string{hashes[4], hashes[3], hashes[2]}, ids(commitRange))
+
+		commit := commitRange.Commits[0]
+		assert.Equal(t, "c5\n\nbody line\n", commit.Message)
+		assert.Equal(t, "Test", commit.User.Username)
+		assert.Equal(t, "test@example.com", commit.User.Id)
+		assert.NotNil(t, commit.CommitedAt)
+	})
+
+	t.Run("accepts commit hashes", func(t *testing.T) {
+		commitRange, err := repo.GetCommitRange(t.Context(), dir, hashes[0], hashes[2], 100)
+		require.NoError(t, err)
+		assert.Equal(t, []string{hashes[2], hashes[1]}, ids(commitRange))
+	})
+
+	t.Run("an empty range has no commits", func(t *testing.T) {
+		commitRange, err := repo.GetCommitRange(t.Context(), dir, "main", "v1.0.0", 100)
+		require.NoError(t, err)
+		assert.Empty(t, commitRange.Commits)
+	})
+
+	t.Run("truncates at the limit", func(t *testing.T) {
+		commitRange, err := repo.GetCommitRange(t.Context(), dir, hashes[0], "main", 2)
+		require.NoError(t, err)
+		assert.True(t, commitRange.Truncated)
+		assert.Equal(t, []string{hashes[4], hashes[3]}, ids(commitRange))
+	})
+
+	t.Run("refs without common history are rejected", func(t *testing.T) {
+		_, err := repo.GetCommitRange(t.Context(), dir, "main", "lonely", 100)
+		assert.ErrorIs(t, err, registry.ErrUnrelatedRefs)
+	})
+
+	t.Run("unknown refs are not found", func(t *testing.T) {
+		_, err := repo.GetCommitRange(t.Context(), dir, "v9.9.9", "main", 100)
+		assert.Equal(t, connect.CodeNotFound, connect.CodeOf(err))
+	})
 }
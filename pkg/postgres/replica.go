@@ -0,0 +1,79 @@
+package postgres
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/exaring/otelpgx"
+	"github.com/jackc/pgx/v5/pgxpool"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+
+	"hasir-api/pkg/config"
+)
+
+type primaryContextKey struct{}
+
+// WithPrimary sends the reads made with ctx to the primary. Flows that read
+// what they just wrote, or decide a write from what they read, use it so
+// replication lag cannot hand them stale rows.
+func WithPrimary(ctx context.Context) context.Context {
+	return context.WithValue(ctx, primaryContextKey{}, true)
+}
+
+// ReadPool picks the pool for a read-only query: the replica when one is
+// configured and ctx has not asked for the primary.
+func ReadPool(ctx context.Context, primary, replica *pgxpool.Pool) *pgxpool.Pool {
+	if replica == nil {
+		return primary
+	}
+
+	if usePrimary, _ := ctx.Value(primaryContextKey{}).(bool); usePrimary {
+		return primary
+	}
+
+	return replica
+}
+
+// NewReplicaPool connects to the configured read replica. It returns a nil
+// pool when no replica is configured, which ReadPool treats as "use the
+// primary".
+func NewReplicaPool(cfg config.PostgresConfig, traceProvider *sdktrace.TracerProvider) (*pgxpool.Pool, error) {
+	if cfg.ReplicaConnectionString == "" {
+		return nil, nil
+	}
+
+	pgConfig, err := pgxpool.ParseConfig(cfg.ReplicaConnectionString)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse replica config: %w", err)
+	}
+
+	if err := ApplySchema(pgConfig, cfg.Schema); err != nil {
+		return nil, err
+	}
+
+	if traceProvider != nil {
+		pgConfig.ConnConfig.Tracer = otelpgx.NewTracer(
+			otelpgx.WithTracerProvider(traceProvider),
+			otelpgx.WithDisableConnectionDetailsInAttributes(),
+		)
+	}
+
+	pool, err := pgxpool.NewWithConfig(context.Background(), pgConfig)
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect replica: %w", err)
+	}
+
+	if traceProvider != nil {
+		if err := otelpgx.RecordStats(pool); err != nil {
+			pool.Close()
+			return nil, fmt.Errorf("unable to record replica stats: %w", err)
+		}
+	}
+
+	if err := pool.Ping(context.Background()); err != nil {
+		pool.Close()
+		return nil, fmt.Errorf("failed to ping replica: %w", err)
+	}
+
+	return pool, nil
+}
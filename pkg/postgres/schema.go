@@ -0,0 +1,52 @@
+package postgres
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+var schemaNamePattern = regexp.MustCompile(`^[a-z_][a-z0-9_]{0,62}$`)
+
+func ValidateSchema(schema string) error {
+	if !schemaNamePattern.MatchString(schema) {
+		return fmt.Errorf("invalid schema name %q: must match %s", schema, schemaNamePattern.String())
+	}
+
+	return nil
+}
+
+// ApplySchema points every pooled connection at schema via search_path so
+// queries resolve unqualified table names there. public stays on the path
+// behind it because extensions such as pg_trgm are installed there. An empty
+// schema keeps the server default.
+func ApplySchema(pgConfig *pgxpool.Config, schema string) error {
+	if schema == "" {
+		return nil
+	}
+
+	if err := ValidateSchema(schema); err != nil {
+		return err
+	}
+
+	sql := "SET search_path TO " + pgx.Identifier{schema}.Sanitize() + ", public"
+	afterConnect := pgConfig.AfterConnect
+	pgConfig.AfterConnect = func(ctx context.Context, conn *pgx.Conn) error {
+		if afterConnect != nil {
+			if err := afterConnect(ctx, conn); err != nil {
+				return err
+			}
+		}
+
+		if _, err := conn.Exec(ctx, sql); err != nil {
+			return fmt.Errorf("failed to set search_path: %w", err)
+		}
+
+		return nil
+	}
+
+	return nil
+}
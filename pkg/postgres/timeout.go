@@ -0,0 +1,31 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"connectrpc.com/connect"
+)
+
+var ErrQueryTimeout = connect.NewError(connect.CodeDeadlineExceeded, errors.New("database query timed out"))
+
+// WithQueryTimeout bounds ctx by timeout. A non-positive timeout leaves the
+// caller's deadline as the only limit.
+func WithQueryTimeout(ctx context.Context, timeout time.Duration) (context.Context, context.CancelFunc) {
+	if timeout <= 0 {
+		return context.WithCancel(ctx)
+	}
+
+	return context.WithTimeout(ctx, timeout)
+}
+
+// QueryError returns ErrQueryTimeout when ctx's deadline has passed, since the
+// query failure is then a consequence of the timeout, and err otherwise.
+func QueryError(ctx context.Context, err error) error {
+	if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+		return ErrQueryTimeout
+	}
+
+	return err
+}
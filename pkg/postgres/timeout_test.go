@@ -0,0 +1,91 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"connectrpc.com/connect"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	pgcontainer "github.com/testcontainers/testcontainers-go/modules/postgres"
+)
+
+func TestWithQueryTimeout(t *testing.T) {
+	t.Run("sets deadline", func(t *testing.T) {
+		ctx, cancel := WithQueryTimeout(t.Context(), time.Second)
+		defer cancel()
+
+		deadline, ok := ctx.Deadline()
+		require.True(t, ok)
+		assert.WithinDuration(t, time.Now().Add(time.Second), deadline, 100*time.Millisecond)
+	})
+
+	t.Run("non-positive timeout keeps caller deadline", func(t *testing.T) {
+		ctx, cancel := WithQueryTimeout(t.Context(), 0)
+		defer cancel()
+
+		_, ok := ctx.Deadline()
+		_, parentOk := t.Context().Deadline()
+		assert.Equal(t, parentOk, ok)
+	})
+}
+
+func TestQueryError(t *testing.T) {
+	queryErr := connect.NewError(connect.CodeInternal, errors.New("failed to query"))
+
+	t.Run("returns original error before deadline", func(t *testing.T) {
+		assert.Same(t, queryErr, QueryError(t.Context(), queryErr))
+	})
+
+	t.Run("returns deadline exceeded after deadline", func(t *testing.T) {
+		ctx, cancel := context.WithTimeout(t.Context(), time.Nanosecond)
+		defer cancel()
+		<-ctx.Done()
+
+		err := QueryError(ctx, queryErr)
+		assert.Equal(t, connect.CodeDeadlineExceeded, connect.CodeOf(err))
+	})
+
+	t.Run("keeps original error on cancellation", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(t.Context())
+		cancel()
+
+		assert.Same(t, queryErr, QueryError(ctx, queryErr))
+	})
+
+	t.Run("slow query hits timeout", func(t *testing.T) {
+		container, err := pgcontainer.Run(t.Context(),
+			"postgres:16-alpine",
+			pgcontainer.WithDatabase("test"),
+			pgcontainer.WithUsername("test"),
+			pgcontainer.WithPassword("test"),
+			pgcontainer.BasicWaitStrategies(),
+			pgcontainer.WithSQLDriver("pgx"),
+		)
+		require.NoError(t, err)
+		defer func() {
+			require.NoError(t, container.Terminate(t.Context()))
+		}()
+
+		connString, err := container.ConnectionString(t.Context())
+		require.NoError(t, err)
+
+		pool, err := pgxpool.New(t.Context(), connString)
+		require.NoError(t, err)
+		defer pool.Close()
+
+		ctx, cancel := WithQueryTimeout(t.Context(), 200*time.Millisecond)
+		defer cancel()
+
+		start := time.Now()
+		_, err = pool.Exec(ctx, "SELECT pg_sleep(5)")
+		require.Error(t, err)
+		assert.Less(t, time.Since(start), 5*time.Second)
+
+		err = QueryError(ctx, queryErr)
+		assert.Equal(t, connect.CodeDeadlineExceeded, connect.CodeOf(err))
+	})
+}
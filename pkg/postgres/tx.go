@@ -0,0 +1,48 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"connectrpc.com/connect"
+	"github.com/jackc/pgx/v5"
+	"go.opentelemetry.io/otel/trace"
+)
+
+type TxBeginner interface {
+	Begin(ctx context.Context) (pgx.Tx, error)
+}
+
+// WithTx runs fn inside a transaction, committing when fn returns nil and
+// rolling back when it returns an error or panics. A panic is converted into
+// an internal error so callers see a failed transaction rather than a crash.
+func WithTx(ctx context.Context, db TxBeginner, fn func(pgx.Tx) error) (err error) {
+	span := trace.SpanFromContext(ctx)
+
+	tx, err := db.Begin(ctx)
+	if err != nil {
+		span.RecordError(err)
+		return QueryError(ctx, connect.NewError(connect.CodeInternal, errors.New("failed to begin transaction")))
+	}
+
+	defer func() {
+		if recovered := recover(); recovered != nil {
+			_ = tx.Rollback(ctx)
+			err = connect.NewError(connect.CodeInternal, fmt.Errorf("transaction panicked: %v", recovered))
+			span.RecordError(err)
+		}
+	}()
+
+	if err = fn(tx); err != nil {
+		_ = tx.Rollback(ctx)
+		return err
+	}
+
+	if err = tx.Commit(ctx); err != nil {
+		span.RecordError(err)
+		return QueryError(ctx, connect.NewError(connect.CodeInternal, errors.New("failed to commit transaction")))
+	}
+
+	return nil
+}
@@ -0,0 +1,113 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"connectrpc.com/connect"
+	"github.com/jackc/pgx/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type fakeTx struct {
+	pgx.Tx
+	commitErr  error
+	committed  bool
+	rolledBack bool
+}
+
+func (tx *fakeTx) Commit(ctx context.Context) error {
+	if tx.commitErr != nil {
+		return tx.commitErr
+	}
+	tx.committed = true
+	return nil
+}
+
+func (tx *fakeTx) Rollback(ctx context.Context) error {
+	tx.rolledBack = true
+	return nil
+}
+
+type fakeBeginner struct {
+	tx       *fakeTx
+	beginErr error
+}
+
+func (b *fakeBeginner) Begin(ctx context.Context) (pgx.Tx, error) {
+	if b.beginErr != nil {
+		return nil, b.beginErr
+	}
+	return b.tx, nil
+}
+
+func TestWithTx(t *testing.T) {
+	t.Run("commits when fn succeeds", func(t *testing.T) {
+		tx := &fakeTx{}
+
+		err := WithTx(t.Context(), &fakeBeginner{tx: tx}, func(pgx.Tx) error {
+			return nil
+		})
+
+		require.NoError(t, err)
+		assert.True(t, tx.committed)
+		assert.False(t, tx.rolledBack)
+	})
+
+	t.Run("rolls back and returns fn error", func(t *testing.T) {
+		tx := &fakeTx{}
+		fnErr := connect.NewError(connect.CodeNotFound, errors.New("not found"))
+
+		err := WithTx(t.Context(), &fakeBeginner{tx: tx}, func(pgx.Tx) error {
+			return fnErr
+		})
+
+		assert.Same(t, fnErr, err)
+		assert.False(t, tx.committed)
+		assert.True(t, tx.rolledBack)
+	})
+
+	t.Run("recovers panic and rolls back", func(t *testing.T) {
+		tx := &fakeTx{}
+
+		var err error
+		require.NotPanics(t, func() {
+			err = WithTx(t.Context(), &fakeBeginner{tx: tx}, func(pgx.Tx) error {
+				panic("boom")
+			})
+		})
+
+		require.Error(t, err)
+		assert.Equal(t, connect.CodeInternal, connect.CodeOf(err))
+		assert.Contains(t, err.Error(), "boom")
+		assert.False(t, tx.committed)
+		assert.True(t, tx.rolledBack)
+	})
+
+	t.Run("returns error when begin fails", func(t *testing.T) {
+		called := false
+
+		err := WithTx(t.Context(), &fakeBeginner{beginErr: errors.New("connection closed")}, func(pgx.Tx) error {
+			called = true
+			return nil
+		})
+
+		require.Error(t, err)
+		assert.Equal(t, connect.CodeInternal, connect.CodeOf(err))
+		assert.False(t, called)
+	})
+
+	t.Run("returns error when commit fails", func(t *testing.T) {
+		tx := &fakeTx{commitErr: errors.New("serialization failure")}
+
+		err := WithTx(t.Context(), &fakeBeginner{tx: tx}, func(pgx.Tx) error {
+			return nil
+		})
+
+		require.Error(t, err)
+		assert.Equal(t, connect.CodeInternal, connect.CodeOf(err))
+		assert.False(t, tx.rolledBack)
+	})
+}
@@ -20,6 +20,7 @@ import (
 
 	"hasir-api/internal/user"
 	"hasir-api/pkg/config"
+	"hasir-api/pkg/postgres"
 )
 
 var (
@@ -27,13 +28,17 @@ var (
 	ErrIdentifierAlreadyExists = connect.NewError(connect.CodeAlreadyExists, errors.New("email already exists"))
 	ErrNoRows                  = connect.NewError(connect.CodeNotFound, errors.New("user not found"))
 	ErrRefreshTokenNotFound    = connect.NewError(connect.CodeNotFound, errors.New("refresh token not found"))
+	ErrTotpNotEnrolled         = connect.NewError(connect.CodeNotFound, errors.New("totp is not enrolled"))
+	ErrNoLoginAttempts         = connect.NewError(connect.CodeNotFound, errors.New("no failed login attempts"))
 	ErrInternalServer          = connect.NewError(connect.CodeInternal, errors.New("something went wrong"))
 	ErrUniqueViolationCode     = "23505"
 )
 
 type PgRepository struct {
 	connectionPool *pgxpool.Pool
+	replicaPool    *pgxpool.Pool
 	tracer         trace.Tracer
+	queryTimeout   time.Duration
 }
 
 func NewPgRepository(
@@ -46,6 +51,10 @@ func NewPgRepository(
 		zap.L().Fatal("failed to parse database config", zap.Error(err))
 	}
 
+	if err := postgres.ApplySchema(pgConfig, cfg.PostgresConfig.Schema); err != nil {
+		zap.L().Fatal("failed to apply database schema", zap.Error(err))
+	}
+
 	if traceProvider != nil {
 		pgConfig.ConnConfig.Tracer = otelpgx.NewTracer(
 			otelpgx.WithTracerProvider(traceProvider),
@@ -84,12 +93,28 @@ func NewPgRepository(
 		tracer = noop.NewTracerProvider().Tracer("UserPostgreSQLRepository")
 	}
 
+	replicaPool, err := postgres.NewReplicaPool(cfg.PostgresConfig, traceProvider)
+	if err != nil {
+		zap.L().Fatal("failed to connect read replica", zap.Error(err))
+	}
+
+	queryTimeout, err := cfg.PostgresConfig.GetQueryTimeout()
+	if err != nil {
+		zap.L().Fatal("failed to parse database query timeout", zap.Error(err))
+	}
+
 	return &PgRepository{
 		connectionPool: pgConnectionPool,
+		replicaPool:    replicaPool,
 		tracer:         tracer,
+		queryTimeout:   queryTimeout,
 	}
 }
 
+func (r *PgRepository) readPool(ctx context.Context) *pgxpool.Pool {
+	return postgres.ReadPool(ctx, r.connectionPool, r.replicaPool)
+}
+
 func (r *PgRepository) CreateUser(ctx context.Context, user *user.UserDTO) error {
 	var span trace.Span
 	ctx, span = r.tracer.Start(ctx, "CreateUser", trace.WithAttributes(attribute.KeyValue{
@@ -98,9 +123,12 @@ func (r *PgRepository) CreateUser(ctx context.Context, user *user.UserDTO) error
 	}))
 	defer span.End()
 
+	ctx, cancel := postgres.WithQueryTimeout(ctx, r.queryTimeout)
+	defer cancel()
+
 	connection, err := r.connectionPool.Acquire(ctx)
 	if err != nil {
-		return ErrFailedAcquireConnection
+		return postgres.QueryError(ctx, ErrFailedAcquireConnection)
 	}
 	defer connection.Release()
 
@@ -121,7 +149,7 @@ func (r *PgRepository) CreateUser(ctx context.Context, user *user.UserDTO) error
 			return ErrIdentifierAlreadyExists
 		}
 
-		return connect.NewError(connect.CodeInternal, errors.New("failed to execute insert user query"))
+		return postgres.QueryError(ctx, connect.NewError(connect.CodeInternal, errors.New("failed to execute insert user query")))
 	}
 
 	return nil
@@ -137,9 +165,12 @@ func (r *PgRepository) GetUserByEmail(ctx context.Context, email string) (*user.
 	))
 	defer span.End()
 
+	ctx, cancel := postgres.WithQueryTimeout(ctx, r.queryTimeout)
+	defer cancel()
+
 	connection, err := r.connectionPool.Acquire(ctx)
 	if err != nil {
-		return nil, ErrFailedAcquireConnection
+		return nil, postgres.QueryError(ctx, ErrFailedAcquireConnection)
 	}
 	defer connection.Release()
 
@@ -149,7 +180,7 @@ func (r *PgRepository) GetUserByEmail(ctx context.Context, email string) (*user.
 	rows, err = connection.Query(ctx, sql, email)
 	if err != nil {
 		span.RecordError(err)
-		return nil, ErrInternalServer
+		return nil, postgres.QueryError(ctx, ErrInternalServer)
 	}
 	defer rows.Close()
 
@@ -160,7 +191,7 @@ func (r *PgRepository) GetUserByEmail(ctx context.Context, email string) (*user.
 			return nil, ErrNoRows
 		}
 
-		return nil, connect.NewError(connect.CodeInternal, errors.New("failed to collect row"))
+		return nil, postgres.QueryError(ctx, connect.NewError(connect.CodeInternal, errors.New("failed to collect row")))
 	}
 
 	return &userDTO, nil
@@ -180,9 +211,12 @@ func (r *PgRepository) GetUsersByEmails(ctx context.Context, emails []string) (m
 		return make(map[string]*user.UserDTO), nil
 	}
 
-	connection, err := r.connectionPool.Acquire(ctx)
+	ctx, cancel := postgres.WithQueryTimeout(ctx, r.queryTimeout)
+	defer cancel()
+
+	connection, err := r.readPool(ctx).Acquire(ctx)
 	if err != nil {
-		return nil, ErrFailedAcquireConnection
+		return nil, postgres.QueryError(ctx, ErrFailedAcquireConnection)
 	}
 	defer connection.Release()
 
@@ -192,14 +226,14 @@ func (r *PgRepository) GetUsersByEmails(ctx context.Context, emails []string) (m
 	rows, err = connection.Query(ctx, sql, emails)
 	if err != nil {
 		span.RecordError(err)
-		return nil, ErrInternalServer
+		return nil, postgres.QueryError(ctx, ErrInternalServer)
 	}
 	defer rows.Close()
 
 	users, err := pgx.CollectRows[user.UserDTO](rows, pgx.RowToStructByName)
 	if err != nil {
 		span.RecordError(err)
-		return nil, connect.NewError(connect.CodeInternal, errors.New("failed to collect rows"))
+		return nil, postgres.QueryError(ctx, connect.NewError(connect.CodeInternal, errors.New("failed to collect rows")))
 	}
 
 	userMap := make(map[string]*user.UserDTO, len(users))
@@ -220,9 +254,12 @@ func (r *PgRepository) GetUserById(ctx context.Context, id string) (*user.UserDT
 	))
 	defer span.End()
 
+	ctx, cancel := postgres.WithQueryTimeout(ctx, r.queryTimeout)
+	defer cancel()
+
 	connection, err := r.connectionPool.Acquire(ctx)
 	if err != nil {
-		return nil, ErrFailedAcquireConnection
+		return nil, postgres.QueryError(ctx, ErrFailedAcquireConnection)
 	}
 	defer connection.Release()
 
@@ -232,7 +269,7 @@ func (r *PgRepository) GetUserById(ctx context.Context, id string) (*user.UserDT
 	rows, err = connection.Query(ctx, sql, id)
 	if err != nil {
 		span.RecordError(err)
-		return nil, ErrInternalServer
+		return nil, postgres.QueryError(ctx, ErrInternalServer)
 	}
 	defer rows.Close()
 
@@ -243,12 +280,54 @@ func (r *PgRepository) GetUserById(ctx context.Context, id string) (*user.UserDT
 			return nil, ErrNoRows
 		}
 
-		return nil, connect.NewError(connect.CodeInternal, errors.New("failed to collect row"))
+		return nil, postgres.QueryError(ctx, connect.NewError(connect.CodeInternal, errors.New("failed to collect row")))
 	}
 
 	return &userDTO, nil
 }
 
+func (r *PgRepository) GetOrganizationMemberships(ctx context.Context, userId string) ([]user.OrganizationMembershipDTO, error) {
+	var span trace.Span
+	ctx, span = r.tracer.Start(ctx, "GetOrganizationMemberships", trace.WithAttributes(
+		attribute.KeyValue{
+			Key:   "userId",
+			Value: attribute.StringValue(userId),
+		},
+	))
+	defer span.End()
+
+	ctx, cancel := postgres.WithQueryTimeout(ctx, r.queryTimeout)
+	defer cancel()
+
+	connection, err := r.connectionPool.Acquire(ctx)
+	if err != nil {
+		return nil, postgres.QueryError(ctx, ErrFailedAcquireConnection)
+	}
+	defer connection.Release()
+
+	sql := `
+		SELECT om.organization_id, o.slug, o.display_name, om.role, om.joined_at
+		FROM organization_members om
+		JOIN organizations o ON o.id = om.organization_id
+		WHERE om.user_id = $1 AND o.deleted_at IS NULL
+		ORDER BY o.slug
+	`
+
+	rows, err := connection.Query(ctx, sql, userId)
+	if err != nil {
+		span.RecordError(err)
+		return nil, postgres.QueryError(ctx, ErrInternalServer)
+	}
+
+	memberships, err := pgx.CollectRows(rows, pgx.RowToStructByName[user.OrganizationMembershipDTO])
+	if err != nil {
+		span.RecordError(err)
+		return nil, postgres.QueryError(ctx, ErrInternalServer)
+	}
+
+	return memberships, nil
+}
+
 func (r *PgRepository) CreateRefreshToken(ctx context.Context, id, token string, expiresAt time.Time) error {
 	var span trace.Span
 	ctx, span = r.tracer.Start(ctx, "CreateRefreshToken", trace.WithAttributes(
@@ -263,9 +342,12 @@ func (r *PgRepository) CreateRefreshToken(ctx context.Context, id, token string,
 	))
 	defer span.End()
 
+	ctx, cancel := postgres.WithQueryTimeout(ctx, r.queryTimeout)
+	defer cancel()
+
 	connection, err := r.connectionPool.Acquire(ctx)
 	if err != nil {
-		return ErrFailedAcquireConnection
+		return postgres.QueryError(ctx, ErrFailedAcquireConnection)
 	}
 	defer connection.Release()
 
@@ -279,7 +361,7 @@ func (r *PgRepository) CreateRefreshToken(ctx context.Context, id, token string,
 
 	if _, err = connection.Exec(ctx, sql, sqlArgs); err != nil {
 		span.RecordError(err)
-		return ErrInternalServer
+		return postgres.QueryError(ctx, ErrInternalServer)
 	}
 
 	return nil
@@ -295,9 +377,12 @@ func (r *PgRepository) GetRefreshTokenByTokenId(ctx context.Context, token strin
 	))
 	defer span.End()
 
+	ctx, cancel := postgres.WithQueryTimeout(ctx, r.queryTimeout)
+	defer cancel()
+
 	connection, err := r.connectionPool.Acquire(ctx)
 	if err != nil {
-		return nil, ErrFailedAcquireConnection
+		return nil, postgres.QueryError(ctx, ErrFailedAcquireConnection)
 	}
 	defer connection.Release()
 
@@ -307,7 +392,7 @@ func (r *PgRepository) GetRefreshTokenByTokenId(ctx context.Context, token strin
 	rows, err = connection.Query(ctx, sql, token)
 	if err != nil {
 		span.RecordError(err)
-		return nil, ErrInternalServer
+		return nil, postgres.QueryError(ctx, ErrInternalServer)
 	}
 	defer rows.Close()
 
@@ -318,7 +403,7 @@ func (r *PgRepository) GetRefreshTokenByTokenId(ctx context.Context, token strin
 			return nil, ErrRefreshTokenNotFound
 		}
 
-		return nil, connect.NewError(connect.CodeInternal, errors.New("failed to collect row"))
+		return nil, postgres.QueryError(ctx, connect.NewError(connect.CodeInternal, errors.New("failed to collect row")))
 	}
 
 	return &refreshToken, nil
@@ -338,9 +423,12 @@ func (r *PgRepository) DeleteRefreshToken(ctx context.Context, userId, token str
 	))
 	defer span.End()
 
+	ctx, cancel := postgres.WithQueryTimeout(ctx, r.queryTimeout)
+	defer cancel()
+
 	connection, err := r.connectionPool.Acquire(ctx)
 	if err != nil {
-		return ErrFailedAcquireConnection
+		return postgres.QueryError(ctx, ErrFailedAcquireConnection)
 	}
 	defer connection.Release()
 
@@ -352,7 +440,7 @@ func (r *PgRepository) DeleteRefreshToken(ctx context.Context, userId, token str
 
 	if _, err = connection.Exec(ctx, sql, sqlArgs); err != nil {
 		span.RecordError(err)
-		return ErrInternalServer
+		return postgres.QueryError(ctx, ErrInternalServer)
 	}
 
 	return nil
@@ -372,9 +460,12 @@ func (r *PgRepository) UpdateUserById(ctx context.Context, id string, user *user
 	))
 	defer span.End()
 
+	ctx, cancel := postgres.WithQueryTimeout(ctx, r.queryTimeout)
+	defer cancel()
+
 	connection, err := r.connectionPool.Acquire(ctx)
 	if err != nil {
-		return ErrFailedAcquireConnection
+		return postgres.QueryError(ctx, ErrFailedAcquireConnection)
 	}
 	defer connection.Release()
 
@@ -403,7 +494,7 @@ func (r *PgRepository) UpdateUserById(ctx context.Context, id string, user *user
 			if errors.Is(err, pgx.ErrNoRows) {
 				return ErrNoRows
 			}
-			return connect.NewError(connect.CodeInternal, errors.New("failed to verify user existence"))
+			return postgres.QueryError(ctx, connect.NewError(connect.CodeInternal, errors.New("failed to verify user existence")))
 		}
 		return nil
 	}
@@ -423,7 +514,7 @@ func (r *PgRepository) UpdateUserById(ctx context.Context, id string, user *user
 			return err
 		}
 
-		return connect.NewError(connect.CodeInternal, errors.New("failed to execute update user query"))
+		return postgres.QueryError(ctx, connect.NewError(connect.CodeInternal, errors.New("failed to execute update user query")))
 	}
 
 	if result.RowsAffected() == 0 {
@@ -443,9 +534,12 @@ func (r *PgRepository) DeleteUser(ctx context.Context, userId string) error {
 	))
 	defer span.End()
 
+	ctx, cancel := postgres.WithQueryTimeout(ctx, r.queryTimeout)
+	defer cancel()
+
 	connection, err := r.connectionPool.Acquire(ctx)
 	if err != nil {
-		return ErrFailedAcquireConnection
+		return postgres.QueryError(ctx, ErrFailedAcquireConnection)
 	}
 	defer connection.Release()
 
@@ -457,7 +551,7 @@ func (r *PgRepository) DeleteUser(ctx context.Context, userId string) error {
 
 	if _, err = connection.Exec(ctx, sql, sqlArgs); err != nil {
 		span.RecordError(err)
-		return ErrInternalServer
+		return postgres.QueryError(ctx, ErrInternalServer)
 	}
 
 	return nil
@@ -468,9 +562,12 @@ func (r *PgRepository) CreateApiKey(ctx context.Context, userId, name, apiKey st
 	ctx, span = r.tracer.Start(ctx, "CreateApiKey")
 	defer span.End()
 
+	ctx, cancel := postgres.WithQueryTimeout(ctx, r.queryTimeout)
+	defer cancel()
+
 	connection, err := r.connectionPool.Acquire(ctx)
 	if err != nil {
-		return ErrFailedAcquireConnection
+		return postgres.QueryError(ctx, ErrFailedAcquireConnection)
 	}
 	defer connection.Release()
 
@@ -493,7 +590,7 @@ func (r *PgRepository) CreateApiKey(ctx context.Context, userId, name, apiKey st
 		if errors.As(err, &pgErr) && pgErr.Code == ErrUniqueViolationCode {
 			return connect.NewError(connect.CodeAlreadyExists, errors.New("api key already exists"))
 		}
-		return ErrInternalServer
+		return postgres.QueryError(ctx, ErrInternalServer)
 	}
 
 	return nil
@@ -504,9 +601,12 @@ func (r *PgRepository) GetApiKeys(ctx context.Context, userId string, page, page
 	ctx, span = r.tracer.Start(ctx, "GetApiKeys")
 	defer span.End()
 
-	connection, err := r.connectionPool.Acquire(ctx)
+	ctx, cancel := postgres.WithQueryTimeout(ctx, r.queryTimeout)
+	defer cancel()
+
+	connection, err := r.readPool(ctx).Acquire(ctx)
 	if err != nil {
-		return nil, ErrFailedAcquireConnection
+		return nil, postgres.QueryError(ctx, ErrFailedAcquireConnection)
 	}
 	defer connection.Release()
 
@@ -523,7 +623,7 @@ func (r *PgRepository) GetApiKeys(ctx context.Context, userId string, page, page
 	rows, err := connection.Query(ctx, sql, userId, pageSize, offset)
 	if err != nil {
 		span.RecordError(err)
-		return nil, ErrInternalServer
+		return nil, postgres.QueryError(ctx, ErrInternalServer)
 	}
 	defer rows.Close()
 
@@ -539,14 +639,14 @@ func (r *PgRepository) GetApiKeys(ctx context.Context, userId string, page, page
 		)
 		if err != nil {
 			span.RecordError(err)
-			return nil, ErrInternalServer
+			return nil, postgres.QueryError(ctx, ErrInternalServer)
 		}
 		apiKeys = append(apiKeys, key)
 	}
 
 	if err = rows.Err(); err != nil {
 		span.RecordError(err)
-		return nil, ErrInternalServer
+		return nil, postgres.QueryError(ctx, ErrInternalServer)
 	}
 
 	return &apiKeys, nil
@@ -557,9 +657,12 @@ func (r *PgRepository) GetApiKeysCount(ctx context.Context, userId string) (int,
 	ctx, span = r.tracer.Start(ctx, "GetApiKeysCount")
 	defer span.End()
 
-	connection, err := r.connectionPool.Acquire(ctx)
+	ctx, cancel := postgres.WithQueryTimeout(ctx, r.queryTimeout)
+	defer cancel()
+
+	connection, err := r.readPool(ctx).Acquire(ctx)
 	if err != nil {
-		return 0, ErrFailedAcquireConnection
+		return 0, postgres.QueryError(ctx, ErrFailedAcquireConnection)
 	}
 	defer connection.Release()
 
@@ -573,7 +676,7 @@ func (r *PgRepository) GetApiKeysCount(ctx context.Context, userId string) (int,
 	err = connection.QueryRow(ctx, sql, userId).Scan(&count)
 	if err != nil {
 		span.RecordError(err)
-		return 0, ErrInternalServer
+		return 0, postgres.QueryError(ctx, ErrInternalServer)
 	}
 
 	return count, nil
@@ -584,9 +687,12 @@ func (r *PgRepository) RevokeApiKey(ctx context.Context, userId, keyId string) e
 	ctx, span = r.tracer.Start(ctx, "RevokeApiKey")
 	defer span.End()
 
+	ctx, cancel := postgres.WithQueryTimeout(ctx, r.queryTimeout)
+	defer cancel()
+
 	connection, err := r.connectionPool.Acquire(ctx)
 	if err != nil {
-		return ErrFailedAcquireConnection
+		return postgres.QueryError(ctx, ErrFailedAcquireConnection)
 	}
 	defer connection.Release()
 
@@ -606,7 +712,7 @@ func (r *PgRepository) RevokeApiKey(ctx context.Context, userId, keyId string) e
 
 	if err != nil {
 		span.RecordError(err)
-		return ErrInternalServer
+		return postgres.QueryError(ctx, ErrInternalServer)
 	}
 
 	if result.RowsAffected() == 0 {
@@ -621,26 +727,32 @@ func (r *PgRepository) CreateSshKey(ctx context.Context, userId, name, publicKey
 	ctx, span = r.tracer.Start(ctx, "CreateSshKey")
 	defer span.End()
 
+	ctx, cancel := postgres.WithQueryTimeout(ctx, r.queryTimeout)
+	defer cancel()
+
 	connection, err := r.connectionPool.Acquire(ctx)
 	if err != nil {
-		return ErrFailedAcquireConnection
+		return postgres.QueryError(ctx, ErrFailedAcquireConnection)
 	}
 	defer connection.Release()
 
-	var activeKeyId string
+	var activeUserId string
 	activeCheckSQL := `
-		SELECT id
+		SELECT user_id
 		FROM ssh_keys
 		WHERE public_key = $1 AND deleted_at IS NULL
 		LIMIT 1
 	`
-	err = connection.QueryRow(ctx, activeCheckSQL, publicKey).Scan(&activeKeyId)
+	err = connection.QueryRow(ctx, activeCheckSQL, publicKey).Scan(&activeUserId)
 	if err == nil {
-		return connect.NewError(connect.CodeAlreadyExists, errors.New("ssh key already exists"))
+		if activeUserId == userId {
+			return connect.NewError(connect.CodeAlreadyExists, errors.New("ssh key already exists"))
+		}
+		return connect.NewError(connect.CodeAlreadyExists, errors.New("ssh key already exists for another user"))
 	}
 	if !errors.Is(err, pgx.ErrNoRows) {
 		span.RecordError(err)
-		return ErrInternalServer
+		return postgres.QueryError(ctx, ErrInternalServer)
 	}
 
 	var existingKeyId string
@@ -662,7 +774,7 @@ func (r *PgRepository) CreateSshKey(ctx context.Context, userId, name, publicKey
 			result, err := connection.Exec(ctx, restoreSQL, name, existingKeyId)
 			if err != nil {
 				span.RecordError(err)
-				return ErrInternalServer
+				return postgres.QueryError(ctx, ErrInternalServer)
 			}
 			if result.RowsAffected() == 0 {
 				return nil
@@ -674,7 +786,7 @@ func (r *PgRepository) CreateSshKey(ctx context.Context, userId, name, publicKey
 	}
 	if !errors.Is(err, pgx.ErrNoRows) {
 		span.RecordError(err)
-		return ErrInternalServer
+		return postgres.QueryError(ctx, ErrInternalServer)
 	}
 
 	sql := `
@@ -697,7 +809,7 @@ func (r *PgRepository) CreateSshKey(ctx context.Context, userId, name, publicKey
 		if errors.As(err, &pgErr) && pgErr.Code == ErrUniqueViolationCode {
 			return connect.NewError(connect.CodeAlreadyExists, errors.New("ssh key already exists"))
 		}
-		return ErrInternalServer
+		return postgres.QueryError(ctx, ErrInternalServer)
 	}
 
 	return nil
@@ -708,16 +820,19 @@ func (r *PgRepository) GetSshKeys(ctx context.Context, userId string, page, page
 	ctx, span = r.tracer.Start(ctx, "GetSshKeys")
 	defer span.End()
 
-	connection, err := r.connectionPool.Acquire(ctx)
+	ctx, cancel := postgres.WithQueryTimeout(ctx, r.queryTimeout)
+	defer cancel()
+
+	connection, err := r.readPool(ctx).Acquire(ctx)
 	if err != nil {
-		return nil, ErrFailedAcquireConnection
+		return nil, postgres.QueryError(ctx, ErrFailedAcquireConnection)
 	}
 	defer connection.Release()
 
 	offset := (page - 1) * pageSize
 
 	sql := `
-		SELECT id, user_id, name, public_key, created_at
+		SELECT id, user_id, name, public_key, created_at, last_used_at
 		FROM ssh_keys
 		WHERE user_id = $1 AND deleted_at IS NULL
 		ORDER BY created_at DESC
@@ -727,7 +842,7 @@ func (r *PgRepository) GetSshKeys(ctx context.Context, userId string, page, page
 	rows, err := connection.Query(ctx, sql, userId, pageSize, offset)
 	if err != nil {
 		span.RecordError(err)
-		return nil, ErrInternalServer
+		return nil, postgres.QueryError(ctx, ErrInternalServer)
 	}
 	defer rows.Close()
 
@@ -740,17 +855,18 @@ func (r *PgRepository) GetSshKeys(ctx context.Context, userId string, page, page
 			&key.Name,
 			&key.PublicKey,
 			&key.CreatedAt,
+			&key.LastUsedAt,
 		)
 		if err != nil {
 			span.RecordError(err)
-			return nil, ErrInternalServer
+			return nil, postgres.QueryError(ctx, ErrInternalServer)
 		}
 		sshKeys = append(sshKeys, key)
 	}
 
 	if err = rows.Err(); err != nil {
 		span.RecordError(err)
-		return nil, ErrInternalServer
+		return nil, postgres.QueryError(ctx, ErrInternalServer)
 	}
 
 	return &sshKeys, nil
@@ -761,9 +877,12 @@ func (r *PgRepository) GetSshKeysCount(ctx context.Context, userId string) (int,
 	ctx, span = r.tracer.Start(ctx, "GetSshKeysCount")
 	defer span.End()
 
-	connection, err := r.connectionPool.Acquire(ctx)
+	ctx, cancel := postgres.WithQueryTimeout(ctx, r.queryTimeout)
+	defer cancel()
+
+	connection, err := r.readPool(ctx).Acquire(ctx)
 	if err != nil {
-		return 0, ErrFailedAcquireConnection
+		return 0, postgres.QueryError(ctx, ErrFailedAcquireConnection)
 	}
 	defer connection.Release()
 
@@ -777,7 +896,7 @@ func (r *PgRepository) GetSshKeysCount(ctx context.Context, userId string) (int,
 	err = connection.QueryRow(ctx, sql, userId).Scan(&count)
 	if err != nil {
 		span.RecordError(err)
-		return 0, ErrInternalServer
+		return 0, postgres.QueryError(ctx, ErrInternalServer)
 	}
 
 	return count, nil
@@ -788,9 +907,12 @@ func (r *PgRepository) RevokeSshKey(ctx context.Context, userId, keyId string) e
 	ctx, span = r.tracer.Start(ctx, "RevokeSshKey")
 	defer span.End()
 
+	ctx, cancel := postgres.WithQueryTimeout(ctx, r.queryTimeout)
+	defer cancel()
+
 	connection, err := r.connectionPool.Acquire(ctx)
 	if err != nil {
-		return ErrFailedAcquireConnection
+		return postgres.QueryError(ctx, ErrFailedAcquireConnection)
 	}
 	defer connection.Release()
 
@@ -810,7 +932,7 @@ func (r *PgRepository) RevokeSshKey(ctx context.Context, userId, keyId string) e
 
 	if err != nil {
 		span.RecordError(err)
-		return ErrInternalServer
+		return postgres.QueryError(ctx, ErrInternalServer)
 	}
 
 	if result.RowsAffected() == 0 {
@@ -820,14 +942,50 @@ func (r *PgRepository) RevokeSshKey(ctx context.Context, userId, keyId string) e
 	return nil
 }
 
+func (r *PgRepository) MarkSshKeyUsed(ctx context.Context, publicKey string, usedAt time.Time) error {
+	var span trace.Span
+	ctx, span = r.tracer.Start(ctx, "MarkSshKeyUsed")
+	defer span.End()
+
+	ctx, cancel := postgres.WithQueryTimeout(ctx, r.queryTimeout)
+	defer cancel()
+
+	connection, err := r.connectionPool.Acquire(ctx)
+	if err != nil {
+		return postgres.QueryError(ctx, ErrFailedAcquireConnection)
+	}
+	defer connection.Release()
+
+	sql := `
+		UPDATE ssh_keys
+		SET last_used_at = $1
+		WHERE public_key = $2 AND deleted_at IS NULL
+	`
+
+	result, err := connection.Exec(ctx, sql, usedAt, publicKey)
+	if err != nil {
+		span.RecordError(err)
+		return postgres.QueryError(ctx, ErrInternalServer)
+	}
+
+	if result.RowsAffected() == 0 {
+		return connect.NewError(connect.CodeNotFound, errors.New("ssh key not found"))
+	}
+
+	return nil
+}
+
 func (r *PgRepository) GetUserBySshPublicKey(ctx context.Context, publicKey string) (*user.UserDTO, error) {
 	var span trace.Span
 	ctx, span = r.tracer.Start(ctx, "GetUserBySshPublicKey")
 	defer span.End()
 
+	ctx, cancel := postgres.WithQueryTimeout(ctx, r.queryTimeout)
+	defer cancel()
+
 	connection, err := r.connectionPool.Acquire(ctx)
 	if err != nil {
-		return nil, ErrFailedAcquireConnection
+		return nil, postgres.QueryError(ctx, ErrFailedAcquireConnection)
 	}
 	defer connection.Release()
 
@@ -841,17 +999,23 @@ func (r *PgRepository) GetUserBySshPublicKey(ctx context.Context, publicKey stri
 	rows, err := connection.Query(ctx, sql, publicKey)
 	if err != nil {
 		span.RecordError(err)
-		return nil, ErrInternalServer
+		return nil, postgres.QueryError(ctx, ErrInternalServer)
 	}
 	defer rows.Close()
 
+	// public_key is unique, so a second row means the constraint is gone and
+	// the key cannot be trusted to identify anyone.
 	var userDTO user.UserDTO
-	userDTO, err = pgx.CollectOneRow[user.UserDTO](rows, pgx.RowToStructByName)
+	userDTO, err = pgx.CollectExactlyOneRow[user.UserDTO](rows, pgx.RowToStructByName)
 	if err != nil {
 		if errors.Is(err, pgx.ErrNoRows) {
 			return nil, connect.NewError(connect.CodeNotFound, errors.New("ssh key not found"))
 		}
-		return nil, connect.NewError(connect.CodeInternal, errors.New("failed to collect row"))
+		if errors.Is(err, pgx.ErrTooManyRows) {
+			span.RecordError(err)
+			return nil, connect.NewError(connect.CodeFailedPrecondition, errors.New("ssh key is registered to more than one user"))
+		}
+		return nil, postgres.QueryError(ctx, connect.NewError(connect.CodeInternal, errors.New("failed to collect row")))
 	}
 
 	return &userDTO, nil
@@ -862,9 +1026,12 @@ func (r *PgRepository) GetUserByApiKey(ctx context.Context, apiKey string) (*use
 	ctx, span = r.tracer.Start(ctx, "GetUserByApiKey")
 	defer span.End()
 
+	ctx, cancel := postgres.WithQueryTimeout(ctx, r.queryTimeout)
+	defer cancel()
+
 	connection, err := r.connectionPool.Acquire(ctx)
 	if err != nil {
-		return nil, ErrFailedAcquireConnection
+		return nil, postgres.QueryError(ctx, ErrFailedAcquireConnection)
 	}
 	defer connection.Release()
 
@@ -878,7 +1045,7 @@ func (r *PgRepository) GetUserByApiKey(ctx context.Context, apiKey string) (*use
 	rows, err := connection.Query(ctx, sql, apiKey)
 	if err != nil {
 		span.RecordError(err)
-		return nil, ErrInternalServer
+		return nil, postgres.QueryError(ctx, ErrInternalServer)
 	}
 	defer rows.Close()
 
@@ -888,7 +1055,7 @@ func (r *PgRepository) GetUserByApiKey(ctx context.Context, apiKey string) (*use
 		if errors.Is(err, pgx.ErrNoRows) {
 			return nil, connect.NewError(connect.CodeNotFound, errors.New("api key not found"))
 		}
-		return nil, connect.NewError(connect.CodeInternal, errors.New("failed to collect row"))
+		return nil, postgres.QueryError(ctx, connect.NewError(connect.CodeInternal, errors.New("failed to collect row")))
 	}
 
 	return &userDTO, nil
@@ -904,9 +1071,12 @@ func (r *PgRepository) CreatePasswordResetToken(ctx context.Context, userId, tok
 	))
 	defer span.End()
 
+	ctx, cancel := postgres.WithQueryTimeout(ctx, r.queryTimeout)
+	defer cancel()
+
 	connection, err := r.connectionPool.Acquire(ctx)
 	if err != nil {
-		return ErrFailedAcquireConnection
+		return postgres.QueryError(ctx, ErrFailedAcquireConnection)
 	}
 	defer connection.Release()
 
@@ -929,7 +1099,7 @@ func (r *PgRepository) CreatePasswordResetToken(ctx context.Context, userId, tok
 		if errors.As(err, &pgErr) && pgErr.Code == ErrUniqueViolationCode {
 			return connect.NewError(connect.CodeAlreadyExists, errors.New("reset token already exists"))
 		}
-		return ErrInternalServer
+		return postgres.QueryError(ctx, ErrInternalServer)
 	}
 
 	return nil
@@ -945,9 +1115,12 @@ func (r *PgRepository) GetPasswordResetToken(ctx context.Context, token string)
 	))
 	defer span.End()
 
+	ctx, cancel := postgres.WithQueryTimeout(ctx, r.queryTimeout)
+	defer cancel()
+
 	connection, err := r.connectionPool.Acquire(ctx)
 	if err != nil {
-		return nil, ErrFailedAcquireConnection
+		return nil, postgres.QueryError(ctx, ErrFailedAcquireConnection)
 	}
 	defer connection.Release()
 
@@ -960,7 +1133,7 @@ func (r *PgRepository) GetPasswordResetToken(ctx context.Context, token string)
 	rows, err := connection.Query(ctx, sql, token)
 	if err != nil {
 		span.RecordError(err)
-		return nil, ErrInternalServer
+		return nil, postgres.QueryError(ctx, ErrInternalServer)
 	}
 	defer rows.Close()
 
@@ -970,7 +1143,7 @@ func (r *PgRepository) GetPasswordResetToken(ctx context.Context, token string)
 		if errors.Is(err, pgx.ErrNoRows) {
 			return nil, connect.NewError(connect.CodeNotFound, errors.New("reset token not found"))
 		}
-		return nil, connect.NewError(connect.CodeInternal, errors.New("failed to collect row"))
+		return nil, postgres.QueryError(ctx, connect.NewError(connect.CodeInternal, errors.New("failed to collect row")))
 	}
 
 	return &resetToken, nil
@@ -986,9 +1159,12 @@ func (r *PgRepository) MarkPasswordResetTokenAsUsed(ctx context.Context, token s
 	))
 	defer span.End()
 
+	ctx, cancel := postgres.WithQueryTimeout(ctx, r.queryTimeout)
+	defer cancel()
+
 	connection, err := r.connectionPool.Acquire(ctx)
 	if err != nil {
-		return ErrFailedAcquireConnection
+		return postgres.QueryError(ctx, ErrFailedAcquireConnection)
 	}
 	defer connection.Release()
 
@@ -1006,7 +1182,7 @@ func (r *PgRepository) MarkPasswordResetTokenAsUsed(ctx context.Context, token s
 	)
 	if err != nil {
 		span.RecordError(err)
-		return ErrInternalServer
+		return postgres.QueryError(ctx, ErrInternalServer)
 	}
 
 	if result.RowsAffected() == 0 {
@@ -1015,3 +1191,284 @@ func (r *PgRepository) MarkPasswordResetTokenAsUsed(ctx context.Context, token s
 
 	return nil
 }
+
+// SetTotpSecret starts (or restarts) enrollment: the new secret replaces any
+// previous one and stays disabled until EnableTotp.
+func (r *PgRepository) SetTotpSecret(ctx context.Context, userId, secret string) error {
+	var span trace.Span
+	ctx, span = r.tracer.Start(ctx, "SetTotpSecret", trace.WithAttributes(
+		attribute.KeyValue{
+			Key:   "userId",
+			Value: attribute.StringValue(userId),
+		},
+	))
+	defer span.End()
+
+	ctx, cancel := postgres.WithQueryTimeout(ctx, r.queryTimeout)
+	defer cancel()
+
+	connection, err := r.connectionPool.Acquire(ctx)
+	if err != nil {
+		return postgres.QueryError(ctx, ErrFailedAcquireConnection)
+	}
+	defer connection.Release()
+
+	sql := `
+		INSERT INTO user_totp (user_id, secret, enabled_at, created_at)
+		VALUES ($1, $2, NULL, $3)
+		ON CONFLICT (user_id) DO UPDATE
+		SET secret = EXCLUDED.secret, enabled_at = NULL, created_at = EXCLUDED.created_at
+	`
+
+	if _, err = connection.Exec(ctx, sql, userId, secret, time.Now().UTC()); err != nil {
+		span.RecordError(err)
+		return postgres.QueryError(ctx, ErrInternalServer)
+	}
+
+	return nil
+}
+
+func (r *PgRepository) GetTotpSettings(ctx context.Context, userId string) (*user.TotpSettingsDTO, error) {
+	var span trace.Span
+	ctx, span = r.tracer.Start(ctx, "GetTotpSettings", trace.WithAttributes(
+		attribute.KeyValue{
+			Key:   "userId",
+			Value: attribute.StringValue(userId),
+		},
+	))
+	defer span.End()
+
+	ctx, cancel := postgres.WithQueryTimeout(ctx, r.queryTimeout)
+	defer cancel()
+
+	connection, err := r.connectionPool.Acquire(ctx)
+	if err != nil {
+		return nil, postgres.QueryError(ctx, ErrFailedAcquireConnection)
+	}
+	defer connection.Release()
+
+	sql := "SELECT user_id, secret, enabled_at, last_used_step, created_at FROM user_totp WHERE user_id = $1"
+
+	rows, err := connection.Query(ctx, sql, userId)
+	if err != nil {
+		span.RecordError(err)
+		return nil, postgres.QueryError(ctx, ErrInternalServer)
+	}
+
+	settings, err := pgx.CollectOneRow(rows, pgx.RowToStructByName[user.TotpSettingsDTO])
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, ErrTotpNotEnrolled
+		}
+
+		span.RecordError(err)
+		return nil, postgres.QueryError(ctx, ErrInternalServer)
+	}
+
+	return &settings, nil
+}
+
+func (r *PgRepository) EnableTotp(ctx context.Context, userId string, enabledAt time.Time) error {
+	var span trace.Span
+	ctx, span = r.tracer.Start(ctx, "EnableTotp", trace.WithAttributes(
+		attribute.KeyValue{
+			Key:   "userId",
+			Value: attribute.StringValue(userId),
+		},
+	))
+	defer span.End()
+
+	ctx, cancel := postgres.WithQueryTimeout(ctx, r.queryTimeout)
+	defer cancel()
+
+	connection, err := r.connectionPool.Acquire(ctx)
+	if err != nil {
+		return postgres.QueryError(ctx, ErrFailedAcquireConnection)
+	}
+	defer connection.Release()
+
+	sql := "UPDATE user_totp SET enabled_at = $2 WHERE user_id = $1 AND enabled_at IS NULL"
+
+	result, err := connection.Exec(ctx, sql, userId, enabledAt)
+	if err != nil {
+		span.RecordError(err)
+		return postgres.QueryError(ctx, ErrInternalServer)
+	}
+
+	if result.RowsAffected() == 0 {
+		return ErrTotpNotEnrolled
+	}
+
+	return nil
+}
+
+// UseTotpStep records step as the last accepted TOTP time step. It reports
+// false, recording nothing, when a code from step or a later one was already
+// accepted, so concurrent requests cannot both use the same code.
+func (r *PgRepository) UseTotpStep(ctx context.Context, userId string, step int64) (bool, error) {
+	var span trace.Span
+	ctx, span = r.tracer.Start(ctx, "UseTotpStep", trace.WithAttributes(
+		attribute.KeyValue{
+			Key:   "userId",
+			Value: attribute.StringValue(userId),
+		},
+	))
+	defer span.End()
+
+	ctx, cancel := postgres.WithQueryTimeout(ctx, r.queryTimeout)
+	defer cancel()
+
+	connection, err := r.connectionPool.Acquire(ctx)
+	if err != nil {
+		return false, postgres.QueryError(ctx, ErrFailedAcquireConnection)
+	}
+	defer connection.Release()
+
+	sql := `UPDATE user_totp SET last_used_step = $2
+		WHERE user_id = $1 AND (last_used_step IS NULL OR last_used_step < $2)`
+
+	result, err := connection.Exec(ctx, sql, userId, step)
+	if err != nil {
+		span.RecordError(err)
+		return false, postgres.QueryError(ctx, ErrInternalServer)
+	}
+
+	return result.RowsAffected() > 0, nil
+}
+
+func (r *PgRepository) GetLoginAttempts(ctx context.Context, userId string) (*user.LoginAttemptsDTO, error) {
+	var span trace.Span
+	ctx, span = r.tracer.Start(ctx, "GetLoginAttempts", trace.WithAttributes(
+		attribute.KeyValue{
+			Key:   "userId",
+			Value: attribute.StringValue(userId),
+		},
+	))
+	defer span.End()
+
+	ctx, cancel := postgres.WithQueryTimeout(ctx, r.queryTimeout)
+	defer cancel()
+
+	connection, err := r.connectionPool.Acquire(ctx)
+	if err != nil {
+		return nil, postgres.QueryError(ctx, ErrFailedAcquireConnection)
+	}
+	defer connection.Release()
+
+	sql := "SELECT user_id, failed_count, last_failed_at, locked_until FROM login_attempts WHERE user_id = $1"
+
+	rows, err := connection.Query(ctx, sql, userId)
+	if err != nil {
+		span.RecordError(err)
+		return nil, postgres.QueryError(ctx, ErrInternalServer)
+	}
+
+	attempts, err := pgx.CollectOneRow(rows, pgx.RowToStructByName[user.LoginAttemptsDTO])
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, ErrNoLoginAttempts
+		}
+
+		span.RecordError(err)
+		return nil, postgres.QueryError(ctx, ErrInternalServer)
+	}
+
+	return &attempts, nil
+}
+
+// RecordFailedLogin counts one more failed login and returns the number of
+// consecutive failures. The increment happens in the database, so concurrent
+// attempts from several instances are all counted.
+func (r *PgRepository) RecordFailedLogin(ctx context.Context, userId string, failedAt time.Time) (int, error) {
+	var span trace.Span
+	ctx, span = r.tracer.Start(ctx, "RecordFailedLogin", trace.WithAttributes(
+		attribute.KeyValue{
+			Key:   "userId",
+			Value: attribute.StringValue(userId),
+		},
+	))
+	defer span.End()
+
+	ctx, cancel := postgres.WithQueryTimeout(ctx, r.queryTimeout)
+	defer cancel()
+
+	connection, err := r.connectionPool.Acquire(ctx)
+	if err != nil {
+		return 0, postgres.QueryError(ctx, ErrFailedAcquireConnection)
+	}
+	defer connection.Release()
+
+	sql := `
+		INSERT INTO login_attempts (user_id, failed_count, last_failed_at)
+		VALUES ($1, 1, $2)
+		ON CONFLICT (user_id) DO UPDATE
+		SET failed_count = login_attempts.failed_count + 1, last_failed_at = EXCLUDED.last_failed_at
+		RETURNING failed_count
+	`
+
+	var failedCount int
+	if err = connection.QueryRow(ctx, sql, userId, failedAt).Scan(&failedCount); err != nil {
+		span.RecordError(err)
+		return 0, postgres.QueryError(ctx, ErrInternalServer)
+	}
+
+	return failedCount, nil
+}
+
+func (r *PgRepository) LockAccount(ctx context.Context, userId string, lockedUntil time.Time) error {
+	var span trace.Span
+	ctx, span = r.tracer.Start(ctx, "LockAccount", trace.WithAttributes(
+		attribute.KeyValue{
+			Key:   "userId",
+			Value: attribute.StringValue(userId),
+		},
+	))
+	defer span.End()
+
+	ctx, cancel := postgres.WithQueryTimeout(ctx, r.queryTimeout)
+	defer cancel()
+
+	connection, err := r.connectionPool.Acquire(ctx)
+	if err != nil {
+		return postgres.QueryError(ctx, ErrFailedAcquireConnection)
+	}
+	defer connection.Release()
+
+	sql := "UPDATE login_attempts SET locked_until = $2 WHERE user_id = $1"
+
+	if _, err = connection.Exec(ctx, sql, userId, lockedUntil); err != nil {
+		span.RecordError(err)
+		return postgres.QueryError(ctx, ErrInternalServer)
+	}
+
+	return nil
+}
+
+func (r *PgRepository) ResetLoginAttempts(ctx context.Context, userId string) error {
+	var span trace.Span
+	ctx, span = r.tracer.Start(ctx, "ResetLoginAttempts", trace.WithAttributes(
+		attribute.KeyValue{
+			Key:   "userId",
+			Value: attribute.StringValue(userId),
+		},
+	))
+	defer span.End()
+
+	ctx, cancel := postgres.WithQueryTimeout(ctx, r.queryTimeout)
+	defer cancel()
+
+	connection, err := r.connectionPool.Acquire(ctx)
+	if err != nil {
+		return postgres.QueryError(ctx, ErrFailedAcquireConnection)
+	}
+	defer connection.Release()
+
+	sql := "DELETE FROM login_attempts WHERE user_id = $1"
+
+	if _, err = connection.Exec(ctx, sql, userId); err != nil {
+		span.RecordError(err)
+		return postgres.QueryError(ctx, ErrInternalServer)
+	}
+
+	return nil
+}
@@ -4,6 +4,7 @@ import (
 	"testing"
 	"time"
 
+	"connectrpc.com/connect"
 	"github.com/google/uuid"
 	"github.com/jackc/pgx/v5"
 	"github.com/stretchr/testify/assert"
@@ -591,6 +592,75 @@ func setupPgContainer(t *testing.T) *postgres.PostgresContainer {
 	return postgresContainer
 }
 
+func TestPgRepository_GetOrganizationMemberships(t *testing.T) {
+	container := setupPgContainer(t)
+	defer func() {
+		err := container.Terminate(t.Context())
+		require.NoError(t, err)
+	}()
+
+	connString, err := container.ConnectionString(t.Context())
+	require.NoError(t, err)
+
+	createUserTable(t, connString)
+	createFakeUser(t, connString)
+
+	conn, err := pgx.Connect(t.Context(), connString)
+	require.NoError(t, err)
+	defer func() {
+		_ = conn.Close(t.Context())
+	}()
+
+	_, err = conn.Exec(t.Context(), `
+		CREATE TABLE organizations (id varchar primary key, slug varchar not null, display_name varchar not null, deleted_at timestamp);
+		CREATE TABLE organization_members (id varchar primary key, organization_id varchar not null, user_id varchar not null, role varchar not null, joined_at timestamp not null);
+		INSERT INTO organizations (id, slug, display_name, deleted_at) VALUES
+			('org-b', 'beta', 'Beta', NULL),
+			('org-a', 'alpha', 'Alpha', NULL),
+			('org-gone', 'gone', 'Gone', NOW());
+	`)
+	require.NoError(t, err)
+
+	for _, member := range []struct{ orgId, userId, role string }{
+		{"org-b", fakeId, "reader"},
+		{"org-a", fakeId, "owner"},
+		{"org-gone", fakeId, "owner"},
+		{"org-a", "someone-else", "author"},
+	} {
+		_, err = conn.Exec(t.Context(),
+			"INSERT INTO organization_members (id, organization_id, user_id, role, joined_at) VALUES ($1, $2, $3, $4, NOW())",
+			uuid.NewString(), member.orgId, member.userId, member.role)
+		require.NoError(t, err)
+	}
+
+	traceProvider := sdktrace.NewTracerProvider()
+	pgRepository := NewPgRepository(&config.Config{
+		PostgresConfig: config.PostgresConfig{
+			ConnectionString: connString,
+		},
+	}, traceProvider)
+
+	t.Run("lists live organizations ordered by slug", func(t *testing.T) {
+		memberships, err := pgRepository.GetOrganizationMemberships(t.Context(), fakeId)
+
+		require.NoError(t, err)
+		require.Len(t, memberships, 2)
+		assert.Equal(t, "org-a", memberships[0].OrganizationId)
+		assert.Equal(t, "alpha", memberships[0].Slug)
+		assert.Equal(t, "Alpha", memberships[0].DisplayName)
+		assert.Equal(t, "owner", memberships[0].Role)
+		assert.Equal(t, "org-b", memberships[1].OrganizationId)
+		assert.Equal(t, "reader", memberships[1].Role)
+	})
+
+	t.Run("user without organizations", func(t *testing.T) {
+		memberships, err := pgRepository.GetOrganizationMemberships(t.Context(), uuid.NewString())
+
+		require.NoError(t, err)
+		assert.Empty(t, memberships)
+	})
+}
+
 func TestPgRepository_UpdateUserById(t *testing.T) {
 	t.Run("happy path - update all fields", func(t *testing.T) {
 		container := setupPgContainer(t)
@@ -1508,6 +1578,51 @@ func TestPgRepository_CreateSshKey(t *testing.T) {
 		assert.Error(t, err)
 		assert.Contains(t, err.Error(), "already exists for another user")
 	})
+
+	t.Run("rejects a key active for a different user", func(t *testing.T) {
+		container := setupPgContainer(t)
+		defer func() {
+			err := container.Terminate(t.Context())
+			require.NoError(t, err)
+		}()
+
+		connString, err := container.ConnectionString(t.Context())
+		require.NoError(t, err)
+
+		createUserTable(t, connString)
+		createFakeUser(t, connString)
+		createSshKeysTable(t, connString)
+
+		conn, err := pgx.Connect(t.Context(), connString)
+		require.NoError(t, err)
+		defer func() {
+			_ = conn.Close(t.Context())
+		}()
+
+		otherUserId := uuid.NewString()
+		_, err = conn.Exec(t.Context(),
+			"INSERT INTO users (id, username, email, password, created_at) VALUES ($1, $2, $3, $4, $5)",
+			otherUserId, "otheruser", "other@example.com", "hashed", time.Now().UTC())
+		require.NoError(t, err)
+
+		publicKey := "ssh-rsa AAAAB3NzaC1yc2EAAAADAQABAAABshared"
+		createFakeSshKey(t, connString, otherUserId, uuid.NewString(), publicKey)
+
+		traceProvider := sdktrace.NewTracerProvider()
+		pgRepository := NewPgRepository(&config.Config{
+			PostgresConfig: config.PostgresConfig{
+				ConnectionString: connString,
+			},
+		}, traceProvider)
+
+		err = pgRepository.CreateSshKey(t.Context(), fakeId, "stolen-key", publicKey)
+		assert.Equal(t, connect.CodeAlreadyExists, connect.CodeOf(err))
+		assert.Contains(t, err.Error(), "already exists for another user")
+
+		owner, err := pgRepository.GetUserBySshPublicKey(t.Context(), publicKey)
+		require.NoError(t, err)
+		assert.Equal(t, otherUserId, owner.Id)
+	})
 }
 
 func TestPgRepository_GetSshKeys(t *testing.T) {
@@ -1551,6 +1666,80 @@ func TestPgRepository_GetSshKeys(t *testing.T) {
 	})
 }
 
+func TestPgRepository_MarkSshKeyUsed(t *testing.T) {
+	t.Run("advances last used time", func(t *testing.T) {
+		container := setupPgContainer(t)
+		defer func() {
+			err := container.Terminate(t.Context())
+			require.NoError(t, err)
+		}()
+
+		connString, err := container.ConnectionString(t.Context())
+		require.NoError(t, err)
+
+		createUserTable(t, connString)
+		createFakeUser(t, connString)
+		createSshKeysTable(t, connString)
+		keyId := uuid.NewString()
+		keyValue := "ssh-ed25519 key..."
+		createFakeSshKey(t, connString, fakeId, keyId, keyValue)
+
+		traceProvider := sdktrace.NewTracerProvider()
+		pgRepository := NewPgRepository(&config.Config{
+			PostgresConfig: config.PostgresConfig{
+				ConnectionString: connString,
+			},
+		}, traceProvider)
+
+		keys, err := pgRepository.GetSshKeys(t.Context(), fakeId, 1, 10)
+		require.NoError(t, err)
+		require.Len(t, *keys, 1)
+		assert.Nil(t, (*keys)[0].LastUsedAt)
+
+		firstUse := time.Now().UTC().Add(-time.Hour).Truncate(time.Microsecond)
+		err = pgRepository.MarkSshKeyUsed(t.Context(), keyValue, firstUse)
+		require.NoError(t, err)
+
+		keys, err = pgRepository.GetSshKeys(t.Context(), fakeId, 1, 10)
+		require.NoError(t, err)
+		require.NotNil(t, (*keys)[0].LastUsedAt)
+		assert.True(t, firstUse.Equal(*(*keys)[0].LastUsedAt))
+
+		secondUse := time.Now().UTC().Truncate(time.Microsecond)
+		err = pgRepository.MarkSshKeyUsed(t.Context(), keyValue, secondUse)
+		require.NoError(t, err)
+
+		keys, err = pgRepository.GetSshKeys(t.Context(), fakeId, 1, 10)
+		require.NoError(t, err)
+		require.NotNil(t, (*keys)[0].LastUsedAt)
+		assert.True(t, (*keys)[0].LastUsedAt.After(firstUse))
+	})
+
+	t.Run("returns not found for unknown key", func(t *testing.T) {
+		container := setupPgContainer(t)
+		defer func() {
+			err := container.Terminate(t.Context())
+			require.NoError(t, err)
+		}()
+
+		connString, err := container.ConnectionString(t.Context())
+		require.NoError(t, err)
+
+		createUserTable(t, connString)
+		createSshKeysTable(t, connString)
+
+		traceProvider := sdktrace.NewTracerProvider()
+		pgRepository := NewPgRepository(&config.Config{
+			PostgresConfig: config.PostgresConfig{
+				ConnectionString: connString,
+			},
+		}, traceProvider)
+
+		err = pgRepository.MarkSshKeyUsed(t.Context(), "ssh-ed25519 unknown...", time.Now().UTC())
+		require.Error(t, err)
+	})
+}
+
 func TestPgRepository_RevokeSshKey(t *testing.T) {
 	t.Run("marks SSH key as deleted", func(t *testing.T) {
 		container := setupPgContainer(t)
@@ -1686,8 +1875,9 @@ func createSshKeysTable(t *testing.T, connString string) {
 			id varchar PRIMARY KEY,
 			user_id varchar NOT NULL,
 			name varchar NOT NULL,
-			public_key text NOT NULL,
+			public_key text NOT NULL UNIQUE,
 			created_at timestamp NOT NULL,
+			last_used_at timestamp,
 			deleted_at timestamp
 		);
 	`
@@ -2193,3 +2383,156 @@ func createFakePasswordResetToken(t *testing.T, connString, userId, token string
 	)
 	require.NoError(t, err)
 }
+
+func TestPgRepository_Totp(t *testing.T) {
+	container := setupPgContainer(t)
+	defer func() {
+		err := container.Terminate(t.Context())
+		require.NoError(t, err)
+	}()
+
+	connString, err := container.ConnectionString(t.Context())
+	require.NoError(t, err)
+
+	createUserTable(t, connString)
+	createFakeUser(t, connString)
+
+	conn, err := pgx.Connect(t.Context(), connString)
+	require.NoError(t, err)
+	defer func() {
+		_ = conn.Close(t.Context())
+	}()
+
+	_, err = conn.Exec(t.Context(), `
+		CREATE TABLE user_totp (
+			user_id varchar primary key,
+			secret text not null,
+			enabled_at timestamptz,
+			last_used_step bigint,
+			created_at timestamptz not null
+		)
+	`)
+	require.NoError(t, err)
+
+	traceProvider := sdktrace.NewTracerProvider()
+	pgRepository := NewPgRepository(&config.Config{
+		PostgresConfig: config.PostgresConfig{
+			ConnectionString: connString,
+		},
+	}, traceProvider)
+
+	t.Run("not enrolled", func(t *testing.T) {
+		_, err := pgRepository.GetTotpSettings(t.Context(), fakeId)
+		assert.Equal(t, connect.CodeNotFound, connect.CodeOf(err))
+
+		err = pgRepository.EnableTotp(t.Context(), fakeId, time.Now().UTC())
+		assert.Equal(t, connect.CodeNotFound, connect.CodeOf(err))
+	})
+
+	t.Run("enroll, enable and re-enroll", func(t *testing.T) {
+		require.NoError(t, pgRepository.SetTotpSecret(t.Context(), fakeId, "sealed-1"))
+
+		settings, err := pgRepository.GetTotpSettings(t.Context(), fakeId)
+		require.NoError(t, err)
+		assert.Equal(t, "sealed-1", settings.Secret)
+		assert.Nil(t, settings.EnabledAt)
+
+		require.NoError(t, pgRepository.EnableTotp(t.Context(), fakeId, time.Now().UTC()))
+
+		settings, err = pgRepository.GetTotpSettings(t.Context(), fakeId)
+		require.NoError(t, err)
+		assert.NotNil(t, settings.EnabledAt)
+
+		require.NoError(t, pgRepository.SetTotpSecret(t.Context(), fakeId, "sealed-2"))
+
+		settings, err = pgRepository.GetTotpSettings(t.Context(), fakeId)
+		require.NoError(t, err)
+		assert.Equal(t, "sealed-2", settings.Secret)
+		assert.Nil(t, settings.EnabledAt, "a new secret needs verifying again")
+	})
+
+	t.Run("each time step is used once", func(t *testing.T) {
+		require.NoError(t, pgRepository.SetTotpSecret(t.Context(), fakeId, "sealed-3"))
+
+		fresh, err := pgRepository.UseTotpStep(t.Context(), fakeId, 100)
+		require.NoError(t, err)
+		assert.True(t, fresh)
+
+		fresh, err = pgRepository.UseTotpStep(t.Context(), fakeId, 100)
+		require.NoError(t, err)
+		assert.False(t, fresh, "the same step is a replay")
+
+		fresh, err = pgRepository.UseTotpStep(t.Context(), fakeId, 99)
+		require.NoError(t, err)
+		assert.False(t, fresh, "an older step is a replay too")
+
+		fresh, err = pgRepository.UseTotpStep(t.Context(), fakeId, 101)
+		require.NoError(t, err)
+		assert.True(t, fresh)
+
+		settings, err := pgRepository.GetTotpSettings(t.Context(), fakeId)
+		require.NoError(t, err)
+		require.NotNil(t, settings.LastUsedStep)
+		assert.Equal(t, int64(101), *settings.LastUsedStep)
+	})
+}
+
+func TestPgRepository_LoginAttempts(t *testing.T) {
+	container := setupPgContainer(t)
+	defer func() {
+		err := container.Terminate(t.Context())
+		require.NoError(t, err)
+	}()
+
+	connString, err := container.ConnectionString(t.Context())
+	require.NoError(t, err)
+
+	createUserTable(t, connString)
+	createFakeUser(t, connString)
+
+	conn, err := pgx.Connect(t.Context(), connString)
+	require.NoError(t, err)
+	defer func() {
+		_ = conn.Close(t.Context())
+	}()
+
+	_, err = conn.Exec(t.Context(), `
+		CREATE TABLE login_attempts (
+			user_id varchar primary key,
+			failed_count integer not null default 0,
+			last_failed_at timestamptz not null,
+			locked_until timestamptz
+		)
+	`)
+	require.NoError(t, err)
+
+	traceProvider := sdktrace.NewTracerProvider()
+	pgRepository := NewPgRepository(&config.Config{
+		PostgresConfig: config.PostgresConfig{
+			ConnectionString: connString,
+		},
+	}, traceProvider)
+
+	_, err = pgRepository.GetLoginAttempts(t.Context(), fakeId)
+	assert.Equal(t, connect.CodeNotFound, connect.CodeOf(err))
+
+	for expected := 1; expected <= 3; expected++ {
+		count, err := pgRepository.RecordFailedLogin(t.Context(), fakeId, time.Now().UTC())
+		require.NoError(t, err)
+		assert.Equal(t, expected, count)
+	}
+
+	lockedUntil := time.Now().UTC().Add(time.Minute).Truncate(time.Microsecond)
+	require.NoError(t, pgRepository.LockAccount(t.Context(), fakeId, lockedUntil))
+
+	attempts, err := pgRepository.GetLoginAttempts(t.Context(), fakeId)
+	require.NoError(t, err)
+	assert.Equal(t, 3, attempts.FailedCount)
+	require.NotNil(t, attempts.LockedUntil)
+	assert.True(t, lockedUntil.Equal(*attempts.LockedUntil))
+
+	require.NoError(t, pgRepository.ResetLoginAttempts(t.Context(), fakeId))
+
+	_, err = pgRepository.GetLoginAttempts(t.Context(), fakeId)
+	assert.Equal(t, connect.CodeNotFound, connect.CodeOf(err))
+}
@@ -0,0 +1,123 @@
+package sshserver
+
+import (
+	"net"
+	"sync"
+	"time"
+
+	"github.com/gliderlabs/ssh"
+	"go.uber.org/zap"
+
+	"hasir-api/pkg/config"
+)
+
+type Limits struct {
+	IdleTimeout      time.Duration
+	MaxTimeout       time.Duration
+	HandshakeTimeout time.Duration
+	MaxSessions      int
+}
+
+func LimitsFromConfig(cfg config.SshConfig) (Limits, error) {
+	idleTimeout, err := cfg.GetIdleTimeout()
+	if err != nil {
+		return Limits{}, err
+	}
+
+	maxTimeout, err := cfg.GetMaxTimeout()
+	if err != nil {
+		return Limits{}, err
+	}
+
+	handshakeTimeout, err := cfg.GetHandshakeTimeout()
+	if err != nil {
+		return Limits{}, err
+	}
+
+	return Limits{
+		IdleTimeout:      idleTimeout,
+		MaxTimeout:       maxTimeout,
+		HandshakeTimeout: handshakeTimeout,
+		MaxSessions:      cfg.GetMaxSessions(),
+	}, nil
+}
+
+// Apply wires the limits into the server. Connections beyond MaxSessions are
+// closed before the SSH handshake starts, and connections that have not
+// completed the handshake within HandshakeTimeout are dropped.
+func Apply(server *ssh.Server, limits Limits) {
+	server.IdleTimeout = limits.IdleTimeout
+	server.MaxTimeout = limits.MaxTimeout
+
+	var slots chan struct{}
+	if limits.MaxSessions > 0 {
+		slots = make(chan struct{}, limits.MaxSessions)
+	}
+
+	previousCallback := server.ConnCallback
+	server.ConnCallback = func(ctx ssh.Context, conn net.Conn) net.Conn {
+		if previousCallback != nil {
+			conn = previousCallback(ctx, conn)
+			if conn == nil {
+				return nil
+			}
+		}
+
+		limitedConn := &limitedConn{Conn: conn, closed: make(chan struct{})}
+
+		if slots != nil {
+			select {
+			case slots <- struct{}{}:
+				limitedConn.release = func() { <-slots }
+			default:
+				zap.L().Warn("SSH connection rejected, max sessions reached",
+					zap.String("remoteAddr", conn.RemoteAddr().String()),
+					zap.Int("maxSessions", limits.MaxSessions),
+				)
+				return nil
+			}
+		}
+
+		if limits.HandshakeTimeout > 0 {
+			go limitedConn.enforceHandshakeTimeout(ctx, limits.HandshakeTimeout)
+		}
+
+		return limitedConn
+	}
+}
+
+type limitedConn struct {
+	net.Conn
+
+	release   func()
+	closed    chan struct{}
+	closeOnce sync.Once
+}
+
+func (c *limitedConn) enforceHandshakeTimeout(ctx ssh.Context, timeout time.Duration) {
+	timer := time.NewTimer(timeout)
+	defer timer.Stop()
+
+	select {
+	case <-timer.C:
+		if ctx.Value(ssh.ContextKeyConn) == nil {
+			zap.L().Debug("SSH handshake timed out",
+				zap.String("remoteAddr", c.RemoteAddr().String()),
+			)
+			_ = c.Close()
+		}
+	case <-c.closed:
+	}
+}
+
+func (c *limitedConn) Close() error {
+	err := c.Conn.Close()
+	c.closeOnce.Do(func() {
+		close(c.closed)
+		if c.release != nil {
+			c.release()
+		}
+	})
+
+	return err
+}
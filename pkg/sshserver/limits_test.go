@@ -0,0 +1,151 @@
+package sshserver
+
+import (
+	"errors"
+	"io"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/gliderlabs/ssh"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	gossh "golang.org/x/crypto/ssh"
+
+	"hasir-api/pkg/config"
+)
+
+func startTestServer(t *testing.T, limits Limits) string {
+	t.Helper()
+
+	server := &ssh.Server{
+		Handler: func(session ssh.Session) {
+			<-session.Context().Done()
+		},
+	}
+	Apply(server, limits)
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+
+	go func() {
+		_ = server.Serve(listener)
+	}()
+	t.Cleanup(func() {
+		_ = server.Close()
+	})
+
+	return listener.Addr().String()
+}
+
+func dialTestClient(addr string) (*gossh.Client, error) {
+	return gossh.Dial("tcp", addr, &gossh.ClientConfig{
+		User:            "git",
+		HostKeyCallback: gossh.InsecureIgnoreHostKey(), // #nosec G106 -- test server
+		Timeout:         2 * time.Second,
+	})
+}
+
+func TestLimitsFromConfig(t *testing.T) {
+	t.Run("defaults when not configured", func(t *testing.T) {
+		limits, err := LimitsFromConfig(config.SshConfig{})
+		require.NoError(t, err)
+
+		assert.Equal(t, 10*time.Minute, limits.IdleTimeout)
+		assert.Equal(t, time.Duration(0), limits.MaxTimeout)
+		assert.Equal(t, 30*time.Second, limits.HandshakeTimeout)
+		assert.Equal(t, 100, limits.MaxSessions)
+	})
+
+	t.Run("configured values", func(t *testing.T) {
+		limits, err := LimitsFromConfig(config.SshConfig{
+			IdleTimeout:      "5m",
+			MaxTimeout:       "1h",
+			HandshakeTimeout: "10s",
+			MaxSessions:      20,
+		})
+		require.NoError(t, err)
+
+		assert.Equal(t, 5*time.Minute, limits.IdleTimeout)
+		assert.Equal(t, time.Hour, limits.MaxTimeout)
+		assert.Equal(t, 10*time.Second, limits.HandshakeTimeout)
+		assert.Equal(t, 20, limits.MaxSessions)
+	})
+
+	t.Run("invalid duration", func(t *testing.T) {
+		_, err := LimitsFromConfig(config.SshConfig{IdleTimeout: "soon"})
+		require.Error(t, err)
+	})
+}
+
+func TestApply(t *testing.T) {
+	t.Run("idle connection is closed after timeout", func(t *testing.T) {
+		addr := startTestServer(t, Limits{IdleTimeout: 200 * time.Millisecond})
+
+		client, err := dialTestClient(addr)
+		require.NoError(t, err)
+		defer func() {
+			_ = client.Close()
+		}()
+
+		closed := make(chan struct{})
+		go func() {
+			_ = client.Wait()
+			close(closed)
+		}()
+
+		select {
+		case <-closed:
+		case <-time.After(3 * time.Second):
+			t.Fatal("expected idle connection to be closed")
+		}
+	})
+
+	t.Run("connections over max sessions are refused", func(t *testing.T) {
+		addr := startTestServer(t, Limits{MaxSessions: 1})
+
+		first, err := dialTestClient(addr)
+		require.NoError(t, err)
+		defer func() {
+			_ = first.Close()
+		}()
+
+		_, err = dialTestClient(addr)
+		require.Error(t, err)
+	})
+
+	t.Run("slot is released when connection closes", func(t *testing.T) {
+		addr := startTestServer(t, Limits{MaxSessions: 1})
+
+		first, err := dialTestClient(addr)
+		require.NoError(t, err)
+		require.NoError(t, first.Close())
+
+		require.Eventually(t, func() bool {
+			client, err := dialTestClient(addr)
+			if err != nil {
+				return false
+			}
+			_ = client.Close()
+			return true
+		}, 3*time.Second, 50*time.Millisecond)
+	})
+
+	t.Run("connection without handshake is dropped", func(t *testing.T) {
+		addr := startTestServer(t, Limits{HandshakeTimeout: 200 * time.Millisecond})
+
+		conn, err := net.Dial("tcp", addr)
+		require.NoError(t, err)
+		defer func() {
+			_ = conn.Close()
+		}()
+
+		require.NoError(t, conn.SetReadDeadline(time.Now().Add(3*time.Second)))
+		_, err = io.ReadAll(conn)
+
+		var netErr net.Error
+		if errors.As(err, &netErr) && netErr.Timeout() {
+			t.Fatal("expected server to close the connection before the read deadline")
+		}
+	})
+}
@@ -0,0 +1,106 @@
+package main
+
+import (
+	"context"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/gliderlabs/ssh"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.uber.org/zap"
+
+	"hasir-api/pkg/config"
+)
+
+type shutdownTimeouts struct {
+	http  time.Duration
+	ssh   time.Duration
+	queue time.Duration
+	trace time.Duration
+}
+
+func shutdownTimeoutsFromConfig(cfg config.ShutdownConfig) (shutdownTimeouts, error) {
+	var timeouts shutdownTimeouts
+	var err error
+	if timeouts.http, err = cfg.GetHttpTimeout(); err != nil {
+		return shutdownTimeouts{}, err
+	}
+	if timeouts.ssh, err = cfg.GetSshTimeout(); err != nil {
+		return shutdownTimeouts{}, err
+	}
+	if timeouts.queue, err = cfg.GetQueueTimeout(); err != nil {
+		return shutdownTimeouts{}, err
+	}
+	if timeouts.trace, err = cfg.GetTraceTimeout(); err != nil {
+		return shutdownTimeouts{}, err
+	}
+
+	return timeouts, nil
+}
+
+type stopper interface {
+	Stop()
+}
+
+// shutdown stops the subsystems in dependency order, each phase under its
+// own deadline:
+//
+//  1. the HTTP and SSH servers stop accepting and drain together, so
+//     handlers finish whatever they were enqueueing;
+//  2. the queues stop once nothing can enqueue into them any more;
+//  3. traces are flushed last so the shutdown itself is recorded.
+//
+// A phase that overruns is logged and the next one starts regardless.
+func shutdown(timeouts shutdownTimeouts, server *http.Server, sshServer *ssh.Server, queues []stopper, traceProvider *sdktrace.TracerProvider) {
+	var wg sync.WaitGroup
+	wg.Go(func() {
+		ctx, cancel := context.WithTimeout(context.Background(), timeouts.http)
+		defer cancel()
+		if err := server.Shutdown(ctx); err != nil {
+			zap.L().Error("HTTP shutdown error", zap.Error(err))
+		}
+	})
+	if sshServer != nil {
+		wg.Go(func() {
+			ctx, cancel := context.WithTimeout(context.Background(), timeouts.ssh)
+			defer cancel()
+			if err := sshServer.Shutdown(ctx); err != nil {
+				zap.L().Error("SSH shutdown error", zap.Error(err))
+			}
+		})
+	}
+	wg.Wait()
+
+	stopQueues(queues, timeouts.queue)
+
+	if traceProvider != nil {
+		ctx, cancel := context.WithTimeout(context.Background(), timeouts.trace)
+		defer cancel()
+		if err := traceProvider.Shutdown(ctx); err != nil {
+			zap.L().Error("TracerProvider shutdown error", zap.Error(err))
+		}
+	}
+
+	zap.L().Info("Server gracefully stopped")
+}
+
+// stopQueues waits for in-flight jobs up to timeout. Jobs still running
+// afterwards are abandoned and picked up again once their lease expires.
+func stopQueues(queues []stopper, timeout time.Duration) {
+	done := make(chan struct{})
+	go func() {
+		var wg sync.WaitGroup
+		for _, queue := range queues {
+			wg.Go(queue.Stop)
+		}
+		wg.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+	case <-time.After(timeout):
+		zap.L().Warn("job queues did not stop in time", zap.Duration("timeout", timeout))
+	}
+}
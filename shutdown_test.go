@@ -0,0 +1,119 @@
+package main
+
+import (
+	"errors"
+	"net"
+	"net/http"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type fakeQueue struct {
+	mu      sync.Mutex
+	stopped bool
+	jobs    []string
+}
+
+func (q *fakeQueue) Enqueue(job string) error {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	if q.stopped {
+		return errors.New("queue stopped")
+	}
+	q.jobs = append(q.jobs, job)
+	return nil
+}
+
+func (q *fakeQueue) Stop() {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	q.stopped = true
+}
+
+type blockingQueue struct{}
+
+func (blockingQueue) Stop() {
+	select {}
+}
+
+func testShutdownTimeouts() shutdownTimeouts {
+	return shutdownTimeouts{
+		http:  5 * time.Second,
+		ssh:   5 * time.Second,
+		queue: 5 * time.Second,
+		trace: 5 * time.Second,
+	}
+}
+
+func TestShutdown(t *testing.T) {
+	t.Run("queues stop after in-flight requests enqueue", func(t *testing.T) {
+		queue := &fakeQueue{}
+		handlerStarted := make(chan struct{})
+		shutdownStarted := make(chan struct{})
+
+		server := &http.Server{
+			ReadHeaderTimeout: time.Second,
+			Handler: http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+				close(handlerStarted)
+				<-shutdownStarted
+
+				if err := queue.Enqueue("job-1"); err != nil {
+					http.Error(w, err.Error(), http.StatusServiceUnavailable)
+					return
+				}
+				w.WriteHeader(http.StatusAccepted)
+			}),
+		}
+		server.RegisterOnShutdown(func() {
+			close(shutdownStarted)
+		})
+
+		listener, err := net.Listen("tcp", "127.0.0.1:0")
+		require.NoError(t, err)
+		go func() {
+			_ = server.Serve(listener)
+		}()
+
+		statusCode := make(chan int, 1)
+		go func() {
+			resp, err := http.Post("http://"+listener.Addr().String(), "text/plain", nil)
+			if err != nil {
+				statusCode <- 0
+				return
+			}
+			_ = resp.Body.Close()
+			statusCode <- resp.StatusCode
+		}()
+
+		<-handlerStarted
+		shutdown(testShutdownTimeouts(), server, nil, []stopper{queue}, nil)
+
+		assert.Equal(t, http.StatusAccepted, <-statusCode)
+		assert.Equal(t, []string{"job-1"}, queue.jobs)
+		assert.True(t, queue.stopped)
+	})
+
+	t.Run("a queue that does not stop is abandoned after its timeout", func(t *testing.T) {
+		server := &http.Server{ReadHeaderTimeout: time.Second}
+		timeouts := testShutdownTimeouts()
+		timeouts.queue = 50 * time.Millisecond
+
+		done := make(chan struct{})
+		go func() {
+			shutdown(timeouts, server, nil, []stopper{blockingQueue{}}, nil)
+			close(done)
+		}()
+
+		select {
+		case <-done:
+		case <-time.After(2 * time.Second):
+			t.Fatal("shutdown waited past the queue timeout")
+		}
+	})
+}
@@ -0,0 +1,88 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"time"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracegrpc"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	semconv "go.opentelemetry.io/otel/semconv/v1.37.0"
+	"go.uber.org/zap"
+
+	"hasir-api/pkg/config"
+)
+
+// collectorDialTimeout bounds the startup reachability probe. The gRPC
+// exporter connects lazily, so without the probe an unreachable collector only
+// shows up as dropped spans.
+const collectorDialTimeout = 2 * time.Second
+
+// setupTracing returns a nil provider when tracing is disabled. An unreachable
+// collector disables tracing as well unless cfg.Required is set, in which case
+// it is returned as an error.
+func setupTracing(ctx context.Context, cfg config.OtelConfig) (*sdktrace.TracerProvider, error) {
+	if !cfg.Enabled {
+		return nil, nil
+	}
+
+	tracerProvider, err := initTracer(ctx, cfg)
+	if err != nil {
+		if cfg.Required {
+			return nil, err
+		}
+
+		zap.L().Error("OpenTelemetry tracing disabled: collector unavailable",
+			zap.String("endpoint", cfg.TraceEndpoint),
+			zap.Error(err))
+		return nil, nil
+	}
+
+	return tracerProvider, nil
+}
+
+func initTracer(ctx context.Context, cfg config.OtelConfig) (*sdktrace.TracerProvider, error) {
+	conn, err := net.DialTimeout("tcp", cfg.TraceEndpoint, collectorDialTimeout)
+	if err != nil {
+		return nil, fmt.Errorf("failed to reach trace collector: %w", err)
+	}
+	_ = conn.Close()
+
+	res, err := resource.New(ctx,
+		resource.WithAttributes(
+			semconv.ServiceNameKey.String("hasir-api"),
+			semconv.ServiceVersionKey.String("1.0.0"),
+		),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create resource: %w", err)
+	}
+
+	traceExporter, err := otlptracegrpc.New(ctx,
+		otlptracegrpc.WithEndpoint(cfg.TraceEndpoint),
+		otlptracegrpc.WithInsecure(),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create trace exporter: %w", err)
+	}
+
+	tracerProvider := sdktrace.NewTracerProvider(
+		sdktrace.WithSampler(sdktrace.AlwaysSample()),
+		sdktrace.WithResource(res),
+		sdktrace.WithBatcher(traceExporter),
+	)
+	otel.SetTracerProvider(tracerProvider)
+	otel.SetTextMapPropagator(
+		propagation.NewCompositeTextMapPropagator(
+			propagation.TraceContext{},
+			propagation.Baggage{},
+		),
+	)
+
+	zap.L().Info("OpenTelemetry tracing enabled", zap.String("endpoint", cfg.TraceEndpoint))
+	return tracerProvider, nil
+}
@@ -0,0 +1,70 @@
+package main
+
+import (
+	"net"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"hasir-api/pkg/config"
+)
+
+// unreachableEndpoint returns an address nothing is listening on.
+func unreachableEndpoint(t *testing.T) string {
+	t.Helper()
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	addr := listener.Addr().String()
+	require.NoError(t, listener.Close())
+
+	return addr
+}
+
+func TestSetupTracing(t *testing.T) {
+	t.Run("disabled tracing has no provider", func(t *testing.T) {
+		provider, err := setupTracing(t.Context(), config.OtelConfig{Enabled: false})
+
+		require.NoError(t, err)
+		assert.Nil(t, provider)
+	})
+
+	t.Run("unreachable collector disables tracing by default", func(t *testing.T) {
+		provider, err := setupTracing(t.Context(), config.OtelConfig{
+			Enabled:       true,
+			TraceEndpoint: unreachableEndpoint(t),
+		})
+
+		require.NoError(t, err)
+		assert.Nil(t, provider)
+	})
+
+	t.Run("unreachable collector fails when tracing is required", func(t *testing.T) {
+		provider, err := setupTracing(t.Context(), config.OtelConfig{
+			Enabled:       true,
+			TraceEndpoint: unreachableEndpoint(t),
+			Required:      true,
+		})
+
+		require.Error(t, err)
+		assert.Nil(t, provider)
+	})
+
+	t.Run("reachable collector enables tracing", func(t *testing.T) {
+		listener, err := net.Listen("tcp", "127.0.0.1:0")
+		require.NoError(t, err)
+		defer func() {
+			_ = listener.Close()
+		}()
+
+		provider, err := setupTracing(t.Context(), config.OtelConfig{
+			Enabled:       true,
+			TraceEndpoint: listener.Addr().String(),
+		})
+
+		require.NoError(t, err)
+		require.NotNil(t, provider)
+		_ = provider.Shutdown(t.Context())
+	})
+}